@@ -0,0 +1,577 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/emergent-company/emergent.memory/pkg/apperror"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+	"github.com/emergent-company/emergent.memory/pkg/sse"
+)
+
+// A2A (agent-to-agent) exposes agent definitions with VisibilityExternal to
+// other agent frameworks. Each project publishes a directory of agent cards;
+// a caller creates a task for an agent, follows its progress over SSE,
+// answers ask_user questions, and fetches the result. A task is the agent run
+// it started, and its ID is that run's ID. When the agent pauses for input
+// and is resumed, the task continues in the resumed run.
+
+// A2ATaskState is the caller-facing state of an A2A task.
+type A2ATaskState string
+
+const (
+	A2ATaskStateWorking       A2ATaskState = "working"
+	A2ATaskStateInputRequired A2ATaskState = "input-required"
+	A2ATaskStatePaused        A2ATaskState = "paused" // step limit reached
+	A2ATaskStateCompleted     A2ATaskState = "completed"
+	A2ATaskStateFailed        A2ATaskState = "failed"
+	A2ATaskStateCanceled      A2ATaskState = "canceled"
+	A2ATaskStateRejected      A2ATaskState = "rejected"
+)
+
+// a2aTriggerSource marks runs started through the A2A task endpoint.
+const a2aTriggerSource = "a2a"
+
+// a2aPollInterval is how often a task stream checks for new progress.
+const a2aPollInterval = time.Second
+
+// AgentCard describes an externally visible agent to other agent frameworks.
+type AgentCard struct {
+	Name               string                `json:"name"`
+	DisplayName        string                `json:"displayName"`
+	Description        string                `json:"description,omitempty"`
+	URL                string                `json:"url"`
+	Capabilities       AgentCardCapabilities `json:"capabilities"`
+	Skills             []string              `json:"skills,omitempty"`
+	DefaultInputModes  []string              `json:"defaultInputModes"`
+	DefaultOutputModes []string              `json:"defaultOutputModes"`
+	Authentication     AgentCardAuth         `json:"authentication"`
+}
+
+// AgentCardCapabilities lists the protocol features an agent supports.
+type AgentCardCapabilities struct {
+	Streaming     bool `json:"streaming"`
+	InputRequired bool `json:"inputRequired"` // the agent may ask the caller questions
+}
+
+// AgentCardAuth describes how to authenticate task requests.
+type AgentCardAuth struct {
+	Schemes []string `json:"schemes"`
+	Scopes  []string `json:"scopes"`
+}
+
+// AgentCardDirectory is the well-known card listing of a project.
+type AgentCardDirectory struct {
+	ProjectID string      `json:"projectId"`
+	Agents    []AgentCard `json:"agents"`
+}
+
+// A2ATask is the state of a task created through the A2A endpoint.
+type A2ATask struct {
+	ID          string              `json:"id"`
+	Agent       string              `json:"agent"`
+	State       A2ATaskState        `json:"state"`
+	Message     string              `json:"message,omitempty"` // error or cancellation reason
+	Result      string              `json:"result,omitempty"`  // final response when completed
+	Questions   []*AgentQuestionDTO `json:"questions,omitempty"`
+	Steps       int                 `json:"steps"`
+	CurrentRun  string              `json:"currentRunId"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	CompletedAt *time.Time          `json:"completedAt,omitempty"`
+}
+
+// CreateA2ATaskRequest starts a task for an external agent.
+type CreateA2ATaskRequest struct {
+	Message  string         `json:"message"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// A2ATaskInputRequest answers a question asked by a task's agent. QuestionID
+// may be omitted when exactly one question is pending.
+type A2ATaskInputRequest struct {
+	QuestionID string `json:"questionId,omitempty"`
+	Response   string `json:"response"`
+}
+
+// buildAgentCard describes an external agent definition. baseURL is the
+// project's A2A root, e.g. https://host/api/projects/<id>/a2a.
+func buildAgentCard(def *AgentDefinition, baseURL string) AgentCard {
+	card := AgentCard{
+		Name:               def.Name,
+		DisplayName:        def.Name,
+		URL:                baseURL + "/agents/" + def.Name + "/tasks",
+		Capabilities:       AgentCardCapabilities{Streaming: true},
+		DefaultInputModes:  []string{"text/plain"},
+		DefaultOutputModes: []string{"text/plain"},
+		Authentication: AgentCardAuth{
+			Schemes: []string{"bearer"},
+			Scopes:  []string{"agents:read", "agents:write"},
+		},
+	}
+	if def.Description != nil {
+		card.Description = *def.Description
+	}
+	for _, pattern := range def.Tools {
+		if ok, _ := path.Match(pattern, "ask_user"); ok {
+			card.Capabilities.InputRequired = true
+			break
+		}
+	}
+	// Tool calls held for approval also pause the task for input
+	for _, policy := range def.ToolPolicies {
+		if policy.Action == ToolPolicyRequireApproval {
+			card.Capabilities.InputRequired = true
+			break
+		}
+	}
+
+	if acp := def.ACPConfig; acp != nil {
+		if acp.DisplayName != "" {
+			card.DisplayName = acp.DisplayName
+		}
+		if acp.Description != "" {
+			card.Description = acp.Description
+		}
+		card.Skills = acp.Capabilities
+		if len(acp.InputModes) > 0 {
+			card.DefaultInputModes = acp.InputModes
+		}
+		if len(acp.OutputModes) > 0 {
+			card.DefaultOutputModes = acp.OutputModes
+		}
+	}
+	return card
+}
+
+// a2aTaskState maps the latest run of a task to its A2A state.
+func a2aTaskState(run *AgentRun, pendingQuestions int) A2ATaskState {
+	switch run.Status {
+	case RunStatusSuccess:
+		return A2ATaskStateCompleted
+	case RunStatusError:
+		return A2ATaskStateFailed
+	case RunStatusCancelled:
+		return A2ATaskStateCanceled
+	case RunStatusSkipped:
+		return A2ATaskStateRejected
+	case RunStatusPaused:
+		if pendingQuestions > 0 {
+			return A2ATaskStateInputRequired
+		}
+		return A2ATaskStatePaused
+	default:
+		return A2ATaskStateWorking
+	}
+}
+
+// terminal reports whether a task in this state can make no more progress.
+func (s A2ATaskState) terminal() bool {
+	switch s {
+	case A2ATaskStateCompleted, A2ATaskStateFailed, A2ATaskStateCanceled, A2ATaskStateRejected:
+		return true
+	}
+	return false
+}
+
+// isA2ATask reports whether run was started through the A2A task endpoint.
+func isA2ATask(run *AgentRun) bool {
+	return run != nil && run.TriggerSource != nil && *run.TriggerSource == a2aTriggerSource
+}
+
+// a2aBaseURL returns the project's A2A root URL as seen by the caller.
+func a2aBaseURL(c echo.Context, projectID string) string {
+	return fmt.Sprintf("%s://%s/api/projects/%s/a2a", c.Scheme(), c.Request().Host, projectID)
+}
+
+// findExternalDefinition returns an external agent definition by name, or a
+// not-found error for missing and non-external definitions alike.
+func (h *Handler) findExternalDefinition(ctx context.Context, projectID, name string) (*AgentDefinition, error) {
+	def, err := h.repo.FindDefinitionByName(ctx, projectID, name)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get agent definition", err)
+	}
+	if def == nil || def.Visibility != VisibilityExternal {
+		return nil, apperror.NewNotFound("Agent", name)
+	}
+	return def, nil
+}
+
+// loadA2ATask builds the task rooted at taskID, along with its run chain.
+// Runs not started through the A2A task endpoint are not found, so A2A callers
+// can't read or continue other runs in the project.
+func (h *Handler) loadA2ATask(ctx context.Context, projectID, taskID string) (*A2ATask, []*AgentRun, error) {
+	root, err := h.repo.FindRunByIDForProject(ctx, taskID, projectID)
+	if err != nil {
+		return nil, nil, apperror.NewInternal("failed to get task", err)
+	}
+	if !isA2ATask(root) {
+		return nil, nil, apperror.NewNotFound("Task", taskID)
+	}
+
+	chain, err := h.repo.FindRunChain(ctx, root.ID)
+	if err != nil {
+		return nil, nil, apperror.NewInternal("failed to get task runs", err)
+	}
+	if len(chain) == 0 {
+		chain = []*AgentRun{root}
+	}
+	latest := chain[len(chain)-1]
+
+	questions, err := h.repo.FindPendingQuestionsByRunID(ctx, latest.ID)
+	if err != nil {
+		return nil, nil, apperror.NewInternal("failed to get task questions", err)
+	}
+
+	task := &A2ATask{
+		ID:          root.ID,
+		State:       a2aTaskState(latest, len(questions)),
+		Steps:       latest.StepCount,
+		CurrentRun:  latest.ID,
+		CreatedAt:   root.StartedAt,
+		CompletedAt: latest.CompletedAt,
+	}
+	if agent, _ := h.repo.FindByID(ctx, root.AgentID, nil); agent != nil {
+		task.Agent = agent.Name
+	}
+	for _, q := range questions {
+		task.Questions = append(task.Questions, q.ToDTO())
+	}
+	if latest.ErrorMessage != nil {
+		task.Message = *latest.ErrorMessage
+	} else if latest.SkipReason != nil {
+		task.Message = *latest.SkipReason
+	}
+	if resp, ok := latest.Summary["final_response"].(string); ok && task.State == A2ATaskStateCompleted {
+		task.Result = resp
+	}
+	return task, chain, nil
+}
+
+// GetAgentCards handles GET /api/projects/:projectId/a2a/.well-known/agent.json
+// @Summary      List external agent cards
+// @Description  Returns the agent cards of the project's externally visible agent definitions
+// @Tags         a2a
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Success      200 {object} AgentCardDirectory "Agent card directory"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/projects/{projectId}/a2a/.well-known/agent.json [get]
+// @Security     bearerAuth
+func (h *Handler) GetAgentCards(c echo.Context) error {
+	projectID := c.Param("projectId")
+
+	defs, err := h.repo.FindAllDefinitions(c.Request().Context(), projectID, false)
+	if err != nil {
+		return apperror.NewInternal("failed to list agent definitions", err)
+	}
+
+	baseURL := a2aBaseURL(c, projectID)
+	dir := AgentCardDirectory{ProjectID: projectID, Agents: []AgentCard{}}
+	for _, def := range defs {
+		if def.Visibility == VisibilityExternal {
+			dir.Agents = append(dir.Agents, buildAgentCard(def, baseURL))
+		}
+	}
+	return c.JSON(http.StatusOK, dir)
+}
+
+// GetAgentCard handles GET /api/projects/:projectId/a2a/agents/:name
+// @Summary      Get an external agent card
+// @Tags         a2a
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        name path string true "Agent definition name"
+// @Success      200 {object} AgentCard "Agent card"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Agent not found or not external"
+// @Router       /api/projects/{projectId}/a2a/agents/{name} [get]
+// @Security     bearerAuth
+func (h *Handler) GetAgentCard(c echo.Context) error {
+	projectID := c.Param("projectId")
+
+	def, err := h.findExternalDefinition(c.Request().Context(), projectID, c.Param("name"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, buildAgentCard(def, a2aBaseURL(c, projectID)))
+}
+
+// CreateA2ATask handles POST /api/projects/:projectId/a2a/agents/:name/tasks
+// @Summary      Create an A2A task
+// @Description  Starts an external agent on the given message. The run executes in the background; follow it via the task's events stream or poll the task.
+// @Tags         a2a
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        name path string true "Agent definition name"
+// @Param        body body CreateA2ATaskRequest true "Task input"
+// @Success      202 {object} A2ATask "Task created"
+// @Failure      400 {object} apperror.Error "Invalid request"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Agent not found or not external"
+// @Failure      503 {object} apperror.Error "Agent executor unavailable"
+// @Router       /api/projects/{projectId}/a2a/agents/{name}/tasks [post]
+// @Security     bearerAuth
+func (h *Handler) CreateA2ATask(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+	projectID := c.Param("projectId")
+	ctx := c.Request().Context()
+
+	var req CreateA2ATaskRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+	if req.Message == "" {
+		return apperror.NewBadRequest("message is required")
+	}
+
+	def, err := h.findExternalDefinition(ctx, projectID, c.Param("name"))
+	if err != nil {
+		return err
+	}
+	if h.executor == nil {
+		return apperror.New(http.StatusServiceUnavailable, "service_unavailable", "agent executor is not available")
+	}
+
+	agent, err := h.repo.EnsureAgentForDefinition(ctx, def, "a2a:"+def.ID)
+	if err != nil {
+		return apperror.NewInternal("failed to get agent for definition", err)
+	}
+
+	var timeout *time.Duration
+	if def.DefaultTimeout != nil && *def.DefaultTimeout > 0 {
+		d := time.Duration(*def.DefaultTimeout) * time.Second
+		timeout = &d
+	}
+
+	metadata := map[string]any{"user_id": user.ID}
+	if user.APITokenID != "" {
+		metadata["api_token_id"] = user.APITokenID
+	}
+	if len(req.Metadata) > 0 {
+		metadata["caller"] = req.Metadata
+	}
+	source := a2aTriggerSource
+
+	// Run detached from the request; hand back the run ID once it exists
+	created := make(chan string, 1)
+	failed := make(chan error, 1)
+	go func() {
+		runCtx := auth.ContextWithProjectID(context.Background(), projectID)
+		_, err := h.executor.Execute(runCtx, ExecuteRequest{
+			Agent:           agent,
+			AgentDefinition: def,
+			ProjectID:       projectID,
+			UserMessage:     req.Message,
+			UserID:          user.ID,
+			MaxSteps:        def.MaxSteps,
+			Timeout:         timeout,
+			TriggerSource:   &source,
+			TriggerMetadata: metadata,
+			OnRunCreated:    func(runID string) { created <- runID },
+		})
+		if err != nil {
+			slog.Error("a2a task execution failed",
+				slog.String("agent", def.Name),
+				slog.String("project_id", projectID),
+				slog.String("error", err.Error()),
+			)
+			failed <- err
+		}
+	}()
+
+	var runID string
+	select {
+	case runID = <-created:
+	case err := <-failed:
+		return apperror.NewInternal("failed to start task", err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	task, _, err := h.loadA2ATask(ctx, projectID, runID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, task)
+}
+
+// GetA2ATask handles GET /api/projects/:projectId/a2a/tasks/:taskId
+// @Summary      Get an A2A task
+// @Description  Returns the task's state, pending questions and, once completed, its result
+// @Tags         a2a
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        taskId path string true "Task ID (UUID)"
+// @Success      200 {object} A2ATask "Task"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Task not found"
+// @Router       /api/projects/{projectId}/a2a/tasks/{taskId} [get]
+// @Security     bearerAuth
+func (h *Handler) GetA2ATask(c echo.Context) error {
+	task, _, err := h.loadA2ATask(c.Request().Context(), c.Param("projectId"), c.Param("taskId"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, task)
+}
+
+// StreamA2ATask handles GET /api/projects/:projectId/a2a/tasks/:taskId/events
+// @Summary      Stream A2A task progress
+// @Description  Server-Sent Events stream of a task: "task" on every state change, "message" and "tool_call" as the agent persists them. Earlier progress is replayed on connect. The stream ends after the task reaches a terminal state.
+// @Tags         a2a
+// @Produce      text/event-stream
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        taskId path string true "Task ID (UUID)"
+// @Success      200 {string} string "SSE stream (events: task, message, tool_call)"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Task not found"
+// @Router       /api/projects/{projectId}/a2a/tasks/{taskId}/events [get]
+// @Security     bearerAuth
+func (h *Handler) StreamA2ATask(c echo.Context) error {
+	projectID := c.Param("projectId")
+	ctx := c.Request().Context()
+
+	task, chain, err := h.loadA2ATask(ctx, projectID, c.Param("taskId"))
+	if err != nil {
+		return err
+	}
+
+	w := sse.NewWriter(c.Response().Writer)
+	if err := w.Start(); err != nil {
+		return err
+	}
+	defer w.Close()
+
+	// Progress is read back from the database rather than from the
+	// executor, so the stream works from any replica and after reconnects.
+	seen := map[string]bool{}
+	var lastState A2ATaskState
+	ticker := time.NewTicker(a2aPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, run := range chain {
+			msgs, err := h.repo.FindMessagesByRunID(ctx, run.ID)
+			if err != nil {
+				return nil
+			}
+			for _, m := range msgs {
+				if !seen[m.ID] {
+					seen[m.ID] = true
+					_ = w.WriteEvent("message", m.ToDTO())
+				}
+			}
+			calls, err := h.repo.FindToolCallsByRunID(ctx, run.ID)
+			if err != nil {
+				return nil
+			}
+			for _, tc := range calls {
+				if !seen[tc.ID] {
+					seen[tc.ID] = true
+					_ = w.WriteEvent("tool_call", tc.ToDTO())
+				}
+			}
+		}
+		if task.State != lastState {
+			lastState = task.State
+			if err := w.WriteEvent("task", task); err != nil {
+				return nil
+			}
+		}
+		if task.State.terminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		task, chain, err = h.loadA2ATask(ctx, projectID, task.ID)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// SendA2ATaskInput handles POST /api/projects/:projectId/a2a/tasks/:taskId/input
+// @Summary      Answer an A2A task's question
+// @Description  Answers a question the agent asked via ask_user and resumes the task
+// @Tags         a2a
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        taskId path string true "Task ID (UUID)"
+// @Param        body body A2ATaskInputRequest true "Answer"
+// @Success      202 {object} A2ATask "Answer accepted, task resuming"
+// @Failure      400 {object} apperror.Error "Invalid request"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Task or question not found"
+// @Failure      409 {object} apperror.Error "Task is not waiting for input"
+// @Router       /api/projects/{projectId}/a2a/tasks/{taskId}/input [post]
+// @Security     bearerAuth
+func (h *Handler) SendA2ATaskInput(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+	projectID := c.Param("projectId")
+	ctx := c.Request().Context()
+
+	var req A2ATaskInputRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+	if req.Response == "" {
+		return apperror.NewBadRequest("response is required")
+	}
+
+	task, chain, err := h.loadA2ATask(ctx, projectID, c.Param("taskId"))
+	if err != nil {
+		return err
+	}
+	if task.State != A2ATaskStateInputRequired {
+		return apperror.ErrConflict.WithMessage(fmt.Sprintf("task is %s, not waiting for input", task.State))
+	}
+
+	questionID := req.QuestionID
+	if questionID == "" {
+		if len(task.Questions) != 1 {
+			return apperror.NewBadRequest("questionId is required when several questions are pending")
+		}
+		questionID = task.Questions[0].ID
+	}
+
+	question, err := h.repo.FindQuestionByID(ctx, questionID)
+	if err != nil {
+		return apperror.NewInternal("failed to get question", err)
+	}
+	if question == nil || question.RunID != chain[len(chain)-1].ID {
+		return apperror.NewNotFound("AgentQuestion", questionID)
+	}
+	if question.Status != QuestionStatusPending {
+		return apperror.ErrConflict.WithMessage(fmt.Sprintf("question is already %s", question.Status))
+	}
+
+	if err := h.answerQuestion(ctx, question, req.Response, user.ID); err != nil {
+		return err
+	}
+
+	task, _, err = h.loadA2ATask(ctx, projectID, task.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, task)
+}
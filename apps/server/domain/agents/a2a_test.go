@@ -0,0 +1,72 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAgentCard(t *testing.T) {
+	desc := "Summarises documents"
+	def := &AgentDefinition{
+		Name:        "summariser",
+		Description: &desc,
+		Tools:       []string{"search_*"},
+		Visibility:  VisibilityExternal,
+	}
+
+	card := buildAgentCard(def, "https://memory.example/api/projects/p1/a2a")
+	assert.Equal(t, "summariser", card.Name)
+	assert.Equal(t, "summariser", card.DisplayName)
+	assert.Equal(t, "Summarises documents", card.Description)
+	assert.Equal(t, "https://memory.example/api/projects/p1/a2a/agents/summariser/tasks", card.URL)
+	assert.True(t, card.Capabilities.Streaming)
+	assert.False(t, card.Capabilities.InputRequired, "search_* does not include ask_user")
+	assert.Equal(t, []string{"text/plain"}, card.DefaultInputModes)
+
+	def.Tools = []string{"*"}
+	def.ACPConfig = &ACPConfig{
+		DisplayName:  "Document Summariser",
+		Capabilities: []string{"summarization"},
+		OutputModes:  []string{"text/markdown"},
+	}
+	card = buildAgentCard(def, "https://memory.example/api/projects/p1/a2a")
+	assert.Equal(t, "Document Summariser", card.DisplayName)
+	assert.Equal(t, "Summarises documents", card.Description, "falls back to the definition description")
+	assert.Equal(t, []string{"summarization"}, card.Skills)
+	assert.Equal(t, []string{"text/plain"}, card.DefaultInputModes)
+	assert.Equal(t, []string{"text/markdown"}, card.DefaultOutputModes)
+	assert.True(t, card.Capabilities.InputRequired, "wildcard tools include ask_user")
+}
+
+func TestA2ATaskState(t *testing.T) {
+	tests := []struct {
+		status   AgentRunStatus
+		pending  int
+		want     A2ATaskState
+		terminal bool
+	}{
+		{RunStatusRunning, 0, A2ATaskStateWorking, false},
+		{RunStatusPaused, 1, A2ATaskStateInputRequired, false},
+		{RunStatusPaused, 0, A2ATaskStatePaused, false},
+		{RunStatusSuccess, 0, A2ATaskStateCompleted, true},
+		{RunStatusError, 0, A2ATaskStateFailed, true},
+		{RunStatusCancelled, 0, A2ATaskStateCanceled, true},
+		{RunStatusSkipped, 0, A2ATaskStateRejected, true},
+	}
+	for _, tt := range tests {
+		got := a2aTaskState(&AgentRun{Status: tt.status}, tt.pending)
+		assert.Equal(t, tt.want, got, "status %s with %d pending", tt.status, tt.pending)
+		assert.Equal(t, tt.terminal, got.terminal(), "terminal(%s)", got)
+	}
+}
+
+func TestIsA2ATask(t *testing.T) {
+	a2a := a2aTriggerSource
+	other := "webhook"
+
+	assert.True(t, isA2ATask(&AgentRun{TriggerSource: &a2a}))
+	assert.False(t, isA2ATask(&AgentRun{TriggerSource: &other}), "runs started elsewhere")
+	assert.False(t, isA2ATask(&AgentRun{}), "runs without a trigger source")
+	assert.False(t, isA2ATask(nil))
+}
@@ -99,6 +99,19 @@ func BuildAskUserTool(deps AskUserToolDeps) (tool.Tool, error) {
 				slog.Int("options_count", len(options)),
 			)
 
+			// A run triggered from an MCP client that can elicit asks its user
+			// directly and continues with the answer
+			answer, err := elicitAnswer(ctx, question, options)
+			if err != nil {
+				deps.Logger.Warn("ask_user: elicitation failed, falling back to notification",
+					slog.String("question_id", q.ID),
+					slog.String("error", err.Error()),
+				)
+			}
+			if answer != nil {
+				return answerElicitedQuestion(ctx, deps, q, answer), nil
+			}
+
 			// Create a notification for the user
 			if deps.UserID != "" {
 				notificationID := createQuestionNotification(ctx, deps, q)
@@ -126,6 +139,38 @@ func BuildAskUserTool(deps AskUserToolDeps) (tool.Tool, error) {
 	)
 }
 
+// answerElicitedQuestion records the answer the user gave through the MCP
+// client and returns it to the agent.
+func answerElicitedQuestion(ctx context.Context, deps AskUserToolDeps, q *AgentQuestion, answer *elicitedAnswer) map[string]any {
+	// An answer is attributed to the run's user. Without one, or when the
+	// user declined, the question is closed so the UI can't answer it again.
+	var err error
+	if answer.Accepted && deps.UserID != "" {
+		err = deps.Repo.AnswerQuestion(ctx, q.ID, answer.Response, deps.UserID)
+	} else {
+		err = deps.Repo.CancelPendingQuestionsForRun(ctx, deps.RunID)
+	}
+	if err != nil {
+		deps.Logger.Warn("failed to close elicited question",
+			slog.String("question_id", q.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if !answer.Accepted {
+		return map[string]any{
+			"question_id": q.ID,
+			"status":      "declined",
+			"message":     "The user declined to answer. Continue without their input.",
+		}
+	}
+	return map[string]any{
+		"question_id": q.ID,
+		"status":      "answered",
+		"response":    answer.Response,
+	}
+}
+
 // createQuestionNotification inserts a notification for the agent question.
 // Returns the notification ID, or empty string on failure.
 func createQuestionNotification(ctx tool.Context, deps AskUserToolDeps, q *AgentQuestion) string {
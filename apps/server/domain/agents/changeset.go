@@ -0,0 +1,704 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+	"github.com/emergent-company/emergent.memory/domain/tasks"
+	"github.com/emergent-company/emergent.memory/pkg/apperror"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// ChangeSetTaskType is the tasks inbox type used for change set reviews.
+const ChangeSetTaskType = "agent_change_set"
+
+// mutatingGraphTools are the graph MCP tools that suggest and hybrid mode
+// agents stage for review instead of executing. Agent updates are staged too,
+// so an agent cannot switch itself or its definition out of review.
+var mutatingGraphTools = map[string]bool{
+	"create_entity":              true,
+	"update_entity":              true,
+	"delete_entity":              true,
+	"restore_entity":             true,
+	"create_relationship":        true,
+	"update_relationship":        true,
+	"delete_relationship":        true,
+	"batch_create_entities":      true,
+	"batch_create_relationships": true,
+	"update_agent":               true,
+	"update_agent_definition":    true,
+}
+
+// autoApplicableTools are the tools whose calls hybrid mode rules may apply
+// without review. Deletes, restores and agent updates are always reviewed.
+var autoApplicableTools = map[string]bool{
+	"create_entity":              true,
+	"update_entity":              true,
+	"create_relationship":        true,
+	"update_relationship":        true,
+	"batch_create_entities":      true,
+	"batch_create_relationships": true,
+}
+
+// toolExecutor executes builtin MCP tools. Implemented by *mcp.Service.
+type toolExecutor interface {
+	ExecuteTool(ctx context.Context, projectID string, toolName string, args map[string]any) (*mcp.ToolResult, error)
+}
+
+// ChangeSetService stages graph writes from suggest/hybrid mode agents and
+// applies or discards them on review.
+type ChangeSetService struct {
+	repo  *Repository
+	tools toolExecutor
+	log   *slog.Logger
+}
+
+// NewChangeSetService creates a new ChangeSetService.
+func NewChangeSetService(repo *Repository, mcpService *mcp.Service, log *slog.Logger) *ChangeSetService {
+	return &ChangeSetService{
+		repo:  repo,
+		tools: mcpService,
+		log:   log.With(logger.Scope("agents.changesets")),
+	}
+}
+
+// effectiveExecutionMode returns the execution mode for a run. Sub-agents
+// inherit their parent's mode via the request; otherwise the agent's own mode
+// applies.
+func effectiveExecutionMode(req ExecuteRequest) AgentExecutionMode {
+	if req.ExecutionMode != "" {
+		return req.ExecutionMode
+	}
+	if req.Agent != nil && req.Agent.ExecutionMode != "" {
+		return req.Agent.ExecutionMode
+	}
+	return ExecutionModeExecute
+}
+
+// effectiveAutoApplyRules returns the hybrid mode rules for a run.
+func effectiveAutoApplyRules(req ExecuteRequest) *AutoApplyRules {
+	if req.AutoApplyRules != nil {
+		return req.AutoApplyRules
+	}
+	if req.Agent != nil {
+		return req.Agent.AutoApplyRules
+	}
+	return nil
+}
+
+// newStager returns the change stager for a run, or nil when the run executes
+// writes directly.
+func (s *ChangeSetService) newStager(req ExecuteRequest, runID string) *changeStager {
+	mode := effectiveExecutionMode(req)
+	if mode != ExecutionModeSuggest && mode != ExecutionModeHybrid {
+		return nil
+	}
+
+	st := &changeStager{
+		svc:       s,
+		mode:      mode,
+		projectID: req.ProjectID,
+		runID:     runID,
+		agentName: "agent",
+	}
+	if mode == ExecutionModeHybrid {
+		st.rules = effectiveAutoApplyRules(req)
+	}
+	if req.Agent != nil {
+		if req.Agent.ID != "" {
+			id := req.Agent.ID
+			st.agentID = &id
+		}
+		if req.Agent.Name != "" {
+			st.agentName = req.Agent.Name
+		}
+	}
+	return st
+}
+
+// changeStager intercepts mutating graph tool calls for a single run and
+// collects them into the run's pending change set.
+type changeStager struct {
+	svc       *ChangeSetService
+	mode      AgentExecutionMode
+	rules     *AutoApplyRules
+	projectID string
+	runID     string
+	agentID   *string
+	agentName string
+
+	mu  sync.Mutex
+	set *AgentChangeSet
+	// placeholders maps the entity placeholders handed out in this run to
+	// the change set staging them.
+	placeholders map[string]string
+}
+
+// intercept stages a mutating tool call and returns the result reported to
+// the agent in place of executing the tool. It returns nil when the call
+// should run normally (read-only tools, or hybrid changes allowed by the
+// auto-apply rules).
+func (st *changeStager) intercept(ctx context.Context, toolName string, args map[string]any) map[string]any {
+	if st == nil || !mutatingGraphTools[toolName] {
+		return nil
+	}
+
+	objectType, confidence := describeChange(toolName, args)
+	change := StagedChange{
+		ToolName:   toolName,
+		Args:       args,
+		ObjectType: objectType,
+		Confidence: confidence,
+		Status:     StagedChangeStatusPending,
+		StagedAt:   time.Now(),
+	}
+
+	// Changes referencing staged entities wait for them to be reviewed
+	st.mu.Lock()
+	refs := referencedPlaceholders(args, st.placeholders)
+	st.mu.Unlock()
+	if st.mode == ExecutionModeHybrid && len(refs) == 0 && st.rules.allows(change) {
+		return nil
+	}
+
+	if toolName == "create_entity" {
+		change.PlaceholderID = uuid.New().String()
+	}
+
+	set, seq, err := st.stage(ctx, change)
+	if err != nil {
+		st.svc.log.Error("failed to stage change",
+			slog.String("run_id", st.runID),
+			slog.String("tool", toolName),
+			slog.String("error", err.Error()),
+		)
+		// Never fall through to executing the write
+		return map[string]any{"error": "failed to stage change for review: " + err.Error()}
+	}
+
+	result := map[string]any{
+		"success":       true,
+		"staged":        true,
+		"change_set_id": set.ID,
+		"change_seq":    seq,
+		"message": fmt.Sprintf("This agent runs in %s mode: the change was staged for review and has NOT been applied yet. "+
+			"It will be applied if a reviewer approves the change set.", st.mode),
+	}
+	if change.PlaceholderID != "" {
+		entity := map[string]any{"id": change.PlaceholderID, "type": objectType}
+		if key, ok := args["key"].(string); ok && key != "" {
+			entity["key"] = key
+		}
+		result["entity"] = entity
+		result["message"] = result["message"].(string) +
+			" The returned entity id is a placeholder that can be used in further staged changes in this run."
+	}
+	return result
+}
+
+// stage appends a change to the run's pending change set, creating the set
+// (and its inbox task) on first use or when the previous set has already been
+// reviewed.
+func (st *changeStager) stage(ctx context.Context, change StagedChange) (*AgentChangeSet, int, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.svc.repo == nil {
+		return nil, 0, fmt.Errorf("change staging is not configured")
+	}
+
+	if st.set != nil {
+		if err := st.resolveReviewedPlaceholders(ctx, &change, st.set.ID); err != nil {
+			return nil, 0, err
+		}
+		change.Seq = len(st.set.Changes) + 1
+		st.set.Changes = append(st.set.Changes, change)
+		updated, err := st.svc.repo.UpdatePendingChangeSetChanges(ctx, st.set)
+		if err != nil {
+			st.set.Changes = st.set.Changes[:len(st.set.Changes)-1]
+			return nil, 0, err
+		}
+		if updated {
+			st.recordPlaceholder(change, st.set.ID)
+			return st.set, change.Seq, nil
+		}
+		// Reviewed while the run was still going; start a new set
+		st.set = nil
+	}
+
+	if err := st.resolveReviewedPlaceholders(ctx, &change, ""); err != nil {
+		return nil, 0, err
+	}
+	change.Seq = 1
+	set := &AgentChangeSet{
+		ProjectID: st.projectID,
+		AgentID:   st.agentID,
+		RunID:     st.runID,
+		Status:    ChangeSetStatusPending,
+		Changes:   []StagedChange{change},
+	}
+	if err := st.svc.repo.CreateChangeSet(ctx, set); err != nil {
+		return nil, 0, err
+	}
+	st.set = set
+	st.recordPlaceholder(change, set.ID)
+
+	st.svc.createReviewTask(ctx, set, st.agentName)
+	return set, change.Seq, nil
+}
+
+// recordPlaceholder remembers the entity placeholder of a staged change.
+// Callers hold st.mu.
+func (st *changeStager) recordPlaceholder(change StagedChange, setID string) {
+	if change.PlaceholderID == "" {
+		return
+	}
+	if st.placeholders == nil {
+		st.placeholders = make(map[string]string)
+	}
+	st.placeholders[change.PlaceholderID] = setID
+}
+
+// resolveReviewedPlaceholders rewrites the placeholders a change takes from
+// change sets reviewed earlier in the run to the IDs of the entities they
+// created, since applyChanges only replaces placeholders within one set.
+// Placeholders of the pending set are left to applyChanges. Callers hold
+// st.mu.
+func (st *changeStager) resolveReviewedPlaceholders(ctx context.Context, change *StagedChange, pendingSetID string) error {
+	ids := make(map[string]string)
+	sets := make(map[string]*AgentChangeSet)
+	for placeholder := range referencedPlaceholders(change.Args, st.placeholders) {
+		setID := st.placeholders[placeholder]
+		if setID == pendingSetID {
+			continue
+		}
+		set, ok := sets[setID]
+		if !ok {
+			var err error
+			if set, err = st.svc.repo.FindChangeSetByID(ctx, st.projectID, setID); err != nil {
+				return err
+			}
+			if set == nil {
+				return fmt.Errorf("change set %s not found", setID)
+			}
+			sets[setID] = set
+		}
+		id := createdEntityID(set, placeholder)
+		if id == "" {
+			return fmt.Errorf("entity %s was not created: its change set %s is %s", placeholder, setID, set.Status)
+		}
+		ids[placeholder] = id
+	}
+	if len(ids) > 0 {
+		change.Args, _ = substitutePlaceholders(change.Args, ids).(map[string]any)
+	}
+	return nil
+}
+
+// createdEntityID returns the ID of the entity a change set created for a
+// placeholder, or "" when that change was not applied.
+func createdEntityID(set *AgentChangeSet, placeholder string) string {
+	for _, change := range set.Changes {
+		if change.PlaceholderID != placeholder || change.Status != StagedChangeStatusApplied {
+			continue
+		}
+		if entity, ok := change.Result["entity"].(map[string]any); ok {
+			id, _ := entity["id"].(string)
+			return id
+		}
+	}
+	return ""
+}
+
+// referencedPlaceholders returns the known placeholders found among the
+// strings of v.
+func referencedPlaceholders(v any, known map[string]string) map[string]bool {
+	refs := make(map[string]bool)
+	if len(known) == 0 {
+		return refs
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch val := v.(type) {
+		case string:
+			if _, ok := known[val]; ok {
+				refs[val] = true
+			}
+		case map[string]any:
+			for _, item := range val {
+				walk(item)
+			}
+		case []any:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	return refs
+}
+
+// createReviewTask adds the change set to the tasks inbox. Failures are
+// logged; the change set stays reviewable through the agents API.
+func (s *ChangeSetService) createReviewTask(ctx context.Context, set *AgentChangeSet, agentName string) {
+	metadata, _ := json.Marshal(map[string]any{
+		"changeSetId": set.ID,
+		"runId":       set.RunID,
+		"agentId":     set.AgentID,
+	})
+	description := fmt.Sprintf("Agent %q staged graph changes in run %s. Approve to apply them or reject to discard them.", agentName, set.RunID)
+	sourceType := ChangeSetTaskType
+
+	task := &tasks.Task{
+		ProjectID:   set.ProjectID,
+		Title:       fmt.Sprintf("Review changes proposed by agent %s", agentName),
+		Description: &description,
+		Type:        ChangeSetTaskType,
+		Status:      "pending",
+		SourceType:  &sourceType,
+		SourceID:    &set.ID,
+		Metadata:    metadata,
+	}
+	if err := s.repo.CreateTask(ctx, task); err != nil {
+		s.log.Warn("failed to create change set review task",
+			slog.String("change_set_id", set.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if err := s.repo.SetChangeSetTaskID(ctx, set.ID, task.ID); err != nil {
+		s.log.Warn("failed to link change set review task",
+			slog.String("change_set_id", set.ID),
+			slog.String("task_id", task.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	set.TaskID = &task.ID
+}
+
+// Approve applies a pending change set. When seqs is non-empty only those
+// changes are applied and the rest are rejected.
+func (s *ChangeSetService) Approve(ctx context.Context, projectID, id, userID string, seqs []int, notes *string) (*AgentChangeSet, error) {
+	set, err := s.approve(ctx, projectID, id, userID, seqs, notes)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveReviewTask(ctx, set, "accepted", userID, notes)
+	return set, nil
+}
+
+// Reject discards a pending change set without applying any of its changes.
+func (s *ChangeSetService) Reject(ctx context.Context, projectID, id, userID string, notes *string) (*AgentChangeSet, error) {
+	set, err := s.reject(ctx, projectID, id, userID, notes)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveReviewTask(ctx, set, "rejected", userID, notes)
+	return set, nil
+}
+
+// HandleTaskResolution implements tasks.ResolutionHandler so that resolving
+// the review task from the inbox approves or rejects the change set. The
+// tasks service marks the task itself.
+func (s *ChangeSetService) HandleTaskResolution(ctx context.Context, task *tasks.Task, resolution, userID string, notes *string) error {
+	if task.SourceID == nil {
+		return nil
+	}
+	var err error
+	if resolution == "accepted" {
+		_, err = s.approve(ctx, task.ProjectID, *task.SourceID, userID, nil, notes)
+	} else {
+		_, err = s.reject(ctx, task.ProjectID, *task.SourceID, userID, notes)
+	}
+	return err
+}
+
+func (s *ChangeSetService) approve(ctx context.Context, projectID, id, userID string, seqs []int, notes *string) (*AgentChangeSet, error) {
+	if _, err := s.findPending(ctx, projectID, id); err != nil {
+		return nil, err
+	}
+
+	// Claim the set; the returned row includes any changes staged up to now
+	set, err := s.repo.TransitionChangeSetStatus(ctx, id, ChangeSetStatusPending, ChangeSetStatusApplying)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to claim change set", err)
+	}
+	if set == nil {
+		return nil, apperror.ErrConflict.WithMessage("change set is no longer pending")
+	}
+
+	var selected map[int]bool
+	if len(seqs) > 0 {
+		selected = make(map[int]bool, len(seqs))
+		for _, seq := range seqs {
+			selected[seq] = true
+		}
+	}
+
+	set.Status = applyChanges(ctx, s.tools, set.ProjectID, set.Changes, selected)
+	s.finishReview(set, userID, notes)
+	if err := s.repo.FinishChangeSetReview(ctx, set); err != nil {
+		return nil, apperror.NewInternal("failed to save change set review", err)
+	}
+
+	s.log.Info("change set approved",
+		slog.String("change_set_id", set.ID),
+		slog.String("status", string(set.Status)),
+		slog.Int("changes", len(set.Changes)),
+	)
+	return set, nil
+}
+
+func (s *ChangeSetService) reject(ctx context.Context, projectID, id, userID string, notes *string) (*AgentChangeSet, error) {
+	if _, err := s.findPending(ctx, projectID, id); err != nil {
+		return nil, err
+	}
+
+	set, err := s.repo.TransitionChangeSetStatus(ctx, id, ChangeSetStatusPending, ChangeSetStatusRejected)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to reject change set", err)
+	}
+	if set == nil {
+		return nil, apperror.ErrConflict.WithMessage("change set is no longer pending")
+	}
+
+	for i := range set.Changes {
+		set.Changes[i].Status = StagedChangeStatusRejected
+	}
+	s.finishReview(set, userID, notes)
+	if err := s.repo.FinishChangeSetReview(ctx, set); err != nil {
+		return nil, apperror.NewInternal("failed to save change set review", err)
+	}
+	return set, nil
+}
+
+func (s *ChangeSetService) findPending(ctx context.Context, projectID, id string) (*AgentChangeSet, error) {
+	set, err := s.repo.FindChangeSetByID(ctx, projectID, id)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get change set", err)
+	}
+	if set == nil {
+		return nil, apperror.NewNotFound("AgentChangeSet", id)
+	}
+	if set.Status != ChangeSetStatusPending {
+		return nil, apperror.ErrConflict.WithMessage(fmt.Sprintf("change set is already %s", set.Status))
+	}
+	return set, nil
+}
+
+func (s *ChangeSetService) finishReview(set *AgentChangeSet, userID string, notes *string) {
+	now := time.Now()
+	if userID != "" {
+		set.ReviewedBy = &userID
+	}
+	set.ReviewedAt = &now
+	set.ReviewNotes = notes
+}
+
+// resolveReviewTask marks the inbox task for a change set reviewed through
+// the agents API (non-fatal).
+func (s *ChangeSetService) resolveReviewTask(ctx context.Context, set *AgentChangeSet, resolution, userID string, notes *string) {
+	if set.TaskID == nil {
+		return
+	}
+	if err := s.repo.ResolveTask(ctx, *set.TaskID, resolution, userID, notes); err != nil {
+		s.log.Warn("failed to resolve change set review task",
+			slog.String("change_set_id", set.ID),
+			slog.String("task_id", *set.TaskID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// applyChanges replays staged changes in order and returns the resulting set
+// status. Entity placeholders handed out at staging time are rewritten to the
+// IDs of the entities created earlier in the set. When selected is non-nil,
+// changes not in it are rejected.
+func applyChanges(ctx context.Context, tools toolExecutor, projectID string, changes []StagedChange, selected map[int]bool) ChangeSetStatus {
+	ids := make(map[string]string)
+	applied, failed := 0, 0
+
+	for i := range changes {
+		change := &changes[i]
+		if selected != nil && !selected[change.Seq] {
+			change.Status = StagedChangeStatusRejected
+			continue
+		}
+
+		args, _ := substitutePlaceholders(change.Args, ids).(map[string]any)
+		toolResult, err := tools.ExecuteTool(ctx, projectID, change.ToolName, args)
+		if err == nil {
+			change.Result, _ = convertToolResult(toolResult)
+			if msg, ok := change.Result["error"].(string); ok {
+				err = fmt.Errorf("%s", msg)
+			}
+		}
+		if err != nil {
+			change.Status = StagedChangeStatusFailed
+			change.Error = err.Error()
+			failed++
+			continue
+		}
+
+		change.Status = StagedChangeStatusApplied
+		applied++
+		if change.PlaceholderID != "" {
+			if entity, ok := change.Result["entity"].(map[string]any); ok {
+				if id, ok := entity["id"].(string); ok {
+					ids[change.PlaceholderID] = id
+				}
+			}
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return ChangeSetStatusApplied
+	case applied > 0:
+		return ChangeSetStatusPartiallyApplied
+	default:
+		return ChangeSetStatusFailed
+	}
+}
+
+// substitutePlaceholders returns a copy of v with every string equal to a
+// known placeholder replaced by the real ID.
+func substitutePlaceholders(v any, ids map[string]string) any {
+	switch val := v.(type) {
+	case string:
+		if id, ok := ids[val]; ok {
+			return id
+		}
+		return val
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = substitutePlaceholders(item, ids)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = substitutePlaceholders(item, ids)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// describeChange extracts the object (or relationship) type and confidence a
+// tool call would write, for display and hybrid rule matching. Batch calls
+// report their distinct types joined with commas and the lowest confidence.
+// Calls that only reference an existing object by ID report no type.
+func describeChange(toolName string, args map[string]any) (string, *float64) {
+	switch toolName {
+	case "create_entity", "create_relationship":
+		objectType, _ := args["type"].(string)
+		return objectType, itemConfidence(args)
+	case "batch_create_entities", "batch_create_relationships":
+		listKey := "entities"
+		if toolName == "batch_create_relationships" {
+			listKey = "relationships"
+		}
+		items, _ := args[listKey].([]any)
+		var types []string
+		seen := make(map[string]bool)
+		var confidence *float64
+		for _, raw := range items {
+			item, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			if t, _ := item["type"].(string); t != "" && !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+			c := itemConfidence(item)
+			if c == nil {
+				// One item without confidence makes the batch's confidence unknown
+				return strings.Join(types, ","), nil
+			}
+			if confidence == nil || *c < *confidence {
+				confidence = c
+			}
+		}
+		return strings.Join(types, ","), confidence
+	default:
+		return "", itemConfidence(args)
+	}
+}
+
+// itemConfidence reads a confidence score from a tool argument object, either
+// top-level or within its properties.
+func itemConfidence(item map[string]any) *float64 {
+	if c, ok := item["confidence"].(float64); ok {
+		return &c
+	}
+	if props, ok := item["properties"].(map[string]any); ok {
+		if c, ok := props["confidence"].(float64); ok {
+			return &c
+		}
+	}
+	return nil
+}
+
+// allows reports whether a hybrid mode change can be applied without review:
+// a create or update whose object types are all listed. The confidence is
+// reported by the agent itself, so it only narrows what the types allow.
+func (r *AutoApplyRules) allows(change StagedChange) bool {
+	if r == nil || len(r.ObjectTypes) == 0 || !autoApplicableTools[change.ToolName] || change.ObjectType == "" {
+		return false
+	}
+
+	allowed := make(map[string]bool, len(r.ObjectTypes))
+	for _, t := range r.ObjectTypes {
+		allowed[t] = true
+	}
+	for _, t := range strings.Split(change.ObjectType, ",") {
+		if !allowed[t] {
+			return false
+		}
+	}
+
+	if r.MinConfidence != nil {
+		if change.Confidence == nil || *change.Confidence < *r.MinConfidence {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateTask inserts a tasks inbox entry (cross-domain insert).
+func (r *Repository) CreateTask(ctx context.Context, task *tasks.Task) error {
+	_, err := r.db.NewInsert().Model(task).Returning("*").Exec(ctx)
+	return err
+}
+
+// ResolveTask marks a pending tasks inbox entry as resolved (cross-domain
+// update used when a change set is reviewed through the agents API).
+func (r *Repository) ResolveTask(ctx context.Context, taskID, resolution, userID string, notes *string) error {
+	now := time.Now()
+	q := r.db.NewUpdate().
+		Model((*tasks.Task)(nil)).
+		Set("status = ?", resolution).
+		Set("resolved_at = ?", now).
+		Set("resolution_notes = ?", notes).
+		Set("updated_at = ?", now).
+		Where("id = ?", taskID).
+		Where("status = ?", "pending")
+	if userID != "" {
+		q = q.Set("resolved_by = ?", userID)
+	}
+	_, err := q.Exec(ctx)
+	return err
+}
@@ -0,0 +1,184 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+)
+
+// fakeToolExecutor records tool calls and creates entities with sequential IDs.
+type fakeToolExecutor struct {
+	calls  []map[string]any
+	failOn string
+}
+
+func (f *fakeToolExecutor) ExecuteTool(_ context.Context, _ string, toolName string, args map[string]any) (*mcp.ToolResult, error) {
+	f.calls = append(f.calls, args)
+	if toolName == f.failOn {
+		return &mcp.ToolResult{IsError: true, Content: []mcp.ContentBlock{{Type: "text", Text: "boom"}}}, nil
+	}
+	result := map[string]any{"success": true}
+	if toolName == "create_entity" {
+		result["entity"] = map[string]any{"id": "real-" + args["name"].(string)}
+	}
+	text, _ := json.Marshal(result)
+	return &mcp.ToolResult{Content: []mcp.ContentBlock{{Type: "text", Text: string(text)}}}, nil
+}
+
+func TestApplyChanges_SubstitutesPlaceholders(t *testing.T) {
+	tools := &fakeToolExecutor{}
+	changes := []StagedChange{
+		{Seq: 1, ToolName: "create_entity", Args: map[string]any{"type": "Person", "name": "ada"}, PlaceholderID: "ph-1"},
+		{Seq: 2, ToolName: "create_entity", Args: map[string]any{"type": "Person", "name": "bob"}, PlaceholderID: "ph-2"},
+		{Seq: 3, ToolName: "create_relationship", Args: map[string]any{"type": "KNOWS", "source_id": "ph-1", "target_id": "ph-2"}},
+	}
+
+	status := applyChanges(context.Background(), tools, "project-1", changes, nil)
+
+	assert.Equal(t, ChangeSetStatusApplied, status)
+	require.Len(t, tools.calls, 3)
+	assert.Equal(t, "real-ada", tools.calls[2]["source_id"])
+	assert.Equal(t, "real-bob", tools.calls[2]["target_id"])
+	for _, c := range changes {
+		assert.Equal(t, StagedChangeStatusApplied, c.Status)
+	}
+	assert.Equal(t, "ph-1", changes[2].Args["source_id"], "staged args are left untouched")
+}
+
+func TestApplyChanges_Selection(t *testing.T) {
+	tools := &fakeToolExecutor{}
+	changes := []StagedChange{
+		{Seq: 1, ToolName: "create_entity", Args: map[string]any{"name": "ada"}},
+		{Seq: 2, ToolName: "create_entity", Args: map[string]any{"name": "bob"}},
+	}
+
+	status := applyChanges(context.Background(), tools, "project-1", changes, map[int]bool{2: true})
+
+	assert.Equal(t, ChangeSetStatusApplied, status)
+	require.Len(t, tools.calls, 1)
+	assert.Equal(t, StagedChangeStatusRejected, changes[0].Status)
+	assert.Equal(t, StagedChangeStatusApplied, changes[1].Status)
+}
+
+func TestApplyChanges_Failures(t *testing.T) {
+	tools := &fakeToolExecutor{failOn: "delete_entity"}
+	changes := []StagedChange{
+		{Seq: 1, ToolName: "create_entity", Args: map[string]any{"name": "ada"}},
+		{Seq: 2, ToolName: "delete_entity", Args: map[string]any{"entity_id": "x"}},
+	}
+
+	status := applyChanges(context.Background(), tools, "project-1", changes, nil)
+	assert.Equal(t, ChangeSetStatusPartiallyApplied, status)
+	assert.Equal(t, StagedChangeStatusFailed, changes[1].Status)
+	assert.Equal(t, "boom", changes[1].Error)
+
+	onlyFailing := []StagedChange{{Seq: 1, ToolName: "delete_entity", Args: map[string]any{}}}
+	assert.Equal(t, ChangeSetStatusFailed, applyChanges(context.Background(), tools, "project-1", onlyFailing, nil))
+}
+
+func TestDescribeChange(t *testing.T) {
+	objectType, confidence := describeChange("create_entity", map[string]any{
+		"type":       "Person",
+		"properties": map[string]any{"confidence": 0.8},
+	})
+	assert.Equal(t, "Person", objectType)
+	require.NotNil(t, confidence)
+	assert.Equal(t, 0.8, *confidence)
+
+	objectType, confidence = describeChange("batch_create_entities", map[string]any{
+		"entities": []any{
+			map[string]any{"type": "Person", "confidence": 0.9},
+			map[string]any{"type": "Place", "confidence": 0.7},
+			map[string]any{"type": "Person", "confidence": 0.95},
+		},
+	})
+	assert.Equal(t, "Person,Place", objectType)
+	require.NotNil(t, confidence)
+	assert.Equal(t, 0.7, *confidence, "batch reports the lowest confidence")
+
+	_, confidence = describeChange("batch_create_entities", map[string]any{
+		"entities": []any{
+			map[string]any{"type": "Person", "confidence": 0.9},
+			map[string]any{"type": "Person"},
+		},
+	})
+	assert.Nil(t, confidence, "an item without confidence makes the batch unknown")
+
+	objectType, _ = describeChange("delete_entity", map[string]any{"entity_id": "x"})
+	assert.Empty(t, objectType)
+}
+
+func TestAutoApplyRules_Allows(t *testing.T) {
+	high, low := 0.95, 0.5
+	threshold := 0.9
+
+	tests := []struct {
+		name   string
+		rules  *AutoApplyRules
+		change StagedChange
+		want   bool
+	}{
+		{name: "nil rules", rules: nil, change: StagedChange{ToolName: "create_entity", ObjectType: "Person"}, want: false},
+		{name: "empty rules", rules: &AutoApplyRules{}, change: StagedChange{ToolName: "create_entity", ObjectType: "Person"}, want: false},
+		{name: "type allowed", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}}, change: StagedChange{ToolName: "create_entity", ObjectType: "Person"}, want: true},
+		{name: "type not allowed", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}}, change: StagedChange{ToolName: "create_entity", ObjectType: "Place"}, want: false},
+		{name: "batch needs every type", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}}, change: StagedChange{ToolName: "batch_create_entities", ObjectType: "Person,Place"}, want: false},
+		{name: "untyped change", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}}, change: StagedChange{ToolName: "update_entity"}, want: false},
+		{name: "confidence alone", rules: &AutoApplyRules{MinConfidence: &threshold}, change: StagedChange{ToolName: "create_entity", ObjectType: "Person", Confidence: &high}, want: false},
+		{name: "confidence too low", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}, MinConfidence: &threshold}, change: StagedChange{ToolName: "create_entity", ObjectType: "Person", Confidence: &low}, want: false},
+		{name: "confidence unknown", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}, MinConfidence: &threshold}, change: StagedChange{ToolName: "create_entity", ObjectType: "Person"}, want: false},
+		{name: "both rules", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}, MinConfidence: &threshold}, change: StagedChange{ToolName: "create_entity", ObjectType: "Person", Confidence: &high}, want: true},
+		{name: "delete never", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}, MinConfidence: &threshold}, change: StagedChange{ToolName: "delete_entity", ObjectType: "Person", Confidence: &high}, want: false},
+		{name: "restore never", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}}, change: StagedChange{ToolName: "restore_entity", ObjectType: "Person"}, want: false},
+		{name: "agent update never", rules: &AutoApplyRules{ObjectTypes: []string{"Person"}}, change: StagedChange{ToolName: "update_agent", ObjectType: "Person"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rules.allows(tt.change))
+		})
+	}
+}
+
+func TestNewStager_ExecuteModePassesThrough(t *testing.T) {
+	s := &ChangeSetService{}
+	assert.Nil(t, s.newStager(ExecuteRequest{Agent: &Agent{ExecutionMode: ExecutionModeExecute}}, "run-1"))
+}
+
+func TestChangeStager_StagesAgentUpdates(t *testing.T) {
+	st := &changeStager{svc: &ChangeSetService{log: slog.Default()}, mode: ExecutionModeSuggest}
+	for _, tool := range []string{"update_agent", "update_agent_definition"} {
+		result := st.intercept(context.Background(), tool, map[string]any{"executionMode": "execute"})
+		require.NotNil(t, result, "%s must not run unreviewed", tool)
+	}
+}
+
+func TestChangeStager_PlaceholderReferencesAreReviewed(t *testing.T) {
+	st := &changeStager{
+		svc:          &ChangeSetService{log: slog.Default()},
+		mode:         ExecutionModeHybrid,
+		rules:        &AutoApplyRules{ObjectTypes: []string{"KNOWS"}},
+		placeholders: map[string]string{"ph-1": "set-1"},
+	}
+	assert.Nil(t, st.intercept(context.Background(), "create_relationship", map[string]any{"type": "KNOWS", "source_id": "a", "target_id": "b"}))
+	assert.NotNil(t, st.intercept(context.Background(), "create_relationship", map[string]any{"type": "KNOWS", "source_id": "ph-1", "target_id": "b"}),
+		"a relationship to a staged entity cannot be applied before the entity exists")
+}
+
+func TestCreatedEntityID(t *testing.T) {
+	set := &AgentChangeSet{Changes: []StagedChange{
+		{PlaceholderID: "ph-1", Status: StagedChangeStatusApplied, Result: map[string]any{"entity": map[string]any{"id": "real-1"}}},
+		{PlaceholderID: "ph-2", Status: StagedChangeStatusRejected},
+	}}
+	assert.Equal(t, "real-1", createdEntityID(set, "ph-1"))
+	assert.Empty(t, createdEntityID(set, "ph-2"))
+	assert.Empty(t, createdEntityID(set, "ph-3"))
+
+	refs := referencedPlaceholders(map[string]any{"ids": []any{"ph-1", "x"}, "nested": map[string]any{"id": "ph-2"}}, map[string]string{"ph-1": "s", "ph-2": "s", "ph-3": "s"})
+	assert.Equal(t, map[string]bool{"ph-1": true, "ph-2": true}, refs)
+}
@@ -22,6 +22,9 @@ type CoordinationToolDeps struct {
 	ParentRunID string
 	Depth       int
 	MaxDepth    int
+	// Staging settings inherited by spawned sub-agents (empty in execute mode)
+	ExecutionMode  AgentExecutionMode
+	AutoApplyRules *AutoApplyRules
 }
 
 // --- list_available_agents ---
@@ -234,6 +237,8 @@ func executeSingleSpawn(ctx context.Context, deps CoordinationToolDeps, req Spaw
 		Timeout:         timeout,
 		Depth:           deps.Depth + 1,
 		MaxDepth:        deps.MaxDepth,
+		ExecutionMode:   deps.ExecutionMode,
+		AutoApplyRules:  deps.AutoApplyRules,
 	}
 
 	// Handle resume_run_id: resume a paused prior run instead of starting fresh
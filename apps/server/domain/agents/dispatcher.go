@@ -0,0 +1,485 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/domain/events"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	// dispatchInterval is how often the dispatcher polls the outbox and the
+	// delivery queue.
+	dispatchInterval = 2 * time.Second
+	// dispatchBatchSize caps the outbox events read per agent per poll.
+	dispatchBatchSize = 200
+	// maxConcurrentDeliveries caps reaction runs in flight per replica.
+	maxConcurrentDeliveries = 8
+	// maxDeliveryAttempts is how often a delivery is tried before it fails.
+	maxDeliveryAttempts = 5
+	// deliveryBaseBackoff is the wait before the first retry; it doubles
+	// with every further attempt up to deliveryMaxBackoff.
+	deliveryBaseBackoff = 30 * time.Second
+	deliveryMaxBackoff  = 30 * time.Minute
+	// staleDeliveryAfter is how long a delivery may stay processing before it
+	// is considered interrupted and requeued.
+	staleDeliveryAfter = 2 * time.Hour
+)
+
+// EventDispatcher delivers entity events from the durable outbox to reaction
+// agents with at-least-once semantics. Each poll it advances every reaction
+// agent's outbox cursor, queueing matching events in kb.agent_processing_log,
+// then claims due deliveries and runs the agents. Failed runs are retried with
+// exponential backoff. Cursors and deliveries are locked with SKIP LOCKED, so
+// any number of replicas can run the dispatcher side by side.
+type EventDispatcher struct {
+	repo     *Repository
+	triggers *TriggerService
+	log      *slog.Logger
+
+	slots   chan struct{} // bounds concurrent deliveries
+	stopCh  chan struct{}
+	stopped chan struct{}
+	running bool
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+}
+
+// NewEventDispatcher creates a new EventDispatcher.
+func NewEventDispatcher(repo *Repository, triggers *TriggerService, log *slog.Logger) *EventDispatcher {
+	return &EventDispatcher{
+		repo:     repo,
+		triggers: triggers,
+		log:      log.With(logger.Scope("agents.dispatcher")),
+		slots:    make(chan struct{}, maxConcurrentDeliveries),
+	}
+}
+
+// Start begins the dispatcher's polling loop.
+func (d *EventDispatcher) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return nil
+	}
+	d.running = true
+	d.stopCh = make(chan struct{})
+	d.stopped = make(chan struct{})
+	d.triggers.dispatcherActive.Store(true)
+
+	d.log.Info("event dispatcher starting",
+		slog.Duration("poll_interval", dispatchInterval),
+		slog.Int("max_concurrent", maxConcurrentDeliveries))
+
+	go d.run(context.WithoutCancel(ctx))
+	return nil
+}
+
+// Stop stops polling and waits for in-flight deliveries to finish. Deliveries
+// still running when ctx expires are requeued after staleDeliveryAfter.
+func (d *EventDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return nil
+	}
+	d.running = false
+	close(d.stopCh)
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		<-d.stopped
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.log.Info("event dispatcher stopped gracefully")
+	case <-ctx.Done():
+		d.log.Warn("event dispatcher stop timeout")
+	}
+	return nil
+}
+
+// run is the main dispatcher loop.
+func (d *EventDispatcher) run(ctx context.Context) {
+	defer close(d.stopped)
+
+	if n, err := d.repo.RequeueStaleDeliveries(ctx, staleDeliveryAfter, maxDeliveryAttempts); err != nil {
+		d.log.Warn("failed to requeue stale deliveries", slog.String("error", err.Error()))
+	} else if n > 0 {
+		d.log.Info("requeued stale deliveries", slog.Int("count", n))
+	}
+
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.queueEvents(ctx)
+			d.deliver(ctx)
+		}
+	}
+}
+
+// queueEvents advances the outbox cursor of every enabled reaction agent.
+func (d *EventDispatcher) queueEvents(ctx context.Context) {
+	agents, err := d.repo.FindEnabledByTriggerType(ctx, TriggerTypeReaction)
+	if err != nil {
+		d.log.Warn("failed to load reaction agents", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, agent := range agents {
+		if agent.ReactionConfig == nil || len(agent.ReactionConfig.Events) == 0 {
+			continue
+		}
+		match := func(evt *events.OutboxEvent) (ReactionEventType, bool) {
+			return matchReaction(agent, evt)
+		}
+		queued, err := d.repo.QueueOutboxEvents(ctx, agent, dispatchBatchSize, match)
+		if err != nil {
+			d.log.Warn("failed to queue events for agent",
+				slog.String("agent", agent.Name),
+				slog.String("agent_id", agent.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if queued > 0 {
+			d.log.Debug("queued events for agent",
+				slog.String("agent", agent.Name),
+				slog.String("agent_id", agent.ID),
+				slog.Int("count", queued))
+		}
+	}
+}
+
+// deliver claims as many due deliveries as there are free slots and runs
+// each in its own goroutine.
+func (d *EventDispatcher) deliver(ctx context.Context) {
+	free := cap(d.slots) - len(d.slots)
+	if free == 0 {
+		return
+	}
+
+	entries, err := d.repo.ClaimDueDeliveries(ctx, free)
+	if err != nil {
+		d.log.Warn("failed to claim deliveries", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, entry := range entries {
+		d.slots <- struct{}{}
+		d.wg.Add(1)
+		go func(entry *AgentProcessingLog) {
+			defer func() {
+				<-d.slots
+				d.wg.Done()
+			}()
+			d.deliverOne(ctx, entry)
+		}(entry)
+	}
+}
+
+// deliverOne runs the agent for one claimed delivery and records the outcome.
+func (d *EventDispatcher) deliverOne(ctx context.Context, entry *AgentProcessingLog) {
+	log := d.log.With(
+		slog.String("delivery_id", entry.ID),
+		slog.String("agent_id", entry.AgentID),
+		slog.String("object_id", entry.GraphObjectID),
+		slog.String("event", string(entry.EventType)),
+		slog.Int("attempt", entry.AttemptCount),
+	)
+
+	agent, err := d.repo.FindByID(ctx, entry.AgentID, nil)
+	if err != nil {
+		d.retry(ctx, log, entry, nil, "failed to load agent: "+err.Error())
+		return
+	}
+	if agent == nil || !agent.Enabled {
+		d.finish(ctx, log, entry, ProcessingStatusSkipped, nil, "agent deleted or disabled", nil)
+		return
+	}
+
+	if agent.ReactionConfig != nil && agent.ReactionConfig.ConcurrencyStrategy == ConcurrencySkip {
+		busy, err := d.repo.HasOtherDeliveryInProgress(ctx, agent.ID, entry.GraphObjectID, entry.ID)
+		if err != nil {
+			d.retry(ctx, log, entry, nil, "failed to check concurrent deliveries: "+err.Error())
+			return
+		}
+		if busy {
+			d.finish(ctx, log, entry, ProcessingStatusSkipped, nil, "agent is already processing this object", nil)
+			return
+		}
+	}
+
+	result, err := d.triggers.runTriggeredAgent(ctx, agent, &reactionTrigger{Delivery: entry})
+	if err != nil {
+		d.retry(ctx, log, entry, nil, err.Error())
+		return
+	}
+
+	summary := map[string]any{"runId": result.RunID, "status": string(result.Status)}
+	if result.Status == RunStatusError {
+		msg := "agent run failed"
+		if e, ok := result.Summary["error"].(string); ok && e != "" {
+			msg = e
+		}
+		d.retry(ctx, log, entry, &result.RunID, msg)
+		return
+	}
+	d.finish(ctx, log, entry, ProcessingStatusCompleted, &result.RunID, "", summary)
+}
+
+// retry requeues a failed delivery with backoff, or fails it for good once
+// it has used all attempts.
+func (d *EventDispatcher) retry(ctx context.Context, log *slog.Logger, entry *AgentProcessingLog, runID *string, msg string) {
+	if entry.AttemptCount >= maxDeliveryAttempts {
+		log.Error("reaction delivery failed permanently", slog.String("error", msg))
+		d.finish(ctx, log, entry, ProcessingStatusFailed, runID, msg, nil)
+		return
+	}
+
+	next := time.Now().Add(deliveryBackoff(entry.AttemptCount))
+	log.Warn("reaction delivery failed, will retry",
+		slog.String("error", msg),
+		slog.Time("next_attempt_at", next))
+	if err := d.repo.RetryDelivery(ctx, entry.ID, runID, msg, next); err != nil {
+		log.Error("failed to requeue delivery", slog.String("error", err.Error()))
+	}
+}
+
+// finish records a delivery's final status.
+func (d *EventDispatcher) finish(ctx context.Context, log *slog.Logger, entry *AgentProcessingLog, status AgentProcessingStatus, runID *string, msg string, summary map[string]any) {
+	var errorMsg *string
+	if msg != "" {
+		errorMsg = &msg
+	}
+	if err := d.repo.FinishDelivery(ctx, entry.ID, status, runID, errorMsg, summary); err != nil {
+		log.Error("failed to record delivery outcome",
+			slog.String("status", string(status)),
+			slog.String("error", err.Error()))
+	}
+}
+
+// deliveryBackoff returns the wait before the retry following the given
+// attempt: deliveryBaseBackoff doubled per attempt, capped at deliveryMaxBackoff.
+func deliveryBackoff(attempt int) time.Duration {
+	backoff := deliveryBaseBackoff
+	for i := 1; i < attempt && backoff < deliveryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > deliveryMaxBackoff {
+		backoff = deliveryMaxBackoff
+	}
+	return backoff
+}
+
+// matchReaction reports whether an outbox event triggers a reaction agent,
+// and as which reaction event type. Events caused by agents are ignored to
+// prevent loops, matching the in-process trigger path. The agent's condition,
+// if any, is evaluated against the event's data.
+func matchReaction(agent *Agent, evt *events.OutboxEvent) (ReactionEventType, bool) {
+	rc := agent.ReactionConfig
+	if rc == nil || evt.ProjectID != agent.ProjectID || evt.ActorType == events.ActorAgent {
+		return "", false
+	}
+
+	eventType, ok := reactionEventType(evt.EventType)
+	if !ok {
+		return "", false
+	}
+	subscribed := false
+	for _, e := range rc.Events {
+		if e == eventType {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return "", false
+	}
+
+	if len(rc.ObjectTypes) > 0 {
+		objectType := evt.ObjectType
+		if objectType == "" {
+			objectType = string(evt.Entity)
+		}
+		typeMatched := false
+		for _, t := range rc.ObjectTypes {
+			if t == objectType {
+				typeMatched = true
+				break
+			}
+		}
+		if !typeMatched {
+			return "", false
+		}
+	}
+
+	if !rc.Condition.matches(eventType, evt) {
+		return "", false
+	}
+	return eventType, true
+}
+
+// Validate checks the reaction config's condition and limits.
+func (rc *ReactionConfig) Validate() error {
+	if rc == nil {
+		return nil
+	}
+	if rc.DebounceSeconds < 0 {
+		return fmt.Errorf("debounceSeconds must not be negative")
+	}
+	if rc.MaxConcurrentRuns < 0 {
+		return fmt.Errorf("maxConcurrentRuns must not be negative")
+	}
+	if rc.Condition != nil {
+		for i, p := range rc.Condition.Match {
+			if err := p.Validate(); err != nil {
+				return fmt.Errorf("condition.match[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether an event satisfies the condition. The event's data
+// is the payload written by the graph service: the new version's state, its
+// change summary and the previous version's status and labels.
+func (c *ReactionCondition) matches(eventType ReactionEventType, evt *events.OutboxEvent) bool {
+	if c == nil {
+		return true
+	}
+	data := evt.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+	created := eventType == EventTypeCreated
+	previous, _ := data["previous"].(map[string]any)
+
+	if len(c.PropertiesChanged) > 0 {
+		changed := changedProperties(data, created)
+		if !containsAny(changed, c.PropertiesChanged) {
+			return false
+		}
+	}
+
+	if len(c.LabelsAdded) > 0 || len(c.LabelsRemoved) > 0 {
+		current := toStringSet(data["labels"])
+		before := map[string]bool{}
+		if !created {
+			before = toStringSet(previous["labels"])
+		}
+		if len(c.LabelsAdded) > 0 && !containsAny(setDifference(current, before), c.LabelsAdded) {
+			return false
+		}
+		if len(c.LabelsRemoved) > 0 && !containsAny(setDifference(before, current), c.LabelsRemoved) {
+			return false
+		}
+	}
+
+	if c.StatusChanged {
+		if created {
+			if data["status"] == nil {
+				return false
+			}
+		} else if previous == nil || previous["status"] == data["status"] {
+			return false
+		}
+	}
+
+	if len(c.Match) > 0 {
+		doc := map[string]any{
+			"type":       evt.ObjectType,
+			"key":        data["key"],
+			"status":     data["status"],
+			"labels":     data["labels"],
+			"properties": data["properties"],
+			"changes":    data["changes"],
+			"previous":   previous,
+			"event":      string(eventType),
+		}
+		for _, p := range c.Match {
+			ok, err := p.Evaluate(doc)
+			if err != nil || !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// changedProperties returns the names of the properties an event changed,
+// taken from the change summary's JSON Pointer paths. For created events
+// every property counts as changed.
+func changedProperties(data map[string]any, created bool) map[string]bool {
+	changed := map[string]bool{}
+	if created {
+		props, _ := data["properties"].(map[string]any)
+		for k := range props {
+			changed[k] = true
+		}
+		return changed
+	}
+	summary, _ := data["changes"].(map[string]any)
+	for path := range toStringSet(summary["paths"]) {
+		name := strings.TrimPrefix(path, "/")
+		if i := strings.Index(name, "/"); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+		changed[name] = true
+	}
+	return changed
+}
+
+// toStringSet converts a JSON string array into a set.
+func toStringSet(v any) map[string]bool {
+	set := map[string]bool{}
+	switch items := v.(type) {
+	case []string:
+		for _, s := range items {
+			set[s] = true
+		}
+	case []any:
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+	return set
+}
+
+// setDifference returns the members of a that are not in b.
+func setDifference(a, b map[string]bool) map[string]bool {
+	diff := map[string]bool{}
+	for k := range a {
+		if !b[k] {
+			diff[k] = true
+		}
+	}
+	return diff
+}
+
+// containsAny reports whether set holds any of wanted; "*" matches a
+// non-empty set.
+func containsAny(set map[string]bool, wanted []string) bool {
+	for _, w := range wanted {
+		if (w == "*" && len(set) > 0) || set[w] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/emergent-company/emergent.memory/domain/events"
+	"github.com/emergent-company/emergent.memory/domain/graph"
+)
+
+func TestMatchReaction(t *testing.T) {
+	agent := makeTestAgent("a1", "reactor", "p1", &ReactionConfig{
+		ObjectTypes: []string{"Person"},
+		Events:      []ReactionEventType{EventTypeCreated, EventTypeDeleted},
+	})
+	base := events.OutboxEvent{
+		ProjectID:  "p1",
+		EventType:  events.EventTypeCreated,
+		Entity:     events.EntityGraphObject,
+		ObjectType: "Person",
+	}
+
+	eventType, ok := matchReaction(agent, &base)
+	assert.True(t, ok)
+	assert.Equal(t, EventTypeCreated, eventType)
+
+	tests := []struct {
+		name   string
+		modify func(e *events.OutboxEvent)
+	}{
+		{name: "other project", modify: func(e *events.OutboxEvent) { e.ProjectID = "p2" }},
+		{name: "unsubscribed event", modify: func(e *events.OutboxEvent) { e.EventType = events.EventTypeUpdated }},
+		{name: "other object type", modify: func(e *events.OutboxEvent) { e.ObjectType = "Place" }},
+		{name: "agent actor", modify: func(e *events.OutboxEvent) { e.ActorType = events.ActorAgent }},
+		{name: "batch event", modify: func(e *events.OutboxEvent) { e.EventType = events.EventTypeBatch }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt := base
+			tt.modify(&evt)
+			_, ok := matchReaction(agent, &evt)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("user actor allowed", func(t *testing.T) {
+		evt := base
+		evt.ActorType = events.ActorUser
+		_, ok := matchReaction(agent, &evt)
+		assert.True(t, ok)
+	})
+
+	t.Run("wildcard object types", func(t *testing.T) {
+		wildcard := makeTestAgent("a2", "all", "p1", &ReactionConfig{Events: []ReactionEventType{EventTypeDeleted}})
+		evt := base
+		evt.EventType = events.EventTypeDeleted
+		evt.ObjectType = "Anything"
+		eventType, ok := matchReaction(wildcard, &evt)
+		assert.True(t, ok)
+		assert.Equal(t, EventTypeDeleted, eventType)
+	})
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, deliveryBackoff(1))
+	assert.Equal(t, 60*time.Second, deliveryBackoff(2))
+	assert.Equal(t, 2*time.Minute, deliveryBackoff(3))
+	assert.Equal(t, deliveryMaxBackoff, deliveryBackoff(20))
+}
+
+func TestOnEntityEvent_GraphObjectsSkippedWhileDispatcherActive(t *testing.T) {
+	ts, eventSvc := newTestTriggerServiceWithEvents()
+	ts.dispatcherActive.Store(true)
+
+	// Matches a registered agent in the same project; with nil repo the
+	// spawned execution would panic if the event were not skipped.
+	agent := makeTestAgent("a1", "outbox-only", "p1", &ReactionConfig{
+		ObjectTypes: []string{"Person"},
+		Events:      []ReactionEventType{EventTypeCreated},
+	})
+	ts.registerEventTrigger(agent)
+
+	eventSvc.EmitCreated(events.EntityGraphObject, "obj-1", "p1", &events.EmitOptions{ObjectType: "Person"})
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestReactionCondition_Matches(t *testing.T) {
+	updated := &events.OutboxEvent{
+		EventType:  events.EventTypeUpdated,
+		ObjectType: "Ticket",
+		Data: map[string]any{
+			"status":     "closed",
+			"labels":     []any{"urgent", "billing"},
+			"properties": map[string]any{"priority": float64(1), "title": "Refund"},
+			"changes":    map[string]any{"paths": []any{"/priority"}},
+			"previous":   map[string]any{"status": "open", "labels": []any{"billing"}},
+		},
+	}
+	created := &events.OutboxEvent{
+		EventType:  events.EventTypeCreated,
+		ObjectType: "Ticket",
+		Data: map[string]any{
+			"labels":     []any{"new"},
+			"properties": map[string]any{"title": "Hello"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		cond      *ReactionCondition
+		eventType ReactionEventType
+		evt       *events.OutboxEvent
+		want      bool
+	}{
+		{"nil condition", nil, EventTypeUpdated, updated, true},
+		{"property changed", &ReactionCondition{PropertiesChanged: []string{"priority"}}, EventTypeUpdated, updated, true},
+		{"property unchanged", &ReactionCondition{PropertiesChanged: []string{"title"}}, EventTypeUpdated, updated, false},
+		{"created counts all properties", &ReactionCondition{PropertiesChanged: []string{"title"}}, EventTypeCreated, created, true},
+		{"label added", &ReactionCondition{LabelsAdded: []string{"urgent"}}, EventTypeUpdated, updated, true},
+		{"label already present", &ReactionCondition{LabelsAdded: []string{"billing"}}, EventTypeUpdated, updated, false},
+		{"any label added", &ReactionCondition{LabelsAdded: []string{"*"}}, EventTypeCreated, created, true},
+		{"label not removed", &ReactionCondition{LabelsRemoved: []string{"billing"}}, EventTypeUpdated, updated, false},
+		{"status changed", &ReactionCondition{StatusChanged: true}, EventTypeUpdated, updated, true},
+		{"status on create without status", &ReactionCondition{StatusChanged: true}, EventTypeCreated, created, false},
+		{
+			"predicate holds",
+			&ReactionCondition{Match: []graph.Predicate{{Path: "/properties/priority", Operator: "lessThanOrEqual", Value: 2}}},
+			EventTypeUpdated, updated, true,
+		},
+		{
+			"predicate on previous",
+			&ReactionCondition{Match: []graph.Predicate{{Path: "/previous/status", Operator: "equals", Value: "closed"}}},
+			EventTypeUpdated, updated, false,
+		},
+		{
+			"all clauses must hold",
+			&ReactionCondition{PropertiesChanged: []string{"priority"}, LabelsAdded: []string{"vip"}},
+			EventTypeUpdated, updated, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cond.matches(tt.eventType, tt.evt))
+		})
+	}
+}
+
+func TestMatchReaction_Condition(t *testing.T) {
+	agent := makeTestAgent("a1", "reactor", "p1", &ReactionConfig{
+		Events:    []ReactionEventType{EventTypeUpdated},
+		Condition: &ReactionCondition{StatusChanged: true},
+	})
+	evt := &events.OutboxEvent{
+		ProjectID: "p1",
+		EventType: events.EventTypeUpdated,
+		Entity:    events.EntityGraphObject,
+		Data:      map[string]any{"status": "done", "previous": map[string]any{"status": "done"}},
+	}
+	_, ok := matchReaction(agent, evt)
+	assert.False(t, ok)
+
+	evt.Data["previous"] = map[string]any{"status": "open"}
+	_, ok = matchReaction(agent, evt)
+	assert.True(t, ok)
+}
+
+func TestReactionConfig_Validate(t *testing.T) {
+	var nilConfig *ReactionConfig
+	assert.NoError(t, nilConfig.Validate())
+	assert.NoError(t, (&ReactionConfig{DebounceSeconds: 300, MaxConcurrentRuns: 2}).Validate())
+	assert.Error(t, (&ReactionConfig{DebounceSeconds: -1}).Validate())
+	assert.Error(t, (&ReactionConfig{MaxConcurrentRuns: -1}).Validate())
+	assert.Error(t, (&ReactionConfig{Condition: &ReactionCondition{
+		Match: []graph.Predicate{{Path: "/status", Operator: "like"}},
+	}}).Validate())
+}
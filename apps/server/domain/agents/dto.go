@@ -14,6 +14,7 @@ type AgentDTO struct {
 	TriggerType    AgentTriggerType   `json:"triggerType"`
 	ReactionConfig *ReactionConfig    `json:"reactionConfig"`
 	ExecutionMode  AgentExecutionMode `json:"executionMode"`
+	AutoApplyRules *AutoApplyRules    `json:"autoApplyRules,omitempty"`
 	Capabilities   *AgentCapabilities `json:"capabilities"`
 	Config         map[string]any     `json:"config"`
 	Description    *string            `json:"description"`
@@ -54,6 +55,7 @@ type CreateAgentDTO struct {
 	TriggerType    AgentTriggerType   `json:"triggerType"`
 	ReactionConfig *ReactionConfig    `json:"reactionConfig"`
 	ExecutionMode  AgentExecutionMode `json:"executionMode"`
+	AutoApplyRules *AutoApplyRules    `json:"autoApplyRules"`
 	Capabilities   *AgentCapabilities `json:"capabilities"`
 	Config         map[string]any     `json:"config"`
 	Description    *string            `json:"description"`
@@ -68,6 +70,7 @@ type UpdateAgentDTO struct {
 	TriggerType    *AgentTriggerType   `json:"triggerType"`
 	ReactionConfig *ReactionConfig     `json:"reactionConfig"`
 	ExecutionMode  *AgentExecutionMode `json:"executionMode"`
+	AutoApplyRules *AutoApplyRules     `json:"autoApplyRules"`
 	Capabilities   *AgentCapabilities  `json:"capabilities"`
 	Config         map[string]any      `json:"config"`
 	Description    *string             `json:"description"`
@@ -78,14 +81,32 @@ type BatchTriggerDTO struct {
 	ObjectIDs []string `json:"objectIds" validate:"required,min=1,max=100,dive,uuid"`
 }
 
-// PendingEventObjectDTO represents a graph object pending processing
+// PendingEventObjectDTO represents a graph object event pending processing
 type PendingEventObjectDTO struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	Key       string    `json:"key"`
-	Version   int       `json:"version"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string    `bun:"id" json:"id"`
+	Type      string    `bun:"type" json:"type"`
+	Key       string    `bun:"key" json:"key"`
+	Version   int       `bun:"version" json:"version"`
+	CreatedAt time.Time `bun:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `bun:"updated_at" json:"updatedAt"`
+
+	EventType     string     `bun:"event_type" json:"eventType"`
+	Status        string     `bun:"status" json:"status"` // new (not yet queued), pending or processing
+	Attempts      int        `bun:"attempt_count" json:"attempts"`
+	NextAttemptAt *time.Time `bun:"next_attempt_at" json:"nextAttemptAt,omitempty"`
+	LastError     *string    `bun:"error_message" json:"lastError,omitempty"`
+	EventID       *int64     `bun:"event_id" json:"eventId,omitempty"`
+	QueuedAt      time.Time  `bun:"queued_at" json:"queuedAt"`
+}
+
+// ReplayEventsDTO is the request body for replaying a reaction agent's events
+type ReplayEventsDTO struct {
+	Since time.Time `json:"since" validate:"required"`
+}
+
+// ReplayEventsResponseDTO is the response for replaying events
+type ReplayEventsResponseDTO struct {
+	Since time.Time `json:"since"`
 }
 
 // PendingEventsResponseDTO is the response for pending events query
@@ -142,6 +163,7 @@ func (a *Agent) ToDTO() *AgentDTO {
 		TriggerType:    a.TriggerType,
 		ReactionConfig: a.ReactionConfig,
 		ExecutionMode:  a.ExecutionMode,
+		AutoApplyRules: a.AutoApplyRules,
 		Capabilities:   a.Capabilities,
 		Config:         a.Config,
 		Description:    a.Description,
@@ -209,6 +231,7 @@ type AgentDefinitionDTO struct {
 	Model           *ModelConfig    `json:"model,omitempty"`
 	Tools           []string        `json:"tools"`
 	FlowType        AgentFlowType   `json:"flowType"`
+	FlowConfig      *FlowConfig     `json:"flowConfig,omitempty"`
 	IsDefault       bool            `json:"isDefault"`
 	MaxSteps        *int            `json:"maxSteps,omitempty"`
 	DefaultTimeout  *int            `json:"defaultTimeout,omitempty"`
@@ -216,6 +239,8 @@ type AgentDefinitionDTO struct {
 	ACPConfig       *ACPConfig      `json:"acpConfig,omitempty"`
 	Config          map[string]any  `json:"config,omitempty"`
 	WorkspaceConfig map[string]any  `json:"workspaceConfig,omitempty"`
+	ToolPolicies    ToolPolicies    `json:"toolPolicies,omitempty"`
+	InputSchema     map[string]any  `json:"inputSchema,omitempty"`
 	CreatedAt       time.Time       `json:"createdAt"`
 	UpdatedAt       time.Time       `json:"updatedAt"`
 }
@@ -243,6 +268,7 @@ type CreateAgentDefinitionDTO struct {
 	Model           *ModelConfig    `json:"model"`
 	Tools           []string        `json:"tools"`
 	FlowType        AgentFlowType   `json:"flowType"`
+	FlowConfig      *FlowConfig     `json:"flowConfig"`
 	IsDefault       *bool           `json:"isDefault"`
 	MaxSteps        *int            `json:"maxSteps"`
 	DefaultTimeout  *int            `json:"defaultTimeout"`
@@ -250,6 +276,8 @@ type CreateAgentDefinitionDTO struct {
 	ACPConfig       *ACPConfig      `json:"acpConfig"`
 	Config          map[string]any  `json:"config"`
 	WorkspaceConfig map[string]any  `json:"workspaceConfig"`
+	ToolPolicies    ToolPolicies    `json:"toolPolicies"`
+	InputSchema     map[string]any  `json:"inputSchema"`
 }
 
 // UpdateAgentDefinitionDTO is the request DTO for updating an agent definition
@@ -260,6 +288,7 @@ type UpdateAgentDefinitionDTO struct {
 	Model           *ModelConfig     `json:"model"`
 	Tools           []string         `json:"tools"`
 	FlowType        *AgentFlowType   `json:"flowType"`
+	FlowConfig      *FlowConfig      `json:"flowConfig"`
 	IsDefault       *bool            `json:"isDefault"`
 	MaxSteps        *int             `json:"maxSteps"`
 	DefaultTimeout  *int             `json:"defaultTimeout"`
@@ -267,6 +296,126 @@ type UpdateAgentDefinitionDTO struct {
 	ACPConfig       *ACPConfig       `json:"acpConfig"`
 	Config          map[string]any   `json:"config"`
 	WorkspaceConfig map[string]any   `json:"workspaceConfig"`
+	ToolPolicies    ToolPolicies     `json:"toolPolicies"`
+	InputSchema     map[string]any   `json:"inputSchema"`
+}
+
+// --- Agent Change Set DTOs ---
+
+// AgentChangeSetDTO is the response DTO for a staged change set
+type AgentChangeSetDTO struct {
+	ID          string          `json:"id"`
+	ProjectID   string          `json:"projectId"`
+	AgentID     *string         `json:"agentId,omitempty"`
+	RunID       string          `json:"runId"`
+	Status      ChangeSetStatus `json:"status"`
+	Changes     []StagedChange  `json:"changes"`
+	TaskID      *string         `json:"taskId,omitempty"`
+	ReviewedBy  *string         `json:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time      `json:"reviewedAt,omitempty"`
+	ReviewNotes *string         `json:"reviewNotes,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// ReviewChangeSetRequest is the request body for approving or rejecting a change set
+type ReviewChangeSetRequest struct {
+	// ChangeSeqs optionally limits approval to these changes; the rest are rejected
+	ChangeSeqs []int   `json:"changeSeqs,omitempty"`
+	Notes      *string `json:"notes,omitempty"`
+}
+
+// --- Agent Memory DTOs ---
+
+// AgentMemoryDTO is the response DTO for a long-term agent memory
+type AgentMemoryDTO struct {
+	ID                string          `json:"id"`
+	ProjectID         string          `json:"projectId"`
+	AgentDefinitionID string          `json:"agentDefinitionId"`
+	UserID            *string         `json:"userId,omitempty"`
+	Kind              AgentMemoryKind `json:"kind"`
+	Content           string          `json:"content"`
+	SourceRunID       *string         `json:"sourceRunId,omitempty"`
+	RecallCount       int             `json:"recallCount"`
+	LastRecalledAt    *time.Time      `json:"lastRecalledAt,omitempty"`
+	CreatedAt         time.Time       `json:"createdAt"`
+	UpdatedAt         time.Time       `json:"updatedAt"`
+}
+
+// PurgeMemoriesRequest is the request body for purging agent memories.
+// Omitted filters match everything; an empty body purges the whole project.
+type PurgeMemoriesRequest struct {
+	AgentDefinitionID *string          `json:"agentDefinitionId,omitempty"`
+	UserID            *string          `json:"userId,omitempty"`
+	Kind              *AgentMemoryKind `json:"kind,omitempty"`
+}
+
+// PurgeMemoriesResponseDTO reports how many memories a purge removed
+type PurgeMemoriesResponseDTO struct {
+	Deleted int `json:"deleted"`
+}
+
+// --- Agent Eval DTOs ---
+
+// AgentEvalSuiteDTO is the response DTO for an evaluation suite
+type AgentEvalSuiteDTO struct {
+	ID                string         `json:"id"`
+	ProjectID         string         `json:"projectId"`
+	AgentDefinitionID string         `json:"agentDefinitionId"`
+	Name              string         `json:"name"`
+	Description       *string        `json:"description,omitempty"`
+	Scenarios         []EvalScenario `json:"scenarios"`
+	JudgeModel        *string        `json:"judgeModel,omitempty"`
+	PassThreshold     *float64       `json:"passThreshold,omitempty"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+}
+
+// CreateAgentEvalSuiteDTO is the request body for creating an evaluation suite
+type CreateAgentEvalSuiteDTO struct {
+	AgentDefinitionID string         `json:"agentDefinitionId"`
+	Name              string         `json:"name"`
+	Description       *string        `json:"description,omitempty"`
+	Scenarios         []EvalScenario `json:"scenarios"`
+	JudgeModel        *string        `json:"judgeModel,omitempty"`
+	PassThreshold     *float64       `json:"passThreshold,omitempty"`
+}
+
+// UpdateAgentEvalSuiteDTO is the request body for updating an evaluation
+// suite. Omitted fields are left unchanged.
+type UpdateAgentEvalSuiteDTO struct {
+	Name          *string        `json:"name,omitempty"`
+	Description   *string        `json:"description,omitempty"`
+	Scenarios     []EvalScenario `json:"scenarios,omitempty"`
+	JudgeModel    *string        `json:"judgeModel,omitempty"`
+	PassThreshold *float64       `json:"passThreshold,omitempty"`
+}
+
+// StartEvalRunRequest is the request body for running an evaluation suite
+type StartEvalRunRequest struct {
+	// Scenarios limits the run to these scenario names
+	Scenarios []string `json:"scenarios,omitempty"`
+	// SystemPrompt evaluates an unsaved system prompt instead of the
+	// definition's
+	SystemPrompt *string `json:"systemPrompt,omitempty"`
+}
+
+// AgentEvalRunDTO is the response DTO for an evaluation run
+type AgentEvalRunDTO struct {
+	ID                 string               `json:"id"`
+	SuiteID            string               `json:"suiteId"`
+	ProjectID          string               `json:"projectId"`
+	AgentDefinitionID  string               `json:"agentDefinitionId"`
+	DefinitionHash     string               `json:"definitionHash"`
+	DefinitionSnapshot map[string]any       `json:"definitionSnapshot"`
+	Status             EvalRunStatus        `json:"status"`
+	Score              *float64             `json:"score,omitempty"`
+	Passed             int                  `json:"passed"`
+	Total              int                  `json:"total"`
+	Results            []EvalScenarioResult `json:"results"`
+	ErrorMessage       *string              `json:"errorMessage,omitempty"`
+	StartedAt          time.Time            `json:"startedAt"`
+	CompletedAt        *time.Time           `json:"completedAt,omitempty"`
 }
 
 // --- Agent Run Message / Tool Call DTOs ---
@@ -309,6 +458,7 @@ func (d *AgentDefinition) ToDTO() *AgentDefinitionDTO {
 		Model:           d.Model,
 		Tools:           d.Tools,
 		FlowType:        d.FlowType,
+		FlowConfig:      d.FlowConfig,
 		IsDefault:       d.IsDefault,
 		MaxSteps:        d.MaxSteps,
 		DefaultTimeout:  d.DefaultTimeout,
@@ -316,6 +466,8 @@ func (d *AgentDefinition) ToDTO() *AgentDefinitionDTO {
 		ACPConfig:       d.ACPConfig,
 		Config:          d.Config,
 		WorkspaceConfig: d.WorkspaceConfig,
+		ToolPolicies:    d.ToolPolicies,
+		InputSchema:     d.InputSchema,
 		CreatedAt:       d.CreatedAt,
 		UpdatedAt:       d.UpdatedAt,
 	}
@@ -338,6 +490,89 @@ func (d *AgentDefinition) ToSummaryDTO() *AgentDefinitionSummaryDTO {
 	}
 }
 
+// ToDTO converts an AgentChangeSet entity to AgentChangeSetDTO
+func (cs *AgentChangeSet) ToDTO() *AgentChangeSetDTO {
+	changes := cs.Changes
+	if changes == nil {
+		changes = []StagedChange{}
+	}
+	return &AgentChangeSetDTO{
+		ID:          cs.ID,
+		ProjectID:   cs.ProjectID,
+		AgentID:     cs.AgentID,
+		RunID:       cs.RunID,
+		Status:      cs.Status,
+		Changes:     changes,
+		TaskID:      cs.TaskID,
+		ReviewedBy:  cs.ReviewedBy,
+		ReviewedAt:  cs.ReviewedAt,
+		ReviewNotes: cs.ReviewNotes,
+		CreatedAt:   cs.CreatedAt,
+		UpdatedAt:   cs.UpdatedAt,
+	}
+}
+
+// ToDTO converts an AgentMemory entity to AgentMemoryDTO
+func (m *AgentMemory) ToDTO() *AgentMemoryDTO {
+	return &AgentMemoryDTO{
+		ID:                m.ID,
+		ProjectID:         m.ProjectID,
+		AgentDefinitionID: m.AgentDefinitionID,
+		UserID:            m.UserID,
+		Kind:              m.Kind,
+		Content:           m.Content,
+		SourceRunID:       m.SourceRunID,
+		RecallCount:       m.RecallCount,
+		LastRecalledAt:    m.LastRecalledAt,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+}
+
+// ToDTO converts an AgentEvalSuite entity to AgentEvalSuiteDTO
+func (s *AgentEvalSuite) ToDTO() *AgentEvalSuiteDTO {
+	scenarios := s.Scenarios
+	if scenarios == nil {
+		scenarios = []EvalScenario{}
+	}
+	return &AgentEvalSuiteDTO{
+		ID:                s.ID,
+		ProjectID:         s.ProjectID,
+		AgentDefinitionID: s.AgentDefinitionID,
+		Name:              s.Name,
+		Description:       s.Description,
+		Scenarios:         scenarios,
+		JudgeModel:        s.JudgeModel,
+		PassThreshold:     s.PassThreshold,
+		CreatedAt:         s.CreatedAt,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
+
+// ToDTO converts an AgentEvalRun entity to AgentEvalRunDTO
+func (r *AgentEvalRun) ToDTO() *AgentEvalRunDTO {
+	results := r.Results
+	if results == nil {
+		results = []EvalScenarioResult{}
+	}
+	return &AgentEvalRunDTO{
+		ID:                 r.ID,
+		SuiteID:            r.SuiteID,
+		ProjectID:          r.ProjectID,
+		AgentDefinitionID:  r.AgentDefinitionID,
+		DefinitionHash:     r.DefinitionHash,
+		DefinitionSnapshot: r.DefinitionSnapshot,
+		Status:             r.Status,
+		Score:              r.Score,
+		Passed:             r.Passed,
+		Total:              r.Total,
+		Results:            results,
+		ErrorMessage:       r.ErrorMessage,
+		StartedAt:          r.StartedAt,
+		CompletedAt:        r.CompletedAt,
+	}
+}
+
 // ToDTO converts an AgentRunMessage entity to AgentRunMessageDTO
 func (m *AgentRunMessage) ToDTO() *AgentRunMessageDTO {
 	return &AgentRunMessageDTO{
@@ -419,6 +654,8 @@ type AgentQuestionDTO struct {
 	RespondedAt    *time.Time            `json:"respondedAt,omitempty"`
 	Status         AgentQuestionStatus   `json:"status"`
 	NotificationID *string               `json:"notificationId,omitempty"`
+	ToolName       *string               `json:"toolName,omitempty"`
+	ToolArgs       map[string]any        `json:"toolArgs,omitempty"`
 	CreatedAt      time.Time             `json:"createdAt"`
 	UpdatedAt      time.Time             `json:"updatedAt"`
 }
@@ -437,6 +674,8 @@ func (q *AgentQuestion) ToDTO() *AgentQuestionDTO {
 		RespondedAt:    q.RespondedAt,
 		Status:         q.Status,
 		NotificationID: q.NotificationID,
+		ToolName:       q.ToolName,
+		ToolArgs:       q.ToolArgs,
 		CreatedAt:      q.CreatedAt,
 		UpdatedAt:      q.UpdatedAt,
 	}
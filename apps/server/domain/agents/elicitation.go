@@ -0,0 +1,61 @@
+package agents
+
+import (
+	"context"
+	"strings"
+
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+)
+
+// elicitedAnswer is the user's answer to a question asked through the MCP
+// client that triggered the run.
+type elicitedAnswer struct {
+	Accepted bool
+	Response string
+}
+
+// elicitAnswer asks a question through the MCP client that triggered the run,
+// which shows it in its own UI and waits for the user. It returns nil when
+// the run was not triggered by a client that can elicit, in which case the
+// question goes through the notification path instead.
+func elicitAnswer(ctx context.Context, question string, options []AgentQuestionOption) (*elicitedAnswer, error) {
+	client := mcp.ClientSessionFromContext(ctx)
+	if !client.SupportsElicitation() {
+		return nil, nil
+	}
+
+	field := mcp.ElicitProperty{Type: "string", Title: "Response"}
+	for _, opt := range options {
+		field.Enum = append(field.Enum, opt.Value)
+		field.EnumNames = append(field.EnumNames, opt.Label)
+	}
+	result, err := client.Elicit(ctx, &mcp.ElicitParams{
+		Message: question,
+		RequestedSchema: mcp.ElicitSchema{
+			Type:       "object",
+			Properties: map[string]mcp.ElicitProperty{"response": field},
+			Required:   []string{"response"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, _ := result.Content["response"].(string)
+	return &elicitedAnswer{
+		Accepted: result.Action == mcp.ElicitActionAccept && response != "",
+		Response: response,
+	}, nil
+}
+
+// elicitToolApproval asks the user of the triggering MCP client to approve a
+// tool call. asked is false when the client cannot elicit or the request
+// failed, and the approval must be requested by pausing the run.
+func elicitToolApproval(ctx context.Context, toolName string, args map[string]any) (approved, asked bool) {
+	question, options := approvalQuestion(toolName, args)
+	answer, err := elicitAnswer(ctx, question, options)
+	if err != nil || answer == nil {
+		return false, false
+	}
+	return answer.Accepted && strings.EqualFold(answer.Response, approvalApprove), true
+}
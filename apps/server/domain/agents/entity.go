@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/domain/graph"
 )
 
 // AgentTriggerType defines how an agent is triggered
@@ -80,13 +82,41 @@ const (
 	ProcessingStatusSkipped    AgentProcessingStatus = "skipped"
 )
 
-// ReactionConfig contains configuration for reaction triggers
+// ReactionConfig contains configuration for reaction triggers. Reactions fire
+// on graph object events only; relationship writes record no events.
 type ReactionConfig struct {
 	ObjectTypes          []string            `json:"objectTypes"`
 	Events               []ReactionEventType `json:"events"`
 	ConcurrencyStrategy  ConcurrencyStrategy `json:"concurrencyStrategy"`
 	IgnoreAgentTriggered bool                `json:"ignoreAgentTriggered"`
 	IgnoreSelfTriggered  bool                `json:"ignoreSelfTriggered"`
+	// Condition narrows the matching events further; nil matches all.
+	Condition *ReactionCondition `json:"condition,omitempty"`
+	// DebounceSeconds delays each delivery by this long and coalesces further
+	// events for the same object into it, so the agent runs once per window
+	// with the latest version.
+	DebounceSeconds int `json:"debounceSeconds,omitempty"`
+	// MaxConcurrentRuns caps this agent's reaction runs in flight across all
+	// objects. 0 means unlimited.
+	MaxConcurrentRuns int `json:"maxConcurrentRuns,omitempty"`
+}
+
+// ReactionCondition restricts a reaction to events whose change matches. Every
+// clause that is set must hold. A created event counts every property and
+// label of the new object as changed or added.
+type ReactionCondition struct {
+	// PropertiesChanged matches when any of these properties was added,
+	// updated or removed.
+	PropertiesChanged []string `json:"propertiesChanged,omitempty"`
+	// LabelsAdded matches when any of these labels was added; "*" matches any.
+	LabelsAdded []string `json:"labelsAdded,omitempty"`
+	// LabelsRemoved matches when any of these labels was removed; "*" matches any.
+	LabelsRemoved []string `json:"labelsRemoved,omitempty"`
+	// StatusChanged matches when the object's status changed.
+	StatusChanged bool `json:"statusChanged,omitempty"`
+	// Match holds predicates that must all hold against the event document:
+	// /type, /key, /status, /labels, /properties, /changes, /previous, /event.
+	Match []graph.Predicate `json:"match,omitempty"`
 }
 
 // AgentCapabilities defines capability restrictions for agents
@@ -98,6 +128,17 @@ type AgentCapabilities struct {
 	AllowedObjectTypes     []string `json:"allowedObjectTypes,omitempty"`
 }
 
+// AutoApplyRules selects which staged changes a hybrid mode agent applies
+// immediately. Only creates and updates are auto-applied, when every object
+// type they write is listed and their confidence meets MinConfidence (when
+// set). Deletes, restores, agent updates and changes referencing entities
+// still awaiting review are always staged; with no object types, hybrid
+// behaves like suggest.
+type AutoApplyRules struct {
+	ObjectTypes   []string `json:"objectTypes,omitempty"`
+	MinConfidence *float64 `json:"minConfidence,omitempty"`
+}
+
 // RateLimitConfig configures rate limiting for an agent webhook hook
 type RateLimitConfig struct {
 	RequestsPerMinute int `json:"requestsPerMinute"`
@@ -139,6 +180,7 @@ type Agent struct {
 	ReactionConfig *ReactionConfig    `bun:"reaction_config,type:jsonb" json:"reactionConfig"`
 	ExecutionMode  AgentExecutionMode `bun:"execution_mode,notnull,default:'execute'" json:"executionMode"`
 	Capabilities   *AgentCapabilities `bun:"capabilities,type:jsonb" json:"capabilities"`
+	AutoApplyRules *AutoApplyRules    `bun:"auto_apply_rules,type:jsonb" json:"autoApplyRules,omitempty"`
 	Config         map[string]any     `bun:"config,type:jsonb,default:'{}'" json:"config"`
 	Description    *string            `bun:"description" json:"description"`
 	LastRunAt      *time.Time         `bun:"last_run_at" json:"lastRunAt"`
@@ -207,12 +249,29 @@ type AgentProcessingLog struct {
 	CompletedAt   *time.Time            `bun:"completed_at" json:"completedAt"`
 	ErrorMessage  *string               `bun:"error_message" json:"errorMessage"`
 	ResultSummary map[string]any        `bun:"result_summary,type:jsonb" json:"resultSummary"`
+	EventID       *int64                `bun:"event_id" json:"eventId,omitempty"` // kb.entity_event_outbox row; nil for manual batch triggers
+	AttemptCount  int                   `bun:"attempt_count,notnull,default:0" json:"attemptCount"`
+	NextAttemptAt *time.Time            `bun:"next_attempt_at" json:"nextAttemptAt,omitempty"`
+	RunID         *string               `bun:"run_id,type:uuid" json:"runId,omitempty"`
 	CreatedAt     time.Time             `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
 
 	// Relations
 	Agent *Agent `bun:"rel:belongs-to,join:agent_id=id" json:"-"`
 }
 
+// AgentEventCursor is a reaction agent's read position in the entity event
+// outbox. Events up to (LastTxID, LastEventID) have been queued in
+// kb.agent_processing_log.
+// Table: kb.agent_event_cursors
+type AgentEventCursor struct {
+	bun.BaseModel `bun:"table:kb.agent_event_cursors,alias:aec"`
+
+	AgentID     string    `bun:"agent_id,pk,type:uuid" json:"agentId"`
+	LastTxID    int64     `bun:"last_tx_id,notnull" json:"lastTxId"`
+	LastEventID int64     `bun:"last_event_id,notnull" json:"lastEventId"`
+	UpdatedAt   time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
+
 // AgentVisibility defines the visibility level of an agent definition
 type AgentVisibility string
 
@@ -231,6 +290,51 @@ const (
 	FlowTypeLoop       AgentFlowType = "loop"       // Loop until condition met
 )
 
+// FlowStep is one sub-agent of a sequential or loop flow.
+// Tools and Model inherit from the definition when unset.
+type FlowStep struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Prompt      string       `json:"prompt"`
+	Tools       []string     `json:"tools,omitempty"`
+	Model       *ModelConfig `json:"model,omitempty"`
+	// OutputKey is the session state key the step's final response is stored
+	// under, so later steps can reference it as {key} in their prompts.
+	// Defaults to the step name.
+	OutputKey string `json:"outputKey,omitempty"`
+}
+
+// FlowConfig holds the step configuration for sequential and loop flows
+type FlowConfig struct {
+	Steps []FlowStep `json:"steps"`
+	// MaxIterations caps loop flows. Defaults to (and is capped by) the
+	// definition's max steps.
+	MaxIterations *int `json:"maxIterations,omitempty"`
+}
+
+// ToolPolicyAction decides what happens to a tool call matched by a policy
+type ToolPolicyAction string
+
+const (
+	ToolPolicyAllow           ToolPolicyAction = "allow"
+	ToolPolicyRequireApproval ToolPolicyAction = "require_approval"
+	ToolPolicyDeny            ToolPolicyAction = "deny"
+)
+
+// ToolPolicy matches tool calls by tool name and, optionally, arguments.
+// Tool is an exact name or glob (e.g. "github_*"); Args maps argument names to
+// regular expressions the argument must match, so a policy can single out
+// e.g. workspace_bash calls whose command runs "git push".
+type ToolPolicy struct {
+	Tool   string            `json:"tool"`
+	Args   map[string]string `json:"args,omitempty"`
+	Action ToolPolicyAction  `json:"action"`
+}
+
+// ToolPolicies is an ordered list of tool policies; the first match wins and
+// calls matching none are allowed.
+type ToolPolicies []ToolPolicy
+
 // ACPConfig holds Agent Card Protocol metadata for externally-visible agents
 type ACPConfig struct {
 	DisplayName  string   `json:"displayName,omitempty"`
@@ -262,6 +366,7 @@ type AgentDefinition struct {
 	Model           *ModelConfig    `bun:"model,type:jsonb,default:'{}'" json:"model,omitempty"`
 	Tools           []string        `bun:"tools,array" json:"tools"`
 	FlowType        AgentFlowType   `bun:"flow_type,notnull,default:'single'" json:"flowType"`
+	FlowConfig      *FlowConfig     `bun:"flow_config,type:jsonb" json:"flowConfig,omitempty"`
 	IsDefault       bool            `bun:"is_default,notnull,default:false" json:"isDefault"`
 	MaxSteps        *int            `bun:"max_steps" json:"maxSteps,omitempty"`
 	DefaultTimeout  *int            `bun:"default_timeout" json:"defaultTimeout,omitempty"`
@@ -269,6 +374,8 @@ type AgentDefinition struct {
 	ACPConfig       *ACPConfig      `bun:"acp_config,type:jsonb" json:"acpConfig,omitempty"`
 	Config          map[string]any  `bun:"config,type:jsonb,default:'{}'" json:"config,omitempty"`
 	WorkspaceConfig map[string]any  `bun:"workspace_config,type:jsonb" json:"workspaceConfig,omitempty"`
+	ToolPolicies    ToolPolicies    `bun:"tool_policies,type:jsonb" json:"toolPolicies,omitempty"`
+	InputSchema     map[string]any  `bun:"input_schema,type:jsonb" json:"inputSchema,omitempty"` // input when called as an MCP tool
 	CreatedAt       time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
 	UpdatedAt       time.Time       `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
 }
@@ -347,7 +454,276 @@ type AgentQuestion struct {
 	CreatedAt      time.Time             `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
 	UpdatedAt      time.Time             `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
 
+	// ToolName and ToolArgs are set on approval questions: the exact tool
+	// call a tool policy is holding until the user approves or denies it.
+	ToolName *string        `bun:"tool_name" json:"toolName,omitempty"`
+	ToolArgs map[string]any `bun:"tool_args,type:jsonb" json:"toolArgs,omitempty"`
+
 	// Relations
 	Run   *AgentRun `bun:"rel:belongs-to,join:run_id=id" json:"-"`
 	Agent *Agent    `bun:"rel:belongs-to,join:agent_id=id" json:"-"`
 }
+
+// ChangeSetStatus represents the review state of an agent change set
+type ChangeSetStatus string
+
+const (
+	ChangeSetStatusPending          ChangeSetStatus = "pending"
+	ChangeSetStatusApplying         ChangeSetStatus = "applying"
+	ChangeSetStatusApplied          ChangeSetStatus = "applied"
+	ChangeSetStatusPartiallyApplied ChangeSetStatus = "partially_applied"
+	ChangeSetStatusFailed           ChangeSetStatus = "failed"
+	ChangeSetStatusRejected         ChangeSetStatus = "rejected"
+)
+
+// StagedChangeStatus represents the state of a single staged change
+type StagedChangeStatus string
+
+const (
+	StagedChangeStatusPending  StagedChangeStatus = "pending"
+	StagedChangeStatusApplied  StagedChangeStatus = "applied"
+	StagedChangeStatusFailed   StagedChangeStatus = "failed"
+	StagedChangeStatusRejected StagedChangeStatus = "rejected"
+)
+
+// StagedChange is a mutating graph tool call held back for review
+type StagedChange struct {
+	Seq        int            `json:"seq"`
+	ToolName   string         `json:"toolName"`
+	Args       map[string]any `json:"args"`
+	ObjectType string         `json:"objectType,omitempty"`
+	Confidence *float64       `json:"confidence,omitempty"`
+	// PlaceholderID is the entity ID returned to the agent for a staged
+	// create_entity. Later changes referencing it are rewritten to the real
+	// ID when the change set is applied.
+	PlaceholderID string             `json:"placeholderId,omitempty"`
+	Status        StagedChangeStatus `json:"status"`
+	Result        map[string]any     `json:"result,omitempty"`
+	Error         string             `json:"error,omitempty"`
+	StagedAt      time.Time          `json:"stagedAt"`
+}
+
+// AgentChangeSet groups the graph writes staged by one agent run.
+// Table: kb.agent_change_sets
+type AgentChangeSet struct {
+	bun.BaseModel `bun:"table:kb.agent_change_sets,alias:acs"`
+
+	ID          string          `bun:"id,pk,type:uuid,default:gen_random_uuid()" json:"id"`
+	ProjectID   string          `bun:"project_id,type:uuid,notnull" json:"projectId"`
+	AgentID     *string         `bun:"agent_id,type:uuid" json:"agentId,omitempty"`
+	RunID       string          `bun:"run_id,type:uuid,notnull" json:"runId"`
+	Status      ChangeSetStatus `bun:"status,notnull,default:'pending'" json:"status"`
+	Changes     []StagedChange  `bun:"changes,type:jsonb,notnull,default:'[]'" json:"changes"`
+	TaskID      *string         `bun:"task_id,type:uuid" json:"taskId,omitempty"`
+	ReviewedBy  *string         `bun:"reviewed_by,type:uuid" json:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time      `bun:"reviewed_at" json:"reviewedAt,omitempty"`
+	ReviewNotes *string         `bun:"review_notes" json:"reviewNotes,omitempty"`
+	CreatedAt   time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt   time.Time       `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
+
+// AgentMemoryKind classifies a long-term agent memory
+type AgentMemoryKind string
+
+const (
+	MemoryKindFact       AgentMemoryKind = "fact"
+	MemoryKindPreference AgentMemoryKind = "preference"
+	MemoryKindSummary    AgentMemoryKind = "summary"
+)
+
+// IsValid reports whether k is a known memory kind.
+func (k AgentMemoryKind) IsValid() bool {
+	switch k {
+	case MemoryKindFact, MemoryKindPreference, MemoryKindSummary:
+		return true
+	}
+	return false
+}
+
+// AgentMemory is a long-term memory of an agent definition. Memories with a
+// UserID are recalled only in that user's runs. The embedding column is
+// written and queried with raw SQL.
+// Table: kb.agent_memories
+type AgentMemory struct {
+	bun.BaseModel `bun:"table:kb.agent_memories,alias:am"`
+
+	ID                string          `bun:"id,pk,type:uuid,default:gen_random_uuid()" json:"id"`
+	ProjectID         string          `bun:"project_id,type:uuid,notnull" json:"projectId"`
+	AgentDefinitionID string          `bun:"agent_definition_id,type:uuid,notnull" json:"agentDefinitionId"`
+	UserID            *string         `bun:"user_id,type:uuid" json:"userId,omitempty"`
+	Kind              AgentMemoryKind `bun:"kind,notnull,default:'fact'" json:"kind"`
+	Content           string          `bun:"content,notnull" json:"content"`
+	SourceRunID       *string         `bun:"source_run_id,type:uuid" json:"sourceRunId,omitempty"`
+	RecallCount       int             `bun:"recall_count,notnull,default:0" json:"recallCount"`
+	LastRecalledAt    *time.Time      `bun:"last_recalled_at" json:"lastRecalledAt,omitempty"`
+	CreatedAt         time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt         time.Time       `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+
+	// Distance is the cosine distance to a recall query, set by similarity search
+	Distance *float64 `bun:"distance,scanonly" json:"-"`
+}
+
+// EvalScenario is one test case of an agent evaluation suite: graph state to
+// seed, the input message, and what the run is expected to do.
+type EvalScenario struct {
+	Name  string    `json:"name"`
+	Input string    `json:"input"`
+	Seed  *EvalSeed `json:"seed,omitempty"`
+	// ExpectedToolCalls are tool calls the run must (or, when forbidden,
+	// must not) make.
+	ExpectedToolCalls []EvalToolCallExpectation `json:"expectedToolCalls,omitempty"`
+	// ExpectedGraph is the graph state after the run, evaluated against the
+	// seed plus the writes the agent made.
+	ExpectedGraph *EvalGraphExpectation `json:"expectedGraph,omitempty"`
+	// ResponseContains lists phrases the final answer must contain,
+	// case-insensitively.
+	ResponseContains []string `json:"responseContains,omitempty"`
+	// Rubric is given to an LLM judge that scores the final answer.
+	Rubric string `json:"rubric,omitempty"`
+	// ToolResults stubs tools by name: calls return the given result
+	// instead of running.
+	ToolResults map[string]map[string]any `json:"toolResults,omitempty"`
+	// ModelScript replaces the model with scripted turns, played in order.
+	ModelScript []EvalModelTurn `json:"modelScript,omitempty"`
+}
+
+// EvalSeed is the graph state created before a scenario runs and removed
+// after it. Relationships reference objects by their Ref.
+type EvalSeed struct {
+	Objects       []EvalSeedObject       `json:"objects,omitempty"`
+	Relationships []EvalSeedRelationship `json:"relationships,omitempty"`
+}
+
+// EvalSeedObject is a graph object created for a scenario.
+type EvalSeedObject struct {
+	Ref        string         `json:"ref"`
+	Type       string         `json:"type"`
+	Key        string         `json:"key,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// EvalSeedRelationship is a relationship between two seeded objects.
+type EvalSeedRelationship struct {
+	Type       string         `json:"type"`
+	Source     string         `json:"source"`
+	Target     string         `json:"target"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// EvalToolCallExpectation matches tool calls by name (or glob) and a subset
+// of their arguments.
+type EvalToolCallExpectation struct {
+	Tool      string         `json:"tool"`
+	Args      map[string]any `json:"args,omitempty"`
+	Forbidden bool           `json:"forbidden,omitempty"`
+}
+
+// EvalGraphExpectation describes the expected graph end state.
+type EvalGraphExpectation struct {
+	Objects       []EvalObjectExpectation       `json:"objects,omitempty"`
+	Relationships []EvalRelationshipExpectation `json:"relationships,omitempty"`
+}
+
+// EvalObjectExpectation matches a seeded object by Ref, or any object by
+// Type and Key, and asserts on its properties. Deleted expects the object to
+// have been deleted; Absent expects no such object to exist.
+type EvalObjectExpectation struct {
+	Ref        string         `json:"ref,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Key        string         `json:"key,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+	Deleted    bool           `json:"deleted,omitempty"`
+	Absent     bool           `json:"absent,omitempty"`
+}
+
+// EvalRelationshipExpectation expects a relationship between two objects,
+// each given as a seed Ref or an object Key.
+type EvalRelationshipExpectation struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Absent bool   `json:"absent,omitempty"`
+}
+
+// EvalModelTurn is one scripted model response: text, tool calls or both.
+type EvalModelTurn struct {
+	Text      string              `json:"text,omitempty"`
+	ToolCalls []EvalModelToolCall `json:"toolCalls,omitempty"`
+}
+
+// EvalModelToolCall is a tool call made by a scripted model turn.
+type EvalModelToolCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// AgentEvalSuite is a set of evaluation scenarios for an agent definition.
+// Table: kb.agent_eval_suites
+type AgentEvalSuite struct {
+	bun.BaseModel `bun:"table:kb.agent_eval_suites,alias:aes"`
+
+	ID                string         `bun:"id,pk,type:uuid,default:gen_random_uuid()" json:"id"`
+	ProjectID         string         `bun:"project_id,type:uuid,notnull" json:"projectId"`
+	AgentDefinitionID string         `bun:"agent_definition_id,type:uuid,notnull" json:"agentDefinitionId"`
+	Name              string         `bun:"name,notnull" json:"name"`
+	Description       *string        `bun:"description" json:"description,omitempty"`
+	Scenarios         []EvalScenario `bun:"scenarios,type:jsonb,notnull,default:'[]'" json:"scenarios"`
+	// JudgeModel names the model scoring rubrics; empty uses the default model.
+	JudgeModel *string `bun:"judge_model" json:"judgeModel,omitempty"`
+	// PassThreshold is the minimum judge score for a rubric to pass.
+	PassThreshold *float64  `bun:"pass_threshold" json:"passThreshold,omitempty"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
+
+// EvalRunStatus represents the state of an evaluation run
+type EvalRunStatus string
+
+const (
+	EvalRunStatusRunning   EvalRunStatus = "running"
+	EvalRunStatusCompleted EvalRunStatus = "completed"
+	EvalRunStatusFailed    EvalRunStatus = "failed"
+)
+
+// EvalCheck is the outcome of one assertion of a scenario. Score is 0 or 1,
+// except for rubric checks, which carry the judge's score.
+type EvalCheck struct {
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// EvalScenarioResult is the outcome of one scenario in an evaluation run.
+type EvalScenarioResult struct {
+	Scenario  string           `json:"scenario"`
+	RunID     string           `json:"runId,omitempty"`
+	Passed    bool             `json:"passed"`
+	Score     float64          `json:"score"`
+	Checks    []EvalCheck      `json:"checks"`
+	Response  string           `json:"response,omitempty"`
+	ToolCalls []ReplayDecision `json:"toolCalls,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// AgentEvalRun is one execution of an evaluation suite against a version of
+// the agent definition.
+// Table: kb.agent_eval_runs
+type AgentEvalRun struct {
+	bun.BaseModel `bun:"table:kb.agent_eval_runs,alias:aer"`
+
+	ID                 string               `bun:"id,pk,type:uuid,default:gen_random_uuid()" json:"id"`
+	SuiteID            string               `bun:"suite_id,type:uuid,notnull" json:"suiteId"`
+	ProjectID          string               `bun:"project_id,type:uuid,notnull" json:"projectId"`
+	AgentDefinitionID  string               `bun:"agent_definition_id,type:uuid,notnull" json:"agentDefinitionId"`
+	DefinitionHash     string               `bun:"definition_hash,notnull" json:"definitionHash"`
+	DefinitionSnapshot map[string]any       `bun:"definition_snapshot,type:jsonb,notnull,default:'{}'" json:"definitionSnapshot"`
+	Status             EvalRunStatus        `bun:"status,notnull,default:'running'" json:"status"`
+	Score              *float64             `bun:"score" json:"score,omitempty"`
+	Passed             int                  `bun:"passed,notnull,default:0" json:"passed"`
+	Total              int                  `bun:"total,notnull,default:0" json:"total"`
+	Results            []EvalScenarioResult `bun:"results,type:jsonb,notnull,default:'[]'" json:"results"`
+	ErrorMessage       *string              `bun:"error_message" json:"errorMessage,omitempty"`
+	StartedAt          time.Time            `bun:"started_at,nullzero,notnull,default:current_timestamp" json:"startedAt"`
+	CompletedAt        *time.Time           `bun:"completed_at" json:"completedAt,omitempty"`
+}
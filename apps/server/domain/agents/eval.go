@@ -0,0 +1,1219 @@
+package agents
+
+// Evaluation suites test an agent definition with scripted scenarios. Each
+// scenario seeds graph objects for real, runs the agent with its graph writes
+// captured instead of applied, and checks the tool calls, the resulting graph
+// state and the final answer, optionally scored by an LLM judge. Every run
+// records a fingerprint of the definition it ran against, so results can be
+// compared across prompt, model and tool changes.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/emergent-company/emergent.memory/domain/events"
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+	"github.com/emergent-company/emergent.memory/pkg/adk"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	evalTriggerSource = "eval"
+
+	// defaultEvalPassThreshold is the minimum judge score for a rubric to pass
+	// when the suite sets none.
+	defaultEvalPassThreshold = 0.7
+
+	// evalRunStaleAfter is how long a run may stay running before a new run
+	// of the same suite is allowed anyway.
+	evalRunStaleAfter = 6 * time.Hour
+)
+
+// ErrEvalRunInProgress is returned when a suite already has a run in
+// progress; concurrent runs would seed conflicting objects.
+var ErrEvalRunInProgress = errors.New("an evaluation run of this suite is already in progress")
+
+// EvalRunOptions configures an evaluation run.
+type EvalRunOptions struct {
+	// Scenarios limits the run to these scenario names. Empty runs all.
+	Scenarios []string
+	// SystemPrompt replaces the definition's system prompt, to evaluate a
+	// prompt change before saving it.
+	SystemPrompt *string
+}
+
+// EvalService runs evaluation suites against agent definitions.
+type EvalService struct {
+	repo         *Repository
+	executor     *AgentExecutor
+	tools        toolExecutor
+	modelFactory *adk.ModelFactory
+	log          *slog.Logger
+}
+
+// NewEvalService creates a new EvalService.
+func NewEvalService(repo *Repository, executor *AgentExecutor, mcpService *mcp.Service, modelFactory *adk.ModelFactory, log *slog.Logger) *EvalService {
+	return &EvalService{
+		repo:         repo,
+		executor:     executor,
+		tools:        mcpService,
+		modelFactory: modelFactory,
+		log:          log.With(logger.Scope("agents.evals")),
+	}
+}
+
+// Start records a new evaluation run of suite against def and executes its
+// scenarios in the background. The returned run is in the running state.
+func (s *EvalService) Start(ctx context.Context, suite *AgentEvalSuite, def *AgentDefinition, opts EvalRunOptions) (*AgentEvalRun, error) {
+	scenarios, err := selectEvalScenarios(suite.Scenarios, opts.Scenarios)
+	if err != nil {
+		return nil, err
+	}
+	running, err := s.repo.HasRunningEvalRun(ctx, suite.ID, evalRunStaleAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for running evaluations: %w", err)
+	}
+	if running {
+		return nil, ErrEvalRunInProgress
+	}
+	if opts.SystemPrompt != nil {
+		edited := *def
+		edited.SystemPrompt = opts.SystemPrompt
+		def = &edited
+	}
+
+	agent, err := s.repo.EnsureAgentForDefinition(ctx, def, "eval:"+def.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent for definition: %w", err)
+	}
+
+	hash, snapshot := definitionFingerprint(def)
+	run := &AgentEvalRun{
+		SuiteID:            suite.ID,
+		ProjectID:          suite.ProjectID,
+		AgentDefinitionID:  def.ID,
+		DefinitionHash:     hash,
+		DefinitionSnapshot: snapshot,
+		Status:             EvalRunStatusRunning,
+		Total:              len(scenarios),
+		Results:            []EvalScenarioResult{},
+	}
+	if err := s.repo.CreateEvalRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create eval run: %w", err)
+	}
+
+	// Run detached from the request
+	started := *run
+	go func() {
+		runCtx := auth.ContextWithProjectID(context.Background(), suite.ProjectID)
+		s.execute(runCtx, run, suite, def, agent, scenarios)
+	}()
+	return &started, nil
+}
+
+// execute runs the scenarios one after another, saving results as they
+// complete.
+func (s *EvalService) execute(ctx context.Context, run *AgentEvalRun, suite *AgentEvalSuite, def *AgentDefinition, agent *Agent, scenarios []EvalScenario) {
+	s.log.Info("running agent eval suite",
+		slog.String("eval_run_id", run.ID),
+		slog.String("suite", suite.Name),
+		slog.Int("scenarios", len(scenarios)),
+	)
+
+	var judge model.LLM
+	if needsJudge(scenarios) {
+		var err error
+		if judge, err = s.judgeModel(ctx, suite); err != nil {
+			s.fail(ctx, run, fmt.Errorf("failed to create judge model: %w", err))
+			return
+		}
+	}
+
+	threshold := defaultEvalPassThreshold
+	if suite.PassThreshold != nil {
+		threshold = *suite.PassThreshold
+	}
+
+	for _, sc := range scenarios {
+		result := s.runScenario(ctx, run, def, agent, sc, judge, threshold)
+		run.Results = append(run.Results, result)
+		if result.Passed {
+			run.Passed++
+		}
+		if err := s.repo.UpdateEvalRun(ctx, run); err != nil {
+			s.log.Warn("failed to save eval progress",
+				slog.String("eval_run_id", run.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	score := evalRunScore(run.Results)
+	now := time.Now()
+	run.Score = &score
+	run.Status = EvalRunStatusCompleted
+	run.CompletedAt = &now
+	if err := s.repo.UpdateEvalRun(ctx, run); err != nil {
+		s.log.Error("failed to complete eval run",
+			slog.String("eval_run_id", run.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// fail marks a run as failed before any scenario could run.
+func (s *EvalService) fail(ctx context.Context, run *AgentEvalRun, err error) {
+	s.log.Error("agent eval run failed",
+		slog.String("eval_run_id", run.ID),
+		slog.String("error", err.Error()),
+	)
+	msg := err.Error()
+	now := time.Now()
+	run.Status = EvalRunStatusFailed
+	run.ErrorMessage = &msg
+	run.CompletedAt = &now
+	_ = s.repo.UpdateEvalRun(ctx, run)
+}
+
+// judgeModel creates the model that scores rubrics.
+func (s *EvalService) judgeModel(ctx context.Context, suite *AgentEvalSuite) (model.LLM, error) {
+	if suite.JudgeModel != nil && *suite.JudgeModel != "" {
+		return s.modelFactory.CreateModelWithName(ctx, *suite.JudgeModel)
+	}
+	return s.modelFactory.CreateModel(ctx)
+}
+
+// runScenario seeds the scenario's graph state, runs the agent and scores
+// the outcome. Seeded objects are removed afterwards.
+func (s *EvalService) runScenario(ctx context.Context, run *AgentEvalRun, def *AgentDefinition, agent *Agent, sc EvalScenario, judge model.LLM, threshold float64) EvalScenarioResult {
+	result := EvalScenarioResult{Scenario: sc.Name, Checks: []EvalCheck{}}
+
+	// Seeds are written as the agent so reaction agents don't fire on them
+	seedCtx := events.WithActor(ctx, &events.ActorContext{ActorType: events.ActorAgent, ActorID: agent.ID})
+	seed, err := s.seed(seedCtx, run.ProjectID, sc.Seed)
+	defer s.cleanup(seedCtx, run.ProjectID, seed)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to seed graph: %s", err.Error())
+		return result
+	}
+
+	var timeout *time.Duration
+	if def.DefaultTimeout != nil && *def.DefaultTimeout > 0 {
+		d := time.Duration(*def.DefaultTimeout) * time.Second
+		timeout = &d
+	}
+	source := evalTriggerSource
+	state := newEvalState(sc)
+	executed, err := s.executor.Execute(ctx, ExecuteRequest{
+		Agent:           agent,
+		AgentDefinition: def,
+		ProjectID:       run.ProjectID,
+		UserMessage:     sc.Input,
+		MaxSteps:        def.MaxSteps,
+		Timeout:         timeout,
+		TriggerSource:   &source,
+		TriggerMetadata: map[string]any{
+			"eval_run_id":   run.ID,
+			"eval_suite_id": run.SuiteID,
+			"scenario":      sc.Name,
+		},
+		eval: state,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.RunID = executed.RunID
+	result.Response, _ = executed.Summary["final_response"].(string)
+
+	calls, err := s.repo.FindToolCallsByRunID(ctx, executed.RunID)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to load tool calls: %s", err.Error())
+		return result
+	}
+	for _, tc := range calls {
+		result.ToolCalls = append(result.ToolCalls, *toReplayDecision(tc))
+	}
+
+	runError, _ := executed.Summary["error"].(string)
+	graph := newEvalGraph(seed)
+	graph.apply(state.capturedWrites())
+	result.Checks = evaluateScenario(sc, executed.Status, runError, result.Response, calls, graph)
+
+	if sc.Rubric != "" {
+		result.Checks = append(result.Checks, rubricCheck(ctx, judge, sc, result.Response, threshold))
+	}
+	result.Score, result.Passed = scoreChecks(result.Checks)
+	return result
+}
+
+// --- Seeding ---
+
+// evalSeedObject is a seeded object and the IDs the graph assigned to it.
+type evalSeedObject struct {
+	EvalSeedObject
+	ID          string
+	CanonicalID string
+}
+
+// evalSeedRelationship is a seeded relationship and its graph ID.
+type evalSeedRelationship struct {
+	EvalSeedRelationship
+	ID       string
+	SourceID string
+	TargetID string
+}
+
+// evalSeedState is the graph state created for one scenario.
+type evalSeedState struct {
+	Objects       []evalSeedObject
+	Relationships []evalSeedRelationship
+}
+
+// seed creates the scenario's seed objects and relationships through the
+// graph MCP tools. What was created before a failure is returned so it can be
+// cleaned up.
+func (s *EvalService) seed(ctx context.Context, projectID string, seed *EvalSeed) (*evalSeedState, error) {
+	state := &evalSeedState{}
+	if seed == nil {
+		return state, nil
+	}
+
+	ids := make(map[string]string, len(seed.Objects))
+	for _, obj := range seed.Objects {
+		args := map[string]any{"type": obj.Type, "properties": obj.Properties}
+		if obj.Key != "" {
+			args["key"] = obj.Key
+		}
+		out, err := s.executeTool(ctx, projectID, "create_entity", args)
+		if err != nil {
+			return state, fmt.Errorf("object %q: %w", obj.Ref, err)
+		}
+		entity, _ := out["entity"].(map[string]any)
+		id, _ := entity["id"].(string)
+		canonicalID, _ := entity["canonical_id"].(string)
+		if id == "" {
+			return state, fmt.Errorf("object %q: create_entity returned no id", obj.Ref)
+		}
+		state.Objects = append(state.Objects, evalSeedObject{EvalSeedObject: obj, ID: id, CanonicalID: canonicalID})
+		ids[obj.Ref] = id
+	}
+
+	for _, rel := range seed.Relationships {
+		out, err := s.executeTool(ctx, projectID, "create_relationship", map[string]any{
+			"type":       rel.Type,
+			"source_id":  ids[rel.Source],
+			"target_id":  ids[rel.Target],
+			"properties": rel.Properties,
+		})
+		if err != nil {
+			return state, fmt.Errorf("relationship %s %s->%s: %w", rel.Type, rel.Source, rel.Target, err)
+		}
+		created, _ := out["relationship"].(map[string]any)
+		id, _ := created["id"].(string)
+		state.Relationships = append(state.Relationships, evalSeedRelationship{
+			EvalSeedRelationship: rel,
+			ID:                   id,
+			SourceID:             ids[rel.Source],
+			TargetID:             ids[rel.Target],
+		})
+	}
+	return state, nil
+}
+
+// cleanup deletes seeded relationships and objects. Failures are logged.
+func (s *EvalService) cleanup(ctx context.Context, projectID string, state *evalSeedState) {
+	if state == nil {
+		return
+	}
+	for _, rel := range state.Relationships {
+		if rel.ID == "" {
+			continue
+		}
+		if _, err := s.executeTool(ctx, projectID, "delete_relationship", map[string]any{"relationship_id": rel.ID}); err != nil {
+			s.log.Warn("failed to delete eval seed relationship",
+				slog.String("relationship_id", rel.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	for _, obj := range state.Objects {
+		if _, err := s.executeTool(ctx, projectID, "delete_entity", map[string]any{"entity_id": obj.ID}); err != nil {
+			s.log.Warn("failed to delete eval seed object",
+				slog.String("entity_id", obj.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// executeTool runs a builtin MCP tool and returns its result, turning error
+// results into errors.
+func (s *EvalService) executeTool(ctx context.Context, projectID, toolName string, args map[string]any) (map[string]any, error) {
+	toolResult, err := s.tools.ExecuteTool(ctx, projectID, toolName, args)
+	if err != nil {
+		return nil, err
+	}
+	out, err := convertToolResult(toolResult)
+	if err != nil {
+		return nil, err
+	}
+	if msg, ok := out["error"].(string); ok {
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return out, nil
+}
+
+// --- Sandboxed execution ---
+
+// evalState sandboxes an evaluated run: stubbed tools return their scripted
+// results, graph writes are captured instead of applied, and a model script
+// replaces the configured model.
+type evalState struct {
+	model       model.LLM
+	toolResults map[string]map[string]any
+
+	mu     sync.Mutex
+	writes []StagedChange
+}
+
+func newEvalState(sc EvalScenario) *evalState {
+	s := &evalState{toolResults: sc.ToolResults}
+	if len(sc.ModelScript) > 0 {
+		s.model = &recordedModel{turns: scriptedTurns(sc.ModelScript)}
+	}
+	return s
+}
+
+// scriptedTurns converts a model script to model responses.
+func scriptedTurns(script []EvalModelTurn) []*genai.Content {
+	turns := make([]*genai.Content, len(script))
+	for i, turn := range script {
+		content := &genai.Content{Role: genai.RoleModel}
+		if turn.Text != "" {
+			content.Parts = append(content.Parts, genai.NewPartFromText(turn.Text))
+		}
+		for _, call := range turn.ToolCalls {
+			content.Parts = append(content.Parts, genai.NewPartFromFunctionCall(call.Name, call.Args))
+		}
+		turns[i] = content
+	}
+	return turns
+}
+
+// interceptToolCall returns the result of a stubbed tool or a captured graph
+// write, or nil when the tool should run.
+func (s *evalState) interceptToolCall(toolName string, args map[string]any) map[string]any {
+	if s == nil {
+		return nil
+	}
+	if stub, ok := s.toolResults[toolName]; ok {
+		out := make(map[string]any, len(stub))
+		for k, v := range stub {
+			out[k] = v
+		}
+		return out
+	}
+	// Memory writes are captured too, so evaluations leave no memories behind
+	if !mutatingGraphTools[toolName] && toolName != ToolNameRemember && toolName != ToolNameForget {
+		return nil
+	}
+
+	result := capturedWriteResult(toolName, args)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, StagedChange{
+		Seq:      len(s.writes) + 1,
+		ToolName: toolName,
+		Args:     args,
+		Status:   StagedChangeStatusPending,
+		Result:   result,
+		StagedAt: time.Now(),
+	})
+	return result
+}
+
+// capturedWrites returns the graph writes made by the run, in order.
+func (s *evalState) capturedWrites() []StagedChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StagedChange(nil), s.writes...)
+}
+
+// capturedWriteResult builds the result reported for a captured graph
+// write, shaped like the tool's real result. Created objects and
+// relationships get generated IDs the agent can keep using.
+func capturedWriteResult(toolName string, args map[string]any) map[string]any {
+	switch toolName {
+	case "create_entity":
+		return map[string]any{"success": true, "entity": capturedEntity(args)}
+	case "create_relationship":
+		return map[string]any{"success": true, "relationship": capturedRelationship(args)}
+	case "batch_create_entities", "batch_create_relationships":
+		listKey, itemKey := "entities", "entity"
+		if toolName == "batch_create_relationships" {
+			listKey, itemKey = "relationships", "relationship"
+		}
+		items, _ := args[listKey].([]any)
+		results := make([]any, 0, len(items))
+		for i, raw := range items {
+			item, _ := raw.(map[string]any)
+			created := capturedEntity(item)
+			if itemKey == "relationship" {
+				created = capturedRelationship(item)
+			}
+			results = append(results, map[string]any{"success": true, "index": i, itemKey: created})
+		}
+		return map[string]any{
+			"success": len(results),
+			"failed":  0,
+			"total":   len(items),
+			"results": results,
+		}
+	default:
+		return map[string]any{"success": true}
+	}
+}
+
+func capturedEntity(args map[string]any) map[string]any {
+	id := uuid.New().String()
+	return map[string]any{
+		"id":           id,
+		"canonical_id": id,
+		"type":         args["type"],
+		"key":          args["key"],
+		"properties":   args["properties"],
+		"version":      1,
+	}
+}
+
+func capturedRelationship(args map[string]any) map[string]any {
+	id := uuid.New().String()
+	return map[string]any{
+		"id":           id,
+		"canonical_id": id,
+		"type":         args["type"],
+		"source_id":    args["source_id"],
+		"target_id":    args["target_id"],
+		"version":      1,
+	}
+}
+
+// --- Graph end state ---
+
+type evalObject struct {
+	IDs        []string
+	Ref        string
+	Type       string
+	Key        string
+	Properties map[string]any
+	Deleted    bool
+}
+
+type evalRelationship struct {
+	ID       string
+	Type     string
+	SourceID string
+	TargetID string
+	Deleted  bool
+}
+
+// evalGraph is the graph state a scenario is judged on: the seed with the
+// run's captured writes applied.
+type evalGraph struct {
+	objects       []*evalObject
+	byID          map[string]*evalObject
+	relationships []*evalRelationship
+}
+
+func newEvalGraph(seed *evalSeedState) *evalGraph {
+	g := &evalGraph{byID: make(map[string]*evalObject)}
+	if seed == nil {
+		return g
+	}
+	for _, obj := range seed.Objects {
+		ids := []string{obj.ID}
+		if obj.CanonicalID != "" && obj.CanonicalID != obj.ID {
+			ids = append(ids, obj.CanonicalID)
+		}
+		g.addObject(&evalObject{IDs: ids, Ref: obj.Ref, Type: obj.Type, Key: obj.Key, Properties: copyProperties(obj.Properties)})
+	}
+	for _, rel := range seed.Relationships {
+		g.relationships = append(g.relationships, &evalRelationship{ID: rel.ID, Type: rel.Type, SourceID: rel.SourceID, TargetID: rel.TargetID})
+	}
+	return g
+}
+
+func (g *evalGraph) addObject(obj *evalObject) {
+	g.objects = append(g.objects, obj)
+	for _, id := range obj.IDs {
+		g.byID[id] = obj
+	}
+}
+
+// apply replays captured graph writes onto the graph.
+func (g *evalGraph) apply(writes []StagedChange) {
+	for _, w := range writes {
+		switch w.ToolName {
+		case "create_entity":
+			entity, _ := w.Result["entity"].(map[string]any)
+			g.createObject(w.Args, entity)
+		case "batch_create_entities":
+			items, _ := w.Args["entities"].([]any)
+			for i, result := range batchResults(w.Result) {
+				if item, ok := listItem(items, i); ok {
+					entity, _ := result["entity"].(map[string]any)
+					g.createObject(item, entity)
+				}
+			}
+		case "update_entity":
+			id, _ := w.Args["entity_id"].(string)
+			if obj := g.byID[id]; obj != nil {
+				props, _ := w.Args["properties"].(map[string]any)
+				for k, v := range props {
+					if v == nil {
+						delete(obj.Properties, k)
+					} else {
+						obj.Properties[k] = v
+					}
+				}
+			}
+		case "delete_entity", "restore_entity":
+			id, _ := w.Args["entity_id"].(string)
+			if obj := g.byID[id]; obj != nil {
+				obj.Deleted = w.ToolName == "delete_entity"
+			}
+		case "create_relationship":
+			rel, _ := w.Result["relationship"].(map[string]any)
+			g.createRelationship(w.Args, rel)
+		case "batch_create_relationships":
+			items, _ := w.Args["relationships"].([]any)
+			for i, result := range batchResults(w.Result) {
+				if item, ok := listItem(items, i); ok {
+					rel, _ := result["relationship"].(map[string]any)
+					g.createRelationship(item, rel)
+				}
+			}
+		case "delete_relationship":
+			id, _ := w.Args["relationship_id"].(string)
+			for _, rel := range g.relationships {
+				if rel.ID == id {
+					rel.Deleted = true
+				}
+			}
+		}
+	}
+}
+
+func (g *evalGraph) createObject(args, created map[string]any) {
+	id, _ := created["id"].(string)
+	objectType, _ := args["type"].(string)
+	key, _ := args["key"].(string)
+	props, _ := args["properties"].(map[string]any)
+	g.addObject(&evalObject{IDs: []string{id}, Type: objectType, Key: key, Properties: copyProperties(props)})
+}
+
+func (g *evalGraph) createRelationship(args, created map[string]any) {
+	rel := &evalRelationship{}
+	rel.ID, _ = created["id"].(string)
+	rel.Type, _ = args["type"].(string)
+	rel.SourceID, _ = args["source_id"].(string)
+	rel.TargetID, _ = args["target_id"].(string)
+	g.relationships = append(g.relationships, rel)
+}
+
+// batchResults returns the per-item results of a captured batch write; item
+// i of the result belongs to item i of the arguments.
+func batchResults(result map[string]any) []map[string]any {
+	raw, _ := result["results"].([]any)
+	out := make([]map[string]any, 0, len(raw))
+	for _, r := range raw {
+		if m, ok := r.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// findObjects returns the objects matching an expectation's identity: the
+// seeded object with its Ref, or objects of its Type and, if set, Key.
+func (g *evalGraph) findObjects(exp EvalObjectExpectation) []*evalObject {
+	var out []*evalObject
+	for _, obj := range g.objects {
+		switch {
+		case exp.Ref != "":
+			if obj.Ref != exp.Ref {
+				continue
+			}
+		case obj.Type != exp.Type || (exp.Key != "" && obj.Key != exp.Key):
+			continue
+		}
+		out = append(out, obj)
+	}
+	return out
+}
+
+// endpointIDs returns the IDs of the objects an expectation endpoint names,
+// by seed Ref or object Key.
+func (g *evalGraph) endpointIDs(name string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, obj := range g.objects {
+		if obj.Ref == name || obj.Key == name {
+			for _, id := range obj.IDs {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+func listItem(items []any, i int) (map[string]any, bool) {
+	if i >= len(items) {
+		return nil, false
+	}
+	item, ok := items[i].(map[string]any)
+	return item, ok
+}
+
+func copyProperties(props map[string]any) map[string]any {
+	out := make(map[string]any, len(props))
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
+
+// --- Scoring ---
+
+// evaluateScenario checks a finished run against a scenario's expectations,
+// except the rubric, which needs the judge.
+func evaluateScenario(sc EvalScenario, status AgentRunStatus, runError, response string, calls []*AgentRunToolCall, graph *evalGraph) []EvalCheck {
+	checks := []EvalCheck{statusCheck(status, runError)}
+
+	for _, exp := range sc.ExpectedToolCalls {
+		checks = append(checks, toolCallCheck(exp, calls))
+	}
+	if sc.ExpectedGraph != nil {
+		for _, exp := range sc.ExpectedGraph.Objects {
+			checks = append(checks, objectCheck(exp, graph))
+		}
+		for _, exp := range sc.ExpectedGraph.Relationships {
+			checks = append(checks, relationshipCheck(exp, graph))
+		}
+	}
+	lower := strings.ToLower(response)
+	for _, phrase := range sc.ResponseContains {
+		checks = append(checks, newEvalCheck(
+			fmt.Sprintf("response contains %q", phrase),
+			strings.Contains(lower, strings.ToLower(phrase)),
+			""))
+	}
+	return checks
+}
+
+func newEvalCheck(name string, passed bool, detail string) EvalCheck {
+	check := EvalCheck{Name: name, Passed: passed}
+	if passed {
+		check.Score = 1
+	} else {
+		check.Detail = detail
+	}
+	return check
+}
+
+func statusCheck(status AgentRunStatus, runError string) EvalCheck {
+	detail := fmt.Sprintf("run ended with status %s", status)
+	if runError != "" {
+		detail += ": " + runError
+	}
+	return newEvalCheck("run completed", status == RunStatusSuccess, detail)
+}
+
+func toolCallCheck(exp EvalToolCallExpectation, calls []*AgentRunToolCall) EvalCheck {
+	matched := 0
+	for _, tc := range calls {
+		if ok, _ := path.Match(exp.Tool, tc.ToolName); ok && valueContains(tc.Input, exp.Args) {
+			matched++
+		}
+	}
+	if exp.Forbidden {
+		return newEvalCheck("no call to "+exp.Tool, matched == 0,
+			fmt.Sprintf("%d matching calls were made", matched))
+	}
+	name := "calls " + exp.Tool
+	if len(exp.Args) > 0 {
+		name += " with " + canonicalArgs(exp.Args)
+	}
+	return newEvalCheck(name, matched > 0, fmt.Sprintf("no matching call among %d tool calls", len(calls)))
+}
+
+func objectCheck(exp EvalObjectExpectation, graph *evalGraph) EvalCheck {
+	desc := exp.Ref
+	if desc == "" {
+		desc = exp.Type
+		if exp.Key != "" {
+			desc += " " + exp.Key
+		}
+	}
+
+	var live, deleted []*evalObject
+	for _, obj := range graph.findObjects(exp) {
+		if !valueContains(obj.Properties, exp.Properties) {
+			continue
+		}
+		if obj.Deleted {
+			deleted = append(deleted, obj)
+		} else {
+			live = append(live, obj)
+		}
+	}
+
+	switch {
+	case exp.Absent:
+		return newEvalCheck("no object "+desc, len(live) == 0, fmt.Sprintf("%d matching objects exist", len(live)))
+	case exp.Deleted:
+		return newEvalCheck("object "+desc+" deleted", len(deleted) > 0 && len(live) == 0, "a matching object still exists")
+	default:
+		return newEvalCheck("object "+desc, len(live) > 0, "no matching object exists")
+	}
+}
+
+func relationshipCheck(exp EvalRelationshipExpectation, graph *evalGraph) EvalCheck {
+	sources, targets := graph.endpointIDs(exp.Source), graph.endpointIDs(exp.Target)
+	found := false
+	for _, rel := range graph.relationships {
+		if !rel.Deleted && rel.Type == exp.Type && sources[rel.SourceID] && targets[rel.TargetID] {
+			found = true
+			break
+		}
+	}
+	desc := fmt.Sprintf("%s -[%s]-> %s", exp.Source, exp.Type, exp.Target)
+	if exp.Absent {
+		return newEvalCheck("no relationship "+desc, !found, "the relationship exists")
+	}
+	return newEvalCheck("relationship "+desc, found, "no such relationship exists")
+}
+
+// valueContains reports whether actual contains expected: objects match when
+// every expected key matches recursively, anything else when equal as JSON.
+func valueContains(actual, expected any) bool {
+	if exp, ok := expected.(map[string]any); ok {
+		act, ok := actual.(map[string]any)
+		if !ok && len(exp) > 0 {
+			return false
+		}
+		for k, v := range exp {
+			if !valueContains(act[k], v) {
+				return false
+			}
+		}
+		return true
+	}
+	a, errA := json.Marshal(actual)
+	e, errE := json.Marshal(expected)
+	return errA == nil && errE == nil && string(a) == string(e)
+}
+
+// rubricCheck scores the final answer with the judge model.
+func rubricCheck(ctx context.Context, judge model.LLM, sc EvalScenario, response string, threshold float64) EvalCheck {
+	check := EvalCheck{Name: "rubric"}
+	if judge == nil {
+		check.Detail = "no judge model available"
+		return check
+	}
+	score, reasoning, err := judgeAnswer(ctx, judge, sc.Input, response, sc.Rubric)
+	if err != nil {
+		check.Detail = "judge failed: " + err.Error()
+		return check
+	}
+	check.Score = score
+	check.Passed = score >= threshold
+	check.Detail = reasoning
+	return check
+}
+
+const judgePrompt = `You are grading the final answer of an AI agent against a rubric.
+
+Task given to the agent:
+%s
+
+The agent's final answer:
+%s
+
+Rubric:
+%s
+
+Reply with only a JSON object of the form {"score": <number from 0 to 1>, "reasoning": "<one or two sentences>"}.`
+
+// judgeAnswer asks the judge model to score an answer against a rubric and
+// returns the score in [0, 1] with the judge's reasoning.
+func judgeAnswer(ctx context.Context, judge model.LLM, input, response, rubric string) (float64, string, error) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			genai.NewContentFromText(fmt.Sprintf(judgePrompt, input, response, rubric), genai.RoleUser),
+		},
+		Config: &genai.GenerateContentConfig{
+			Temperature:      genai.Ptr[float32](0),
+			ResponseMIMEType: "application/json",
+		},
+	}
+
+	var text strings.Builder
+	for resp, err := range judge.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return 0, "", err
+		}
+		if resp == nil || resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	return parseJudgeVerdict(text.String())
+}
+
+// parseJudgeVerdict reads the judge's JSON verdict, tolerating surrounding
+// text such as code fences. Scores are clamped to [0, 1].
+func parseJudgeVerdict(text string) (float64, string, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return 0, "", fmt.Errorf("judge reply has no JSON object: %q", truncateText(text, 200))
+	}
+	var verdict struct {
+		Score     *float64 `json:"score"`
+		Reasoning string   `json:"reasoning"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &verdict); err != nil {
+		return 0, "", fmt.Errorf("invalid judge reply: %w", err)
+	}
+	if verdict.Score == nil {
+		return 0, "", fmt.Errorf("judge reply has no score")
+	}
+	return min(max(*verdict.Score, 0), 1), verdict.Reasoning, nil
+}
+
+// scoreChecks returns a scenario's score, the mean of its check scores, and
+// whether every check passed.
+func scoreChecks(checks []EvalCheck) (float64, bool) {
+	if len(checks) == 0 {
+		return 0, false
+	}
+	total, passed := 0.0, true
+	for _, c := range checks {
+		total += c.Score
+		passed = passed && c.Passed
+	}
+	return total / float64(len(checks)), passed
+}
+
+// evalRunScore is the mean score of a run's scenarios.
+func evalRunScore(results []EvalScenarioResult) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, r := range results {
+		total += r.Score
+	}
+	return total / float64(len(results))
+}
+
+func needsJudge(scenarios []EvalScenario) bool {
+	for _, sc := range scenarios {
+		if sc.Rubric != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// selectEvalScenarios returns the named scenarios of a suite, or all of them
+// when names is empty.
+func selectEvalScenarios(scenarios []EvalScenario, names []string) ([]EvalScenario, error) {
+	if len(names) == 0 {
+		return scenarios, nil
+	}
+	byName := make(map[string]EvalScenario, len(scenarios))
+	for _, sc := range scenarios {
+		byName[sc.Name] = sc
+	}
+	out := make([]EvalScenario, 0, len(names))
+	for _, name := range names {
+		sc, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown scenario %q", name)
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
+
+// --- Validation ---
+
+// Validate checks that a suite has uniquely named scenarios with inputs, and
+// that seeds and expectations are well formed.
+func (s *AgentEvalSuite) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if s.PassThreshold != nil && (*s.PassThreshold < 0 || *s.PassThreshold > 1) {
+		return fmt.Errorf("passThreshold must be between 0 and 1")
+	}
+	if len(s.Scenarios) == 0 {
+		return fmt.Errorf("at least one scenario is required")
+	}
+	names := make(map[string]bool, len(s.Scenarios))
+	for i, sc := range s.Scenarios {
+		if sc.Name == "" {
+			return fmt.Errorf("scenarios[%d]: name is required", i)
+		}
+		if names[sc.Name] {
+			return fmt.Errorf("scenarios[%d]: duplicate name %q", i, sc.Name)
+		}
+		names[sc.Name] = true
+		if err := sc.validate(); err != nil {
+			return fmt.Errorf("scenario %q: %w", sc.Name, err)
+		}
+	}
+	return nil
+}
+
+func (sc EvalScenario) validate() error {
+	if strings.TrimSpace(sc.Input) == "" {
+		return fmt.Errorf("input is required")
+	}
+
+	refs := make(map[string]bool)
+	if sc.Seed != nil {
+		for i, obj := range sc.Seed.Objects {
+			if obj.Ref == "" || obj.Type == "" {
+				return fmt.Errorf("seed.objects[%d]: ref and type are required", i)
+			}
+			if refs[obj.Ref] {
+				return fmt.Errorf("seed.objects[%d]: duplicate ref %q", i, obj.Ref)
+			}
+			refs[obj.Ref] = true
+		}
+		for i, rel := range sc.Seed.Relationships {
+			if rel.Type == "" {
+				return fmt.Errorf("seed.relationships[%d]: type is required", i)
+			}
+			if !refs[rel.Source] || !refs[rel.Target] {
+				return fmt.Errorf("seed.relationships[%d]: source and target must be seeded object refs", i)
+			}
+		}
+	}
+
+	for i, exp := range sc.ExpectedToolCalls {
+		if exp.Tool == "" {
+			return fmt.Errorf("expectedToolCalls[%d]: tool is required", i)
+		}
+		if _, err := path.Match(exp.Tool, ""); err != nil {
+			return fmt.Errorf("expectedToolCalls[%d]: invalid tool pattern %q", i, exp.Tool)
+		}
+	}
+
+	if g := sc.ExpectedGraph; g != nil {
+		for i, exp := range g.Objects {
+			switch {
+			case exp.Ref != "" && !refs[exp.Ref]:
+				return fmt.Errorf("expectedGraph.objects[%d]: unknown seed ref %q", i, exp.Ref)
+			case exp.Ref == "" && exp.Type == "":
+				return fmt.Errorf("expectedGraph.objects[%d]: ref or type is required", i)
+			case exp.Absent && exp.Deleted:
+				return fmt.Errorf("expectedGraph.objects[%d]: absent and deleted are exclusive", i)
+			}
+		}
+		for i, exp := range g.Relationships {
+			if exp.Type == "" || exp.Source == "" || exp.Target == "" {
+				return fmt.Errorf("expectedGraph.relationships[%d]: type, source and target are required", i)
+			}
+		}
+	}
+
+	for i, turn := range sc.ModelScript {
+		if turn.Text == "" && len(turn.ToolCalls) == 0 {
+			return fmt.Errorf("modelScript[%d]: text or toolCalls is required", i)
+		}
+		for j, call := range turn.ToolCalls {
+			if call.Name == "" {
+				return fmt.Errorf("modelScript[%d].toolCalls[%d]: name is required", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// --- Version comparison ---
+
+// definitionFingerprint returns a snapshot of the definition fields that
+// affect a run's behavior, and its SHA-256. Runs with equal hashes ran the
+// same version of the definition.
+func definitionFingerprint(def *AgentDefinition) (string, map[string]any) {
+	snapshot := map[string]any{
+		"systemPrompt":    def.SystemPrompt,
+		"description":     def.Description,
+		"model":           def.Model,
+		"tools":           def.Tools,
+		"flowType":        def.FlowType,
+		"flowConfig":      def.FlowConfig,
+		"maxSteps":        def.MaxSteps,
+		"config":          def.Config,
+		"workspaceConfig": def.WorkspaceConfig,
+		"toolPolicies":    def.ToolPolicies,
+	}
+	// Round-trip so the stored snapshot and the hash see the same values
+	b, _ := json.Marshal(snapshot)
+	var normalized map[string]any
+	_ = json.Unmarshal(b, &normalized)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), normalized
+}
+
+// EvalScenarioComparison compares one scenario across two evaluation runs.
+// Status is improved, regressed, unchanged, added or removed.
+type EvalScenarioComparison struct {
+	Scenario   string   `json:"scenario"`
+	Status     string   `json:"status"`
+	BaseScore  *float64 `json:"baseScore,omitempty"`
+	Score      *float64 `json:"score,omitempty"`
+	Delta      float64  `json:"delta"`
+	BasePassed bool     `json:"basePassed"`
+	Passed     bool     `json:"passed"`
+}
+
+// EvalComparison compares an evaluation run against a base run, typically of
+// an earlier definition version.
+type EvalComparison struct {
+	BaseRunID          string                   `json:"baseRunId"`
+	RunID              string                   `json:"runId"`
+	BaseDefinitionHash string                   `json:"baseDefinitionHash"`
+	DefinitionHash     string                   `json:"definitionHash"`
+	ChangedFields      []string                 `json:"changedFields"`
+	BaseScore          float64                  `json:"baseScore"`
+	Score              float64                  `json:"score"`
+	ScoreDelta         float64                  `json:"scoreDelta"`
+	BasePassed         int                      `json:"basePassed"`
+	Passed             int                      `json:"passed"`
+	Regressions        []string                 `json:"regressions"`
+	Improvements       []string                 `json:"improvements"`
+	Scenarios          []EvalScenarioComparison `json:"scenarios"`
+}
+
+// compareEvalRuns compares run against base scenario by scenario. A scenario
+// regressed when it stopped passing or its score dropped.
+func compareEvalRuns(base, run *AgentEvalRun) *EvalComparison {
+	cmp := &EvalComparison{
+		BaseRunID:          base.ID,
+		RunID:              run.ID,
+		BaseDefinitionHash: base.DefinitionHash,
+		DefinitionHash:     run.DefinitionHash,
+		ChangedFields:      changedSnapshotFields(base.DefinitionSnapshot, run.DefinitionSnapshot),
+		BaseScore:          evalRunScore(base.Results),
+		Score:              evalRunScore(run.Results),
+		BasePassed:         base.Passed,
+		Passed:             run.Passed,
+		Regressions:        []string{},
+		Improvements:       []string{},
+		Scenarios:          []EvalScenarioComparison{},
+	}
+	cmp.ScoreDelta = cmp.Score - cmp.BaseScore
+
+	baseResults := make(map[string]EvalScenarioResult, len(base.Results))
+	for _, r := range base.Results {
+		baseResults[r.Scenario] = r
+	}
+	seen := make(map[string]bool, len(run.Results))
+	for _, r := range run.Results {
+		seen[r.Scenario] = true
+		score := r.Score
+		sc := EvalScenarioComparison{Scenario: r.Scenario, Score: &score, Passed: r.Passed, Status: "added"}
+		if b, ok := baseResults[r.Scenario]; ok {
+			baseScore := b.Score
+			sc.BaseScore = &baseScore
+			sc.BasePassed = b.Passed
+			sc.Delta = score - baseScore
+			switch {
+			case (b.Passed && !r.Passed) || (b.Passed == r.Passed && sc.Delta < 0):
+				sc.Status = "regressed"
+				cmp.Regressions = append(cmp.Regressions, r.Scenario)
+			case (!b.Passed && r.Passed) || (b.Passed == r.Passed && sc.Delta > 0):
+				sc.Status = "improved"
+				cmp.Improvements = append(cmp.Improvements, r.Scenario)
+			default:
+				sc.Status = "unchanged"
+			}
+		}
+		cmp.Scenarios = append(cmp.Scenarios, sc)
+	}
+	for _, b := range base.Results {
+		if seen[b.Scenario] {
+			continue
+		}
+		baseScore := b.Score
+		cmp.Scenarios = append(cmp.Scenarios, EvalScenarioComparison{
+			Scenario:   b.Scenario,
+			Status:     "removed",
+			BaseScore:  &baseScore,
+			BasePassed: b.Passed,
+		})
+	}
+	return cmp
+}
+
+// changedSnapshotFields lists the definition fields that differ between two
+// snapshots, sorted.
+func changedSnapshotFields(a, b map[string]any) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	changed := []string{}
+	for k := range keys {
+		x, _ := json.Marshal(a[k])
+		y, _ := json.Marshal(b[k])
+		if string(x) != string(y) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// comparisonBase picks the run to compare run against from a suite's
+// completed runs, newest first: the latest earlier run of a different
+// definition version, or else the latest earlier run.
+func comparisonBase(completed []*AgentEvalRun, run *AgentEvalRun) *AgentEvalRun {
+	var previous *AgentEvalRun
+	for _, r := range completed {
+		if r.ID == run.ID || !r.StartedAt.Before(run.StartedAt) {
+			continue
+		}
+		if r.DefinitionHash != run.DefinitionHash {
+			return r
+		}
+		if previous == nil {
+			previous = r
+		}
+	}
+	return previous
+}
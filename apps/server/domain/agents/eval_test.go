@@ -0,0 +1,339 @@
+package agents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+func validEvalSuite() *AgentEvalSuite {
+	return &AgentEvalSuite{
+		Name: "owners",
+		Scenarios: []EvalScenario{{
+			Name:  "links owner",
+			Input: "Jane owns Acme. Record it.",
+			Seed: &EvalSeed{
+				Objects: []EvalSeedObject{
+					{Ref: "acme", Type: "Company", Key: "acme"},
+					{Ref: "jane", Type: "Person", Key: "jane"},
+				},
+				Relationships: []EvalSeedRelationship{{Type: "KNOWS", Source: "jane", Target: "acme"}},
+			},
+			ExpectedToolCalls: []EvalToolCallExpectation{{Tool: "create_*"}},
+			ExpectedGraph: &EvalGraphExpectation{
+				Objects:       []EvalObjectExpectation{{Ref: "acme"}, {Type: "Person", Key: "bob", Absent: true}},
+				Relationships: []EvalRelationshipExpectation{{Type: "OWNS", Source: "jane", Target: "acme"}},
+			},
+		}},
+	}
+}
+
+func TestAgentEvalSuite_Validate(t *testing.T) {
+	require.NoError(t, validEvalSuite().Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(s *AgentEvalSuite)
+		errMsg string
+	}{
+		{"missing name", func(s *AgentEvalSuite) { s.Name = " " }, "name is required"},
+		{"no scenarios", func(s *AgentEvalSuite) { s.Scenarios = nil }, "at least one scenario"},
+		{"bad threshold", func(s *AgentEvalSuite) { v := 1.5; s.PassThreshold = &v }, "passThreshold"},
+		{"duplicate scenario", func(s *AgentEvalSuite) { s.Scenarios = append(s.Scenarios, s.Scenarios[0]) }, "duplicate name"},
+		{"missing input", func(s *AgentEvalSuite) { s.Scenarios[0].Input = "" }, "input is required"},
+		{"duplicate ref", func(s *AgentEvalSuite) { s.Scenarios[0].Seed.Objects[1].Ref = "acme" }, "duplicate ref"},
+		{"unknown relationship ref", func(s *AgentEvalSuite) { s.Scenarios[0].Seed.Relationships[0].Target = "globex" }, "must be seeded object refs"},
+		{"bad tool pattern", func(s *AgentEvalSuite) { s.Scenarios[0].ExpectedToolCalls[0].Tool = "[" }, "invalid tool pattern"},
+		{"unknown expected ref", func(s *AgentEvalSuite) { s.Scenarios[0].ExpectedGraph.Objects[0].Ref = "globex" }, "unknown seed ref"},
+		{"empty model turn", func(s *AgentEvalSuite) { s.Scenarios[0].ModelScript = []EvalModelTurn{{}} }, "text or toolCalls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := validEvalSuite()
+			tt.mutate(suite)
+			err := suite.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestEvalState_InterceptToolCall(t *testing.T) {
+	s := newEvalState(EvalScenario{ToolResults: map[string]map[string]any{"web_search": {"results": []any{"acme.com"}}}})
+
+	assert.Equal(t, map[string]any{"results": []any{"acme.com"}}, s.interceptToolCall("web_search", nil))
+	assert.Nil(t, s.interceptToolCall("search_entities", map[string]any{"query": "Acme"}), "reads run for real")
+
+	created := s.interceptToolCall("create_entity", map[string]any{"type": "Person", "key": "jane"})
+	entity, ok := created["entity"].(map[string]any)
+	require.True(t, ok)
+	assert.NotEmpty(t, entity["id"])
+	assert.Equal(t, "jane", entity["key"])
+
+	assert.Equal(t, map[string]any{"success": true}, s.interceptToolCall(ToolNameRemember, map[string]any{"content": "x"}))
+
+	writes := s.capturedWrites()
+	require.Len(t, writes, 2)
+	assert.Equal(t, "create_entity", writes[0].ToolName)
+	assert.Equal(t, 2, writes[1].Seq)
+
+	assert.Nil(t, (*evalState)(nil).interceptToolCall("create_entity", nil))
+}
+
+func TestEvalGraph_Apply(t *testing.T) {
+	seed := &evalSeedState{Objects: []evalSeedObject{
+		{EvalSeedObject: EvalSeedObject{Ref: "acme", Type: "Company", Key: "acme", Properties: map[string]any{"stage": "lead"}}, ID: "v1", CanonicalID: "c1"},
+		{EvalSeedObject: EvalSeedObject{Ref: "old", Type: "Company", Key: "old"}, ID: "v2"},
+	}}
+
+	s := newEvalState(EvalScenario{})
+	created := s.interceptToolCall("create_entity", map[string]any{"type": "Person", "key": "jane", "properties": map[string]any{"name": "Jane"}})
+	janeID := created["entity"].(map[string]any)["id"]
+	s.interceptToolCall("create_relationship", map[string]any{"type": "OWNS", "source_id": janeID, "target_id": "c1"})
+	s.interceptToolCall("update_entity", map[string]any{"entity_id": "c1", "properties": map[string]any{"stage": "customer"}})
+	s.interceptToolCall("delete_entity", map[string]any{"entity_id": "v2"})
+	s.interceptToolCall("batch_create_entities", map[string]any{"entities": []any{
+		map[string]any{"type": "Person", "key": "bob"},
+	}})
+
+	graph := newEvalGraph(seed)
+	graph.apply(s.capturedWrites())
+
+	checks := []EvalCheck{
+		objectCheck(EvalObjectExpectation{Ref: "acme", Properties: map[string]any{"stage": "customer"}}, graph),
+		objectCheck(EvalObjectExpectation{Type: "Person", Key: "jane", Properties: map[string]any{"name": "Jane"}}, graph),
+		objectCheck(EvalObjectExpectation{Type: "Person", Key: "bob"}, graph),
+		objectCheck(EvalObjectExpectation{Ref: "old", Deleted: true}, graph),
+		objectCheck(EvalObjectExpectation{Type: "Person", Key: "eve", Absent: true}, graph),
+		relationshipCheck(EvalRelationshipExpectation{Type: "OWNS", Source: "jane", Target: "acme"}, graph),
+		relationshipCheck(EvalRelationshipExpectation{Type: "OWNS", Source: "acme", Target: "jane", Absent: true}, graph),
+	}
+	for _, c := range checks {
+		assert.True(t, c.Passed, "%s: %s", c.Name, c.Detail)
+	}
+
+	failed := objectCheck(EvalObjectExpectation{Ref: "acme", Properties: map[string]any{"stage": "lead"}}, graph)
+	assert.False(t, failed.Passed)
+	assert.Zero(t, failed.Score)
+	assert.Equal(t, "no matching object exists", failed.Detail)
+}
+
+func TestEvaluateScenario(t *testing.T) {
+	sc := EvalScenario{
+		ExpectedToolCalls: []EvalToolCallExpectation{
+			{Tool: "search_*", Args: map[string]any{"query": "Acme"}},
+			{Tool: "delete_entity", Forbidden: true},
+		},
+		ResponseContains: []string{"jane"},
+	}
+	calls := []*AgentRunToolCall{
+		{ToolName: "search_entities", Input: map[string]any{"query": "Acme", "limit": float64(5)}},
+	}
+
+	checks := evaluateScenario(sc, RunStatusSuccess, "", "Acme is owned by Jane.", calls, newEvalGraph(nil))
+	require.Len(t, checks, 4)
+	for _, c := range checks {
+		assert.True(t, c.Passed, c.Name)
+	}
+	score, passed := scoreChecks(checks)
+	assert.True(t, passed)
+	assert.Equal(t, 1.0, score)
+
+	checks = evaluateScenario(sc, RunStatusError, "model unavailable", "", nil, newEvalGraph(nil))
+	assert.False(t, checks[0].Passed)
+	assert.Equal(t, "run ended with status error: model unavailable", checks[0].Detail)
+	score, passed = scoreChecks(checks)
+	assert.False(t, passed)
+	assert.Equal(t, 0.25, score, "only the forbidden call check passes")
+}
+
+func TestValueContains(t *testing.T) {
+	actual := map[string]any{"query": "Acme", "limit": float64(5), "filter": map[string]any{"type": "Company", "status": "active"}}
+
+	assert.True(t, valueContains(actual, map[string]any(nil)))
+	assert.True(t, valueContains(actual, map[string]any{"limit": 5}))
+	assert.True(t, valueContains(actual, map[string]any{"filter": map[string]any{"type": "Company"}}))
+	assert.False(t, valueContains(actual, map[string]any{"query": "acme"}))
+	assert.False(t, valueContains(actual, map[string]any{"missing": "x"}))
+	assert.False(t, valueContains(actual, map[string]any{"query": map[string]any{"x": 1}}))
+}
+
+func TestParseJudgeVerdict(t *testing.T) {
+	score, reasoning, err := parseJudgeVerdict("```json\n{\"score\": 0.8, \"reasoning\": \"Mostly correct.\"}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, 0.8, score)
+	assert.Equal(t, "Mostly correct.", reasoning)
+
+	score, _, err = parseJudgeVerdict(`{"score": 7}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score, "scores are clamped")
+
+	_, _, err = parseJudgeVerdict("looks good to me")
+	assert.Error(t, err)
+	_, _, err = parseJudgeVerdict(`{"reasoning": "no score"}`)
+	assert.Error(t, err)
+}
+
+func TestRubricCheck(t *testing.T) {
+	sc := EvalScenario{Input: "Who owns Acme?", Rubric: "Names Jane as the owner."}
+	judge := &stubModel{text: `{"score": 0.6, "reasoning": "Names Jane but hedges."}`}
+
+	check := rubricCheck(context.Background(), judge, sc, "Probably Jane.", 0.7)
+	assert.False(t, check.Passed)
+	assert.Equal(t, 0.6, check.Score)
+	assert.Equal(t, "Names Jane but hedges.", check.Detail)
+
+	assert.True(t, rubricCheck(context.Background(), judge, sc, "Probably Jane.", 0.5).Passed)
+	assert.Equal(t, "no judge model available", rubricCheck(context.Background(), nil, sc, "", 0.5).Detail)
+}
+
+func TestEvalScenario_ScriptedRun(t *testing.T) {
+	sc := EvalScenario{
+		Input:       "Find who owns Acme and record them.",
+		ToolResults: map[string]map[string]any{"web_search": {"owner": "Jane"}},
+		ModelScript: []EvalModelTurn{
+			{ToolCalls: []EvalModelToolCall{{Name: "web_search", Args: map[string]any{"query": "Acme owner"}}}},
+			{ToolCalls: []EvalModelToolCall{{Name: "create_entity", Args: map[string]any{"type": "Person", "key": "jane"}}}},
+			{Text: "Jane owns Acme."},
+		},
+		ExpectedToolCalls: []EvalToolCallExpectation{{Tool: "web_search"}, {Tool: "create_entity", Args: map[string]any{"key": "jane"}}},
+		ExpectedGraph:     &EvalGraphExpectation{Objects: []EvalObjectExpectation{{Type: "Person", Key: "jane"}}},
+		ResponseContains:  []string{"Jane"},
+	}
+	state := newEvalState(sc)
+
+	var ran int
+	newTool := func(name string) tool.Tool {
+		tl, err := functiontool.New(functiontool.Config{Name: name, Description: name},
+			func(tool.Context, map[string]any) (map[string]any, error) {
+				ran++
+				return map[string]any{}, nil
+			})
+		require.NoError(t, err)
+		return tl
+	}
+
+	var calls []*AgentRunToolCall
+	root, err := llmagent.New(llmagent.Config{
+		Name:        "researcher",
+		Model:       state.model,
+		Instruction: "You research owners.",
+		Tools:       []tool.Tool{newTool("web_search"), newTool("create_entity")},
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{
+			func(_ tool.Context, tl tool.Tool, args map[string]any) (map[string]any, error) {
+				calls = append(calls, &AgentRunToolCall{ToolName: tl.Name(), Input: args})
+				return state.interceptToolCall(tl.Name(), args), nil
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	sessions := session.InMemoryService()
+	created, err := sessions.Create(ctx, &session.CreateRequest{AppName: "agents", UserID: "system"})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{Agent: root, SessionService: sessions, AppName: "agents"})
+	require.NoError(t, err)
+
+	var final string
+	for event, err := range r.Run(ctx, "system", created.Session.ID(), genai.NewContentFromText(sc.Input, genai.RoleUser), agent.RunConfig{}) {
+		require.NoError(t, err)
+		if event.IsFinalResponse() && event.Content != nil && len(event.Content.Parts) > 0 {
+			final = event.Content.Parts[0].Text
+		}
+	}
+
+	assert.Zero(t, ran, "stubbed tools and graph writes never run")
+	graph := newEvalGraph(nil)
+	graph.apply(state.capturedWrites())
+	checks := evaluateScenario(sc, RunStatusSuccess, "", final, calls, graph)
+	for _, c := range checks {
+		assert.True(t, c.Passed, "%s: %s", c.Name, c.Detail)
+	}
+}
+
+func TestDefinitionFingerprint(t *testing.T) {
+	prompt := "You research owners."
+	def := &AgentDefinition{Name: "researcher", SystemPrompt: &prompt, Tools: []string{"search"}}
+
+	hash, snapshot := definitionFingerprint(def)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, prompt, snapshot["systemPrompt"])
+
+	renamed := *def
+	renamed.Name = "owner-researcher"
+	sameHash, _ := definitionFingerprint(&renamed)
+	assert.Equal(t, hash, sameHash, "renaming does not change behavior")
+
+	edited := "You research owners. Always cite sources."
+	renamed.SystemPrompt = &edited
+	newHash, newSnapshot := definitionFingerprint(&renamed)
+	assert.NotEqual(t, hash, newHash)
+	assert.Equal(t, []string{"systemPrompt"}, changedSnapshotFields(snapshot, newSnapshot))
+}
+
+func TestCompareEvalRuns(t *testing.T) {
+	base := &AgentEvalRun{ID: "r1", DefinitionHash: "h1", Passed: 2, Results: []EvalScenarioResult{
+		{Scenario: "owner", Passed: true, Score: 1},
+		{Scenario: "tone", Passed: true, Score: 0.9},
+		{Scenario: "dedupe", Passed: false, Score: 0.5},
+		{Scenario: "legacy", Passed: true, Score: 1},
+	}}
+	run := &AgentEvalRun{ID: "r2", DefinitionHash: "h2", Passed: 2, Results: []EvalScenarioResult{
+		{Scenario: "owner", Passed: false, Score: 0.5},
+		{Scenario: "tone", Passed: true, Score: 0.9},
+		{Scenario: "dedupe", Passed: true, Score: 1},
+		{Scenario: "new", Passed: true, Score: 1},
+	}}
+
+	cmp := compareEvalRuns(base, run)
+	assert.Equal(t, []string{"owner"}, cmp.Regressions)
+	assert.Equal(t, []string{"dedupe"}, cmp.Improvements)
+
+	statuses := make(map[string]string)
+	for _, sc := range cmp.Scenarios {
+		statuses[sc.Scenario] = sc.Status
+	}
+	assert.Equal(t, map[string]string{
+		"owner": "regressed", "tone": "unchanged", "dedupe": "improved", "new": "added", "legacy": "removed",
+	}, statuses)
+	assert.InDelta(t, 0.85, cmp.Score, 1e-9)
+	assert.InDelta(t, 0, cmp.ScoreDelta, 1e-9)
+}
+
+func TestComparisonBase(t *testing.T) {
+	now := time.Now()
+	latest := &AgentEvalRun{ID: "r3", DefinitionHash: "h2", StartedAt: now}
+	sameVersion := &AgentEvalRun{ID: "r2", DefinitionHash: "h2", StartedAt: now.Add(-time.Hour)}
+	oldVersion := &AgentEvalRun{ID: "r1", DefinitionHash: "h1", StartedAt: now.Add(-2 * time.Hour)}
+
+	assert.Equal(t, oldVersion, comparisonBase([]*AgentEvalRun{latest, sameVersion, oldVersion}, latest),
+		"prefers the previous definition version")
+	assert.Equal(t, sameVersion, comparisonBase([]*AgentEvalRun{latest, sameVersion}, latest))
+	assert.Nil(t, comparisonBase([]*AgentEvalRun{latest}, latest))
+}
+
+func TestSelectEvalScenarios(t *testing.T) {
+	scenarios := []EvalScenario{{Name: "a"}, {Name: "b"}}
+
+	all, err := selectEvalScenarios(scenarios, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	some, err := selectEvalScenarios(scenarios, []string{"b"})
+	require.NoError(t, err)
+	assert.Equal(t, []EvalScenario{{Name: "b"}}, some)
+
+	_, err = selectEvalScenarios(scenarios, []string{"c"})
+	assert.EqualError(t, err, `unknown scenario "c"`)
+}
@@ -2,8 +2,10 @@ package agents
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,16 +13,19 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/adk/agent"
-	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
 
+	"github.com/emergent-company/emergent.memory/domain/events"
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+	"github.com/emergent-company/emergent.memory/domain/provider"
 	"github.com/emergent-company/emergent.memory/domain/workspace"
 	"github.com/emergent-company/emergent.memory/internal/config"
 	"github.com/emergent-company/emergent.memory/pkg/adk"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
 	"github.com/emergent-company/emergent.memory/pkg/logger"
 	"github.com/emergent-company/emergent.memory/pkg/tracing"
 )
@@ -59,6 +64,9 @@ type ExecuteRequest struct {
 	AgentDefinition *AgentDefinition
 	ProjectID       string
 	UserMessage     string
+	// UserID is the user the run is for, if any. It scopes per-user memories
+	// and is recorded on questions asked via ask_user.
+	UserID          string
 	ParentRunID     *string
 	MaxSteps        *int
 	Timeout         *time.Duration
@@ -67,6 +75,21 @@ type ExecuteRequest struct {
 	TriggerSource   *string
 	TriggerMetadata map[string]any
 	StreamCallback  StreamCallback // Optional: enables streaming of text deltas and tool call events
+	// OnRunCreated is called with the run ID as soon as the run record
+	// exists, so callers running Execute in the background can return it.
+	OnRunCreated func(runID string)
+	// ExecutionMode and AutoApplyRules override the agent's own settings.
+	// Set for sub-agents so they inherit a suggest/hybrid parent's staging.
+	ExecutionMode  AgentExecutionMode
+	AutoApplyRules *AutoApplyRules
+
+	// replay serves a recorded run's tool results and model turns; set by Replay.
+	replay *replayState
+	// eval sandboxes a run of an evaluation scenario; set by EvalService.
+	eval *evalState
+	// approval is the answer to the approval question the resumed run paused
+	// on; set when resuming after a tool approval.
+	approval *toolApproval
 }
 
 // ExecuteResult is the outcome of an agent execution.
@@ -93,6 +116,8 @@ type AgentExecutor struct {
 	provisioner    *workspace.AutoProvisioner // nil if workspaces are disabled
 	wsEnabled      bool                       // cached feature flag
 	sessionService session.Service
+	changeSets     *ChangeSetService // stages writes in suggest/hybrid mode
+	memory         *MemoryService    // long-term memories; nil disables recall
 	log            *slog.Logger
 }
 
@@ -104,6 +129,8 @@ func NewAgentExecutor(
 	provisioner *workspace.AutoProvisioner,
 	cfg *config.Config,
 	sessionService session.Service,
+	changeSets *ChangeSetService,
+	memory *MemoryService,
 	log *slog.Logger,
 ) *AgentExecutor {
 	wsEnabled := cfg.Workspace.IsEnabled()
@@ -117,6 +144,8 @@ func NewAgentExecutor(
 		provisioner:    provisioner,
 		wsEnabled:      wsEnabled,
 		sessionService: sessionService,
+		changeSets:     changeSets,
+		memory:         memory,
 		log:            log.With(logger.Scope("agents.executor")),
 	}
 }
@@ -151,6 +180,9 @@ func (ae *AgentExecutor) Execute(ctx context.Context, req ExecuteRequest) (*Exec
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent run: %w", err)
 	}
+	if req.OnRunCreated != nil {
+		req.OnRunCreated(run.ID)
+	}
 
 	// Start OTel span now that we have the run ID
 	ctx, span := tracing.Start(ctx, "agent.run",
@@ -413,6 +445,20 @@ func (ae *AgentExecutor) runPipeline(
 	wsResult *workspace.ProvisioningResult,
 	askPauseState *AskPauseState,
 ) (*ExecuteResult, error) {
+	// Attribute graph writes made by tools to the agent, so reaction agents
+	// don't re-trigger on their own changes
+	ctx = events.WithActor(ctx, &events.ActorContext{ActorType: events.ActorAgent, ActorID: run.AgentID})
+
+	// Attribute LLM usage to this run and its project so budgets apply
+	if auth.ProjectIDFromContext(ctx) == "" && req.ProjectID != "" {
+		ctx = auth.ContextWithProjectID(ctx, req.ProjectID)
+	}
+	attribution := provider.UsageAttribution{AgentID: run.AgentID, RunID: run.ID}
+	if req.AgentDefinition != nil {
+		attribution.AgentDefinitionID = req.AgentDefinition.ID
+	}
+	ctx = provider.WithUsageAttribution(ctx, attribution)
+
 	// Identify the root session ID
 	sessionID := ae.getRootRunID(ctx, run)
 	// Apply timeout if specified
@@ -430,14 +476,30 @@ func (ae *AgentExecutor) runPipeline(
 
 	var llm model.LLM
 	var err error
-	if modelName != "" {
+	switch {
+	case req.replay != nil && req.replay.model != nil:
+		llm = req.replay.model
+	case req.eval != nil && req.eval.model != nil:
+		llm = req.eval.model
+	case modelName != "":
 		llm, err = ae.modelFactory.CreateModelWithName(ctx, modelName)
-	} else {
+	default:
 		llm, err = ae.modelFactory.CreateModel(ctx)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create LLM model: %w", err)
+		// Without a provider, a run triggered from an MCP client that
+		// supports sampling uses the client's model
+		client := mcp.ClientSessionFromContext(ctx)
+		if !client.SupportsSampling() {
+			return nil, fmt.Errorf("failed to create LLM model: %w", err)
+		}
+		ae.log.Info("no LLM provider available, sampling the MCP client's model",
+			slog.String("run_id", run.ID),
+			slog.String("error", err.Error()),
+		)
+		llm = newSamplingModel(client, modelName)
 	}
+	llm = req.replay.wrapModel(llm)
 
 	// Resolve tools from the tool pool
 	maxDepth := req.MaxDepth
@@ -501,6 +563,14 @@ func (ae *AgentExecutor) runPipeline(
 		instruction = ae.augmentInstructionWithWorkspace(instruction, wsResult)
 	}
 
+	// Recall long-term memories relevant to this run and expose the scope to
+	// the remember and forget tools
+	memScope, hasMemory := ae.memoryScope(req, run.ID)
+	if hasMemory {
+		ctx = withMemoryScope(ctx, memScope)
+		instruction += ae.recallMemories(ctx, req, memScope)
+	}
+
 	genConfig := ae.modelFactory.DefaultGenerateConfig()
 	if req.AgentDefinition != nil && req.AgentDefinition.Model != nil {
 		if req.AgentDefinition.Model.Temperature != nil {
@@ -565,7 +635,12 @@ func (ae *AgentExecutor) runPipeline(
 		return nil, nil
 	}
 
+	// In suggest/hybrid mode, mutating graph tool calls are staged for review
+	stager := ae.newChangeStager(req, run.ID)
+
 	// Set up before-tool callback for streaming ToolCallStart events
+	gate := newToolGate(req.AgentDefinition, req.approval)
+	approvalDeps := ae.askUserDeps(req, run.ID, askPauseState)
 	beforeToolCb := func(tCtx tool.Context, t tool.Tool, args map[string]any) (map[string]any, error) {
 		if req.StreamCallback != nil {
 			req.StreamCallback(StreamEvent{
@@ -574,6 +649,41 @@ func (ae *AgentExecutor) runPipeline(
 				Input: args,
 			})
 		}
+		// A replay serves the recorded result instead of running the tool
+		if served := req.replay.serveToolCall(t.Name(), args); served != nil {
+			return served, nil
+		}
+		// An evaluated run serves stubbed tools and captures graph writes
+		if captured := req.eval.interceptToolCall(t.Name(), args); captured != nil {
+			return captured, nil
+		}
+		// Tool policies refuse the call or hold it for the user's approval
+		switch gate.decide(t.Name(), args) {
+		case ToolPolicyDeny:
+			ae.log.Info("tool call denied by policy",
+				slog.String("run_id", run.ID),
+				slog.String("tool", t.Name()),
+			)
+			return deniedToolCall(t.Name()), nil
+		case ToolPolicyRequireApproval:
+			ae.log.Info("tool call requires approval",
+				slog.String("run_id", run.ID),
+				slog.String("tool", t.Name()),
+			)
+			// A run triggered from an MCP client that can elicit asks its
+			// user directly instead of pausing
+			approved, asked := elicitToolApproval(tCtx, t.Name(), args)
+			if !asked {
+				return requestToolApproval(tCtx, approvalDeps, t.Name(), args), nil
+			}
+			if !approved {
+				return deniedToolCall(t.Name()), nil
+			}
+		}
+		// A staged write reports the staging result instead of running the tool
+		if staged := stager.intercept(ctx, t.Name(), args); staged != nil {
+			return staged, nil
+		}
 		// Return nil to let the ADK framework proceed with actual tool execution.
 		// Returning a non-nil result tells the framework the callback already handled
 		// the tool call and skips tool.Run() entirely (Bug 6 fix).
@@ -650,17 +760,17 @@ func (ae *AgentExecutor) runPipeline(
 		return result, toolErr
 	}
 
-	llmAgent, err := llmagent.New(llmagent.Config{
-		Name:                  sanitizeAgentName(agentName),
-		Description:           ae.resolveDescription(req),
-		Instruction:           instruction,
-		Model:                 llm,
-		Tools:                 resolvedTools,
-		GenerateContentConfig: genConfig,
-		BeforeModelCallbacks:  []llmagent.BeforeModelCallback{beforeModelCb},
-		BeforeToolCallbacks:   []llmagent.BeforeToolCallback{beforeToolCb},
-		AfterToolCallbacks:    []llmagent.AfterToolCallback{afterToolCb},
-	})
+	rootAgent, err := ae.buildRootAgent(ctx, req, run.ID, flowAgentConfig{
+		name:        sanitizeAgentName(agentName),
+		description: ae.resolveDescription(req),
+		instruction: instruction,
+		llm:         llm,
+		tools:       resolvedTools,
+		genConfig:   genConfig,
+		beforeModel: beforeModelCb,
+		beforeTool:  beforeToolCb,
+		afterTool:   afterToolCb,
+	}, maxSteps, tracker, askPauseState)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LLM agent: %w", err)
 	}
@@ -704,7 +814,7 @@ func (ae *AgentExecutor) runPipeline(
 	}
 
 	r, err := runner.New(runner.Config{
-		Agent:          llmAgent,
+		Agent:          rootAgent,
 		SessionService: sessionService,
 		AppName:        "agents",
 	})
@@ -720,6 +830,8 @@ func (ae *AgentExecutor) runPipeline(
 
 	// Run the agent
 	var lastEvent *session.Event
+	multiStep := req.AgentDefinition != nil &&
+		(req.AgentDefinition.FlowType == FlowTypeSequential || req.AgentDefinition.FlowType == FlowTypeLoop)
 	runCfg := agent.RunConfig{}
 	if req.StreamCallback != nil {
 		runCfg.StreamingMode = agent.StreamingModeSSE
@@ -733,6 +845,22 @@ func (ae *AgentExecutor) runPipeline(
 					Error: eventErr.Error(),
 				})
 			}
+			// A hard budget limit stops the run rather than failing it
+			var budgetErr *adk.BudgetExceededError
+			if errors.As(eventErr, &budgetErr) {
+				ae.log.Warn("run stopped by LLM budget",
+					slog.String("run_id", run.ID),
+					slog.String("reason", budgetErr.Error()),
+				)
+				_ = ae.repo.CancelRunWithReason(ctx, run.ID, budgetErr.Error(), steps)
+				return &ExecuteResult{
+					RunID:    run.ID,
+					Status:   RunStatusCancelled,
+					Summary:  map[string]any{"error": budgetErr.Error(), "reason": "budget_exceeded"},
+					Steps:    steps,
+					Duration: time.Since(startTime),
+				}, nil
+			}
 			_ = ae.repo.FailRunWithSteps(ctx, run.ID, eventErr.Error(), steps)
 			return &ExecuteResult{
 				RunID:    run.ID,
@@ -764,7 +892,9 @@ func (ae *AgentExecutor) runPipeline(
 			ae.persistEventContent(ctx, run.ID, event, tracker.current())
 		}
 
-		if event.IsFinalResponse() {
+		// Multi-step flows end on exit_loop/guard events without text, so the
+		// summary uses the last final response that carries text.
+		if event.IsFinalResponse() && (!multiStep || eventHasText(event)) {
 			lastEvent = event
 		}
 	}
@@ -847,6 +977,10 @@ func (ae *AgentExecutor) runPipeline(
 		_ = ae.repo.UpdateLastRun(ctx, req.Agent.ID, string(RunStatusSuccess))
 	}
 
+	if hasMemory {
+		ae.summarizeRunToMemory(ctx, req, memScope, summary)
+	}
+
 	ae.log.Info("agent execution completed",
 		slog.String("run_id", run.ID),
 		slog.Int("steps", steps),
@@ -952,6 +1086,10 @@ func (ae *AgentExecutor) buildCoordinationTools(req ExecuteRequest, runID string
 		Depth:       req.Depth,
 		MaxDepth:    maxDepth,
 	}
+	if mode := effectiveExecutionMode(req); mode != ExecutionModeExecute {
+		deps.ExecutionMode = mode
+		deps.AutoApplyRules = effectiveAutoApplyRules(req)
+	}
 
 	var tools []tool.Tool
 
@@ -989,18 +1127,81 @@ func (ae *AgentExecutor) buildAskUserTool(req ExecuteRequest, runID string, paus
 		return nil, nil
 	}
 
-	agentID := ae.resolveAgentID(req)
+	return BuildAskUserTool(ae.askUserDeps(req, runID, pauseState))
+}
 
-	deps := AskUserToolDeps{
+// askUserDeps returns the dependencies for asking the run's user a question,
+// shared by ask_user and tool approval requests.
+func (ae *AgentExecutor) askUserDeps(req ExecuteRequest, runID string, pauseState *AskPauseState) AskUserToolDeps {
+	return AskUserToolDeps{
 		Repo:       ae.repo,
 		Logger:     ae.log,
 		ProjectID:  req.ProjectID,
-		AgentID:    agentID,
+		AgentID:    ae.resolveAgentID(req),
 		RunID:      runID,
 		PauseState: pauseState,
+		UserID:     req.UserID,
 	}
+}
 
-	return BuildAskUserTool(deps)
+// memoryScope returns the memory scope of a run. Only runs of an agent
+// definition have memories; they are per user when the definition's memory
+// config asks for it and the run is for a user.
+func (ae *AgentExecutor) memoryScope(req ExecuteRequest, runID string) (MemoryScope, bool) {
+	if ae.memory == nil || req.AgentDefinition == nil || req.AgentDefinition.ID == "" {
+		return MemoryScope{}, false
+	}
+	scope := MemoryScope{
+		ProjectID:         req.ProjectID,
+		AgentDefinitionID: req.AgentDefinition.ID,
+		RunID:             runID,
+	}
+	if memoryConfig(req.AgentDefinition).PerUser && req.UserID != "" {
+		userID := req.UserID
+		scope.UserID = &userID
+	}
+	return scope, true
+}
+
+// recallMemories returns the instruction section of memories relevant to the
+// run's user message, or "" if there are none.
+func (ae *AgentExecutor) recallMemories(ctx context.Context, req ExecuteRequest, scope MemoryScope) string {
+	cfg := memoryConfig(req.AgentDefinition)
+	mems, err := ae.memory.Recall(ctx, scope, req.UserMessage, cfg.RecallLimit)
+	if err != nil {
+		ae.log.Warn("failed to recall agent memories, continuing without them",
+			slog.String("run_id", scope.RunID),
+			slog.String("error", err.Error()),
+		)
+		return ""
+	}
+	if len(mems) > 0 {
+		ae.log.Info("agent memories recalled",
+			slog.String("run_id", scope.RunID),
+			slog.Int("count", len(mems)),
+		)
+	}
+	return formatMemories(mems)
+}
+
+// summarizeRunToMemory stores a summary memory of a successful run when the
+// definition's memory config enables it. Replays and evaluation runs are not
+// summarized.
+func (ae *AgentExecutor) summarizeRunToMemory(ctx context.Context, req ExecuteRequest, scope MemoryScope, summary map[string]any) {
+	if req.replay != nil || req.eval != nil || !memoryConfig(req.AgentDefinition).SummarizeRuns {
+		return
+	}
+	finalResponse, _ := summary["final_response"].(string)
+	if strings.TrimSpace(finalResponse) == "" {
+		return
+	}
+	content := runSummaryMemory(req.UserMessage, finalResponse, time.Now())
+	if _, err := ae.memory.Remember(ctx, scope, MemoryKindSummary, content); err != nil {
+		ae.log.Warn("failed to store run summary memory",
+			slog.String("run_id", scope.RunID),
+			slog.String("error", err.Error()),
+		)
+	}
 }
 
 // resolveAgentID returns the agent ID for the run record.
@@ -1017,6 +1218,25 @@ func (ae *AgentExecutor) resolveAgentID(req ExecuteRequest) string {
 	return "unknown"
 }
 
+// newChangeStager returns the write stager for a run, or nil when writes run
+// directly. Suggest/hybrid runs without a change set service fall back to a
+// stager that refuses writes, so they never apply changes unreviewed.
+func (ae *AgentExecutor) newChangeStager(req ExecuteRequest, runID string) *changeStager {
+	svc := ae.changeSets
+	if svc == nil {
+		mode := effectiveExecutionMode(req)
+		if mode == ExecutionModeExecute {
+			return nil
+		}
+		ae.log.Warn("change set service unavailable, graph writes will be refused",
+			slog.String("run_id", runID),
+			slog.String("execution_mode", string(mode)),
+		)
+		svc = &ChangeSetService{log: ae.log}
+	}
+	return svc.newStager(req, runID)
+}
+
 // resolveAgentName returns a display name for the agent.
 func (ae *AgentExecutor) resolveAgentName(req ExecuteRequest) string {
 	if req.AgentDefinition != nil && req.AgentDefinition.Name != "" {
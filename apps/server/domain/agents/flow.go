@@ -0,0 +1,311 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"path"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/exitlooptool"
+	"google.golang.org/genai"
+)
+
+// exitLoopToolName is the name of the ADK tool loop steps call to end the loop.
+const exitLoopToolName = "exit_loop"
+
+// Validate checks that the flow config is usable for the given flow type.
+// Single flows ignore the config; sequential and loop flows need at least one
+// step, and step names and output keys must be unique.
+func (c *FlowConfig) Validate(flowType AgentFlowType) error {
+	switch flowType {
+	case "", FlowTypeSingle:
+		return nil
+	case FlowTypeSequential, FlowTypeLoop:
+	default:
+		return fmt.Errorf("unknown flow type %q", flowType)
+	}
+
+	if c == nil || len(c.Steps) == 0 {
+		return fmt.Errorf("%s flow requires at least one step in flowConfig.steps", flowType)
+	}
+	if c.MaxIterations != nil && *c.MaxIterations < 1 {
+		return fmt.Errorf("flowConfig.maxIterations must be at least 1")
+	}
+
+	names := make(map[string]bool, len(c.Steps))
+	outputKeys := make(map[string]bool, len(c.Steps))
+	for i, step := range c.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("flowConfig.steps[%d]: name is required", i)
+		}
+		if step.Prompt == "" {
+			return fmt.Errorf("flowConfig.steps[%d] (%s): prompt is required", i, step.Name)
+		}
+		name := sanitizeAgentName(step.Name)
+		if names[name] {
+			return fmt.Errorf("flowConfig.steps[%d]: duplicate step name %q", i, step.Name)
+		}
+		names[name] = true
+
+		key := step.outputKey()
+		if outputKeys[key] {
+			return fmt.Errorf("flowConfig.steps[%d] (%s): duplicate output key %q", i, step.Name, key)
+		}
+		outputKeys[key] = true
+	}
+	return nil
+}
+
+// outputKey returns the session state key the step's response is stored under.
+func (s FlowStep) outputKey() string {
+	if s.OutputKey != "" {
+		return s.OutputKey
+	}
+	return sanitizeAgentName(s.Name)
+}
+
+// loopIterations returns the iteration cap for a loop flow. The configured
+// value is bounded by the run's step budget, which is also the default.
+func (c *FlowConfig) loopIterations(maxSteps int) uint {
+	n := maxSteps
+	if c != nil && c.MaxIterations != nil && *c.MaxIterations < n {
+		n = *c.MaxIterations
+	}
+	if n < 1 {
+		n = 1
+	}
+	return uint(n)
+}
+
+// filterToolsByName narrows tools to those matching the given names. Patterns
+// follow the definition tools whitelist: exact names, globs, or "*".
+func filterToolsByName(tools []tool.Tool, patterns []string) []tool.Tool {
+	var result []tool.Tool
+	for _, t := range tools {
+		for _, pattern := range patterns {
+			if pattern == "*" || pattern == t.Name() {
+				result = append(result, t)
+				break
+			}
+			if isGlobPattern(pattern) {
+				if ok, _ := path.Match(pattern, t.Name()); ok {
+					result = append(result, t)
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// flowAgentConfig holds what every LLM agent in a run shares, whether it is
+// the single root agent or one step of a multi-step flow.
+type flowAgentConfig struct {
+	name        string
+	description string
+	instruction string
+	llm         model.LLM
+	tools       []tool.Tool
+	genConfig   *genai.GenerateContentConfig
+
+	beforeModel llmagent.BeforeModelCallback
+	beforeTool  llmagent.BeforeToolCallback
+	afterTool   llmagent.AfterToolCallback
+}
+
+// buildRootAgent builds the agent tree for a run according to the
+// definition's flow type. Single flows (and legacy agents without a
+// definition) run one LLM agent; sequential and loop flows wrap one LLM
+// agent per configured step in the matching ADK workflow agent.
+func (ae *AgentExecutor) buildRootAgent(
+	ctx context.Context,
+	req ExecuteRequest,
+	runID string,
+	cfg flowAgentConfig,
+	maxSteps int,
+	tracker *stepTracker,
+	askPauseState *AskPauseState,
+) (agent.Agent, error) {
+	def := req.AgentDefinition
+	if def == nil || (def.FlowType != FlowTypeSequential && def.FlowType != FlowTypeLoop) {
+		return llmagent.New(llmagent.Config{
+			Name:                  cfg.name,
+			Description:           cfg.description,
+			Instruction:           cfg.instruction,
+			Model:                 cfg.llm,
+			Tools:                 cfg.tools,
+			GenerateContentConfig: cfg.genConfig,
+			BeforeModelCallbacks:  []llmagent.BeforeModelCallback{cfg.beforeModel},
+			BeforeToolCallbacks:   []llmagent.BeforeToolCallback{cfg.beforeTool},
+			AfterToolCallbacks:    []llmagent.AfterToolCallback{cfg.afterTool},
+		})
+	}
+
+	if err := def.FlowConfig.Validate(def.FlowType); err != nil {
+		return nil, fmt.Errorf("invalid flow config: %w", err)
+	}
+
+	isLoop := def.FlowType == FlowTypeLoop
+	var exitLoop tool.Tool
+	if isLoop {
+		var err error
+		if exitLoop, err = exitlooptool.New(); err != nil {
+			return nil, fmt.Errorf("failed to create exit_loop tool: %w", err)
+		}
+	}
+
+	// The first step starting marks a new loop iteration
+	var iterMu sync.Mutex
+	iteration := 0
+
+	steps := make([]agent.Agent, 0, len(def.FlowConfig.Steps)+1)
+	for i, step := range def.FlowConfig.Steps {
+		stepName := sanitizeAgentName(step.Name)
+
+		llm := cfg.llm
+		genConfig := cfg.genConfig
+		if step.Model != nil {
+			if step.Model.Name != "" {
+				stepLLM, err := ae.modelFactory.CreateModelWithName(ctx, step.Model.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create model for step %q: %w", step.Name, err)
+				}
+				llm = stepLLM
+			}
+			stepConfig := *cfg.genConfig
+			if step.Model.Temperature != nil {
+				stepConfig.Temperature = step.Model.Temperature
+			}
+			if step.Model.MaxTokens != nil {
+				stepConfig.MaxOutputTokens = int32(*step.Model.MaxTokens)
+			}
+			genConfig = &stepConfig
+		}
+
+		tools := cfg.tools
+		if step.Tools != nil {
+			tools = filterToolsByName(cfg.tools, step.Tools)
+		}
+		if isLoop {
+			tools = append(append([]tool.Tool{}, tools...), exitLoop)
+		}
+
+		instruction := cfg.instruction + "\n\n" + step.Prompt
+		if isLoop {
+			instruction += "\n\nWhen the task is complete, call the " + exitLoopToolName + " tool to finish."
+		}
+
+		stepIndex := i
+		beforeAgentCb := func(cbCtx agent.CallbackContext) (*genai.Content, error) {
+			iterMu.Lock()
+			if stepIndex == 0 {
+				iteration++
+			}
+			current := iteration
+			iterMu.Unlock()
+
+			msg := &AgentRunMessage{
+				RunID: runID,
+				Role:  "system",
+				Content: map[string]any{
+					"text":      fmt.Sprintf("Starting flow step %q", step.Name),
+					"flow_step": step.Name,
+					"iteration": current,
+				},
+				StepNumber: tracker.current(),
+			}
+			if err := ae.repo.CreateMessage(ctx, msg); err != nil {
+				ae.log.Warn("failed to persist flow step message",
+					slog.String("run_id", runID),
+					slog.String("flow_step", step.Name),
+					slog.String("error", err.Error()),
+				)
+			}
+			return nil, nil
+		}
+
+		stepAgent, err := llmagent.New(llmagent.Config{
+			Name:                  stepName,
+			Description:           step.Description,
+			Instruction:           instruction,
+			Model:                 llm,
+			Tools:                 tools,
+			GenerateContentConfig: genConfig,
+			OutputKey:             step.outputKey(),
+			BeforeAgentCallbacks:  []agent.BeforeAgentCallback{beforeAgentCb},
+			BeforeModelCallbacks:  []llmagent.BeforeModelCallback{cfg.beforeModel},
+			BeforeToolCallbacks:   []llmagent.BeforeToolCallback{cfg.beforeTool},
+			AfterToolCallbacks:    []llmagent.AfterToolCallback{cfg.afterTool},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create agent for step %q: %w", step.Name, err)
+		}
+		steps = append(steps, stepAgent)
+	}
+
+	rootConfig := agent.Config{
+		Name:        cfg.name,
+		Description: cfg.description,
+		SubAgents:   steps,
+	}
+
+	if !isLoop {
+		return sequentialagent.New(sequentialagent.Config{AgentConfig: rootConfig})
+	}
+
+	guard, err := newFlowGuardAgent(ctx, tracker, askPauseState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create flow guard agent: %w", err)
+	}
+	rootConfig.SubAgents = append(rootConfig.SubAgents, guard)
+
+	return loopagent.New(loopagent.Config{
+		AgentConfig:   rootConfig,
+		MaxIterations: def.FlowConfig.loopIterations(maxSteps),
+	})
+}
+
+// newFlowGuardAgent creates an agent that runs at the end of each loop
+// iteration and breaks the loop once the run has been paused (step limit or
+// ask_user) or cancelled, so paused runs don't spin through the remaining
+// iterations.
+func newFlowGuardAgent(ctx context.Context, tracker *stepTracker, askPauseState *AskPauseState) (agent.Agent, error) {
+	return agent.New(agent.Config{
+		Name:        "flow_guard",
+		Description: "Stops the loop when the run is paused or cancelled",
+		Run: func(invCtx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				paused := askPauseState != nil && askPauseState.ShouldPause()
+				if !tracker.exceeded() && !paused && ctx.Err() == nil {
+					return
+				}
+				event := session.NewEvent(invCtx.InvocationID())
+				event.Author = "flow_guard"
+				event.Actions.Escalate = true
+				yield(event, nil)
+			}
+		},
+	})
+}
+
+// eventHasText reports whether an event carries any text content.
+func eventHasText(event *session.Event) bool {
+	if event == nil || event.Content == nil {
+		return false
+	}
+	for _, part := range event.Content.Parts {
+		if part != nil && part.Text != "" {
+			return true
+		}
+	}
+	return false
+}
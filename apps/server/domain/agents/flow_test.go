@@ -0,0 +1,107 @@
+package agents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/exitlooptool"
+)
+
+func TestFlowConfig_Validate(t *testing.T) {
+	valid := &FlowConfig{Steps: []FlowStep{
+		{Name: "research", Prompt: "Find facts"},
+		{Name: "write", Prompt: "Write using {research}"},
+	}}
+	require.NoError(t, valid.Validate(FlowTypeSequential))
+	require.NoError(t, valid.Validate(FlowTypeLoop))
+	require.NoError(t, (*FlowConfig)(nil).Validate(FlowTypeSingle), "single flows need no config")
+
+	zero := 0
+	tests := []struct {
+		name     string
+		flowType AgentFlowType
+		cfg      *FlowConfig
+	}{
+		{name: "unknown flow type", flowType: "parallel", cfg: valid},
+		{name: "missing config", flowType: FlowTypeSequential, cfg: nil},
+		{name: "no steps", flowType: FlowTypeLoop, cfg: &FlowConfig{}},
+		{name: "missing name", flowType: FlowTypeSequential, cfg: &FlowConfig{Steps: []FlowStep{{Prompt: "p"}}}},
+		{name: "missing prompt", flowType: FlowTypeSequential, cfg: &FlowConfig{Steps: []FlowStep{{Name: "a"}}}},
+		{name: "duplicate name", flowType: FlowTypeSequential, cfg: &FlowConfig{Steps: []FlowStep{
+			{Name: "draft step", Prompt: "p"}, {Name: "draft_step", Prompt: "p"},
+		}}},
+		{name: "duplicate output key", flowType: FlowTypeSequential, cfg: &FlowConfig{Steps: []FlowStep{
+			{Name: "a", Prompt: "p", OutputKey: "out"}, {Name: "b", Prompt: "p", OutputKey: "out"},
+		}}},
+		{name: "zero iterations", flowType: FlowTypeLoop, cfg: &FlowConfig{
+			Steps: []FlowStep{{Name: "a", Prompt: "p"}}, MaxIterations: &zero,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.cfg.Validate(tt.flowType))
+		})
+	}
+}
+
+func TestFlowConfig_LoopIterations(t *testing.T) {
+	assert.Equal(t, uint(50), (&FlowConfig{}).loopIterations(50), "defaults to the step budget")
+	assert.Equal(t, uint(3), (&FlowConfig{MaxIterations: intPtr(3)}).loopIterations(50))
+	assert.Equal(t, uint(10), (&FlowConfig{MaxIterations: intPtr(100)}).loopIterations(10), "capped by the step budget")
+	assert.Equal(t, uint(1), (*FlowConfig)(nil).loopIterations(0))
+}
+
+func TestFilterToolsByName(t *testing.T) {
+	exitLoop, err := exitlooptool.New()
+	require.NoError(t, err)
+	askUser, err := BuildAskUserTool(AskUserToolDeps{PauseState: &AskPauseState{}})
+	require.NoError(t, err)
+	tools := []tool.Tool{exitLoop, askUser}
+
+	names := func(ts []tool.Tool) []string {
+		var out []string
+		for _, t := range ts {
+			out = append(out, t.Name())
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"ask_user"}, names(filterToolsByName(tools, []string{"ask_user"})))
+	assert.Equal(t, []string{"exit_loop"}, names(filterToolsByName(tools, []string{"exit_*"})))
+	assert.Equal(t, []string{"exit_loop", "ask_user"}, names(filterToolsByName(tools, []string{"*"})))
+	assert.Empty(t, filterToolsByName(tools, []string{}), "an empty list gives the step no tools")
+}
+
+func TestBuildRootAgent_FlowTypes(t *testing.T) {
+	ae := &AgentExecutor{}
+	tracker := newStepTracker(20, 0)
+	steps := &FlowConfig{Steps: []FlowStep{
+		{Name: "draft", Prompt: "Write a draft"},
+		{Name: "review", Prompt: "Review {draft}", Tools: []string{}},
+	}}
+
+	build := func(def *AgentDefinition) []string {
+		t.Helper()
+		root, err := ae.buildRootAgent(context.Background(), ExecuteRequest{AgentDefinition: def}, "run-1",
+			flowAgentConfig{name: "writer", instruction: "You write."}, 20, tracker, &AskPauseState{})
+		require.NoError(t, err)
+		assert.Equal(t, "writer", root.Name())
+		var names []string
+		for _, sub := range root.SubAgents() {
+			names = append(names, sub.Name())
+		}
+		return names
+	}
+
+	assert.Empty(t, build(nil), "legacy agents run a single LLM agent")
+	assert.Empty(t, build(&AgentDefinition{FlowType: FlowTypeSingle}))
+	assert.Equal(t, []string{"draft", "review"}, build(&AgentDefinition{FlowType: FlowTypeSequential, FlowConfig: steps}))
+	assert.Equal(t, []string{"draft", "review", "flow_guard"}, build(&AgentDefinition{FlowType: FlowTypeLoop, FlowConfig: steps}))
+
+	_, err := ae.buildRootAgent(context.Background(), ExecuteRequest{AgentDefinition: &AgentDefinition{FlowType: FlowTypeLoop}},
+		"run-1", flowAgentConfig{name: "writer"}, 20, tracker, nil)
+	assert.ErrorContains(t, err, "invalid flow config")
+}
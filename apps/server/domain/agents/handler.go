@@ -3,11 +3,13 @@ package agents
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -20,12 +22,13 @@ import (
 type Handler struct {
 	repo        *Repository
 	executor    *AgentExecutor // may be nil in tests
+	evals       *EvalService   // may be nil in tests
 	rateLimiter *WebhookRateLimiter
 }
 
 // NewHandler creates a new agents handler
-func NewHandler(repo *Repository, executor *AgentExecutor, rateLimiter *WebhookRateLimiter) *Handler {
-	return &Handler{repo: repo, executor: executor, rateLimiter: rateLimiter}
+func NewHandler(repo *Repository, executor *AgentExecutor, evals *EvalService, rateLimiter *WebhookRateLimiter) *Handler {
+	return &Handler{repo: repo, executor: executor, evals: evals, rateLimiter: rateLimiter}
 }
 
 // ListAgents handles GET /api/admin/agents
@@ -236,6 +239,12 @@ func (h *Handler) CreateAgent(c echo.Context) error {
 	if dto.ExecutionMode != "" {
 		executionMode = dto.ExecutionMode
 	}
+	if err := validateExecutionMode(executionMode, dto.AutoApplyRules); err != nil {
+		return err
+	}
+	if err := dto.ReactionConfig.Validate(); err != nil {
+		return apperror.NewBadRequest("invalid reactionConfig: " + err.Error())
+	}
 
 	config := dto.Config
 	if config == nil {
@@ -252,6 +261,7 @@ func (h *Handler) CreateAgent(c echo.Context) error {
 		TriggerType:    triggerType,
 		ReactionConfig: dto.ReactionConfig,
 		ExecutionMode:  executionMode,
+		AutoApplyRules: dto.AutoApplyRules,
 		Capabilities:   dto.Capabilities,
 		Config:         config,
 		Description:    dto.Description,
@@ -326,11 +336,20 @@ func (h *Handler) UpdateAgent(c echo.Context) error {
 		agent.TriggerType = *dto.TriggerType
 	}
 	if dto.ReactionConfig != nil {
+		if err := dto.ReactionConfig.Validate(); err != nil {
+			return apperror.NewBadRequest("invalid reactionConfig: " + err.Error())
+		}
 		agent.ReactionConfig = dto.ReactionConfig
 	}
 	if dto.ExecutionMode != nil {
 		agent.ExecutionMode = *dto.ExecutionMode
 	}
+	if dto.AutoApplyRules != nil {
+		agent.AutoApplyRules = dto.AutoApplyRules
+	}
+	if err := validateExecutionMode(agent.ExecutionMode, agent.AutoApplyRules); err != nil {
+		return err
+	}
 	if dto.Capabilities != nil {
 		agent.Capabilities = dto.Capabilities
 	}
@@ -470,6 +489,7 @@ func (h *Handler) TriggerAgent(c echo.Context) error {
 		AgentDefinition: agentDef,
 		ProjectID:       agent.ProjectID,
 		UserMessage:     userMessage,
+		UserID:          user.ID,
 	})
 	if err != nil {
 		return apperror.NewInternal("failed to execute agent", err)
@@ -553,7 +573,7 @@ func (h *Handler) CancelRun(c echo.Context) error {
 
 // GetPendingEvents handles GET /api/admin/agents/:id/pending-events
 // @Summary      Get pending events for reaction agent
-// @Description  Returns pending events (unprocessed graph objects) for a reaction agent
+// @Description  Returns events a reaction agent has yet to process: queued deliveries that are pending or in progress, and matching entity events not yet queued
 // @Tags         agents
 // @Accept       json
 // @Produce      json
@@ -742,6 +762,66 @@ func (h *Handler) BatchTrigger(c echo.Context) error {
 	}))
 }
 
+// ReplayEvents handles POST /api/admin/agents/:id/replay-events
+// @Summary      Replay events for reaction agent
+// @Description  Rewinds a reaction agent's event cursor so every matching entity event since the given time is delivered again. Events the agent already processed successfully are not re-run.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Agent ID (UUID)"
+// @Param        request body ReplayEventsDTO true "Replay request (since)"
+// @Param        X-Project-ID header string false "Project ID (optional)"
+// @Success      200 {object} APIResponse[ReplayEventsResponseDTO] "Replay scheduled"
+// @Failure      400 {object} apperror.Error "Invalid agent ID, not a reaction agent, or invalid request"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Agent not found"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/admin/agents/{id}/replay-events [post]
+// @Security     bearerAuth
+func (h *Handler) ReplayEvents(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return apperror.NewBadRequest("agent id is required")
+	}
+
+	var dto ReplayEventsDTO
+	if err := c.Bind(&dto); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+	if dto.Since.IsZero() {
+		return apperror.NewBadRequest("since is required")
+	}
+	if dto.Since.After(time.Now()) {
+		return apperror.NewBadRequest("since must not be in the future")
+	}
+
+	var projectID *string
+	if user.ProjectID != "" {
+		projectID = &user.ProjectID
+	}
+	agent, err := h.repo.FindByID(c.Request().Context(), id, projectID)
+	if err != nil {
+		return apperror.NewInternal("failed to get agent", err)
+	}
+	if agent == nil {
+		return apperror.NewNotFound("Agent", id)
+	}
+	if agent.TriggerType != TriggerTypeReaction {
+		return apperror.NewBadRequest("event replay is only available for reaction agents")
+	}
+
+	if err := h.repo.ResetEventCursor(c.Request().Context(), agent, dto.Since); err != nil {
+		return apperror.NewInternal("failed to reset event cursor", err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(ReplayEventsResponseDTO{Since: dto.Since}))
+}
+
 // --- Admin Webhook Hook Handlers ---
 
 // CreateWebhookHook handles POST /api/admin/agents/:id/hooks
@@ -1086,6 +1166,15 @@ func (h *Handler) CreateDefinition(c echo.Context) error {
 	if dto.FlowType != "" {
 		flowType = dto.FlowType
 	}
+	if err := dto.FlowConfig.Validate(flowType); err != nil {
+		return apperror.NewBadRequest(err.Error())
+	}
+	if err := dto.ToolPolicies.Validate(); err != nil {
+		return apperror.NewBadRequest(err.Error())
+	}
+	if err := validateInputSchema(dto.InputSchema); err != nil {
+		return apperror.NewBadRequest(err.Error())
+	}
 
 	visibility := VisibilityProject
 	if dto.Visibility != "" {
@@ -1115,6 +1204,7 @@ func (h *Handler) CreateDefinition(c echo.Context) error {
 		Model:           dto.Model,
 		Tools:           tools,
 		FlowType:        flowType,
+		FlowConfig:      dto.FlowConfig,
 		IsDefault:       isDefault,
 		MaxSteps:        dto.MaxSteps,
 		DefaultTimeout:  dto.DefaultTimeout,
@@ -1122,6 +1212,8 @@ func (h *Handler) CreateDefinition(c echo.Context) error {
 		ACPConfig:       dto.ACPConfig,
 		Config:          config,
 		WorkspaceConfig: dto.WorkspaceConfig,
+		ToolPolicies:    dto.ToolPolicies,
+		InputSchema:     dto.InputSchema,
 	}
 
 	if err := h.repo.CreateDefinition(c.Request().Context(), def); err != nil {
@@ -1180,6 +1272,14 @@ func (h *Handler) UpdateDefinition(c echo.Context) error {
 	if dto.FlowType != nil {
 		def.FlowType = *dto.FlowType
 	}
+	if dto.FlowConfig != nil {
+		def.FlowConfig = dto.FlowConfig
+	}
+	if dto.FlowType != nil || dto.FlowConfig != nil {
+		if err := def.FlowConfig.Validate(def.FlowType); err != nil {
+			return apperror.NewBadRequest(err.Error())
+		}
+	}
 	if dto.IsDefault != nil {
 		def.IsDefault = *dto.IsDefault
 	}
@@ -1201,6 +1301,18 @@ func (h *Handler) UpdateDefinition(c echo.Context) error {
 	if dto.WorkspaceConfig != nil {
 		def.WorkspaceConfig = dto.WorkspaceConfig
 	}
+	if dto.ToolPolicies != nil {
+		if err := dto.ToolPolicies.Validate(); err != nil {
+			return apperror.NewBadRequest(err.Error())
+		}
+		def.ToolPolicies = dto.ToolPolicies
+	}
+	if dto.InputSchema != nil {
+		if err := validateInputSchema(dto.InputSchema); err != nil {
+			return apperror.NewBadRequest(err.Error())
+		}
+		def.InputSchema = dto.InputSchema
+	}
 
 	if err := h.repo.UpdateDefinition(c.Request().Context(), def); err != nil {
 		return apperror.NewInternal("failed to update agent definition", err)
@@ -1403,6 +1515,96 @@ func (h *Handler) GetRunToolCalls(c echo.Context) error {
 	return c.JSON(http.StatusOK, SuccessResponse(dtos))
 }
 
+// ReplayRun handles POST /api/projects/:projectId/agent-runs/:runId/replay
+// @Summary      Replay an agent run
+// @Description  Re-runs the agent against a recorded run, serving tool results from the recording (or live for the listed tools), and diffs the new tool call decisions and final response against the original. forkFromStep keeps the recorded decisions before that step; prompt and systemPrompt edit the inputs.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        runId path string true "Run ID (UUID)"
+// @Param        request body ReplayRunRequest false "Replay options"
+// @Success      200 {object} APIResponse[ReplayResultDTO] "Replay result and diff"
+// @Failure      400 {object} apperror.Error "Invalid request or run still running"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Run or agent not found"
+// @Failure      503 {object} apperror.Error "Agent executor unavailable"
+// @Router       /api/projects/{projectId}/agent-runs/{runId}/replay [post]
+// @Security     bearerAuth
+func (h *Handler) ReplayRun(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return apperror.NewBadRequest("projectId is required")
+	}
+
+	runID := c.Param("runId")
+	if runID == "" {
+		return apperror.NewBadRequest("runId is required")
+	}
+
+	var req ReplayRunRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+	if req.ForkFromStep < 0 {
+		return apperror.NewBadRequest("forkFromStep must not be negative")
+	}
+
+	if h.executor == nil {
+		return apperror.New(http.StatusServiceUnavailable, "executor_unavailable", "agent executor is not available")
+	}
+
+	ctx := c.Request().Context()
+	run, err := h.repo.FindRunByIDForProject(ctx, runID, projectID)
+	if err != nil {
+		return apperror.NewInternal("failed to get agent run", err)
+	}
+	if run == nil {
+		return apperror.NewNotFound("AgentRun", runID)
+	}
+	if run.Status == RunStatusRunning {
+		return apperror.NewBadRequest("cannot replay a run that is still running")
+	}
+
+	agent, err := h.repo.FindByID(ctx, run.AgentID, &projectID)
+	if err != nil {
+		return apperror.NewInternal("failed to get agent", err)
+	}
+	if agent == nil {
+		return apperror.NewNotFound("Agent", run.AgentID)
+	}
+
+	agentDef, _ := h.repo.FindDefinitionByName(ctx, projectID, agent.Name)
+	if req.SystemPrompt != nil {
+		if agentDef == nil {
+			return apperror.NewBadRequest("systemPrompt requires the agent to have a definition")
+		}
+		edited := *agentDef
+		edited.SystemPrompt = req.SystemPrompt
+		agentDef = &edited
+	}
+
+	result, err := h.executor.Replay(ctx, run, ExecuteRequest{
+		Agent:           agent,
+		AgentDefinition: agentDef,
+		ProjectID:       projectID,
+		UserMessage:     req.Prompt,
+	}, ReplayOptions{
+		LiveTools:    req.LiveTools,
+		ForkFromStep: req.ForkFromStep,
+	})
+	if err != nil {
+		return apperror.NewInternal("failed to replay agent run", err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(result.ToDTO()))
+}
+
 // --- Workspace Config Handlers ---
 
 // GetSession handles GET /api/v1/agent/sessions/:id
@@ -1631,8 +1833,25 @@ func (h *Handler) HandleRespondToQuestion(c echo.Context) error {
 		return apperror.ErrConflict.WithMessage(fmt.Sprintf("question is already %s", question.Status))
 	}
 
+	if err := h.answerQuestion(c.Request().Context(), question, req.Response, user.ID); err != nil {
+		return err
+	}
+
+	// Re-fetch the question to return the updated state
+	updatedQuestion, err := h.repo.FindQuestionByID(c.Request().Context(), questionID)
+	if err != nil || updatedQuestion == nil {
+		// Fall back to returning what we know with the answer applied
+		return c.JSON(http.StatusAccepted, SuccessResponse(question.ToDTO()))
+	}
+
+	return c.JSON(http.StatusAccepted, SuccessResponse(updatedQuestion.ToDTO()))
+}
+
+// answerQuestion records the response to a pending question and resumes its
+// paused run in the background.
+func (h *Handler) answerQuestion(ctx context.Context, question *AgentQuestion, response, userID string) error {
 	// Look up the run and verify it's paused
-	run, err := h.repo.FindRunByID(c.Request().Context(), question.RunID)
+	run, err := h.repo.FindRunByID(ctx, question.RunID)
 	if err != nil {
 		return apperror.NewInternal("failed to get run", err)
 	}
@@ -1644,58 +1863,59 @@ func (h *Handler) HandleRespondToQuestion(c echo.Context) error {
 	}
 
 	// Update the question with the response
-	if err := h.repo.AnswerQuestion(c.Request().Context(), questionID, req.Response, user.ID); err != nil {
+	if err := h.repo.AnswerQuestion(ctx, question.ID, response, userID); err != nil {
 		return apperror.NewInternal("failed to answer question", err)
 	}
 
 	// Update notification action status if notification was created (non-fatal)
 	if question.NotificationID != nil {
-		_ = h.repo.UpdateNotificationActionStatus(c.Request().Context(), *question.NotificationID, "completed", user.ID)
+		_ = h.repo.UpdateNotificationActionStatus(ctx, *question.NotificationID, "completed", userID)
 	}
 
 	// Resume the agent in a background goroutine
-	if h.executor != nil {
-		// Look up the agent to build the resume request
-		agent, err := h.repo.FindByID(c.Request().Context(), run.AgentID, nil)
-		if err != nil || agent == nil {
-			return apperror.NewInternal("failed to find agent for resume", err)
+	if h.executor == nil {
+		return nil
+	}
+
+	// Look up the agent to build the resume request
+	agent, err := h.repo.FindByID(ctx, run.AgentID, nil)
+	if err != nil || agent == nil {
+		return apperror.NewInternal("failed to find agent for resume", err)
+	}
+
+	// Look up the agent definition (optional, may be nil)
+	agentDef, _ := h.repo.FindDefinitionByName(ctx, agent.ProjectID, agent.Name)
+
+	// Build the resume user message with Q&A context (task 5.4). An approval
+	// question instead resumes with the decision on the held tool call.
+	userMessage := fmt.Sprintf(
+		"Previously you asked: \"%s\"\nThe user responded: \"%s\"\nContinue from where you left off.",
+		question.Question, response,
+	)
+	approval := newToolApproval(question, response)
+	if approval != nil {
+		userMessage = approval.resumeMessage()
+	}
+
+	go func() {
+		ctx := context.Background()
+		_, err := h.executor.Resume(ctx, run, ExecuteRequest{
+			Agent:           agent,
+			AgentDefinition: agentDef,
+			ProjectID:       agent.ProjectID,
+			UserMessage:     userMessage,
+			UserID:          userID,
+			approval:        approval,
+		})
+		if err != nil {
+			slog.Error("failed to resume agent after question response",
+				slog.String("run_id", run.ID),
+				slog.String("question_id", question.ID),
+				slog.String("error", err.Error()),
+			)
 		}
-
-		// Look up the agent definition (optional, may be nil)
-		agentDef, _ := h.repo.FindDefinitionByName(c.Request().Context(), agent.ProjectID, agent.Name)
-
-		// Build the resume user message with Q&A context (task 5.4)
-		userMessage := fmt.Sprintf(
-			"Previously you asked: \"%s\"\nThe user responded: \"%s\"\nContinue from where you left off.",
-			question.Question, req.Response,
-		)
-
-		go func() {
-			ctx := context.Background()
-			_, err := h.executor.Resume(ctx, run, ExecuteRequest{
-				Agent:           agent,
-				AgentDefinition: agentDef,
-				ProjectID:       agent.ProjectID,
-				UserMessage:     userMessage,
-			})
-			if err != nil {
-				slog.Error("failed to resume agent after question response",
-					slog.String("run_id", run.ID),
-					slog.String("question_id", questionID),
-					slog.String("error", err.Error()),
-				)
-			}
-		}()
-	}
-
-	// Re-fetch the question to return the updated state
-	updatedQuestion, err := h.repo.FindQuestionByID(c.Request().Context(), questionID)
-	if err != nil || updatedQuestion == nil {
-		// Fall back to returning what we know with the answer applied
-		return c.JSON(http.StatusAccepted, SuccessResponse(question.ToDTO()))
-	}
-
-	return c.JSON(http.StatusAccepted, SuccessResponse(updatedQuestion.ToDTO()))
+	}()
+	return nil
 }
 
 // HandleListQuestionsByRun handles GET /api/projects/:projectId/agent-runs/:runId/questions
@@ -1907,3 +2127,776 @@ func (h *Handler) GetADKSessionByID(c echo.Context) error {
 func strPtr(s string) *string {
 	return &s
 }
+
+// validateExecutionMode checks an agent's execution mode and hybrid rules.
+func validateExecutionMode(mode AgentExecutionMode, rules *AutoApplyRules) error {
+	switch mode {
+	case ExecutionModeExecute, ExecutionModeSuggest, ExecutionModeHybrid:
+	default:
+		return apperror.NewBadRequest(fmt.Sprintf("invalid executionMode %q: must be suggest, execute or hybrid", mode))
+	}
+	if rules != nil && rules.MinConfidence != nil && (*rules.MinConfidence < 0 || *rules.MinConfidence > 1) {
+		return apperror.NewBadRequest("autoApplyRules.minConfidence must be between 0 and 1")
+	}
+	return nil
+}
+
+// --- Agent Memory Handlers ---
+
+// ListMemories handles GET /api/projects/:projectId/agent-memories
+// @Summary      List agent memories
+// @Description  Lists the long-term memories agents have stored in a project, newest first
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        agentDefinitionId query string false "Filter by agent definition ID"
+// @Param        userId query string false "Filter by user ID"
+// @Param        kind query string false "Filter by kind (fact, preference, summary)"
+// @Param        limit query int false "Max results (default 20, max 100)"
+// @Param        offset query int false "Offset for pagination"
+// @Success      200 {object} APIResponse[PaginatedResponse[AgentMemoryDTO]] "Paginated memories"
+// @Failure      400 {object} apperror.Error "Invalid kind"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Router       /api/projects/{projectId}/agent-memories [get]
+// @Security     bearerAuth
+func (h *Handler) ListMemories(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return apperror.NewBadRequest("projectId is required")
+	}
+
+	limit := 20
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	var filters MemoryFilters
+	if defID := c.QueryParam("agentDefinitionId"); defID != "" {
+		filters.AgentDefinitionID = &defID
+	}
+	if userID := c.QueryParam("userId"); userID != "" {
+		filters.UserID = &userID
+	}
+	if kindStr := c.QueryParam("kind"); kindStr != "" {
+		kind := AgentMemoryKind(kindStr)
+		if !kind.IsValid() {
+			return apperror.NewBadRequest(fmt.Sprintf("invalid kind %q: must be fact, preference or summary", kindStr))
+		}
+		filters.Kind = &kind
+	}
+
+	mems, totalCount, err := h.repo.FindMemoriesByProject(c.Request().Context(), projectID, filters, limit, offset)
+	if err != nil {
+		return apperror.NewInternal("failed to list agent memories", err)
+	}
+
+	dtos := make([]*AgentMemoryDTO, len(mems))
+	for i, m := range mems {
+		dtos[i] = m.ToDTO()
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(PaginatedResponse[*AgentMemoryDTO]{
+		Items:      dtos,
+		TotalCount: totalCount,
+		Limit:      limit,
+		Offset:     offset,
+	}))
+}
+
+// DeleteMemory handles DELETE /api/projects/:projectId/agent-memories/:memoryId
+// @Summary      Delete an agent memory
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        memoryId path string true "Memory ID (UUID)"
+// @Success      200 {object} APIResponse[any] "Memory deleted"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Memory not found"
+// @Router       /api/projects/{projectId}/agent-memories/{memoryId} [delete]
+// @Security     bearerAuth
+func (h *Handler) DeleteMemory(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	id := c.Param("memoryId")
+
+	deleted, err := h.repo.DeleteMemory(c.Request().Context(), projectID, id)
+	if err != nil {
+		return apperror.NewInternal("failed to delete agent memory", err)
+	}
+	if !deleted {
+		return apperror.NewNotFound("AgentMemory", id)
+	}
+
+	return c.JSON(http.StatusOK, APIResponse[any]{Success: true})
+}
+
+// PurgeMemories handles POST /api/projects/:projectId/agent-memories/purge
+// @Summary      Purge agent memories
+// @Description  Deletes every memory in the project matching the filters. An empty body purges all of the project's memories.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        request body PurgeMemoriesRequest false "Filters"
+// @Success      200 {object} APIResponse[PurgeMemoriesResponseDTO] "Number of memories deleted"
+// @Failure      400 {object} apperror.Error "Invalid request"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Router       /api/projects/{projectId}/agent-memories/purge [post]
+// @Security     bearerAuth
+func (h *Handler) PurgeMemories(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return apperror.NewBadRequest("projectId is required")
+	}
+
+	var req PurgeMemoriesRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return apperror.NewBadRequest("invalid request body")
+		}
+	}
+	if req.Kind != nil && !req.Kind.IsValid() {
+		return apperror.NewBadRequest(fmt.Sprintf("invalid kind %q: must be fact, preference or summary", *req.Kind))
+	}
+
+	deleted, err := h.repo.PurgeMemories(c.Request().Context(), projectID, MemoryFilters{
+		AgentDefinitionID: req.AgentDefinitionID,
+		UserID:            req.UserID,
+		Kind:              req.Kind,
+	})
+	if err != nil {
+		return apperror.NewInternal("failed to purge agent memories", err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(PurgeMemoriesResponseDTO{Deleted: deleted}))
+}
+
+// --- Agent Eval Handlers ---
+
+// ListEvalSuites handles GET /api/projects/:projectId/agent-eval-suites
+// @Summary      List agent evaluation suites
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        agentDefinitionId query string false "Filter by agent definition ID"
+// @Param        limit query int false "Max results (default 20, max 100)"
+// @Param        offset query int false "Offset for pagination"
+// @Success      200 {object} APIResponse[PaginatedResponse[AgentEvalSuiteDTO]] "Paginated evaluation suites"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Router       /api/projects/{projectId}/agent-eval-suites [get]
+// @Security     bearerAuth
+func (h *Handler) ListEvalSuites(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return apperror.NewBadRequest("projectId is required")
+	}
+
+	limit := 20
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	var agentDefinitionID *string
+	if defID := c.QueryParam("agentDefinitionId"); defID != "" {
+		agentDefinitionID = &defID
+	}
+
+	suites, totalCount, err := h.repo.FindEvalSuitesByProject(c.Request().Context(), projectID, agentDefinitionID, limit, offset)
+	if err != nil {
+		return apperror.NewInternal("failed to list evaluation suites", err)
+	}
+
+	dtos := make([]*AgentEvalSuiteDTO, len(suites))
+	for i, s := range suites {
+		dtos[i] = s.ToDTO()
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(PaginatedResponse[*AgentEvalSuiteDTO]{
+		Items:      dtos,
+		TotalCount: totalCount,
+		Limit:      limit,
+		Offset:     offset,
+	}))
+}
+
+// CreateEvalSuite handles POST /api/projects/:projectId/agent-eval-suites
+// @Summary      Create an agent evaluation suite
+// @Description  Creates a suite of scenarios for an agent definition. Each scenario seeds graph state, sends an input message and asserts on tool calls, graph end state and the final answer.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        request body CreateAgentEvalSuiteDTO true "Suite"
+// @Success      201 {object} APIResponse[AgentEvalSuiteDTO] "Created suite"
+// @Failure      400 {object} apperror.Error "Invalid suite"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Agent definition not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites [post]
+// @Security     bearerAuth
+func (h *Handler) CreateEvalSuite(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return apperror.NewBadRequest("projectId is required")
+	}
+
+	var dto CreateAgentEvalSuiteDTO
+	if err := c.Bind(&dto); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+	if dto.AgentDefinitionID == "" {
+		return apperror.NewBadRequest("agentDefinitionId is required")
+	}
+
+	ctx := c.Request().Context()
+	def, err := h.repo.FindDefinitionByID(ctx, dto.AgentDefinitionID, &projectID)
+	if err != nil {
+		return apperror.NewInternal("failed to get agent definition", err)
+	}
+	if def == nil {
+		return apperror.NewNotFound("AgentDefinition", dto.AgentDefinitionID)
+	}
+
+	suite := &AgentEvalSuite{
+		ProjectID:         projectID,
+		AgentDefinitionID: def.ID,
+		Name:              dto.Name,
+		Description:       dto.Description,
+		Scenarios:         dto.Scenarios,
+		JudgeModel:        dto.JudgeModel,
+		PassThreshold:     dto.PassThreshold,
+	}
+	if err := suite.Validate(); err != nil {
+		return apperror.NewBadRequest(err.Error())
+	}
+
+	if err := h.repo.CreateEvalSuite(ctx, suite); err != nil {
+		return apperror.NewInternal("failed to create evaluation suite", err)
+	}
+
+	return c.JSON(http.StatusCreated, SuccessResponse(suite.ToDTO()))
+}
+
+// GetEvalSuite handles GET /api/projects/:projectId/agent-eval-suites/:suiteId
+// @Summary      Get an agent evaluation suite
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Success      200 {object} APIResponse[AgentEvalSuiteDTO] "Suite"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Suite not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId} [get]
+// @Security     bearerAuth
+func (h *Handler) GetEvalSuite(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	suite, err := h.findEvalSuite(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(suite.ToDTO()))
+}
+
+// UpdateEvalSuite handles PATCH /api/projects/:projectId/agent-eval-suites/:suiteId
+// @Summary      Update an agent evaluation suite
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Param        request body UpdateAgentEvalSuiteDTO true "Fields to update"
+// @Success      200 {object} APIResponse[AgentEvalSuiteDTO] "Updated suite"
+// @Failure      400 {object} apperror.Error "Invalid suite"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Suite not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId} [patch]
+// @Security     bearerAuth
+func (h *Handler) UpdateEvalSuite(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	var dto UpdateAgentEvalSuiteDTO
+	if err := c.Bind(&dto); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+
+	suite, err := h.findEvalSuite(c)
+	if err != nil {
+		return err
+	}
+
+	if dto.Name != nil {
+		suite.Name = *dto.Name
+	}
+	if dto.Description != nil {
+		suite.Description = dto.Description
+	}
+	if dto.Scenarios != nil {
+		suite.Scenarios = dto.Scenarios
+	}
+	if dto.JudgeModel != nil {
+		suite.JudgeModel = dto.JudgeModel
+	}
+	if dto.PassThreshold != nil {
+		suite.PassThreshold = dto.PassThreshold
+	}
+	if err := suite.Validate(); err != nil {
+		return apperror.NewBadRequest(err.Error())
+	}
+
+	if err := h.repo.UpdateEvalSuite(c.Request().Context(), suite); err != nil {
+		return apperror.NewInternal("failed to update evaluation suite", err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(suite.ToDTO()))
+}
+
+// DeleteEvalSuite handles DELETE /api/projects/:projectId/agent-eval-suites/:suiteId
+// @Summary      Delete an agent evaluation suite and its runs
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Success      200 {object} APIResponse[any] "Suite deleted"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Suite not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId} [delete]
+// @Security     bearerAuth
+func (h *Handler) DeleteEvalSuite(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	id := c.Param("suiteId")
+
+	deleted, err := h.repo.DeleteEvalSuite(c.Request().Context(), projectID, id)
+	if err != nil {
+		return apperror.NewInternal("failed to delete evaluation suite", err)
+	}
+	if !deleted {
+		return apperror.NewNotFound("AgentEvalSuite", id)
+	}
+
+	return c.JSON(http.StatusOK, APIResponse[any]{Success: true})
+}
+
+// StartEvalRun handles POST /api/projects/:projectId/agent-eval-suites/:suiteId/runs
+// @Summary      Run an agent evaluation suite
+// @Description  Runs the suite's scenarios against the current agent definition in the background. Poll the returned run for results.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Param        request body StartEvalRunRequest false "Run options"
+// @Success      202 {object} APIResponse[AgentEvalRunDTO] "Started run"
+// @Failure      400 {object} apperror.Error "Invalid request"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Suite not found"
+// @Failure      409 {object} apperror.Error "A run of the suite is in progress"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId}/runs [post]
+// @Security     bearerAuth
+func (h *Handler) StartEvalRun(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	var req StartEvalRunRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return apperror.NewBadRequest("invalid request body")
+		}
+	}
+
+	if h.evals == nil {
+		return apperror.New(http.StatusServiceUnavailable, "executor_unavailable", "agent executor is not available")
+	}
+
+	suite, err := h.findEvalSuite(c)
+	if err != nil {
+		return err
+	}
+	if _, err := selectEvalScenarios(suite.Scenarios, req.Scenarios); err != nil {
+		return apperror.NewBadRequest(err.Error())
+	}
+
+	ctx := c.Request().Context()
+	def, err := h.repo.FindDefinitionByID(ctx, suite.AgentDefinitionID, &suite.ProjectID)
+	if err != nil {
+		return apperror.NewInternal("failed to get agent definition", err)
+	}
+	if def == nil {
+		return apperror.NewNotFound("AgentDefinition", suite.AgentDefinitionID)
+	}
+
+	run, err := h.evals.Start(ctx, suite, def, EvalRunOptions{
+		Scenarios:    req.Scenarios,
+		SystemPrompt: req.SystemPrompt,
+	})
+	if errors.Is(err, ErrEvalRunInProgress) {
+		return apperror.ErrConflict.WithMessage(err.Error())
+	}
+	if err != nil {
+		return apperror.NewInternal("failed to start evaluation run", err)
+	}
+
+	return c.JSON(http.StatusAccepted, SuccessResponse(run.ToDTO()))
+}
+
+// ListEvalRuns handles GET /api/projects/:projectId/agent-eval-suites/:suiteId/runs
+// @Summary      List runs of an agent evaluation suite
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Param        status query string false "Filter by status (running, completed, failed)"
+// @Param        limit query int false "Max results (default 20, max 100)"
+// @Param        offset query int false "Offset for pagination"
+// @Success      200 {object} APIResponse[PaginatedResponse[AgentEvalRunDTO]] "Paginated runs, newest first"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Suite not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId}/runs [get]
+// @Security     bearerAuth
+func (h *Handler) ListEvalRuns(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	suite, err := h.findEvalSuite(c)
+	if err != nil {
+		return err
+	}
+
+	limit := 20
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	var status *EvalRunStatus
+	if statusStr := c.QueryParam("status"); statusStr != "" {
+		s := EvalRunStatus(statusStr)
+		status = &s
+	}
+
+	runs, totalCount, err := h.repo.FindEvalRunsBySuite(c.Request().Context(), suite.ID, status, limit, offset)
+	if err != nil {
+		return apperror.NewInternal("failed to list evaluation runs", err)
+	}
+
+	dtos := make([]*AgentEvalRunDTO, len(runs))
+	for i, r := range runs {
+		dtos[i] = r.ToDTO()
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(PaginatedResponse[*AgentEvalRunDTO]{
+		Items:      dtos,
+		TotalCount: totalCount,
+		Limit:      limit,
+		Offset:     offset,
+	}))
+}
+
+// GetEvalRun handles GET /api/projects/:projectId/agent-eval-suites/:suiteId/runs/:runId
+// @Summary      Get an agent evaluation run
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Param        runId path string true "Evaluation run ID (UUID)"
+// @Success      200 {object} APIResponse[AgentEvalRunDTO] "Run with per-scenario results"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Run not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId}/runs/{runId} [get]
+// @Security     bearerAuth
+func (h *Handler) GetEvalRun(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	suite, err := h.findEvalSuite(c)
+	if err != nil {
+		return err
+	}
+
+	runID := c.Param("runId")
+	run, err := h.repo.FindEvalRunByID(c.Request().Context(), suite.ID, runID)
+	if err != nil {
+		return apperror.NewInternal("failed to get evaluation run", err)
+	}
+	if run == nil {
+		return apperror.NewNotFound("AgentEvalRun", runID)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(run.ToDTO()))
+}
+
+// CompareEvalRuns handles GET /api/projects/:projectId/agent-eval-suites/:suiteId/compare
+// @Summary      Compare two agent evaluation runs
+// @Description  Compares a run against a base run scenario by scenario and lists the definition fields that changed between them. By default the latest completed run is compared against the latest earlier completed run of a different definition version.
+// @Tags         agents
+// @Produce      json
+// @Param        projectId path string true "Project ID (UUID)"
+// @Param        suiteId path string true "Suite ID (UUID)"
+// @Param        runId query string false "Run to compare (default: latest completed)"
+// @Param        baseRunId query string false "Run to compare against"
+// @Success      200 {object} APIResponse[EvalComparison] "Comparison"
+// @Failure      400 {object} apperror.Error "Runs cannot be compared"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Run not found"
+// @Router       /api/projects/{projectId}/agent-eval-suites/{suiteId}/compare [get]
+// @Security     bearerAuth
+func (h *Handler) CompareEvalRuns(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	suite, err := h.findEvalSuite(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	completed := EvalRunStatusCompleted
+	recent, _, err := h.repo.FindEvalRunsBySuite(ctx, suite.ID, &completed, 100, 0)
+	if err != nil {
+		return apperror.NewInternal("failed to list evaluation runs", err)
+	}
+
+	run, err := h.findComparedEvalRun(ctx, suite.ID, c.QueryParam("runId"), recent)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		return apperror.NewBadRequest("the suite has no completed runs to compare")
+	}
+
+	var base *AgentEvalRun
+	if baseID := c.QueryParam("baseRunId"); baseID != "" {
+		if base, err = h.findComparedEvalRun(ctx, suite.ID, baseID, nil); err != nil {
+			return err
+		}
+	} else if base = comparisonBase(recent, run); base == nil {
+		return apperror.NewBadRequest("no earlier completed run to compare against")
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(compareEvalRuns(base, run)))
+}
+
+// findComparedEvalRun loads a run to compare; without an ID it returns the
+// latest completed run, if any.
+func (h *Handler) findComparedEvalRun(ctx context.Context, suiteID, id string, recent []*AgentEvalRun) (*AgentEvalRun, error) {
+	if id == "" {
+		if len(recent) == 0 {
+			return nil, nil
+		}
+		return recent[0], nil
+	}
+	run, err := h.repo.FindEvalRunByID(ctx, suiteID, id)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get evaluation run", err)
+	}
+	if run == nil {
+		return nil, apperror.NewNotFound("AgentEvalRun", id)
+	}
+	if run.Status != EvalRunStatusCompleted {
+		return nil, apperror.NewBadRequest(fmt.Sprintf("evaluation run %s is %s, only completed runs can be compared", id, run.Status))
+	}
+	return run, nil
+}
+
+// findEvalSuite loads the suite named by the :projectId and :suiteId params.
+func (h *Handler) findEvalSuite(c echo.Context) (*AgentEvalSuite, error) {
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return nil, apperror.NewBadRequest("projectId is required")
+	}
+	id := c.Param("suiteId")
+	suite, err := h.repo.FindEvalSuiteByID(c.Request().Context(), projectID, id)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get evaluation suite", err)
+	}
+	if suite == nil {
+		return nil, apperror.NewNotFound("AgentEvalSuite", id)
+	}
+	return suite, nil
+}
+
+// --- Agent Change Set Handlers ---
+
+// ListChangeSets handles GET /api/projects/:projectId/agent-change-sets
+func (h *Handler) ListChangeSets(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	if projectID == "" {
+		return apperror.NewBadRequest("projectId is required")
+	}
+
+	limit := 20
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	offset := 0
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	var filters ChangeSetFilters
+	if statusStr := c.QueryParam("status"); statusStr != "" {
+		status := ChangeSetStatus(statusStr)
+		filters.Status = &status
+	}
+	if runID := c.QueryParam("runId"); runID != "" {
+		filters.RunID = &runID
+	}
+
+	sets, totalCount, err := h.repo.FindChangeSetsByProject(c.Request().Context(), projectID, filters, limit, offset)
+	if err != nil {
+		return apperror.NewInternal("failed to list change sets", err)
+	}
+
+	dtos := make([]*AgentChangeSetDTO, len(sets))
+	for i, set := range sets {
+		dtos[i] = set.ToDTO()
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(PaginatedResponse[*AgentChangeSetDTO]{
+		Items:      dtos,
+		TotalCount: totalCount,
+		Limit:      limit,
+		Offset:     offset,
+	}))
+}
+
+// GetChangeSet handles GET /api/projects/:projectId/agent-change-sets/:changeSetId
+func (h *Handler) GetChangeSet(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	id := c.Param("changeSetId")
+
+	set, err := h.repo.FindChangeSetByID(c.Request().Context(), projectID, id)
+	if err != nil {
+		return apperror.NewInternal("failed to get change set", err)
+	}
+	if set == nil {
+		return apperror.NewNotFound("AgentChangeSet", id)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(set.ToDTO()))
+}
+
+// ApproveChangeSet handles POST /api/projects/:projectId/agent-change-sets/:changeSetId/approve
+func (h *Handler) ApproveChangeSet(c echo.Context) error {
+	return h.reviewChangeSet(c, true)
+}
+
+// RejectChangeSet handles POST /api/projects/:projectId/agent-change-sets/:changeSetId/reject
+func (h *Handler) RejectChangeSet(c echo.Context) error {
+	return h.reviewChangeSet(c, false)
+}
+
+func (h *Handler) reviewChangeSet(c echo.Context, approve bool) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	projectID := c.Param("projectId")
+	id := c.Param("changeSetId")
+
+	var req ReviewChangeSetRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.NewBadRequest("invalid request body")
+	}
+
+	if h.executor == nil || h.executor.changeSets == nil {
+		return apperror.New(http.StatusServiceUnavailable, "change_sets_unavailable", "change set review is not available")
+	}
+	svc := h.executor.changeSets
+
+	var (
+		set *AgentChangeSet
+		err error
+	)
+	if approve {
+		set, err = svc.Approve(c.Request().Context(), projectID, id, user.ID, req.ChangeSeqs, req.Notes)
+	} else {
+		set, err = svc.Reject(c.Request().Context(), projectID, id, user.ID, req.Notes)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(set.ToDTO()))
+}
@@ -0,0 +1,304 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+)
+
+// mcpTriggerSource marks runs started by calling a published agent tool.
+const mcpTriggerSource = "mcp"
+
+// publishedToolName matches the names MCP clients accept for tools.
+var publishedToolName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// AgentPublisher publishes a project's external and project agent
+// definitions to MCP clients: each becomes a tool running the agent and a
+// prompt carrying its system prompt. Definitions are read on every call, so
+// changes show up on the next tools/list; the ToolPool invalidation that
+// follows a change tells connected sessions to refetch it.
+type AgentPublisher struct {
+	repo     *Repository
+	executor *AgentExecutor
+}
+
+// NewAgentPublisher creates a new AgentPublisher.
+func NewAgentPublisher(repo *Repository, executor *AgentExecutor) *AgentPublisher {
+	return &AgentPublisher{repo: repo, executor: executor}
+}
+
+// publishedDefinitions returns the definitions published in a project.
+// Definitions whose name is not a valid tool name, or is taken by a built-in
+// tool, are not published.
+func (p *AgentPublisher) publishedDefinitions(ctx context.Context, projectID string) ([]*AgentDefinition, error) {
+	defs, err := p.repo.FindAllDefinitions(ctx, projectID, false)
+	if err != nil {
+		return nil, fmt.Errorf("list agent definitions: %w", err)
+	}
+	published := defs[:0]
+	for _, def := range defs {
+		if publishedToolName.MatchString(def.Name) && !mcp.IsKnownTool(def.Name) {
+			published = append(published, def)
+		}
+	}
+	return published, nil
+}
+
+// findPublished returns the published definition with the name, or nil.
+func (p *AgentPublisher) findPublished(ctx context.Context, projectID, name string) (*AgentDefinition, error) {
+	defs, err := p.publishedDefinitions(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		if def.Name == name {
+			return def, nil
+		}
+	}
+	return nil, nil
+}
+
+// PublishedAgentTools returns a tool for every published agent definition.
+func (p *AgentPublisher) PublishedAgentTools(ctx context.Context, projectID string) ([]mcp.ToolDefinition, error) {
+	defs, err := p.publishedDefinitions(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tools := make([]mcp.ToolDefinition, 0, len(defs))
+	for _, def := range defs {
+		tools = append(tools, mcp.ToolDefinition{
+			Name:        def.Name,
+			Description: publishedDescription(def),
+			InputSchema: publishedInputSchema(def),
+		})
+	}
+	return tools, nil
+}
+
+// ExecutePublishedAgent runs the agent published under name with the tool
+// call's arguments and returns its final response. Each tool call the agent
+// makes is reported as progress when the client asked for it.
+func (p *AgentPublisher) ExecutePublishedAgent(ctx context.Context, projectID, name string, args map[string]any) (*mcp.ToolResult, error) {
+	def, err := p.findPublished(ctx, projectID, name)
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+	if p.executor == nil {
+		return errResult("agent executor is not available")
+	}
+
+	message, err := publishedUserMessage(def, args)
+	if err != nil {
+		return errResult(err.Error())
+	}
+
+	agent, err := p.repo.EnsureAgentForDefinition(ctx, def, "mcp:"+def.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get agent for definition: %w", err)
+	}
+
+	var userID string
+	metadata := map[string]any{"tool": name}
+	if user := auth.UserFromContext(ctx); user != nil {
+		userID = user.ID
+		if user.APITokenID != "" {
+			metadata["api_token_id"] = user.APITokenID
+		}
+	}
+
+	var timeout *time.Duration
+	if def.DefaultTimeout != nil && *def.DefaultTimeout > 0 {
+		d := time.Duration(*def.DefaultTimeout) * time.Second
+		timeout = &d
+	}
+
+	source := mcpTriggerSource
+	result, err := p.executor.Execute(ctx, ExecuteRequest{
+		Agent:           agent,
+		AgentDefinition: def,
+		ProjectID:       projectID,
+		UserMessage:     message,
+		UserID:          userID,
+		MaxSteps:        def.MaxSteps,
+		Timeout:         timeout,
+		TriggerSource:   &source,
+		TriggerMetadata: metadata,
+		StreamCallback:  runProgress(ctx, def.Name),
+	})
+	if err != nil {
+		return errResult("failed to run agent: " + err.Error())
+	}
+
+	return publishedRunResult(result), nil
+}
+
+// publishedRunResult is the tool result of a run: its final response, or why
+// it did not complete.
+func publishedRunResult(result *ExecuteResult) *mcp.ToolResult {
+	text, _ := result.Summary["final_response"].(string)
+	switch result.Status {
+	case RunStatusSuccess:
+		if text == "" {
+			text = "The agent finished without a response."
+		}
+	case RunStatusPaused:
+		text = fmt.Sprintf("The agent paused (run %s) waiting for input. Answer its question and resume the run to continue.", result.RunID)
+		if reason, ok := result.Summary["reason"].(string); ok && reason != "" {
+			text += " Reason: " + reason
+		}
+	default:
+		errMsg, _ := result.Summary["error"].(string)
+		return &mcp.ToolResult{
+			Content: []mcp.ContentBlock{{Type: "text", Text: fmt.Sprintf("The agent run %s ended with status %s: %s", result.RunID, result.Status, errMsg)}},
+			IsError: true,
+		}
+	}
+	return &mcp.ToolResult{Content: []mcp.ContentBlock{{Type: "text", Text: text}}}
+}
+
+// PublishedAgentPrompts returns a prompt for every published agent definition
+// with a system prompt. The prompt's arguments are the agent's input.
+func (p *AgentPublisher) PublishedAgentPrompts(ctx context.Context, projectID string) ([]mcp.PromptDefinition, error) {
+	defs, err := p.publishedDefinitions(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]mcp.PromptDefinition, 0, len(defs))
+	for _, def := range defs {
+		if def.SystemPrompt == nil || *def.SystemPrompt == "" {
+			continue
+		}
+		prompts = append(prompts, mcp.PromptDefinition{
+			Name:        def.Name,
+			Description: publishedDescription(def),
+			Arguments:   publishedPromptArguments(def),
+		})
+	}
+	return prompts, nil
+}
+
+// GetPublishedAgentPrompt returns the prompt of a published agent: its system
+// prompt as instructions, followed by the arguments as the task.
+func (p *AgentPublisher) GetPublishedAgentPrompt(ctx context.Context, projectID, name string, args map[string]any) (*mcp.PromptGetResult, error) {
+	def, err := p.findPublished(ctx, projectID, name)
+	if err != nil {
+		return nil, err
+	}
+	if def == nil || def.SystemPrompt == nil || *def.SystemPrompt == "" {
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+
+	text := *def.SystemPrompt
+	if len(args) > 0 {
+		task, err := publishedUserMessage(def, args)
+		if err != nil {
+			return nil, err
+		}
+		text += "\n\n## Task\n\n" + task
+	}
+	return &mcp.PromptGetResult{
+		Description: publishedDescription(def),
+		Messages: []mcp.PromptMessage{
+			{Role: "user", Content: mcp.PromptContent{Type: "text", Text: text}},
+		},
+	}, nil
+}
+
+// publishedDescription describes a published agent.
+func publishedDescription(def *AgentDefinition) string {
+	if def.ACPConfig != nil && def.ACPConfig.Description != "" {
+		return def.ACPConfig.Description
+	}
+	if def.Description != nil && *def.Description != "" {
+		return *def.Description
+	}
+	return fmt.Sprintf("Run the %s agent", def.Name)
+}
+
+// publishedInputSchema returns the agent's declared input schema, or a single
+// message argument when it declares none.
+func publishedInputSchema(def *AgentDefinition) mcp.InputSchema {
+	if len(def.InputSchema) > 0 {
+		return mapToInputSchema(def.InputSchema)
+	}
+	return mcp.InputSchema{
+		Type: "object",
+		Properties: map[string]mcp.PropertySchema{
+			"message": {Type: "string", Description: "Instructions for the agent"},
+		},
+		Required: []string{"message"},
+	}
+}
+
+// publishedPromptArguments returns the prompt arguments matching the agent's
+// input, sorted by name.
+func publishedPromptArguments(def *AgentDefinition) []mcp.PromptArgument {
+	schema := publishedInputSchema(def)
+	args := make([]mcp.PromptArgument, 0, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		args = append(args, mcp.PromptArgument{Name: name, Description: prop.Description})
+	}
+	sort.Slice(args, func(i, j int) bool { return args[i].Name < args[j].Name })
+	return args
+}
+
+// publishedUserMessage builds the run's user message from the tool call's
+// arguments: the message itself for agents without an input schema, the
+// arguments as JSON otherwise.
+func publishedUserMessage(def *AgentDefinition, args map[string]any) (string, error) {
+	schema := publishedInputSchema(def)
+	for _, name := range schema.Required {
+		if v, ok := args[name]; !ok || v == nil || v == "" {
+			return "", fmt.Errorf("%s is required", name)
+		}
+	}
+
+	if len(def.InputSchema) == 0 {
+		message, _ := args["message"].(string)
+		return message, nil
+	}
+	data, err := json.MarshalIndent(args, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	return "Run with this input:\n\n```json\n" + string(data) + "\n```", nil
+}
+
+// validateInputSchema checks that an agent's input schema is a JSON Schema
+// object MCP clients can fill in.
+func validateInputSchema(schema map[string]any) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if t, ok := schema["type"]; ok && t != "object" {
+		return fmt.Errorf("inputSchema type must be \"object\"")
+	}
+	if props, ok := schema["properties"]; ok {
+		if _, ok := props.(map[string]any); !ok {
+			return fmt.Errorf("inputSchema properties must be an object")
+		}
+	}
+	if required, ok := schema["required"]; ok {
+		list, ok := required.([]any)
+		if !ok {
+			return fmt.Errorf("inputSchema required must be an array of property names")
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for _, r := range list {
+			name, ok := r.(string)
+			if _, declared := props[name]; !ok || !declared {
+				return fmt.Errorf("inputSchema requires undeclared property %v", r)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishedInputSchema(t *testing.T) {
+	plain := &AgentDefinition{Name: "summarize"}
+	schema := publishedInputSchema(plain)
+	assert.Equal(t, []string{"message"}, schema.Required)
+
+	message, err := publishedUserMessage(plain, map[string]any{"message": "Summarize the Q3 report"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize the Q3 report", message)
+
+	_, err = publishedUserMessage(plain, map[string]any{})
+	assert.EqualError(t, err, "message is required")
+
+	reviewer := &AgentDefinition{Name: "review", InputSchema: map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"contract": map[string]any{"type": "string", "description": "Contract text"}},
+		"required":   []any{"contract"},
+	}}
+	schema = publishedInputSchema(reviewer)
+	assert.Equal(t, "string", schema.Properties["contract"].Type)
+
+	message, err = publishedUserMessage(reviewer, map[string]any{"contract": "ACME MSA"})
+	require.NoError(t, err)
+	assert.Contains(t, message, `"contract": "ACME MSA"`)
+
+	args := publishedPromptArguments(reviewer)
+	require.Len(t, args, 1)
+	assert.Equal(t, "Contract text", args[0].Description)
+}
+
+func TestPublishedDescription(t *testing.T) {
+	assert.Equal(t, "Run the summarize agent", publishedDescription(&AgentDefinition{Name: "summarize"}))
+	assert.Equal(t, "Summarizes documents", publishedDescription(&AgentDefinition{Name: "summarize", Description: strPtr("Summarizes documents")}))
+}
+
+func TestPublishedRunResult(t *testing.T) {
+	ok := publishedRunResult(&ExecuteResult{RunID: "r1", Status: RunStatusSuccess, Summary: map[string]any{"final_response": "All done"}})
+	assert.False(t, ok.IsError)
+	assert.Equal(t, "All done", ok.Content[0].Text)
+
+	failed := publishedRunResult(&ExecuteResult{RunID: "r2", Status: RunStatusError, Summary: map[string]any{"error": "boom"}})
+	assert.True(t, failed.IsError)
+	assert.Contains(t, failed.Content[0].Text, "boom")
+}
+
+func TestValidateInputSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]any
+		valid  bool
+	}{
+		{name: "none", schema: nil, valid: true},
+		{name: "object", schema: map[string]any{"type": "object", "properties": map[string]any{"q": map[string]any{"type": "string"}}, "required": []any{"q"}}, valid: true},
+		{name: "not an object", schema: map[string]any{"type": "string"}, valid: false},
+		{name: "bad properties", schema: map[string]any{"properties": []any{"q"}}, valid: false},
+		{name: "undeclared required", schema: map[string]any{"properties": map[string]any{}, "required": []any{"q"}}, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInputSchema(tt.schema)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_OnDefinitionsChanged(t *testing.T) {
+	repo := NewRepository(nil)
+	var first, second []string
+	repo.OnDefinitionsChanged(func(projectID string) { first = append(first, projectID) })
+	repo.OnDefinitionsChanged(func(projectID string) { second = append(second, projectID) })
+
+	repo.notifyDefinitionsChanged("proj-1")
+	repo.notifyDefinitionsChanged("")
+
+	assert.Equal(t, []string{"proj-1"}, first, "earlier hooks are kept")
+	assert.Equal(t, []string{"proj-1"}, second)
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 
 	"github.com/emergent-company/emergent.memory/domain/mcp"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
 )
 
 // MCPToolHandler implements mcp.AgentToolHandler, providing 16 agent management
@@ -129,14 +130,35 @@ func (h *MCPToolHandler) ExecuteCreateAgentDefinition(ctx context.Context, proje
 		config = c
 	}
 
+	flowConfig, err := parseFlowConfigArg(args["flow_config"])
+	if err != nil {
+		return errResult(err.Error())
+	}
+	if err := flowConfig.Validate(flowType); err != nil {
+		return errResult(err.Error())
+	}
+
+	toolPolicies, err := parseToolPoliciesArg(args["tool_policies"])
+	if err != nil {
+		return errResult(err.Error())
+	}
+
+	inputSchema, err := parseInputSchemaArg(args["input_schema"])
+	if err != nil {
+		return errResult(err.Error())
+	}
+
 	def := &AgentDefinition{
-		ProjectID:  projectID,
-		Name:       name,
-		FlowType:   flowType,
-		Visibility: visibility,
-		IsDefault:  isDefault,
-		Tools:      tools,
-		Config:     config,
+		ProjectID:    projectID,
+		Name:         name,
+		FlowType:     flowType,
+		FlowConfig:   flowConfig,
+		Visibility:   visibility,
+		IsDefault:    isDefault,
+		Tools:        tools,
+		Config:       config,
+		ToolPolicies: toolPolicies,
+		InputSchema:  inputSchema,
 	}
 
 	// Optional fields
@@ -190,6 +212,16 @@ func (h *MCPToolHandler) ExecuteUpdateAgentDefinition(ctx context.Context, proje
 	if ft, ok := args["flow_type"].(string); ok {
 		def.FlowType = AgentFlowType(ft)
 	}
+	if raw, ok := args["flow_config"]; ok {
+		flowConfig, err := parseFlowConfigArg(raw)
+		if err != nil {
+			return errResult(err.Error())
+		}
+		def.FlowConfig = flowConfig
+	}
+	if err := def.FlowConfig.Validate(def.FlowType); err != nil {
+		return errResult(err.Error())
+	}
 	if v, ok := args["visibility"].(string); ok {
 		def.Visibility = AgentVisibility(v)
 	}
@@ -216,6 +248,20 @@ func (h *MCPToolHandler) ExecuteUpdateAgentDefinition(ctx context.Context, proje
 	if c, ok := args["config"].(map[string]any); ok {
 		def.Config = c
 	}
+	if raw, ok := args["tool_policies"]; ok {
+		toolPolicies, err := parseToolPoliciesArg(raw)
+		if err != nil {
+			return errResult(err.Error())
+		}
+		def.ToolPolicies = toolPolicies
+	}
+	if raw, ok := args["input_schema"]; ok {
+		inputSchema, err := parseInputSchemaArg(raw)
+		if err != nil {
+			return errResult(err.Error())
+		}
+		def.InputSchema = inputSchema
+	}
 
 	if err := h.repo.UpdateDefinition(ctx, def); err != nil {
 		return errResult("failed to update agent definition: " + err.Error())
@@ -324,15 +370,21 @@ func (h *MCPToolHandler) ExecuteCreateAgent(ctx context.Context, projectID strin
 		config = c
 	}
 
+	autoApplyRules, err := parseAutoApplyRulesArg(args["auto_apply_rules"])
+	if err != nil {
+		return errResult(err.Error())
+	}
+
 	agent := &Agent{
-		ProjectID:     projectID,
-		Name:          name,
-		StrategyType:  strategyType,
-		CronSchedule:  cronSchedule,
-		Enabled:       enabled,
-		TriggerType:   triggerType,
-		ExecutionMode: executionMode,
-		Config:        config,
+		ProjectID:      projectID,
+		Name:           name,
+		StrategyType:   strategyType,
+		CronSchedule:   cronSchedule,
+		Enabled:        enabled,
+		TriggerType:    triggerType,
+		ExecutionMode:  executionMode,
+		AutoApplyRules: autoApplyRules,
+		Config:         config,
 	}
 
 	// Optional fields
@@ -384,6 +436,13 @@ func (h *MCPToolHandler) ExecuteUpdateAgent(ctx context.Context, projectID strin
 	if em, ok := args["execution_mode"].(string); ok {
 		agent.ExecutionMode = AgentExecutionMode(em)
 	}
+	if raw, ok := args["auto_apply_rules"]; ok {
+		rules, err := parseAutoApplyRulesArg(raw)
+		if err != nil {
+			return errResult(err.Error())
+		}
+		agent.AutoApplyRules = rules
+	}
 	if c, ok := args["config"].(map[string]any); ok {
 		agent.Config = c
 	}
@@ -464,11 +523,20 @@ func (h *MCPToolHandler) ExecuteTriggerAgent(ctx context.Context, projectID stri
 		userMessage = *agent.Prompt
 	}
 
+	// The calling user answers questions the run asks, through the MCP
+	// client when it supports elicitation
+	var userID string
+	if user := auth.UserFromContext(ctx); user != nil {
+		userID = user.ID
+	}
+
 	result, err := h.executor.Execute(ctx, ExecuteRequest{
 		Agent:           agent,
 		AgentDefinition: agentDef,
 		ProjectID:       agent.ProjectID,
 		UserMessage:     userMessage,
+		UserID:          userID,
+		StreamCallback:  runProgress(ctx, agent.Name),
 	})
 	if err != nil {
 		return errResult("failed to execute agent: " + err.Error())
@@ -484,6 +552,21 @@ func (h *MCPToolHandler) ExecuteTriggerAgent(ctx context.Context, projectID stri
 	})
 }
 
+// runProgress returns a stream callback reporting each tool call of an agent
+// run as progress of the MCP tool call starting the run. The total is
+// unknown, so progress counts the tool calls made.
+func runProgress(ctx context.Context, agentName string) func(StreamEvent) {
+	client := mcp.ClientSessionFromContext(ctx)
+	var toolCalls int
+	return func(event StreamEvent) {
+		if event.Type != StreamEventToolCallStart {
+			return
+		}
+		toolCalls++
+		client.Progress(float64(toolCalls), 0, fmt.Sprintf("%s: calling %s", agentName, event.Tool))
+	}
+}
+
 // ============================================================================
 // Agent Run Tools
 // ============================================================================
@@ -708,6 +791,10 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 						Enum:        []string{"single", "sequential", "loop"},
 						Default:     "single",
 					},
+					"flow_config": {
+						Type:        "object",
+						Description: "Multi-step " + flowConfigArgDescription,
+					},
 					"visibility": {
 						Type:        "string",
 						Description: "Visibility level: external (ACP-discoverable), project (admin UI only), internal (other agents only)",
@@ -731,6 +818,14 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 						Type:        "string",
 						Description: "Additional configuration as JSON object",
 					},
+					"tool_policies": {
+						Type:        "string",
+						Description: "JSON array of " + toolPoliciesArgDescription,
+					},
+					"input_schema": {
+						Type:        "string",
+						Description: inputSchemaArgDescription,
+					},
 				},
 				Required: []string{"name"},
 			},
@@ -762,6 +857,10 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 						Description: "New flow type",
 						Enum:        []string{"single", "sequential", "loop"},
 					},
+					"flow_config": {
+						Type:        "object",
+						Description: "New multi-step " + flowConfigArgDescription,
+					},
 					"visibility": {
 						Type:        "string",
 						Description: "New visibility level",
@@ -779,6 +878,14 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 						Type:        "integer",
 						Description: "New default timeout in seconds",
 					},
+					"tool_policies": {
+						Type:        "string",
+						Description: "Replacement JSON array of " + toolPoliciesArgDescription,
+					},
+					"input_schema": {
+						Type:        "string",
+						Description: "Replacement " + inputSchemaArgDescription + " An empty object removes it.",
+					},
 				},
 				Required: []string{"definition_id"},
 			},
@@ -861,10 +968,14 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 					},
 					"execution_mode": {
 						Type:        "string",
-						Description: "How the agent executes actions",
+						Description: "How the agent executes actions: execute applies graph writes, suggest stages them for approval, hybrid auto-applies writes matching auto_apply_rules",
 						Enum:        []string{"suggest", "execute", "hybrid"},
 						Default:     "execute",
 					},
+					"auto_apply_rules": {
+						Type:        "object",
+						Description: "Hybrid mode rules for applying changes without review: {\"objectTypes\": [...], \"minConfidence\": 0.9}. Other changes are staged for approval.",
+					},
 					"config": {
 						Type:        "string",
 						Description: "Additional configuration as JSON object",
@@ -913,6 +1024,10 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 						Description: "New execution mode",
 						Enum:        []string{"suggest", "execute", "hybrid"},
 					},
+					"auto_apply_rules": {
+						Type:        "object",
+						Description: "New " + "hybrid mode rules for applying changes without review: {\"objectTypes\": [...], \"minConfidence\": 0.9}. Other changes are staged for approval.",
+					},
 				},
 				Required: []string{"agent_id"},
 			},
@@ -1039,6 +1154,98 @@ func (h *MCPToolHandler) GetAgentToolDefinitions() []mcp.ToolDefinition {
 	}
 }
 
+// flowConfigArgDescription documents the flow_config tool argument.
+const flowConfigArgDescription = "flow configuration for sequential and loop flows: {\"steps\": [{\"name\", \"prompt\", \"tools\", \"model\", \"outputKey\"}], \"maxIterations\"}. " +
+	"Steps run in order; a step's response is stored under its outputKey (default: step name) and can be referenced as {key} in later prompts. " +
+	"Loop steps call exit_loop to finish."
+
+// parseFlowConfigArg decodes a flow_config tool argument.
+func parseFlowConfigArg(raw any) (*FlowConfig, error) {
+	var cfg FlowConfig
+	ok, err := decodeObjectArg(raw, &cfg)
+	if err != nil || !ok {
+		return nil, wrapArgError("flow_config", err)
+	}
+	return &cfg, nil
+}
+
+// toolPoliciesArgDescription documents the tool_policies tool argument.
+const toolPoliciesArgDescription = "tool policies, checked in order: [{\"tool\", \"args\", \"action\"}]. tool is a tool name or glob; args optionally maps argument names to regular expressions the argument must match; " +
+	"action is allow, require_approval (the run pauses until a user approves the exact call) or deny. The first matching policy wins; unmatched calls are allowed. " +
+	"Example: [{\"tool\": \"workspace_bash\", \"args\": {\"command\": \"git\\\\s+push\"}, \"action\": \"require_approval\"}]"
+
+// parseToolPoliciesArg decodes and validates a tool_policies tool argument.
+func parseToolPoliciesArg(raw any) (ToolPolicies, error) {
+	var policies ToolPolicies
+	ok, err := decodeObjectArg(raw, &policies)
+	if err != nil || !ok {
+		return nil, wrapArgError("tool_policies", err)
+	}
+	if err := policies.Validate(); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// inputSchemaArgDescription documents the input_schema tool argument.
+const inputSchemaArgDescription = "JSON Schema object of the input the agent takes when called as an MCP tool, " +
+	"e.g. {\"type\": \"object\", \"properties\": {\"contract\": {\"type\": \"string\"}}, \"required\": [\"contract\"]}. " +
+	"Without one the tool takes a free-text message."
+
+// parseInputSchemaArg decodes and validates an input_schema tool argument.
+func parseInputSchemaArg(raw any) (map[string]any, error) {
+	var schema map[string]any
+	ok, err := decodeObjectArg(raw, &schema)
+	if err != nil || !ok {
+		return nil, wrapArgError("input_schema", err)
+	}
+	if err := validateInputSchema(schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// parseAutoApplyRulesArg decodes an auto_apply_rules tool argument.
+func parseAutoApplyRulesArg(raw any) (*AutoApplyRules, error) {
+	var rules AutoApplyRules
+	ok, err := decodeObjectArg(raw, &rules)
+	if err != nil || !ok {
+		return nil, wrapArgError("auto_apply_rules", err)
+	}
+	return &rules, nil
+}
+
+// decodeObjectArg decodes an object tool argument, given either as an object
+// or as a JSON string, into dst. Returns false when the argument is absent.
+func decodeObjectArg(raw any, dst any) (bool, error) {
+	var data []byte
+	switch v := raw.(type) {
+	case nil:
+		return false, nil
+	case string:
+		if v == "" {
+			return false, nil
+		}
+		data = []byte(v)
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return false, err
+		}
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func wrapArgError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid %s: %w", name, err)
+}
+
 // intPtr returns a pointer to an int value.
 func intPtr(i int) *int {
 	return &i
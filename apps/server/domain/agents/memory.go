@@ -0,0 +1,297 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	// defaultMemoryRecallLimit is how many memories are injected at run start.
+	defaultMemoryRecallLimit = 5
+	// maxMemoryRecallLimit caps the configured recall limit so injected
+	// memories can't crowd out the instructions.
+	maxMemoryRecallLimit = 20
+	// memoryRecallMaxDistance is the largest cosine distance a recalled
+	// memory may have from the run's user message.
+	memoryRecallMaxDistance = 0.6
+	// maxMemoryContentLength caps a single memory so instructions stay small.
+	maxMemoryContentLength = 2000
+)
+
+// MemoryScope identifies whose memories a run sees and writes: the agent
+// definition's shared memories, plus the user's own when UserID is set.
+type MemoryScope struct {
+	ProjectID         string
+	AgentDefinitionID string
+	UserID            *string
+	RunID             string
+}
+
+// MemoryConfig is read from the "memory" key of an agent definition's config.
+type MemoryConfig struct {
+	// PerUser scopes memories written by a run to the user it runs for.
+	PerUser bool `json:"perUser"`
+	// SummarizeRuns stores a summary memory of every successful run.
+	SummarizeRuns bool `json:"summarizeRuns"`
+	// RecallLimit is how many memories are injected at run start (at most
+	// maxMemoryRecallLimit).
+	RecallLimit int `json:"recallLimit"`
+}
+
+// memoryConfig returns the memory settings of an agent definition.
+func memoryConfig(def *AgentDefinition) MemoryConfig {
+	var cfg MemoryConfig
+	if def != nil && def.Config != nil {
+		if raw, ok := def.Config["memory"]; ok {
+			if b, err := json.Marshal(raw); err == nil {
+				_ = json.Unmarshal(b, &cfg)
+			}
+		}
+	}
+	if cfg.RecallLimit <= 0 {
+		cfg.RecallLimit = defaultMemoryRecallLimit
+	}
+	if cfg.RecallLimit > maxMemoryRecallLimit {
+		cfg.RecallLimit = maxMemoryRecallLimit
+	}
+	return cfg
+}
+
+// MemoryEmbedder generates embeddings for memory content and recall queries.
+type MemoryEmbedder interface {
+	EmbedQuery(ctx context.Context, query string) ([]float32, error)
+}
+
+// MemoryService stores and recalls long-term agent memories.
+type MemoryService struct {
+	repo       *Repository
+	embeddings MemoryEmbedder
+	log        *slog.Logger
+}
+
+// NewMemoryService creates a new MemoryService. embeddings may be nil, in
+// which case recall falls back to the most recent memories.
+func NewMemoryService(repo *Repository, embeddings MemoryEmbedder, log *slog.Logger) *MemoryService {
+	return &MemoryService{
+		repo:       repo,
+		embeddings: embeddings,
+		log:        log.With(logger.Scope("agents.memory")),
+	}
+}
+
+// Remember stores a memory in a scope.
+func (s *MemoryService) Remember(ctx context.Context, scope MemoryScope, kind AgentMemoryKind, content string) (*AgentMemory, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("memory content is required")
+	}
+	content = truncateUTF8(content, maxMemoryContentLength)
+	if kind == "" {
+		kind = MemoryKindFact
+	}
+	if !kind.IsValid() {
+		return nil, fmt.Errorf("invalid memory kind %q", kind)
+	}
+
+	mem := &AgentMemory{
+		ProjectID:         scope.ProjectID,
+		AgentDefinitionID: scope.AgentDefinitionID,
+		UserID:            scope.UserID,
+		Kind:              kind,
+		Content:           content,
+	}
+	if scope.RunID != "" {
+		runID := scope.RunID
+		mem.SourceRunID = &runID
+	}
+	if err := s.repo.CreateMemory(ctx, mem, s.embed(ctx, content)); err != nil {
+		return nil, fmt.Errorf("failed to store memory: %w", err)
+	}
+	return mem, nil
+}
+
+// Forget deletes a memory visible in a scope. Returns false when there is no
+// such memory.
+func (s *MemoryService) Forget(ctx context.Context, scope MemoryScope, id string) (bool, error) {
+	return s.repo.DeleteMemoryInScope(ctx, scope, id)
+}
+
+// Recall returns up to limit memories in a scope relevant to query, most
+// similar first. Without an embedding for the query it returns the newest
+// memories instead.
+func (s *MemoryService) Recall(ctx context.Context, scope MemoryScope, query string, limit int) ([]*AgentMemory, error) {
+	has, err := s.repo.HasMemories(ctx, scope)
+	if err != nil || !has {
+		return nil, err
+	}
+
+	var mems []*AgentMemory
+	if vec := s.embed(ctx, query); len(vec) > 0 {
+		mems, err = s.repo.FindSimilarMemories(ctx, scope, vec, memoryRecallMaxDistance, limit)
+	} else {
+		mems, err = s.repo.FindRecentMemories(ctx, scope, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(mems))
+	for i, m := range mems {
+		ids[i] = m.ID
+	}
+	if err := s.repo.MarkMemoriesRecalled(ctx, ids); err != nil {
+		s.log.Warn("failed to mark memories recalled", slog.String("error", err.Error()))
+	}
+	return mems, nil
+}
+
+// embed returns the embedding of text, or nil when embeddings are unavailable.
+func (s *MemoryService) embed(ctx context.Context, text string) []float32 {
+	if s.embeddings == nil || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	vec, err := s.embeddings.EmbedQuery(ctx, text)
+	if err != nil {
+		s.log.Warn("failed to embed memory text", slog.String("error", err.Error()))
+		return nil
+	}
+	return vec
+}
+
+// formatMemories renders recalled memories as an instruction section.
+func formatMemories(mems []*AgentMemory) string {
+	if len(mems) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n## Memories\n")
+	b.WriteString("Things remembered from earlier runs. Use them when relevant. ")
+	b.WriteString("If one is wrong or outdated and you have the forget tool, call it with the memory ID.\n")
+	for _, m := range mems {
+		fmt.Fprintf(&b, "- [%s] (%s) %s\n", m.ID, m.Kind, m.Content)
+	}
+	return b.String()
+}
+
+// runSummaryMemory is the summary memory stored for a successful run.
+func runSummaryMemory(userMessage, finalResponse string, at time.Time) string {
+	return fmt.Sprintf("Run on %s. Asked: %s\nAnswered: %s",
+		at.UTC().Format("2006-01-02"), truncateText(userMessage, 300), truncateText(finalResponse, 1200))
+}
+
+func truncateText(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return truncateUTF8(s, n) + "…"
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+type memoryScopeKey struct{}
+
+// withMemoryScope attaches a run's memory scope to its context, for the
+// remember and forget tools.
+func withMemoryScope(ctx context.Context, scope MemoryScope) context.Context {
+	return context.WithValue(ctx, memoryScopeKey{}, scope)
+}
+
+func memoryScopeFromContext(ctx context.Context) (MemoryScope, bool) {
+	scope, ok := ctx.Value(memoryScopeKey{}).(MemoryScope)
+	return scope, ok
+}
+
+// BuildRememberTool creates the remember tool, which stores a memory in the
+// scope of the calling run.
+func BuildRememberTool(memory *MemoryService) (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        ToolNameRemember,
+			Description: "Store a long-term memory that will be recalled in future runs when relevant. Use it for durable facts, user preferences, or conclusions worth keeping. Keep each memory short and self-contained.",
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"content": {Type: "string", Description: "The memory to store"},
+					"kind": {
+						Type:        "string",
+						Description: "fact (default), preference or summary",
+						Enum:        []any{string(MemoryKindFact), string(MemoryKindPreference), string(MemoryKindSummary)},
+					},
+				},
+				Required: []string{"content"},
+			},
+		},
+		func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+			scope, ok := memoryScopeFromContext(ctx)
+			if !ok {
+				return map[string]any{"error": "memory is only available to agents with a definition"}, nil
+			}
+			content, _ := args["content"].(string)
+			kind, _ := args["kind"].(string)
+
+			mem, err := memory.Remember(ctx, scope, AgentMemoryKind(kind), content)
+			if err != nil {
+				return map[string]any{"error": err.Error()}, nil
+			}
+			return map[string]any{"memory_id": mem.ID, "status": "remembered"}, nil
+		},
+	)
+}
+
+// BuildForgetTool creates the forget tool, which deletes a memory visible to
+// the calling run.
+func BuildForgetTool(memory *MemoryService) (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        ToolNameForget,
+			Description: "Delete a long-term memory that is wrong or outdated, by the memory ID shown in the Memories section.",
+			InputSchema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"memory_id": {Type: "string", Description: "ID of the memory to delete"},
+				},
+				Required: []string{"memory_id"},
+			},
+		},
+		func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+			scope, ok := memoryScopeFromContext(ctx)
+			if !ok {
+				return map[string]any{"error": "memory is only available to agents with a definition"}, nil
+			}
+			id, _ := args["memory_id"].(string)
+			if id == "" {
+				return map[string]any{"error": "memory_id is required"}, nil
+			}
+
+			deleted, err := memory.Forget(ctx, scope, id)
+			if err != nil {
+				return map[string]any{"error": fmt.Sprintf("failed to forget memory: %s", err.Error())}, nil
+			}
+			if !deleted {
+				return map[string]any{"error": "memory not found"}, nil
+			}
+			return map[string]any{"memory_id": id, "status": "forgotten"}, nil
+		},
+	)
+}
@@ -0,0 +1,116 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryConfig(t *testing.T) {
+	cfg := memoryConfig(nil)
+	assert.Equal(t, MemoryConfig{RecallLimit: defaultMemoryRecallLimit}, cfg)
+
+	def := &AgentDefinition{Config: map[string]any{
+		"memory": map[string]any{"perUser": true, "summarizeRuns": true, "recallLimit": float64(3)},
+	}}
+	cfg = memoryConfig(def)
+	assert.True(t, cfg.PerUser)
+	assert.True(t, cfg.SummarizeRuns)
+	assert.Equal(t, 3, cfg.RecallLimit)
+
+	def.Config["memory"] = map[string]any{"recallLimit": float64(1000)}
+	assert.Equal(t, maxMemoryRecallLimit, memoryConfig(def).RecallLimit)
+
+	def.Config["memory"] = "not an object"
+	assert.Equal(t, MemoryConfig{RecallLimit: defaultMemoryRecallLimit}, memoryConfig(def))
+}
+
+func TestFormatMemories(t *testing.T) {
+	assert.Empty(t, formatMemories(nil))
+
+	out := formatMemories([]*AgentMemory{
+		{ID: "m1", Kind: MemoryKindPreference, Content: "Prefers short answers"},
+		{ID: "m2", Kind: MemoryKindFact, Content: "Acme is owned by Jane"},
+	})
+	assert.True(t, strings.HasPrefix(out, "\n\n## Memories\n"))
+	assert.Contains(t, out, "- [m1] (preference) Prefers short answers\n")
+	assert.Contains(t, out, "- [m2] (fact) Acme is owned by Jane\n")
+}
+
+func TestRunSummaryMemory(t *testing.T) {
+	at := time.Date(2026, 3, 4, 22, 0, 0, 0, time.FixedZone("PST", -8*3600))
+	out := runSummaryMemory("Who owns Acme?", strings.Repeat("x", 2000), at)
+
+	assert.True(t, strings.HasPrefix(out, "Run on 2026-03-05. Asked: Who owns Acme?\nAnswered: "))
+	assert.True(t, strings.HasSuffix(out, "…"), "long answers are truncated")
+	assert.Less(t, len(out), 1400)
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	assert.Equal(t, "short", truncateUTF8("short", 10))
+	assert.Equal(t, "héll", truncateUTF8("héllo", 5))
+	assert.Equal(t, "h", truncateUTF8("héllo", 2), "never splits a character")
+	assert.Equal(t, "", truncateUTF8("日本", 2))
+
+	long := strings.Repeat("日本語", 1000)
+	out := truncateUTF8(long, maxMemoryContentLength)
+	assert.True(t, utf8.ValidString(out))
+	assert.LessOrEqual(t, len(out), maxMemoryContentLength)
+
+	summary := runSummaryMemory(long, long, time.Now())
+	assert.True(t, utf8.ValidString(summary))
+}
+
+func TestAgentMemoryKind_IsValid(t *testing.T) {
+	assert.True(t, MemoryKindFact.IsValid())
+	assert.True(t, MemoryKindSummary.IsValid())
+	assert.False(t, AgentMemoryKind("opinion").IsValid())
+}
+
+func TestToolPool_ResolveMemoryTools(t *testing.T) {
+	tp := NewToolPool(ToolPoolConfig{MemoryService: &MemoryService{}})
+
+	names := func(def *AgentDefinition) []string {
+		tools, err := tp.resolveMemoryTools(def)
+		require.NoError(t, err)
+		var out []string
+		for _, tl := range tools {
+			out = append(out, tl.Name())
+		}
+		return out
+	}
+
+	assert.Empty(t, names(nil), "memory belongs to a definition")
+	assert.Empty(t, names(&AgentDefinition{}), "an empty whitelist does not grant memory")
+	assert.Equal(t, []string{ToolNameRemember}, names(&AgentDefinition{Tools: []string{"search", "remember"}}))
+	assert.Equal(t, []string{ToolNameRemember, ToolNameForget}, names(&AgentDefinition{Tools: []string{"*"}}))
+	assert.Equal(t, []string{ToolNameForget}, names(&AgentDefinition{Tools: []string{"forg?t"}}))
+
+	disabled := NewToolPool(ToolPoolConfig{})
+	tools, err := disabled.resolveMemoryTools(&AgentDefinition{Tools: []string{"*"}})
+	require.NoError(t, err)
+	assert.Empty(t, tools)
+}
+
+func TestMemoryScope(t *testing.T) {
+	ae := &AgentExecutor{memory: &MemoryService{}}
+
+	_, ok := ae.memoryScope(ExecuteRequest{ProjectID: "p1", UserID: "u1"}, "r1")
+	assert.False(t, ok, "runs without a definition have no memory")
+
+	def := &AgentDefinition{ID: "d1"}
+	scope, ok := ae.memoryScope(ExecuteRequest{ProjectID: "p1", AgentDefinition: def, UserID: "u1"}, "r1")
+	require.True(t, ok)
+	assert.Nil(t, scope.UserID, "memories are shared unless perUser is set")
+	assert.Equal(t, "r1", scope.RunID)
+
+	def.Config = map[string]any{"memory": map[string]any{"perUser": true}}
+	scope, ok = ae.memoryScope(ExecuteRequest{ProjectID: "p1", AgentDefinition: def, UserID: "u1"}, "r1")
+	require.True(t, ok)
+	require.NotNil(t, scope.UserID)
+	assert.Equal(t, "u1", *scope.UserID)
+}
@@ -10,10 +10,12 @@ import (
 	"github.com/emergent-company/emergent.memory/domain/mcp"
 	"github.com/emergent-company/emergent.memory/domain/mcpregistry"
 	"github.com/emergent-company/emergent.memory/domain/scheduler"
+	"github.com/emergent-company/emergent.memory/domain/tasks"
 	"github.com/emergent-company/emergent.memory/domain/workspace"
 	"github.com/emergent-company/emergent.memory/internal/config"
 	"github.com/emergent-company/emergent.memory/pkg/adk"
 	"github.com/emergent-company/emergent.memory/pkg/adk/session/bunsession"
+	"github.com/emergent-company/emergent.memory/pkg/embeddings"
 	"github.com/uptrace/bun"
 	"google.golang.org/adk/session"
 )
@@ -22,19 +24,27 @@ import (
 var Module = fx.Module("agents",
 	fx.Provide(
 		NewRepository,
+		provideMemoryService,
 		provideToolPool,
 		provideSessionService,
+		provideChangeSetService,
 		provideAgentExecutor,
+		provideEvalService,
 		provideHandler,
 		provideTriggerService,
+		provideEventDispatcher,
 		provideMCPToolHandler,
+		NewAgentPublisher,
 		provideWebhookRateLimiter,
 	),
 	fx.Invoke(
 		RegisterRoutes,
 		registerAgentTriggers,
+		registerEventDispatcher,
 		registerAgentToolHandler,
+		registerChangeSetTaskHandler,
 		registerToolPoolInvalidator,
+		registerAgentPublisher,
 	),
 )
 
@@ -47,11 +57,21 @@ func provideWebhookRateLimiter() *WebhookRateLimiter {
 	return NewWebhookRateLimiter()
 }
 
+// provideMemoryService creates a MemoryService from fx dependencies.
+func provideMemoryService(repo *Repository, embeds *embeddings.Service, log *slog.Logger) *MemoryService {
+	if embeds == nil {
+		return NewMemoryService(repo, nil, log)
+	}
+	return NewMemoryService(repo, embeds, log)
+}
+
 // provideToolPool creates a ToolPool from fx dependencies.
-func provideToolPool(mcpService *mcp.Service, registryService *mcpregistry.Service, log *slog.Logger) *ToolPool {
+func provideToolPool(mcpService *mcp.Service, registryService *mcpregistry.Service, memory *MemoryService, notifier *mcp.Notifier, log *slog.Logger) *ToolPool {
 	return NewToolPool(ToolPoolConfig{
 		MCPService:      mcpService,
 		RegistryService: registryService,
+		MemoryService:   memory,
+		Notifier:        notifier,
 		Logger:          log,
 	})
 }
@@ -64,14 +84,26 @@ func provideAgentExecutor(
 	provisioner *workspace.AutoProvisioner,
 	cfg *config.Config,
 	sessionService session.Service,
+	changeSets *ChangeSetService,
+	memory *MemoryService,
 	log *slog.Logger,
 ) *AgentExecutor {
-	return NewAgentExecutor(modelFactory, toolPool, repo, provisioner, cfg, sessionService, log)
+	return NewAgentExecutor(modelFactory, toolPool, repo, provisioner, cfg, sessionService, changeSets, memory, log)
 }
 
-// provideHandler creates a Handler with both repo and executor.
-func provideHandler(repo *Repository, executor *AgentExecutor, rateLimiter *WebhookRateLimiter) *Handler {
-	return NewHandler(repo, executor, rateLimiter)
+// provideChangeSetService creates a ChangeSetService from fx dependencies.
+func provideChangeSetService(repo *Repository, mcpService *mcp.Service, log *slog.Logger) *ChangeSetService {
+	return NewChangeSetService(repo, mcpService, log)
+}
+
+// provideEvalService creates an EvalService from fx dependencies.
+func provideEvalService(repo *Repository, executor *AgentExecutor, mcpService *mcp.Service, modelFactory *adk.ModelFactory, log *slog.Logger) *EvalService {
+	return NewEvalService(repo, executor, mcpService, modelFactory, log)
+}
+
+// provideHandler creates a Handler with the repo, executor and eval service.
+func provideHandler(repo *Repository, executor *AgentExecutor, evals *EvalService, rateLimiter *WebhookRateLimiter) *Handler {
+	return NewHandler(repo, executor, evals, rateLimiter)
 }
 
 // provideTriggerService creates a TriggerService from fx dependencies.
@@ -85,6 +117,11 @@ func provideTriggerService(
 	return NewTriggerService(sched, executor, repo, eventService, log)
 }
 
+// provideEventDispatcher creates an EventDispatcher from fx dependencies.
+func provideEventDispatcher(repo *Repository, triggers *TriggerService, log *slog.Logger) *EventDispatcher {
+	return NewEventDispatcher(repo, triggers, log)
+}
+
 // provideMCPToolHandler creates an MCPToolHandler from fx dependencies.
 func provideMCPToolHandler(repo *Repository, executor *AgentExecutor, log *slog.Logger) *MCPToolHandler {
 	return NewMCPToolHandler(repo, executor, log)
@@ -96,6 +133,12 @@ func registerAgentToolHandler(mcpService *mcp.Service, handler *MCPToolHandler)
 	mcpService.SetAgentToolHandler(handler)
 }
 
+// registerChangeSetTaskHandler lets the tasks inbox approve or reject change
+// sets staged by suggest/hybrid mode agents.
+func registerChangeSetTaskHandler(tasksService *tasks.Service, changeSets *ChangeSetService) {
+	tasksService.SetResolutionHandler(ChangeSetTaskType, changeSets)
+}
+
 // registerAgentTriggers syncs all agent triggers on startup.
 func registerAgentTriggers(lc fx.Lifecycle, ts *TriggerService) {
 	lc.Append(fx.Hook{
@@ -111,9 +154,30 @@ func registerAgentTriggers(lc fx.Lifecycle, ts *TriggerService) {
 	})
 }
 
+// registerEventDispatcher starts delivering outbox events to reaction agents
+// on startup and stops on shutdown.
+func registerEventDispatcher(lc fx.Lifecycle, d *EventDispatcher) {
+	lc.Append(fx.Hook{
+		OnStart: d.Start,
+		OnStop:  d.Stop,
+	})
+}
+
 // registerToolPoolInvalidator injects the ToolPool into the MCP registry service
 // so that registry mutations (create/update/delete server, sync/toggle tools)
 // automatically invalidate the ToolPool cache for the affected project.
 func registerToolPoolInvalidator(registryService *mcpregistry.Service, toolPool *ToolPool) {
 	registryService.SetToolPoolInvalidator(toolPool)
 }
+
+// registerAgentPublisher publishes agent definitions as MCP tools and prompts.
+// A definition change invalidates the project's ToolPool cache, which tells
+// connected MCP sessions to refetch tools/list, and asks them to refetch
+// prompts/list.
+func registerAgentPublisher(mcpService *mcp.Service, publisher *AgentPublisher, repo *Repository, toolPool *ToolPool, notifier *mcp.Notifier) {
+	mcpService.SetAgentPublisher(publisher)
+	repo.OnDefinitionsChanged(func(projectID string) {
+		toolPool.InvalidateCache(projectID)
+		notifier.PromptsChanged(projectID)
+	})
+}
@@ -0,0 +1,412 @@
+package agents
+
+// Run replay re-executes an agent definition against a recorded run. Tool
+// calls are served from the recorded tool call results instead of running, so
+// a replay has no side effects unless tools are explicitly marked live. The
+// model decides again at every step, except that a fork reuses the recorded
+// model turns before the fork step verbatim, so only later decisions can
+// change. Replays work with any model.LLM, including stubs, which lets
+// production incidents be turned into regression tests.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log/slog"
+	"path"
+	"sort"
+	"sync"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+const (
+	replayTriggerSource = "replay"
+
+	// replayNoRecording is the error code served for a tool call that has no
+	// matching recorded call and is not live.
+	replayNoRecording = "REPLAY_NO_RECORDING"
+)
+
+// ReplayOptions configures a replay of a recorded run.
+type ReplayOptions struct {
+	// LiveTools lists tool names or glob patterns that run for real instead
+	// of being served from the recording.
+	LiveTools []string
+	// ForkFromStep reuses the recorded model turns before this step, so the
+	// model only decides again from this step onward. Zero replays every step.
+	ForkFromStep int
+	// Model replaces the agent's configured model, e.g. with a stub in tests.
+	Model model.LLM
+}
+
+// ReplayResult is the outcome of a replay and how it differs from the
+// recorded run.
+type ReplayResult struct {
+	*ExecuteResult
+	SourceRunID string
+	Diff        *ReplayDiff
+}
+
+// ReplayDecision is a tool call made by the model at a step.
+type ReplayDecision struct {
+	Step     int            `json:"step"`
+	ToolName string         `json:"toolName"`
+	Input    map[string]any `json:"input"`
+}
+
+// ReplayDivergence pairs the recorded and replayed decisions at a position in
+// the tool call sequence. Either side is nil when one run made fewer calls.
+type ReplayDivergence struct {
+	Index    int             `json:"index"`
+	Original *ReplayDecision `json:"original,omitempty"`
+	Replayed *ReplayDecision `json:"replayed,omitempty"`
+}
+
+// ReplayDiff compares the decisions of a replay against the recorded run.
+type ReplayDiff struct {
+	Identical        bool               `json:"identical"`
+	OriginalCalls    int                `json:"originalCalls"`
+	ReplayedCalls    int                `json:"replayedCalls"`
+	Divergences      []ReplayDivergence `json:"divergences"`
+	OriginalResponse string             `json:"originalResponse,omitempty"`
+	ReplayedResponse string             `json:"replayedResponse,omitempty"`
+	ResponseChanged  bool               `json:"responseChanged"`
+}
+
+// ReplayRunRequest is the request body for replaying a run.
+type ReplayRunRequest struct {
+	// Prompt replaces the recorded user message.
+	Prompt string `json:"prompt,omitempty"`
+	// SystemPrompt replaces the agent definition's system prompt.
+	SystemPrompt *string `json:"systemPrompt,omitempty"`
+	// ForkFromStep keeps the recorded decisions before this step.
+	ForkFromStep int `json:"forkFromStep,omitempty"`
+	// LiveTools run for real instead of being served from the recording.
+	LiveTools []string `json:"liveTools,omitempty"`
+}
+
+// ReplayResultDTO is the response for a replay.
+type ReplayResultDTO struct {
+	RunID       string         `json:"runId"`
+	SourceRunID string         `json:"sourceRunId"`
+	Status      AgentRunStatus `json:"status"`
+	Steps       int            `json:"steps"`
+	Summary     map[string]any `json:"summary"`
+	Diff        *ReplayDiff    `json:"diff"`
+}
+
+// ToDTO converts a replay result to its response form.
+func (r *ReplayResult) ToDTO() ReplayResultDTO {
+	return ReplayResultDTO{
+		RunID:       r.RunID,
+		SourceRunID: r.SourceRunID,
+		Status:      r.Status,
+		Steps:       r.Steps,
+		Summary:     r.Summary,
+		Diff:        r.Diff,
+	}
+}
+
+// Replay re-executes req against the recorded run source and diffs the new
+// tool call decisions and final response against the recording. When
+// req.UserMessage is empty the recorded user message is used.
+func (ae *AgentExecutor) Replay(ctx context.Context, source *AgentRun, req ExecuteRequest, opts ReplayOptions) (*ReplayResult, error) {
+	if opts.ForkFromStep < 0 {
+		return nil, fmt.Errorf("fork step must not be negative, got %d", opts.ForkFromStep)
+	}
+
+	messages, err := ae.repo.FindMessagesByRunID(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recorded messages: %w", err)
+	}
+	recorded, err := ae.repo.FindToolCallsByRunID(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recorded tool calls: %w", err)
+	}
+
+	if req.UserMessage == "" {
+		req.UserMessage = recordedUserMessage(messages)
+	}
+	if req.UserMessage == "" {
+		return nil, fmt.Errorf("run %s has no recorded user message", source.ID)
+	}
+
+	triggerSource := replayTriggerSource
+	req.TriggerSource = &triggerSource
+	req.TriggerMetadata = map[string]any{"replay_of": source.ID}
+	if opts.ForkFromStep > 0 {
+		req.TriggerMetadata["fork_from_step"] = opts.ForkFromStep
+	}
+	if len(opts.LiveTools) > 0 {
+		req.TriggerMetadata["live_tools"] = opts.LiveTools
+	}
+	req.replay = newReplayState(messages, recorded, opts)
+
+	ae.log.Info("replaying agent run",
+		slog.String("source_run_id", source.ID),
+		slog.Int("fork_from_step", opts.ForkFromStep),
+		slog.Int("recorded_tool_calls", len(recorded)),
+	)
+
+	result, err := ae.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed, err := ae.repo.FindToolCallsByRunID(ctx, result.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load replayed tool calls: %w", err)
+	}
+	originalResponse, _ := source.Summary["final_response"].(string)
+	replayedResponse, _ := result.Summary["final_response"].(string)
+
+	return &ReplayResult{
+		ExecuteResult: result,
+		SourceRunID:   source.ID,
+		Diff:          diffReplay(recorded, replayed, originalResponse, replayedResponse),
+	}, nil
+}
+
+// replayState is the recording a replayed run is served from.
+type replayState struct {
+	model     model.LLM
+	turns     []*genai.Content
+	liveTools []string
+
+	mu       sync.Mutex
+	recorded []*AgentRunToolCall
+	used     []bool
+}
+
+func newReplayState(messages []*AgentRunMessage, recorded []*AgentRunToolCall, opts ReplayOptions) *replayState {
+	s := &replayState{
+		model:     opts.Model,
+		liveTools: opts.LiveTools,
+		recorded:  recorded,
+		used:      make([]bool, len(recorded)),
+	}
+	if opts.ForkFromStep > 0 {
+		s.turns = recordedModelTurns(messages, opts.ForkFromStep)
+	}
+	return s
+}
+
+// wrapModel returns llm, preceded by the recorded turns kept by a fork.
+func (s *replayState) wrapModel(llm model.LLM) model.LLM {
+	if s == nil || len(s.turns) == 0 {
+		return llm
+	}
+	return &recordedModel{turns: s.turns, live: llm}
+}
+
+// serveToolCall returns the recorded result for a tool call, or nil when the
+// tool is live and should run. A call with no unused recorded call of the
+// same tool and input gets an error result rather than running.
+func (s *replayState) serveToolCall(toolName string, args map[string]any) map[string]any {
+	if s == nil {
+		return nil
+	}
+	for _, pattern := range s.liveTools {
+		if ok, _ := path.Match(pattern, toolName); ok {
+			return nil
+		}
+	}
+
+	key := canonicalArgs(args)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, tc := range s.recorded {
+		if s.used[i] || tc.ToolName != toolName || canonicalArgs(tc.Input) != key {
+			continue
+		}
+		s.used[i] = true
+		output := make(map[string]any, len(tc.Output))
+		for k, v := range tc.Output {
+			output[k] = v
+		}
+		return output
+	}
+	return map[string]any{
+		"error":   replayNoRecording,
+		"message": fmt.Sprintf("No recorded result for this call to %q. The replay diverged from the recording.", toolName),
+	}
+}
+
+// recordedModel serves recorded or scripted model turns in order, then hands
+// over to the live model. Without a live model, running past the recording is
+// an error.
+type recordedModel struct {
+	mu    sync.Mutex
+	turns []*genai.Content
+	next  int
+	live  model.LLM
+}
+
+func (m *recordedModel) Name() string {
+	if m.live != nil {
+		return m.live.Name()
+	}
+	return "recorded"
+}
+
+func (m *recordedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	m.mu.Lock()
+	if m.next < len(m.turns) {
+		content := m.turns[m.next]
+		m.next++
+		m.mu.Unlock()
+		return func(yield func(*model.LLMResponse, error) bool) {
+			yield(&model.LLMResponse{Content: content, TurnComplete: true}, nil)
+		}
+	}
+	m.mu.Unlock()
+
+	if m.live == nil {
+		return func(yield func(*model.LLMResponse, error) bool) {
+			yield(nil, fmt.Errorf("recording has no model turn %d", len(m.turns)+1))
+		}
+	}
+	return m.live.GenerateContent(ctx, req, stream)
+}
+
+// recordedModelTurns rebuilds the model's responses before step beforeStep
+// from the persisted run messages, one turn per step.
+func recordedModelTurns(messages []*AgentRunMessage, beforeStep int) []*genai.Content {
+	byStep := make(map[int]*genai.Content)
+	for _, msg := range messages {
+		if msg.Role == "user" || msg.Role == "system" || msg.StepNumber >= beforeStep {
+			continue
+		}
+		content, ok := byStep[msg.StepNumber]
+		if !ok {
+			content = &genai.Content{Role: genai.RoleModel}
+			byStep[msg.StepNumber] = content
+		}
+		for _, text := range contentStrings(msg.Content["text"]) {
+			content.Parts = append(content.Parts, genai.NewPartFromText(text))
+		}
+		for _, call := range contentMaps(msg.Content["function_calls"]) {
+			name, _ := call["name"].(string)
+			args, _ := call["args"].(map[string]any)
+			content.Parts = append(content.Parts, genai.NewPartFromFunctionCall(name, args))
+		}
+	}
+
+	steps := make([]int, 0, len(byStep))
+	for step, content := range byStep {
+		if len(content.Parts) > 0 {
+			steps = append(steps, step)
+		}
+	}
+	sort.Ints(steps)
+	turns := make([]*genai.Content, len(steps))
+	for i, step := range steps {
+		turns[i] = byStep[step]
+	}
+	return turns
+}
+
+// recordedUserMessage returns the first user message of a run.
+func recordedUserMessage(messages []*AgentRunMessage) string {
+	for _, msg := range messages {
+		if msg.Role != "user" {
+			continue
+		}
+		if texts := contentStrings(msg.Content["text"]); len(texts) > 0 {
+			return texts[0]
+		}
+	}
+	return ""
+}
+
+// diffReplay compares the recorded and replayed tool calls position by
+// position, and the final responses.
+func diffReplay(original, replayed []*AgentRunToolCall, originalResponse, replayedResponse string) *ReplayDiff {
+	diff := &ReplayDiff{
+		OriginalCalls:    len(original),
+		ReplayedCalls:    len(replayed),
+		Divergences:      []ReplayDivergence{},
+		OriginalResponse: originalResponse,
+		ReplayedResponse: replayedResponse,
+		ResponseChanged:  originalResponse != replayedResponse,
+	}
+	for i := 0; i < max(len(original), len(replayed)); i++ {
+		var o, r *AgentRunToolCall
+		if i < len(original) {
+			o = original[i]
+		}
+		if i < len(replayed) {
+			r = replayed[i]
+		}
+		if o != nil && r != nil && o.ToolName == r.ToolName && canonicalArgs(o.Input) == canonicalArgs(r.Input) {
+			continue
+		}
+		diff.Divergences = append(diff.Divergences, ReplayDivergence{
+			Index:    i,
+			Original: toReplayDecision(o),
+			Replayed: toReplayDecision(r),
+		})
+	}
+	diff.Identical = len(diff.Divergences) == 0 && !diff.ResponseChanged
+	return diff
+}
+
+func toReplayDecision(tc *AgentRunToolCall) *ReplayDecision {
+	if tc == nil {
+		return nil
+	}
+	return &ReplayDecision{Step: tc.StepNumber, ToolName: tc.ToolName, Input: tc.Input}
+}
+
+// canonicalArgs encodes tool arguments with sorted keys so recorded and live
+// arguments compare equal regardless of their Go types.
+func canonicalArgs(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	b, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprint(args)
+	}
+	return string(b)
+}
+
+// contentStrings reads a message content value stored as a string or a list
+// of strings, either freshly built or decoded from JSON.
+func contentStrings(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// contentMaps reads a message content value stored as a list of objects.
+func contentMaps(v any) []map[string]any {
+	switch t := v.(type) {
+	case []map[string]any:
+		return t
+	case []any:
+		out := make([]map[string]any, 0, len(t))
+		for _, item := range t {
+			if m, ok := item.(map[string]any); ok {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+	return nil
+}
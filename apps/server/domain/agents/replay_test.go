@@ -0,0 +1,190 @@
+package agents
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// stubModel answers every call with the same text.
+type stubModel struct{ text string }
+
+func (m *stubModel) Name() string { return "stub" }
+
+func (m *stubModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.text, genai.RoleModel)}, nil)
+	}
+}
+
+// recordedIncident is a run as stored: messages decoded from JSONB and the
+// tool calls the model made at step 1 and 2.
+func recordedIncident() ([]*AgentRunMessage, []*AgentRunToolCall) {
+	messages := []*AgentRunMessage{
+		{Role: "user", Content: map[string]any{"text": "Find the owner of Acme"}, StepNumber: 0},
+		{Role: "researcher", Content: map[string]any{
+			"function_calls": []any{map[string]any{"name": "search", "args": map[string]any{"query": "Acme", "limit": float64(5)}}},
+		}, StepNumber: 1},
+		{Role: "researcher", Content: map[string]any{
+			"function_calls": []any{map[string]any{"name": "get_object", "args": map[string]any{"id": "o1"}}},
+		}, StepNumber: 2},
+		{Role: "researcher", Content: map[string]any{"text": []any{"Acme is owned by Jane."}}, StepNumber: 3},
+	}
+	toolCalls := []*AgentRunToolCall{
+		{ToolName: "search", Input: map[string]any{"query": "Acme", "limit": float64(5)}, Output: map[string]any{"ids": []any{"o1"}}, StepNumber: 1},
+		{ToolName: "get_object", Input: map[string]any{"id": "o1"}, Output: map[string]any{"owner": "Jane"}, StepNumber: 2},
+	}
+	return messages, toolCalls
+}
+
+func TestReplayState_ServeToolCall(t *testing.T) {
+	messages, recorded := recordedIncident()
+	s := newReplayState(messages, recorded, ReplayOptions{LiveTools: []string{"workspace_*"}})
+
+	// Live arguments are Go values; recorded ones were decoded from JSON.
+	out := s.serveToolCall("search", map[string]any{"limit": 5, "query": "Acme"})
+	assert.Equal(t, map[string]any{"ids": []any{"o1"}}, out)
+
+	out = s.serveToolCall("search", map[string]any{"limit": 5, "query": "Acme"})
+	assert.Equal(t, replayNoRecording, out["error"], "each recorded call is served once")
+
+	out = s.serveToolCall("get_object", map[string]any{"id": "o2"})
+	assert.Equal(t, replayNoRecording, out["error"], "different arguments diverge")
+
+	assert.Nil(t, s.serveToolCall("workspace_bash", map[string]any{"command": "ls"}), "live tools run")
+	assert.Nil(t, (*replayState)(nil).serveToolCall("search", nil), "no replay serves nothing")
+}
+
+func TestRecordedModelTurns(t *testing.T) {
+	messages, _ := recordedIncident()
+
+	turns := recordedModelTurns(messages, 3)
+	require.Len(t, turns, 2, "steps 1 and 2 precede the fork")
+	require.NotNil(t, turns[0].Parts[0].FunctionCall)
+	assert.Equal(t, "search", turns[0].Parts[0].FunctionCall.Name)
+	assert.Equal(t, "Acme", turns[0].Parts[0].FunctionCall.Args["query"])
+	assert.Equal(t, "get_object", turns[1].Parts[0].FunctionCall.Name)
+
+	turns = recordedModelTurns(messages, 100)
+	require.Len(t, turns, 3)
+	assert.Equal(t, "Acme is owned by Jane.", turns[2].Parts[0].Text)
+
+	assert.Equal(t, "Find the owner of Acme", recordedUserMessage(messages))
+}
+
+func TestRecordedModel_HandsOverToLiveModel(t *testing.T) {
+	messages, _ := recordedIncident()
+	s := newReplayState(messages, nil, ReplayOptions{ForkFromStep: 2})
+	llm := s.wrapModel(&stubModel{text: "edited answer"})
+
+	next := func() (*model.LLMResponse, error) {
+		for resp, err := range llm.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+			return resp, err
+		}
+		return nil, nil
+	}
+
+	resp, err := next()
+	require.NoError(t, err)
+	assert.Equal(t, "search", resp.Content.Parts[0].FunctionCall.Name, "recorded turn before the fork")
+
+	resp, err = next()
+	require.NoError(t, err)
+	assert.Equal(t, "edited answer", resp.Content.Parts[0].Text, "live model from the fork step")
+
+	exhausted := &recordedModel{}
+	for _, err := range exhausted.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+		assert.Error(t, err, "no live model past the recording")
+	}
+}
+
+func TestDiffReplay(t *testing.T) {
+	_, original := recordedIncident()
+
+	diff := diffReplay(original, original, "Jane", "Jane")
+	assert.True(t, diff.Identical)
+	assert.Empty(t, diff.Divergences)
+
+	replayed := []*AgentRunToolCall{
+		{ToolName: "search", Input: map[string]any{"limit": 5, "query": "Acme"}, StepNumber: 1},
+		{ToolName: "get_object", Input: map[string]any{"id": "o9"}, StepNumber: 2},
+		{ToolName: "get_object", Input: map[string]any{"id": "o1"}, StepNumber: 3},
+	}
+	diff = diffReplay(original, replayed, "Jane", "Unknown")
+	assert.False(t, diff.Identical)
+	assert.True(t, diff.ResponseChanged)
+	assert.Equal(t, 2, diff.OriginalCalls)
+	assert.Equal(t, 3, diff.ReplayedCalls)
+	require.Len(t, diff.Divergences, 2)
+	assert.Equal(t, 1, diff.Divergences[0].Index)
+	assert.Equal(t, "o1", diff.Divergences[0].Original.Input["id"])
+	assert.Equal(t, "o9", diff.Divergences[0].Replayed.Input["id"])
+	assert.Nil(t, diff.Divergences[1].Original, "the replay made an extra call")
+}
+
+// TestReplay_RecordedIncidentAsRegressionTest runs a recorded incident
+// through ADK with the recording as the model, serving tool results from the
+// recording, and checks that no tool runs and the recorded answer comes back.
+func TestReplay_RecordedIncidentAsRegressionTest(t *testing.T) {
+	messages, recorded := recordedIncident()
+	s := newReplayState(messages, recorded, ReplayOptions{ForkFromStep: 100})
+
+	var ran int
+	newTool := func(name string) tool.Tool {
+		tl, err := functiontool.New(functiontool.Config{Name: name, Description: name},
+			func(tool.Context, map[string]any) (map[string]any, error) {
+				ran++
+				return map[string]any{}, nil
+			})
+		require.NoError(t, err)
+		return tl
+	}
+
+	var served []map[string]any
+	root, err := llmagent.New(llmagent.Config{
+		Name:        "researcher",
+		Model:       s.wrapModel(nil),
+		Instruction: "You research owners.",
+		Tools:       []tool.Tool{newTool("search"), newTool("get_object")},
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{
+			func(_ tool.Context, tl tool.Tool, args map[string]any) (map[string]any, error) {
+				out := s.serveToolCall(tl.Name(), args)
+				served = append(served, out)
+				return out, nil
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	sessions := session.InMemoryService()
+	created, err := sessions.Create(ctx, &session.CreateRequest{AppName: "agents", UserID: "system"})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{Agent: root, SessionService: sessions, AppName: "agents"})
+	require.NoError(t, err)
+
+	var final string
+	userMsg := genai.NewContentFromText(recordedUserMessage(messages), genai.RoleUser)
+	for event, err := range r.Run(ctx, "system", created.Session.ID(), userMsg, agent.RunConfig{}) {
+		require.NoError(t, err)
+		if event.IsFinalResponse() && event.Content != nil && len(event.Content.Parts) > 0 {
+			final = event.Content.Parts[0].Text
+		}
+	}
+
+	assert.Zero(t, ran, "tools are served from the recording")
+	require.Len(t, served, 2)
+	assert.Equal(t, map[string]any{"owner": "Jane"}, served[1])
+	assert.Equal(t, "Acme is owned by Jane.", final)
+}
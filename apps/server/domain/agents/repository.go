@@ -7,13 +7,36 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/emergent-company/emergent.memory/domain/events"
 	"github.com/emergent-company/emergent.memory/pkg/adk/session/bunsession"
+	"github.com/emergent-company/emergent.memory/pkg/pgutils"
 	"github.com/uptrace/bun"
 )
 
 // Repository handles database operations for agents
 type Repository struct {
 	db bun.IDB
+
+	// definitionsChanged are called with the project of an agent definition
+	// after it is created, updated or deleted
+	definitionsChanged []func(projectID string)
+}
+
+// OnDefinitionsChanged registers fn to be called with the project of an agent
+// definition after it is created, updated or deleted. Hooks registered
+// earlier keep being called.
+func (r *Repository) OnDefinitionsChanged(fn func(projectID string)) {
+	r.definitionsChanged = append(r.definitionsChanged, fn)
+}
+
+// notifyDefinitionsChanged calls the definitions changed hooks.
+func (r *Repository) notifyDefinitionsChanged(projectID string) {
+	if projectID == "" {
+		return
+	}
+	for _, fn := range r.definitionsChanged {
+		fn(projectID)
+	}
 }
 
 // NewRepository creates a new agents repository
@@ -260,70 +283,74 @@ func (r *Repository) MarkProcessingLogStatus(ctx context.Context, id string, sta
 	return err
 }
 
-// GetPendingEvents returns graph objects that haven't been processed by an agent
-// This is used to show unprocessed objects in the admin UI
+// GetPendingEvents returns the events a reaction agent has yet to process:
+// queued deliveries that are pending or in progress, followed by matching
+// outbox events the dispatcher has not queued yet (status "new").
 func (r *Repository) GetPendingEvents(ctx context.Context, agent *Agent, limit int) ([]PendingEventObjectDTO, int, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 100
 	}
 
-	// Build the query to find objects matching the agent's filters
-	// that haven't been successfully processed by this agent
-	type GraphObject struct {
-		ID        string    `bun:"id"`
-		Type      string    `bun:"type"`
-		Key       string    `bun:"key"`
-		Version   int       `bun:"version"`
-		CreatedAt time.Time `bun:"created_at"`
-		UpdatedAt time.Time `bun:"updated_at"`
+	queued := `
+		SELECT apl.graph_object_id AS id, COALESCE(go.type, '') AS type, COALESCE(go.key, '') AS key,
+			apl.object_version AS version,
+			COALESCE(go.created_at, apl.created_at) AS created_at, COALESCE(go.updated_at, apl.created_at) AS updated_at,
+			apl.event_type, apl.status, apl.attempt_count, apl.next_attempt_at, apl.error_message, apl.event_id,
+			apl.created_at AS queued_at
+		FROM kb.agent_processing_log AS apl
+		LEFT JOIN kb.graph_objects AS go ON go.id = apl.graph_object_id
+		WHERE apl.agent_id = ? AND apl.status IN ('pending', 'processing')`
+	args := []any{agent.ID}
+
+	unqueued := `
+		SELECT eeo.entity_id AS id, COALESCE(go.type, eeo.object_type, '') AS type, COALESCE(go.key, '') AS key,
+			COALESCE(eeo.version, 0) AS version,
+			COALESCE(go.created_at, eeo.created_at) AS created_at, COALESCE(go.updated_at, eeo.created_at) AS updated_at,
+			replace(eeo.event_type, 'entity.', '') AS event_type, 'new' AS status, 0 AS attempt_count,
+			NULL::timestamptz AS next_attempt_at, NULL AS error_message, eeo.id AS event_id,
+			eeo.created_at AS queued_at
+		FROM kb.entity_event_outbox AS eeo
+		LEFT JOIN kb.agent_event_cursors AS aec ON aec.agent_id = ?
+		LEFT JOIN kb.graph_objects AS go ON go.id = eeo.entity_id
+		WHERE eeo.project_id = ?
+		AND eeo.entity = ?
+		AND (eeo.tx_id, eeo.id) > (COALESCE(aec.last_tx_id, 0), COALESCE(aec.last_event_id, 0))
+		AND (aec.agent_id IS NOT NULL OR eeo.created_at >= ?)
+		AND eeo.actor_type IS DISTINCT FROM ?`
+	args = append(args, agent.ID, agent.ProjectID, events.EntityGraphObject, agent.CreatedAt, events.ActorAgent)
+
+	var eventTypes []events.EntityEventType
+	if agent.ReactionConfig != nil {
+		for _, e := range agent.ReactionConfig.Events {
+			eventTypes = append(eventTypes, events.EntityEventType("entity."+string(e)))
+		}
+		if len(agent.ReactionConfig.ObjectTypes) > 0 {
+			unqueued += ` AND eeo.object_type IN (?)`
+			args = append(args, bun.In(agent.ReactionConfig.ObjectTypes))
+		}
+	}
+	if len(eventTypes) == 0 {
+		unqueued += ` AND false`
+	} else {
+		unqueued += ` AND eeo.event_type IN (?)`
+		args = append(args, bun.In(eventTypes))
 	}
 
-	var objects []GraphObject
-	q := r.db.NewSelect().
-		TableExpr("kb.graph_objects AS go").
-		Column("go.id", "go.type", "go.key", "go.version", "go.created_at", "go.updated_at").
-		Where("go.project_id = ?", agent.ProjectID).
-		Where("go.deleted_at IS NULL")
-
-	// Filter by object types if specified
-	if agent.ReactionConfig != nil && len(agent.ReactionConfig.ObjectTypes) > 0 {
-		q = q.Where("go.type IN (?)", bun.In(agent.ReactionConfig.ObjectTypes))
-	}
-
-	// Exclude objects that have been successfully processed
-	q = q.Where(`NOT EXISTS (
-		SELECT 1 FROM kb.agent_processing_log apl
-		WHERE apl.agent_id = ?
-		AND apl.graph_object_id = go.id
-		AND apl.object_version = go.version
-		AND apl.status = 'completed'
-	)`, agent.ID)
-
-	// Get total count
-	totalCount, err := q.Count(ctx)
-	if err != nil {
+	union := queued + "\n\t\tUNION ALL" + unqueued
+
+	var totalCount int
+	if err := r.db.NewRaw("SELECT count(*) FROM ("+union+") AS pending", args...).Scan(ctx, &totalCount); err != nil {
 		return nil, 0, err
 	}
 
-	// Get limited results
-	err = q.Order("go.updated_at DESC").
-		Limit(limit).
-		Scan(ctx, &objects)
+	var dtos []PendingEventObjectDTO
+	err := r.db.NewRaw("SELECT * FROM ("+union+") AS pending ORDER BY queued_at, event_id LIMIT ?", append(args, limit)...).
+		Scan(ctx, &dtos)
 	if err != nil {
 		return nil, 0, err
 	}
-
-	// Convert to DTOs
-	dtos := make([]PendingEventObjectDTO, len(objects))
-	for i, obj := range objects {
-		dtos[i] = PendingEventObjectDTO{
-			ID:        obj.ID,
-			Type:      obj.Type,
-			Key:       obj.Key,
-			Version:   obj.Version,
-			CreatedAt: obj.CreatedAt,
-			UpdatedAt: obj.UpdatedAt,
-		}
+	if dtos == nil {
+		dtos = []PendingEventObjectDTO{}
 	}
 
 	return dtos, totalCount, nil
@@ -361,6 +388,321 @@ func (r *Repository) MarkStuckJobsAsAbandoned(ctx context.Context, olderThan tim
 	return int(n), nil
 }
 
+// --- Entity Event Delivery ---
+
+// outboxSettled limits outbox reads to rows written by transactions older than
+// every transaction still in progress. No row that sorts before such a row can
+// still appear, so a cursor over (tx_id, id) never skips an event.
+const outboxSettled = "eeo.tx_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint"
+
+// cursorStartSQL selects the outbox position of the last event in a project
+// written before a point in time, or (0, 0) when there is none.
+const cursorStartSQL = `
+	SELECT ?, COALESCE(last.tx_id, 0), COALESCE(last.id, 0)
+	FROM (SELECT 1) AS one
+	LEFT JOIN LATERAL (
+		SELECT tx_id, id FROM kb.entity_event_outbox
+		WHERE project_id = ? AND created_at < ?
+		ORDER BY tx_id DESC, id DESC
+		LIMIT 1
+	) AS last ON true`
+
+// QueueOutboxEvents advances a reaction agent's outbox cursor by up to limit
+// events, queueing those accepted by match in the processing log. Events
+// already queued are left alone unless their delivery failed or was abandoned,
+// in which case they are queued again. A new cursor starts at the agent's
+// creation time. Returns the number of events queued; when another replica
+// holds the agent's cursor, nothing is done.
+func (r *Repository) QueueOutboxEvents(
+	ctx context.Context,
+	agent *Agent,
+	limit int,
+	match func(evt *events.OutboxEvent) (ReactionEventType, bool),
+) (int, error) {
+	queued := 0
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewRaw(`INSERT INTO kb.agent_event_cursors (agent_id, last_tx_id, last_event_id)`+cursorStartSQL+`
+			ON CONFLICT (agent_id) DO NOTHING`,
+			agent.ID, agent.ProjectID, agent.CreatedAt).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("init cursor: %w", err)
+		}
+
+		cursor := new(AgentEventCursor)
+		err = tx.NewSelect().
+			Model(cursor).
+			Where("agent_id = ?", agent.ID).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil // held by another replica
+			}
+			return fmt.Errorf("lock cursor: %w", err)
+		}
+
+		var batch []*events.OutboxEvent
+		err = tx.NewSelect().
+			Model(&batch).
+			Where("eeo.project_id = ?", agent.ProjectID).
+			Where("(eeo.tx_id, eeo.id) > (?, ?)", cursor.LastTxID, cursor.LastEventID).
+			Where(outboxSettled).
+			OrderExpr("eeo.tx_id, eeo.id").
+			Limit(limit).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("read outbox: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		var debounce time.Duration
+		if agent.ReactionConfig != nil && agent.ReactionConfig.DebounceSeconds > 0 {
+			debounce = time.Duration(agent.ReactionConfig.DebounceSeconds) * time.Second
+		}
+
+		for _, evt := range batch {
+			eventType, ok := match(evt)
+			if !ok {
+				continue
+			}
+			if debounce > 0 {
+				coalesced, err := coalesceDelivery(ctx, tx, agent.ID, evt, eventType)
+				if err != nil {
+					return fmt.Errorf("coalesce event %d: %w", evt.ID, err)
+				}
+				if coalesced {
+					continue
+				}
+			}
+			version := 0
+			if evt.Version != nil {
+				version = *evt.Version
+			}
+			eventID := evt.ID
+			entry := &AgentProcessingLog{
+				AgentID:       agent.ID,
+				GraphObjectID: evt.EntityID,
+				ObjectVersion: version,
+				EventType:     eventType,
+				Status:        ProcessingStatusPending,
+				EventID:       &eventID,
+			}
+			if debounce > 0 {
+				due := time.Now().Add(debounce)
+				entry.NextAttemptAt = &due
+			}
+			res, err := tx.NewInsert().
+				Model(entry).
+				On("CONFLICT (agent_id, event_id) WHERE event_id IS NOT NULL DO UPDATE").
+				Set("status = EXCLUDED.status").
+				Set("attempt_count = 0").
+				Set("next_attempt_at = EXCLUDED.next_attempt_at").
+				Set("started_at = NULL").
+				Set("completed_at = NULL").
+				Set("error_message = NULL").
+				Where("apl.status IN (?)", bun.In([]AgentProcessingStatus{ProcessingStatusFailed, ProcessingStatusAbandoned})).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("queue event %d: %w", evt.ID, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				queued++
+			}
+		}
+
+		last := batch[len(batch)-1]
+		_, err = tx.NewUpdate().
+			Model((*AgentEventCursor)(nil)).
+			Set("last_tx_id = ?", last.TxID).
+			Set("last_event_id = ?", last.ID).
+			Set("updated_at = ?", time.Now()).
+			Where("agent_id = ?", agent.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("advance cursor: %w", err)
+		}
+		return nil
+	})
+	return queued, err
+}
+
+// coalesceDelivery folds an event into a debounced delivery that is still
+// waiting for the same object, pointing it at the event's newer version. The
+// delivery stays due at the end of its original window. A created event
+// followed by updates is still delivered as created. Returns false when there
+// is no such delivery, or the event does not identify its object.
+func coalesceDelivery(ctx context.Context, tx bun.Tx, agentID string, evt *events.OutboxEvent, eventType ReactionEventType) (bool, error) {
+	canonicalID, _ := evt.Data["canonicalId"].(string)
+	if canonicalID == "" {
+		return false, nil
+	}
+
+	pending := new(AgentProcessingLog)
+	err := tx.NewSelect().
+		Model(pending).
+		Join("JOIN kb.graph_objects AS obj ON obj.id = apl.graph_object_id").
+		Where("apl.agent_id = ?", agentID).
+		Where("apl.status = ?", ProcessingStatusPending).
+		Where("apl.attempt_count = 0").
+		Where("apl.event_id IS NOT NULL").
+		Where("obj.canonical_id = ?", canonicalID).
+		OrderExpr("apl.created_at DESC").
+		Limit(1).
+		For("UPDATE OF apl").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if pending.EventType == EventTypeCreated && eventType == EventTypeUpdated {
+		eventType = EventTypeCreated
+	}
+	version := 0
+	if evt.Version != nil {
+		version = *evt.Version
+	}
+	_, err = tx.NewUpdate().
+		Model((*AgentProcessingLog)(nil)).
+		Set("graph_object_id = ?", evt.EntityID).
+		Set("object_version = ?", version).
+		Set("event_id = ?", evt.ID).
+		Set("event_type = ?", eventType).
+		Where("id = ?", pending.ID).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResetEventCursor moves a reaction agent's outbox cursor back to the last
+// event written before since, so the dispatcher replays everything after it.
+func (r *Repository) ResetEventCursor(ctx context.Context, agent *Agent, since time.Time) error {
+	_, err := r.db.NewRaw(`INSERT INTO kb.agent_event_cursors (agent_id, last_tx_id, last_event_id)`+cursorStartSQL+`
+		ON CONFLICT (agent_id) DO UPDATE SET
+			last_tx_id = EXCLUDED.last_tx_id,
+			last_event_id = EXCLUDED.last_event_id,
+			updated_at = now()`,
+		agent.ID, agent.ProjectID, since).Exec(ctx)
+	return err
+}
+
+// claimLockKey serialises delivery claims across replicas so per-agent
+// concurrency limits see every claim made before them.
+const claimLockKey = "agent_reaction_claims"
+
+// ClaimDueDeliveries marks up to limit pending deliveries of enabled agents
+// as processing and returns them. Deliveries waiting out a retry backoff or
+// debounce window are skipped, as are deliveries of agents already running
+// their reactionConfig.maxConcurrentRuns deliveries.
+func (r *Repository) ClaimDueDeliveries(ctx context.Context, limit int) ([]*AgentProcessingLog, error) {
+	var entries []*AgentProcessingLog
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewRaw("SELECT pg_advisory_xact_lock(hashtext(?)::bigint)", claimLockKey).Exec(ctx); err != nil {
+			return fmt.Errorf("lock claims: %w", err)
+		}
+		return tx.NewRaw(`
+			WITH due AS (
+				SELECT apl.id, apl.created_at,
+					row_number() OVER (PARTITION BY apl.agent_id ORDER BY apl.created_at) AS rank,
+					NULLIF((a.reaction_config->>'maxConcurrentRuns')::int, 0) AS max_runs,
+					(SELECT count(*) FROM kb.agent_processing_log AS busy
+					 WHERE busy.agent_id = apl.agent_id AND busy.status = ?) AS running
+				FROM kb.agent_processing_log AS apl
+				JOIN kb.agents AS a ON a.id = apl.agent_id
+				WHERE apl.status = ?
+					AND a.enabled = true
+					AND (apl.next_attempt_at IS NULL OR apl.next_attempt_at <= now())
+			)
+			UPDATE kb.agent_processing_log
+			SET status = ?, started_at = now(), completed_at = NULL, attempt_count = attempt_count + 1
+			WHERE id IN (
+				SELECT id FROM due
+				WHERE max_runs IS NULL OR running + rank <= max_runs
+				ORDER BY created_at
+				LIMIT ?
+			)
+			RETURNING *`,
+			ProcessingStatusProcessing, ProcessingStatusPending, ProcessingStatusProcessing, limit).
+			Scan(ctx, &entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FinishDelivery records the final outcome of a delivery.
+func (r *Repository) FinishDelivery(ctx context.Context, id string, status AgentProcessingStatus, runID *string, errorMsg *string, summary map[string]any) error {
+	q := r.db.NewUpdate().
+		Model((*AgentProcessingLog)(nil)).
+		Set("status = ?", status).
+		Set("completed_at = ?", time.Now()).
+		Set("next_attempt_at = NULL").
+		Set("error_message = ?", errorMsg).
+		Where("id = ?", id)
+	if runID != nil {
+		q = q.Set("run_id = ?", *runID)
+	}
+	if summary != nil {
+		q = q.Set("result_summary = ?", summary)
+	}
+	_, err := q.Exec(ctx)
+	return err
+}
+
+// RetryDelivery puts a failed delivery back in the queue, due at nextAttempt.
+func (r *Repository) RetryDelivery(ctx context.Context, id string, runID *string, errorMsg string, nextAttempt time.Time) error {
+	q := r.db.NewUpdate().
+		Model((*AgentProcessingLog)(nil)).
+		Set("status = ?", ProcessingStatusPending).
+		Set("next_attempt_at = ?", nextAttempt).
+		Set("error_message = ?", errorMsg).
+		Where("id = ?", id)
+	if runID != nil {
+		q = q.Set("run_id = ?", *runID)
+	}
+	_, err := q.Exec(ctx)
+	return err
+}
+
+// HasOtherDeliveryInProgress reports whether an agent is processing an object
+// under a delivery other than excludeID.
+func (r *Repository) HasOtherDeliveryInProgress(ctx context.Context, agentID, objectID, excludeID string) (bool, error) {
+	return r.db.NewSelect().
+		Model((*AgentProcessingLog)(nil)).
+		Where("agent_id = ?", agentID).
+		Where("graph_object_id = ?", objectID).
+		Where("status = ?", ProcessingStatusProcessing).
+		Where("id != ?", excludeID).
+		Exists(ctx)
+}
+
+// RequeueStaleDeliveries returns deliveries stuck in processing for longer
+// than olderThan (e.g. after a crash) to the queue, or abandons them once they
+// have used maxAttempts.
+func (r *Repository) RequeueStaleDeliveries(ctx context.Context, olderThan time.Duration, maxAttempts int) (int, error) {
+	res, err := r.db.NewUpdate().
+		Model((*AgentProcessingLog)(nil)).
+		Set("status = CASE WHEN attempt_count < ? THEN ? ELSE ? END", maxAttempts, ProcessingStatusPending, ProcessingStatusAbandoned).
+		Set("completed_at = CASE WHEN attempt_count < ? THEN NULL ELSE now() END", maxAttempts).
+		Set("next_attempt_at = NULL").
+		Set("error_message = ?", "delivery interrupted before completion").
+		Where("status = ?", ProcessingStatusProcessing).
+		Where("started_at < ?", time.Now().Add(-olderThan)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
 // --- Agent lookup by name ---
 
 // FindByName finds an agent by name within a project.
@@ -380,6 +722,29 @@ func (r *Repository) FindByName(ctx context.Context, projectID, name string) (*A
 	return agent, nil
 }
 
+// EnsureAgentForDefinition returns the runtime agent linked to a definition
+// by name, creating a manual one when none exists. Runs reference a runtime
+// agent, so callers running a definition directly need one.
+func (r *Repository) EnsureAgentForDefinition(ctx context.Context, def *AgentDefinition, strategyType string) (*Agent, error) {
+	agent, err := r.FindByName(ctx, def.ProjectID, def.Name)
+	if err != nil || agent != nil {
+		return agent, err
+	}
+	agent = &Agent{
+		ProjectID:    def.ProjectID,
+		Name:         def.Name,
+		StrategyType: strategyType,
+		CronSchedule: "0 0 * * *", // required by schema but ignored
+		Enabled:      true,
+		TriggerType:  TriggerTypeManual,
+		Description:  def.Description,
+	}
+	if err := r.Create(ctx, agent); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
 // --- Agent Definitions ---
 
 // FindAllDefinitions returns all agent definitions for a project.
@@ -456,6 +821,9 @@ func (r *Repository) CreateDefinition(ctx context.Context, def *AgentDefinition)
 		Model(def).
 		Returning("*").
 		Exec(ctx)
+	if err == nil {
+		r.notifyDefinitionsChanged(def.ProjectID)
+	}
 	return err
 }
 
@@ -534,16 +902,27 @@ func (r *Repository) UpdateDefinition(ctx context.Context, def *AgentDefinition)
 		WherePK().
 		Returning("*").
 		Exec(ctx)
+	if err == nil {
+		r.notifyDefinitionsChanged(def.ProjectID)
+	}
 	return err
 }
 
 // DeleteDefinition deletes an agent definition by ID.
 func (r *Repository) DeleteDefinition(ctx context.Context, id string) error {
-	_, err := r.db.NewDelete().
+	var projectIDs []string
+	err := r.db.NewDelete().
 		Model((*AgentDefinition)(nil)).
 		Where("id = ?", id).
-		Exec(ctx)
-	return err
+		Returning("project_id").
+		Scan(ctx, &projectIDs)
+	if err != nil {
+		return err
+	}
+	for _, projectID := range projectIDs {
+		r.notifyDefinitionsChanged(projectID)
+	}
+	return nil
 }
 
 // --- Extended Agent Run operations ---
@@ -623,6 +1002,21 @@ func (r *Repository) CancelRun(ctx context.Context, runID string) error {
 	return err
 }
 
+// CancelRunWithReason marks a run as cancelled, recording why and the step
+// count at the time it was stopped.
+func (r *Repository) CancelRunWithReason(ctx context.Context, runID string, reason string, stepCount int) error {
+	now := time.Now()
+	_, err := r.db.NewUpdate().
+		Model((*AgentRun)(nil)).
+		Set("status = ?", RunStatusCancelled).
+		Set("completed_at = ?", now).
+		Set("error_message = ?", reason).
+		Set("step_count = ?", stepCount).
+		Where("id = ?", runID).
+		Exec(ctx)
+	return err
+}
+
 // UpdateStepCount updates the step count for a running agent.
 func (r *Repository) UpdateStepCount(ctx context.Context, runID string, stepCount int) error {
 	_, err := r.db.NewUpdate().
@@ -792,6 +1186,27 @@ func (r *Repository) FindRunByIDForProject(ctx context.Context, runID, projectID
 	return run, nil
 }
 
+// FindRunChain returns a run followed by the runs that resumed it, oldest
+// first. A run paused by ask_user or the step limit continues in a new run
+// whose resumed_from points at it.
+func (r *Repository) FindRunChain(ctx context.Context, runID string) ([]*AgentRun, error) {
+	var runs []*AgentRun
+	err := r.db.NewRaw(`WITH RECURSIVE chain(id, depth) AS (
+			SELECT id, 0 FROM kb.agent_runs WHERE id = ?
+			UNION ALL
+			SELECT ar.id, chain.depth + 1 FROM kb.agent_runs AS ar
+			JOIN chain ON ar.resumed_from = chain.id
+		)
+		SELECT ar.* FROM kb.agent_runs AS ar
+		JOIN chain ON chain.id = ar.id
+		ORDER BY chain.depth, ar.started_at`, runID).
+		Scan(ctx, &runs)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
 // --- Agent Webhook Hooks ---
 
 // CreateWebhookHook creates a new webhook hook for an agent
@@ -976,6 +1391,450 @@ func (r *Repository) UpdateNotificationActionStatus(ctx context.Context, notific
 	return err
 }
 
+// --- Agent Change Sets ---
+
+// ChangeSetFilters holds optional filters for querying change sets.
+type ChangeSetFilters struct {
+	Status *ChangeSetStatus
+	RunID  *string
+}
+
+// CreateChangeSet inserts a new change set.
+func (r *Repository) CreateChangeSet(ctx context.Context, set *AgentChangeSet) error {
+	_, err := r.db.NewInsert().Model(set).Returning("*").Exec(ctx)
+	return err
+}
+
+// UpdatePendingChangeSetChanges saves the staged changes of a change set that
+// is still pending. Returns false when the set has already been reviewed.
+func (r *Repository) UpdatePendingChangeSetChanges(ctx context.Context, set *AgentChangeSet) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model(set).
+		Column("changes").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", set.ID).
+		Where("status = ?", ChangeSetStatusPending).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SetChangeSetTaskID links a change set to its review task.
+func (r *Repository) SetChangeSetTaskID(ctx context.Context, id, taskID string) error {
+	_, err := r.db.NewUpdate().
+		Model((*AgentChangeSet)(nil)).
+		Set("task_id = ?", taskID).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// TransitionChangeSetStatus atomically moves a change set from one status to
+// another and returns the updated row, or nil if it was not in the expected
+// status.
+func (r *Repository) TransitionChangeSetStatus(ctx context.Context, id string, from, to ChangeSetStatus) (*AgentChangeSet, error) {
+	set := new(AgentChangeSet)
+	_, err := r.db.NewUpdate().
+		Model(set).
+		Set("status = ?", to).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where("status = ?", from).
+		Returning("*").
+		Exec(ctx, set)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if set.ID == "" {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// FinishChangeSetReview saves the outcome of a review.
+func (r *Repository) FinishChangeSetReview(ctx context.Context, set *AgentChangeSet) error {
+	set.UpdatedAt = time.Now()
+	_, err := r.db.NewUpdate().
+		Model(set).
+		Column("status", "changes", "reviewed_by", "reviewed_at", "review_notes", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// FindChangeSetByID returns a change set scoped to a project.
+func (r *Repository) FindChangeSetByID(ctx context.Context, projectID, id string) (*AgentChangeSet, error) {
+	set := new(AgentChangeSet)
+	err := r.db.NewSelect().
+		Model(set).
+		Where("id = ?", id).
+		Where("project_id = ?", projectID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return set, nil
+}
+
+// FindChangeSetsByProject returns paginated change sets for a project, newest first.
+func (r *Repository) FindChangeSetsByProject(ctx context.Context, projectID string, filters ChangeSetFilters, limit, offset int) ([]*AgentChangeSet, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var sets []*AgentChangeSet
+	q := r.db.NewSelect().
+		Model(&sets).
+		Where("project_id = ?", projectID)
+	if filters.Status != nil {
+		q = q.Where("status = ?", *filters.Status)
+	}
+	if filters.RunID != nil {
+		q = q.Where("run_id = ?", *filters.RunID)
+	}
+
+	total, err := q.Order("created_at DESC").Limit(limit).Offset(offset).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sets, total, nil
+}
+
+// --- Agent Memories ---
+
+// MemoryFilters holds optional filters for querying agent memories.
+type MemoryFilters struct {
+	AgentDefinitionID *string
+	UserID            *string
+	Kind              *AgentMemoryKind
+}
+
+func (f MemoryFilters) apply(q *bun.SelectQuery) *bun.SelectQuery {
+	if f.AgentDefinitionID != nil {
+		q = q.Where("agent_definition_id = ?", *f.AgentDefinitionID)
+	}
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.Kind != nil {
+		q = q.Where("kind = ?", *f.Kind)
+	}
+	return q
+}
+
+// whereMemoryScope limits a query to the memories visible in a scope: the
+// definition's shared memories plus, when a user is set, that user's own.
+func whereMemoryScope(q *bun.SelectQuery, scope MemoryScope) *bun.SelectQuery {
+	q = q.Where("am.agent_definition_id = ?", scope.AgentDefinitionID)
+	if scope.UserID != nil {
+		return q.Where("(am.user_id IS NULL OR am.user_id = ?)", *scope.UserID)
+	}
+	return q.Where("am.user_id IS NULL")
+}
+
+// CreateMemory inserts a memory with an optional embedding.
+func (r *Repository) CreateMemory(ctx context.Context, mem *AgentMemory, embedding []float32) error {
+	q := r.db.NewInsert().Model(mem)
+	if len(embedding) > 0 {
+		q = q.Value("embedding", "?::vector", pgutils.FormatVector(embedding))
+	}
+	_, err := q.Returning("id, created_at, updated_at").Exec(ctx)
+	return err
+}
+
+// HasMemories reports whether any memory is visible in a scope.
+func (r *Repository) HasMemories(ctx context.Context, scope MemoryScope) (bool, error) {
+	q := r.db.NewSelect().Model((*AgentMemory)(nil))
+	return whereMemoryScope(q, scope).Exists(ctx)
+}
+
+// FindSimilarMemories returns the memories in a scope closest to an
+// embedding by cosine distance, up to maxDistance.
+func (r *Repository) FindSimilarMemories(ctx context.Context, scope MemoryScope, embedding []float32, maxDistance float64, limit int) ([]*AgentMemory, error) {
+	vec := pgutils.FormatVector(embedding)
+	var mems []*AgentMemory
+	q := r.db.NewSelect().
+		Model(&mems).
+		ColumnExpr("?TableColumns").
+		ColumnExpr("(am.embedding <=> ?::vector) AS distance", vec).
+		Where("am.embedding IS NOT NULL").
+		Where("(am.embedding <=> ?::vector) <= ?", vec, maxDistance)
+	err := whereMemoryScope(q, scope).
+		OrderExpr("distance ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mems, nil
+}
+
+// FindRecentMemories returns the newest memories in a scope.
+func (r *Repository) FindRecentMemories(ctx context.Context, scope MemoryScope, limit int) ([]*AgentMemory, error) {
+	var mems []*AgentMemory
+	q := r.db.NewSelect().Model(&mems)
+	err := whereMemoryScope(q, scope).
+		Order("am.created_at DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mems, nil
+}
+
+// MarkMemoriesRecalled records that memories were injected into a run.
+func (r *Repository) MarkMemoriesRecalled(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.NewUpdate().
+		Model((*AgentMemory)(nil)).
+		Set("recall_count = recall_count + 1").
+		Set("last_recalled_at = ?", time.Now()).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+	return err
+}
+
+// DeleteMemoryInScope deletes a memory visible in a scope. Returns false when
+// no such memory exists.
+func (r *Repository) DeleteMemoryInScope(ctx context.Context, scope MemoryScope, id string) (bool, error) {
+	q := r.db.NewDelete().
+		Model((*AgentMemory)(nil)).
+		Where("id = ?", id).
+		Where("agent_definition_id = ?", scope.AgentDefinitionID)
+	if scope.UserID != nil {
+		q = q.Where("(user_id IS NULL OR user_id = ?)", *scope.UserID)
+	} else {
+		q = q.Where("user_id IS NULL")
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// FindMemoriesByProject returns paginated memories for a project, newest first.
+func (r *Repository) FindMemoriesByProject(ctx context.Context, projectID string, filters MemoryFilters, limit, offset int) ([]*AgentMemory, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var mems []*AgentMemory
+	q := r.db.NewSelect().
+		Model(&mems).
+		Where("project_id = ?", projectID)
+	total, err := filters.apply(q).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mems, total, nil
+}
+
+// DeleteMemory deletes one memory of a project. Returns false when it does
+// not exist.
+func (r *Repository) DeleteMemory(ctx context.Context, projectID, id string) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*AgentMemory)(nil)).
+		Where("id = ?", id).
+		Where("project_id = ?", projectID).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// PurgeMemories deletes all memories of a project matching the filters and
+// returns how many were removed.
+func (r *Repository) PurgeMemories(ctx context.Context, projectID string, filters MemoryFilters) (int, error) {
+	q := r.db.NewDelete().
+		Model((*AgentMemory)(nil)).
+		Where("project_id = ?", projectID)
+	if filters.AgentDefinitionID != nil {
+		q = q.Where("agent_definition_id = ?", *filters.AgentDefinitionID)
+	}
+	if filters.UserID != nil {
+		q = q.Where("user_id = ?", *filters.UserID)
+	}
+	if filters.Kind != nil {
+		q = q.Where("kind = ?", *filters.Kind)
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// --- Agent Eval Suites ---
+
+// CreateEvalSuite inserts an evaluation suite.
+func (r *Repository) CreateEvalSuite(ctx context.Context, suite *AgentEvalSuite) error {
+	_, err := r.db.NewInsert().Model(suite).Returning("*").Exec(ctx)
+	return err
+}
+
+// UpdateEvalSuite saves an evaluation suite.
+func (r *Repository) UpdateEvalSuite(ctx context.Context, suite *AgentEvalSuite) error {
+	suite.UpdatedAt = time.Now()
+	_, err := r.db.NewUpdate().
+		Model(suite).
+		Column("name", "description", "scenarios", "judge_model", "pass_threshold", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// FindEvalSuiteByID returns an evaluation suite of a project, or nil.
+func (r *Repository) FindEvalSuiteByID(ctx context.Context, projectID, id string) (*AgentEvalSuite, error) {
+	suite := new(AgentEvalSuite)
+	err := r.db.NewSelect().
+		Model(suite).
+		Where("id = ?", id).
+		Where("project_id = ?", projectID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return suite, nil
+}
+
+// FindEvalSuitesByProject returns paginated evaluation suites of a project,
+// optionally for one agent definition.
+func (r *Repository) FindEvalSuitesByProject(ctx context.Context, projectID string, agentDefinitionID *string, limit, offset int) ([]*AgentEvalSuite, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var suites []*AgentEvalSuite
+	q := r.db.NewSelect().
+		Model(&suites).
+		Where("project_id = ?", projectID)
+	if agentDefinitionID != nil {
+		q = q.Where("agent_definition_id = ?", *agentDefinitionID)
+	}
+	total, err := q.Order("name ASC").Limit(limit).Offset(offset).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return suites, total, nil
+}
+
+// DeleteEvalSuite deletes an evaluation suite and its runs. Returns false
+// when it does not exist.
+func (r *Repository) DeleteEvalSuite(ctx context.Context, projectID, id string) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*AgentEvalSuite)(nil)).
+		Where("id = ?", id).
+		Where("project_id = ?", projectID).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// CreateEvalRun inserts an evaluation run.
+func (r *Repository) CreateEvalRun(ctx context.Context, run *AgentEvalRun) error {
+	_, err := r.db.NewInsert().Model(run).Returning("id, started_at").Exec(ctx)
+	return err
+}
+
+// UpdateEvalRun saves the progress and outcome of an evaluation run.
+func (r *Repository) UpdateEvalRun(ctx context.Context, run *AgentEvalRun) error {
+	_, err := r.db.NewUpdate().
+		Model(run).
+		Column("status", "score", "passed", "total", "results", "error_message", "completed_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// FindEvalRunByID returns an evaluation run of a suite, or nil.
+func (r *Repository) FindEvalRunByID(ctx context.Context, suiteID, id string) (*AgentEvalRun, error) {
+	run := new(AgentEvalRun)
+	err := r.db.NewSelect().
+		Model(run).
+		Where("id = ?", id).
+		Where("suite_id = ?", suiteID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// FindEvalRunsBySuite returns paginated runs of a suite, newest first. When
+// status is set only runs in that state are returned.
+func (r *Repository) FindEvalRunsBySuite(ctx context.Context, suiteID string, status *EvalRunStatus, limit, offset int) ([]*AgentEvalRun, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var runs []*AgentEvalRun
+	q := r.db.NewSelect().
+		Model(&runs).
+		Where("suite_id = ?", suiteID)
+	if status != nil {
+		q = q.Where("status = ?", *status)
+	}
+	total, err := q.Order("started_at DESC").Limit(limit).Offset(offset).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// HasRunningEvalRun reports whether a suite has a run in progress. Runs
+// started longer than staleAfter ago are ignored, so a run orphaned by a
+// restart does not block the suite.
+func (r *Repository) HasRunningEvalRun(ctx context.Context, suiteID string, staleAfter time.Duration) (bool, error) {
+	return r.db.NewSelect().
+		Model((*AgentEvalRun)(nil)).
+		Where("suite_id = ?", suiteID).
+		Where("status = ?", EvalRunStatusRunning).
+		Where("started_at > ?", time.Now().Add(-staleAfter)).
+		Exists(ctx)
+}
+
 // --- ADK Sessions ---
 
 // FindADKSessionsByProject returns ADK sessions associated with a specific project
@@ -32,6 +32,7 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
 	agentsWrite.DELETE("/:id", h.DeleteAgent)
 	agentsWrite.POST("/:id/trigger", h.TriggerAgent)
 	agentsWrite.POST("/:id/batch-trigger", h.BatchTrigger)
+	agentsWrite.POST("/:id/replay-events", h.ReplayEvents)
 	agentsWrite.POST("/:id/runs/:runId/cancel", h.CancelRun)
 	agentsWrite.POST("/:id/hooks", h.CreateWebhookHook)
 	agentsWrite.DELETE("/:id/hooks/:hookId", h.DeleteWebhookHook)
@@ -66,6 +67,12 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
 	runs.GET("/:runId/tool-calls", h.GetRunToolCalls)
 	runs.GET("/:runId/questions", h.HandleListQuestionsByRun)
 
+	runsWrite := e.Group("/api/projects/:projectId/agent-runs")
+	runsWrite.Use(authMiddleware.RequireAuth())
+	runsWrite.Use(authMiddleware.RequireProjectScope())
+	runsWrite.Use(authMiddleware.RequireAPITokenScopes("agents:write"))
+	runsWrite.POST("/:runId/replay", h.ReplayRun)
+
 	// --- Project-scoped agent question routes ---
 	questions := e.Group("/api/projects/:projectId/agent-questions")
 	questions.Use(authMiddleware.RequireAuth())
@@ -73,6 +80,72 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
 	questions.GET("", h.HandleListQuestionsByProject)
 	questions.POST("/:questionId/respond", h.HandleRespondToQuestion)
 
+	// --- Project-scoped change set routes (suggest/hybrid mode review) ---
+	changeSets := e.Group("/api/projects/:projectId/agent-change-sets")
+	changeSets.Use(authMiddleware.RequireAuth())
+	changeSets.Use(authMiddleware.RequireProjectScope())
+
+	changeSetsRead := changeSets.Group("")
+	changeSetsRead.Use(authMiddleware.RequireAPITokenScopes("agents:read"))
+	changeSetsRead.GET("", h.ListChangeSets)
+	changeSetsRead.GET("/:changeSetId", h.GetChangeSet)
+
+	changeSetsWrite := changeSets.Group("")
+	changeSetsWrite.Use(authMiddleware.RequireAPITokenScopes("agents:write"))
+	changeSetsWrite.POST("/:changeSetId/approve", h.ApproveChangeSet)
+	changeSetsWrite.POST("/:changeSetId/reject", h.RejectChangeSet)
+
+	// --- Project-scoped agent memory routes (inspect and purge long-term memories) ---
+	memories := e.Group("/api/projects/:projectId/agent-memories")
+	memories.Use(authMiddleware.RequireAuth())
+	memories.Use(authMiddleware.RequireProjectScope())
+
+	memoriesRead := memories.Group("")
+	memoriesRead.Use(authMiddleware.RequireAPITokenScopes("agents:read"))
+	memoriesRead.GET("", h.ListMemories)
+
+	memoriesWrite := memories.Group("")
+	memoriesWrite.Use(authMiddleware.RequireAPITokenScopes("agents:write"))
+	memoriesWrite.DELETE("/:memoryId", h.DeleteMemory)
+	memoriesWrite.POST("/purge", h.PurgeMemories)
+
+	// --- Project-scoped agent evaluation routes (scenario suites and their runs) ---
+	evals := e.Group("/api/projects/:projectId/agent-eval-suites")
+	evals.Use(authMiddleware.RequireAuth())
+	evals.Use(authMiddleware.RequireProjectScope())
+
+	evalsRead := evals.Group("")
+	evalsRead.Use(authMiddleware.RequireAPITokenScopes("agents:read"))
+	evalsRead.GET("", h.ListEvalSuites)
+	evalsRead.GET("/:suiteId", h.GetEvalSuite)
+	evalsRead.GET("/:suiteId/runs", h.ListEvalRuns)
+	evalsRead.GET("/:suiteId/runs/:runId", h.GetEvalRun)
+	evalsRead.GET("/:suiteId/compare", h.CompareEvalRuns)
+
+	evalsWrite := evals.Group("")
+	evalsWrite.Use(authMiddleware.RequireAPITokenScopes("agents:write"))
+	evalsWrite.POST("", h.CreateEvalSuite)
+	evalsWrite.PATCH("/:suiteId", h.UpdateEvalSuite)
+	evalsWrite.DELETE("/:suiteId", h.DeleteEvalSuite)
+	evalsWrite.POST("/:suiteId/runs", h.StartEvalRun)
+
+	// --- Project-scoped A2A routes (external agent discovery and invocation) ---
+	a2a := e.Group("/api/projects/:projectId/a2a")
+	a2a.Use(authMiddleware.RequireAuth())
+	a2a.Use(authMiddleware.RequireProjectScope())
+
+	a2aRead := a2a.Group("")
+	a2aRead.Use(authMiddleware.RequireAPITokenScopes("agents:read"))
+	a2aRead.GET("/.well-known/agent.json", h.GetAgentCards)
+	a2aRead.GET("/agents/:name", h.GetAgentCard)
+	a2aRead.GET("/tasks/:taskId", h.GetA2ATask)
+	a2aRead.GET("/tasks/:taskId/events", h.StreamA2ATask)
+
+	a2aWrite := a2a.Group("")
+	a2aWrite.Use(authMiddleware.RequireAPITokenScopes("agents:write"))
+	a2aWrite.POST("/agents/:name/tasks", h.CreateA2ATask)
+	a2aWrite.POST("/tasks/:taskId/input", h.SendA2ATaskInput)
+
 	// --- Agent session status routes ---
 	sessions := e.Group("/api/v1/agent/sessions")
 	sessions.Use(authMiddleware.RequireAuth())
@@ -0,0 +1,215 @@
+package agents
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+)
+
+// defaultSamplingMaxTokens caps a sampled turn when the run's generate config
+// sets no limit. sampling/createMessage requires one.
+const defaultSamplingMaxTokens = 8192
+
+// samplingModel runs an agent on the model of the MCP client that triggered
+// it, through sampling/createMessage. It is used when the project has no LLM
+// provider configured. Tool calls are only possible when the client accepts
+// tools in sampling requests.
+type samplingModel struct {
+	client    *mcp.ClientSession
+	modelName string
+}
+
+func newSamplingModel(client *mcp.ClientSession, modelName string) *samplingModel {
+	return &samplingModel{client: client, modelName: modelName}
+}
+
+func (m *samplingModel) Name() string {
+	return "mcp-sampling"
+}
+
+func (m *samplingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		result, err := m.client.CreateMessage(ctx, m.samplingParams(req))
+		if err != nil {
+			yield(nil, fmt.Errorf("sample client model: %w", err))
+			return
+		}
+
+		content := &genai.Content{Role: genai.RoleModel}
+		for _, block := range result.Content {
+			switch block.Type {
+			case "text":
+				content.Parts = append(content.Parts, genai.NewPartFromText(block.Text))
+			case "tool_use":
+				args, _ := block.Input.(map[string]any)
+				content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+					ID:   block.ID,
+					Name: block.Name,
+					Args: args,
+				}})
+			}
+		}
+
+		finish := genai.FinishReasonStop
+		if result.StopReason == "maxTokens" {
+			finish = genai.FinishReasonMaxTokens
+		}
+		yield(&model.LLMResponse{
+			Content:        content,
+			FinishReason:   finish,
+			TurnComplete:   true,
+			CustomMetadata: map[string]any{"sampling_model": result.Model},
+		}, nil)
+	}
+}
+
+// samplingParams converts an ADK request to a sampling request.
+func (m *samplingModel) samplingParams(req *model.LLMRequest) *mcp.CreateMessageParams {
+	params := &mcp.CreateMessageParams{MaxTokens: defaultSamplingMaxTokens}
+	if m.modelName != "" {
+		params.ModelPreferences = &mcp.ModelPreferences{Hints: []mcp.ModelHint{{Name: m.modelName}}}
+	}
+
+	if cfg := req.Config; cfg != nil {
+		if cfg.SystemInstruction != nil {
+			params.SystemPrompt = contentText(cfg.SystemInstruction)
+		}
+		if cfg.MaxOutputTokens > 0 {
+			params.MaxTokens = int(cfg.MaxOutputTokens)
+		}
+		if cfg.Temperature != nil {
+			t := float64(*cfg.Temperature)
+			params.Temperature = &t
+		}
+		params.StopSequences = cfg.StopSequences
+		if m.client.SupportsSamplingTools() {
+			for _, t := range cfg.Tools {
+				for _, decl := range t.FunctionDeclarations {
+					params.Tools = append(params.Tools, mcp.SamplingTool{
+						Name:        decl.Name,
+						Description: decl.Description,
+						InputSchema: declarationSchema(decl),
+					})
+				}
+			}
+		}
+	}
+
+	for _, c := range req.Contents {
+		if c == nil {
+			continue
+		}
+		role := "user"
+		if c.Role == genai.RoleModel {
+			role = "assistant"
+		}
+		var blocks mcp.SamplingContents
+		for _, part := range c.Parts {
+			if block, ok := samplingBlock(part); ok {
+				blocks = append(blocks, block)
+			}
+		}
+		if len(blocks) > 0 {
+			params.Messages = append(params.Messages, mcp.SamplingMessage{Role: role, Content: blocks})
+		}
+	}
+	return params
+}
+
+// samplingBlock converts a content part to a sampling content block.
+func samplingBlock(part *genai.Part) (mcp.SamplingContent, bool) {
+	switch {
+	case part == nil || part.Thought:
+		return mcp.SamplingContent{}, false
+	case part.Text != "":
+		return mcp.SamplingContent{Type: "text", Text: part.Text}, true
+	case part.FunctionCall != nil:
+		return mcp.SamplingContent{
+			Type:  "tool_use",
+			ID:    callID(part.FunctionCall.ID, part.FunctionCall.Name),
+			Name:  part.FunctionCall.Name,
+			Input: part.FunctionCall.Args,
+		}, true
+	case part.FunctionResponse != nil:
+		data, _ := json.Marshal(part.FunctionResponse.Response)
+		_, failed := part.FunctionResponse.Response["error"]
+		return mcp.SamplingContent{
+			Type:      "tool_result",
+			ToolUseID: callID(part.FunctionResponse.ID, part.FunctionResponse.Name),
+			Content:   []mcp.SamplingContent{{Type: "text", Text: string(data)}},
+			IsError:   failed,
+		}, true
+	case part.InlineData != nil && strings.HasPrefix(part.InlineData.MIMEType, "image/"):
+		return mcp.SamplingContent{
+			Type:     "image",
+			Data:     base64.StdEncoding.EncodeToString(part.InlineData.Data),
+			MimeType: part.InlineData.MIMEType,
+		}, true
+	}
+	return mcp.SamplingContent{}, false
+}
+
+// callID pairs tool uses with their results when the model gave no call ID.
+func callID(id, name string) string {
+	if id != "" {
+		return id
+	}
+	return name
+}
+
+// contentText joins the text parts of a content.
+func contentText(c *genai.Content) string {
+	texts := make([]string, 0, len(c.Parts))
+	for _, part := range c.Parts {
+		if part != nil && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// declarationSchema returns the JSON Schema of a function's parameters.
+// Gemini schemas spell types in upper case, which JSON Schema does not accept.
+func declarationSchema(decl *genai.FunctionDeclaration) any {
+	if decl.ParametersJsonSchema != nil {
+		return decl.ParametersJsonSchema
+	}
+	if decl.Parameters == nil {
+		return map[string]any{"type": "object"}
+	}
+	data, err := json.Marshal(decl.Parameters)
+	if err != nil {
+		return map[string]any{"type": "object"}
+	}
+	var schema any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return map[string]any{"type": "object"}
+	}
+	return lowerSchemaTypes(schema)
+}
+
+func lowerSchemaTypes(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if s, ok := child.(string); ok && k == "type" {
+				t[k] = strings.ToLower(s)
+				continue
+			}
+			t[k] = lowerSchemaTypes(child)
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = lowerSchemaTypes(child)
+		}
+	}
+	return v
+}
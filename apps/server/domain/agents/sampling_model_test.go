@@ -0,0 +1,63 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestSamplingModel_Params(t *testing.T) {
+	m := newSamplingModel(nil, "gemini-2.5-flash")
+	req := &model.LLMRequest{
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("You are a helpful agent.", genai.RoleUser),
+			MaxOutputTokens:   512,
+		},
+		Contents: []*genai.Content{
+			genai.NewContentFromText("Find Alice", genai.RoleUser),
+			{Role: genai.RoleModel, Parts: []*genai.Part{
+				{Text: "thinking", Thought: true},
+				{FunctionCall: &genai.FunctionCall{ID: "c1", Name: "search_entities", Args: map[string]any{"query": "Alice"}}},
+			}},
+			{Role: genai.RoleUser, Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "c1", Name: "search_entities", Response: map[string]any{"error": "boom"}}},
+			}},
+		},
+	}
+
+	params := m.samplingParams(req)
+	assert.Equal(t, "You are a helpful agent.", params.SystemPrompt)
+	assert.Equal(t, 512, params.MaxTokens)
+	require.NotNil(t, params.ModelPreferences)
+	assert.Equal(t, "gemini-2.5-flash", params.ModelPreferences.Hints[0].Name)
+	assert.Empty(t, params.Tools, "a nil client accepts no tools")
+
+	require.Len(t, params.Messages, 3)
+	assert.Equal(t, "user", params.Messages[0].Role)
+	assert.Equal(t, "assistant", params.Messages[1].Role)
+	require.Len(t, params.Messages[1].Content, 1, "thoughts are not sent")
+	assert.Equal(t, "tool_use", params.Messages[1].Content[0].Type)
+	assert.Equal(t, "c1", params.Messages[1].Content[0].ID)
+
+	result := params.Messages[2].Content[0]
+	assert.Equal(t, "tool_result", result.Type)
+	assert.Equal(t, "c1", result.ToolUseID)
+	assert.True(t, result.IsError)
+}
+
+func TestDeclarationSchema_LowersGeminiTypes(t *testing.T) {
+	schema := declarationSchema(&genai.FunctionDeclaration{
+		Name: "search",
+		Parameters: &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: map[string]*genai.Schema{"query": {Type: genai.TypeString}},
+		},
+	})
+	assert.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"query": map[string]any{"type": "string"}},
+	}, schema)
+}
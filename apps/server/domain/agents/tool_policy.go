@@ -0,0 +1,224 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/tool"
+)
+
+// Approval question option values.
+const (
+	approvalApprove = "approve"
+	approvalDeny    = "deny"
+)
+
+// Validate checks that every policy names a tool, uses a known action, and
+// has valid glob and argument patterns.
+func (p ToolPolicies) Validate() error {
+	for i, policy := range p {
+		if policy.Tool == "" {
+			return fmt.Errorf("toolPolicies[%d]: tool is required", i)
+		}
+		if _, err := path.Match(policy.Tool, ""); err != nil {
+			return fmt.Errorf("toolPolicies[%d]: invalid tool pattern %q", i, policy.Tool)
+		}
+		switch policy.Action {
+		case ToolPolicyAllow, ToolPolicyRequireApproval, ToolPolicyDeny:
+		default:
+			return fmt.Errorf("toolPolicies[%d]: invalid action %q: must be allow, require_approval or deny", i, policy.Action)
+		}
+		for name, pattern := range policy.Args {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("toolPolicies[%d]: invalid pattern for argument %q: %w", i, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Decide returns the action of the first policy matching a tool call, or
+// allow when none does.
+func (p ToolPolicies) Decide(toolName string, args map[string]any) ToolPolicyAction {
+	for _, policy := range p {
+		if policy.matches(toolName, args) {
+			return policy.Action
+		}
+	}
+	return ToolPolicyAllow
+}
+
+// matches reports whether a tool call matches the policy's tool pattern and
+// every argument pattern. Missing arguments never match.
+func (p ToolPolicy) matches(toolName string, args map[string]any) bool {
+	if p.Tool != toolName && p.Tool != "*" {
+		if ok, _ := path.Match(p.Tool, toolName); !ok {
+			return false
+		}
+	}
+	for name, pattern := range p.Args {
+		value, ok := args[name]
+		if !ok {
+			return false
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil || !re.MatchString(argString(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// argString renders a tool argument for pattern matching: strings as is,
+// anything else as JSON.
+func argString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// toolApproval is the user's answer to an approval question, carried into
+// the resumed run.
+type toolApproval struct {
+	ToolName string
+	Args     map[string]any
+	Approved bool
+	Response string
+}
+
+// newToolApproval reads the answer to an approval question. Anything but an
+// explicit approve is a denial.
+func newToolApproval(q *AgentQuestion, response string) *toolApproval {
+	if q.ToolName == nil {
+		return nil
+	}
+	return &toolApproval{
+		ToolName: *q.ToolName,
+		Args:     q.ToolArgs,
+		Approved: strings.EqualFold(strings.TrimSpace(response), approvalApprove),
+		Response: response,
+	}
+}
+
+// resumeMessage tells the resumed agent how the approval was decided.
+func (a *toolApproval) resumeMessage() string {
+	if a.Approved {
+		return fmt.Sprintf(
+			"The user approved your call to %s. Call it again with exactly the same arguments to run it, then continue from where you left off.",
+			a.ToolName)
+	}
+	msg := fmt.Sprintf("The user denied your call to %s. Do not retry it; continue without it.", a.ToolName)
+	if r := strings.TrimSpace(a.Response); r != "" && !strings.EqualFold(r, approvalDeny) {
+		msg += fmt.Sprintf("\nThe user said: %q", r)
+	}
+	return msg
+}
+
+// toolGate applies a definition's tool policies to the calls of one run. It
+// holds the approval the run was resumed with: an approved call runs once
+// without asking again, a denied one keeps being refused.
+type toolGate struct {
+	policies ToolPolicies
+	mu       sync.Mutex
+	approval *toolApproval
+}
+
+func newToolGate(def *AgentDefinition, approval *toolApproval) *toolGate {
+	g := &toolGate{approval: approval}
+	if def != nil {
+		g.policies = def.ToolPolicies
+	}
+	return g
+}
+
+// decide returns the action for a tool call, taking the resumed approval
+// into account.
+func (g *toolGate) decide(toolName string, args map[string]any) ToolPolicyAction {
+	if g == nil {
+		return ToolPolicyAllow
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if a := g.approval; a != nil && a.ToolName == toolName && canonicalArgs(a.Args) == canonicalArgs(args) {
+		if !a.Approved {
+			return ToolPolicyDeny
+		}
+		g.approval = nil
+		return ToolPolicyAllow
+	}
+	return g.policies.Decide(toolName, args)
+}
+
+// approvalQuestion builds the question asking the user to approve a call.
+func approvalQuestion(toolName string, args map[string]any) (string, []AgentQuestionOption) {
+	argsJSON, err := json.MarshalIndent(args, "", "  ")
+	if err != nil {
+		argsJSON = []byte(fmt.Sprint(args))
+	}
+	question := fmt.Sprintf("The agent wants to call %s with these arguments:\n%s\nApprove this call?", toolName, argsJSON)
+	options := []AgentQuestionOption{
+		{Label: "Approve", Value: approvalApprove, Description: "Run the call with exactly these arguments"},
+		{Label: "Deny", Value: approvalDeny, Description: "Skip the call; the agent continues without it"},
+	}
+	return question, options
+}
+
+// requestToolApproval creates an approval question for a tool call and asks
+// the run to pause. Only one approval is pending at a time; further calls in
+// the same turn are deferred.
+func requestToolApproval(ctx tool.Context, deps AskUserToolDeps, toolName string, args map[string]any) map[string]any {
+	if deps.PauseState.ShouldPause() {
+		return map[string]any{
+			"status":  "deferred",
+			"message": fmt.Sprintf("%s needs user approval, and another request is already waiting. Call it again after the run resumes.", toolName),
+		}
+	}
+
+	question, options := approvalQuestion(toolName, args)
+	name := toolName
+	q := &AgentQuestion{
+		RunID:     deps.RunID,
+		AgentID:   deps.AgentID,
+		ProjectID: deps.ProjectID,
+		Question:  question,
+		Options:   options,
+		Status:    QuestionStatusPending,
+		ToolName:  &name,
+		ToolArgs:  args,
+	}
+	if err := deps.Repo.CreateQuestion(ctx, q); err != nil {
+		return map[string]any{"error": fmt.Sprintf("%s needs user approval, but the request could not be created: %s", toolName, err.Error())}
+	}
+
+	if deps.UserID != "" {
+		if notificationID := createQuestionNotification(ctx, deps, q); notificationID != "" {
+			_ = deps.Repo.UpdateQuestionNotificationID(ctx, q.ID, notificationID)
+		}
+	}
+
+	deps.PauseState.RequestPause(q.ID)
+
+	return map[string]any{
+		"question_id": q.ID,
+		"status":      "awaiting_approval",
+		"message":     fmt.Sprintf("%s needs user approval. Execution will pause now and resume when the user decides.", toolName),
+	}
+}
+
+// deniedToolCall is the result of a call refused by policy or by the user.
+func deniedToolCall(toolName string) map[string]any {
+	return map[string]any{
+		"error":  fmt.Sprintf("call to %s was denied by this agent's tool policy", toolName),
+		"status": "denied",
+	}
+}
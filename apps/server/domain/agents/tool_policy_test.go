@@ -0,0 +1,122 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolPolicies_Validate(t *testing.T) {
+	valid := ToolPolicies{
+		{Tool: "workspace_bash", Args: map[string]string{"command": `\bgit\s+push\b`}, Action: ToolPolicyRequireApproval},
+		{Tool: "github_*", Action: ToolPolicyDeny},
+		{Tool: "*", Action: ToolPolicyAllow},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, ToolPolicies(nil).Validate())
+
+	tests := []struct {
+		name   string
+		policy ToolPolicy
+		errMsg string
+	}{
+		{"missing tool", ToolPolicy{Action: ToolPolicyDeny}, "tool is required"},
+		{"bad glob", ToolPolicy{Tool: "[", Action: ToolPolicyDeny}, "invalid tool pattern"},
+		{"bad action", ToolPolicy{Tool: "x", Action: "ask"}, "invalid action"},
+		{"bad arg pattern", ToolPolicy{Tool: "x", Args: map[string]string{"command": "("}, Action: ToolPolicyDeny}, `argument "command"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ToolPolicies{tt.policy}.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestToolPolicies_Decide(t *testing.T) {
+	policies := ToolPolicies{
+		{Tool: "workspace_bash", Args: map[string]string{"command": `\bgit\s+push\b`}, Action: ToolPolicyRequireApproval},
+		{Tool: "github_delete_*", Action: ToolPolicyDeny},
+		{Tool: "github_*", Action: ToolPolicyRequireApproval},
+		{Tool: "delete_entity", Args: map[string]string{"force": `^true$`}, Action: ToolPolicyDeny},
+	}
+
+	tests := []struct {
+		tool string
+		args map[string]any
+		want ToolPolicyAction
+	}{
+		{"workspace_bash", map[string]any{"command": "cd repo && git push origin main"}, ToolPolicyRequireApproval},
+		{"workspace_bash", map[string]any{"command": "git status"}, ToolPolicyAllow},
+		{"workspace_bash", map[string]any{}, ToolPolicyAllow},
+		{"github_delete_repo", nil, ToolPolicyDeny},
+		{"github_create_issue", nil, ToolPolicyRequireApproval},
+		{"delete_entity", map[string]any{"force": true}, ToolPolicyDeny},
+		{"delete_entity", map[string]any{"force": false}, ToolPolicyAllow},
+		{"search_entities", nil, ToolPolicyAllow},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policies.Decide(tt.tool, tt.args), "%s %v", tt.tool, tt.args)
+	}
+	assert.Equal(t, ToolPolicyAllow, ToolPolicies(nil).Decide("anything", nil))
+}
+
+func TestToolGate_ResumedApproval(t *testing.T) {
+	def := &AgentDefinition{ToolPolicies: ToolPolicies{{Tool: "github_*", Action: ToolPolicyRequireApproval}}}
+	// Recorded arguments come back from JSONB with float64 numbers
+	held := map[string]any{"repo": "acme/app", "number": float64(7)}
+	question := &AgentQuestion{ToolName: strPtr("github_merge_pr"), ToolArgs: held}
+
+	gate := newToolGate(def, newToolApproval(question, "Approve"))
+	assert.Equal(t, ToolPolicyRequireApproval, gate.decide("github_merge_pr", map[string]any{"repo": "acme/app", "number": 8}),
+		"different arguments need their own approval")
+	assert.Equal(t, ToolPolicyAllow, gate.decide("github_merge_pr", map[string]any{"number": 7, "repo": "acme/app"}))
+	assert.Equal(t, ToolPolicyRequireApproval, gate.decide("github_merge_pr", map[string]any{"number": 7, "repo": "acme/app"}),
+		"an approval runs the call once")
+
+	gate = newToolGate(def, newToolApproval(question, "no, wrong repo"))
+	assert.Equal(t, ToolPolicyDeny, gate.decide("github_merge_pr", held))
+	assert.Equal(t, ToolPolicyDeny, gate.decide("github_merge_pr", held), "a denied call stays denied")
+
+	assert.Equal(t, ToolPolicyAllow, (*toolGate)(nil).decide("github_merge_pr", held))
+	assert.Equal(t, ToolPolicyRequireApproval, newToolGate(def, nil).decide("github_merge_pr", held))
+}
+
+func TestToolApproval_ResumeMessage(t *testing.T) {
+	assert.Nil(t, newToolApproval(&AgentQuestion{Question: "Which repo?"}, "approve"), "plain questions are not approvals")
+
+	q := &AgentQuestion{ToolName: strPtr("workspace_bash"), ToolArgs: map[string]any{"command": "git push"}}
+
+	approved := newToolApproval(q, " approve ")
+	require.NotNil(t, approved)
+	assert.True(t, approved.Approved)
+	assert.Contains(t, approved.resumeMessage(), "approved your call to workspace_bash")
+
+	denied := newToolApproval(q, approvalDeny)
+	assert.False(t, denied.Approved)
+	assert.Contains(t, denied.resumeMessage(), "denied your call to workspace_bash")
+	assert.NotContains(t, denied.resumeMessage(), "The user said")
+
+	explained := newToolApproval(q, "push to a branch instead")
+	assert.Contains(t, explained.resumeMessage(), `The user said: "push to a branch instead"`)
+}
+
+func TestApprovalQuestion(t *testing.T) {
+	question, options := approvalQuestion("workspace_bash", map[string]any{"command": "git push origin main"})
+	assert.Contains(t, question, "workspace_bash")
+	assert.Contains(t, question, `"command": "git push origin main"`)
+	require.Len(t, options, 2)
+	assert.Equal(t, approvalApprove, options[0].Value)
+	assert.Equal(t, approvalDeny, options[1].Value)
+}
+
+func TestRequestToolApproval_DefersWhilePaused(t *testing.T) {
+	pause := &AskPauseState{}
+	pause.RequestPause("q1")
+
+	out := requestToolApproval(nil, AskUserToolDeps{PauseState: pause}, "workspace_bash", map[string]any{"command": "git push"})
+	assert.Equal(t, "deferred", out["status"])
+	assert.Equal(t, "q1", pause.QuestionID(), "the pending approval is kept")
+}
@@ -21,6 +21,8 @@ const (
 	ToolNameSpawnAgents         = "spawn_agents"
 	ToolNameListAvailableAgents = "list_available_agents"
 	ToolNameAskUser             = "ask_user"
+	ToolNameRemember            = "remember"
+	ToolNameForget              = "forget"
 )
 
 // coordinationTools is the set of tools denied to sub-agents by default.
@@ -36,7 +38,12 @@ const DefaultMaxDepth = 2
 type ToolPoolConfig struct {
 	MCPService      *mcp.Service
 	RegistryService *mcpregistry.Service
-	Logger          *slog.Logger
+	// MemoryService backs the remember and forget tools; nil disables them.
+	MemoryService *MemoryService
+	// Notifier tells connected MCP sessions when a project's tools change;
+	// nil disables the notifications.
+	Notifier *mcp.Notifier
+	Logger   *slog.Logger
 }
 
 // ToolPool maintains a per-project cache of available tools, combining
@@ -45,6 +52,8 @@ type ToolPoolConfig struct {
 type ToolPool struct {
 	mcpService      *mcp.Service
 	registryService *mcpregistry.Service
+	memory          *MemoryService
+	notifier        *mcp.Notifier
 	log             *slog.Logger
 
 	// Per-project cache of tool definitions
@@ -72,6 +81,8 @@ func NewToolPool(cfg ToolPoolConfig) *ToolPool {
 	return &ToolPool{
 		mcpService:      cfg.MCPService,
 		registryService: cfg.RegistryService,
+		memory:          cfg.MemoryService,
+		notifier:        cfg.Notifier,
 		log:             log,
 		cache:           make(map[string]*projectToolCache),
 	}
@@ -164,19 +175,25 @@ func (tp *ToolPool) buildCache(projectID string) *projectToolCache {
 // Call this when a project's MCP server configuration changes.
 func (tp *ToolPool) InvalidateCache(projectID string) {
 	tp.mu.Lock()
-	defer tp.mu.Unlock()
 	delete(tp.cache, projectID)
+	tp.mu.Unlock()
 	tp.log.Info("invalidated tool pool cache",
 		slog.String("project_id", projectID),
 	)
+	if tp.notifier != nil {
+		tp.notifier.ToolsChanged(projectID)
+	}
 }
 
 // InvalidateAll removes all cached tool pools.
 func (tp *ToolPool) InvalidateAll() {
 	tp.mu.Lock()
-	defer tp.mu.Unlock()
 	tp.cache = make(map[string]*projectToolCache)
+	tp.mu.Unlock()
 	tp.log.Info("invalidated all tool pool caches")
+	if tp.notifier != nil {
+		tp.notifier.ToolsChanged("")
+	}
 }
 
 // ResolveTools filters the project's ToolPool to only the tools allowed by the
@@ -201,7 +218,59 @@ func (tp *ToolPool) ResolveTools(projectID string, agentDef *AgentDefinition, de
 	resolvedDefs := tp.filterToolDefs(cache, agentDef, depth, maxDepth)
 
 	// Wrap resolved definitions as ADK tools
-	return tp.wrapTools(projectID, resolvedDefs)
+	tools, err := tp.wrapTools(projectID, resolvedDefs)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryTools, err := tp.resolveMemoryTools(agentDef)
+	if err != nil {
+		return nil, err
+	}
+	return append(tools, memoryTools...), nil
+}
+
+// resolveMemoryTools returns the remember and forget tools an agent
+// definition opts into by exact name, glob pattern or "*". An empty whitelist
+// does not grant them, since memories belong to a definition.
+func (tp *ToolPool) resolveMemoryTools(agentDef *AgentDefinition) ([]tool.Tool, error) {
+	if tp.memory == nil || agentDef == nil {
+		return nil, nil
+	}
+
+	builders := []struct {
+		name  string
+		build func(*MemoryService) (tool.Tool, error)
+	}{
+		{ToolNameRemember, BuildRememberTool},
+		{ToolNameForget, BuildForgetTool},
+	}
+	var tools []tool.Tool
+	for _, b := range builders {
+		if !whitelistAllows(agentDef.Tools, b.name) {
+			continue
+		}
+		t, err := b.build(tp.memory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s tool: %w", b.name, err)
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+// whitelistAllows reports whether a tools whitelist names a tool exactly, by
+// glob pattern or with "*".
+func whitelistAllows(whitelist []string, name string) bool {
+	for _, pattern := range whitelist {
+		if pattern == name || pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // filterToolDefs selects tool definitions from the cache based on the agent
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 
 	"github.com/emergent-company/emergent.memory/domain/events"
 	"github.com/emergent-company/emergent.memory/domain/scheduler"
@@ -24,6 +25,10 @@ type TriggerService struct {
 	// mu protects eventListeners
 	mu             sync.RWMutex
 	eventListeners map[string][]*Agent // eventKey -> agents
+
+	// dispatcherActive is set while the EventDispatcher delivers graph
+	// object events from the outbox.
+	dispatcherActive atomic.Bool
 }
 
 // NewTriggerService creates a new TriggerService.
@@ -58,16 +63,15 @@ func (ts *TriggerService) onEntityEvent(evt events.EntityEvent) {
 		return
 	}
 
+	// Graph object events are delivered durably from the outbox by the
+	// EventDispatcher; handling them here as well would run agents twice.
+	if ts.dispatcherActive.Load() && evt.Entity == events.EntityGraphObject {
+		return
+	}
+
 	// Map entity event type to reaction event type
-	var reactionType ReactionEventType
-	switch evt.Type {
-	case events.EventTypeCreated:
-		reactionType = EventTypeCreated
-	case events.EventTypeUpdated:
-		reactionType = EventTypeUpdated
-	case events.EventTypeDeleted:
-		reactionType = EventTypeDeleted
-	default:
+	reactionType, ok := reactionEventType(evt.Type)
+	if !ok {
 		return // Unsupported event type
 	}
 
@@ -106,6 +110,21 @@ func (ts *TriggerService) onEntityEvent(evt events.EntityEvent) {
 	}
 }
 
+// reactionEventType maps an entity event type to the reaction event type
+// agents subscribe to. Batch events have no reaction equivalent.
+func reactionEventType(t events.EntityEventType) (ReactionEventType, bool) {
+	switch t {
+	case events.EventTypeCreated:
+		return EventTypeCreated, true
+	case events.EventTypeUpdated:
+		return EventTypeUpdated, true
+	case events.EventTypeDeleted:
+		return EventTypeDeleted, true
+	default:
+		return "", false
+	}
+}
+
 // triggerTaskName returns the scheduler task name for an agent.
 func triggerTaskName(agentID string) string {
 	return "agent:" + agentID
@@ -356,6 +375,22 @@ func (ts *TriggerService) executeTriggeredAgent(ctx context.Context, agentID str
 		return fmt.Errorf("agent %s not found (may have been deleted)", agentID)
 	}
 
+	_, err = ts.runTriggeredAgent(ctx, agent, nil)
+	return err
+}
+
+// reactionTrigger describes the queued entity event a reaction agent run
+// handles.
+type reactionTrigger struct {
+	Delivery *AgentProcessingLog
+}
+
+// runTriggeredAgent executes an agent with its AgentDefinition config. When
+// the run handles an entity event, the event is described in the user message
+// and recorded as the run's trigger.
+func (ts *TriggerService) runTriggeredAgent(ctx context.Context, agent *Agent, trigger *reactionTrigger) (*ExecuteResult, error) {
+	projectID := agent.ProjectID
+
 	// Look up the corresponding AgentDefinition for config (system prompt, tools, etc.)
 	var agentDef *AgentDefinition
 	agentDef, _ = ts.repo.FindDefinitionByName(ctx, projectID, agent.Name)
@@ -372,16 +407,35 @@ func (ts *TriggerService) executeTriggeredAgent(ctx context.Context, agentID str
 		maxSteps = agentDef.MaxSteps
 	}
 
-	// Execute the agent
-	result, err := ts.executor.Execute(ctx, ExecuteRequest{
+	req := ExecuteRequest{
 		Agent:           agent,
 		AgentDefinition: agentDef,
 		ProjectID:       projectID,
 		UserMessage:     userMessage,
 		MaxSteps:        maxSteps,
-	})
+	}
+	if trigger != nil {
+		d := trigger.Delivery
+		req.UserMessage += fmt.Sprintf("\n\nTriggered by a %q event for graph object %s (version %d).",
+			d.EventType, d.GraphObjectID, d.ObjectVersion)
+		source := "reaction"
+		req.TriggerSource = &source
+		req.TriggerMetadata = map[string]any{
+			"deliveryId": d.ID,
+			"objectId":   d.GraphObjectID,
+			"version":    d.ObjectVersion,
+			"eventType":  string(d.EventType),
+			"attempt":    d.AttemptCount,
+		}
+		if d.EventID != nil {
+			req.TriggerMetadata["eventId"] = *d.EventID
+		}
+	}
+
+	// Execute the agent
+	result, err := ts.executor.Execute(ctx, req)
 	if err != nil {
-		return fmt.Errorf("agent execution failed: %w", err)
+		return nil, fmt.Errorf("agent execution failed: %w", err)
 	}
 
 	ts.log.Info("triggered agent execution completed",
@@ -392,7 +446,7 @@ func (ts *TriggerService) executeTriggeredAgent(ctx context.Context, agentID str
 		slog.Int("steps", result.Steps),
 		slog.Duration("duration", result.Duration),
 	)
-	return nil
+	return result, nil
 }
 
 // GetEventListeners returns the agents registered for a given event key (for testing/debugging).
@@ -18,6 +18,7 @@ type ApiToken struct {
 	TokenPrefix    string     `bun:"token_prefix,notnull"`
 	TokenEncrypted *string    `bun:"token_encrypted"`
 	Scopes         []string   `bun:"scopes,array"`
+	AllowedTools   []string   `bun:"allowed_tools,array"`
 	CreatedAt      time.Time  `bun:"created_at,notnull,default:now()"`
 	LastUsedAt     *time.Time `bun:"last_used_at"`
 	RevokedAt      *time.Time `bun:"revoked_at"`
@@ -25,14 +26,15 @@ type ApiToken struct {
 
 // ApiTokenDTO is the response DTO for API token endpoints (without sensitive data)
 type ApiTokenDTO struct {
-	ID          string     `json:"id"`
-	ProjectID   *string    `json:"projectId,omitempty"`
-	Name        string     `json:"name"`
-	TokenPrefix string     `json:"tokenPrefix"`
-	Scopes      []string   `json:"scopes"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
-	IsRevoked   bool       `json:"isRevoked"`
+	ID           string     `json:"id"`
+	ProjectID    *string    `json:"projectId,omitempty"`
+	Name         string     `json:"name"`
+	TokenPrefix  string     `json:"tokenPrefix"`
+	Scopes       []string   `json:"scopes"`
+	AllowedTools []string   `json:"allowedTools,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	IsRevoked    bool       `json:"isRevoked"`
 }
 
 // CreateApiTokenResponseDTO extends ApiTokenDTO with the full token value (only at creation)
@@ -57,12 +59,18 @@ type ApiTokenListResponseDTO struct {
 type CreateApiTokenRequest struct {
 	Name   string   `json:"name" validate:"required,min=1,max=255"`
 	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=schema:read data:read data:write agents:read agents:write projects:read projects:write"`
+	// AllowedTools limits the token to these MCP tools; omit to allow every
+	// tool its scopes permit
+	AllowedTools []string `json:"allowedTools,omitempty" validate:"omitempty,max=100,dive,min=1,max=100"`
 }
 
 // CreateAccountTokenRequest is the request body for creating an account-level token (no project binding)
 type CreateAccountTokenRequest struct {
 	Name   string   `json:"name" validate:"required,min=1,max=255"`
 	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=schema:read data:read data:write agents:read agents:write projects:read projects:write"`
+	// AllowedTools limits the token to these MCP tools; omit to allow every
+	// tool its scopes permit
+	AllowedTools []string `json:"allowedTools,omitempty" validate:"omitempty,max=100,dive,min=1,max=100"`
 }
 
 // Available scopes for API tokens
@@ -79,13 +87,14 @@ var ValidApiTokenScopes = []string{
 // ToDTO converts an ApiToken entity to ApiTokenDTO
 func (t *ApiToken) ToDTO() ApiTokenDTO {
 	return ApiTokenDTO{
-		ID:          t.ID,
-		ProjectID:   t.ProjectID,
-		Name:        t.Name,
-		TokenPrefix: t.TokenPrefix,
-		Scopes:      t.Scopes,
-		CreatedAt:   t.CreatedAt,
-		LastUsedAt:  t.LastUsedAt,
-		IsRevoked:   t.RevokedAt != nil,
+		ID:           t.ID,
+		ProjectID:    t.ProjectID,
+		Name:         t.Name,
+		TokenPrefix:  t.TokenPrefix,
+		Scopes:       t.Scopes,
+		AllowedTools: t.AllowedTools,
+		CreatedAt:    t.CreatedAt,
+		LastUsedAt:   t.LastUsedAt,
+		IsRevoked:    t.RevokedAt != nil,
 	}
 }
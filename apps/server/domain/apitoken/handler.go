@@ -60,7 +60,7 @@ func (h *Handler) Create(c echo.Context) error {
 		return apperror.ErrBadRequest.WithMessage("at least one scope is required")
 	}
 
-	result, err := h.svc.Create(c.Request().Context(), projectID, user.ID, req.Name, req.Scopes)
+	result, err := h.svc.Create(c.Request().Context(), projectID, user.ID, req.Name, req.Scopes, req.AllowedTools)
 	if err != nil {
 		return err
 	}
@@ -205,7 +205,7 @@ func (h *Handler) CreateAccountToken(c echo.Context) error {
 		return apperror.ErrBadRequest.WithMessage("at least one scope is required")
 	}
 
-	result, err := h.svc.CreateAccountToken(c.Request().Context(), user.ID, req.Name, req.Scopes)
+	result, err := h.svc.CreateAccountToken(c.Request().Context(), user.ID, req.Name, req.Scopes, req.AllowedTools)
 	if err != nil {
 		return err
 	}
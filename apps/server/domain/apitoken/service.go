@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"log/slog"
 
+	"github.com/emergent-company/emergent.memory/domain/mcp"
 	"github.com/emergent-company/emergent.memory/pkg/apperror"
 	"github.com/emergent-company/emergent.memory/pkg/encryption"
 	"github.com/emergent-company/emergent.memory/pkg/logger"
@@ -59,8 +60,19 @@ func getTokenPrefix(token string) string {
 	return token[:12]
 }
 
+// validateAllowedTools checks that every allowed tool is a known MCP tool.
+// An empty list leaves the token unrestricted.
+func validateAllowedTools(tools []string) error {
+	for _, tool := range tools {
+		if !mcp.IsKnownTool(tool) {
+			return apperror.ErrBadRequest.WithMessage("unknown MCP tool: " + tool)
+		}
+	}
+	return nil
+}
+
 // Create creates a new API token
-func (s *Service) Create(ctx context.Context, projectID, userID, name string, scopes []string) (*CreateApiTokenResponseDTO, error) {
+func (s *Service) Create(ctx context.Context, projectID, userID, name string, scopes, allowedTools []string) (*CreateApiTokenResponseDTO, error) {
 	// Validate scopes
 	for _, scope := range scopes {
 		valid := false
@@ -74,6 +86,9 @@ func (s *Service) Create(ctx context.Context, projectID, userID, name string, sc
 			return nil, apperror.ErrBadRequest.WithMessage("invalid scope: " + scope)
 		}
 	}
+	if err := validateAllowedTools(allowedTools); err != nil {
+		return nil, err
+	}
 
 	// Check for duplicate name
 	existing, err := s.repo.FindByProjectAndName(ctx, projectID, name)
@@ -111,6 +126,7 @@ func (s *Service) Create(ctx context.Context, projectID, userID, name string, sc
 		TokenPrefix:    getTokenPrefix(rawToken),
 		TokenEncrypted: tokenEncrypted,
 		Scopes:         scopes,
+		AllowedTools:   allowedTools,
 	}
 
 	if err := s.repo.Create(ctx, token); err != nil {
@@ -211,7 +227,7 @@ func (s *Service) Revoke(ctx context.Context, tokenID, projectID, userID string)
 }
 
 // CreateAccountToken creates a new account-level (non-project-bound) API token
-func (s *Service) CreateAccountToken(ctx context.Context, userID, name string, scopes []string) (*CreateApiTokenResponseDTO, error) {
+func (s *Service) CreateAccountToken(ctx context.Context, userID, name string, scopes, allowedTools []string) (*CreateApiTokenResponseDTO, error) {
 	// Validate scopes
 	for _, scope := range scopes {
 		valid := false
@@ -225,6 +241,9 @@ func (s *Service) CreateAccountToken(ctx context.Context, userID, name string, s
 			return nil, apperror.ErrBadRequest.WithMessage("invalid scope: " + scope)
 		}
 	}
+	if err := validateAllowedTools(allowedTools); err != nil {
+		return nil, err
+	}
 
 	// Check for duplicate name (among active account tokens for this user)
 	existing, err := s.repo.FindByUserAndName(ctx, userID, name)
@@ -262,6 +281,7 @@ func (s *Service) CreateAccountToken(ctx context.Context, userID, name string, s
 		TokenPrefix:    getTokenPrefix(rawToken),
 		TokenEncrypted: tokenEncrypted,
 		Scopes:         scopes,
+		AllowedTools:   allowedTools,
 	}
 
 	if err := s.repo.CreateAccountToken(ctx, token); err != nil {
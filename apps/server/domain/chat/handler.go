@@ -858,11 +858,16 @@ func (h *Handler) streamAgentChat(ctx context.Context, conv *Conversation, messa
 		// err is already nil
 	} else {
 		// Execute the real agent
+		var userID string
+		if conv.OwnerUserID != nil {
+			userID = *conv.OwnerUserID
+		}
 		result, err = h.agentExecutor.Execute(ctx, agents.ExecuteRequest{
 			Agent:           dummyAgent,
 			AgentDefinition: def,
 			ProjectID:       projectID,
 			UserMessage:     userMessage,
+			UserID:          userID,
 			StreamCallback:  streamCallback,
 		})
 	}
@@ -89,6 +89,13 @@ type ListChunksResponse struct {
 	TotalCount int         `json:"totalCount"`
 }
 
+// ChunkContextResponse is a chunk with its neighbouring chunks, in document order
+type ChunkContextResponse struct {
+	ChunkID    string      `json:"chunkId"`
+	DocumentID string      `json:"documentId"`
+	Chunks     []*ChunkDTO `json:"chunks"`
+}
+
 // BulkDeleteRequest is the request for bulk deleting chunks
 type BulkDeleteRequest struct {
 	IDs []string `json:"ids" validate:"required,min=1"`
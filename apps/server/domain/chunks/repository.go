@@ -91,6 +91,30 @@ func (r *Repository) GetByID(ctx context.Context, projectID, chunkID uuid.UUID)
 	return &chunk, nil
 }
 
+// ListByIndexRange returns a document's chunks with chunk_index between
+// from and to (inclusive), in order
+func (r *Repository) ListByIndexRange(ctx context.Context, projectID, documentID uuid.UUID, from, to int) ([]*ChunkWithDocInfo, error) {
+	var chunks []*ChunkWithDocInfo
+
+	err := r.db.NewSelect().
+		TableExpr("kb.chunks AS c").
+		ColumnExpr("c.*").
+		ColumnExpr("d.filename AS document_filename").
+		ColumnExpr("d.source_url AS document_source_url").
+		Join("INNER JOIN kb.documents AS d ON d.id = c.document_id").
+		Where("c.document_id = ?", documentID).
+		Where("d.project_id = ?", projectID).
+		Where("c.chunk_index BETWEEN ? AND ?", from, to).
+		Order("c.chunk_index").
+		Scan(ctx, &chunks)
+	if err != nil {
+		r.log.Error("failed to list chunk range", "error", err, "documentId", documentID)
+		return nil, apperror.NewInternal("failed to list chunks", err)
+	}
+
+	return chunks, nil
+}
+
 // Create creates a new chunk
 func (r *Repository) Create(ctx context.Context, chunk *Chunk) error {
 	_, err := r.db.NewInsert().
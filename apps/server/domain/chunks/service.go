@@ -53,6 +53,34 @@ func (s *Service) GetByID(ctx context.Context, projectID, chunkID uuid.UUID) (*C
 	return chunk.ToDTO(), nil
 }
 
+// GetContext returns a chunk together with up to before and after neighbouring
+// chunks of the same document, e.g. to read around a search hit
+func (s *Service) GetContext(ctx context.Context, projectID, chunkID uuid.UUID, before, after int) (*ChunkContextResponse, error) {
+	hit, err := s.repo.GetByID(ctx, projectID, chunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := max(hit.ChunkIndex-max(before, 0), 0)
+	to := hit.ChunkIndex + max(after, 0)
+
+	window, err := s.repo.ListByIndexRange(ctx, projectID, hit.DocumentID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*ChunkDTO, 0, len(window))
+	for _, chunk := range window {
+		dtos = append(dtos, chunk.ToDTO())
+	}
+
+	return &ChunkContextResponse{
+		ChunkID:    hit.ID.String(),
+		DocumentID: hit.DocumentID.String(),
+		Chunks:     dtos,
+	}, nil
+}
+
 // Delete deletes a chunk by ID
 func (s *Service) Delete(ctx context.Context, projectID, chunkID uuid.UUID) error {
 	return s.repo.Delete(ctx, projectID, chunkID)
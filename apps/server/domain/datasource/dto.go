@@ -31,21 +31,23 @@ type ProviderSchemaDTO struct {
 
 // DataSourceIntegrationDTO represents a data source integration for API responses
 type DataSourceIntegrationDTO struct {
-	ID                  string     `json:"id"`
-	ProjectID           string     `json:"projectId"`
-	Name                string     `json:"name"`
-	Description         *string    `json:"description,omitempty"`
-	ProviderType        string     `json:"providerType"`
-	SourceType          string     `json:"sourceType"`
-	SyncMode            string     `json:"syncMode"`
-	SyncIntervalMinutes *int       `json:"syncIntervalMinutes,omitempty"`
-	LastSyncedAt        *time.Time `json:"lastSyncedAt,omitempty"`
-	NextSyncAt          *time.Time `json:"nextSyncAt,omitempty"`
-	Status              string     `json:"status"`
-	ErrorMessage        *string    `json:"errorMessage,omitempty"`
-	ErrorCount          int        `json:"errorCount"`
-	CreatedAt           time.Time  `json:"createdAt"`
-	UpdatedAt           time.Time  `json:"updatedAt"`
+	ID                  string       `json:"id"`
+	ProjectID           string       `json:"projectId"`
+	Name                string       `json:"name"`
+	Description         *string      `json:"description,omitempty"`
+	ProviderType        string       `json:"providerType"`
+	SourceType          string       `json:"sourceType"`
+	SyncMode            string       `json:"syncMode"`
+	SyncIntervalMinutes *int         `json:"syncIntervalMinutes,omitempty"`
+	LastSyncedAt        *time.Time   `json:"lastSyncedAt,omitempty"`
+	NextSyncAt          *time.Time   `json:"nextSyncAt,omitempty"`
+	Status              string       `json:"status"`
+	ErrorMessage        *string      `json:"errorMessage,omitempty"`
+	ErrorCount          int          `json:"errorCount"`
+	WebhookEnabled      bool         `json:"webhookEnabled"`
+	MappingRules        MappingRules `json:"mappingRules"`
+	CreatedAt           time.Time    `json:"createdAt"`
+	UpdatedAt           time.Time    `json:"updatedAt"`
 }
 
 // CreateDataSourceIntegrationDTO represents request to create a new integration
@@ -57,6 +59,7 @@ type CreateDataSourceIntegrationDTO struct {
 	Config              map[string]interface{} `json:"config"`
 	SyncMode            *string                `json:"syncMode,omitempty"`
 	SyncIntervalMinutes *int                   `json:"syncIntervalMinutes,omitempty"`
+	MappingRules        MappingRules           `json:"mappingRules,omitempty"`
 }
 
 // UpdateDataSourceIntegrationDTO represents request to update an integration
@@ -67,6 +70,7 @@ type UpdateDataSourceIntegrationDTO struct {
 	SyncMode            *string                `json:"syncMode,omitempty"`
 	SyncIntervalMinutes *int                   `json:"syncIntervalMinutes,omitempty"`
 	Enabled             *bool                  `json:"enabled,omitempty"`
+	MappingRules        *MappingRules          `json:"mappingRules,omitempty"` // Replaces all rules; [] removes them
 }
 
 // TestConfigDTO represents request to test a provider configuration
@@ -81,6 +85,21 @@ type TestConnectionResponseDTO struct {
 	Message string `json:"message"`
 }
 
+// MappingPreviewRequestDTO represents request to dry-run mapping rules
+type MappingPreviewRequestDTO struct {
+	// Rules to preview (default: the integration's saved rules)
+	Rules *MappingRules `json:"rules,omitempty"`
+
+	// Records to map (default: the sample kept from the latest sync)
+	Records []Record `json:"records,omitempty"`
+}
+
+// MappingPreviewResponseDTO is the result of a mapping dry run
+type MappingPreviewResponseDTO struct {
+	Records []Record       `json:"records"`
+	Result  *MappingResult `json:"result"`
+}
+
 // ------------------------------------------------------------------
 // Sync Job DTOs
 // ------------------------------------------------------------------
@@ -106,6 +125,7 @@ type SyncJobDTO struct {
 	CreatedAt         time.Time  `json:"createdAt"`
 	StartedAt         *time.Time `json:"startedAt,omitempty"`
 	CompletedAt       *time.Time `json:"completedAt,omitempty"`
+	Logs              JSONArray  `json:"logs,omitempty"`
 }
 
 // TriggerSyncDTO represents request to trigger a sync
@@ -122,6 +142,43 @@ type TriggerSyncResponseDTO struct {
 	JobID   *string `json:"jobId,omitempty"`
 }
 
+// ------------------------------------------------------------------
+// Webhook DTOs
+// ------------------------------------------------------------------
+
+// SetWebhookSecretDTO represents request to enable webhooks on an integration
+type SetWebhookSecretDTO struct {
+	// Secret is the signing secret issued by the provider (e.g. ClickUp generates
+	// one per webhook). When empty, a random secret is generated.
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookSecretResponseDTO is returned once when webhooks are enabled or the secret rotated
+type WebhookSecretResponseDTO struct {
+	Secret     string `json:"secret"`
+	WebhookURL string `json:"webhookUrl"`
+}
+
+// WebhookReceiveResponseDTO is the response to an inbound webhook delivery
+type WebhookReceiveResponseDTO struct {
+	Status     string  `json:"status"` // 'accepted', 'duplicate', 'ignored', 'verified'
+	DeliveryID string  `json:"deliveryId"`
+	JobID      *string `json:"jobId,omitempty"`
+	Challenge  string  `json:"challenge,omitempty"` // Echoed for endpoint verification requests
+}
+
+// WebhookDeliveryDTO represents a logged webhook delivery
+type WebhookDeliveryDTO struct {
+	ID            string    `json:"id"`
+	DeliveryID    string    `json:"deliveryId"`
+	EventType     *string   `json:"eventType,omitempty"`
+	ItemIDs       []string  `json:"itemIds"`
+	Status        string    `json:"status"`
+	StatusMessage *string   `json:"statusMessage,omitempty"`
+	SyncJobID     *string   `json:"syncJobId,omitempty"`
+	ReceivedAt    time.Time `json:"receivedAt"`
+}
+
 // ------------------------------------------------------------------
 // Sync Configuration DTOs
 // ------------------------------------------------------------------
@@ -204,6 +261,8 @@ func (i *DataSourceIntegration) ToDTO() DataSourceIntegrationDTO {
 		Status:              string(i.Status),
 		ErrorMessage:        i.ErrorMessage,
 		ErrorCount:          i.ErrorCount,
+		WebhookEnabled:      i.WebhookSecret != nil && *i.WebhookSecret != "",
+		MappingRules:        i.MappingRules,
 		CreatedAt:           i.CreatedAt,
 		UpdatedAt:           i.UpdatedAt,
 	}
@@ -231,5 +290,24 @@ func (j *DataSourceSyncJob) ToDTO() SyncJobDTO {
 		CreatedAt:         j.CreatedAt,
 		StartedAt:         j.StartedAt,
 		CompletedAt:       j.CompletedAt,
+		Logs:              j.Logs,
+	}
+}
+
+// ToDTO converts a DataSourceWebhookDelivery to WebhookDeliveryDTO
+func (d *DataSourceWebhookDelivery) ToDTO() WebhookDeliveryDTO {
+	itemIDs := []string(d.ItemIDs)
+	if itemIDs == nil {
+		itemIDs = []string{}
+	}
+	return WebhookDeliveryDTO{
+		ID:            d.ID,
+		DeliveryID:    d.DeliveryID,
+		EventType:     d.EventType,
+		ItemIDs:       itemIDs,
+		Status:        string(d.Status),
+		StatusMessage: d.StatusMessage,
+		SyncJobID:     d.SyncJobID,
+		ReceivedAt:    d.ReceivedAt,
 	}
 }
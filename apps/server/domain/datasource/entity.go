@@ -64,9 +64,10 @@ type DataSourceIntegration struct {
 	ProjectID           string            `bun:"project_id,notnull,type:uuid"`
 	Name                string            `bun:"name,notnull"`
 	Description         *string           `bun:"description"`
-	ProviderType        string            `bun:"provider_type,notnull"` // 'imap', 'gmail_oauth', 'google_drive', 'clickup'
-	SourceType          string            `bun:"source_type,notnull"`   // 'email', 'drive', 'clickup-document'
-	ConfigEncrypted     *string           `bun:"config_encrypted"`      // AES-256-GCM encrypted config
+	ProviderType        string            `bun:"provider_type,notnull"`    // 'imap', 'gmail_oauth', 'google_drive', 'clickup'
+	SourceType          string            `bun:"source_type,notnull"`      // 'email', 'drive', 'clickup-document'
+	ConfigEncrypted     *string           `bun:"config_encrypted"`         // AES-256-GCM encrypted config
+	WebhookSecret       *string           `bun:"webhook_secret_encrypted"` // Encrypted HMAC signing secret (nil = webhooks disabled)
 	SyncMode            SyncMode          `bun:"sync_mode,notnull,default:'manual'"`
 	SyncIntervalMinutes *int              `bun:"sync_interval_minutes"`
 	LastSyncedAt        *time.Time        `bun:"last_synced_at"`
@@ -75,6 +76,8 @@ type DataSourceIntegration struct {
 	ErrorMessage        *string           `bun:"error_message"`
 	ErrorCount          int               `bun:"error_count,notnull,default:0"`
 	Metadata            JSON              `bun:"metadata,type:jsonb,notnull,default:'{}'"`
+	MappingRules        MappingRules      `bun:"mapping_rules,type:jsonb,notnull,default:'[]'"`
+	MappingSample       Records           `bun:"mapping_sample,type:jsonb,notnull,default:'[]'"`
 	CreatedBy           *string           `bun:"created_by,type:uuid"`
 	CreatedAt           time.Time         `bun:"created_at,notnull,default:now()"`
 	UpdatedAt           time.Time         `bun:"updated_at,notnull,default:now()"`
@@ -118,6 +121,36 @@ type DataSourceSyncJob struct {
 	UpdatedAt         time.Time   `bun:"updated_at,notnull,default:now()"`
 }
 
+// ------------------------------------------------------------------
+// DataSourceWebhookDelivery - Log of inbound webhook deliveries
+// ------------------------------------------------------------------
+
+// WebhookDeliveryStatus represents the outcome of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryAccepted WebhookDeliveryStatus = "accepted" // Queued a targeted sync job
+	WebhookDeliveryIgnored  WebhookDeliveryStatus = "ignored"  // Verified but carried no syncable items
+)
+
+// DataSourceWebhookDelivery represents a verified webhook delivery in
+// kb.data_source_webhook_deliveries. The (integration_id, delivery_id) pair is
+// unique, which is how replayed deliveries are detected.
+type DataSourceWebhookDelivery struct {
+	bun.BaseModel `bun:"table:kb.data_source_webhook_deliveries,alias:dswd"`
+
+	ID            string                `bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	IntegrationID string                `bun:"integration_id,notnull,type:uuid"`
+	ProjectID     string                `bun:"project_id,notnull,type:uuid"`
+	DeliveryID    string                `bun:"delivery_id,notnull"`
+	EventType     *string               `bun:"event_type"`
+	ItemIDs       StringArray           `bun:"item_ids,type:jsonb,notnull,default:'[]'"`
+	Status        WebhookDeliveryStatus `bun:"status,notnull,default:'accepted'"`
+	StatusMessage *string               `bun:"status_message"`
+	SyncJobID     *string               `bun:"sync_job_id,type:uuid"`
+	ReceivedAt    time.Time             `bun:"received_at,notnull,default:now()"`
+}
+
 // ------------------------------------------------------------------
 // SyncConfiguration - Stored sync configurations
 // ------------------------------------------------------------------
@@ -0,0 +1,44 @@
+package datasource
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ExportStorageKeyOption is the sync job option carrying the storage key
+	// of an uploaded export archive
+	ExportStorageKeyOption = "exportStorageKey"
+
+	// MaxExportUploadBytes caps the size of an uploaded export archive
+	MaxExportUploadBytes = 500 << 20 // 500 MiB
+)
+
+// ExportProvider is implemented by providers that can import an uploaded
+// export archive (e.g. a Slack or Teams export) instead of calling the
+// source API. The archive is passed to Sync through ExportStorageKeyOption.
+type ExportProvider interface {
+	Provider
+
+	// AcceptsExports reports whether the integration config accepts archive uploads
+	AcceptsExports(config map[string]interface{}) bool
+}
+
+// exportStorageKeyPrefix is the storage prefix for an integration's export archives
+func exportStorageKeyPrefix(projectID, integrationID string) string {
+	return fmt.Sprintf("datasource-exports/%s/%s/", projectID, integrationID)
+}
+
+// exportStorageKey reads the export archive key from sync options. Keys
+// outside the integration's own prefix are rejected, since sync options can
+// be supplied by API callers.
+func exportStorageKey(custom map[string]interface{}, projectID, integrationID string) (string, error) {
+	key, _ := custom[ExportStorageKeyOption].(string)
+	if key == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(key, exportStorageKeyPrefix(projectID, integrationID)) || strings.Contains(key, "..") {
+		return "", fmt.Errorf("export archive does not belong to this integration")
+	}
+	return key, nil
+}
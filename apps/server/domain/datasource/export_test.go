@@ -0,0 +1,39 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportStorageKey(t *testing.T) {
+	prefix := exportStorageKeyPrefix("p1", "i1")
+	assert.Equal(t, "datasource-exports/p1/i1/", prefix)
+
+	tests := []struct {
+		name    string
+		custom  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "missing", custom: map[string]interface{}{}, want: ""},
+		{name: "not a string", custom: map[string]interface{}{ExportStorageKeyOption: 42}, want: ""},
+		{name: "own prefix", custom: map[string]interface{}{ExportStorageKeyOption: prefix + "a.zip"}, want: prefix + "a.zip"},
+		{name: "other integration", custom: map[string]interface{}{ExportStorageKeyOption: "datasource-exports/p1/i2/a.zip"}, wantErr: true},
+		{name: "traversal", custom: map[string]interface{}{ExportStorageKeyOption: prefix + "../i2/a.zip"}, wantErr: true},
+		{name: "arbitrary key", custom: map[string]interface{}{ExportStorageKeyOption: "documents/secret.pdf"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := exportStorageKey(tt.custom, "p1", "i1")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/emergent-company/emergent.memory/domain/graph"
+)
+
+// graphWriter adapts graph.Service for providers that map imported items
+// into graph objects and relationships. Object IDs handed to providers are
+// canonical IDs, so they stay valid as objects gain new versions.
+type graphWriter struct {
+	svc *graph.Service
+}
+
+func newGraphWriter(svc *graph.Service) *graphWriter {
+	return &graphWriter{svc: svc}
+}
+
+// UpsertObject creates or updates the object identified by (type, key)
+func (w *graphWriter) UpsertObject(ctx context.Context, projectID, objType, key string, properties map[string]any) (string, error) {
+	pid, err := uuid.Parse(projectID)
+	if err != nil {
+		return "", fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	obj, _, err := w.svc.CreateOrUpdate(ctx, pid, &graph.CreateGraphObjectRequest{
+		Type:       objType,
+		Key:        &key,
+		Properties: properties,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	return obj.CanonicalID.String(), nil
+}
+
+// FindObject returns the canonical ID of the object with the key and one of the types, or ""
+func (w *graphWriter) FindObject(ctx context.Context, projectID string, types []string, key string) (string, error) {
+	pid, err := uuid.Parse(projectID)
+	if err != nil {
+		return "", fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	resp, err := w.svc.List(ctx, graph.ListParams{
+		ProjectID: pid,
+		Types:     types,
+		Key:       &key,
+		Limit:     1,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Items) == 0 {
+		return "", nil
+	}
+	return resp.Items[0].CanonicalID.String(), nil
+}
+
+// Relate creates a relationship, returning the existing one if already present
+func (w *graphWriter) Relate(ctx context.Context, projectID, relType, srcID, dstID string) error {
+	pid, err := uuid.Parse(projectID)
+	if err != nil {
+		return fmt.Errorf("invalid project ID: %w", err)
+	}
+	src, err := uuid.Parse(srcID)
+	if err != nil {
+		return fmt.Errorf("invalid source ID: %w", err)
+	}
+	dst, err := uuid.Parse(dstID)
+	if err != nil {
+		return fmt.Errorf("invalid destination ID: %w", err)
+	}
+
+	_, err = w.svc.CreateRelationship(ctx, pid, &graph.CreateGraphRelationshipRequest{
+		Type:  relType,
+		SrcID: src,
+		DstID: dst,
+	})
+	return err
+}
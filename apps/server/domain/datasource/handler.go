@@ -2,13 +2,19 @@ package datasource
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	"github.com/emergent-company/emergent.memory/domain/datasource/providers/github"
+	"github.com/emergent-company/emergent.memory/domain/datasource/providers/slack"
+	"github.com/emergent-company/emergent.memory/internal/storage"
 	"github.com/emergent-company/emergent.memory/pkg/apperror"
 	"github.com/emergent-company/emergent.memory/pkg/auth"
 	"github.com/emergent-company/emergent.memory/pkg/encryption"
@@ -21,6 +27,8 @@ type Handler struct {
 	jobsSvc    *JobsService
 	registry   *ProviderRegistry
 	encryption *encryption.Service
+	storage    *storage.Service
+	mapper     *Mapper
 	log        *slog.Logger
 }
 
@@ -30,6 +38,8 @@ func NewHandler(
 	jobsSvc *JobsService,
 	registry *ProviderRegistry,
 	encryption *encryption.Service,
+	storage *storage.Service,
+	mapper *Mapper,
 	log *slog.Logger,
 ) *Handler {
 	return &Handler{
@@ -37,6 +47,8 @@ func NewHandler(
 		jobsSvc:    jobsSvc,
 		registry:   registry,
 		encryption: encryption,
+		storage:    storage,
+		mapper:     mapper,
 		log:        log.With(logger.Scope("datasource.handler")),
 	}
 }
@@ -47,7 +59,7 @@ func NewHandler(
 
 // ListProviders handles GET /api/data-source-integrations/providers
 // @Summary      List data source providers
-// @Description  Returns all available data source providers (ClickUp, IMAP, Gmail, Google Drive)
+// @Description  Returns all available data source providers (ClickUp, GitHub, Slack, IMAP, Gmail, Google Drive)
 // @Tags         datasource
 // @Accept       json
 // @Produce      json
@@ -60,10 +72,24 @@ func (h *Handler) ListProviders(c echo.Context) error {
 		{
 			Type:        "clickup",
 			Name:        "ClickUp",
-			Description: "Sync documents from ClickUp docs",
+			Description: "Sync docs and tasks from ClickUp",
 			SourceType:  "clickup-document",
 			Available:   true,
 		},
+		{
+			Type:        "github",
+			Name:        "GitHub",
+			Description: "Sync issues, pull requests, discussions and markdown files from a GitHub repository",
+			SourceType:  "github-issue",
+			Available:   true,
+		},
+		{
+			Type:        "slack",
+			Name:        "Slack",
+			Description: "Sync channel conversations from Slack, or import Slack and Teams export archives",
+			SourceType:  "slack-conversation",
+			Available:   true,
+		},
 		{
 			Type:        "imap",
 			Name:        "IMAP Email",
@@ -96,7 +122,7 @@ func (h *Handler) ListProviders(c echo.Context) error {
 // @Tags         datasource
 // @Accept       json
 // @Produce      json
-// @Param        providerType path string true "Provider type" Enums(clickup,imap,gmail_oauth,google_drive)
+// @Param        providerType path string true "Provider type" Enums(clickup,github,slack,imap,gmail_oauth,google_drive)
 // @Success      200 {object} ProviderSchemaDTO "Provider configuration schema"
 // @Failure      400 {object} apperror.Error "Invalid provider type"
 // @Failure      401 {object} apperror.Error "Unauthorized"
@@ -129,6 +155,17 @@ func (h *Handler) GetProviderSchema(c echo.Context) error {
 			},
 			Required: []string{"apiKey"},
 		}
+	case "github":
+		schema = ProviderSchemaDTO{
+			Type:       "object",
+			Properties: github.ConfigSchema["properties"].(map[string]interface{}),
+			Required:   []string{"repository"},
+		}
+	case "slack":
+		schema = ProviderSchemaDTO{
+			Type:       "object",
+			Properties: slack.ConfigSchema["properties"].(map[string]interface{}),
+		}
 	case "imap":
 		schema = ProviderSchemaDTO{
 			Type: "object",
@@ -428,6 +465,9 @@ func (h *Handler) Create(c echo.Context) error {
 	if _, ok := h.registry.Get(dto.ProviderType); !ok {
 		return apperror.NewBadRequest("unknown provider type: " + dto.ProviderType)
 	}
+	if err := dto.MappingRules.Validate(); err != nil {
+		return apperror.NewBadRequest("invalid mapping rules: " + err.Error())
+	}
 
 	// Check for duplicate name
 	ctx := c.Request().Context()
@@ -450,6 +490,7 @@ func (h *Handler) Create(c echo.Context) error {
 		SyncMode:       SyncModeManual,
 		Status:         IntegrationStatusActive,
 		Metadata:       make(JSON),
+		MappingRules:   dto.MappingRules,
 	}
 
 	if dto.SyncMode != nil {
@@ -551,6 +592,12 @@ func (h *Handler) Update(c echo.Context) error {
 			integration.Status = IntegrationStatusDisabled
 		}
 	}
+	if dto.MappingRules != nil {
+		if err := dto.MappingRules.Validate(); err != nil {
+			return apperror.NewBadRequest("invalid mapping rules: " + err.Error())
+		}
+		integration.MappingRules = *dto.MappingRules
+	}
 
 	// Encrypt new config if provided
 	if dto.Config != nil && len(dto.Config) > 0 {
@@ -801,6 +848,214 @@ func (h *Handler) TriggerSync(c echo.Context) error {
 	})
 }
 
+// PreviewMapping handles POST /api/data-source-integrations/:id/mapping/preview
+// @Summary      Preview mapping rules
+// @Description  Dry-runs field-to-graph mapping rules against records and reports the objects and relationships they would create or update. Rules default to the integration's saved rules and records to the sample kept from the latest sync. Nothing is written.
+// @Tags         datasource
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Integration ID (UUID)"
+// @Param        request body MappingPreviewRequestDTO false "Rules and records to preview"
+// @Param        X-Project-ID header string true "Project ID"
+// @Success      200 {object} MappingPreviewResponseDTO "Mapping preview"
+// @Failure      400 {object} apperror.Error "Invalid rules"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Integration not found"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/data-source-integrations/{id}/mapping/preview [post]
+// @Security     bearerAuth
+func (h *Handler) PreviewMapping(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	if user.ProjectID == "" {
+		return apperror.NewBadRequest("X-Project-ID header is required")
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return apperror.NewBadRequest("integration ID is required")
+	}
+
+	var dto MappingPreviewRequestDTO
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&dto); err != nil {
+			return apperror.NewBadRequest("invalid request body")
+		}
+	}
+
+	ctx := c.Request().Context()
+	integration, err := h.repo.GetByID(ctx, user.ProjectID, id)
+	if err != nil {
+		if errors.Is(err, ErrIntegrationNotFound) {
+			return apperror.NewNotFound("Integration", id)
+		}
+		return apperror.NewInternal("failed to get integration", err)
+	}
+
+	rules := integration.MappingRules
+	if dto.Rules != nil {
+		rules = *dto.Rules
+	}
+	if err := rules.Validate(); err != nil {
+		return apperror.NewBadRequest("invalid mapping rules: " + err.Error())
+	}
+
+	records := dto.Records
+	if records == nil {
+		records = integration.MappingSample
+	}
+	if len(records) > MaxPreviewRecords {
+		return apperror.NewBadRequest(fmt.Sprintf("at most %d records can be previewed", MaxPreviewRecords))
+	}
+	if records == nil {
+		records = []Record{}
+	}
+
+	result, err := h.mapper.Apply(ctx, integration, rules, records, true)
+	if err != nil {
+		return apperror.NewInternal("failed to preview mapping", err)
+	}
+
+	return c.JSON(http.StatusOK, MappingPreviewResponseDTO{
+		Records: records,
+		Result:  result,
+	})
+}
+
+// UploadExport handles POST /api/data-source-integrations/:id/export
+// @Summary      Upload export archive
+// @Description  Uploads an export archive (e.g. a Slack or Teams export zip) and queues a sync job that imports it without calling the source API
+// @Tags         datasource
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path string true "Integration ID (UUID)"
+// @Param        file formData file true "Export archive (.zip)"
+// @Param        X-Project-ID header string true "Project ID"
+// @Success      200 {object} TriggerSyncResponseDTO "Sync job created or already running"
+// @Failure      400 {object} apperror.Error "Invalid file or integration does not accept exports"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Integration not found"
+// @Failure      503 {object} apperror.Error "Storage not configured"
+// @Router       /api/data-source-integrations/{id}/export [post]
+// @Security     bearerAuth
+func (h *Handler) UploadExport(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	if user.ProjectID == "" {
+		return apperror.NewBadRequest("X-Project-ID header is required")
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return apperror.NewBadRequest("integration ID is required")
+	}
+
+	if h.storage == nil || !h.storage.Enabled() {
+		return apperror.New(http.StatusServiceUnavailable, "storage_unavailable", "Storage service is not configured")
+	}
+
+	ctx := c.Request().Context()
+	integration, err := h.repo.GetByID(ctx, user.ProjectID, id)
+	if err != nil {
+		if errors.Is(err, ErrIntegrationNotFound) {
+			return apperror.NewNotFound("Integration", id)
+		}
+		return apperror.NewInternal("failed to get integration", err)
+	}
+
+	if integration.Status == IntegrationStatusDisabled {
+		return apperror.NewBadRequest("integration is disabled")
+	}
+
+	provider, ok := h.registry.Get(integration.ProviderType)
+	if !ok {
+		return apperror.NewBadRequest("provider not available: " + integration.ProviderType)
+	}
+	exportProvider, ok := provider.(ExportProvider)
+	if !ok {
+		return apperror.NewBadRequest("provider does not support export archives")
+	}
+
+	config := map[string]interface{}{}
+	if integration.ConfigEncrypted != nil && *integration.ConfigEncrypted != "" {
+		config, err = h.encryption.Decrypt(ctx, *integration.ConfigEncrypted)
+		if err != nil {
+			return apperror.NewInternal("failed to decrypt configuration", err)
+		}
+	}
+	if !exportProvider.AcceptsExports(config) {
+		return apperror.NewBadRequest("integration is not configured for export import")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return apperror.NewBadRequest("file is required")
+	}
+	if file.Size > MaxExportUploadBytes {
+		return apperror.NewBadRequest("export archive exceeds maximum of 500MB")
+	}
+
+	activeJob, err := h.jobsSvc.GetActiveJobForIntegration(ctx, integration.ID)
+	if err != nil {
+		return apperror.NewInternal("failed to check active jobs", err)
+	}
+	if activeJob != nil {
+		return c.JSON(http.StatusOK, TriggerSyncResponseDTO{
+			Success: false,
+			Message: "A sync is already in progress",
+			JobID:   &activeJob.ID,
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return apperror.NewBadRequest("failed to read file")
+	}
+	defer src.Close()
+
+	key := exportStorageKeyPrefix(integration.ProjectID, integration.ID) + uuid.New().String() + ".zip"
+	if _, err := h.storage.Upload(ctx, key, src, file.Size, storage.UploadOptions{
+		ContentType: "application/zip",
+		Metadata:    map[string]string{"filename": file.Filename},
+	}); err != nil {
+		return apperror.NewInternal("failed to store export archive", err)
+	}
+
+	jobID := uuid.New().String()
+	job := &DataSourceSyncJob{
+		ID:            jobID,
+		IntegrationID: integration.ID,
+		ProjectID:     integration.ProjectID,
+		Status:        JobStatusPending,
+		TriggerType:   TriggerTypeManual,
+		MaxRetries:    3,
+		SyncOptions: JSON{
+			ExportStorageKeyOption: key,
+			"exportFilename":       file.Filename,
+		},
+	}
+	if user.ID != "" {
+		job.TriggeredBy = &user.ID
+	}
+
+	if err := h.jobsSvc.Create(ctx, job); err != nil {
+		_ = h.storage.Delete(ctx, key)
+		return apperror.NewInternal("failed to create sync job", err)
+	}
+
+	return c.JSON(http.StatusOK, TriggerSyncResponseDTO{
+		Success: true,
+		Message: "Export uploaded, import job created",
+		JobID:   &jobID,
+	})
+}
+
 // ListSyncJobs handles GET /api/data-source-integrations/:id/sync-jobs
 // @Summary      List sync jobs for integration
 // @Description  Returns all sync jobs for a specific integration with optional status filter
@@ -1038,3 +1293,327 @@ func (h *Handler) CancelSyncJob(c echo.Context) error {
 		"message": "Job cancelled",
 	})
 }
+
+// ------------------------------------------------------------------
+// Webhook Endpoints
+// ------------------------------------------------------------------
+
+// SetWebhookSecret handles POST /api/data-source-integrations/:id/webhook-secret
+// @Summary      Enable webhooks / rotate signing secret
+// @Description  Stores a signing secret for inbound webhooks (generated if not supplied). The secret is only returned once.
+// @Tags         datasource
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Integration ID (UUID)"
+// @Param        X-Project-ID header string true "Project ID"
+// @Param        request body SetWebhookSecretDTO false "Provider-issued secret (optional)"
+// @Success      200 {object} WebhookSecretResponseDTO "Signing secret and webhook URL"
+// @Failure      400 {object} apperror.Error "Provider does not support webhooks"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Integration not found"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/data-source-integrations/{id}/webhook-secret [post]
+// @Security     bearerAuth
+func (h *Handler) SetWebhookSecret(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	if user.ProjectID == "" {
+		return apperror.NewBadRequest("X-Project-ID header is required")
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return apperror.NewBadRequest("integration ID is required")
+	}
+
+	var dto SetWebhookSecretDTO
+	if err := c.Bind(&dto); err != nil {
+		// Binding failure is OK - dto is optional
+		dto = SetWebhookSecretDTO{}
+	}
+
+	ctx := c.Request().Context()
+	integration, err := h.repo.GetByID(ctx, user.ProjectID, id)
+	if err != nil {
+		if errors.Is(err, ErrIntegrationNotFound) {
+			return apperror.NewNotFound("Integration", id)
+		}
+		return apperror.NewInternal("failed to get integration", err)
+	}
+
+	provider, ok := h.registry.Get(integration.ProviderType)
+	if !ok {
+		return apperror.NewBadRequest("unknown provider type: " + integration.ProviderType)
+	}
+	if _, ok := provider.(WebhookProvider); !ok {
+		return apperror.NewBadRequest("provider does not support webhooks: " + integration.ProviderType)
+	}
+
+	secret := dto.Secret
+	if secret == "" {
+		secret, err = GenerateWebhookSecret()
+		if err != nil {
+			return apperror.NewInternal("failed to generate webhook secret", err)
+		}
+	}
+
+	encrypted, err := h.encryption.Encrypt(ctx, map[string]interface{}{webhookSecretKey: secret})
+	if err != nil {
+		return apperror.NewInternal("failed to encrypt webhook secret", err)
+	}
+
+	integration.WebhookSecret = &encrypted
+	integration.UpdatedAt = time.Now()
+	if err := h.repo.Update(ctx, integration); err != nil {
+		return apperror.NewInternal("failed to update integration", err)
+	}
+
+	return c.JSON(http.StatusOK, WebhookSecretResponseDTO{
+		Secret:     secret,
+		WebhookURL: "/api/data-source-integrations/" + integration.ID + "/webhook",
+	})
+}
+
+// DeleteWebhookSecret handles DELETE /api/data-source-integrations/:id/webhook-secret
+// @Summary      Disable webhooks
+// @Description  Removes the signing secret; subsequent webhook deliveries are rejected
+// @Tags         datasource
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Integration ID (UUID)"
+// @Param        X-Project-ID header string true "Project ID"
+// @Success      204 "Webhooks disabled"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Integration not found"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/data-source-integrations/{id}/webhook-secret [delete]
+// @Security     bearerAuth
+func (h *Handler) DeleteWebhookSecret(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	if user.ProjectID == "" {
+		return apperror.NewBadRequest("X-Project-ID header is required")
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return apperror.NewBadRequest("integration ID is required")
+	}
+
+	ctx := c.Request().Context()
+	integration, err := h.repo.GetByID(ctx, user.ProjectID, id)
+	if err != nil {
+		if errors.Is(err, ErrIntegrationNotFound) {
+			return apperror.NewNotFound("Integration", id)
+		}
+		return apperror.NewInternal("failed to get integration", err)
+	}
+
+	integration.WebhookSecret = nil
+	integration.UpdatedAt = time.Now()
+	if err := h.repo.Update(ctx, integration); err != nil {
+		return apperror.NewInternal("failed to update integration", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /api/data-source-integrations/:id/webhook-deliveries
+// @Summary      List webhook deliveries
+// @Description  Returns the most recent verified webhook deliveries and the sync jobs they triggered
+// @Tags         datasource
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Integration ID (UUID)"
+// @Param        limit query int false "Max deliveries to return (default 20)"
+// @Param        X-Project-ID header string true "Project ID"
+// @Success      200 {array} WebhookDeliveryDTO "Webhook deliveries"
+// @Failure      401 {object} apperror.Error "Unauthorized"
+// @Failure      404 {object} apperror.Error "Integration not found"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/data-source-integrations/{id}/webhook-deliveries [get]
+// @Security     bearerAuth
+func (h *Handler) ListWebhookDeliveries(c echo.Context) error {
+	user := auth.GetUser(c)
+	if user == nil {
+		return apperror.ErrUnauthorized
+	}
+
+	if user.ProjectID == "" {
+		return apperror.NewBadRequest("X-Project-ID header is required")
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return apperror.NewBadRequest("integration ID is required")
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.repo.GetByID(ctx, user.ProjectID, id); err != nil {
+		if errors.Is(err, ErrIntegrationNotFound) {
+			return apperror.NewNotFound("Integration", id)
+		}
+		return apperror.NewInternal("failed to get integration", err)
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	deliveries, err := h.repo.ListWebhookDeliveries(ctx, id, limit)
+	if err != nil {
+		return apperror.NewInternal("failed to list webhook deliveries", err)
+	}
+
+	dtos := make([]WebhookDeliveryDTO, len(deliveries))
+	for i, d := range deliveries {
+		dtos[i] = d.ToDTO()
+	}
+
+	return c.JSON(http.StatusOK, dtos)
+}
+
+// ReceiveWebhook handles POST /api/data-source-integrations/:id/webhook
+// @Summary      Receive data source webhook
+// @Description  Public endpoint for provider webhooks. Authenticated by the provider's HMAC signature; each verified delivery queues a targeted sync job for the changed items.
+// @Tags         datasource
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Integration ID (UUID)"
+// @Success      200 {object} WebhookReceiveResponseDTO "Duplicate or ignored delivery, or endpoint verification challenge"
+// @Success      202 {object} WebhookReceiveResponseDTO "Delivery accepted and sync job queued"
+// @Failure      400 {object} apperror.Error "Malformed payload"
+// @Failure      401 {object} apperror.Error "Invalid signature"
+// @Failure      404 {object} apperror.Error "Integration not found or webhooks disabled"
+// @Failure      500 {object} apperror.Error "Internal server error"
+// @Router       /api/data-source-integrations/{id}/webhook [post]
+func (h *Handler) ReceiveWebhook(c echo.Context) error {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return apperror.NewNotFound("Integration", id)
+	}
+
+	ctx := c.Request().Context()
+	integration, err := h.repo.GetByIDWithoutProject(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrIntegrationNotFound) {
+			return apperror.NewNotFound("Integration", id)
+		}
+		return apperror.NewInternal("failed to get integration", err)
+	}
+
+	// Don't reveal whether the integration exists when webhooks are off
+	if integration.WebhookSecret == nil || *integration.WebhookSecret == "" {
+		return apperror.NewNotFound("Integration", id)
+	}
+
+	provider, ok := h.registry.Get(integration.ProviderType)
+	if !ok {
+		return apperror.NewNotFound("Integration", id)
+	}
+	webhookProvider, ok := provider.(WebhookProvider)
+	if !ok {
+		return apperror.NewNotFound("Integration", id)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, MaxWebhookBodyBytes+1))
+	if err != nil {
+		return apperror.NewBadRequest("failed to read request body")
+	}
+	if len(body) > MaxWebhookBodyBytes {
+		return apperror.NewBadRequest("webhook payload too large")
+	}
+
+	secretData, err := h.encryption.Decrypt(ctx, *integration.WebhookSecret)
+	if err != nil {
+		return apperror.NewInternal("failed to decrypt webhook secret", err)
+	}
+	secret, _ := secretData[webhookSecretKey].(string)
+	if secret == "" {
+		return apperror.NewNotFound("Integration", id)
+	}
+
+	headers := c.Request().Header
+	if err := webhookProvider.VerifyWebhook(secret, headers, body); err != nil {
+		h.log.Warn("rejected webhook delivery",
+			slog.String("integration_id", integration.ID),
+			slog.String("provider_type", integration.ProviderType),
+			slog.String("error", err.Error()))
+		return apperror.ErrUnauthorized.WithMessage("invalid webhook signature")
+	}
+
+	delivery, err := webhookProvider.ParseWebhook(headers, body)
+	if err != nil {
+		return apperror.NewBadRequest("invalid webhook payload: " + err.Error())
+	}
+	if delivery.Challenge != "" {
+		return c.JSON(http.StatusOK, WebhookReceiveResponseDTO{
+			Status:     "verified",
+			DeliveryID: delivery.DeliveryID,
+			Challenge:  delivery.Challenge,
+		})
+	}
+	if delivery.DeliveryID == "" {
+		delivery.DeliveryID = bodyDeliveryID(body)
+	}
+
+	upserts, deletes := splitWebhookEvents(delivery.Events)
+	record := &DataSourceWebhookDelivery{
+		ID:            uuid.New().String(),
+		IntegrationID: integration.ID,
+		ProjectID:     integration.ProjectID,
+		DeliveryID:    delivery.DeliveryID,
+		ItemIDs:       StringArray(append(append([]string{}, upserts...), deletes...)),
+		Status:        WebhookDeliveryAccepted,
+		ReceivedAt:    time.Now(),
+	}
+	if delivery.EventType != "" {
+		record.EventType = &delivery.EventType
+	}
+
+	// Deliveries with nothing to sync are recorded as ignored. Otherwise the
+	// delivery and its sync job are created together, so a failed job insert
+	// leaves the delivery unrecorded and the provider's retry goes through.
+	var job *DataSourceSyncJob
+	var inserted bool
+	if integration.Status == IntegrationStatusDisabled || (len(upserts) == 0 && len(deletes) == 0) {
+		message := "no syncable items in delivery"
+		if integration.Status == IntegrationStatusDisabled {
+			message = "integration is disabled"
+		}
+		record.Status = WebhookDeliveryIgnored
+		record.StatusMessage = &message
+		inserted, err = h.repo.CreateWebhookDelivery(ctx, record)
+	} else {
+		job = newWebhookSyncJob(integration, delivery, upserts, deletes)
+		job.ID = uuid.New().String()
+		inserted, err = h.repo.CreateWebhookDeliveryJob(ctx, record, job)
+	}
+	if err != nil {
+		return apperror.NewInternal("failed to record webhook delivery", err)
+	}
+	if !inserted {
+		h.log.Info("ignored replayed webhook delivery",
+			slog.String("integration_id", integration.ID),
+			slog.String("delivery_id", delivery.DeliveryID))
+		return c.JSON(http.StatusOK, WebhookReceiveResponseDTO{
+			Status:     "duplicate",
+			DeliveryID: delivery.DeliveryID,
+		})
+	}
+	if job == nil {
+		return c.JSON(http.StatusOK, WebhookReceiveResponseDTO{
+			Status:     string(WebhookDeliveryIgnored),
+			DeliveryID: delivery.DeliveryID,
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, WebhookReceiveResponseDTO{
+		Status:     string(WebhookDeliveryAccepted),
+		DeliveryID: delivery.DeliveryID,
+		JobID:      &job.ID,
+	})
+}
@@ -377,16 +377,21 @@ func (s *JobsService) GetIntegration(ctx context.Context, id string) (*DataSourc
 	return integration, nil
 }
 
-// UpdateIntegrationSyncStatus updates the sync-related fields on an integration
-func (s *JobsService) UpdateIntegrationSyncStatus(ctx context.Context, integrationID string, lastSynced time.Time, nextSync *time.Time, status IntegrationStatus, errMsg *string) error {
+// UpdateIntegrationSyncStatus updates the sync-related fields on an integration.
+// A nil lastSynced leaves last_synced_at untouched so failed syncs don't advance
+// the incremental sync cursor.
+func (s *JobsService) UpdateIntegrationSyncStatus(ctx context.Context, integrationID string, lastSynced *time.Time, nextSync *time.Time, status IntegrationStatus, errMsg *string) error {
 	now := time.Now()
 	q := s.db.NewUpdate().
 		Model((*DataSourceIntegration)(nil)).
-		Set("last_synced_at = ?", lastSynced).
 		Set("status = ?", status).
 		Set("updated_at = ?", now).
 		Where("id = ?", integrationID)
 
+	if lastSynced != nil {
+		q = q.Set("last_synced_at = ?", *lastSynced)
+	}
+
 	if nextSync != nil {
 		q = q.Set("next_sync_at = ?", *nextSync)
 	}
@@ -402,6 +407,16 @@ func (s *JobsService) UpdateIntegrationSyncStatus(ctx context.Context, integrati
 	return err
 }
 
+// SaveMappingSample stores the record sample used to preview mapping rules
+func (s *JobsService) SaveMappingSample(ctx context.Context, integrationID string, sample Records) error {
+	_, err := s.db.NewUpdate().
+		Model((*DataSourceIntegration)(nil)).
+		Set("mapping_sample = ?", sample).
+		Where("id = ?", integrationID).
+		Exec(ctx)
+	return err
+}
+
 // truncateError truncates error messages to a reasonable length
 func truncateError(msg string) string {
 	const maxLen = 1000
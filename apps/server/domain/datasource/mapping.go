@@ -0,0 +1,515 @@
+package datasource
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emergent-company/emergent.memory/domain/graph"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	// MappingSampleSize is how many records from the latest sync are kept for previews
+	MappingSampleSize = 20
+
+	// MaxPreviewRecords caps the number of records a dry-run preview evaluates
+	MaxPreviewRecords = 200
+)
+
+// Mapping actions reported for planned objects
+const (
+	MappingActionCreate = "create"
+	MappingActionUpdate = "update"
+	MappingActionUpsert = "upsert"
+)
+
+// ------------------------------------------------------------------
+// Records
+// ------------------------------------------------------------------
+
+// Record is a structured item emitted by a provider alongside its documents.
+// Records whose SourceType has a mapping rule are written straight to the graph.
+type Record struct {
+	// SourceType is the provider's item type (e.g. "task")
+	SourceType string `json:"sourceType"`
+
+	// ID is the item's stable identifier at the source
+	ID string `json:"id"`
+
+	// Fields holds the item's data; mapping rules address it with field paths
+	Fields map[string]any `json:"fields"`
+}
+
+// Records is a helper type for JSONB columns holding records
+type Records []Record
+
+// Value implements driver.Valuer for Records
+func (r Records) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner for Records
+func (r *Records) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// ------------------------------------------------------------------
+// Mapping rules
+// ------------------------------------------------------------------
+
+// MappingRule maps one record type to a graph object type
+type MappingRule struct {
+	// SourceType is the record type this rule applies to
+	SourceType string `json:"sourceType"`
+
+	// ObjectType is the graph object type to create
+	ObjectType string `json:"objectType"`
+
+	// KeyField is the field path holding the object's stable key (default: the record ID)
+	KeyField string `json:"keyField,omitempty"`
+
+	// Properties maps object property names to field paths
+	Properties map[string]string `json:"properties,omitempty"`
+
+	// Relationships create edges from this object to objects of other rules
+	Relationships []RelationshipMapping `json:"relationships,omitempty"`
+}
+
+// RelationshipMapping derives relationships from a field holding references
+type RelationshipMapping struct {
+	// Type is the relationship type (e.g. "ASSIGNED_TO")
+	Type string `json:"type"`
+
+	// Field is the field path holding the referenced record ID(s)
+	Field string `json:"field"`
+
+	// TargetSourceType is the record type of the referenced items
+	TargetSourceType string `json:"targetSourceType"`
+}
+
+// MappingRules is the mapping configured on an integration
+type MappingRules []MappingRule
+
+// Value implements driver.Valuer for MappingRules
+func (m MappingRules) Value() (driver.Value, error) {
+	if m == nil {
+		return "[]", nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner for MappingRules
+func (m *MappingRules) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+// Validate checks that every rule is complete, that each source type has at
+// most one rule and that relationships point at mapped source types
+func (m MappingRules) Validate() error {
+	bySource := make(map[string]bool, len(m))
+	for i, rule := range m {
+		if rule.SourceType == "" {
+			return fmt.Errorf("rule %d: sourceType is required", i)
+		}
+		if rule.ObjectType == "" {
+			return fmt.Errorf("rule %d: objectType is required", i)
+		}
+		if bySource[rule.SourceType] {
+			return fmt.Errorf("rule %d: duplicate rule for source type %q", i, rule.SourceType)
+		}
+		bySource[rule.SourceType] = true
+		for prop, path := range rule.Properties {
+			if prop == "" || path == "" {
+				return fmt.Errorf("rule %d: property names and field paths must be non-empty", i)
+			}
+		}
+	}
+
+	for i, rule := range m {
+		for j, rel := range rule.Relationships {
+			if rel.Type == "" || rel.Field == "" || rel.TargetSourceType == "" {
+				return fmt.Errorf("rule %d relationship %d: type, field and targetSourceType are required", i, j)
+			}
+			if !bySource[rel.TargetSourceType] {
+				return fmt.Errorf("rule %d relationship %d: no rule for target source type %q", i, j, rel.TargetSourceType)
+			}
+		}
+	}
+	return nil
+}
+
+// find returns the rule for a source type
+func (m MappingRules) find(sourceType string) (*MappingRule, bool) {
+	for i := range m {
+		if m[i].SourceType == sourceType {
+			return &m[i], true
+		}
+	}
+	return nil, false
+}
+
+// ------------------------------------------------------------------
+// Field paths
+// ------------------------------------------------------------------
+
+// lookupField resolves a dot-separated field path. Numeric segments index
+// into arrays; any other segment applied to an array is applied to each
+// element, so "assignees.id" yields every assignee's ID.
+func lookupField(fields map[string]any, path string) any {
+	var current any = fields
+	for _, segment := range strings.Split(path, ".") {
+		current = lookupSegment(current, segment)
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+func lookupSegment(value any, segment string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return v[segment]
+	case []any:
+		if idx, err := strconv.Atoi(segment); err == nil {
+			if idx < 0 || idx >= len(v) {
+				return nil
+			}
+			return v[idx]
+		}
+		var out []any
+		for _, item := range v {
+			if got := lookupSegment(item, segment); got != nil {
+				if nested, ok := got.([]any); ok {
+					out = append(out, nested...)
+				} else {
+					out = append(out, got)
+				}
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// fieldStrings returns the scalar values at a field path as strings
+func fieldStrings(fields map[string]any, path string) []string {
+	var out []string
+	var collect func(v any)
+	collect = func(v any) {
+		switch t := v.(type) {
+		case nil:
+		case []any:
+			for _, item := range t {
+				collect(item)
+			}
+		case string:
+			if t != "" {
+				out = append(out, t)
+			}
+		case float64:
+			out = append(out, strconv.FormatFloat(t, 'f', -1, 64))
+		case bool, int, int64:
+			out = append(out, fmt.Sprint(t))
+		}
+	}
+	collect(lookupField(fields, path))
+	return out
+}
+
+// normalizeFields round-trips fields through JSON so providers can emit
+// typed values (structs, []string) and paths see plain maps and slices
+func normalizeFields(fields map[string]any) map[string]any {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fields
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fields
+	}
+	return out
+}
+
+// mappingKey is the graph key of a mapped record. It is derived from the
+// provider type and the record's key so re-syncs update the same object.
+func mappingKey(providerType, sourceType, key string) string {
+	return fmt.Sprintf("%s:%s:%s", providerType, sourceType, key)
+}
+
+// ------------------------------------------------------------------
+// Mapper
+// ------------------------------------------------------------------
+
+// MappedObject is a graph object planned (or written) for a record
+type MappedObject struct {
+	SourceType string         `json:"sourceType"`
+	RecordID   string         `json:"recordId"`
+	Type       string         `json:"type"`
+	Key        string         `json:"key"`
+	Properties map[string]any `json:"properties"`
+	Action     string         `json:"action"` // 'create', 'update' (dry run) or 'upsert'
+	ObjectID   string         `json:"objectId,omitempty"`
+}
+
+// MappedRelationship is a relationship planned (or written) between mapped objects
+type MappedRelationship struct {
+	Type     string `json:"type"`
+	SrcKey   string `json:"srcKey"`
+	DstKey   string `json:"dstKey"`
+	Resolved bool   `json:"resolved"` // false when the target object doesn't exist
+}
+
+// MappingResult describes what a mapping run created, or would create in a dry run
+type MappingResult struct {
+	DryRun          bool                 `json:"dryRun"`
+	Objects         []MappedObject       `json:"objects"`
+	Relationships   []MappedRelationship `json:"relationships"`
+	UnmappedRecords int                  `json:"unmappedRecords"`
+	Errors          []string             `json:"errors,omitempty"`
+}
+
+// mappingGraph is the graph access the mapper needs (implemented by graphWriter)
+type mappingGraph interface {
+	UpsertObject(ctx context.Context, projectID, objType, key string, properties map[string]any) (string, error)
+	FindObject(ctx context.Context, projectID string, types []string, key string) (string, error)
+	Relate(ctx context.Context, projectID, relType, srcID, dstID string) error
+}
+
+// Mapper applies an integration's mapping rules to provider records,
+// upserting graph objects directly instead of going through extraction
+type Mapper struct {
+	graph mappingGraph
+	log   *slog.Logger
+}
+
+// NewMapper creates a new mapper
+func NewMapper(graphService *graph.Service, log *slog.Logger) *Mapper {
+	return &Mapper{
+		graph: newGraphWriter(graphService),
+		log:   log.With(logger.Scope("datasource-mapper")),
+	}
+}
+
+// plannedRelationship is a relationship whose endpoints are identified by key
+type plannedRelationship struct {
+	relType string
+	srcType string
+	srcKey  string
+	dstType string
+	dstKey  string
+}
+
+// Apply maps records into the integration's project. With dryRun nothing is
+// written; objects are reported as 'create' or 'update' depending on whether
+// an object with the same key already exists. Per-record failures are
+// collected in the result; only cancellation aborts the run.
+func (m *Mapper) Apply(ctx context.Context, integration *DataSourceIntegration, rules MappingRules, records []Record, dryRun bool) (*MappingResult, error) {
+	result := &MappingResult{
+		DryRun:        dryRun,
+		Objects:       []MappedObject{},
+		Relationships: []MappedRelationship{},
+	}
+
+	// Plan objects; later records with the same key replace earlier ones
+	objectIndex := make(map[string]int)
+	var planned []plannedRelationship
+	for _, record := range records {
+		rule, ok := rules.find(record.SourceType)
+		if !ok {
+			result.UnmappedRecords++
+			continue
+		}
+		fields := normalizeFields(record.Fields)
+
+		keyValue := record.ID
+		if rule.KeyField != "" {
+			keys := fieldStrings(fields, rule.KeyField)
+			if len(keys) == 0 {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s %s: key field %q is empty", record.SourceType, record.ID, rule.KeyField))
+				continue
+			}
+			keyValue = keys[0]
+		}
+		if keyValue == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s record has no ID", record.SourceType))
+			continue
+		}
+
+		obj := MappedObject{
+			SourceType: record.SourceType,
+			RecordID:   record.ID,
+			Type:       rule.ObjectType,
+			Key:        mappingKey(integration.ProviderType, record.SourceType, keyValue),
+			Properties: make(map[string]any, len(rule.Properties)),
+			Action:     MappingActionUpsert,
+		}
+		for prop, path := range rule.Properties {
+			if value := lookupField(fields, path); value != nil {
+				obj.Properties[prop] = value
+			}
+		}
+
+		if i, ok := objectIndex[obj.Key]; ok {
+			result.Objects[i] = obj
+		} else {
+			objectIndex[obj.Key] = len(result.Objects)
+			result.Objects = append(result.Objects, obj)
+		}
+
+		for _, rel := range rule.Relationships {
+			target, _ := rules.find(rel.TargetSourceType)
+			for _, ref := range fieldStrings(fields, rel.Field) {
+				planned = append(planned, plannedRelationship{
+					relType: rel.Type,
+					srcType: rule.ObjectType,
+					srcKey:  obj.Key,
+					dstType: target.ObjectType,
+					dstKey:  mappingKey(integration.ProviderType, rel.TargetSourceType, ref),
+				})
+			}
+		}
+	}
+
+	// Write (or probe) objects
+	ids := make(map[string]string, len(result.Objects))
+	for i := range result.Objects {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		obj := &result.Objects[i]
+
+		if dryRun {
+			existing, err := m.graph.FindObject(ctx, integration.ProjectID, []string{obj.Type}, obj.Key)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", obj.Key, err.Error()))
+				continue
+			}
+			obj.Action = MappingActionCreate
+			if existing != "" {
+				obj.Action = MappingActionUpdate
+				obj.ObjectID = existing
+			}
+			ids[obj.Key] = obj.ObjectID
+			continue
+		}
+
+		id, err := m.graph.UpsertObject(ctx, integration.ProjectID, obj.Type, obj.Key, obj.Properties)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", obj.Key, err.Error()))
+			continue
+		}
+		obj.ObjectID = id
+		ids[obj.Key] = id
+	}
+
+	// Relationships. Targets outside this batch are looked up by key.
+	seen := make(map[string]bool)
+	for _, rel := range planned {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		dedupe := rel.relType + "|" + rel.srcKey + "|" + rel.dstKey
+		if seen[dedupe] {
+			continue
+		}
+		seen[dedupe] = true
+
+		srcID, ok := ids[rel.srcKey]
+		if !ok {
+			// Source object failed to write; its error is already recorded
+			continue
+		}
+		dstID, dstFound := ids[rel.dstKey]
+		if !dstFound {
+			found, err := m.graph.FindObject(ctx, integration.ProjectID, []string{rel.dstType}, rel.dstKey)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s -> %s: %s", rel.srcKey, rel.dstKey, err.Error()))
+				continue
+			}
+			dstID, dstFound = found, found != ""
+		}
+
+		if !dryRun && dstFound {
+			if err := m.graph.Relate(ctx, integration.ProjectID, rel.relType, srcID, dstID); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s -[%s]-> %s: %s", rel.srcKey, rel.relType, rel.dstKey, err.Error()))
+				continue
+			}
+		}
+		mapped := MappedRelationship{Type: rel.relType, SrcKey: rel.srcKey, DstKey: rel.dstKey, Resolved: dstFound}
+		result.Relationships = append(result.Relationships, mapped)
+	}
+
+	if !dryRun {
+		m.log.Info("applied mapping rules",
+			slog.String("integration_id", integration.ID),
+			slog.Int("objects", len(result.Objects)),
+			slog.Int("relationships", len(result.Relationships)),
+			slog.Int("unmapped", result.UnmappedRecords),
+			slog.Int("errors", len(result.Errors)))
+	}
+	return result, nil
+}
+
+// sampleRecords picks up to MappingSampleSize records for previews, keeping
+// every source type represented
+func sampleRecords(records []Record) Records {
+	if len(records) <= MappingSampleSize {
+		return append(Records{}, records...)
+	}
+
+	byType := make(map[string][]Record)
+	for _, r := range records {
+		byType[r.SourceType] = append(byType[r.SourceType], r)
+	}
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	// Round-robin across types
+	sample := make(Records, 0, MappingSampleSize)
+	for i := 0; len(sample) < MappingSampleSize; i++ {
+		added := false
+		for _, t := range types {
+			if i < len(byType[t]) && len(sample) < MappingSampleSize {
+				sample = append(sample, byType[t][i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return sample
+}
@@ -0,0 +1,195 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGraph is an in-memory mappingGraph keyed by object key
+type fakeGraph struct {
+	objects   map[string]map[string]any
+	relations []string
+	failKeys  map[string]bool
+}
+
+func newFakeGraph() *fakeGraph {
+	return &fakeGraph{objects: map[string]map[string]any{}, failKeys: map[string]bool{}}
+}
+
+func (g *fakeGraph) UpsertObject(_ context.Context, _, objType, key string, properties map[string]any) (string, error) {
+	if g.failKeys[key] {
+		return "", fmt.Errorf("write failed")
+	}
+	g.objects[key] = properties
+	return "id-" + key, nil
+}
+
+func (g *fakeGraph) FindObject(_ context.Context, _ string, _ []string, key string) (string, error) {
+	if _, ok := g.objects[key]; ok {
+		return "id-" + key, nil
+	}
+	return "", nil
+}
+
+func (g *fakeGraph) Relate(_ context.Context, _, relType, srcID, dstID string) error {
+	g.relations = append(g.relations, srcID+" -"+relType+"-> "+dstID)
+	return nil
+}
+
+var testRules = MappingRules{
+	{
+		SourceType: "task",
+		ObjectType: "Task",
+		Properties: map[string]string{
+			"title":    "name",
+			"priority": "customFields.Priority",
+			"assignee": "assignees.0.name",
+		},
+		Relationships: []RelationshipMapping{
+			{Type: "ASSIGNED_TO", Field: "assignees.id", TargetSourceType: "user"},
+			{Type: "SUBTASK_OF", Field: "parentId", TargetSourceType: "task"},
+		},
+	},
+	{SourceType: "user", ObjectType: "Person", KeyField: "email", Properties: map[string]string{"name": "name"}},
+}
+
+func testRecords() []Record {
+	return []Record{
+		{SourceType: "user", ID: "u1", Fields: map[string]any{"id": "u1", "name": "Ada", "email": "u1"}},
+		{SourceType: "task", ID: "t1", Fields: map[string]any{
+			"name":         "Ship it",
+			"customFields": map[string]any{"Priority": "high"},
+			"assignees":    []map[string]any{{"id": "u1", "name": "Ada"}, {"id": "u2", "name": "Grace"}},
+			"parentId":     "t0",
+		}},
+		{SourceType: "comment", ID: "c1", Fields: map[string]any{}},
+	}
+}
+
+func newTestMapper(g *fakeGraph) *Mapper {
+	return &Mapper{graph: g, log: testLogger()}
+}
+
+func TestMappingRules_Validate(t *testing.T) {
+	require.NoError(t, testRules.Validate())
+	require.NoError(t, MappingRules(nil).Validate())
+
+	tests := []struct {
+		name  string
+		rules MappingRules
+	}{
+		{name: "missing source type", rules: MappingRules{{ObjectType: "Task"}}},
+		{name: "missing object type", rules: MappingRules{{SourceType: "task"}}},
+		{name: "duplicate source type", rules: MappingRules{{SourceType: "task", ObjectType: "A"}, {SourceType: "task", ObjectType: "B"}}},
+		{name: "empty property path", rules: MappingRules{{SourceType: "task", ObjectType: "Task", Properties: map[string]string{"title": ""}}}},
+		{name: "unmapped relationship target", rules: MappingRules{{
+			SourceType: "task", ObjectType: "Task",
+			Relationships: []RelationshipMapping{{Type: "ASSIGNED_TO", Field: "assignee", TargetSourceType: "user"}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.rules.Validate())
+		})
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	fields := normalizeFields(map[string]any{
+		"name":      "Ship it",
+		"status":    map[string]any{"status": "open"},
+		"assignees": []map[string]any{{"id": 1, "tags": []string{"a", "b"}}, {"id": 2, "tags": []string{"c"}}},
+	})
+
+	assert.Equal(t, "Ship it", lookupField(fields, "name"))
+	assert.Equal(t, "open", lookupField(fields, "status.status"))
+	assert.Equal(t, float64(2), lookupField(fields, "assignees.1.id"))
+	assert.Equal(t, []any{float64(1), float64(2)}, lookupField(fields, "assignees.id"))
+	assert.Equal(t, []any{"a", "b", "c"}, lookupField(fields, "assignees.tags"))
+	assert.Nil(t, lookupField(fields, "assignees.5.id"))
+	assert.Nil(t, lookupField(fields, "missing.path"))
+
+	assert.Equal(t, []string{"1", "2"}, fieldStrings(fields, "assignees.id"))
+}
+
+func TestMapper_DryRun(t *testing.T) {
+	g := newFakeGraph()
+	g.objects["clickup:user:u1"] = map[string]any{}
+	integration := &DataSourceIntegration{ID: "i1", ProjectID: "p1", ProviderType: "clickup"}
+
+	result, err := newTestMapper(g).Apply(context.Background(), integration, testRules, testRecords(), true)
+	require.NoError(t, err)
+
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.UnmappedRecords)
+	require.Len(t, result.Objects, 2)
+
+	user, task := result.Objects[0], result.Objects[1]
+	assert.Equal(t, "clickup:user:u1", user.Key)
+	assert.Equal(t, MappingActionUpdate, user.Action)
+	assert.Equal(t, "clickup:task:t1", task.Key)
+	assert.Equal(t, MappingActionCreate, task.Action)
+	assert.Equal(t, map[string]any{"title": "Ship it", "priority": "high", "assignee": "Ada"}, task.Properties)
+
+	// u1 exists, u2 and the parent task don't
+	require.Len(t, result.Relationships, 3)
+	assert.True(t, result.Relationships[0].Resolved)
+	assert.False(t, result.Relationships[1].Resolved)
+	assert.False(t, result.Relationships[2].Resolved)
+
+	assert.Len(t, g.objects, 1, "dry run must not write objects")
+	assert.Empty(t, g.relations, "dry run must not write relationships")
+}
+
+func TestMapper_Apply(t *testing.T) {
+	g := newFakeGraph()
+	integration := &DataSourceIntegration{ID: "i1", ProjectID: "p1", ProviderType: "clickup"}
+
+	result, err := newTestMapper(g).Apply(context.Background(), integration, testRules, testRecords(), false)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Errors)
+	assert.Contains(t, g.objects, "clickup:task:t1")
+	assert.Contains(t, g.objects, "clickup:user:u1")
+	assert.Equal(t, []string{"id-clickup:task:t1 -ASSIGNED_TO-> id-clickup:user:u1"}, g.relations)
+}
+
+func TestMapper_CollectsErrors(t *testing.T) {
+	g := newFakeGraph()
+	g.failKeys["clickup:task:t1"] = true
+	integration := &DataSourceIntegration{ID: "i1", ProjectID: "p1", ProviderType: "clickup"}
+
+	records := append(testRecords(), Record{SourceType: "user", ID: "u3", Fields: map[string]any{"name": "No email"}})
+	result, err := newTestMapper(g).Apply(context.Background(), integration, testRules, records, false)
+	require.NoError(t, err)
+
+	require.Len(t, result.Errors, 2)
+	assert.Contains(t, result.Errors[0], `key field "email" is empty`)
+	assert.Contains(t, result.Errors[1], "write failed")
+	assert.Empty(t, result.Relationships, "relationships of failed objects are skipped")
+}
+
+func TestSampleRecords(t *testing.T) {
+	var records []Record
+	for i := 0; i < 30; i++ {
+		records = append(records, Record{SourceType: "task", ID: fmt.Sprint(i)})
+	}
+	records = append(records, Record{SourceType: "user", ID: "u1"})
+
+	sample := sampleRecords(records)
+	assert.Len(t, sample, MappingSampleSize)
+	assert.Equal(t, "u1", sample[1].ID, "every source type is represented")
+
+	assert.Len(t, sampleRecords(records[:3]), 3)
+}
+
+// testLogger discards output so test runs do not write log files.
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
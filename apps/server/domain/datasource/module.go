@@ -3,12 +3,18 @@ package datasource
 import (
 	"context"
 	"log/slog"
+	"net/http"
 
 	"github.com/uptrace/bun"
 	"go.uber.org/fx"
 
 	"github.com/emergent-company/emergent.memory/domain/datasource/providers/clickup"
+	"github.com/emergent-company/emergent.memory/domain/datasource/providers/github"
+	"github.com/emergent-company/emergent.memory/domain/datasource/providers/slack"
+	"github.com/emergent-company/emergent.memory/domain/githubapp"
+	"github.com/emergent-company/emergent.memory/domain/graph"
 	"github.com/emergent-company/emergent.memory/internal/config"
+	"github.com/emergent-company/emergent.memory/internal/storage"
 	"github.com/emergent-company/emergent.memory/pkg/encryption"
 )
 
@@ -20,6 +26,7 @@ var Module = fx.Module("datasource",
 		NewJobsService,
 		NewProviderRegistry,
 		encryption.NewService,
+		NewMapper,
 		NewWorker,
 		NewHandler,
 	),
@@ -59,6 +66,7 @@ func (a *clickupAdapter) TestConnection(ctx context.Context, config ProviderConf
 		ProjectID:     config.ProjectID,
 		Config:        config.Config,
 		Metadata:      config.Metadata,
+		LastSyncedAt:  config.LastSyncedAt,
 	}
 	return a.provider.TestConnection(ctx, clickupConfig)
 }
@@ -70,12 +78,15 @@ func (a *clickupAdapter) Sync(ctx context.Context, config ProviderConfig, option
 		ProjectID:     config.ProjectID,
 		Config:        config.Config,
 		Metadata:      config.Metadata,
+		LastSyncedAt:  config.LastSyncedAt,
 	}
 	clickupOptions := clickup.SyncOptions{
 		Limit:           options.Limit,
 		FullSync:        options.FullSync,
 		ConfigurationID: options.ConfigurationID,
 		Custom:          options.Custom,
+		ItemIDs:         options.ItemIDs,
+		DeletedItemIDs:  options.DeletedItemIDs,
 	}
 
 	// Wrap the progress callback
@@ -100,6 +111,203 @@ func (a *clickupAdapter) Sync(ctx context.Context, config ProviderConfig, option
 	}
 
 	// Convert result
+	records := make([]Record, 0, len(result.Records))
+	for _, r := range result.Records {
+		records = append(records, Record{SourceType: r.SourceType, ID: r.ID, Fields: r.Fields})
+	}
+	return &SyncResult{
+		TotalItems:      result.TotalItems,
+		ProcessedItems:  result.ProcessedItems,
+		SuccessfulItems: result.SuccessfulItems,
+		FailedItems:     result.FailedItems,
+		SkippedItems:    result.SkippedItems,
+		DocumentIDs:     result.DocumentIDs,
+		Errors:          result.Errors,
+		Records:         records,
+		Cursor:          result.Cursor,
+	}, nil
+}
+
+func (a *clickupAdapter) VerifyWebhook(secret string, headers http.Header, body []byte) error {
+	return a.provider.VerifyWebhook(secret, headers, body)
+}
+
+func (a *clickupAdapter) ParseWebhook(headers http.Header, body []byte) (*WebhookDelivery, error) {
+	payload, err := a.provider.ParseWebhook(headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &WebhookDelivery{
+		DeliveryID: payload.DeliveryID,
+		EventType:  payload.EventType,
+	}
+	for _, id := range payload.UpsertedIDs {
+		delivery.Events = append(delivery.Events, WebhookEvent{ItemID: id, Action: WebhookActionUpsert})
+	}
+	for _, id := range payload.DeletedIDs {
+		delivery.Events = append(delivery.Events, WebhookEvent{ItemID: id, Action: WebhookActionDelete})
+	}
+	return delivery, nil
+}
+
+// githubAdapter wraps the github.Provider to implement datasource.Provider
+type githubAdapter struct {
+	provider *github.Provider
+}
+
+func (a *githubAdapter) ProviderType() string {
+	return a.provider.ProviderType()
+}
+
+func (a *githubAdapter) TestConnection(ctx context.Context, config ProviderConfig) error {
+	return a.provider.TestConnection(ctx, github.ProviderConfig{
+		IntegrationID: config.IntegrationID,
+		ProjectID:     config.ProjectID,
+		Config:        config.Config,
+		Metadata:      config.Metadata,
+		LastSyncedAt:  config.LastSyncedAt,
+	})
+}
+
+func (a *githubAdapter) Sync(ctx context.Context, config ProviderConfig, options SyncOptions, progress ProgressCallback) (*SyncResult, error) {
+	githubConfig := github.ProviderConfig{
+		IntegrationID: config.IntegrationID,
+		ProjectID:     config.ProjectID,
+		Config:        config.Config,
+		Metadata:      config.Metadata,
+		LastSyncedAt:  config.LastSyncedAt,
+	}
+	githubOptions := github.SyncOptions{
+		Limit:           options.Limit,
+		FullSync:        options.FullSync,
+		ConfigurationID: options.ConfigurationID,
+		Custom:          options.Custom,
+		ItemIDs:         options.ItemIDs,
+		DeletedItemIDs:  options.DeletedItemIDs,
+	}
+
+	var githubProgress github.ProgressCallback
+	if progress != nil {
+		githubProgress = func(p github.Progress) {
+			progress(Progress{
+				Phase:           p.Phase,
+				TotalItems:      p.TotalItems,
+				ProcessedItems:  p.ProcessedItems,
+				SuccessfulItems: p.SuccessfulItems,
+				FailedItems:     p.FailedItems,
+				SkippedItems:    p.SkippedItems,
+				Message:         p.Message,
+			})
+		}
+	}
+
+	result, err := a.provider.Sync(ctx, githubConfig, githubOptions, githubProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyncResult{
+		TotalItems:      result.TotalItems,
+		ProcessedItems:  result.ProcessedItems,
+		SuccessfulItems: result.SuccessfulItems,
+		FailedItems:     result.FailedItems,
+		SkippedItems:    result.SkippedItems,
+		DocumentIDs:     result.DocumentIDs,
+		Errors:          result.Errors,
+		Cursor:          result.Cursor,
+	}, nil
+}
+
+func (a *githubAdapter) VerifyWebhook(secret string, headers http.Header, body []byte) error {
+	return a.provider.VerifyWebhook(secret, headers, body)
+}
+
+func (a *githubAdapter) ParseWebhook(headers http.Header, body []byte) (*WebhookDelivery, error) {
+	payload, err := a.provider.ParseWebhook(headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &WebhookDelivery{
+		DeliveryID: payload.DeliveryID,
+		EventType:  payload.EventType,
+	}
+	for _, id := range payload.UpsertedIDs {
+		delivery.Events = append(delivery.Events, WebhookEvent{ItemID: id, Action: WebhookActionUpsert})
+	}
+	for _, id := range payload.DeletedIDs {
+		delivery.Events = append(delivery.Events, WebhookEvent{ItemID: id, Action: WebhookActionDelete})
+	}
+	return delivery, nil
+}
+
+// slackAdapter wraps the slack.Provider to implement datasource.Provider,
+// datasource.ExportProvider and datasource.WebhookProvider
+type slackAdapter struct {
+	provider *slack.Provider
+}
+
+func (a *slackAdapter) ProviderType() string {
+	return a.provider.ProviderType()
+}
+
+func (a *slackAdapter) TestConnection(ctx context.Context, config ProviderConfig) error {
+	return a.provider.TestConnection(ctx, slack.ProviderConfig{
+		IntegrationID: config.IntegrationID,
+		ProjectID:     config.ProjectID,
+		Config:        config.Config,
+		Metadata:      config.Metadata,
+		LastSyncedAt:  config.LastSyncedAt,
+	})
+}
+
+func (a *slackAdapter) AcceptsExports(config map[string]interface{}) bool {
+	return a.provider.AcceptsExports(config)
+}
+
+func (a *slackAdapter) Sync(ctx context.Context, config ProviderConfig, options SyncOptions, progress ProgressCallback) (*SyncResult, error) {
+	exportKey, err := exportStorageKey(options.Custom, config.ProjectID, config.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	slackConfig := slack.ProviderConfig{
+		IntegrationID: config.IntegrationID,
+		ProjectID:     config.ProjectID,
+		Config:        config.Config,
+		Metadata:      config.Metadata,
+		LastSyncedAt:  config.LastSyncedAt,
+	}
+	slackOptions := slack.SyncOptions{
+		Limit:            options.Limit,
+		FullSync:         options.FullSync,
+		ConfigurationID:  options.ConfigurationID,
+		Custom:           options.Custom,
+		ExportStorageKey: exportKey,
+		ItemIDs:          options.ItemIDs,
+	}
+
+	var slackProgress slack.ProgressCallback
+	if progress != nil {
+		slackProgress = func(p slack.Progress) {
+			progress(Progress{
+				Phase:           p.Phase,
+				TotalItems:      p.TotalItems,
+				ProcessedItems:  p.ProcessedItems,
+				SuccessfulItems: p.SuccessfulItems,
+				FailedItems:     p.FailedItems,
+				SkippedItems:    p.SkippedItems,
+				Message:         p.Message,
+			})
+		}
+	}
+
+	result, err := a.provider.Sync(ctx, slackConfig, slackOptions, slackProgress)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SyncResult{
 		TotalItems:      result.TotalItems,
 		ProcessedItems:  result.ProcessedItems,
@@ -108,15 +316,59 @@ func (a *clickupAdapter) Sync(ctx context.Context, config ProviderConfig, option
 		SkippedItems:    result.SkippedItems,
 		DocumentIDs:     result.DocumentIDs,
 		Errors:          result.Errors,
+		Cursor:          result.Cursor,
 	}, nil
 }
 
+func (a *slackAdapter) VerifyWebhook(secret string, headers http.Header, body []byte) error {
+	return a.provider.VerifyWebhook(secret, headers, body)
+}
+
+func (a *slackAdapter) ParseWebhook(headers http.Header, body []byte) (*WebhookDelivery, error) {
+	payload, err := a.provider.ParseWebhook(headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &WebhookDelivery{
+		DeliveryID: payload.DeliveryID,
+		EventType:  payload.EventType,
+		Challenge:  payload.Challenge,
+	}
+	for _, id := range payload.UpsertedIDs {
+		delivery.Events = append(delivery.Events, WebhookEvent{ItemID: id, Action: WebhookActionUpsert})
+	}
+	return delivery, nil
+}
+
 // RegisterProviders registers all available data source providers
-func RegisterProviders(registry *ProviderRegistry, db *bun.DB, log *slog.Logger) {
+func RegisterProviders(registry *ProviderRegistry, db *bun.DB, githubApp *githubapp.Service, graphService *graph.Service, storageSvc *storage.Service, log *slog.Logger) {
 	// Register ClickUp provider (fully implemented)
 	clickupProvider := clickup.NewProvider(db, log)
 	registry.Register(&clickupAdapter{provider: clickupProvider})
 
+	// Register GitHub provider (authenticates with the GitHub App installation
+	// token). Absent services stay nil interfaces so the provider's nil checks work.
+	var tokens github.TokenSource
+	if githubApp != nil {
+		tokens = githubApp
+	}
+	var graphWriter github.GraphWriter
+	if graphService != nil {
+		graphWriter = newGraphWriter(graphService)
+	}
+	githubProvider := github.NewProvider(db, tokens, graphWriter, log)
+	registry.Register(&githubAdapter{provider: githubProvider})
+
+	// Register Slack provider (live Web API sync, or uploaded Slack/Teams exports
+	// when object storage is configured)
+	var exports slack.ExportSource
+	if storageSvc != nil && storageSvc.Enabled() {
+		exports = storageSvc
+	}
+	slackProvider := slack.NewProvider(db, exports, log)
+	registry.Register(&slackAdapter{provider: slackProvider})
+
 	// Register placeholder providers for other integrations
 	// These will be implemented later
 	registry.Register(NewNoOpProvider("imap"))
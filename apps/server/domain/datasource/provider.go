@@ -3,6 +3,7 @@ package datasource
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 // Provider is the interface that data source providers must implement.
@@ -25,6 +26,10 @@ type ProviderConfig struct {
 	ProjectID     string
 	Config        map[string]interface{}
 	Metadata      map[string]interface{}
+
+	// LastSyncedAt is when the integration last synced successfully (nil = never).
+	// Providers use it as the cursor for incremental sync.
+	LastSyncedAt *time.Time
 }
 
 // SyncOptions contains options for a sync operation
@@ -38,6 +43,13 @@ type SyncOptions struct {
 	// ConfigurationID is the specific sync configuration to use
 	ConfigurationID string
 
+	// ItemIDs restricts the sync to specific provider items (webhook-triggered syncs).
+	// When ItemIDs or DeletedItemIDs is set, providers skip discovery entirely.
+	ItemIDs []string
+
+	// DeletedItemIDs are provider items that were deleted at the source
+	DeletedItemIDs []string
+
 	// Custom options from the sync job
 	Custom map[string]interface{}
 }
@@ -51,6 +63,14 @@ type SyncResult struct {
 	SkippedItems    int
 	DocumentIDs     []string
 	Errors          []string
+
+	// Records are structured items for the integration's mapping rules
+	// (optional; providers without structured data leave it empty)
+	Records []Record
+
+	// Cursor replaces the sync start time as the incremental sync cursor when
+	// a provider could not process everything (zero = keep the previous cursor)
+	Cursor *time.Time
 }
 
 // ProgressCallback is called by providers to report sync progress
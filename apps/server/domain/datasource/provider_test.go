@@ -2,6 +2,9 @@ package datasource
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"strings"
 	"testing"
 )
 
@@ -415,3 +418,22 @@ func TestProgress(t *testing.T) {
 		}
 	})
 }
+
+func TestRegisterProviders_WithoutGitHubApp(t *testing.T) {
+	registry := NewProviderRegistry()
+	RegisterProviders(registry, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	provider, ok := registry.Get("github")
+	if !ok {
+		t.Fatal("github provider not registered")
+	}
+
+	// Without the GitHub App and a personal token the provider must report
+	// the missing token rather than call through a nil service
+	err := provider.TestConnection(context.Background(), ProviderConfig{
+		Config: map[string]interface{}{"repository": "acme/widgets"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no access token configured") {
+		t.Errorf("TestConnection() error = %v, want missing token error", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -29,6 +30,10 @@ type Client struct {
 	httpClient  *http.Client
 	rateLimiter *RateLimiter
 	log         *slog.Logger
+
+	// v2URL and v3URL are the API base URLs (overridable for tests)
+	v2URL string
+	v3URL string
 }
 
 // NewClient creates a new ClickUp API client
@@ -39,6 +44,8 @@ func NewClient(log *slog.Logger) *Client {
 		},
 		rateLimiter: NewRateLimiter(defaultMaxRequests, defaultWindowMs),
 		log:         log.With(logger.Scope("clickup-client")),
+		v2URL:       baseURLV2,
+		v3URL:       baseURLV3,
 	}
 }
 
@@ -156,7 +163,7 @@ func (c *Client) request(ctx context.Context, apiToken, method, urlStr string) (
 
 // GetWorkspaces retrieves all workspaces (teams) the user has access to
 func (c *Client) GetWorkspaces(ctx context.Context, apiToken string) (*WorkspacesResponse, error) {
-	urlStr := fmt.Sprintf("%s/team", baseURLV2)
+	urlStr := fmt.Sprintf("%s/team", c.v2URL)
 
 	body, err := c.request(ctx, apiToken, http.MethodGet, urlStr)
 	if err != nil {
@@ -174,7 +181,7 @@ func (c *Client) GetWorkspaces(ctx context.Context, apiToken string) (*Workspace
 
 // GetSpaces retrieves all spaces in a workspace
 func (c *Client) GetSpaces(ctx context.Context, apiToken, workspaceID string, archived bool) (*SpacesResponse, error) {
-	u, _ := url.Parse(fmt.Sprintf("%s/team/%s/space", baseURLV2, workspaceID))
+	u, _ := url.Parse(fmt.Sprintf("%s/team/%s/space", c.v2URL, workspaceID))
 	if archived {
 		q := u.Query()
 		q.Set("archived", "true")
@@ -195,13 +202,151 @@ func (c *Client) GetSpaces(ctx context.Context, apiToken, workspaceID string, ar
 	return &response, nil
 }
 
+// GetFolders retrieves all folders (with their lists) in a space
+func (c *Client) GetFolders(ctx context.Context, apiToken, spaceID string, archived bool) (*FoldersResponse, error) {
+	u, _ := url.Parse(fmt.Sprintf("%s/space/%s/folder", c.v2URL, spaceID))
+	if archived {
+		q := u.Query()
+		q.Set("archived", "true")
+		u.RawQuery = q.Encode()
+	}
+
+	body, err := c.request(ctx, apiToken, http.MethodGet, u.String())
+	if err != nil {
+		c.log.Error("failed to get folders", logger.Error(err), slog.String("space_id", spaceID))
+		return nil, fmt.Errorf("get folders: %w", err)
+	}
+
+	var response FoldersResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parse folders response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetFolderlessLists retrieves lists that live directly in a space (outside any folder)
+func (c *Client) GetFolderlessLists(ctx context.Context, apiToken, spaceID string, archived bool) (*ListsResponse, error) {
+	u, _ := url.Parse(fmt.Sprintf("%s/space/%s/list", c.v2URL, spaceID))
+	if archived {
+		q := u.Query()
+		q.Set("archived", "true")
+		u.RawQuery = q.Encode()
+	}
+
+	body, err := c.request(ctx, apiToken, http.MethodGet, u.String())
+	if err != nil {
+		c.log.Error("failed to get folderless lists", logger.Error(err), slog.String("space_id", spaceID))
+		return nil, fmt.Errorf("get folderless lists: %w", err)
+	}
+
+	var response ListsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parse lists response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// TaskQuery contains the filters for listing tasks in a list
+type TaskQuery struct {
+	// Page is the zero-based page number (ClickUp returns up to 100 tasks per page)
+	Page int
+
+	// DateUpdatedGt only returns tasks updated after this Unix ms timestamp (0 = no filter)
+	DateUpdatedGt int64
+
+	// Subtasks includes subtasks in the result
+	Subtasks bool
+
+	// IncludeClosed includes tasks in closed statuses
+	IncludeClosed bool
+
+	// Archived returns archived tasks instead of active ones
+	Archived bool
+}
+
+// GetTasks retrieves one page of tasks from a list
+func (c *Client) GetTasks(ctx context.Context, apiToken, listID string, query TaskQuery) (*TasksResponse, error) {
+	u, _ := url.Parse(fmt.Sprintf("%s/list/%s/task", c.v2URL, listID))
+	q := u.Query()
+	q.Set("page", strconv.Itoa(query.Page))
+	q.Set("include_markdown_description", "true")
+	if query.DateUpdatedGt > 0 {
+		q.Set("date_updated_gt", strconv.FormatInt(query.DateUpdatedGt, 10))
+	}
+	if query.Subtasks {
+		q.Set("subtasks", "true")
+	}
+	if query.IncludeClosed {
+		q.Set("include_closed", "true")
+	}
+	if query.Archived {
+		q.Set("archived", "true")
+	}
+	u.RawQuery = q.Encode()
+
+	body, err := c.request(ctx, apiToken, http.MethodGet, u.String())
+	if err != nil {
+		c.log.Error("failed to get tasks", logger.Error(err), slog.String("list_id", listID))
+		return nil, fmt.Errorf("get tasks: %w", err)
+	}
+
+	var response TasksResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parse tasks response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetTask retrieves a single task by ID, including its subtasks
+func (c *Client) GetTask(ctx context.Context, apiToken, taskID string) (*Task, error) {
+	u, _ := url.Parse(fmt.Sprintf("%s/task/%s", c.v2URL, taskID))
+	q := u.Query()
+	q.Set("include_subtasks", "true")
+	q.Set("include_markdown_description", "true")
+	u.RawQuery = q.Encode()
+
+	body, err := c.request(ctx, apiToken, http.MethodGet, u.String())
+	if err != nil {
+		c.log.Error("failed to get task", logger.Error(err), slog.String("task_id", taskID))
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+
+	var task Task
+	if err := json.Unmarshal(body, &task); err != nil {
+		return nil, fmt.Errorf("parse task response: %w", err)
+	}
+
+	return &task, nil
+}
+
+// GetTaskComments retrieves the comments on a task
+func (c *Client) GetTaskComments(ctx context.Context, apiToken, taskID string) (*CommentsResponse, error) {
+	urlStr := fmt.Sprintf("%s/task/%s/comment", c.v2URL, taskID)
+
+	body, err := c.request(ctx, apiToken, http.MethodGet, urlStr)
+	if err != nil {
+		c.log.Error("failed to get task comments", logger.Error(err), slog.String("task_id", taskID))
+		return nil, fmt.Errorf("get task comments: %w", err)
+	}
+
+	var response CommentsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parse comments response: %w", err)
+	}
+
+	return &response, nil
+}
+
 // ----------------------------------------------------------------------------
 // ClickUp API v3 Methods (Docs)
 // ----------------------------------------------------------------------------
 
 // GetDocs retrieves all docs in a workspace, optionally filtered by parent
 func (c *Client) GetDocs(ctx context.Context, apiToken, workspaceID string, cursor, parentID, parentType string) (*DocsResponse, error) {
-	u, _ := url.Parse(fmt.Sprintf("%s/workspaces/%s/docs", baseURLV3, workspaceID))
+	u, _ := url.Parse(fmt.Sprintf("%s/workspaces/%s/docs", c.v3URL, workspaceID))
 	q := u.Query()
 	if cursor != "" {
 		q.Set("cursor", cursor)
@@ -230,7 +375,7 @@ func (c *Client) GetDocs(ctx context.Context, apiToken, workspaceID string, curs
 
 // GetDoc retrieves a specific doc by ID
 func (c *Client) GetDoc(ctx context.Context, apiToken, workspaceID, docID string) (*Doc, error) {
-	urlStr := fmt.Sprintf("%s/workspaces/%s/docs/%s", baseURLV3, workspaceID, docID)
+	urlStr := fmt.Sprintf("%s/workspaces/%s/docs/%s", c.v3URL, workspaceID, docID)
 
 	body, err := c.request(ctx, apiToken, http.MethodGet, urlStr)
 	if err != nil {
@@ -248,7 +393,7 @@ func (c *Client) GetDoc(ctx context.Context, apiToken, workspaceID, docID string
 
 // GetDocPages retrieves all pages for a doc
 func (c *Client) GetDocPages(ctx context.Context, apiToken, workspaceID, docID string) ([]Page, error) {
-	urlStr := fmt.Sprintf("%s/workspaces/%s/docs/%s/pages", baseURLV3, workspaceID, docID)
+	urlStr := fmt.Sprintf("%s/workspaces/%s/docs/%s/pages", c.v3URL, workspaceID, docID)
 
 	body, err := c.request(ctx, apiToken, http.MethodGet, urlStr)
 	if err != nil {
@@ -266,7 +411,7 @@ func (c *Client) GetDocPages(ctx context.Context, apiToken, workspaceID, docID s
 
 // GetPage retrieves a specific page from a doc
 func (c *Client) GetPage(ctx context.Context, apiToken, workspaceID, docID, pageID string) (*Page, error) {
-	urlStr := fmt.Sprintf("%s/workspaces/%s/docs/%s/pages/%s", baseURLV3, workspaceID, docID, pageID)
+	urlStr := fmt.Sprintf("%s/workspaces/%s/docs/%s/pages/%s", c.v3URL, workspaceID, docID, pageID)
 
 	body, err := c.request(ctx, apiToken, http.MethodGet, urlStr)
 	if err != nil {
@@ -17,8 +17,12 @@ import (
 )
 
 const (
-	ProviderTypeClickUp = "clickup"
-	SourceTypeClickUp   = "clickup-document"
+	ProviderTypeClickUp   = "clickup"
+	SourceTypeClickUp     = "clickup-document"
+	SourceTypeClickUpTask = "clickup-task"
+
+	// RecordTypeTask is the record type of tasks for field-to-graph mapping
+	RecordTypeTask = "task"
 )
 
 // ProviderConfig contains the decrypted configuration for a provider
@@ -28,6 +32,7 @@ type ProviderConfig struct {
 	ProjectID     string
 	Config        map[string]interface{}
 	Metadata      map[string]interface{}
+	LastSyncedAt  *time.Time
 }
 
 // SyncOptions contains options for a sync operation
@@ -37,6 +42,8 @@ type SyncOptions struct {
 	FullSync        bool
 	ConfigurationID string
 	Custom          map[string]interface{}
+	ItemIDs         []string
+	DeletedItemIDs  []string
 }
 
 // SyncResult contains the results of a sync operation
@@ -49,6 +56,21 @@ type SyncResult struct {
 	SkippedItems    int
 	DocumentIDs     []string
 	Errors          []string
+
+	// Records are structured task records for field-to-graph mapping
+	Records []Record
+
+	// Cursor is where the next incremental sync resumes (nil = the sync start
+	// time, zero = keep the previous cursor)
+	Cursor *time.Time
+}
+
+// Record is a structured item for field-to-graph mapping
+// Mirrors datasource.Record to avoid import cycle
+type Record struct {
+	SourceType string
+	ID         string
+	Fields     map[string]any
 }
 
 // Progress represents the current progress of a sync operation
@@ -66,7 +88,7 @@ type Progress struct {
 // ProgressCallback is called by providers to report sync progress
 type ProgressCallback func(progress Progress)
 
-// Provider implements the ClickUp data source provider for ClickUp Docs.
+// Provider implements the ClickUp data source provider for ClickUp Docs and Tasks.
 type Provider struct {
 	client  *Client
 	db      bun.IDB
@@ -127,6 +149,12 @@ func (p *Provider) Sync(ctx context.Context, config ProviderConfig, options Sync
 		return result, fmt.Errorf("workspace ID not configured")
 	}
 
+	// Webhook-triggered syncs only touch the items named in the delivery
+	if len(options.ItemIDs) > 0 || len(options.DeletedItemIDs) > 0 {
+		err := p.syncTargetedTasks(ctx, clickupConfig, config.ProjectID, config.IntegrationID, options.ItemIDs, options.DeletedItemIDs, result)
+		return result, err
+	}
+
 	// Report starting phase
 	if progressCB != nil {
 		progressCB(Progress{
@@ -223,11 +251,25 @@ func (p *Provider) Sync(ctx context.Context, config ProviderConfig, options Sync
 		}
 	}
 
+	// Import tasks from the same spaces
+	if clickupConfig.TasksEnabled() {
+		remaining := 0
+		if options.Limit > 0 {
+			remaining = options.Limit - result.ProcessedItems
+		}
+		if options.Limit == 0 || remaining > 0 {
+			sinceMs := p.syncSince(clickupConfig, config, options)
+			if err := p.syncTasks(ctx, clickupConfig, spaceIDs, config.ProjectID, config.IntegrationID, sinceMs, remaining, result, progressCB); err != nil {
+				return result, err
+			}
+		}
+	}
+
 	// Report completion
 	if progressCB != nil {
 		progressCB(Progress{
 			Phase:           "completed",
-			TotalItems:      len(allDocs),
+			TotalItems:      result.TotalItems,
 			ProcessedItems:  result.ProcessedItems,
 			SuccessfulItems: result.SuccessfulItems,
 			FailedItems:     result.FailedItems,
@@ -315,6 +357,21 @@ func (p *Provider) getDocsFromSpace(ctx context.Context, config *Config, spaceID
 	return allDocs, nil
 }
 
+// syncSince returns the Unix ms timestamp to sync task changes from (0 = everything).
+// An explicit lastSyncedAt in the config wins over the integration's last sync time.
+func (p *Provider) syncSince(clickupConfig *Config, config ProviderConfig, options SyncOptions) int64 {
+	if options.FullSync {
+		return 0
+	}
+	if clickupConfig.LastSyncedAt > 0 {
+		return clickupConfig.LastSyncedAt
+	}
+	if config.LastSyncedAt != nil {
+		return config.LastSyncedAt.UnixMilli()
+	}
+	return 0
+}
+
 // filterByUpdatedSince filters docs to those updated after the given timestamp
 func (p *Provider) filterByUpdatedSince(docs []Doc, sinceMs int64) []Doc {
 	var filtered []Doc
@@ -343,7 +400,7 @@ func (p *Provider) importDoc(ctx context.Context, config *Config, doc Doc, proje
 	}
 
 	// Check for existing document by clickupDocId
-	existing, err := p.findExistingDoc(ctx, projectID, integrationID, doc.ID)
+	existing, err := p.findExisting(ctx, projectID, integrationID, "clickupDocId", doc.ID)
 	if err != nil {
 		return "", false, err
 	}
@@ -371,14 +428,15 @@ func (p *Provider) importDoc(ctx context.Context, config *Config, doc Doc, proje
 	return docID, false, nil
 }
 
-// findExistingDoc finds an existing document by clickupDocId
-func (p *Provider) findExistingDoc(ctx context.Context, projectID, integrationID, clickupDocID string) (*documents.Document, error) {
+// findExisting finds an existing document by a ClickUp ID stored in its metadata
+// (metadataKey is "clickupDocId" or "clickupTaskId")
+func (p *Provider) findExisting(ctx context.Context, projectID, integrationID, metadataKey, clickupID string) (*documents.Document, error) {
 	var doc documents.Document
 	err := p.db.NewSelect().
 		Model(&doc).
 		Where("project_id = ?", projectID).
 		Where("data_source_integration_id = ?", integrationID).
-		Where("metadata->>'clickupDocId' = ?", clickupDocID).
+		Where("metadata->>? = ?", metadataKey, clickupID).
 		Scan(ctx)
 
 	if err != nil {
@@ -3,6 +3,8 @@ package clickup
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,8 +12,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
-	"github.com/emergent-company/emergent.memory/pkg/logger"
 )
 
 func TestRateLimiter(t *testing.T) {
@@ -100,7 +100,7 @@ func TestRateLimiter(t *testing.T) {
 }
 
 func TestClient(t *testing.T) {
-	log := logger.NewLogger()
+	log := testLogger()
 
 	t.Run("GetWorkspaces success", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -261,7 +261,7 @@ func TestTypes(t *testing.T) {
 }
 
 func TestProviderHelpers(t *testing.T) {
-	log := logger.NewLogger()
+	log := testLogger()
 
 	t.Run("parseConfig validates API token", func(t *testing.T) {
 		// Note: This test would need actual database access for full provider testing
@@ -439,7 +439,7 @@ func TestConfigSchema(t *testing.T) {
 }
 
 func TestProvider_ParseConfig(t *testing.T) {
-	log := logger.NewLogger()
+	log := testLogger()
 	p := &Provider{
 		log: log,
 	}
@@ -517,7 +517,7 @@ func TestProvider_ParseConfig(t *testing.T) {
 }
 
 func TestProvider_ProviderType(t *testing.T) {
-	log := logger.NewLogger()
+	log := testLogger()
 	p := &Provider{
 		log: log,
 	}
@@ -528,7 +528,7 @@ func TestProvider_ProviderType(t *testing.T) {
 }
 
 func TestClient_ResetRateLimiter(t *testing.T) {
-	log := logger.NewLogger()
+	log := testLogger()
 	client := NewClient(log)
 
 	// Fill up the rate limiter
@@ -547,3 +547,8 @@ func TestClient_ResetRateLimiter(t *testing.T) {
 	err := client.rateLimiter.WaitForSlot(ctx)
 	assert.NoError(t, err)
 }
+
+// testLogger discards output so test runs do not write log files.
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
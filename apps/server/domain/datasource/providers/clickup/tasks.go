@@ -0,0 +1,566 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/emergent-company/emergent.memory/domain/documents"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// maxTaskPages caps pagination per list to protect against runaway loops
+const maxTaskPages = 1000
+
+// syncTasks imports tasks from all lists in the configured spaces, oldest
+// change first. Counts are accumulated into result; limit (0 = no limit) caps
+// the number of tasks imported. When lists could not be read, tasks failed or
+// the limit cut the sync short, result.Cursor is set to the last task fully
+// processed so the rest are fetched again next time.
+func (p *Provider) syncTasks(ctx context.Context, config *Config, spaceIDs []string, projectID, integrationID string, sinceMs int64, limit int, result *SyncResult, progressCB ProgressCallback) error {
+	if progressCB != nil {
+		progressCB(Progress{
+			Phase:   "discovering",
+			Message: "Discovering ClickUp tasks...",
+		})
+	}
+
+	// complete stays true while every changed task is fetched and imported
+	complete := true
+
+	var lists []List
+	for _, spaceID := range spaceIDs {
+		spaceLists, err := p.getListsFromSpace(ctx, config, spaceID)
+		if err != nil {
+			p.log.Warn("failed to get lists from space",
+				logger.Error(err),
+				slog.String("space_id", spaceID))
+			complete = false
+			continue
+		}
+		lists = append(lists, spaceLists...)
+	}
+
+	// Tasks can live in multiple lists, so dedupe by ID
+	seen := make(map[string]bool)
+	var tasks []Task
+	for _, list := range lists {
+		listTasks, err := p.getTasksFromList(ctx, config, list.ID, sinceMs)
+		if err != nil {
+			p.log.Warn("failed to get tasks from list",
+				logger.Error(err),
+				slog.String("list_id", list.ID))
+			complete = false
+			continue
+		}
+		for _, task := range listTasks {
+			if seen[task.ID] {
+				continue
+			}
+			seen[task.ID] = true
+			if task.List.Name == "" {
+				task.List.Name = list.Name
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	p.log.Info("discovered tasks to sync",
+		slog.Int("list_count", len(lists)),
+		slog.Int("task_count", len(tasks)),
+		slog.Int64("since", sinceMs))
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return taskUpdatedMs(tasks[i]) < taskUpdatedMs(tasks[j])
+	})
+	truncated := limit > 0 && len(tasks) > limit
+	if truncated {
+		tasks = tasks[:limit]
+	}
+
+	result.TotalItems += len(tasks)
+
+	if progressCB != nil {
+		progressCB(Progress{
+			Phase:      "importing",
+			TotalItems: result.TotalItems,
+			Message:    fmt.Sprintf("Importing %d tasks...", len(tasks)),
+		})
+	}
+
+	// lastMs is the update time of the last task in the unbroken run of
+	// imported tasks. Missing lists leave nothing safe to advance past.
+	lastMs := sinceMs
+	advancing := complete
+	for i, task := range tasks {
+		select {
+		case <-ctx.Done():
+			result.Errors = append(result.Errors, "sync cancelled")
+			return ctx.Err()
+		default:
+		}
+
+		docID, skipped, err := p.importTask(ctx, config, task, projectID, integrationID)
+		result.ProcessedItems++
+
+		if err != nil {
+			complete = false
+			advancing = false
+		} else if advancing {
+			lastMs = max(lastMs, taskUpdatedMs(task))
+		}
+
+		if err != nil {
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("task %s: %s", task.ID, err.Error()))
+			p.log.Warn("failed to import task",
+				logger.Error(err),
+				slog.String("task_id", task.ID),
+				slog.String("task_name", task.Name))
+		} else if skipped {
+			result.SkippedItems++
+		} else {
+			result.SuccessfulItems++
+			result.DocumentIDs = append(result.DocumentIDs, docID)
+		}
+		if err == nil {
+			result.Records = append(result.Records, p.taskRecord(task, config))
+		}
+
+		if progressCB != nil && i%10 == 0 {
+			progressCB(Progress{
+				Phase:           "importing",
+				TotalItems:      result.TotalItems,
+				ProcessedItems:  result.ProcessedItems,
+				SuccessfulItems: result.SuccessfulItems,
+				FailedItems:     result.FailedItems,
+				SkippedItems:    result.SkippedItems,
+				Message:         fmt.Sprintf("Importing %d/%d tasks...", i+1, len(tasks)),
+			})
+		}
+	}
+
+	if !complete || truncated {
+		result.Cursor = taskCursor(sinceMs, lastMs)
+	}
+	return nil
+}
+
+// taskCursor returns the cursor for a task sync that stopped short: just
+// before the last task fully processed, so tasks updated in the same
+// millisecond are fetched again, or a zero time to keep the previous cursor
+// when nothing past it was processed.
+func taskCursor(sinceMs, lastMs int64) *time.Time {
+	if lastMs <= sinceMs {
+		return &time.Time{}
+	}
+	cursor := time.UnixMilli(lastMs - 1)
+	return &cursor
+}
+
+// taskUpdatedMs returns a task's update time in Unix ms (0 if unknown).
+func taskUpdatedMs(task Task) int64 {
+	ms, _ := strconv.ParseInt(task.DateUpdated, 10, 64)
+	return ms
+}
+
+// getListsFromSpace returns all lists in a space, both inside folders and folderless
+func (p *Provider) getListsFromSpace(ctx context.Context, config *Config, spaceID string) ([]List, error) {
+	var lists []List
+
+	folders, err := p.client.GetFolders(ctx, config.APIToken, spaceID, config.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+	for _, folder := range folders.Folders {
+		for _, list := range folder.Lists {
+			if list.Folder == nil {
+				list.Folder = &FolderRef{ID: folder.ID, Name: folder.Name}
+			}
+			lists = append(lists, list)
+		}
+	}
+
+	folderless, err := p.client.GetFolderlessLists(ctx, config.APIToken, spaceID, config.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+	lists = append(lists, folderless.Lists...)
+
+	return lists, nil
+}
+
+// getTasksFromList pages through all tasks in a list updated after sinceMs (0 = all)
+func (p *Provider) getTasksFromList(ctx context.Context, config *Config, listID string, sinceMs int64) ([]Task, error) {
+	var tasks []Task
+
+	for page := 0; page < maxTaskPages; page++ {
+		resp, err := p.client.GetTasks(ctx, config.APIToken, listID, TaskQuery{
+			Page:          page,
+			DateUpdatedGt: sinceMs,
+			Subtasks:      config.IncludeSubtasks,
+			IncludeClosed: config.IncludeClosed,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, resp.Tasks...)
+
+		if resp.LastPage || len(resp.Tasks) == 0 {
+			break
+		}
+	}
+
+	return tasks, nil
+}
+
+// importTask imports a single ClickUp task as a document.
+// Returns the document ID, whether it was skipped, and any error
+func (p *Provider) importTask(ctx context.Context, config *Config, task Task, projectID, integrationID string) (string, bool, error) {
+	existing, err := p.findExisting(ctx, projectID, integrationID, "clickupTaskId", task.ID)
+	if err != nil {
+		return "", false, err
+	}
+
+	if existing != nil {
+		if meta, ok := existing.Metadata["clickupUpdatedAt"].(string); ok && meta == task.DateUpdated {
+			return existing.ID, true, nil
+		}
+	}
+
+	var comments []Comment
+	if config.IncludeComments {
+		resp, err := p.client.GetTaskComments(ctx, config.APIToken, task.ID)
+		if err != nil {
+			p.log.Warn("failed to fetch comments for task",
+				logger.Error(err),
+				slog.String("task_id", task.ID))
+		} else {
+			comments = resp.Comments
+		}
+	}
+
+	content := p.buildTaskContent(task, comments)
+	metadataMap := p.buildTaskMetadata(task, comments, config)
+
+	if existing != nil {
+		existing.Filename = &task.Name
+		existing.Content = &content
+		existing.Metadata = metadataMap
+		existing.UpdatedAt = time.Now()
+
+		if _, err := p.db.NewUpdate().
+			Model(existing).
+			WherePK().
+			Exec(ctx); err != nil {
+			return "", false, fmt.Errorf("update document: %w", err)
+		}
+
+		p.log.Debug("updated document from ClickUp task",
+			slog.String("document_id", existing.ID),
+			slog.String("clickup_task_id", task.ID))
+		return existing.ID, false, nil
+	}
+
+	mimeType := "text/markdown"
+	sourceType := SourceTypeClickUpTask
+	conversionStatus := "not_required"
+
+	document := &documents.Document{
+		ID:                      uuid.New().String(),
+		ProjectID:               projectID,
+		Filename:                &task.Name,
+		Content:                 &content,
+		MimeType:                &mimeType,
+		SourceType:              &sourceType,
+		DataSourceIntegrationID: &integrationID,
+		ConversionStatus:        &conversionStatus,
+		Metadata:                metadataMap,
+		CreatedAt:               time.Now(),
+		UpdatedAt:               time.Now(),
+	}
+
+	if err := p.docRepo.Create(ctx, document); err != nil {
+		return "", false, fmt.Errorf("create document: %w", err)
+	}
+
+	p.log.Debug("created document from ClickUp task",
+		slog.String("document_id", document.ID),
+		slog.String("clickup_task_id", task.ID),
+		slog.String("name", task.Name))
+
+	return document.ID, false, nil
+}
+
+// taskRecord builds the structured record for a task. Its fields are the
+// document metadata plus the task's name, description and raw IDs, so mapping
+// rules can address e.g. "customFields.Priority" or "assigneeIds".
+func (p *Provider) taskRecord(task Task, config *Config) Record {
+	fields := p.buildTaskMetadata(task, nil, config)
+	delete(fields, "commentCount")
+	fields["id"] = task.ID
+	fields["name"] = task.Name
+	fields["description"] = task.TextContent
+
+	assigneeIDs := make([]string, 0, len(task.Assignees))
+	for _, a := range task.Assignees {
+		assigneeIDs = append(assigneeIDs, strconv.Itoa(a.ID))
+	}
+	fields["assigneeIds"] = assigneeIDs
+
+	return Record{
+		SourceType: RecordTypeTask,
+		ID:         task.ID,
+		Fields:     fields,
+	}
+}
+
+// buildTaskMetadata builds the document metadata for a task
+func (p *Provider) buildTaskMetadata(task Task, comments []Comment, config *Config) map[string]any {
+	metadata := TaskDocumentMetadata{
+		ClickUpTaskID:      task.ID,
+		ClickUpWorkspaceID: config.WorkspaceID,
+		ClickUpSpaceID:     task.Space.ID,
+		ClickUpFolderID:    task.Folder.ID,
+		ClickUpListID:      task.List.ID,
+		ClickUpListName:    task.List.Name,
+		ClickUpURL:         task.URL,
+		Status:             task.Status.Status,
+		StatusType:         task.Status.Type,
+		Creator:            task.Creator.Username,
+		ChecklistCount:     len(task.Checklists),
+		CommentCount:       len(comments),
+		ClickUpCreatedAt:   task.DateCreated,
+		ClickUpUpdatedAt:   task.DateUpdated,
+		Archived:           task.Archived,
+		ItemType:           "task",
+		Provider:           "clickup",
+	}
+
+	if task.CustomID != nil {
+		metadata.ClickUpCustomID = *task.CustomID
+	}
+	if task.Parent != nil {
+		metadata.ClickUpParentTaskID = *task.Parent
+	}
+	if task.Priority != nil {
+		metadata.Priority = task.Priority.Priority
+	}
+	for _, a := range task.Assignees {
+		metadata.Assignees = append(metadata.Assignees, a.Username)
+	}
+	for _, t := range task.Tags {
+		metadata.Tags = append(metadata.Tags, t.Name)
+	}
+	metadata.DueDate = formatUnixMs(task.DueDate)
+	metadata.StartDate = formatUnixMs(task.StartDate)
+	metadata.DateClosed = formatUnixMs(task.DateClosed)
+
+	if len(task.CustomFields) > 0 {
+		metadata.CustomFields = make(map[string]interface{})
+		for _, cf := range task.CustomFields {
+			if value := customFieldValue(cf); value != nil {
+				metadata.CustomFields[cf.Name] = value
+			}
+		}
+	}
+
+	metadataMap := make(map[string]any)
+	metaJSON, _ := json.Marshal(metadata)
+	json.Unmarshal(metaJSON, &metadataMap)
+
+	return metadataMap
+}
+
+// buildTaskContent renders a task, its checklists and comments as markdown
+func (p *Provider) buildTaskContent(task Task, comments []Comment) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", task.Name))
+
+	if task.Status.Status != "" {
+		sb.WriteString(fmt.Sprintf("**Status:** %s\n", task.Status.Status))
+	}
+	if task.Priority != nil && task.Priority.Priority != "" {
+		sb.WriteString(fmt.Sprintf("**Priority:** %s\n", task.Priority.Priority))
+	}
+	if len(task.Assignees) > 0 {
+		names := make([]string, len(task.Assignees))
+		for i, a := range task.Assignees {
+			names[i] = a.Username
+		}
+		sb.WriteString(fmt.Sprintf("**Assignees:** %s\n", strings.Join(names, ", ")))
+	}
+	if task.Creator.Username != "" {
+		sb.WriteString(fmt.Sprintf("**Created by:** %s\n", task.Creator.Username))
+	}
+	if due := formatUnixMs(task.DueDate); due != "" {
+		sb.WriteString(fmt.Sprintf("**Due:** %s\n", due))
+	}
+	if start := formatUnixMs(task.StartDate); start != "" {
+		sb.WriteString(fmt.Sprintf("**Start:** %s\n", start))
+	}
+	if len(task.Tags) > 0 {
+		names := make([]string, len(task.Tags))
+		for i, t := range task.Tags {
+			names[i] = t.Name
+		}
+		sb.WriteString(fmt.Sprintf("**Tags:** %s\n", strings.Join(names, ", ")))
+	}
+	if task.List.Name != "" {
+		sb.WriteString(fmt.Sprintf("**List:** %s\n", task.List.Name))
+	}
+	if task.Parent != nil && *task.Parent != "" {
+		sb.WriteString(fmt.Sprintf("**Parent task:** %s\n", *task.Parent))
+	}
+	sb.WriteString("\n")
+
+	description := task.MarkdownDescription
+	if description == "" {
+		description = task.Description
+	}
+	if description == "" {
+		description = task.TextContent
+	}
+	if description != "" {
+		sb.WriteString("## Description\n\n")
+		sb.WriteString(description)
+		sb.WriteString("\n\n")
+	}
+
+	var fieldLines []string
+	for _, cf := range task.CustomFields {
+		value := customFieldValue(cf)
+		if value == nil {
+			continue
+		}
+		fieldLines = append(fieldLines, fmt.Sprintf("- **%s:** %v\n", cf.Name, value))
+	}
+	if len(fieldLines) > 0 {
+		sort.Strings(fieldLines)
+		sb.WriteString("## Custom Fields\n\n")
+		for _, line := range fieldLines {
+			sb.WriteString(line)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(task.Checklists) > 0 {
+		sb.WriteString("## Checklists\n\n")
+		for _, cl := range task.Checklists {
+			sb.WriteString(fmt.Sprintf("### %s\n\n", cl.Name))
+			appendChecklistItems(&sb, cl.Items, 0)
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(comments) > 0 {
+		sb.WriteString("## Comments\n\n")
+		for _, c := range comments {
+			when := formatUnixMs(&c.Date)
+			if when != "" {
+				sb.WriteString(fmt.Sprintf("**%s** (%s):\n", c.User.Username, when))
+			} else {
+				sb.WriteString(fmt.Sprintf("**%s**:\n", c.User.Username))
+			}
+			sb.WriteString(c.CommentText)
+			sb.WriteString("\n\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// appendChecklistItems renders checklist items as a nested markdown task list
+func appendChecklistItems(sb *strings.Builder, items []ChecklistItem, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, item := range items {
+		mark := " "
+		if item.Resolved {
+			mark = "x"
+		}
+		sb.WriteString(fmt.Sprintf("%s- [%s] %s\n", indent, mark, item.Name))
+		if len(item.Children) > 0 {
+			appendChecklistItems(sb, item.Children, depth+1)
+		}
+	}
+}
+
+// customFieldValue resolves a custom field to a display value.
+// Drop-down and label fields are resolved to their option names.
+// Returns nil when the field has no value.
+func customFieldValue(cf CustomField) interface{} {
+	if cf.Value == nil {
+		return nil
+	}
+
+	switch cf.Type {
+	case "drop_down":
+		// Value is the option's orderindex (number) or, in newer payloads, its ID
+		for _, opt := range cf.TypeConfig.Options {
+			switch v := cf.Value.(type) {
+			case float64:
+				if opt.OrderIndex != nil && *opt.OrderIndex == int(v) {
+					return optionName(opt)
+				}
+			case string:
+				if opt.ID == v {
+					return optionName(opt)
+				}
+			}
+		}
+	case "labels":
+		ids, ok := cf.Value.([]interface{})
+		if !ok {
+			break
+		}
+		var names []string
+		for _, id := range ids {
+			for _, opt := range cf.TypeConfig.Options {
+				if s, ok := id.(string); ok && opt.ID == s {
+					names = append(names, optionName(opt))
+				}
+			}
+		}
+		if len(names) > 0 {
+			return names
+		}
+	case "date":
+		if s, ok := cf.Value.(string); ok {
+			return formatUnixMs(&s)
+		}
+	}
+
+	if s, ok := cf.Value.(string); ok && s == "" {
+		return nil
+	}
+	return cf.Value
+}
+
+// optionName returns the display name of a custom field option
+func optionName(opt CustomFieldOption) string {
+	if opt.Name != "" {
+		return opt.Name
+	}
+	return opt.Label
+}
+
+// formatUnixMs formats a ClickUp Unix ms timestamp string as RFC 3339 (UTC).
+// Returns "" for nil, empty or unparseable values.
+func formatUnixMs(value *string) string {
+	if value == nil || *value == "" {
+		return ""
+	}
+	ms, err := strconv.ParseInt(*value, 10, 64)
+	if err != nil {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
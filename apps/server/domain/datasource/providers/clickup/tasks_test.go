@@ -0,0 +1,351 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(testLogger())
+	client.v2URL = server.URL + "/api/v2"
+	client.v3URL = server.URL + "/api/v3"
+	return client
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestClient_Tasks(t *testing.T) {
+	t.Run("GetTasks sends incremental and subtask filters", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/list/list_1/task", r.URL.Path)
+			assert.Equal(t, "test-token", r.Header.Get("Authorization"))
+			q := r.URL.Query()
+			assert.Equal(t, "2", q.Get("page"))
+			assert.Equal(t, "1704067200000", q.Get("date_updated_gt"))
+			assert.Equal(t, "true", q.Get("subtasks"))
+			assert.Equal(t, "true", q.Get("include_closed"))
+			assert.Equal(t, "true", q.Get("include_markdown_description"))
+
+			json.NewEncoder(w).Encode(TasksResponse{
+				Tasks:    []Task{{ID: "t1", Name: "Task 1"}},
+				LastPage: true,
+			})
+		})
+
+		resp, err := client.GetTasks(context.Background(), "test-token", "list_1", TaskQuery{
+			Page:          2,
+			DateUpdatedGt: 1704067200000,
+			Subtasks:      true,
+			IncludeClosed: true,
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.LastPage)
+		require.Len(t, resp.Tasks, 1)
+		assert.Equal(t, "t1", resp.Tasks[0].ID)
+	})
+
+	t.Run("GetTasks omits unset filters", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			assert.Equal(t, "0", q.Get("page"))
+			assert.Empty(t, q.Get("date_updated_gt"))
+			assert.Empty(t, q.Get("subtasks"))
+			assert.Empty(t, q.Get("include_closed"))
+			w.Write([]byte(`{"tasks":[],"last_page":true}`))
+		})
+
+		_, err := client.GetTasks(context.Background(), "test-token", "list_1", TaskQuery{})
+		require.NoError(t, err)
+	})
+
+	t.Run("GetFolders and GetFolderlessLists", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v2/space/sp_1/folder":
+				w.Write([]byte(`{"folders":[{"id":"f1","name":"Folder","lists":[{"id":"l1","name":"In Folder"}]}]}`))
+			case "/api/v2/space/sp_1/list":
+				w.Write([]byte(`{"lists":[{"id":"l2","name":"Folderless"}]}`))
+			default:
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+		})
+
+		p := &Provider{client: client, log: testLogger()}
+		lists, err := p.getListsFromSpace(context.Background(), &Config{APIToken: "test-token"}, "sp_1")
+		require.NoError(t, err)
+		require.Len(t, lists, 2)
+		assert.Equal(t, "l1", lists[0].ID)
+		require.NotNil(t, lists[0].Folder)
+		assert.Equal(t, "f1", lists[0].Folder.ID)
+		assert.Equal(t, "l2", lists[1].ID)
+	})
+
+	t.Run("getTasksFromList pages until last_page", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			page := r.URL.Query().Get("page")
+			if page == "0" {
+				w.Write([]byte(`{"tasks":[{"id":"t1"}],"last_page":false}`))
+				return
+			}
+			w.Write([]byte(`{"tasks":[{"id":"t2"}],"last_page":true}`))
+		})
+
+		p := &Provider{client: client, log: testLogger()}
+		tasks, err := p.getTasksFromList(context.Background(), &Config{APIToken: "test-token"}, "list_1", 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "t2", tasks[1].ID)
+	})
+
+	t.Run("GetTaskComments", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/task/t1/comment", r.URL.Path)
+			w.Write([]byte(`{"comments":[{"id":"c1","comment_text":"Looks good","user":{"id":1,"username":"alice"},"date":"1704067200000"}]}`))
+		})
+
+		resp, err := client.GetTaskComments(context.Background(), "test-token", "t1")
+		require.NoError(t, err)
+		require.Len(t, resp.Comments, 1)
+		assert.Equal(t, "alice", resp.Comments[0].User.Username)
+	})
+}
+
+func TestTask_JSONParsing(t *testing.T) {
+	taskJSON := `{
+		"id": "9hx",
+		"custom_id": "ENG-42",
+		"name": "Ship it",
+		"markdown_description": "Do the **thing**",
+		"status": {"status": "in progress", "type": "custom"},
+		"date_created": "1704067200000",
+		"date_updated": "1704153600000",
+		"due_date": "1704240000000",
+		"start_date": null,
+		"creator": {"id": 1, "username": "alice"},
+		"assignees": [{"id": 2, "username": "bob"}],
+		"tags": [{"name": "backend"}],
+		"parent": "9hw",
+		"priority": {"id": "2", "priority": "high"},
+		"custom_fields": [
+			{"id": "cf1", "name": "Points", "type": "number", "value": "5"},
+			{"id": "cf2", "name": "Team", "type": "drop_down", "type_config": {"options": [{"id": "o1", "name": "Platform", "orderindex": 0}]}, "value": 0}
+		],
+		"checklists": [{"id": "cl1", "name": "QA", "items": [{"id": "i1", "name": "Tested", "resolved": true}]}],
+		"list": {"id": "l1"},
+		"folder": {"id": "f1"},
+		"space": {"id": "sp1"},
+		"url": "https://app.clickup.com/t/9hx"
+	}`
+
+	var task Task
+	require.NoError(t, json.Unmarshal([]byte(taskJSON), &task))
+
+	assert.Equal(t, "9hx", task.ID)
+	require.NotNil(t, task.CustomID)
+	assert.Equal(t, "ENG-42", *task.CustomID)
+	assert.Equal(t, "in progress", task.Status.Status)
+	require.NotNil(t, task.Parent)
+	assert.Equal(t, "9hw", *task.Parent)
+	assert.Nil(t, task.StartDate)
+	require.NotNil(t, task.Priority)
+	assert.Equal(t, "high", task.Priority.Priority)
+	assert.Len(t, task.CustomFields, 2)
+	require.Len(t, task.Checklists, 1)
+	assert.True(t, task.Checklists[0].Items[0].Resolved)
+	assert.Equal(t, "sp1", task.Space.ID)
+}
+
+func TestProvider_BuildTaskContent(t *testing.T) {
+	p := &Provider{log: testLogger()}
+	zero := 0
+
+	task := Task{
+		ID:                  "t1",
+		Name:                "Ship it",
+		MarkdownDescription: "Do the **thing**",
+		Description:         "plain fallback",
+		Status:              TaskStatus{Status: "in progress"},
+		Priority:            &TaskPriority{Priority: "high"},
+		Assignees:           []User{{Username: "bob"}, {Username: "carol"}},
+		Creator:             User{Username: "alice"},
+		Tags:                []Tag{{Name: "backend"}},
+		DueDate:             strPtr("1704240000000"),
+		Parent:              strPtr("t0"),
+		List:                ListRef{ID: "l1", Name: "Sprint 1"},
+		CustomFields: []CustomField{
+			{Name: "Team", Type: "drop_down", TypeConfig: CustomFieldTypeConfig{Options: []CustomFieldOption{{ID: "o1", Name: "Platform", OrderIndex: &zero}}}, Value: float64(0)},
+			{Name: "Empty", Type: "short_text"},
+		},
+		Checklists: []Checklist{{
+			Name: "QA",
+			Items: []ChecklistItem{
+				{Name: "Tested", Resolved: true, Children: []ChecklistItem{{Name: "Edge cases"}}},
+				{Name: "Documented"},
+			},
+		}},
+	}
+	comments := []Comment{{CommentText: "Looks good", User: User{Username: "dave"}, Date: "1704067200000"}}
+
+	content := p.buildTaskContent(task, comments)
+
+	assert.Contains(t, content, "# Ship it")
+	assert.Contains(t, content, "**Status:** in progress")
+	assert.Contains(t, content, "**Priority:** high")
+	assert.Contains(t, content, "**Assignees:** bob, carol")
+	assert.Contains(t, content, "**Due:** 2024-01-03T00:00:00Z")
+	assert.Contains(t, content, "**Tags:** backend")
+	assert.Contains(t, content, "**List:** Sprint 1")
+	assert.Contains(t, content, "**Parent task:** t0")
+	assert.Contains(t, content, "Do the **thing**")
+	assert.NotContains(t, content, "plain fallback")
+	assert.Contains(t, content, "- **Team:** Platform")
+	assert.NotContains(t, content, "Empty")
+	assert.Contains(t, content, "### QA")
+	assert.Contains(t, content, "- [x] Tested")
+	assert.Contains(t, content, "  - [ ] Edge cases")
+	assert.Contains(t, content, "- [ ] Documented")
+	assert.Contains(t, content, "## Comments")
+	assert.Contains(t, content, "**dave** (2024-01-01T00:00:00Z):")
+	assert.Contains(t, content, "Looks good")
+}
+
+func TestProvider_BuildTaskMetadata(t *testing.T) {
+	p := &Provider{log: testLogger()}
+
+	task := Task{
+		ID:          "t1",
+		CustomID:    strPtr("ENG-1"),
+		Status:      TaskStatus{Status: "done", Type: "closed"},
+		Assignees:   []User{{Username: "bob"}},
+		Tags:        []Tag{{Name: "bug"}},
+		Parent:      strPtr("t0"),
+		DateUpdated: "1704153600000",
+		List:        ListRef{ID: "l1", Name: "Backlog"},
+		Space:       SpaceRef{ID: "sp1"},
+		CustomFields: []CustomField{
+			{Name: "Points", Type: "number", Value: "3"},
+		},
+	}
+
+	meta := p.buildTaskMetadata(task, nil, &Config{WorkspaceID: "ws1"})
+
+	assert.Equal(t, "t1", meta["clickupTaskId"])
+	assert.Equal(t, "ENG-1", meta["clickupCustomId"])
+	assert.Equal(t, "ws1", meta["clickupWorkspaceId"])
+	assert.Equal(t, "t0", meta["clickupParentTaskId"])
+	assert.Equal(t, "1704153600000", meta["clickupUpdatedAt"])
+	assert.Equal(t, "done", meta["status"])
+	assert.Equal(t, "task", meta["itemType"])
+	assert.Equal(t, "clickup", meta["provider"])
+	assert.Equal(t, []interface{}{"bob"}, meta["assignees"])
+	assert.Equal(t, map[string]interface{}{"Points": "3"}, meta["customFields"])
+}
+
+func TestCustomFieldValue(t *testing.T) {
+	idx := 1
+	options := CustomFieldTypeConfig{Options: []CustomFieldOption{
+		{ID: "o1", Name: "Low"},
+		{ID: "o2", Name: "High", OrderIndex: &idx},
+		{ID: "o3", Label: "Urgent"},
+	}}
+
+	tests := []struct {
+		name string
+		cf   CustomField
+		want interface{}
+	}{
+		{"nil value", CustomField{Type: "text"}, nil},
+		{"empty string", CustomField{Type: "text", Value: ""}, nil},
+		{"plain value", CustomField{Type: "number", Value: "42"}, "42"},
+		{"drop_down by orderindex", CustomField{Type: "drop_down", TypeConfig: options, Value: float64(1)}, "High"},
+		{"drop_down by id", CustomField{Type: "drop_down", TypeConfig: options, Value: "o1"}, "Low"},
+		{"labels", CustomField{Type: "labels", TypeConfig: options, Value: []interface{}{"o1", "o3"}}, []string{"Low", "Urgent"}},
+		{"date", CustomField{Type: "date", Value: "1704067200000"}, "2024-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, customFieldValue(tt.cf))
+		})
+	}
+}
+
+func TestFormatUnixMs(t *testing.T) {
+	assert.Equal(t, "", formatUnixMs(nil))
+	assert.Equal(t, "", formatUnixMs(strPtr("")))
+	assert.Equal(t, "", formatUnixMs(strPtr("not-a-number")))
+	assert.Equal(t, "2024-01-01T00:00:00Z", formatUnixMs(strPtr("1704067200000")))
+}
+
+func TestProvider_SyncSince(t *testing.T) {
+	p := &Provider{log: testLogger()}
+	last := time.UnixMilli(1704153600000)
+
+	t.Run("full sync ignores cursors", func(t *testing.T) {
+		got := p.syncSince(&Config{LastSyncedAt: 1}, ProviderConfig{LastSyncedAt: &last}, SyncOptions{FullSync: true})
+		assert.Equal(t, int64(0), got)
+	})
+
+	t.Run("config lastSyncedAt wins", func(t *testing.T) {
+		got := p.syncSince(&Config{LastSyncedAt: 1704067200000}, ProviderConfig{LastSyncedAt: &last}, SyncOptions{})
+		assert.Equal(t, int64(1704067200000), got)
+	})
+
+	t.Run("falls back to integration last sync", func(t *testing.T) {
+		got := p.syncSince(&Config{}, ProviderConfig{LastSyncedAt: &last}, SyncOptions{})
+		assert.Equal(t, int64(1704153600000), got)
+	})
+
+	t.Run("never synced", func(t *testing.T) {
+		assert.Equal(t, int64(0), p.syncSince(&Config{}, ProviderConfig{}, SyncOptions{}))
+	})
+}
+
+func TestTaskCursor(t *testing.T) {
+	t.Run("advances to just before the last processed task", func(t *testing.T) {
+		got := taskCursor(1704067200000, 1704153600000)
+		require.NotNil(t, got)
+		assert.Equal(t, int64(1704153599999), got.UnixMilli())
+	})
+
+	t.Run("keeps the previous cursor when nothing was processed", func(t *testing.T) {
+		got := taskCursor(1704067200000, 1704067200000)
+		require.NotNil(t, got)
+		assert.True(t, got.IsZero())
+	})
+
+	t.Run("keeps no cursor on a first sync that failed early", func(t *testing.T) {
+		got := taskCursor(0, 0)
+		require.NotNil(t, got)
+		assert.True(t, got.IsZero())
+	})
+}
+
+func TestTaskUpdatedMs(t *testing.T) {
+	assert.Equal(t, int64(1704067200000), taskUpdatedMs(Task{DateUpdated: "1704067200000"}))
+	assert.Equal(t, int64(0), taskUpdatedMs(Task{}))
+}
+
+func TestConfig_TasksEnabled(t *testing.T) {
+	assert.True(t, (&Config{}).TasksEnabled())
+
+	enabled := true
+	assert.True(t, (&Config{ImportTasks: &enabled}).TasksEnabled())
+
+	disabled := false
+	assert.False(t, (&Config{ImportTasks: &disabled}).TasksEnabled())
+}
@@ -1,4 +1,4 @@
-// Package clickup provides a data source provider for ClickUp Docs.
+// Package clickup provides a data source provider for ClickUp Docs and Tasks.
 package clickup
 
 // Config represents the ClickUp provider configuration.
@@ -19,10 +19,28 @@ type Config struct {
 	// IncludeArchived includes archived docs and spaces
 	IncludeArchived bool `json:"includeArchived,omitempty"`
 
+	// ImportTasks imports tasks from the lists in the selected spaces (nil = true)
+	ImportTasks *bool `json:"importTasks,omitempty"`
+
+	// IncludeSubtasks imports subtasks as separate task documents
+	IncludeSubtasks bool `json:"includeSubtasks,omitempty"`
+
+	// IncludeClosed imports tasks in closed/done statuses
+	IncludeClosed bool `json:"includeClosed,omitempty"`
+
+	// IncludeComments fetches and embeds task comments (one extra request per task)
+	IncludeComments bool `json:"includeComments,omitempty"`
+
 	// LastSyncedAt is the timestamp of the last sync (Unix ms)
 	LastSyncedAt int64 `json:"lastSyncedAt,omitempty"`
 }
 
+// TasksEnabled reports whether tasks should be imported.
+// Task import is on unless explicitly disabled.
+func (c *Config) TasksEnabled() bool {
+	return c.ImportTasks == nil || *c.ImportTasks
+}
+
 // SelectedSpace represents a space selected for syncing
 type SelectedSpace struct {
 	ID   string `json:"id"`
@@ -74,6 +92,162 @@ type SpacesResponse struct {
 	Spaces []Space `json:"spaces"`
 }
 
+// FolderRef is a lightweight reference to a folder embedded in other objects
+type FolderRef struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+// SpaceRef is a lightweight reference to a space embedded in other objects
+type SpaceRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// List represents a ClickUp list (the container that holds tasks)
+type List struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Content  string     `json:"content,omitempty"`
+	Archived bool       `json:"archived"`
+	Folder   *FolderRef `json:"folder,omitempty"`
+	Space    *SpaceRef  `json:"space,omitempty"`
+}
+
+// Folder represents a ClickUp folder and the lists it contains
+type Folder struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Hidden   bool      `json:"hidden"`
+	Archived bool      `json:"archived"`
+	Space    *SpaceRef `json:"space,omitempty"`
+	Lists    []List    `json:"lists"`
+}
+
+// FoldersResponse is the response from GET /space/{space_id}/folder
+type FoldersResponse struct {
+	Folders []Folder `json:"folders"`
+}
+
+// ListsResponse is the response from GET /space/{space_id}/list
+type ListsResponse struct {
+	Lists []List `json:"lists"`
+}
+
+// TaskStatus is the workflow status of a task
+type TaskStatus struct {
+	Status string `json:"status"`
+	Color  string `json:"color,omitempty"`
+	Type   string `json:"type,omitempty"` // 'open', 'custom', 'closed', 'done'
+}
+
+// TaskPriority is the priority of a task
+type TaskPriority struct {
+	ID       string `json:"id"`
+	Priority string `json:"priority"` // 'urgent', 'high', 'normal', 'low'
+	Color    string `json:"color,omitempty"`
+}
+
+// Tag is a label attached to a task
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// CustomFieldOption is a selectable option of a drop_down or labels custom field
+type CustomFieldOption struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	Label      string `json:"label,omitempty"`
+	OrderIndex *int   `json:"orderindex,omitempty"`
+}
+
+// CustomFieldTypeConfig holds the type-specific configuration of a custom field
+type CustomFieldTypeConfig struct {
+	Options []CustomFieldOption `json:"options,omitempty"`
+}
+
+// CustomField is a custom field value on a task.
+// Value is left untyped because its shape depends on Type.
+type CustomField struct {
+	ID         string                `json:"id"`
+	Name       string                `json:"name"`
+	Type       string                `json:"type"`
+	TypeConfig CustomFieldTypeConfig `json:"type_config,omitempty"`
+	Value      interface{}           `json:"value,omitempty"`
+}
+
+// ChecklistItem is a single item of a task checklist
+type ChecklistItem struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Resolved bool            `json:"resolved"`
+	Assignee *User           `json:"assignee,omitempty"`
+	Children []ChecklistItem `json:"children,omitempty"`
+}
+
+// Checklist is a named checklist on a task
+type Checklist struct {
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Items []ChecklistItem `json:"items"`
+}
+
+// Task represents a ClickUp task (from v2 API)
+type Task struct {
+	ID                  string        `json:"id"`
+	CustomID            *string       `json:"custom_id,omitempty"`
+	Name                string        `json:"name"`
+	TextContent         string        `json:"text_content,omitempty"`
+	Description         string        `json:"description,omitempty"`
+	MarkdownDescription string        `json:"markdown_description,omitempty"`
+	Status              TaskStatus    `json:"status"`
+	DateCreated         string        `json:"date_created"` // Unix timestamp (ms) as string
+	DateUpdated         string        `json:"date_updated"` // Unix timestamp (ms) as string
+	DateClosed          *string       `json:"date_closed,omitempty"`
+	DueDate             *string       `json:"due_date,omitempty"`
+	StartDate           *string       `json:"start_date,omitempty"`
+	Archived            bool          `json:"archived"`
+	Creator             User          `json:"creator"`
+	Assignees           []User        `json:"assignees"`
+	Tags                []Tag         `json:"tags"`
+	Parent              *string       `json:"parent,omitempty"` // Parent task ID for subtasks
+	Priority            *TaskPriority `json:"priority,omitempty"`
+	CustomFields        []CustomField `json:"custom_fields"`
+	Checklists          []Checklist   `json:"checklists"`
+	List                ListRef       `json:"list"`
+	Folder              FolderRef     `json:"folder"`
+	Space               SpaceRef      `json:"space"`
+	URL                 string        `json:"url,omitempty"`
+	Subtasks            []Task        `json:"subtasks,omitempty"` // Only populated by GET /task/{id}
+}
+
+// ListRef is a lightweight reference to a list embedded in a task
+type ListRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// TasksResponse is the response from GET /list/{list_id}/task
+type TasksResponse struct {
+	Tasks    []Task `json:"tasks"`
+	LastPage bool   `json:"last_page"`
+}
+
+// Comment represents a comment on a task
+type Comment struct {
+	ID          string `json:"id"`
+	CommentText string `json:"comment_text"`
+	User        User   `json:"user"`
+	Resolved    bool   `json:"resolved"`
+	Date        string `json:"date"` // Unix timestamp (ms) as string
+}
+
+// CommentsResponse is the response from GET /task/{task_id}/comment
+type CommentsResponse struct {
+	Comments []Comment `json:"comments"`
+}
+
 // ----------------------------------------------------------------------------
 // ClickUp API v3 Types (Docs)
 // ----------------------------------------------------------------------------
@@ -152,6 +326,37 @@ type DocumentMetadata struct {
 	Provider            string `json:"provider"` // Always "clickup"
 }
 
+// TaskDocumentMetadata is stored in document.metadata for ClickUp-sourced tasks.
+// It carries the structured task fields so they survive alongside the rendered markdown.
+type TaskDocumentMetadata struct {
+	ClickUpTaskID       string                 `json:"clickupTaskId"`
+	ClickUpCustomID     string                 `json:"clickupCustomId,omitempty"`
+	ClickUpWorkspaceID  string                 `json:"clickupWorkspaceId"`
+	ClickUpSpaceID      string                 `json:"clickupSpaceId,omitempty"`
+	ClickUpFolderID     string                 `json:"clickupFolderId,omitempty"`
+	ClickUpListID       string                 `json:"clickupListId,omitempty"`
+	ClickUpListName     string                 `json:"clickupListName,omitempty"`
+	ClickUpParentTaskID string                 `json:"clickupParentTaskId,omitempty"`
+	ClickUpURL          string                 `json:"clickupUrl,omitempty"`
+	Status              string                 `json:"status,omitempty"`
+	StatusType          string                 `json:"statusType,omitempty"`
+	Priority            string                 `json:"priority,omitempty"`
+	Creator             string                 `json:"creator,omitempty"`
+	Assignees           []string               `json:"assignees,omitempty"`
+	Tags                []string               `json:"tags,omitempty"`
+	DueDate             string                 `json:"dueDate,omitempty"`
+	StartDate           string                 `json:"startDate,omitempty"`
+	DateClosed          string                 `json:"dateClosed,omitempty"`
+	CustomFields        map[string]interface{} `json:"customFields,omitempty"`
+	ChecklistCount      int                    `json:"checklistCount,omitempty"`
+	CommentCount        int                    `json:"commentCount,omitempty"`
+	ClickUpCreatedAt    string                 `json:"clickupCreatedAt,omitempty"`
+	ClickUpUpdatedAt    string                 `json:"clickupUpdatedAt,omitempty"`
+	Archived            bool                   `json:"archived,omitempty"`
+	ItemType            string                 `json:"itemType"` // Always "task"
+	Provider            string                 `json:"provider"` // Always "clickup"
+}
+
 // ConfigSchema is the JSON schema for provider configuration (used by UI)
 var ConfigSchema = map[string]interface{}{
 	"type":     "object",
@@ -180,6 +385,30 @@ var ConfigSchema = map[string]interface{}{
 			"description": "Include archived docs and spaces in sync",
 			"default":     false,
 		},
+		"importTasks": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Import Tasks",
+			"description": "Import tasks (description, status, assignees, custom fields, checklists) from lists",
+			"default":     true,
+		},
+		"includeSubtasks": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Include Subtasks",
+			"description": "Import subtasks as separate task documents",
+			"default":     false,
+		},
+		"includeClosed": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Include Closed Tasks",
+			"description": "Import tasks in closed or done statuses",
+			"default":     false,
+		},
+		"includeComments": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Include Comments",
+			"description": "Fetch task comments (uses one extra API request per task)",
+			"default":     false,
+		},
 	},
 	"ui:authType":       "token",
 	"ui:testConnection": true,
@@ -0,0 +1,156 @@
+package clickup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// SignatureHeader is the header ClickUp signs webhook bodies with
+// (hex-encoded HMAC-SHA256 of the raw body, keyed by the webhook secret)
+const SignatureHeader = "X-Signature"
+
+// WebhookPayload is a parsed ClickUp webhook delivery.
+// Mirrors datasource.WebhookDelivery to avoid import cycle
+type WebhookPayload struct {
+	DeliveryID  string
+	EventType   string
+	UpsertedIDs []string
+	DeletedIDs  []string
+}
+
+// webhookBody is the JSON body ClickUp posts for task events
+type webhookBody struct {
+	Event        string `json:"event"`
+	TaskID       string `json:"task_id"`
+	WebhookID    string `json:"webhook_id"`
+	HistoryItems []struct {
+		ID string `json:"id"`
+	} `json:"history_items"`
+}
+
+// VerifyWebhook checks the X-Signature header against the signing secret
+func (p *Provider) VerifyWebhook(secret string, headers http.Header, body []byte) error {
+	signature := strings.TrimSpace(headers.Get(SignatureHeader))
+	if signature == "" {
+		return fmt.Errorf("missing %s header", SignatureHeader)
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// ParseWebhook converts a ClickUp webhook body into task changes.
+// Events that don't concern a task (list, folder, space, goal events) yield no items.
+func (p *Provider) ParseWebhook(headers http.Header, body []byte) (*WebhookPayload, error) {
+	var wb webhookBody
+	if err := json.Unmarshal(body, &wb); err != nil {
+		return nil, fmt.Errorf("parse webhook body: %w", err)
+	}
+	if wb.Event == "" {
+		return nil, fmt.Errorf("missing event")
+	}
+
+	payload := &WebhookPayload{EventType: wb.Event}
+
+	// ClickUp retries deliveries with the same history item, which makes
+	// webhook_id + history item ID a stable delivery ID
+	if len(wb.HistoryItems) > 0 && wb.HistoryItems[0].ID != "" {
+		payload.DeliveryID = wb.WebhookID + ":" + wb.HistoryItems[0].ID
+	}
+
+	if wb.TaskID == "" || !strings.HasPrefix(wb.Event, "task") {
+		return payload, nil
+	}
+
+	if wb.Event == "taskDeleted" {
+		payload.DeletedIDs = []string{wb.TaskID}
+	} else {
+		payload.UpsertedIDs = []string{wb.TaskID}
+	}
+	return payload, nil
+}
+
+// syncTargetedTasks re-imports or removes specific tasks (webhook-triggered syncs)
+// without walking spaces and lists
+func (p *Provider) syncTargetedTasks(ctx context.Context, config *Config, projectID, integrationID string, itemIDs, deletedIDs []string, result *SyncResult) error {
+	result.TotalItems = len(itemIDs) + len(deletedIDs)
+
+	for _, taskID := range itemIDs {
+		select {
+		case <-ctx.Done():
+			result.Errors = append(result.Errors, "sync cancelled")
+			return ctx.Err()
+		default:
+		}
+
+		result.ProcessedItems++
+		task, err := p.client.GetTask(ctx, config.APIToken, taskID)
+		if err != nil {
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("task %s: %s", taskID, err.Error()))
+			continue
+		}
+
+		docID, skipped, err := p.importTask(ctx, config, *task, projectID, integrationID)
+		if err != nil {
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("task %s: %s", taskID, err.Error()))
+		} else if skipped {
+			result.SkippedItems++
+		} else {
+			result.SuccessfulItems++
+			result.DocumentIDs = append(result.DocumentIDs, docID)
+		}
+		if err == nil {
+			result.Records = append(result.Records, p.taskRecord(*task, config))
+		}
+	}
+
+	for _, taskID := range deletedIDs {
+		result.ProcessedItems++
+		existing, err := p.findExisting(ctx, projectID, integrationID, "clickupTaskId", taskID)
+		if err != nil {
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("task %s: %s", taskID, err.Error()))
+			continue
+		}
+		if existing == nil {
+			result.SkippedItems++
+			continue
+		}
+		if _, err := p.docRepo.DeleteWithCascade(ctx, projectID, existing.ID); err != nil {
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("task %s: %s", taskID, err.Error()))
+			continue
+		}
+		result.SuccessfulItems++
+		p.log.Debug("deleted document for deleted ClickUp task",
+			slog.String("document_id", existing.ID),
+			slog.String("clickup_task_id", taskID))
+	}
+
+	if result.FailedItems > 0 && result.SuccessfulItems == 0 && result.SkippedItems == 0 {
+		p.log.Warn("targeted clickup sync failed for all items",
+			logger.Error(fmt.Errorf("%s", strings.Join(result.Errors, "; "))))
+		return fmt.Errorf("targeted sync failed for all %d items", result.FailedItems)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package clickup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestProvider_VerifyWebhook(t *testing.T) {
+	p := &Provider{}
+	body := []byte(`{"event":"taskUpdated","task_id":"abc"}`)
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid signature", signature: sign("secret", body)},
+		{name: "missing signature", signature: "", wantErr: true},
+		{name: "wrong secret", signature: sign("other", body), wantErr: true},
+		{name: "not hex", signature: "zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			if tt.signature != "" {
+				headers.Set(SignatureHeader, tt.signature)
+			}
+			err := p.VerifyWebhook("secret", headers, body)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProvider_ParseWebhook(t *testing.T) {
+	p := &Provider{}
+
+	tests := []struct {
+		name         string
+		body         string
+		wantErr      bool
+		wantDelivery string
+		wantUpserted []string
+		wantDeleted  []string
+	}{
+		{
+			name:         "task updated",
+			body:         `{"event":"taskUpdated","task_id":"abc","webhook_id":"wh1","history_items":[{"id":"h1"}]}`,
+			wantDelivery: "wh1:h1",
+			wantUpserted: []string{"abc"},
+		},
+		{
+			name:        "task deleted",
+			body:        `{"event":"taskDeleted","task_id":"abc","webhook_id":"wh1"}`,
+			wantDeleted: []string{"abc"},
+		},
+		{
+			name:         "non-task event",
+			body:         `{"event":"listCreated","list_id":"l1","webhook_id":"wh1","history_items":[{"id":"h2"}]}`,
+			wantDelivery: "wh1:h2",
+		},
+		{
+			name:    "missing event",
+			body:    `{"task_id":"abc"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			body:    `{`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := p.ParseWebhook(http.Header{}, []byte(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDelivery, payload.DeliveryID)
+			assert.Equal(t, tt.wantUpserted, payload.UpsertedIDs)
+			assert.Equal(t, tt.wantDeleted, payload.DeletedIDs)
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	baseURL = "https://api.github.com"
+
+	// perPage is the page size for REST list endpoints (GitHub maximum)
+	perPage = 100
+
+	// maxFileBytes skips repository files larger than this
+	maxFileBytes = 1 << 20 // 1 MiB
+)
+
+// Client is a stateless GitHub API client.
+// Each method accepts the access token, making it suitable for the DataSourceProvider pattern.
+type Client struct {
+	httpClient *http.Client
+	log        *slog.Logger
+
+	// apiURL is the API base URL (overridable for tests and GitHub Enterprise)
+	apiURL string
+}
+
+// NewClient creates a new GitHub API client
+func NewClient(log *slog.Logger) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		log:    log.With(logger.Scope("github-client")),
+		apiURL: baseURL,
+	}
+}
+
+// ----------------------------------------------------------------------------
+// HTTP Helpers
+// ----------------------------------------------------------------------------
+
+// request makes an authenticated API request
+func (c *Client) request(ctx context.Context, token, method, urlStr string, payload any, accept string) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if accept == "" {
+		accept = "application/vnd.github+json"
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			return nil, fmt.Errorf("rate limit exceeded, resets at %s", time.Unix(reset, 0).UTC().Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
+	}
+
+	return body, nil
+}
+
+// get makes a GET request and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, token, urlStr string, out any) error {
+	body, err := c.request(ctx, token, http.MethodGet, urlStr, nil, "")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
+
+// repoURL builds a REST URL under /repos/{owner}/{name}
+func (c *Client) repoURL(owner, name, path string) string {
+	return fmt.Sprintf("%s/repos/%s/%s%s", c.apiURL, url.PathEscape(owner), url.PathEscape(name), path)
+}
+
+// ----------------------------------------------------------------------------
+// REST API Methods
+// ----------------------------------------------------------------------------
+
+// GetRepository retrieves repository details
+func (c *Client) GetRepository(ctx context.Context, token, owner, name string) (*Repository, error) {
+	var repo Repository
+	if err := c.get(ctx, token, c.repoURL(owner, name, ""), &repo); err != nil {
+		c.log.Error("failed to get repository", logger.Error(err), slog.String("repository", owner+"/"+name))
+		return nil, fmt.Errorf("get repository: %w", err)
+	}
+	return &repo, nil
+}
+
+// ListIssues retrieves one page of issues and pull requests, oldest update first.
+// A non-zero since only returns items updated at or after that time.
+func (c *Client) ListIssues(ctx context.Context, token, owner, name string, since time.Time, page int) ([]Issue, error) {
+	u, _ := url.Parse(c.repoURL(owner, name, "/issues"))
+	q := u.Query()
+	q.Set("state", "all")
+	q.Set("sort", "updated")
+	q.Set("direction", "asc")
+	q.Set("per_page", strconv.Itoa(perPage))
+	q.Set("page", strconv.Itoa(page))
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	u.RawQuery = q.Encode()
+
+	var issues []Issue
+	if err := c.get(ctx, token, u.String(), &issues); err != nil {
+		return nil, fmt.Errorf("list issues: %w", err)
+	}
+	return issues, nil
+}
+
+// GetIssue retrieves a single issue or pull request by number
+func (c *Client) GetIssue(ctx context.Context, token, owner, name string, number int) (*Issue, error) {
+	var issue Issue
+	if err := c.get(ctx, token, c.repoURL(owner, name, fmt.Sprintf("/issues/%d", number)), &issue); err != nil {
+		return nil, fmt.Errorf("get issue %d: %w", number, err)
+	}
+	return &issue, nil
+}
+
+// GetPullRequest retrieves the pull-request-only fields for a number
+func (c *Client) GetPullRequest(ctx context.Context, token, owner, name string, number int) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.get(ctx, token, c.repoURL(owner, name, fmt.Sprintf("/pulls/%d", number)), &pr); err != nil {
+		return nil, fmt.Errorf("get pull request %d: %w", number, err)
+	}
+	return &pr, nil
+}
+
+// ListIssueComments retrieves all comments on an issue or pull request
+func (c *Client) ListIssueComments(ctx context.Context, token, owner, name string, number int) ([]IssueComment, error) {
+	var all []IssueComment
+	for page := 1; ; page++ {
+		u := c.repoURL(owner, name, fmt.Sprintf("/issues/%d/comments?per_page=%d&page=%d", number, perPage, page))
+
+		var comments []IssueComment
+		if err := c.get(ctx, token, u, &comments); err != nil {
+			return nil, fmt.Errorf("list comments for %d: %w", number, err)
+		}
+		all = append(all, comments...)
+		if len(comments) < perPage {
+			return all, nil
+		}
+	}
+}
+
+// GetTree retrieves the recursive file tree for a branch
+func (c *Client) GetTree(ctx context.Context, token, owner, name, branch string) (*Tree, error) {
+	u := c.repoURL(owner, name, fmt.Sprintf("/git/trees/%s?recursive=1", url.PathEscape(branch)))
+
+	var tree Tree
+	if err := c.get(ctx, token, u, &tree); err != nil {
+		return nil, fmt.Errorf("get tree: %w", err)
+	}
+	return &tree, nil
+}
+
+// GetBlob retrieves the raw content of a blob by SHA
+func (c *Client) GetBlob(ctx context.Context, token, owner, name, sha string) ([]byte, error) {
+	u := c.repoURL(owner, name, "/git/blobs/"+url.PathEscape(sha))
+
+	body, err := c.request(ctx, token, http.MethodGet, u, nil, "application/vnd.github.raw")
+	if err != nil {
+		return nil, fmt.Errorf("get blob %s: %w", sha, err)
+	}
+	return body, nil
+}
+
+// ----------------------------------------------------------------------------
+// GraphQL API Methods
+// ----------------------------------------------------------------------------
+
+const discussionFields = `fragment DiscussionFields on Discussion {
+  number title body url createdAt updatedAt
+  author { login }
+  category { name }
+  comments(first: 100) { nodes { body createdAt author { login } } }
+}`
+
+const discussionsQuery = `query($owner: String!, $name: String!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 50, after: $after, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      nodes { ...DiscussionFields }
+      pageInfo { endCursor hasNextPage }
+    }
+  }
+}
+` + discussionFields
+
+const discussionQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    discussion(number: $number) { ...DiscussionFields }
+  }
+}
+` + discussionFields
+
+// graphql runs a GraphQL query and decodes the "data" field into out
+func (c *Client) graphql(ctx context.Context, token, query string, variables map[string]any, out any) error {
+	body, err := c.request(ctx, token, http.MethodPost, c.apiURL+"/graphql",
+		map[string]any{"query": query, "variables": variables}, "")
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("parse data: %w", err)
+	}
+	return nil
+}
+
+// ListDiscussions retrieves one page of discussions, most recently updated first
+func (c *Client) ListDiscussions(ctx context.Context, token, owner, name, after string) (*DiscussionsPage, error) {
+	variables := map[string]any{"owner": owner, "name": name}
+	if after != "" {
+		variables["after"] = after
+	}
+
+	var data struct {
+		Repository struct {
+			Discussions struct {
+				Nodes    []Discussion `json:"nodes"`
+				PageInfo struct {
+					EndCursor   string `json:"endCursor"`
+					HasNextPage bool   `json:"hasNextPage"`
+				} `json:"pageInfo"`
+			} `json:"discussions"`
+		} `json:"repository"`
+	}
+	if err := c.graphql(ctx, token, discussionsQuery, variables, &data); err != nil {
+		return nil, fmt.Errorf("list discussions: %w", err)
+	}
+
+	d := data.Repository.Discussions
+	return &DiscussionsPage{
+		Discussions: d.Nodes,
+		EndCursor:   d.PageInfo.EndCursor,
+		HasNextPage: d.PageInfo.HasNextPage,
+	}, nil
+}
+
+// GetDiscussion retrieves a single discussion by number
+func (c *Client) GetDiscussion(ctx context.Context, token, owner, name string, number int) (*Discussion, error) {
+	var data struct {
+		Repository struct {
+			Discussion *Discussion `json:"discussion"`
+		} `json:"repository"`
+	}
+	variables := map[string]any{"owner": owner, "name": name, "number": number}
+	if err := c.graphql(ctx, token, discussionQuery, variables, &data); err != nil {
+		return nil, fmt.Errorf("get discussion %d: %w", number, err)
+	}
+	if data.Repository.Discussion == nil {
+		return nil, fmt.Errorf("discussion %d not found", number)
+	}
+	return data.Repository.Discussion, nil
+}
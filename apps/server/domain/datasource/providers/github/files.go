@@ -0,0 +1,173 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/emergent-company/emergent.memory/domain/documents"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// markdownExtensions are the file extensions imported from repositories
+var markdownExtensions = map[string]bool{
+	".md":       true,
+	".mdx":      true,
+	".markdown": true,
+}
+
+// isImportableFile reports whether a tree entry is a markdown file under one of the paths
+func isImportableFile(entry TreeEntry, paths []string) bool {
+	if entry.Type != "blob" || entry.Size > maxFileBytes {
+		return false
+	}
+	if !markdownExtensions[strings.ToLower(path.Ext(entry.Path))] {
+		return false
+	}
+	if len(paths) == 0 {
+		return true
+	}
+	for _, prefix := range paths {
+		prefix = strings.Trim(prefix, "/")
+		if prefix == "" || entry.Path == prefix || strings.HasPrefix(entry.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveBranch returns the configured branch or the repository default branch
+func (p *Provider) resolveBranch(ctx context.Context, run *syncRun) (string, error) {
+	if run.config.Branch != "" {
+		return run.config.Branch, nil
+	}
+	repo, err := p.client.GetRepository(ctx, run.token, run.owner, run.name)
+	if err != nil {
+		return "", err
+	}
+	return repo.DefaultBranch, nil
+}
+
+// syncFiles imports markdown files from the branch. Files are compared by blob
+// SHA, so unchanged files are skipped without downloading them. Documents for
+// files no longer in the tree are removed.
+func (p *Provider) syncFiles(ctx context.Context, run *syncRun, fullSync bool) error {
+	run.progress("syncing", "Importing repository files...")
+
+	branch, err := p.resolveBranch(ctx, run)
+	if err != nil {
+		run.result.Errors = append(run.result.Errors, err.Error())
+		return err
+	}
+
+	tree, err := p.client.GetTree(ctx, run.token, run.owner, run.name, branch)
+	if err != nil {
+		run.result.Errors = append(run.result.Errors, err.Error())
+		return err
+	}
+
+	present := make(map[string]bool)
+	for _, entry := range tree.Tree {
+		if !isImportableFile(entry, run.config.Paths) {
+			continue
+		}
+		present[fileGitHubID(run.owner, run.name, entry.Path)] = true
+
+		select {
+		case <-ctx.Done():
+			run.result.Errors = append(run.result.Errors, "sync cancelled")
+			return ctx.Err()
+		default:
+		}
+		if run.full() {
+			return nil
+		}
+
+		run.result.TotalItems++
+		docID, skipped, err := p.importFile(ctx, run, branch, entry, fullSync)
+		run.record(entry.Path, docID, skipped, err)
+	}
+
+	// A truncated tree is incomplete, so absence doesn't mean deletion
+	if tree.Truncated {
+		p.log.Warn("repository tree truncated, skipping removal of deleted files",
+			slog.String("repository", run.config.Repository))
+		return nil
+	}
+
+	p.removeStaleFiles(ctx, run, present)
+	return nil
+}
+
+// importFile imports a single markdown file
+func (p *Provider) importFile(ctx context.Context, run *syncRun, branch string, entry TreeEntry, force bool) (string, bool, error) {
+	githubID := fileGitHubID(run.owner, run.name, entry.Path)
+
+	if !force {
+		existing, err := p.findExisting(ctx, run.projectID, run.integrationID, githubID)
+		if err != nil {
+			return "", false, err
+		}
+		if existing != nil {
+			if sha, ok := existing.Metadata["githubBlobSha"].(string); ok && sha == entry.SHA {
+				return existing.ID, true, nil
+			}
+		}
+	}
+
+	content, err := p.client.GetBlob(ctx, run.token, run.owner, run.name, entry.SHA)
+	if err != nil {
+		return "", false, err
+	}
+
+	metadata := DocumentMetadata{
+		GitHubID:   githubID,
+		Repository: run.owner + "/" + run.name,
+		ItemType:   "file",
+		URL:        fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", run.owner, run.name, branch, entry.Path),
+		Path:       entry.Path,
+		Branch:     branch,
+		BlobSHA:    entry.SHA,
+		Provider:   ProviderTypeGitHub,
+	}
+
+	docID, _, err := p.upsertDocument(ctx, run, SourceTypeGitHubFile, entry.Path, string(content), metadata, "", "")
+	return docID, false, err
+}
+
+// removeStaleFiles deletes file documents whose path is no longer in the tree
+func (p *Provider) removeStaleFiles(ctx context.Context, run *syncRun, present map[string]bool) {
+	var docs []documents.Document
+	err := p.db.NewSelect().
+		Model(&docs).
+		Column("id", "metadata").
+		Where("project_id = ?", run.projectID).
+		Where("data_source_integration_id = ?", run.integrationID).
+		Where("source_type = ?", SourceTypeGitHubFile).
+		Scan(ctx)
+	if err != nil {
+		p.log.Warn("failed to list file documents", logger.Error(err))
+		return
+	}
+
+	for _, doc := range docs {
+		githubID, _ := doc.Metadata["githubId"].(string)
+		if githubID == "" || present[githubID] {
+			continue
+		}
+		if _, err := p.docRepo.DeleteWithCascade(ctx, run.projectID, doc.ID); err != nil {
+			p.log.Warn("failed to delete document for removed file",
+				logger.Error(err),
+				slog.String("github_id", githubID))
+			continue
+		}
+		p.log.Debug("deleted document for removed file", slog.String("github_id", githubID))
+	}
+}
+
+// fileGitHubID is the document ID key for a repository file
+func fileGitHubID(owner, name, filePath string) string {
+	return fmt.Sprintf("%s/%s:%s", owner, name, filePath)
+}
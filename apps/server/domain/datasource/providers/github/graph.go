@@ -0,0 +1,198 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// Graph object types created for GitHub items
+const (
+	ObjectTypeIssue       = "GitHubIssue"
+	ObjectTypePullRequest = "GitHubPullRequest"
+	ObjectTypeDiscussion  = "GitHubDiscussion"
+	ObjectTypeUser        = "GitHubUser"
+	ObjectTypeLabel       = "GitHubLabel"
+)
+
+// Graph relationship types created for GitHub items
+const (
+	RelAuthoredBy = "AUTHORED_BY"
+	RelAssignedTo = "ASSIGNED_TO"
+	RelHasLabel   = "HAS_LABEL"
+	RelReferences = "REFERENCES"
+)
+
+// importedItem is an item mapped into the graph during a sync, kept for the
+// cross-reference pass
+type importedItem struct {
+	objectID   string
+	references []int
+}
+
+// referencePattern matches "#12", "owner/repo#12" and issue/pull URLs
+var referencePattern = regexp.MustCompile(
+	`(?:https://github\.com/([\w.-]+/[\w.-]+)/(?:issues|pull)/(\d+))|(?:(?:^|[^\w/#&])(?:([\w.-]+/[\w.-]+))?#(\d+)\b)`)
+
+// parseReferences extracts the issue and pull request numbers in the same
+// repository referenced by the texts, excluding self. Sorted and de-duplicated.
+func parseReferences(owner, name string, self int, texts ...string) []int {
+	repo := strings.ToLower(owner + "/" + name)
+	seen := make(map[int]bool)
+	var refs []int
+
+	for _, text := range texts {
+		for _, m := range referencePattern.FindAllStringSubmatch(text, -1) {
+			refRepo, num := m[1], m[2]
+			if num == "" {
+				refRepo, num = m[3], m[4]
+			}
+			if refRepo != "" && strings.ToLower(refRepo) != repo {
+				continue
+			}
+			n, err := strconv.Atoi(num)
+			if err != nil || n <= 0 || n == self || seen[n] {
+				continue
+			}
+			seen[n] = true
+			refs = append(refs, n)
+		}
+	}
+
+	sort.Ints(refs)
+	return refs
+}
+
+// mapIssueToGraph upserts the graph object for an issue or pull request and
+// links it to its author, assignees and labels. Graph failures are logged and
+// don't fail the document import.
+func (p *Provider) mapIssueToGraph(ctx context.Context, run *syncRun, issue *Issue, metadata DocumentMetadata, docID string) {
+	if p.graph == nil || !run.config.GraphEnabled() {
+		return
+	}
+
+	objType := ObjectTypeIssue
+	if issue.IsPullRequest() {
+		objType = ObjectTypePullRequest
+	}
+
+	objectID, err := p.graph.UpsertObject(ctx, run.projectID, objType, metadata.GitHubID, map[string]any{
+		"name":        fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+		"title":       issue.Title,
+		"number":      issue.Number,
+		"state":       metadata.State,
+		"url":         issue.HTMLURL,
+		"repository":  metadata.Repository,
+		"document_id": docID,
+	})
+	if err != nil {
+		p.log.Warn("failed to upsert graph object", logger.Error(err), slog.String("github_id", metadata.GitHubID))
+		return
+	}
+
+	p.relateUser(ctx, run, objectID, RelAuthoredBy, issue.User.Login)
+	for _, a := range issue.Assignees {
+		p.relateUser(ctx, run, objectID, RelAssignedTo, a.Login)
+	}
+	for _, l := range issue.Labels {
+		p.relateLabel(ctx, run, objectID, l)
+	}
+
+	run.imported = append(run.imported, importedItem{objectID: objectID, references: metadata.References})
+}
+
+// mapDiscussionToGraph upserts the graph object for a discussion and links it to its author
+func (p *Provider) mapDiscussionToGraph(ctx context.Context, run *syncRun, d *Discussion, metadata DocumentMetadata, docID string) {
+	if p.graph == nil || !run.config.GraphEnabled() {
+		return
+	}
+
+	objectID, err := p.graph.UpsertObject(ctx, run.projectID, ObjectTypeDiscussion, metadata.GitHubID, map[string]any{
+		"name":        d.Title,
+		"title":       d.Title,
+		"number":      d.Number,
+		"category":    d.Category.Name,
+		"url":         d.URL,
+		"repository":  metadata.Repository,
+		"document_id": docID,
+	})
+	if err != nil {
+		p.log.Warn("failed to upsert graph object", logger.Error(err), slog.String("github_id", metadata.GitHubID))
+		return
+	}
+
+	p.relateUser(ctx, run, objectID, RelAuthoredBy, d.AuthorLogin())
+	run.imported = append(run.imported, importedItem{objectID: objectID, references: metadata.References})
+}
+
+// relateUser links an object to the graph object for a GitHub user
+func (p *Provider) relateUser(ctx context.Context, run *syncRun, objectID, relType, login string) {
+	if login == "" {
+		return
+	}
+	userID, err := p.graph.UpsertObject(ctx, run.projectID, ObjectTypeUser, "github:"+strings.ToLower(login), map[string]any{
+		"name":  login,
+		"login": login,
+		"url":   "https://github.com/" + login,
+	})
+	if err == nil {
+		err = p.graph.Relate(ctx, run.projectID, relType, objectID, userID)
+	}
+	if err != nil {
+		p.log.Warn("failed to link user", logger.Error(err), slog.String("login", login))
+	}
+}
+
+// relateLabel links an object to the graph object for a repository label
+func (p *Provider) relateLabel(ctx context.Context, run *syncRun, objectID string, label Label) {
+	key := fmt.Sprintf("%s/%s:label:%s", run.owner, run.name, strings.ToLower(label.Name))
+	labelID, err := p.graph.UpsertObject(ctx, run.projectID, ObjectTypeLabel, key, map[string]any{
+		"name":        label.Name,
+		"color":       label.Color,
+		"description": label.Description,
+		"repository":  run.owner + "/" + run.name,
+	})
+	if err == nil {
+		err = p.graph.Relate(ctx, run.projectID, RelHasLabel, objectID, labelID)
+	}
+	if err != nil {
+		p.log.Warn("failed to link label", logger.Error(err), slog.String("label", label.Name))
+	}
+}
+
+// linkReferences creates REFERENCES relationships for cross-references found
+// during the sync. Runs after all items are imported so references between
+// items in the same sync resolve; targets that were never imported are skipped.
+func (p *Provider) linkReferences(ctx context.Context, run *syncRun) {
+	if p.graph == nil || len(run.imported) == 0 {
+		return
+	}
+
+	targets := make(map[int]string)
+	for _, item := range run.imported {
+		for _, n := range item.references {
+			targetID, ok := targets[n]
+			if !ok {
+				var err error
+				targetID, err = p.graph.FindObject(ctx, run.projectID,
+					[]string{ObjectTypeIssue, ObjectTypePullRequest}, issueGitHubID(run.owner, run.name, n))
+				if err != nil {
+					p.log.Warn("failed to resolve reference", logger.Error(err), slog.Int("number", n))
+				}
+				targets[n] = targetID
+			}
+			if targetID == "" || targetID == item.objectID {
+				continue
+			}
+			if err := p.graph.Relate(ctx, run.projectID, RelReferences, item.objectID, targetID); err != nil {
+				p.log.Warn("failed to link reference", logger.Error(err), slog.Int("number", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// syncIssues imports issues and pull requests updated since the given time.
+// They are listed oldest update first, so the cursor can resume right after
+// the last one imported before a failure or the item limit.
+func (p *Provider) syncIssues(ctx context.Context, run *syncRun, since time.Time) error {
+	run.progress("syncing", "Importing issues and pull requests...")
+
+	resume := &resumePoint{run: run}
+
+	for page := 1; ; page++ {
+		issues, err := p.client.ListIssues(ctx, run.token, run.owner, run.name, since, page)
+		if err != nil {
+			run.result.Errors = append(run.result.Errors, err.Error())
+			return err
+		}
+
+		for i := range issues {
+			select {
+			case <-ctx.Done():
+				run.result.Errors = append(run.result.Errors, "sync cancelled")
+				return ctx.Err()
+			default:
+			}
+
+			issue := &issues[i]
+			if issue.IsPullRequest() && !run.config.PullRequestsEnabled() ||
+				!issue.IsPullRequest() && !run.config.IssuesEnabled() {
+				continue
+			}
+			if run.full() {
+				resume.truncated()
+				return nil
+			}
+
+			run.result.TotalItems++
+			docID, skipped, err := p.importIssue(ctx, run, issue)
+			run.record(fmt.Sprintf("#%d", issue.Number), docID, skipped, err)
+			resume.record(issue.UpdatedAt, err)
+		}
+
+		run.progress("syncing", fmt.Sprintf("Processed %d items", run.result.ProcessedItems))
+
+		if len(issues) < perPage {
+			return nil
+		}
+	}
+}
+
+// importIssue imports a single issue or pull request with its comments.
+// Returns the document ID, whether it was skipped, and any error
+func (p *Provider) importIssue(ctx context.Context, run *syncRun, issue *Issue) (string, bool, error) {
+	metadata := DocumentMetadata{
+		GitHubID:        issueGitHubID(run.owner, run.name, issue.Number),
+		Repository:      run.owner + "/" + run.name,
+		ItemType:        "issue",
+		Number:          issue.Number,
+		URL:             issue.HTMLURL,
+		State:           issue.State,
+		Author:          issue.User.Login,
+		CommentCount:    issue.Comments,
+		GitHubCreatedAt: issue.CreatedAt.UTC().Format(time.RFC3339),
+		GitHubUpdatedAt: issue.UpdatedAt.UTC().Format(time.RFC3339),
+		Provider:        ProviderTypeGitHub,
+	}
+	for _, a := range issue.Assignees {
+		metadata.Assignees = append(metadata.Assignees, a.Login)
+	}
+	for _, l := range issue.Labels {
+		metadata.Labels = append(metadata.Labels, l.Name)
+	}
+
+	// Cheap pre-check before fetching comments and PR details
+	existing, err := p.findExisting(ctx, run.projectID, run.integrationID, metadata.GitHubID)
+	if err != nil {
+		return "", false, err
+	}
+	if existing != nil {
+		if stored, ok := existing.Metadata["githubUpdatedAt"].(string); ok && stored == metadata.GitHubUpdatedAt {
+			return existing.ID, true, nil
+		}
+	}
+
+	sourceType := SourceTypeGitHubIssue
+	var pr *PullRequest
+	if issue.IsPullRequest() {
+		sourceType = SourceTypeGitHubPullRequest
+		metadata.ItemType = "pull_request"
+		pr, err = p.client.GetPullRequest(ctx, run.token, run.owner, run.name, issue.Number)
+		if err != nil {
+			p.log.Warn("failed to fetch pull request details",
+				logger.Error(err),
+				slog.Int("number", issue.Number))
+		} else {
+			metadata.Merged = pr.Merged
+			metadata.Draft = pr.Draft
+			metadata.BaseBranch = pr.Base.Ref
+			metadata.HeadBranch = pr.Head.Ref
+			if pr.Merged {
+				metadata.State = "merged"
+			}
+		}
+	}
+
+	var comments []IssueComment
+	if issue.Comments > 0 {
+		comments, err = p.client.ListIssueComments(ctx, run.token, run.owner, run.name, issue.Number)
+		if err != nil {
+			p.log.Warn("failed to fetch comments",
+				logger.Error(err),
+				slog.Int("number", issue.Number))
+		}
+	}
+
+	refTexts := []string{issue.Body}
+	for _, c := range comments {
+		refTexts = append(refTexts, c.Body)
+	}
+	metadata.References = parseReferences(run.owner, run.name, issue.Number, refTexts...)
+
+	content := buildIssueContent(run.owner+"/"+run.name, issue, pr, comments)
+	title := fmt.Sprintf("#%d %s", issue.Number, issue.Title)
+
+	docID, _, err := p.upsertDocument(ctx, run, sourceType, title, content, metadata, "", "")
+	if err != nil {
+		return "", false, err
+	}
+
+	p.mapIssueToGraph(ctx, run, issue, metadata, docID)
+	return docID, false, nil
+}
+
+// buildIssueContent renders an issue or pull request and its comments as markdown
+func buildIssueContent(repository string, issue *Issue, pr *PullRequest, comments []IssueComment) string {
+	var sb strings.Builder
+
+	kind := "Issue"
+	if issue.IsPullRequest() {
+		kind = "Pull Request"
+	}
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", issue.Title))
+	sb.WriteString(fmt.Sprintf("**%s:** %s#%d\n", kind, repository, issue.Number))
+
+	state := issue.State
+	if pr != nil && pr.Merged {
+		state = "merged"
+	}
+	sb.WriteString(fmt.Sprintf("**State:** %s\n", state))
+	sb.WriteString(fmt.Sprintf("**Author:** @%s\n", issue.User.Login))
+
+	if len(issue.Assignees) > 0 {
+		logins := make([]string, len(issue.Assignees))
+		for i, a := range issue.Assignees {
+			logins[i] = "@" + a.Login
+		}
+		sb.WriteString(fmt.Sprintf("**Assignees:** %s\n", strings.Join(logins, ", ")))
+	}
+	if len(issue.Labels) > 0 {
+		names := make([]string, len(issue.Labels))
+		for i, l := range issue.Labels {
+			names[i] = l.Name
+		}
+		sb.WriteString(fmt.Sprintf("**Labels:** %s\n", strings.Join(names, ", ")))
+	}
+	if pr != nil {
+		sb.WriteString(fmt.Sprintf("**Branches:** %s → %s\n", pr.Head.Ref, pr.Base.Ref))
+	}
+	sb.WriteString(fmt.Sprintf("**Created:** %s\n", issue.CreatedAt.UTC().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("**URL:** %s\n", issue.HTMLURL))
+
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		sb.WriteString("\n")
+		sb.WriteString(body)
+		sb.WriteString("\n")
+	}
+
+	if len(comments) > 0 {
+		sb.WriteString("\n## Comments\n")
+		for _, c := range comments {
+			sb.WriteString(fmt.Sprintf("\n### @%s — %s\n\n", c.User.Login, c.CreatedAt.UTC().Format(time.RFC3339)))
+			sb.WriteString(strings.TrimSpace(c.Body))
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// syncDiscussions imports discussions updated since the given time
+func (p *Provider) syncDiscussions(ctx context.Context, run *syncRun, since time.Time) error {
+	run.progress("syncing", "Importing discussions...")
+
+	after := ""
+	for {
+		page, err := p.client.ListDiscussions(ctx, run.token, run.owner, run.name, after)
+		if err != nil {
+			run.result.Errors = append(run.result.Errors, err.Error())
+			return err
+		}
+
+		for i := range page.Discussions {
+			d := &page.Discussions[i]
+
+			// Newest first, so everything after this is older than the cursor
+			if !since.IsZero() && d.UpdatedAt.Before(since) {
+				return nil
+			}
+			if run.full() {
+				// Older discussions were not reached
+				run.holdCursor(time.Time{})
+				return nil
+			}
+
+			run.result.TotalItems++
+			docID, skipped, err := p.importDiscussion(ctx, run, d)
+			run.record(fmt.Sprintf("discussion %d", d.Number), docID, skipped, err)
+			if err != nil {
+				// Listed newest first, so the cursor cannot resume between them
+				run.holdCursor(time.Time{})
+			}
+		}
+
+		if !page.HasNextPage {
+			return nil
+		}
+		after = page.EndCursor
+	}
+}
+
+// importDiscussion imports a single discussion with its top-level comments
+func (p *Provider) importDiscussion(ctx context.Context, run *syncRun, d *Discussion) (string, bool, error) {
+	texts := []string{d.Body}
+	for _, c := range d.Comments.Nodes {
+		texts = append(texts, c.Body)
+	}
+
+	metadata := DocumentMetadata{
+		GitHubID:        discussionGitHubID(run.owner, run.name, d.Number),
+		Repository:      run.owner + "/" + run.name,
+		ItemType:        "discussion",
+		Number:          d.Number,
+		URL:             d.URL,
+		Author:          d.AuthorLogin(),
+		Category:        d.Category.Name,
+		CommentCount:    len(d.Comments.Nodes),
+		References:      parseReferences(run.owner, run.name, 0, texts...),
+		GitHubCreatedAt: d.CreatedAt.UTC().Format(time.RFC3339),
+		GitHubUpdatedAt: d.UpdatedAt.UTC().Format(time.RFC3339),
+		Provider:        ProviderTypeGitHub,
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", d.Title))
+	sb.WriteString(fmt.Sprintf("**Discussion:** %s/%s #%d\n", run.owner, run.name, d.Number))
+	sb.WriteString(fmt.Sprintf("**Category:** %s\n", d.Category.Name))
+	sb.WriteString(fmt.Sprintf("**Author:** @%s\n", d.AuthorLogin()))
+	sb.WriteString(fmt.Sprintf("**URL:** %s\n", d.URL))
+	if body := strings.TrimSpace(d.Body); body != "" {
+		sb.WriteString("\n" + body + "\n")
+	}
+	if len(d.Comments.Nodes) > 0 {
+		sb.WriteString("\n## Comments\n")
+		for _, c := range d.Comments.Nodes {
+			author := "ghost"
+			if c.Author != nil {
+				author = c.Author.Login
+			}
+			sb.WriteString(fmt.Sprintf("\n### @%s — %s\n\n", author, c.CreatedAt.UTC().Format(time.RFC3339)))
+			sb.WriteString(strings.TrimSpace(c.Body) + "\n")
+		}
+	}
+
+	title := fmt.Sprintf("Discussion #%d %s", d.Number, d.Title)
+	docID, skipped, err := p.upsertDocument(ctx, run, SourceTypeGitHubDiscussion, title, sb.String(), metadata, "githubUpdatedAt", metadata.GitHubUpdatedAt)
+	if err != nil || skipped {
+		return docID, skipped, err
+	}
+
+	p.mapDiscussionToGraph(ctx, run, d, metadata, docID)
+	return docID, false, nil
+}
+
+// issueGitHubID is the document ID key for an issue or pull request
+func issueGitHubID(owner, name string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", owner, name, number)
+}
+
+// discussionGitHubID is the document ID key for a discussion
+func discussionGitHubID(owner, name string, number int) string {
+	return fmt.Sprintf("%s/%s/discussions/%d", owner, name, number)
+}
@@ -0,0 +1,472 @@
+package github
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/domain/documents"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	ProviderTypeGitHub = "github"
+
+	SourceTypeGitHubIssue       = "github-issue"
+	SourceTypeGitHubPullRequest = "github-pull-request"
+	SourceTypeGitHubDiscussion  = "github-discussion"
+	SourceTypeGitHubFile        = "github-file"
+)
+
+// ProviderConfig contains the decrypted configuration for a provider
+// Mirrors datasource.ProviderConfig to avoid import cycle
+type ProviderConfig struct {
+	IntegrationID string
+	ProjectID     string
+	Config        map[string]interface{}
+	Metadata      map[string]interface{}
+	LastSyncedAt  *time.Time
+}
+
+// SyncOptions contains options for a sync operation
+// Mirrors datasource.SyncOptions to avoid import cycle
+type SyncOptions struct {
+	Limit           int
+	FullSync        bool
+	ConfigurationID string
+	Custom          map[string]interface{}
+	ItemIDs         []string
+	DeletedItemIDs  []string
+}
+
+// SyncResult contains the results of a sync operation
+// Mirrors datasource.SyncResult to avoid import cycle
+type SyncResult struct {
+	TotalItems      int
+	ProcessedItems  int
+	SuccessfulItems int
+	FailedItems     int
+	SkippedItems    int
+	DocumentIDs     []string
+	Errors          []string
+
+	// Cursor is where the next incremental sync resumes (nil = the sync start
+	// time, zero = keep the previous cursor)
+	Cursor *time.Time
+}
+
+// Progress represents the current progress of a sync operation
+// Mirrors datasource.Progress to avoid import cycle
+type Progress struct {
+	Phase           string
+	TotalItems      int
+	ProcessedItems  int
+	SuccessfulItems int
+	FailedItems     int
+	SkippedItems    int
+	Message         string
+}
+
+// ProgressCallback is called by providers to report sync progress
+type ProgressCallback func(progress Progress)
+
+// TokenSource supplies GitHub App installation tokens (implemented by githubapp.Service)
+type TokenSource interface {
+	GetInstallationToken(ctx context.Context) (string, error)
+}
+
+// GraphWriter upserts graph objects and relationships for imported items.
+// Implemented in the datasource module on top of graph.Service.
+type GraphWriter interface {
+	// UpsertObject creates or updates the object identified by (type, key) and returns its ID
+	UpsertObject(ctx context.Context, projectID, objType, key string, properties map[string]any) (string, error)
+
+	// FindObject returns the ID of the object with the given key and one of the types, or "" if none exists
+	FindObject(ctx context.Context, projectID string, types []string, key string) (string, error)
+
+	// Relate creates a relationship between two objects (idempotent)
+	Relate(ctx context.Context, projectID, relType, srcID, dstID string) error
+}
+
+// Provider implements the GitHub data source provider
+type Provider struct {
+	client  *Client
+	tokens  TokenSource
+	graph   GraphWriter
+	db      bun.IDB
+	docRepo *documents.Repository
+	log     *slog.Logger
+}
+
+// NewProvider creates a new GitHub provider. tokens and graph may be nil:
+// without tokens every integration needs an access token, and without
+// graph no graph objects are created.
+func NewProvider(db bun.IDB, tokens TokenSource, graph GraphWriter, log *slog.Logger) *Provider {
+	return &Provider{
+		client:  NewClient(log),
+		tokens:  tokens,
+		graph:   graph,
+		db:      db,
+		docRepo: documents.NewRepository(db, log),
+		log:     log.With(logger.Scope("github-provider")),
+	}
+}
+
+// ProviderType returns the provider type identifier
+func (p *Provider) ProviderType() string {
+	return ProviderTypeGitHub
+}
+
+// TestConnection checks that the repository is reachable with the configured credentials
+func (p *Provider) TestConnection(ctx context.Context, config ProviderConfig) error {
+	ghConfig, err := p.parseConfig(config.Config)
+	if err != nil {
+		return err
+	}
+	owner, name, err := ghConfig.OwnerAndName()
+	if err != nil {
+		return err
+	}
+
+	token, err := p.token(ctx, ghConfig)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.client.GetRepository(ctx, token, owner, name); err != nil {
+		return fmt.Errorf("connection test failed: %w", err)
+	}
+	return nil
+}
+
+// syncRun carries per-sync state shared by the import phases
+type syncRun struct {
+	config        *Config
+	token         string
+	owner         string
+	name          string
+	projectID     string
+	integrationID string
+	limit         int
+	result        *SyncResult
+	progressCB    ProgressCallback
+
+	// since is the incremental sync cursor the run started from (zero for a
+	// full sync)
+	since time.Time
+
+	// imported collects graph refs for the cross-reference pass
+	imported []importedItem
+}
+
+// full reports whether the item limit has been reached
+func (r *syncRun) full() bool {
+	return r.limit > 0 && r.result.ProcessedItems >= r.limit
+}
+
+// holdCursor keeps the next incremental sync from resuming after t, because
+// items updated after it were not all imported. A zero t keeps the previous
+// cursor.
+func (r *syncRun) holdCursor(t time.Time) {
+	if !t.IsZero() && !t.After(r.since) {
+		t = time.Time{}
+	}
+	if r.result.Cursor == nil || t.Before(*r.result.Cursor) {
+		r.result.Cursor = &t
+	}
+}
+
+// resumePoint follows items imported oldest update first and holds the run's
+// cursor at the last one imported before the first failure, so the failed
+// item and those after it are fetched again by the next incremental sync.
+type resumePoint struct {
+	run    *syncRun
+	last   time.Time
+	failed bool
+}
+
+// record notes the outcome of importing an item updated at updated.
+func (p *resumePoint) record(updated time.Time, err error) {
+	switch {
+	case p.failed:
+	case err != nil:
+		p.failed = true
+		p.run.holdCursor(p.last)
+	default:
+		p.last = updated
+	}
+}
+
+// truncated holds the cursor at the last item imported when the run stops
+// before reaching the most recently updated items.
+func (p *resumePoint) truncated() {
+	if !p.failed {
+		p.run.holdCursor(p.last)
+	}
+}
+
+// record updates the result for one processed item
+func (r *syncRun) record(label, docID string, skipped bool, err error) {
+	r.result.ProcessedItems++
+	switch {
+	case err != nil:
+		r.result.FailedItems++
+		r.result.Errors = append(r.result.Errors, fmt.Sprintf("%s: %s", label, err.Error()))
+	case skipped:
+		r.result.SkippedItems++
+	default:
+		r.result.SuccessfulItems++
+		if docID != "" {
+			r.result.DocumentIDs = append(r.result.DocumentIDs, docID)
+		}
+	}
+}
+
+// progress reports the current counts for a phase
+func (r *syncRun) progress(phase, message string) {
+	if r.progressCB == nil {
+		return
+	}
+	r.progressCB(Progress{
+		Phase:           phase,
+		TotalItems:      r.result.TotalItems,
+		ProcessedItems:  r.result.ProcessedItems,
+		SuccessfulItems: r.result.SuccessfulItems,
+		FailedItems:     r.result.FailedItems,
+		SkippedItems:    r.result.SkippedItems,
+		Message:         message,
+	})
+}
+
+// Sync imports issues, pull requests, discussions and files from the repository
+func (p *Provider) Sync(ctx context.Context, config ProviderConfig, options SyncOptions, progressCB ProgressCallback) (*SyncResult, error) {
+	ghConfig, err := p.parseConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{
+		DocumentIDs: []string{},
+		Errors:      []string{},
+	}
+
+	owner, name, err := ghConfig.OwnerAndName()
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result, err
+	}
+
+	token, err := p.token(ctx, ghConfig)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result, err
+	}
+
+	run := &syncRun{
+		config:        ghConfig,
+		token:         token,
+		owner:         owner,
+		name:          name,
+		projectID:     config.ProjectID,
+		integrationID: config.IntegrationID,
+		limit:         options.Limit,
+		result:        result,
+		progressCB:    progressCB,
+	}
+
+	// Webhook-triggered syncs only touch the items named in the delivery
+	if len(options.ItemIDs) > 0 || len(options.DeletedItemIDs) > 0 {
+		err := p.syncTargeted(ctx, run, options.ItemIDs, options.DeletedItemIDs)
+		return result, err
+	}
+
+	var since time.Time
+	if !options.FullSync && config.LastSyncedAt != nil {
+		since = *config.LastSyncedAt
+	}
+	run.since = since
+
+	run.progress("discovering", fmt.Sprintf("Syncing %s/%s...", owner, name))
+
+	if ghConfig.IssuesEnabled() || ghConfig.PullRequestsEnabled() {
+		if err := p.syncIssues(ctx, run, since); err != nil {
+			return result, err
+		}
+	}
+
+	if ghConfig.ImportDiscussions {
+		if run.full() {
+			// Discussions were not reached, so keep the cursor where they start
+			run.holdCursor(time.Time{})
+		} else if err := p.syncDiscussions(ctx, run, since); err != nil {
+			return result, err
+		}
+	}
+
+	if ghConfig.FilesEnabled() && !run.full() {
+		if err := p.syncFiles(ctx, run, options.FullSync); err != nil {
+			return result, err
+		}
+	}
+
+	p.linkReferences(ctx, run)
+
+	run.progress("completed", fmt.Sprintf("Sync complete: %d imported, %d skipped, %d failed",
+		result.SuccessfulItems, result.SkippedItems, result.FailedItems))
+
+	p.log.Info("github sync completed",
+		slog.String("repository", ghConfig.Repository),
+		slog.Int("total", result.TotalItems),
+		slog.Int("successful", result.SuccessfulItems),
+		slog.Int("failed", result.FailedItems),
+		slog.Int("skipped", result.SkippedItems))
+
+	if result.FailedItems > 0 && result.SuccessfulItems == 0 && result.SkippedItems == 0 {
+		return result, fmt.Errorf("sync failed for all %d items", result.FailedItems)
+	}
+
+	return result, nil
+}
+
+// token returns the access token for API calls: the configured personal
+// token if set, otherwise the GitHub App installation token
+func (p *Provider) token(ctx context.Context, config *Config) (string, error) {
+	if config.AccessToken != "" {
+		return config.AccessToken, nil
+	}
+	if p.tokens == nil {
+		return "", fmt.Errorf("no access token configured and GitHub App is not available")
+	}
+	token, err := p.tokens.GetInstallationToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get installation token: %w", err)
+	}
+	return token, nil
+}
+
+// parseConfig parses the provider configuration from a map
+func (p *Provider) parseConfig(config map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+
+	var ghConfig Config
+	if err := json.Unmarshal(data, &ghConfig); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if ghConfig.Repository == "" {
+		return nil, fmt.Errorf("repository is required")
+	}
+
+	return &ghConfig, nil
+}
+
+// findExisting finds an existing document by its GitHub ID
+func (p *Provider) findExisting(ctx context.Context, projectID, integrationID, githubID string) (*documents.Document, error) {
+	var doc documents.Document
+	err := p.db.NewSelect().
+		Model(&doc).
+		Where("project_id = ?", projectID).
+		Where("data_source_integration_id = ?", integrationID).
+		Where("metadata->>'githubId' = ?", githubID).
+		Limit(1).
+		Scan(ctx)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find existing doc: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// upsertDocument creates or updates the document for a GitHub item.
+// Returns the document ID and whether it was skipped because versionKey's
+// value in the stored metadata already matches.
+func (p *Provider) upsertDocument(ctx context.Context, run *syncRun, sourceType, title, content string, metadata DocumentMetadata, versionKey, version string) (string, bool, error) {
+	existing, err := p.findExisting(ctx, run.projectID, run.integrationID, metadata.GitHubID)
+	if err != nil {
+		return "", false, err
+	}
+
+	if existing != nil && version != "" {
+		if stored, ok := existing.Metadata[versionKey].(string); ok && stored == version {
+			return existing.ID, true, nil
+		}
+	}
+
+	metadataMap := make(map[string]any)
+	metaJSON, _ := json.Marshal(metadata)
+	json.Unmarshal(metaJSON, &metadataMap)
+
+	if existing != nil {
+		existing.Filename = &title
+		existing.Content = &content
+		existing.Metadata = metadataMap
+		existing.UpdatedAt = time.Now()
+
+		if _, err := p.db.NewUpdate().
+			Model(existing).
+			WherePK().
+			Exec(ctx); err != nil {
+			return "", false, fmt.Errorf("update document: %w", err)
+		}
+
+		p.log.Debug("updated document from GitHub item",
+			slog.String("document_id", existing.ID),
+			slog.String("github_id", metadata.GitHubID))
+		return existing.ID, false, nil
+	}
+
+	mimeType := "text/markdown"
+	conversionStatus := "not_required"
+	integrationID := run.integrationID
+
+	document := &documents.Document{
+		ID:                      uuid.New().String(),
+		ProjectID:               run.projectID,
+		Filename:                &title,
+		Content:                 &content,
+		MimeType:                &mimeType,
+		SourceType:              &sourceType,
+		DataSourceIntegrationID: &integrationID,
+		ConversionStatus:        &conversionStatus,
+		Metadata:                metadataMap,
+		CreatedAt:               time.Now(),
+		UpdatedAt:               time.Now(),
+	}
+
+	if err := p.docRepo.Create(ctx, document); err != nil {
+		return "", false, fmt.Errorf("create document: %w", err)
+	}
+
+	p.log.Debug("created document from GitHub item",
+		slog.String("document_id", document.ID),
+		slog.String("github_id", metadata.GitHubID))
+
+	return document.ID, false, nil
+}
+
+// deleteDocument removes the document for a GitHub item, if it exists.
+// Returns false if there was nothing to delete.
+func (p *Provider) deleteDocument(ctx context.Context, run *syncRun, githubID string) (bool, error) {
+	existing, err := p.findExisting(ctx, run.projectID, run.integrationID, githubID)
+	if err != nil || existing == nil {
+		return false, err
+	}
+	if _, err := p.docRepo.DeleteWithCascade(ctx, run.projectID, existing.ID); err != nil {
+		return false, fmt.Errorf("delete document: %w", err)
+	}
+	return true, nil
+}
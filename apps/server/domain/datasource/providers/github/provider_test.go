@@ -0,0 +1,410 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(testLogger())
+	client.apiURL = server.URL
+	return client
+}
+
+func TestConfig_OwnerAndName(t *testing.T) {
+	tests := []struct {
+		repository string
+		owner      string
+		name       string
+		wantErr    bool
+	}{
+		{repository: "octocat/hello-world", owner: "octocat", name: "hello-world"},
+		{repository: " /octocat/hello-world/ ", owner: "octocat", name: "hello-world"},
+		{repository: "octocat", wantErr: true},
+		{repository: "octocat/", wantErr: true},
+		{repository: "a/b/c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			cfg := &Config{Repository: tt.repository}
+			owner, name, err := cfg.OwnerAndName()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.owner, owner)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestConfig_Defaults(t *testing.T) {
+	cfg := &Config{}
+	assert.True(t, cfg.IssuesEnabled())
+	assert.True(t, cfg.PullRequestsEnabled())
+	assert.True(t, cfg.FilesEnabled())
+	assert.True(t, cfg.GraphEnabled())
+	assert.False(t, cfg.ImportDiscussions)
+
+	off := false
+	cfg = &Config{ImportIssues: &off, ImportFiles: &off}
+	assert.False(t, cfg.IssuesEnabled())
+	assert.False(t, cfg.FilesEnabled())
+}
+
+func TestClient_ListIssues(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/octocat/hello/issues", r.URL.Path)
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		assert.Equal(t, "all", r.URL.Query().Get("state"))
+		assert.Equal(t, "2024-03-01T12:00:00Z", r.URL.Query().Get("since"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+
+		w.Write([]byte(`[
+			{"number": 1, "title": "Bug", "state": "open", "user": {"login": "alice"}, "labels": [{"name": "bug"}]},
+			{"number": 2, "title": "Fix", "state": "closed", "user": {"login": "bob"}, "pull_request": {"url": "x"}}
+		]`))
+	})
+
+	issues, err := client.ListIssues(context.Background(), "tok", "octocat", "hello", since, 2)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.False(t, issues[0].IsPullRequest())
+	assert.Equal(t, "bug", issues[0].Labels[0].Name)
+	assert.True(t, issues[1].IsPullRequest())
+}
+
+func TestClient_ListIssueComments_Paginates(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/octocat/hello/issues/7/comments", r.URL.Path)
+
+		count := perPage
+		if r.URL.Query().Get("page") == "2" {
+			count = 3
+		}
+		comments := make([]IssueComment, count)
+		json.NewEncoder(w).Encode(comments)
+	})
+
+	comments, err := client.ListIssueComments(context.Background(), "tok", "octocat", "hello", 7)
+	require.NoError(t, err)
+	assert.Len(t, comments, perPage+3)
+}
+
+func TestClient_GetBlob(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/octocat/hello/git/blobs/abc123", r.URL.Path)
+		assert.Equal(t, "application/vnd.github.raw", r.Header.Get("Accept"))
+		w.Write([]byte("# Readme\n"))
+	})
+
+	content, err := client.GetBlob(context.Background(), "tok", "octocat", "hello", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "# Readme\n", string(content))
+}
+
+func TestClient_ListDiscussions(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graphql", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &req))
+		assert.Contains(t, req.Query, "fragment DiscussionFields")
+		assert.Equal(t, "cursor1", req.Variables["after"])
+
+		w.Write([]byte(`{"data": {"repository": {"discussions": {
+			"nodes": [{"number": 3, "title": "Idea", "author": {"login": "carol"}, "category": {"name": "Ideas"},
+				"comments": {"nodes": [{"body": "+1", "author": null}]}}],
+			"pageInfo": {"endCursor": "cursor2", "hasNextPage": true}
+		}}}}`))
+	})
+
+	page, err := client.ListDiscussions(context.Background(), "tok", "octocat", "hello", "cursor1")
+	require.NoError(t, err)
+	require.Len(t, page.Discussions, 1)
+	assert.Equal(t, "carol", page.Discussions[0].AuthorLogin())
+	assert.Equal(t, "Ideas", page.Discussions[0].Category.Name)
+	assert.Equal(t, "cursor2", page.EndCursor)
+	assert.True(t, page.HasNextPage)
+}
+
+func TestClient_GraphQLErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": null, "errors": [{"message": "Could not resolve to a Repository"}]}`))
+	})
+
+	_, err := client.GetDiscussion(context.Background(), "tok", "octocat", "missing", 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not resolve to a Repository")
+}
+
+func TestClient_RateLimitError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	_, err := client.GetRepository(context.Background(), "tok", "octocat", "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded")
+}
+
+func TestParseReferences(t *testing.T) {
+	text := "Fixes #12 and relates to octocat/hello#7, see https://github.com/octocat/hello/pull/30. " +
+		"Not other/repo#9, not a heading ## or color &#35;4, self #5, dup #12."
+
+	refs := parseReferences("octocat", "hello", 5, text, "Also #3")
+	assert.Equal(t, []int{3, 7, 12, 30}, refs)
+
+	assert.Empty(t, parseReferences("octocat", "hello", 0, "no refs here"))
+}
+
+func TestIsImportableFile(t *testing.T) {
+	tests := []struct {
+		entry TreeEntry
+		paths []string
+		want  bool
+	}{
+		{entry: TreeEntry{Path: "README.md", Type: "blob"}, want: true},
+		{entry: TreeEntry{Path: "docs/guide.MDX", Type: "blob"}, want: true},
+		{entry: TreeEntry{Path: "main.go", Type: "blob"}, want: false},
+		{entry: TreeEntry{Path: "docs", Type: "tree"}, want: false},
+		{entry: TreeEntry{Path: "big.md", Type: "blob", Size: maxFileBytes + 1}, want: false},
+		{entry: TreeEntry{Path: "docs/a.md", Type: "blob"}, paths: []string{"/docs/"}, want: true},
+		{entry: TreeEntry{Path: "docsite/a.md", Type: "blob"}, paths: []string{"docs"}, want: false},
+		{entry: TreeEntry{Path: "README.md", Type: "blob"}, paths: []string{"docs", "README.md"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry.Path, func(t *testing.T) {
+			assert.Equal(t, tt.want, isImportableFile(tt.entry, tt.paths))
+		})
+	}
+}
+
+func TestBuildIssueContent(t *testing.T) {
+	issue := &Issue{
+		Number:      4,
+		Title:       "Add feature",
+		Body:        "Body text",
+		State:       "closed",
+		HTMLURL:     "https://github.com/octocat/hello/pull/4",
+		User:        User{Login: "alice"},
+		Assignees:   []User{{Login: "bob"}},
+		Labels:      []Label{{Name: "enhancement"}},
+		PullRequest: &PullRequestRef{URL: "x"},
+	}
+	pr := &PullRequest{Merged: true, Head: BranchRef{Ref: "feature"}, Base: BranchRef{Ref: "main"}}
+	comments := []IssueComment{{Body: "LGTM", User: User{Login: "carol"}}}
+
+	content := buildIssueContent("octocat/hello", issue, pr, comments)
+
+	assert.True(t, strings.HasPrefix(content, "# Add feature\n"))
+	assert.Contains(t, content, "**Pull Request:** octocat/hello#4")
+	assert.Contains(t, content, "**State:** merged")
+	assert.Contains(t, content, "**Assignees:** @bob")
+	assert.Contains(t, content, "**Labels:** enhancement")
+	assert.Contains(t, content, "**Branches:** feature → main")
+	assert.Contains(t, content, "Body text")
+	assert.Contains(t, content, "### @carol")
+	assert.Contains(t, content, "LGTM")
+}
+
+func TestResumePoint_HoldsCursorBeforeFirstFailure(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return since.Add(time.Duration(hours) * time.Hour) }
+
+	t.Run("failed item in the middle", func(t *testing.T) {
+		run := &syncRun{result: &SyncResult{}, since: since}
+		resume := &resumePoint{run: run}
+		resume.record(at(1), nil)
+		resume.record(at(2), nil)
+		resume.record(at(3), assert.AnError)
+		resume.record(at(4), nil)
+
+		require.NotNil(t, run.result.Cursor)
+		assert.Equal(t, at(2), *run.result.Cursor)
+	})
+
+	t.Run("first item failed keeps the previous cursor", func(t *testing.T) {
+		run := &syncRun{result: &SyncResult{}, since: since}
+		resume := &resumePoint{run: run}
+		resume.record(at(1), assert.AnError)
+		resume.record(at(2), nil)
+
+		require.NotNil(t, run.result.Cursor)
+		assert.True(t, run.result.Cursor.IsZero())
+	})
+
+	t.Run("all imported leaves the sync start time", func(t *testing.T) {
+		run := &syncRun{result: &SyncResult{}, since: since}
+		resume := &resumePoint{run: run}
+		resume.record(at(1), nil)
+		resume.record(at(2), nil)
+
+		assert.Nil(t, run.result.Cursor)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		run := &syncRun{result: &SyncResult{}, since: since}
+		resume := &resumePoint{run: run}
+		resume.record(at(1), nil)
+		resume.truncated()
+
+		require.NotNil(t, run.result.Cursor)
+		assert.Equal(t, at(1), *run.result.Cursor)
+	})
+
+	t.Run("failed discussion keeps the previous cursor", func(t *testing.T) {
+		run := &syncRun{result: &SyncResult{}, since: since}
+		resume := &resumePoint{run: run}
+		resume.record(at(1), nil)
+		resume.truncated()
+		run.holdCursor(time.Time{})
+
+		require.NotNil(t, run.result.Cursor)
+		assert.True(t, run.result.Cursor.IsZero())
+	})
+}
+
+func TestProvider_VerifyWebhook(t *testing.T) {
+	p := &Provider{}
+	body := []byte(`{"action":"opened"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	headers := http.Header{}
+	headers.Set(SignatureHeader, valid)
+	assert.NoError(t, p.VerifyWebhook("secret", headers, body))
+	assert.Error(t, p.VerifyWebhook("other", headers, body))
+
+	headers.Set(SignatureHeader, strings.TrimPrefix(valid, "sha256="))
+	assert.Error(t, p.VerifyWebhook("secret", headers, body))
+
+	assert.Error(t, p.VerifyWebhook("secret", http.Header{}, body))
+}
+
+func TestProvider_ParseWebhook(t *testing.T) {
+	p := &Provider{}
+
+	tests := []struct {
+		name         string
+		event        string
+		body         string
+		wantEvent    string
+		wantUpserted []string
+		wantDeleted  []string
+	}{
+		{
+			name:         "issue opened",
+			event:        "issues",
+			body:         `{"action":"opened","issue":{"number":12}}`,
+			wantEvent:    "issues.opened",
+			wantUpserted: []string{"issue:12"},
+		},
+		{
+			name:        "issue deleted",
+			event:       "issues",
+			body:        `{"action":"deleted","issue":{"number":12}}`,
+			wantEvent:   "issues.deleted",
+			wantDeleted: []string{"issue:12"},
+		},
+		{
+			name:         "issue comment",
+			event:        "issue_comment",
+			body:         `{"action":"created","issue":{"number":3}}`,
+			wantEvent:    "issue_comment.created",
+			wantUpserted: []string{"issue:3"},
+		},
+		{
+			name:         "pull request",
+			event:        "pull_request",
+			body:         `{"action":"closed","number":8}`,
+			wantEvent:    "pull_request.closed",
+			wantUpserted: []string{"issue:8"},
+		},
+		{
+			name:        "discussion deleted",
+			event:       "discussion",
+			body:        `{"action":"deleted","discussion":{"number":2}}`,
+			wantEvent:   "discussion.deleted",
+			wantDeleted: []string{"discussion:2"},
+		},
+		{
+			name:  "push replays commits in order",
+			event: "push",
+			body: `{"ref":"refs/heads/main","commits":[
+				{"added":["docs/a.md"],"modified":[],"removed":["old.md"]},
+				{"added":[],"modified":["README.md"],"removed":["docs/a.md"]},
+				{"added":["old.md"],"modified":[],"removed":[]}
+			]}`,
+			wantEvent:    "push",
+			wantUpserted: []string{"file:main:old.md", "file:main:README.md"},
+			wantDeleted:  []string{"file:main:docs/a.md"},
+		},
+		{
+			name:      "tag push",
+			event:     "push",
+			body:      `{"ref":"refs/tags/v1.0","commits":[]}`,
+			wantEvent: "push",
+		},
+		{
+			name:      "ping",
+			event:     "ping",
+			body:      `{"zen":"Keep it simple."}`,
+			wantEvent: "ping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			headers.Set(EventHeader, tt.event)
+			headers.Set(DeliveryHeader, "d-1")
+
+			payload, err := p.ParseWebhook(headers, []byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, "d-1", payload.DeliveryID)
+			assert.Equal(t, tt.wantEvent, payload.EventType)
+			assert.Equal(t, tt.wantUpserted, payload.UpsertedIDs)
+			assert.Equal(t, tt.wantDeleted, payload.DeletedIDs)
+		})
+	}
+
+	_, err := p.ParseWebhook(http.Header{}, []byte(`{}`))
+	assert.Error(t, err)
+}
+
+// testLogger discards output so test runs do not write log files.
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,307 @@
+// Package github provides a data source provider for GitHub repositories
+// (issues, pull requests, discussions and markdown files).
+package github
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config represents the GitHub provider configuration.
+// This is stored encrypted in DataSourceIntegration.config_encrypted.
+type Config struct {
+	// Repository is the repository to sync, as "owner/name"
+	Repository string `json:"repository"`
+
+	// Branch is the branch to read files from (empty = repository default branch)
+	Branch string `json:"branch,omitempty"`
+
+	// AccessToken is an optional personal access token. When empty the
+	// GitHub App installation token is used.
+	AccessToken string `json:"accessToken,omitempty"`
+
+	// ImportIssues imports issues and their comments (nil = true)
+	ImportIssues *bool `json:"importIssues,omitempty"`
+
+	// ImportPullRequests imports pull requests and their comments (nil = true)
+	ImportPullRequests *bool `json:"importPullRequests,omitempty"`
+
+	// ImportDiscussions imports discussions and their comments
+	ImportDiscussions bool `json:"importDiscussions,omitempty"`
+
+	// ImportFiles imports markdown files from the branch (nil = true)
+	ImportFiles *bool `json:"importFiles,omitempty"`
+
+	// Paths restricts file import to these path prefixes (empty = whole repository)
+	Paths []string `json:"paths,omitempty"`
+
+	// MapToGraph maps authors, labels and cross-references into graph objects
+	// and relationships (nil = true)
+	MapToGraph *bool `json:"mapToGraph,omitempty"`
+}
+
+// IssuesEnabled reports whether issues should be imported
+func (c *Config) IssuesEnabled() bool {
+	return c.ImportIssues == nil || *c.ImportIssues
+}
+
+// PullRequestsEnabled reports whether pull requests should be imported
+func (c *Config) PullRequestsEnabled() bool {
+	return c.ImportPullRequests == nil || *c.ImportPullRequests
+}
+
+// FilesEnabled reports whether repository files should be imported
+func (c *Config) FilesEnabled() bool {
+	return c.ImportFiles == nil || *c.ImportFiles
+}
+
+// GraphEnabled reports whether imported items are mapped into the graph
+func (c *Config) GraphEnabled() bool {
+	return c.MapToGraph == nil || *c.MapToGraph
+}
+
+// OwnerAndName splits Repository into owner and name
+func (c *Config) OwnerAndName() (string, string, error) {
+	owner, name, ok := strings.Cut(strings.Trim(c.Repository, "/ "), "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("repository must be in the form owner/name, got %q", c.Repository)
+	}
+	return owner, name, nil
+}
+
+// ----------------------------------------------------------------------------
+// GitHub REST API Types
+// ----------------------------------------------------------------------------
+
+// Repository is a GitHub repository
+type Repository struct {
+	ID            int64  `json:"id"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	HTMLURL       string `json:"html_url"`
+	Private       bool   `json:"private"`
+}
+
+// User is a GitHub user or bot account
+type User struct {
+	Login   string `json:"login"`
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// Label is an issue or pull request label
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PullRequestRef is set on issues that are pull requests
+type PullRequestRef struct {
+	URL      string     `json:"url"`
+	MergedAt *time.Time `json:"merged_at,omitempty"`
+}
+
+// Issue is a GitHub issue. The issues API also returns pull requests,
+// which have PullRequest set.
+type Issue struct {
+	ID          int64           `json:"id"`
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	StateReason string          `json:"state_reason,omitempty"`
+	HTMLURL     string          `json:"html_url"`
+	User        User            `json:"user"`
+	Assignees   []User          `json:"assignees"`
+	Labels      []Label         `json:"labels"`
+	Comments    int             `json:"comments"`
+	PullRequest *PullRequestRef `json:"pull_request,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	ClosedAt    *time.Time      `json:"closed_at,omitempty"`
+}
+
+// IsPullRequest reports whether the issue is a pull request
+func (i *Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
+}
+
+// PullRequest holds the pull-request-only fields
+type PullRequest struct {
+	Number   int        `json:"number"`
+	Merged   bool       `json:"merged"`
+	MergedAt *time.Time `json:"merged_at,omitempty"`
+	Draft    bool       `json:"draft"`
+	Head     BranchRef  `json:"head"`
+	Base     BranchRef  `json:"base"`
+}
+
+// BranchRef is a pull request head or base
+type BranchRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// IssueComment is a comment on an issue or pull request
+type IssueComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      User      `json:"user"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tree is a recursive git tree listing
+type Tree struct {
+	SHA       string      `json:"sha"`
+	Tree      []TreeEntry `json:"tree"`
+	Truncated bool        `json:"truncated"`
+}
+
+// TreeEntry is a single entry in a git tree
+type TreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"` // "blob" or "tree"
+	SHA  string `json:"sha"`
+	Size int    `json:"size"`
+}
+
+// ----------------------------------------------------------------------------
+// GitHub GraphQL Types (discussions)
+// ----------------------------------------------------------------------------
+
+// Discussion is a repository discussion
+type Discussion struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Author    *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Category struct {
+		Name string `json:"name"`
+	} `json:"category"`
+	Comments struct {
+		Nodes []DiscussionComment `json:"nodes"`
+	} `json:"comments"`
+}
+
+// AuthorLogin returns the discussion author's login ("ghost" for deleted accounts)
+func (d *Discussion) AuthorLogin() string {
+	if d.Author == nil {
+		return "ghost"
+	}
+	return d.Author.Login
+}
+
+// DiscussionComment is a top-level discussion comment
+type DiscussionComment struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	Author    *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// DiscussionsPage is one page of the discussions GraphQL query
+type DiscussionsPage struct {
+	Discussions []Discussion
+	EndCursor   string
+	HasNextPage bool
+}
+
+// ----------------------------------------------------------------------------
+// Document Metadata
+// ----------------------------------------------------------------------------
+
+// DocumentMetadata is stored in document.metadata for GitHub documents
+type DocumentMetadata struct {
+	// GitHubID uniquely identifies the item within the integration
+	// ("owner/repo#12", "owner/repo/discussions/3", "owner/repo:docs/intro.md")
+	GitHubID        string   `json:"githubId"`
+	Repository      string   `json:"githubRepository"`
+	ItemType        string   `json:"itemType"` // "issue", "pull_request", "discussion", "file"
+	Number          int      `json:"githubNumber,omitempty"`
+	URL             string   `json:"githubUrl,omitempty"`
+	State           string   `json:"state,omitempty"`
+	Author          string   `json:"author,omitempty"`
+	Assignees       []string `json:"assignees,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
+	Category        string   `json:"category,omitempty"`
+	CommentCount    int      `json:"commentCount,omitempty"`
+	Merged          bool     `json:"merged,omitempty"`
+	Draft           bool     `json:"draft,omitempty"`
+	BaseBranch      string   `json:"baseBranch,omitempty"`
+	HeadBranch      string   `json:"headBranch,omitempty"`
+	Path            string   `json:"path,omitempty"`
+	Branch          string   `json:"branch,omitempty"`
+	BlobSHA         string   `json:"githubBlobSha,omitempty"`
+	References      []int    `json:"references,omitempty"`
+	GitHubCreatedAt string   `json:"githubCreatedAt,omitempty"`
+	GitHubUpdatedAt string   `json:"githubUpdatedAt,omitempty"`
+	Provider        string   `json:"provider"`
+}
+
+// ConfigSchema is the JSON schema for provider configuration (used by UI)
+var ConfigSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"repository"},
+	"properties": map[string]interface{}{
+		"repository": map[string]interface{}{
+			"type":           "string",
+			"title":          "Repository",
+			"description":    "Repository to sync, as owner/name",
+			"ui:placeholder": "octocat/hello-world",
+		},
+		"branch": map[string]interface{}{
+			"type":        "string",
+			"title":       "Branch",
+			"description": "Branch to import files from (defaults to the repository default branch)",
+		},
+		"accessToken": map[string]interface{}{
+			"type":        "string",
+			"title":       "Access Token",
+			"description": "Optional personal access token. Leave empty to use the connected GitHub App.",
+			"format":      "password",
+		},
+		"importIssues": map[string]interface{}{
+			"type":    "boolean",
+			"title":   "Import Issues",
+			"default": true,
+		},
+		"importPullRequests": map[string]interface{}{
+			"type":    "boolean",
+			"title":   "Import Pull Requests",
+			"default": true,
+		},
+		"importDiscussions": map[string]interface{}{
+			"type":    "boolean",
+			"title":   "Import Discussions",
+			"default": false,
+		},
+		"importFiles": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Import Markdown Files",
+			"description": "Import .md and .mdx files from the branch",
+			"default":     true,
+		},
+		"paths": map[string]interface{}{
+			"type":        "array",
+			"title":       "Paths",
+			"description": "Only import files under these paths (e.g. docs/)",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+		"mapToGraph": map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Map to Graph",
+			"description": "Create graph objects for authors and labels, and relationships for cross-references",
+			"default":     true,
+		},
+	},
+}
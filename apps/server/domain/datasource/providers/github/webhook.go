@@ -0,0 +1,307 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SignatureHeader carries "sha256=" + hex HMAC-SHA256 of the raw body
+	SignatureHeader = "X-Hub-Signature-256"
+
+	// EventHeader names the webhook event ("issues", "push", ...)
+	EventHeader = "X-GitHub-Event"
+
+	// DeliveryHeader is GitHub's unique ID for a delivery (stable across redeliveries)
+	DeliveryHeader = "X-GitHub-Delivery"
+)
+
+// Item ID prefixes used for webhook-targeted syncs
+const (
+	itemPrefixIssue      = "issue:"
+	itemPrefixDiscussion = "discussion:"
+	itemPrefixFile       = "file:" // file:<branch>:<path>
+)
+
+// WebhookPayload is a parsed GitHub webhook delivery.
+// Mirrors datasource.WebhookDelivery to avoid import cycle
+type WebhookPayload struct {
+	DeliveryID  string
+	EventType   string
+	UpsertedIDs []string
+	DeletedIDs  []string
+}
+
+// webhookBody holds the fields used from issue, pull request, discussion and push events
+type webhookBody struct {
+	Action string `json:"action"`
+	Number int    `json:"number"`
+	Issue  *struct {
+		Number int `json:"number"`
+	} `json:"issue"`
+	Discussion *struct {
+		Number int `json:"number"`
+	} `json:"discussion"`
+	Ref     string `json:"ref"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// VerifyWebhook checks the X-Hub-Signature-256 header against the signing secret
+func (p *Provider) VerifyWebhook(secret string, headers http.Header, body []byte) error {
+	signature, ok := strings.CutPrefix(strings.TrimSpace(headers.Get(SignatureHeader)), "sha256=")
+	if !ok {
+		return fmt.Errorf("missing or malformed %s header", SignatureHeader)
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// ParseWebhook converts a GitHub webhook into item changes. Events that don't
+// concern synced items (ping, stars, workflow runs, ...) yield no items.
+func (p *Provider) ParseWebhook(headers http.Header, body []byte) (*WebhookPayload, error) {
+	event := headers.Get(EventHeader)
+	if event == "" {
+		return nil, fmt.Errorf("missing %s header", EventHeader)
+	}
+
+	var wb webhookBody
+	if err := json.Unmarshal(body, &wb); err != nil {
+		return nil, fmt.Errorf("parse webhook body: %w", err)
+	}
+
+	payload := &WebhookPayload{
+		DeliveryID: headers.Get(DeliveryHeader),
+		EventType:  event,
+	}
+	if wb.Action != "" {
+		payload.EventType = event + "." + wb.Action
+	}
+
+	switch event {
+	case "issues":
+		if wb.Issue == nil {
+			break
+		}
+		id := itemPrefixIssue + strconv.Itoa(wb.Issue.Number)
+		if wb.Action == "deleted" {
+			payload.DeletedIDs = append(payload.DeletedIDs, id)
+		} else {
+			payload.UpsertedIDs = append(payload.UpsertedIDs, id)
+		}
+
+	case "issue_comment":
+		if wb.Issue != nil {
+			payload.UpsertedIDs = append(payload.UpsertedIDs, itemPrefixIssue+strconv.Itoa(wb.Issue.Number))
+		}
+
+	case "pull_request", "pull_request_review", "pull_request_review_comment":
+		if wb.Number > 0 {
+			payload.UpsertedIDs = append(payload.UpsertedIDs, itemPrefixIssue+strconv.Itoa(wb.Number))
+		}
+
+	case "discussion", "discussion_comment":
+		if wb.Discussion == nil {
+			break
+		}
+		id := itemPrefixDiscussion + strconv.Itoa(wb.Discussion.Number)
+		if event == "discussion" && wb.Action == "deleted" {
+			payload.DeletedIDs = append(payload.DeletedIDs, id)
+		} else {
+			payload.UpsertedIDs = append(payload.UpsertedIDs, id)
+		}
+
+	case "push":
+		branch, ok := strings.CutPrefix(wb.Ref, "refs/heads/")
+		if !ok {
+			break
+		}
+		// Replay the commits in order so the last change to a path wins
+		removed := make(map[string]bool)
+		var paths []string
+		for _, c := range wb.Commits {
+			for _, list := range [][]string{c.Added, c.Modified} {
+				for _, path := range list {
+					if _, seen := removed[path]; !seen {
+						paths = append(paths, path)
+					}
+					removed[path] = false
+				}
+			}
+			for _, path := range c.Removed {
+				if _, seen := removed[path]; !seen {
+					paths = append(paths, path)
+				}
+				removed[path] = true
+			}
+		}
+		for _, path := range paths {
+			id := itemPrefixFile + branch + ":" + path
+			if removed[path] {
+				payload.DeletedIDs = append(payload.DeletedIDs, id)
+			} else {
+				payload.UpsertedIDs = append(payload.UpsertedIDs, id)
+			}
+		}
+	}
+
+	return payload, nil
+}
+
+// syncTargeted re-imports or removes the items named by a webhook delivery
+func (p *Provider) syncTargeted(ctx context.Context, run *syncRun, itemIDs, deletedIDs []string) error {
+	run.result.TotalItems = len(itemIDs) + len(deletedIDs)
+
+	// Push events cover every branch; only the synced branch is imported
+	var branch string
+	var tree map[string]TreeEntry
+	fileTarget := func(rest string) (string, bool, error) {
+		b, path, ok := strings.Cut(rest, ":")
+		if !ok || !run.config.FilesEnabled() {
+			return "", false, nil
+		}
+		if branch == "" {
+			var err error
+			if branch, err = p.resolveBranch(ctx, run); err != nil {
+				return "", false, err
+			}
+		}
+		return path, b == branch && isImportableFile(TreeEntry{Type: "blob", Path: path}, run.config.Paths), nil
+	}
+
+	for _, itemID := range itemIDs {
+		select {
+		case <-ctx.Done():
+			run.result.Errors = append(run.result.Errors, "sync cancelled")
+			return ctx.Err()
+		default:
+		}
+
+		var docID string
+		var skipped bool
+		var err error
+
+		switch {
+		case strings.HasPrefix(itemID, itemPrefixIssue):
+			var number int
+			number, err = strconv.Atoi(strings.TrimPrefix(itemID, itemPrefixIssue))
+			if err != nil {
+				break
+			}
+			var issue *Issue
+			if issue, err = p.client.GetIssue(ctx, run.token, run.owner, run.name, number); err != nil {
+				break
+			}
+			if issue.IsPullRequest() && !run.config.PullRequestsEnabled() || !issue.IsPullRequest() && !run.config.IssuesEnabled() {
+				skipped = true
+				break
+			}
+			docID, skipped, err = p.importIssue(ctx, run, issue)
+
+		case strings.HasPrefix(itemID, itemPrefixDiscussion):
+			if !run.config.ImportDiscussions {
+				skipped = true
+				break
+			}
+			var number int
+			number, err = strconv.Atoi(strings.TrimPrefix(itemID, itemPrefixDiscussion))
+			if err != nil {
+				break
+			}
+			var d *Discussion
+			if d, err = p.client.GetDiscussion(ctx, run.token, run.owner, run.name, number); err != nil {
+				break
+			}
+			docID, skipped, err = p.importDiscussion(ctx, run, d)
+
+		case strings.HasPrefix(itemID, itemPrefixFile):
+			var path string
+			var wanted bool
+			path, wanted, err = fileTarget(strings.TrimPrefix(itemID, itemPrefixFile))
+			if err != nil || !wanted {
+				skipped = err == nil
+				break
+			}
+			if tree == nil {
+				var t *Tree
+				if t, err = p.client.GetTree(ctx, run.token, run.owner, run.name, branch); err != nil {
+					break
+				}
+				tree = make(map[string]TreeEntry, len(t.Tree))
+				for _, e := range t.Tree {
+					tree[e.Path] = e
+				}
+			}
+			entry, ok := tree[path]
+			if !ok || !isImportableFile(entry, run.config.Paths) {
+				skipped = true
+				break
+			}
+			docID, skipped, err = p.importFile(ctx, run, branch, entry, false)
+
+		default:
+			err = fmt.Errorf("unknown item ID")
+		}
+
+		run.record(itemID, docID, skipped, err)
+	}
+
+	for _, itemID := range deletedIDs {
+		var githubID string
+		var err error
+
+		switch {
+		case strings.HasPrefix(itemID, itemPrefixIssue):
+			var number int
+			if number, err = strconv.Atoi(strings.TrimPrefix(itemID, itemPrefixIssue)); err == nil {
+				githubID = issueGitHubID(run.owner, run.name, number)
+			}
+		case strings.HasPrefix(itemID, itemPrefixDiscussion):
+			var number int
+			if number, err = strconv.Atoi(strings.TrimPrefix(itemID, itemPrefixDiscussion)); err == nil {
+				githubID = discussionGitHubID(run.owner, run.name, number)
+			}
+		case strings.HasPrefix(itemID, itemPrefixFile):
+			var path string
+			var wanted bool
+			if path, wanted, err = fileTarget(strings.TrimPrefix(itemID, itemPrefixFile)); err == nil && wanted {
+				githubID = fileGitHubID(run.owner, run.name, path)
+			}
+		default:
+			err = fmt.Errorf("unknown item ID")
+		}
+
+		deleted := false
+		if err == nil && githubID != "" {
+			deleted, err = p.deleteDocument(ctx, run, githubID)
+		}
+		run.record(itemID, "", err == nil && !deleted, err)
+	}
+
+	p.linkReferences(ctx, run)
+
+	if run.result.FailedItems > 0 && run.result.SuccessfulItems == 0 && run.result.SkippedItems == 0 {
+		return fmt.Errorf("targeted sync failed for all %d items", run.result.FailedItems)
+	}
+	return nil
+}
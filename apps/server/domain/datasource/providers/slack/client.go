@@ -0,0 +1,262 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	baseURL = "https://slack.com/api"
+
+	// pageLimit is the page size for cursor-paginated methods
+	pageLimit = 200
+
+	// maxRetries is how many times a rate-limited request is retried
+	maxRetries = 3
+)
+
+// Client is a stateless Slack Web API client.
+// Each method accepts the bot token, making it suitable for the DataSourceProvider pattern.
+type Client struct {
+	httpClient *http.Client
+	log        *slog.Logger
+
+	// apiURL is the API base URL (overridable for tests)
+	apiURL string
+}
+
+// NewClient creates a new Slack API client
+func NewClient(log *slog.Logger) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		log:    log.With(logger.Scope("slack-client")),
+		apiURL: baseURL,
+	}
+}
+
+// ----------------------------------------------------------------------------
+// HTTP Helpers
+// ----------------------------------------------------------------------------
+
+// call invokes a Web API method and decodes the response into out.
+// Rate-limited requests (HTTP 429) are retried after the Retry-After delay.
+func (c *Client) call(ctx context.Context, token, method string, params url.Values, out any) error {
+	urlStr := fmt.Sprintf("%s/%s?%s", c.apiURL, method, params.Encode())
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("HTTP request: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			if wait <= 0 {
+				wait = 1
+			}
+			c.log.Debug("rate limited, retrying",
+				slog.String("method", method),
+				slog.Int("retry_after_seconds", wait))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(wait) * time.Second):
+				continue
+			}
+		}
+
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
+		}
+
+		var envelope apiResponse
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		if !envelope.OK {
+			return fmt.Errorf("slack %s: %s", method, envelope.Error)
+		}
+
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		return nil
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Web API Methods
+// ----------------------------------------------------------------------------
+
+// AuthTest verifies the token and returns the workspace name
+func (c *Client) AuthTest(ctx context.Context, token string) (string, error) {
+	var resp struct {
+		Team string `json:"team"`
+	}
+	if err := c.call(ctx, token, "auth.test", url.Values{}, &resp); err != nil {
+		return "", fmt.Errorf("auth test: %w", err)
+	}
+	return resp.Team, nil
+}
+
+// ListChannels retrieves all public and private channels visible to the bot
+func (c *Client) ListChannels(ctx context.Context, token string) ([]Channel, error) {
+	var channels []Channel
+	cursor := ""
+	for {
+		params := url.Values{}
+		params.Set("types", "public_channel,private_channel")
+		params.Set("exclude_archived", "true")
+		params.Set("limit", strconv.Itoa(pageLimit))
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		var resp struct {
+			apiResponse
+			Channels []Channel `json:"channels"`
+		}
+		if err := c.call(ctx, token, "conversations.list", params, &resp); err != nil {
+			return nil, fmt.Errorf("list channels: %w", err)
+		}
+		channels = append(channels, resp.Channels...)
+
+		cursor = resp.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return channels, nil
+		}
+	}
+}
+
+// ListUsers retrieves all workspace members
+func (c *Client) ListUsers(ctx context.Context, token string) ([]User, error) {
+	var users []User
+	cursor := ""
+	for {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(pageLimit))
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		var resp struct {
+			apiResponse
+			Members []User `json:"members"`
+		}
+		if err := c.call(ctx, token, "users.list", params, &resp); err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+		users = append(users, resp.Members...)
+
+		cursor = resp.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return users, nil
+		}
+	}
+}
+
+// GetHistory retrieves top-level channel messages posted at or after oldest
+// and before latest (zero = unbounded)
+func (c *Client) GetHistory(ctx context.Context, token, channelID string, oldest, latest time.Time) ([]Message, error) {
+	var messages []Message
+	cursor := ""
+	for {
+		params := url.Values{}
+		params.Set("channel", channelID)
+		params.Set("limit", strconv.Itoa(pageLimit))
+		if !oldest.IsZero() {
+			params.Set("oldest", formatTS(oldest))
+			params.Set("inclusive", "true")
+		}
+		if !latest.IsZero() {
+			params.Set("latest", formatTS(latest))
+		}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		var resp struct {
+			apiResponse
+			Messages []Message `json:"messages"`
+		}
+		if err := c.call(ctx, token, "conversations.history", params, &resp); err != nil {
+			return nil, fmt.Errorf("get history for %s: %w", channelID, err)
+		}
+		messages = append(messages, resp.Messages...)
+
+		cursor = resp.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return messages, nil
+		}
+	}
+}
+
+// GetReplies retrieves a thread's replies (excluding the parent message)
+func (c *Client) GetReplies(ctx context.Context, token, channelID, threadTS string) ([]Message, error) {
+	var replies []Message
+	cursor := ""
+	for {
+		params := url.Values{}
+		params.Set("channel", channelID)
+		params.Set("ts", threadTS)
+		params.Set("limit", strconv.Itoa(pageLimit))
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		var resp struct {
+			apiResponse
+			Messages []Message `json:"messages"`
+		}
+		if err := c.call(ctx, token, "conversations.replies", params, &resp); err != nil {
+			return nil, fmt.Errorf("get replies for %s: %w", threadTS, err)
+		}
+		for _, m := range resp.Messages {
+			if m.TS != threadTS {
+				replies = append(replies, m)
+			}
+		}
+
+		cursor = resp.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return replies, nil
+		}
+	}
+}
+
+// parseTS converts a Slack timestamp ("1700000000.123456") to a time
+func parseTS(ts string) time.Time {
+	f, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)).UTC()
+}
+
+// formatTS converts a time to a Slack timestamp
+func formatTS(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
@@ -0,0 +1,349 @@
+package slack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// userDirectory resolves user IDs to display names
+type userDirectory map[string]string
+
+// newUserDirectory builds a directory from workspace members
+func newUserDirectory(users []User) userDirectory {
+	dir := make(userDirectory, len(users))
+	for i := range users {
+		dir[users[i].ID] = users[i].DisplayName()
+	}
+	return dir
+}
+
+// name returns the display name for a user ID, falling back to the ID
+func (d userDirectory) name(id string) string {
+	if name, ok := d[id]; ok && name != "" {
+		return name
+	}
+	return id
+}
+
+var (
+	// userMentionPattern matches <@U123> and <@U123|name>
+	userMentionPattern = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+	// channelMentionPattern matches <#C123|name> and <#C123>
+	channelMentionPattern = regexp.MustCompile(`<#([CG][A-Z0-9]+)(?:\|([^>]*))?>`)
+	// linkPattern matches <url|label> and <url>
+	linkPattern = regexp.MustCompile(`<((?:https?|mailto):[^|>]+)(?:\|([^>]*))?>`)
+	// specialMentionPattern matches <!here>, <!channel>, <!everyone>
+	specialMentionPattern = regexp.MustCompile(`<!(here|channel|everyone)(?:\|[^>]*)?>`)
+)
+
+// formatText converts Slack markup into readable text, resolving user mentions to names
+func (d userDirectory) formatText(text string) string {
+	text = userMentionPattern.ReplaceAllStringFunc(text, func(m string) string {
+		return "@" + d.name(userMentionPattern.FindStringSubmatch(m)[1])
+	})
+	text = channelMentionPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := channelMentionPattern.FindStringSubmatch(m)
+		if sub[2] != "" {
+			return "#" + sub[2]
+		}
+		return "#" + sub[1]
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := linkPattern.FindStringSubmatch(m)
+		if sub[2] != "" && sub[2] != sub[1] {
+			return fmt.Sprintf("%s (%s)", sub[2], sub[1])
+		}
+		return sub[1]
+	})
+	text = specialMentionPattern.ReplaceAllString(text, "@$1")
+
+	// Slack escapes these three characters in message text
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+}
+
+// skippedSubtypes are Slack message subtypes that carry no conversation content
+var skippedSubtypes = map[string]bool{
+	"channel_join":    true,
+	"channel_leave":   true,
+	"channel_topic":   true,
+	"channel_purpose": true,
+	"channel_name":    true,
+	"channel_archive": true,
+	"group_join":      true,
+	"group_leave":     true,
+	"pinned_item":     true,
+	"tombstone":       true,
+}
+
+// normalizeSlackMessages converts Slack messages to ChatMessages, resolving
+// user names and dropping join/leave noise (and bot messages unless includeBots)
+func normalizeSlackMessages(messages []Message, users userDirectory, includeBots bool) []ChatMessage {
+	out := make([]ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Type != "" && m.Type != "message" || skippedSubtypes[m.Subtype] {
+			continue
+		}
+		isBot := m.BotID != "" || m.Subtype == "bot_message"
+		if isBot && !includeBots {
+			continue
+		}
+
+		msg := ChatMessage{
+			ID:     m.TS,
+			UserID: m.User,
+			Text:   users.formatText(m.Text),
+			Time:   parseTS(m.TS),
+			IsBot:  isBot,
+		}
+		if m.ThreadTS != "" && m.ThreadTS != m.TS {
+			msg.ThreadID = m.ThreadTS
+		}
+
+		switch {
+		case m.User != "" && users[m.User] != "":
+			msg.UserName = users[m.User]
+		case m.UserProfile != nil && m.UserProfile.RealName != "":
+			msg.UserName = m.UserProfile.RealName
+		case m.Username != "":
+			msg.UserName = m.Username
+		default:
+			msg.UserName = users.name(m.User)
+		}
+
+		for _, f := range m.Files {
+			msg.Files = append(msg.Files, f.Name)
+		}
+		if strings.TrimSpace(msg.Text) == "" && len(msg.Files) == 0 {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+// groupConversations splits a channel's messages into conversations.
+// Replies are always kept with their root. With GroupByThread a root that has
+// replies becomes its own conversation and the remaining roots are grouped by
+// day; with GroupByChannelDay all roots are grouped by day. Replies whose root
+// isn't present become roots themselves.
+func groupConversations(channel ChatChannel, groupBy string, loc *time.Location) []Conversation {
+	roots := make(map[string]ChatMessage)
+	replies := make(map[string][]ChatMessage)
+	for _, m := range channel.Messages {
+		if m.ThreadID == "" {
+			roots[m.ID] = m
+		}
+	}
+	for _, m := range channel.Messages {
+		if m.ThreadID == "" {
+			continue
+		}
+		if _, ok := roots[m.ThreadID]; ok {
+			replies[m.ThreadID] = append(replies[m.ThreadID], m)
+		} else {
+			orphan := m
+			orphan.ThreadID = ""
+			roots[orphan.ID] = orphan
+		}
+	}
+
+	byKey := make(map[string]*Conversation)
+	var keys []string
+	for _, root := range sortedMessages(roots) {
+		var key string
+		if groupBy == GroupByThread && len(replies[root.ID]) > 0 {
+			key = "thread:" + root.ID
+		} else {
+			key = "day:" + root.Time.In(loc).Format("2006-01-02")
+		}
+
+		conv, ok := byKey[key]
+		if !ok {
+			conv = &Conversation{
+				ChannelID:   channel.ID,
+				ChannelName: channel.Name,
+				Key:         key,
+				Replies:     make(map[string][]ChatMessage),
+			}
+			byKey[key] = conv
+			keys = append(keys, key)
+		}
+		conv.Roots = append(conv.Roots, root)
+		if r := replies[root.ID]; len(r) > 0 {
+			sort.SliceStable(r, func(i, j int) bool { return r[i].Time.Before(r[j].Time) })
+			conv.Replies[root.ID] = r
+		}
+	}
+
+	out := make([]Conversation, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, *byKey[key])
+	}
+	return out
+}
+
+// sortedMessages returns the map's messages in time order
+func sortedMessages(messages map[string]ChatMessage) []ChatMessage {
+	out := make([]ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Time.Equal(out[j].Time) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Time.Before(out[j].Time)
+	})
+	return out
+}
+
+// messages returns all messages in the conversation, roots followed by their replies
+func (c *Conversation) messages() []ChatMessage {
+	var out []ChatMessage
+	for _, root := range c.Roots {
+		out = append(out, root)
+		out = append(out, c.Replies[root.ID]...)
+	}
+	return out
+}
+
+// participants returns the distinct author names in order of first appearance
+func (c *Conversation) participants() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range c.messages() {
+		if m.UserName != "" && !seen[m.UserName] {
+			seen[m.UserName] = true
+			names = append(names, m.UserName)
+		}
+	}
+	return names
+}
+
+// title returns the document title for the conversation
+func (c *Conversation) title(loc *time.Location) string {
+	if strings.HasPrefix(c.Key, "thread:") && len(c.Roots) > 0 {
+		summary := strings.Join(strings.Fields(c.Roots[0].Text), " ")
+		if runes := []rune(summary); len(runes) > 60 {
+			summary = string(runes[:60]) + "…"
+		}
+		return fmt.Sprintf("#%s thread %s: %s", c.ChannelName, c.Roots[0].Time.In(loc).Format("2006-01-02"), summary)
+	}
+	return fmt.Sprintf("#%s %s", c.ChannelName, strings.TrimPrefix(c.Key, "day:"))
+}
+
+// render renders the conversation as a markdown transcript
+func (c *Conversation) render(loc *time.Location) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", c.title(loc)))
+	sb.WriteString(fmt.Sprintf("**Channel:** #%s\n", c.ChannelName))
+	if p := c.participants(); len(p) > 0 {
+		sb.WriteString(fmt.Sprintf("**Participants:** %s\n", strings.Join(p, ", ")))
+	}
+	sb.WriteString("\n")
+
+	for _, root := range c.Roots {
+		writeMessage(&sb, root, loc, "")
+		for _, reply := range c.Replies[root.ID] {
+			writeMessage(&sb, reply, loc, "> ")
+		}
+	}
+
+	return sb.String()
+}
+
+// writeMessage writes one transcript line; replies are quoted under their root
+func writeMessage(sb *strings.Builder, m ChatMessage, loc *time.Location, prefix string) {
+	text := strings.TrimSpace(m.Text)
+	for _, f := range m.Files {
+		if text != "" {
+			text += " "
+		}
+		text += fmt.Sprintf("[file: %s]", f)
+	}
+	text = strings.ReplaceAll(text, "\n", "\n"+prefix)
+
+	sb.WriteString(fmt.Sprintf("%s**%s** (%s): %s\n", prefix, m.UserName, m.Time.In(loc).Format("2006-01-02 15:04"), text))
+	if prefix == "" {
+		sb.WriteString("\n")
+	}
+}
+
+// buildMetadata builds document.metadata for a conversation
+func (c *Conversation) buildMetadata(source, groupBy, content string, loc *time.Location) DocumentMetadata {
+	msgs := c.messages()
+	sum := sha256.Sum256([]byte(content))
+
+	meta := DocumentMetadata{
+		ConversationID: c.ChannelID + ":" + c.Key,
+		ChannelID:      c.ChannelID,
+		ChannelName:    c.ChannelName,
+		Source:         source,
+		GroupBy:        groupBy,
+		MessageCount:   len(msgs),
+		Participants:   c.participants(),
+		ContentHash:    hex.EncodeToString(sum[:]),
+		Provider:       ProviderTypeSlack,
+	}
+	if strings.HasPrefix(c.Key, "day:") {
+		meta.Date = strings.TrimPrefix(c.Key, "day:")
+	} else if len(c.Roots) > 0 {
+		meta.Date = c.Roots[0].Time.In(loc).Format("2006-01-02")
+	}
+
+	var first, last time.Time
+	for _, m := range msgs {
+		if first.IsZero() || m.Time.Before(first) {
+			first = m.Time
+		}
+		if m.Time.After(last) {
+			last = m.Time
+		}
+	}
+	if !first.IsZero() {
+		meta.FirstMessageAt = first.UTC().Format(time.RFC3339)
+		meta.LastMessageAt = last.UTC().Format(time.RFC3339)
+	}
+	return meta
+}
+
+// buildIntegrationMetadata records the thread structure of a conversation
+// for document.integration_metadata
+func (c *Conversation) buildIntegrationMetadata(source string) map[string]any {
+	threads := make([]ThreadMetadata, 0, len(c.Roots))
+	for _, root := range c.Roots {
+		t := ThreadMetadata{
+			RootID:   root.ID,
+			RootUser: root.UserName,
+			RootAt:   root.Time.UTC().Format(time.RFC3339),
+		}
+		seen := map[string]bool{}
+		if root.UserName != "" {
+			seen[root.UserName] = true
+			t.Participants = append(t.Participants, root.UserName)
+		}
+		for _, r := range c.Replies[root.ID] {
+			t.ReplyIDs = append(t.ReplyIDs, r.ID)
+			if r.UserName != "" && !seen[r.UserName] {
+				seen[r.UserName] = true
+				t.Participants = append(t.Participants, r.UserName)
+			}
+		}
+		threads = append(threads, t)
+	}
+
+	return map[string]any{
+		"provider":        ProviderTypeSlack,
+		"source":          source,
+		"channelId":       c.ChannelID,
+		"channelName":     c.ChannelName,
+		"conversationKey": c.Key,
+		"threads":         threads,
+	}
+}
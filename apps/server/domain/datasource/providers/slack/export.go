@@ -0,0 +1,310 @@
+package slack
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Export sources recorded in document metadata
+const (
+	SourceLive        = "slack"
+	SourceSlackExport = "slack-export"
+	SourceTeamsExport = "teams-export"
+)
+
+// maxExportFileBytes caps how much of a single archive entry is read
+const maxExportFileBytes = 64 << 20 // 64 MiB
+
+// Export is a parsed chat export archive
+type Export struct {
+	Source   string
+	Channels []ChatChannel
+}
+
+// ParseExport reads a Slack or Microsoft Teams export archive. Slack exports
+// are recognized by their users.json/channels.json files; anything else is
+// read as Teams (Microsoft Graph chatMessage JSON).
+func ParseExport(r *zip.Reader, includeBots bool) (*Export, error) {
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() {
+			files[strings.TrimPrefix(path.Clean(f.Name), "/")] = f
+		}
+	}
+
+	if _, ok := files["channels.json"]; ok {
+		return parseSlackExport(files, includeBots)
+	}
+	if _, ok := files["users.json"]; ok {
+		return parseSlackExport(files, includeBots)
+	}
+	return parseTeamsExport(files, includeBots)
+}
+
+// readJSON decodes an archive entry into out
+func readJSON(f *zip.File, out any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxExportFileBytes+1))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", f.Name, err)
+	}
+	if len(data) > maxExportFileBytes {
+		return fmt.Errorf("%s exceeds %d bytes", f.Name, maxExportFileBytes)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parse %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Slack export
+// ----------------------------------------------------------------------------
+
+// parseSlackExport reads a standard Slack workspace export:
+// users.json, channels.json (+ groups.json, mpims.json, dms.json) and one
+// directory per channel holding YYYY-MM-DD.json message files
+func parseSlackExport(files map[string]*zip.File, includeBots bool) (*Export, error) {
+	var users []User
+	if f, ok := files["users.json"]; ok {
+		if err := readJSON(f, &users); err != nil {
+			return nil, err
+		}
+	}
+	dir := newUserDirectory(users)
+
+	// Directory name → channel. Channels are keyed by name, DMs by ID.
+	channelsByDir := make(map[string]Channel)
+	for _, name := range []string{"channels.json", "groups.json", "mpims.json", "dms.json"} {
+		f, ok := files[name]
+		if !ok {
+			continue
+		}
+		var channels []Channel
+		if err := readJSON(f, &channels); err != nil {
+			return nil, err
+		}
+		for _, ch := range channels {
+			if ch.Name != "" {
+				channelsByDir[ch.Name] = ch
+			} else {
+				channelsByDir[ch.ID] = Channel{ID: ch.ID, Name: ch.ID}
+			}
+		}
+	}
+
+	messagesByDir := make(map[string][]Message)
+	for name, f := range files {
+		dirName, file := path.Split(name)
+		dirName = strings.TrimSuffix(dirName, "/")
+		if dirName == "" || strings.Contains(dirName, "/") || !strings.HasSuffix(file, ".json") {
+			continue
+		}
+		var msgs []Message
+		if err := readJSON(f, &msgs); err != nil {
+			return nil, err
+		}
+		messagesByDir[dirName] = append(messagesByDir[dirName], msgs...)
+	}
+
+	export := &Export{Source: SourceSlackExport}
+	for _, dirName := range sortedKeys(messagesByDir) {
+		ch, ok := channelsByDir[dirName]
+		if !ok {
+			ch = Channel{ID: dirName, Name: dirName}
+		}
+		export.Channels = append(export.Channels, ChatChannel{
+			ID:       ch.ID,
+			Name:     ch.Name,
+			Messages: normalizeSlackMessages(messagesByDir[dirName], dir, includeBots),
+		})
+	}
+	return export, nil
+}
+
+// ----------------------------------------------------------------------------
+// Teams export
+// ----------------------------------------------------------------------------
+
+// teamsMessage is a Microsoft Graph chatMessage
+type teamsMessage struct {
+	ID              string    `json:"id"`
+	ReplyToID       string    `json:"replyToId"`
+	MessageType     string    `json:"messageType"`
+	CreatedDateTime time.Time `json:"createdDateTime"`
+	DeletedDateTime *string   `json:"deletedDateTime"`
+	ChatID          string    `json:"chatId"`
+	From            *struct {
+		User *struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"displayName"`
+		} `json:"user"`
+		Application *struct {
+			DisplayName string `json:"displayName"`
+		} `json:"application"`
+	} `json:"from"`
+	Body struct {
+		ContentType string `json:"contentType"`
+		Content     string `json:"content"`
+	} `json:"body"`
+	ChannelIdentity *struct {
+		ChannelID string `json:"channelId"`
+	} `json:"channelIdentity"`
+	Attachments []struct {
+		Name string `json:"name"`
+	} `json:"attachments"`
+}
+
+// parseTeamsExport reads Teams messages from every JSON file in the archive.
+// Each file holds a Graph list response ({"value": [...]}) or a plain array.
+// The channel is the file's directory (falling back to the channel or chat ID).
+func parseTeamsExport(files map[string]*zip.File, includeBots bool) (*Export, error) {
+	channels := make(map[string]*ChatChannel)
+	var order []string
+
+	for _, name := range sortedKeys(files) {
+		if !strings.HasSuffix(strings.ToLower(name), ".json") {
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := readJSON(files[name], &raw); err != nil {
+			return nil, err
+		}
+		var msgs []teamsMessage
+		if err := json.Unmarshal(raw, &msgs); err != nil {
+			var list struct {
+				Value []teamsMessage `json:"value"`
+			}
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, fmt.Errorf("parse %s: not a Teams message list", name)
+			}
+			msgs = list.Value
+		}
+
+		dirName := strings.TrimSuffix(path.Dir(name), "/")
+		for _, m := range msgs {
+			if m.MessageType != "" && m.MessageType != "message" || m.DeletedDateTime != nil {
+				continue
+			}
+
+			channelID := dirName
+			if channelID == "." {
+				switch {
+				case m.ChannelIdentity != nil && m.ChannelIdentity.ChannelID != "":
+					channelID = m.ChannelIdentity.ChannelID
+				case m.ChatID != "":
+					channelID = m.ChatID
+				default:
+					channelID = strings.TrimSuffix(name, path.Ext(name))
+				}
+			}
+
+			msg, ok := normalizeTeamsMessage(m, includeBots)
+			if !ok {
+				continue
+			}
+
+			ch, exists := channels[channelID]
+			if !exists {
+				ch = &ChatChannel{ID: channelID, Name: path.Base(channelID)}
+				channels[channelID] = ch
+				order = append(order, channelID)
+			}
+			ch.Messages = append(ch.Messages, msg)
+		}
+	}
+
+	export := &Export{Source: SourceTeamsExport}
+	for _, id := range order {
+		export.Channels = append(export.Channels, *channels[id])
+	}
+	return export, nil
+}
+
+// normalizeTeamsMessage converts a Graph chatMessage to a ChatMessage
+func normalizeTeamsMessage(m teamsMessage, includeBots bool) (ChatMessage, bool) {
+	msg := ChatMessage{
+		ID:   m.ID,
+		Time: m.CreatedDateTime.UTC(),
+	}
+	if m.ReplyToID != "" && m.ReplyToID != m.ID {
+		msg.ThreadID = m.ReplyToID
+	}
+
+	if m.From != nil {
+		switch {
+		case m.From.User != nil:
+			msg.UserID = m.From.User.ID
+			msg.UserName = m.From.User.DisplayName
+		case m.From.Application != nil:
+			msg.UserName = m.From.Application.DisplayName
+			msg.IsBot = true
+		}
+	}
+	if msg.IsBot && !includeBots {
+		return msg, false
+	}
+	if msg.UserName == "" {
+		msg.UserName = msg.UserID
+	}
+
+	msg.Text = m.Body.Content
+	if strings.EqualFold(m.Body.ContentType, "html") {
+		msg.Text = htmlToText(msg.Text)
+	}
+	for _, a := range m.Attachments {
+		if a.Name != "" {
+			msg.Files = append(msg.Files, a.Name)
+		}
+	}
+
+	if strings.TrimSpace(msg.Text) == "" && len(msg.Files) == 0 {
+		return msg, false
+	}
+	return msg, true
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]+>`)
+)
+
+// htmlToText strips Teams message HTML down to plain text
+func htmlToText(s string) string {
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// sortedKeys returns map keys in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,514 @@
+package slack
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/domain/documents"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	ProviderTypeSlack = "slack"
+
+	SourceTypeSlackConversation = "slack-conversation"
+)
+
+// ProviderConfig contains the decrypted configuration for a provider
+// Mirrors datasource.ProviderConfig to avoid import cycle
+type ProviderConfig struct {
+	IntegrationID string
+	ProjectID     string
+	Config        map[string]interface{}
+	Metadata      map[string]interface{}
+	LastSyncedAt  *time.Time
+}
+
+// SyncOptions contains options for a sync operation
+// Mirrors datasource.SyncOptions to avoid import cycle
+type SyncOptions struct {
+	Limit           int
+	FullSync        bool
+	ConfigurationID string
+	Custom          map[string]interface{}
+
+	// ExportStorageKey is the storage key of an uploaded export archive (export mode)
+	ExportStorageKey string
+
+	// ItemIDs are the thread roots ("<channel id>:<root ts>") changed since
+	// the last sync (webhook-triggered syncs)
+	ItemIDs []string
+}
+
+// SyncResult contains the results of a sync operation
+// Mirrors datasource.SyncResult to avoid import cycle
+type SyncResult struct {
+	TotalItems      int
+	ProcessedItems  int
+	SuccessfulItems int
+	FailedItems     int
+	SkippedItems    int
+	DocumentIDs     []string
+	Errors          []string
+
+	// Cursor is where the next incremental sync resumes (nil = the sync start
+	// time)
+	Cursor *time.Time
+}
+
+// Progress represents the current progress of a sync operation
+// Mirrors datasource.Progress to avoid import cycle
+type Progress struct {
+	Phase           string
+	TotalItems      int
+	ProcessedItems  int
+	SuccessfulItems int
+	FailedItems     int
+	SkippedItems    int
+	Message         string
+}
+
+// ProgressCallback is called by providers to report sync progress
+type ProgressCallback func(progress Progress)
+
+// ExportSource reads uploaded export archives (implemented by storage.Service)
+type ExportSource interface {
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Provider implements the Slack data source provider
+type Provider struct {
+	client  *Client
+	exports ExportSource
+	db      bun.IDB
+	docRepo *documents.Repository
+	log     *slog.Logger
+}
+
+// NewProvider creates a new Slack provider. exports may be nil, in which
+// case export mode is unavailable.
+func NewProvider(db bun.IDB, exports ExportSource, log *slog.Logger) *Provider {
+	return &Provider{
+		client:  NewClient(log),
+		exports: exports,
+		db:      db,
+		docRepo: documents.NewRepository(db, log),
+		log:     log.With(logger.Scope("slack-provider")),
+	}
+}
+
+// ProviderType returns the provider type identifier
+func (p *Provider) ProviderType() string {
+	return ProviderTypeSlack
+}
+
+// TestConnection verifies the bot token (live mode). Export mode has no
+// remote connection to test.
+func (p *Provider) TestConnection(ctx context.Context, config ProviderConfig) error {
+	slackConfig, err := p.parseConfig(config.Config)
+	if err != nil {
+		return err
+	}
+	if slackConfig.EffectiveMode() == ModeExport {
+		if p.exports == nil {
+			return fmt.Errorf("export mode requires object storage to be configured")
+		}
+		return nil
+	}
+
+	if _, err := p.client.AuthTest(ctx, slackConfig.BotToken); err != nil {
+		return fmt.Errorf("connection test failed: %w", err)
+	}
+	return nil
+}
+
+// AcceptsExports reports whether the integration imports uploaded export
+// archives rather than reading the Web API
+func (p *Provider) AcceptsExports(config map[string]interface{}) bool {
+	slackConfig, err := p.parseConfig(config)
+	return err == nil && slackConfig.EffectiveMode() == ModeExport && p.exports != nil
+}
+
+// Sync imports conversations from Slack (live mode) or from an uploaded
+// export archive (export mode). Live syncs resume at the oldest day with a
+// conversation that was not imported, so failed days are read again.
+func (p *Provider) Sync(ctx context.Context, config ProviderConfig, options SyncOptions, progressCB ProgressCallback) (*SyncResult, error) {
+	slackConfig, err := p.parseConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{
+		DocumentIDs: []string{},
+		Errors:      []string{},
+	}
+
+	// Webhook-triggered syncs only re-read the days named in the delivery
+	if len(options.ItemIDs) > 0 {
+		if slackConfig.EffectiveMode() != ModeLive {
+			return result, nil
+		}
+		err := p.syncTargeted(ctx, slackConfig, config.ProjectID, config.IntegrationID, options.ItemIDs, result)
+		return result, err
+	}
+
+	var channels []ChatChannel
+	var source string
+	if slackConfig.EffectiveMode() == ModeExport {
+		if options.ExportStorageKey == "" {
+			// Scheduled syncs have nothing to do until an export is uploaded
+			p.log.Info("no export archive for sync, nothing to import",
+				slog.String("integration_id", config.IntegrationID))
+			return result, nil
+		}
+		channels, source, err = p.readExport(ctx, slackConfig, options.ExportStorageKey, progressCB)
+	} else {
+		var oldest time.Time
+		if !options.FullSync && config.LastSyncedAt != nil {
+			// Re-read whole days so channel-day documents stay complete
+			y, m, d := config.LastSyncedAt.In(slackConfig.Location()).Date()
+			oldest = time.Date(y, m, d, 0, 0, 0, 0, slackConfig.Location())
+		}
+		channels, err = p.readLive(ctx, slackConfig, oldest, progressCB)
+		source = SourceLive
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result, err
+	}
+
+	loc := slackConfig.Location()
+	groupBy := slackConfig.EffectiveGroupBy()
+
+	var conversations []Conversation
+	for _, ch := range channels {
+		conversations = append(conversations, groupConversations(ch, groupBy, loc)...)
+	}
+	result.TotalItems = len(conversations)
+
+	// Conversations that were not imported, failed or beyond the limit
+	var pending []*Conversation
+
+	for i := range conversations {
+		select {
+		case <-ctx.Done():
+			result.Errors = append(result.Errors, "sync cancelled")
+			return result, ctx.Err()
+		default:
+		}
+		if options.Limit > 0 && result.ProcessedItems >= options.Limit {
+			for j := i; j < len(conversations); j++ {
+				pending = append(pending, &conversations[j])
+			}
+			break
+		}
+
+		conv := &conversations[i]
+		docID, skipped, err := p.importConversation(ctx, conv, source, groupBy, loc, config.ProjectID, config.IntegrationID)
+		result.ProcessedItems++
+		switch {
+		case err != nil:
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("#%s %s: %s", conv.ChannelName, conv.Key, err.Error()))
+			pending = append(pending, conv)
+		case skipped:
+			result.SkippedItems++
+		default:
+			result.SuccessfulItems++
+			result.DocumentIDs = append(result.DocumentIDs, docID)
+		}
+
+		if progressCB != nil && (result.ProcessedItems%10 == 0 || result.ProcessedItems == result.TotalItems) {
+			progressCB(Progress{
+				Phase:           "syncing",
+				TotalItems:      result.TotalItems,
+				ProcessedItems:  result.ProcessedItems,
+				SuccessfulItems: result.SuccessfulItems,
+				FailedItems:     result.FailedItems,
+				SkippedItems:    result.SkippedItems,
+				Message:         fmt.Sprintf("Processed %d/%d conversations", result.ProcessedItems, result.TotalItems),
+			})
+		}
+	}
+
+	if source == SourceLive {
+		result.Cursor = resumeCursor(pending, loc)
+	}
+
+	p.log.Info("slack sync completed",
+		slog.String("source", source),
+		slog.Int("channels", len(channels)),
+		slog.Int("total", result.TotalItems),
+		slog.Int("successful", result.SuccessfulItems),
+		slog.Int("failed", result.FailedItems),
+		slog.Int("skipped", result.SkippedItems))
+
+	if result.FailedItems > 0 && result.SuccessfulItems == 0 && result.SkippedItems == 0 {
+		return result, fmt.Errorf("sync failed for all %d conversations", result.FailedItems)
+	}
+
+	return result, nil
+}
+
+// resumeCursor returns the start of the oldest day with a pending
+// conversation, or nil when every conversation was imported
+func resumeCursor(pending []*Conversation, loc *time.Location) *time.Time {
+	var cursor *time.Time
+	for _, conv := range pending {
+		if len(conv.Roots) == 0 {
+			continue
+		}
+		y, m, d := conv.Roots[0].Time.In(loc).Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if cursor == nil || day.Before(*cursor) {
+			cursor = &day
+		}
+	}
+	return cursor
+}
+
+// readLive reads channel history through the Web API. conversations.history
+// only returns messages posted at or after oldest, so only threads whose root
+// is inside that window are re-read: replies added to older threads are not
+// seen by incremental syncs. With Events API webhooks enabled they are
+// imported as they are posted; otherwise the next full sync picks them up.
+func (p *Provider) readLive(ctx context.Context, config *Config, oldest time.Time, progressCB ProgressCallback) ([]ChatChannel, error) {
+	if progressCB != nil {
+		progressCB(Progress{Phase: "discovering", Message: "Discovering Slack channels..."})
+	}
+
+	users, err := p.client.ListUsers(ctx, config.BotToken)
+	if err != nil {
+		return nil, err
+	}
+	dir := newUserDirectory(users)
+
+	all, err := p.client.ListChannels(ctx, config.BotToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var channels []ChatChannel
+	for _, ch := range all {
+		// Without an explicit selection, only channels the bot was added to
+		if !config.WantsChannel(ch) || len(config.Channels) == 0 && !ch.IsMember {
+			continue
+		}
+
+		channel, _, err := p.readChannel(ctx, config, ch, dir, oldest, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
+
+// readChannel reads a channel's messages posted at or after oldest and before
+// latest (zero = unbounded), with the replies of threads rooted in that range.
+// complete is false when some thread's replies could not be read.
+func (p *Provider) readChannel(ctx context.Context, config *Config, ch Channel, dir userDirectory, oldest, latest time.Time) (channel ChatChannel, complete bool, err error) {
+	history, err := p.client.GetHistory(ctx, config.BotToken, ch.ID, oldest, latest)
+	if err != nil {
+		return ChatChannel{}, false, err
+	}
+
+	complete = true
+	messages := history
+	for _, m := range history {
+		if m.ReplyCount == 0 {
+			continue
+		}
+		replies, err := p.client.GetReplies(ctx, config.BotToken, ch.ID, m.TS)
+		if err != nil {
+			p.log.Warn("failed to fetch thread replies",
+				logger.Error(err),
+				slog.String("channel", ch.Name),
+				slog.String("thread_ts", m.TS))
+			complete = false
+			continue
+		}
+		messages = append(messages, replies...)
+	}
+
+	return ChatChannel{
+		ID:       ch.ID,
+		Name:     ch.Name,
+		Messages: normalizeSlackMessages(messages, dir, config.IncludeBots),
+	}, complete, nil
+}
+
+// readExport downloads and parses an export archive
+func (p *Provider) readExport(ctx context.Context, config *Config, key string, progressCB ProgressCallback) ([]ChatChannel, string, error) {
+	if p.exports == nil {
+		return nil, "", fmt.Errorf("export mode requires object storage to be configured")
+	}
+	if progressCB != nil {
+		progressCB(Progress{Phase: "discovering", Message: "Reading export archive..."})
+	}
+
+	rc, err := p.exports.Download(ctx, key)
+	if err != nil {
+		return nil, "", fmt.Errorf("download export: %w", err)
+	}
+	defer rc.Close()
+
+	// zip needs random access, so spool the archive to a temp file
+	tmp, err := os.CreateTemp("", "chat-export-*.zip")
+	if err != nil {
+		return nil, "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, rc)
+	if err != nil {
+		return nil, "", fmt.Errorf("download export: %w", err)
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return nil, "", fmt.Errorf("open export archive: %w", err)
+	}
+
+	export, err := ParseExport(zr, config.IncludeBots)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var channels []ChatChannel
+	for _, ch := range export.Channels {
+		if config.WantsChannel(Channel{ID: ch.ID, Name: ch.Name}) {
+			channels = append(channels, ch)
+		}
+	}
+	return channels, export.Source, nil
+}
+
+// importConversation creates or updates the document for a conversation.
+// Returns the document ID, whether it was skipped (content unchanged), and any error
+func (p *Provider) importConversation(ctx context.Context, conv *Conversation, source, groupBy string, loc *time.Location, projectID, integrationID string) (string, bool, error) {
+	content := conv.render(loc)
+	metadata := conv.buildMetadata(source, groupBy, content, loc)
+
+	existing, err := p.findExisting(ctx, projectID, integrationID, metadata.ConversationID)
+	if err != nil {
+		return "", false, err
+	}
+	if existing != nil {
+		if hash, ok := existing.Metadata["slackContentHash"].(string); ok && hash == metadata.ContentHash {
+			return existing.ID, true, nil
+		}
+	}
+
+	metadataMap := make(map[string]any)
+	metaJSON, _ := json.Marshal(metadata)
+	json.Unmarshal(metaJSON, &metadataMap)
+
+	integrationMetadata := make(map[string]any)
+	imJSON, _ := json.Marshal(conv.buildIntegrationMetadata(source))
+	json.Unmarshal(imJSON, &integrationMetadata)
+
+	title := conv.title(loc)
+
+	if existing != nil {
+		existing.Filename = &title
+		existing.Content = &content
+		existing.Metadata = metadataMap
+		existing.IntegrationMetadata = integrationMetadata
+		existing.UpdatedAt = time.Now()
+
+		if _, err := p.db.NewUpdate().
+			Model(existing).
+			WherePK().
+			Exec(ctx); err != nil {
+			return "", false, fmt.Errorf("update document: %w", err)
+		}
+
+		p.log.Debug("updated document from conversation",
+			slog.String("document_id", existing.ID),
+			slog.String("conversation_id", metadata.ConversationID))
+		return existing.ID, false, nil
+	}
+
+	mimeType := "text/markdown"
+	sourceType := SourceTypeSlackConversation
+	conversionStatus := "not_required"
+
+	document := &documents.Document{
+		ID:                      uuid.New().String(),
+		ProjectID:               projectID,
+		Filename:                &title,
+		Content:                 &content,
+		MimeType:                &mimeType,
+		SourceType:              &sourceType,
+		DataSourceIntegrationID: &integrationID,
+		ConversionStatus:        &conversionStatus,
+		IntegrationMetadata:     integrationMetadata,
+		Metadata:                metadataMap,
+		CreatedAt:               time.Now(),
+		UpdatedAt:               time.Now(),
+	}
+
+	if err := p.docRepo.Create(ctx, document); err != nil {
+		return "", false, fmt.Errorf("create document: %w", err)
+	}
+
+	p.log.Debug("created document from conversation",
+		slog.String("document_id", document.ID),
+		slog.String("conversation_id", metadata.ConversationID))
+
+	return document.ID, false, nil
+}
+
+// parseConfig parses the provider configuration from a map
+func (p *Provider) parseConfig(config map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+
+	var slackConfig Config
+	if err := json.Unmarshal(data, &slackConfig); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if slackConfig.EffectiveMode() == ModeLive && slackConfig.BotToken == "" {
+		return nil, fmt.Errorf("bot token is required in live mode")
+	}
+
+	return &slackConfig, nil
+}
+
+// findExisting finds an existing document by its conversation ID
+func (p *Provider) findExisting(ctx context.Context, projectID, integrationID, conversationID string) (*documents.Document, error) {
+	var doc documents.Document
+	err := p.db.NewSelect().
+		Model(&doc).
+		Where("project_id = ?", projectID).
+		Where("data_source_integration_id = ?", integrationID).
+		Where("metadata->>'slackConversationId' = ?", conversationID).
+		Limit(1).
+		Scan(ctx)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find existing doc: %w", err)
+	}
+
+	return &doc, nil
+}
@@ -0,0 +1,420 @@
+package slack
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(testLogger())
+	client.apiURL = server.URL
+	return client
+}
+
+// buildZip builds an in-memory zip archive from name → JSON value
+func buildZip(t *testing.T, files map[string]any) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, v := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(f).Encode(v))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return r
+}
+
+func TestClient_PaginatesAndChecksEnvelope(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/users.list":
+			if r.URL.Query().Get("cursor") == "" {
+				w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"ada"}],"response_metadata":{"next_cursor":"next"}}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"members":[{"id":"U2","name":"grace"}]}`))
+		case "/auth.test":
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	users, err := client.ListUsers(context.Background(), "xoxb-test")
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "U2", users[1].ID)
+
+	_, err = client.AuthTest(context.Background(), "xoxb-test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_auth")
+}
+
+func TestClient_RetriesRateLimited(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true,"team":"Acme"}`))
+	})
+
+	team, err := client.AuthTest(context.Background(), "xoxb-test")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", team)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClient_GetRepliesExcludesParent(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/conversations.replies", r.URL.Path)
+		w.Write([]byte(`{"ok":true,"messages":[{"ts":"100.000001","text":"root"},{"ts":"101.000001","text":"reply"}]}`))
+	})
+
+	replies, err := client.GetReplies(context.Background(), "xoxb-test", "C1", "100.000001")
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	assert.Equal(t, "reply", replies[0].Text)
+}
+
+func TestClient_GetHistoryBounds(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/conversations.history", r.URL.Path)
+		assert.Equal(t, "1709251200.000000", r.URL.Query().Get("oldest"))
+		assert.Equal(t, "1709337600.000000", r.URL.Query().Get("latest"))
+		w.Write([]byte(`{"ok":true,"messages":[{"ts":"1709280000.000001","text":"hi"}]}`))
+	})
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	messages, err := client.GetHistory(context.Background(), "xoxb-test", "C1", day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+}
+
+func TestUserDirectory_FormatText(t *testing.T) {
+	dir := userDirectory{"U1": "Ada Lovelace"}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "hi <@U1>", want: "hi @Ada Lovelace"},
+		{in: "hi <@U9|bob>", want: "hi @U9"},
+		{in: "see <#C1|general>", want: "see #general"},
+		{in: "see <#C1>", want: "see #C1"},
+		{in: "<https://example.com|docs> and <https://example.com>", want: "docs (https://example.com) and https://example.com"},
+		{in: "<!here> a &lt; b &amp;&amp; c &gt; d", want: "@here a < b && c > d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, dir.formatText(tt.in))
+		})
+	}
+}
+
+func TestNormalizeSlackMessages(t *testing.T) {
+	dir := userDirectory{"U1": "Ada"}
+	msgs := normalizeSlackMessages([]Message{
+		{Type: "message", User: "U1", Text: "hello", TS: "100.000000"},
+		{Type: "message", Subtype: "channel_join", User: "U1", Text: "joined", TS: "101.000000"},
+		{Type: "message", BotID: "B1", Text: "beep", TS: "102.000000"},
+		{Type: "message", User: "U1", Text: "reply", TS: "103.000000", ThreadTS: "100.000000"},
+		{Type: "message", User: "U1", Text: "", TS: "104.000000"},
+	}, dir, false)
+
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "Ada", msgs[0].UserName)
+	assert.Empty(t, msgs[0].ThreadID)
+	assert.Equal(t, "100.000000", msgs[1].ThreadID)
+}
+
+func TestGroupConversations(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	channel := ChatChannel{
+		ID:   "C1",
+		Name: "general",
+		Messages: []ChatMessage{
+			{ID: "1", UserName: "Ada", Text: "standalone", Time: day},
+			{ID: "2", UserName: "Ada", Text: "question", Time: day.Add(time.Hour)},
+			{ID: "3", ThreadID: "2", UserName: "Grace", Text: "answer", Time: day.Add(2 * time.Hour)},
+			{ID: "4", ThreadID: "missing", UserName: "Grace", Text: "orphan", Time: day.Add(24 * time.Hour)},
+		},
+	}
+
+	t.Run("thread", func(t *testing.T) {
+		convs := groupConversations(channel, GroupByThread, time.UTC)
+		require.Len(t, convs, 3)
+		assert.Equal(t, "day:2024-03-01", convs[0].Key)
+		assert.Equal(t, "thread:2", convs[1].Key)
+		require.Len(t, convs[1].Replies["2"], 1)
+		assert.Equal(t, "day:2024-03-02", convs[2].Key)
+		assert.Equal(t, "4", convs[2].Roots[0].ID)
+	})
+
+	t.Run("channel_day", func(t *testing.T) {
+		convs := groupConversations(channel, GroupByChannelDay, time.UTC)
+		require.Len(t, convs, 2)
+		assert.Equal(t, "day:2024-03-01", convs[0].Key)
+		assert.Len(t, convs[0].Roots, 2)
+		assert.Len(t, convs[0].Replies["2"], 1)
+	})
+
+	t.Run("timezone", func(t *testing.T) {
+		loc := time.FixedZone("UTC-10", -10*3600)
+		convs := groupConversations(channel, GroupByChannelDay, loc)
+		assert.Equal(t, "day:2024-02-29", convs[0].Key)
+	})
+}
+
+func TestResumeCursor(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	channel := ChatChannel{
+		ID:   "C1",
+		Name: "general",
+		Messages: []ChatMessage{
+			{ID: "1", UserName: "Ada", Text: "first day", Time: day},
+			{ID: "2", UserName: "Ada", Text: "second day", Time: day.Add(24 * time.Hour)},
+			{ID: "3", UserName: "Ada", Text: "third day", Time: day.Add(48 * time.Hour)},
+		},
+	}
+	convs := groupConversations(channel, GroupByChannelDay, time.UTC)
+	require.Len(t, convs, 3)
+
+	assert.Nil(t, resumeCursor(nil, time.UTC))
+
+	cursor := resumeCursor([]*Conversation{&convs[2], &convs[1]}, time.UTC)
+	require.NotNil(t, cursor)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), *cursor)
+
+	loc := time.FixedZone("UTC-10", -10*3600)
+	cursor = resumeCursor([]*Conversation{&convs[0]}, loc)
+	require.NotNil(t, cursor)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, loc), *cursor)
+}
+
+func TestConversation_Render(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	conv := groupConversations(ChatChannel{
+		ID:   "C1",
+		Name: "general",
+		Messages: []ChatMessage{
+			{ID: "1", UserName: "Ada", Text: "deploy today?", Time: day},
+			{ID: "2", ThreadID: "1", UserName: "Grace", Text: "yes\nafter lunch", Time: day.Add(time.Minute), Files: []string{"plan.pdf"}},
+		},
+	}, GroupByThread, time.UTC)[0]
+
+	content := conv.render(time.UTC)
+	assert.Contains(t, content, "# #general thread 2024-03-01: deploy today?")
+	assert.Contains(t, content, "**Participants:** Ada, Grace")
+	assert.Contains(t, content, "**Ada** (2024-03-01 09:00): deploy today?")
+	assert.Contains(t, content, "> **Grace** (2024-03-01 09:01): yes\n> after lunch [file: plan.pdf]")
+
+	meta := conv.buildMetadata(SourceLive, GroupByThread, content, time.UTC)
+	assert.Equal(t, "C1:thread:1", meta.ConversationID)
+	assert.Equal(t, 2, meta.MessageCount)
+	assert.Equal(t, "2024-03-01", meta.Date)
+	assert.Len(t, meta.ContentHash, 64)
+}
+
+func TestParseExport_Slack(t *testing.T) {
+	r := buildZip(t, map[string]any{
+		"users.json":    []map[string]any{{"id": "U1", "name": "ada", "real_name": "Ada Lovelace"}},
+		"channels.json": []map[string]any{{"id": "C1", "name": "general"}},
+		"general/2024-03-01.json": []map[string]any{
+			{"type": "message", "user": "U1", "text": "hello <@U1>", "ts": "1709283600.000100"},
+			{"type": "message", "user": "U1", "text": "reply", "ts": "1709283700.000100", "thread_ts": "1709283600.000100"},
+			{"type": "message", "subtype": "channel_join", "user": "U1", "text": "joined", "ts": "1709283800.000100"},
+		},
+	})
+
+	export, err := ParseExport(r, false)
+	require.NoError(t, err)
+	assert.Equal(t, SourceSlackExport, export.Source)
+	require.Len(t, export.Channels, 1)
+
+	ch := export.Channels[0]
+	assert.Equal(t, "C1", ch.ID)
+	assert.Equal(t, "general", ch.Name)
+	require.Len(t, ch.Messages, 2)
+	assert.Equal(t, "hello @Ada Lovelace", ch.Messages[0].Text)
+	assert.Equal(t, "Ada Lovelace", ch.Messages[0].UserName)
+	assert.Equal(t, "1709283600.000100", ch.Messages[1].ThreadID)
+}
+
+func TestParseExport_Teams(t *testing.T) {
+	r := buildZip(t, map[string]any{
+		"engineering/messages.json": map[string]any{
+			"value": []map[string]any{
+				{
+					"id": "1", "messageType": "message", "createdDateTime": "2024-03-01T09:00:00Z",
+					"from": map[string]any{"user": map[string]any{"id": "u1", "displayName": "Ada"}},
+					"body": map[string]any{"contentType": "html", "content": "<p>Hello &amp; welcome</p><p>team</p>"},
+				},
+				{
+					"id": "2", "replyToId": "1", "messageType": "message", "createdDateTime": "2024-03-01T09:05:00Z",
+					"from": map[string]any{"user": map[string]any{"id": "u2", "displayName": "Grace"}},
+					"body": map[string]any{"contentType": "text", "content": "thanks"},
+				},
+				{
+					"id": "3", "messageType": "systemEventMessage", "createdDateTime": "2024-03-01T09:06:00Z",
+					"body": map[string]any{"content": "member added"},
+				},
+				{
+					"id": "4", "messageType": "message", "createdDateTime": "2024-03-01T09:07:00Z",
+					"deletedDateTime": "2024-03-01T09:08:00Z",
+					"body":            map[string]any{"content": "oops"},
+				},
+			},
+		},
+	})
+
+	export, err := ParseExport(r, false)
+	require.NoError(t, err)
+	assert.Equal(t, SourceTeamsExport, export.Source)
+	require.Len(t, export.Channels, 1)
+
+	ch := export.Channels[0]
+	assert.Equal(t, "engineering", ch.Name)
+	require.Len(t, ch.Messages, 2)
+	assert.Equal(t, "Hello & welcome\nteam", ch.Messages[0].Text)
+	assert.Equal(t, "1", ch.Messages[1].ThreadID)
+}
+
+func TestConfig_Defaults(t *testing.T) {
+	p := NewProvider(nil, nil, testLogger())
+
+	_, err := p.parseConfig(map[string]interface{}{})
+	require.Error(t, err, "live mode requires a bot token")
+
+	cfg, err := p.parseConfig(map[string]interface{}{"mode": ModeExport})
+	require.NoError(t, err)
+	assert.Equal(t, GroupByThread, cfg.EffectiveGroupBy())
+	assert.False(t, p.AcceptsExports(map[string]interface{}{"mode": ModeExport}), "no export source configured")
+
+	assert.True(t, cfg.WantsChannel(Channel{ID: "C1", Name: "general"}))
+	cfg.Channels = []string{"#General"}
+	assert.True(t, cfg.WantsChannel(Channel{ID: "C1", Name: "general"}))
+	assert.False(t, cfg.WantsChannel(Channel{ID: "C2", Name: "random"}))
+	assert.Equal(t, time.UTC, cfg.Location())
+}
+
+func TestProvider_VerifyWebhook(t *testing.T) {
+	p := &Provider{}
+	body := []byte(`{"type":"event_callback"}`)
+
+	sign := func(secret, timestamp string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + timestamp + ":"))
+		mac.Write(body)
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := http.Header{}
+	headers.Set(TimestampHeader, now)
+	headers.Set(SignatureHeader, sign("secret", now))
+	assert.NoError(t, p.VerifyWebhook("secret", headers, body))
+	assert.Error(t, p.VerifyWebhook("other", headers, body))
+
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	headers.Set(TimestampHeader, stale)
+	headers.Set(SignatureHeader, sign("secret", stale))
+	assert.Error(t, p.VerifyWebhook("secret", headers, body), "replayed request")
+
+	assert.Error(t, p.VerifyWebhook("secret", http.Header{}, body))
+}
+
+func TestProvider_ParseWebhook(t *testing.T) {
+	p := &Provider{}
+
+	t.Run("url verification", func(t *testing.T) {
+		payload, err := p.ParseWebhook(nil, []byte(`{"type":"url_verification","challenge":"abc"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", payload.Challenge)
+		assert.Empty(t, payload.UpsertedIDs)
+	})
+
+	tests := []struct {
+		name         string
+		event        string
+		wantEvent    string
+		wantUpserted []string
+	}{
+		{
+			name:         "new message",
+			event:        `{"type":"message","channel":"C1","ts":"100.000001"}`,
+			wantEvent:    "message",
+			wantUpserted: []string{"C1:100.000001"},
+		},
+		{
+			name:         "thread reply",
+			event:        `{"type":"message","channel":"C1","ts":"200.000001","thread_ts":"100.000001"}`,
+			wantEvent:    "message",
+			wantUpserted: []string{"C1:100.000001"},
+		},
+		{
+			name:         "edited reply",
+			event:        `{"type":"message","subtype":"message_changed","channel":"C1","ts":"300.000001","message":{"ts":"200.000001","thread_ts":"100.000001"}}`,
+			wantEvent:    "message.message_changed",
+			wantUpserted: []string{"C1:100.000001"},
+		},
+		{
+			name:         "deleted message",
+			event:        `{"type":"message","subtype":"message_deleted","channel":"C1","ts":"300.000001","deleted_ts":"200.000001","previous_message":{"ts":"200.000001"}}`,
+			wantEvent:    "message.message_deleted",
+			wantUpserted: []string{"C1:200.000001"},
+		},
+		{
+			name:      "other event",
+			event:     `{"type":"reaction_added","item":{"channel":"C1","ts":"100.000001"}}`,
+			wantEvent: "reaction_added",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"type":"event_callback","event_id":"Ev1","event":` + tt.event + `}`
+			payload, err := p.ParseWebhook(nil, []byte(body))
+			require.NoError(t, err)
+			assert.Equal(t, "Ev1", payload.DeliveryID)
+			assert.Equal(t, tt.wantEvent, payload.EventType)
+			assert.Equal(t, tt.wantUpserted, payload.UpsertedIDs)
+		})
+	}
+
+	_, err := p.ParseWebhook(nil, []byte(`{"type":"event_callback"}`))
+	assert.Error(t, err)
+}
+
+// testLogger discards output so test runs do not write log files.
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
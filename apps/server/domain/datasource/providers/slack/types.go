@@ -0,0 +1,279 @@
+// Package slack provides a data source provider for Slack channels and for
+// Slack / Microsoft Teams export archives.
+package slack
+
+import (
+	"strings"
+	"time"
+)
+
+// Sync modes
+const (
+	// ModeLive reads channels through the Slack Web API with a bot token
+	ModeLive = "live"
+
+	// ModeExport reads an uploaded export archive and never calls Slack
+	ModeExport = "export"
+)
+
+// Conversation grouping
+const (
+	// GroupByThread creates one document per thread; messages outside
+	// threads are grouped per channel-day
+	GroupByThread = "thread"
+
+	// GroupByChannelDay creates one document per channel per day, with
+	// thread replies nested under their parent
+	GroupByChannelDay = "channel_day"
+)
+
+// Config represents the Slack provider configuration.
+// This is stored encrypted in DataSourceIntegration.config_encrypted.
+type Config struct {
+	// Mode is "live" (default) or "export"
+	Mode string `json:"mode,omitempty"`
+
+	// BotToken is the Slack bot token (xoxb-...), required in live mode
+	BotToken string `json:"botToken,omitempty"`
+
+	// Channels restricts the sync to these channel IDs or names
+	// (empty = every channel the bot is a member of, or every channel in the export)
+	Channels []string `json:"channels,omitempty"`
+
+	// GroupBy is "thread" (default) or "channel_day"
+	GroupBy string `json:"groupBy,omitempty"`
+
+	// IncludeBots includes messages posted by bots and integrations
+	IncludeBots bool `json:"includeBots,omitempty"`
+
+	// Timezone is the IANA zone used to split channel-days (default UTC)
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// EffectiveMode returns the configured mode, defaulting to live
+func (c *Config) EffectiveMode() string {
+	if c.Mode == ModeExport {
+		return ModeExport
+	}
+	return ModeLive
+}
+
+// EffectiveGroupBy returns the configured grouping, defaulting to thread
+func (c *Config) EffectiveGroupBy() string {
+	if c.GroupBy == GroupByChannelDay {
+		return GroupByChannelDay
+	}
+	return GroupByThread
+}
+
+// Location returns the configured timezone (UTC if unset or invalid)
+func (c *Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// WantsChannel reports whether a channel is selected by Channels
+func (c *Config) WantsChannel(ch Channel) bool {
+	if len(c.Channels) == 0 {
+		return true
+	}
+	for _, sel := range c.Channels {
+		sel = strings.TrimPrefix(strings.TrimSpace(sel), "#")
+		if sel == ch.ID || strings.EqualFold(sel, ch.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// Slack Web API Types
+// ----------------------------------------------------------------------------
+
+// apiResponse is the envelope shared by all Slack Web API responses
+type apiResponse struct {
+	OK               bool   `json:"ok"`
+	Error            string `json:"error,omitempty"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// Channel is a Slack conversation (public or private channel)
+type Channel struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsPrivate  bool   `json:"is_private"`
+	IsArchived bool   `json:"is_archived"`
+	IsMember   bool   `json:"is_member"`
+	Topic      struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+}
+
+// User is a Slack workspace member
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	IsBot    bool   `json:"is_bot"`
+	Deleted  bool   `json:"deleted"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+// DisplayName returns the best human-readable name for the user
+func (u *User) DisplayName() string {
+	for _, name := range []string{u.Profile.RealName, u.RealName, u.Profile.DisplayName, u.Name} {
+		if name != "" {
+			return name
+		}
+	}
+	return u.ID
+}
+
+// Message is a Slack message as returned by the API and in exports
+type Message struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	User        string `json:"user,omitempty"`
+	BotID       string `json:"bot_id,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+	ReplyCount  int    `json:"reply_count,omitempty"`
+	UserProfile *struct {
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"user_profile,omitempty"`
+	Files []struct {
+		Name string `json:"name"`
+	} `json:"files,omitempty"`
+}
+
+// ----------------------------------------------------------------------------
+// Normalized Types (shared by live, Slack export and Teams export)
+// ----------------------------------------------------------------------------
+
+// ChatMessage is a source-independent chat message
+type ChatMessage struct {
+	ID       string
+	ThreadID string // parent message ID for replies; empty for top-level messages
+	UserID   string
+	UserName string
+	Text     string
+	Time     time.Time
+	IsBot    bool
+	Files    []string
+}
+
+// ChatChannel is a channel with its messages
+type ChatChannel struct {
+	ID       string
+	Name     string
+	Messages []ChatMessage
+}
+
+// Conversation is a group of messages that becomes one document
+type Conversation struct {
+	ChannelID   string
+	ChannelName string
+
+	// Key identifies the conversation within the channel
+	// ("thread:<parent id>" or "day:YYYY-MM-DD")
+	Key string
+
+	// Roots are the top-level messages in time order
+	Roots []ChatMessage
+
+	// Replies maps root message IDs to their replies in time order
+	Replies map[string][]ChatMessage
+}
+
+// ----------------------------------------------------------------------------
+// Document Metadata
+// ----------------------------------------------------------------------------
+
+// DocumentMetadata is stored in document.metadata for conversation documents
+type DocumentMetadata struct {
+	// ConversationID uniquely identifies the document within the integration
+	// ("<channel id>:<conversation key>")
+	ConversationID string   `json:"slackConversationId"`
+	ChannelID      string   `json:"channelId"`
+	ChannelName    string   `json:"channelName"`
+	Source         string   `json:"source"` // "slack", "slack-export", "teams-export"
+	GroupBy        string   `json:"groupBy"`
+	Date           string   `json:"date,omitempty"`
+	MessageCount   int      `json:"messageCount"`
+	Participants   []string `json:"participants,omitempty"`
+	FirstMessageAt string   `json:"firstMessageAt,omitempty"`
+	LastMessageAt  string   `json:"lastMessageAt,omitempty"`
+	ContentHash    string   `json:"slackContentHash"`
+	Provider       string   `json:"provider"`
+}
+
+// ThreadMetadata describes one thread in document.integration_metadata
+type ThreadMetadata struct {
+	RootID       string   `json:"rootId"`
+	RootUser     string   `json:"rootUser,omitempty"`
+	RootAt       string   `json:"rootAt"`
+	ReplyIDs     []string `json:"replyIds,omitempty"`
+	Participants []string `json:"participants,omitempty"`
+}
+
+// ConfigSchema is the JSON schema for provider configuration (used by UI)
+var ConfigSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"mode": map[string]interface{}{
+			"type":        "string",
+			"title":       "Mode",
+			"description": "live reads channels with a bot token; export imports uploaded Slack or Teams export archives offline",
+			"enum":        []string{ModeLive, ModeExport},
+			"default":     ModeLive,
+		},
+		"botToken": map[string]interface{}{
+			"type":           "string",
+			"title":          "Bot Token",
+			"description":    "Slack bot token with channels:history, channels:read, groups:history, groups:read and users:read scopes (live mode)",
+			"format":         "password",
+			"ui:placeholder": "xoxb-...",
+		},
+		"channels": map[string]interface{}{
+			"type":        "array",
+			"title":       "Channels",
+			"description": "Channel names or IDs to sync (empty = all channels the bot is in, or all channels in the export)",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+		"groupBy": map[string]interface{}{
+			"type":        "string",
+			"title":       "Group Messages By",
+			"description": "thread: one document per thread; channel_day: one document per channel per day",
+			"enum":        []string{GroupByThread, GroupByChannelDay},
+			"default":     GroupByThread,
+		},
+		"includeBots": map[string]interface{}{
+			"type":    "boolean",
+			"title":   "Include Bot Messages",
+			"default": false,
+		},
+		"timezone": map[string]interface{}{
+			"type":           "string",
+			"title":          "Timezone",
+			"description":    "Timezone used to split messages into days",
+			"ui:placeholder": "UTC",
+		},
+	},
+}
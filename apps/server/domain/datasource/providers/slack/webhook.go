@@ -0,0 +1,298 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries "v0=" + hex HMAC-SHA256 of "v0:<timestamp>:<body>",
+	// keyed by the app's signing secret
+	SignatureHeader = "X-Slack-Signature"
+
+	// TimestampHeader is the Unix time the request was signed at
+	TimestampHeader = "X-Slack-Request-Timestamp"
+
+	// signatureVersion is the only signing scheme Slack uses
+	signatureVersion = "v0"
+
+	// maxSignatureAge rejects replays of old signed requests
+	maxSignatureAge = 5 * time.Minute
+)
+
+// WebhookPayload is a parsed Slack Events API delivery.
+// Mirrors datasource.WebhookDelivery to avoid import cycle
+type WebhookPayload struct {
+	DeliveryID  string
+	EventType   string
+	Challenge   string
+	UpsertedIDs []string
+}
+
+// eventMessage holds the message fields used from message events
+type eventMessage struct {
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+}
+
+// webhookBody is the JSON body of url_verification and event_callback requests
+type webhookBody struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	EventID   string `json:"event_id"`
+	Event     *struct {
+		Type    string `json:"type"`
+		Subtype string `json:"subtype"`
+		Channel string `json:"channel"`
+		eventMessage
+		Message         *eventMessage `json:"message"`
+		PreviousMessage *eventMessage `json:"previous_message"`
+	} `json:"event"`
+}
+
+// VerifyWebhook checks the X-Slack-Signature header against the signing secret
+// and rejects requests signed more than five minutes ago
+func (p *Provider) VerifyWebhook(secret string, headers http.Header, body []byte) error {
+	timestamp := strings.TrimSpace(headers.Get(TimestampHeader))
+	if timestamp == "" {
+		return fmt.Errorf("missing %s header", TimestampHeader)
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed %s header", TimestampHeader)
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return fmt.Errorf("request timestamp outside the allowed window")
+	}
+
+	signature, ok := strings.CutPrefix(strings.TrimSpace(headers.Get(SignatureHeader)), signatureVersion+"=")
+	if !ok {
+		return fmt.Errorf("missing or malformed %s header", SignatureHeader)
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// ParseWebhook converts an Events API request into item changes. A
+// url_verification request yields its challenge; message events yield the
+// thread root they belong to as "<channel id>:<root ts>". Other events yield
+// no items.
+func (p *Provider) ParseWebhook(headers http.Header, body []byte) (*WebhookPayload, error) {
+	var wb webhookBody
+	if err := json.Unmarshal(body, &wb); err != nil {
+		return nil, fmt.Errorf("parse webhook body: %w", err)
+	}
+	if wb.Type == "" {
+		return nil, fmt.Errorf("missing type")
+	}
+
+	// Slack retries a delivery with the same event ID
+	payload := &WebhookPayload{
+		DeliveryID: wb.EventID,
+		EventType:  wb.Type,
+	}
+
+	switch wb.Type {
+	case "url_verification":
+		if wb.Challenge == "" {
+			return nil, fmt.Errorf("missing challenge")
+		}
+		payload.Challenge = wb.Challenge
+		return payload, nil
+	case "event_callback":
+	default:
+		return payload, nil
+	}
+
+	if wb.Event == nil {
+		return nil, fmt.Errorf("missing event")
+	}
+	payload.EventType = wb.Event.Type
+	if wb.Event.Subtype != "" {
+		payload.EventType += "." + wb.Event.Subtype
+	}
+	if wb.Event.Type != "message" || wb.Event.Channel == "" {
+		return payload, nil
+	}
+
+	// Edits and deletions describe the affected message separately
+	msg := &wb.Event.eventMessage
+	switch {
+	case wb.Event.Message != nil:
+		msg = wb.Event.Message
+	case wb.Event.PreviousMessage != nil:
+		msg = wb.Event.PreviousMessage
+	}
+
+	root := msg.ThreadTS
+	if root == "" {
+		root = msg.TS
+	}
+	if root != "" {
+		payload.UpsertedIDs = []string{wb.Event.Channel + ":" + root}
+	}
+	return payload, nil
+}
+
+// syncTargeted re-reads the channel-days holding the given thread roots
+// (webhook-triggered syncs). Re-reading whole days keeps channel-day documents
+// complete and picks up replies to threads rooted before the incremental sync
+// window. Documents of conversations that no longer exist are removed.
+func (p *Provider) syncTargeted(ctx context.Context, config *Config, projectID, integrationID string, itemIDs []string, result *SyncResult) error {
+	loc := config.Location()
+	groupBy := config.EffectiveGroupBy()
+
+	// Thread roots per channel-day
+	type channelDay struct {
+		channelID string
+		day       time.Time
+	}
+	roots := make(map[channelDay][]string)
+	var order []channelDay
+	for _, id := range itemIDs {
+		channelID, ts, ok := strings.Cut(id, ":")
+		rootTime := parseTS(ts)
+		if !ok || channelID == "" || rootTime.IsZero() {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid item ID %q", id))
+			continue
+		}
+		y, m, d := rootTime.In(loc).Date()
+		key := channelDay{channelID: channelID, day: time.Date(y, m, d, 0, 0, 0, 0, loc)}
+		if _, ok := roots[key]; !ok {
+			order = append(order, key)
+		}
+		roots[key] = append(roots[key], ts)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	users, err := p.client.ListUsers(ctx, config.BotToken)
+	if err != nil {
+		return err
+	}
+	dir := newUserDirectory(users)
+
+	all, err := p.client.ListChannels(ctx, config.BotToken)
+	if err != nil {
+		return err
+	}
+	channels := make(map[string]Channel, len(all))
+	for _, ch := range all {
+		channels[ch.ID] = ch
+	}
+
+	for _, key := range order {
+		select {
+		case <-ctx.Done():
+			result.Errors = append(result.Errors, "sync cancelled")
+			return ctx.Err()
+		default:
+		}
+
+		// Same channel selection as scheduled syncs
+		ch, ok := channels[key.channelID]
+		if !ok || !config.WantsChannel(ch) || len(config.Channels) == 0 && !ch.IsMember {
+			result.TotalItems++
+			result.ProcessedItems++
+			result.SkippedItems++
+			continue
+		}
+
+		channel, complete, err := p.readChannel(ctx, config, ch, dir, key.day, key.day.AddDate(0, 0, 1))
+		if err != nil {
+			result.TotalItems++
+			result.ProcessedItems++
+			result.FailedItems++
+			result.Errors = append(result.Errors, fmt.Sprintf("#%s %s: %s", ch.Name, key.day.Format("2006-01-02"), err.Error()))
+			continue
+		}
+
+		conversations := groupConversations(channel, groupBy, loc)
+		present := make(map[string]bool, len(conversations))
+		for i := range conversations {
+			conv := &conversations[i]
+			present[ch.ID+":"+conv.Key] = true
+
+			result.TotalItems++
+			result.ProcessedItems++
+			docID, skipped, err := p.importConversation(ctx, conv, SourceLive, groupBy, loc, projectID, integrationID)
+			switch {
+			case err != nil:
+				result.FailedItems++
+				result.Errors = append(result.Errors, fmt.Sprintf("#%s %s: %s", conv.ChannelName, conv.Key, err.Error()))
+			case skipped:
+				result.SkippedItems++
+			default:
+				result.SuccessfulItems++
+				result.DocumentIDs = append(result.DocumentIDs, docID)
+			}
+		}
+
+		// A deleted message can empty its day or end its thread. Without all
+		// replies a thread looks like a plain message, so keep its document.
+		if !complete {
+			continue
+		}
+		stale := []string{ch.ID + ":day:" + key.day.Format("2006-01-02")}
+		for _, ts := range roots[key] {
+			stale = append(stale, ch.ID+":thread:"+ts)
+		}
+		for _, conversationID := range stale {
+			if present[conversationID] {
+				continue
+			}
+			p.removeConversation(ctx, projectID, integrationID, conversationID, result)
+		}
+	}
+
+	if result.FailedItems > 0 && result.SuccessfulItems == 0 && result.SkippedItems == 0 {
+		return fmt.Errorf("targeted sync failed for all %d conversations", result.FailedItems)
+	}
+	return nil
+}
+
+// removeConversation deletes the document of a conversation that no longer
+// exists at the source, if there is one
+func (p *Provider) removeConversation(ctx context.Context, projectID, integrationID, conversationID string, result *SyncResult) {
+	existing, err := p.findExisting(ctx, projectID, integrationID, conversationID)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", conversationID, err.Error()))
+		return
+	}
+	if existing == nil {
+		return
+	}
+
+	result.TotalItems++
+	result.ProcessedItems++
+	if _, err := p.docRepo.DeleteWithCascade(ctx, projectID, existing.ID); err != nil {
+		result.FailedItems++
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", conversationID, err.Error()))
+		return
+	}
+	result.SuccessfulItems++
+	p.log.Debug("deleted document for removed conversation",
+		slog.String("document_id", existing.ID),
+		slog.String("conversation_id", conversationID))
+}
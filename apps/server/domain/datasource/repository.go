@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 
 	"github.com/uptrace/bun"
@@ -249,3 +250,75 @@ func (r *Repository) GetSyncJob(ctx context.Context, integrationID, jobID string
 	}
 	return job, nil
 }
+
+// CreateWebhookDelivery records a webhook delivery.
+// Returns false without error when the delivery ID was already recorded (a replay).
+func (r *Repository) CreateWebhookDelivery(ctx context.Context, delivery *DataSourceWebhookDelivery) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(delivery).
+		On("CONFLICT (integration_id, delivery_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// CreateWebhookDeliveryJob records a webhook delivery together with the sync
+// job it queues, in one transaction, so a delivery is only marked as seen once
+// its job exists and the provider's retry of a failed delivery is processed.
+// Returns false without error, and creates no job, when the delivery ID was
+// already recorded (a replay).
+func (r *Repository) CreateWebhookDeliveryJob(ctx context.Context, delivery *DataSourceWebhookDelivery, job *DataSourceSyncJob) (bool, error) {
+	inserted := false
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewInsert().
+			Model(delivery).
+			On("CONFLICT (integration_id, delivery_id) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+
+		if _, err := tx.NewInsert().Model(job).Exec(ctx); err != nil {
+			return fmt.Errorf("create sync job: %w", err)
+		}
+		if _, err := tx.NewUpdate().
+			Model((*DataSourceWebhookDelivery)(nil)).
+			Set("sync_job_id = ?", job.ID).
+			Where("id = ?", delivery.ID).
+			Exec(ctx); err != nil {
+			return err
+		}
+		delivery.SyncJobID = &job.ID
+		inserted = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return inserted, nil
+}
+
+// ListWebhookDeliveries returns the most recent webhook deliveries for an integration
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, integrationID string, limit int) ([]*DataSourceWebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var deliveries []*DataSourceWebhookDelivery
+	err := r.db.NewSelect().
+		Model(&deliveries).
+		Where("integration_id = ?", integrationID).
+		OrderExpr("received_at DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
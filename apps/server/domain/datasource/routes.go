@@ -30,10 +30,22 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
 	// Integration operations
 	dsi.POST("/:id/test-connection", h.TestConnection)
 	dsi.POST("/:id/sync", h.TriggerSync)
+	dsi.POST("/:id/export", h.UploadExport)
+	dsi.POST("/:id/mapping/preview", h.PreviewMapping)
 
 	// Sync jobs
 	dsi.GET("/:id/sync-jobs", h.ListSyncJobs)
 	dsi.GET("/:id/sync-jobs/latest", h.GetLatestSyncJob)
 	dsi.GET("/:id/sync-jobs/:jobId", h.GetSyncJob)
 	dsi.POST("/:id/sync-jobs/:jobId/cancel", h.CancelSyncJob)
+
+	// Webhook configuration and delivery log
+	dsi.POST("/:id/webhook-secret", h.SetWebhookSecret)
+	dsi.DELETE("/:id/webhook-secret", h.DeleteWebhookSecret)
+	dsi.GET("/:id/webhook-deliveries", h.ListWebhookDeliveries)
+
+	// --- Public Webhook Receiver ---
+	// NOTE: Does not use RequireAuth; deliveries are authenticated by the
+	// provider's HMAC signature against the integration's signing secret
+	e.POST("/api/data-source-integrations/:id/webhook", h.ReceiveWebhook)
 }
@@ -0,0 +1,163 @@
+package datasource
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// WebhookSecretPrefix helps identify generated webhook signing secrets
+	WebhookSecretPrefix = "whsec_"
+
+	// WebhookSecretBytes is the number of random bytes in a generated secret
+	WebhookSecretBytes = 32
+
+	// MaxWebhookBodyBytes caps the size of an inbound webhook payload
+	MaxWebhookBodyBytes = 1 << 20 // 1 MiB
+
+	// webhookSecretKey is the key the secret is stored under in the encrypted blob
+	webhookSecretKey = "secret"
+)
+
+// WebhookAction describes what happened to an item at the source
+type WebhookAction string
+
+const (
+	WebhookActionUpsert WebhookAction = "upsert"
+	WebhookActionDelete WebhookAction = "delete"
+)
+
+// WebhookEvent is a single item change parsed from a webhook delivery
+type WebhookEvent struct {
+	ItemID string
+	Action WebhookAction
+}
+
+// WebhookDelivery is a parsed, provider-independent webhook payload
+type WebhookDelivery struct {
+	// DeliveryID uniquely identifies the delivery at the source and is used
+	// to reject replays. Empty means the receiver derives one from the body.
+	DeliveryID string
+
+	// EventType is the provider's event name (e.g. "taskUpdated", "issues")
+	EventType string
+
+	// Events are the item changes carried by the delivery (may be empty for
+	// events that don't map to syncable items, such as pings)
+	Events []WebhookEvent
+
+	// Challenge is echoed back for endpoint verification requests (Slack's
+	// url_verification), which are answered without recording a delivery
+	Challenge string
+}
+
+// WebhookProvider is implemented by providers that accept push-based updates.
+// The receiver verifies the signature before parsing, so ParseWebhook only ever
+// sees authenticated payloads.
+type WebhookProvider interface {
+	// VerifyWebhook checks the request signature against the integration's signing secret
+	VerifyWebhook(secret string, headers http.Header, body []byte) error
+
+	// ParseWebhook converts a verified payload into item events
+	ParseWebhook(headers http.Header, body []byte) (*WebhookDelivery, error)
+}
+
+// GenerateWebhookSecret creates a new random signing secret
+func GenerateWebhookSecret() (string, error) {
+	bytes := make([]byte, WebhookSecretBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return WebhookSecretPrefix + hex.EncodeToString(bytes), nil
+}
+
+// bodyDeliveryID derives a delivery ID from the payload for providers that
+// don't send one. Identical replays hash to the same ID.
+func bodyDeliveryID(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// splitWebhookEvents separates events into upserted and deleted item IDs,
+// de-duplicating while preserving order. A delete wins over an earlier upsert
+// of the same item in the same delivery.
+func splitWebhookEvents(events []WebhookEvent) (upserts []string, deletes []string) {
+	final := make(map[string]WebhookAction, len(events))
+	var order []string
+	for _, e := range events {
+		if e.ItemID == "" {
+			continue
+		}
+		if _, seen := final[e.ItemID]; !seen {
+			order = append(order, e.ItemID)
+		}
+		if e.Action == WebhookActionDelete || final[e.ItemID] != WebhookActionDelete {
+			final[e.ItemID] = e.Action
+		}
+	}
+	for _, id := range order {
+		if final[id] == WebhookActionDelete {
+			deletes = append(deletes, id)
+		} else {
+			upserts = append(upserts, id)
+		}
+	}
+	return upserts, deletes
+}
+
+// newWebhookSyncJob builds a targeted sync job for a verified webhook delivery
+func newWebhookSyncJob(integration *DataSourceIntegration, delivery *WebhookDelivery, upserts, deletes []string) *DataSourceSyncJob {
+	options := JSON{
+		"webhookDeliveryId": delivery.DeliveryID,
+		"webhookEvent":      delivery.EventType,
+	}
+	if len(upserts) > 0 {
+		options["itemIds"] = upserts
+	}
+	if len(deletes) > 0 {
+		options["deletedItemIds"] = deletes
+	}
+
+	return &DataSourceSyncJob{
+		IntegrationID: integration.ID,
+		ProjectID:     integration.ProjectID,
+		Status:        JobStatusPending,
+		TriggerType:   TriggerTypeWebhook,
+		MaxRetries:    3,
+		SyncOptions:   options,
+		DocumentIDs:   JSONArray{},
+		Logs: JSONArray{
+			SyncJobLogEntry{
+				Timestamp: time.Now(),
+				Level:     "info",
+				Message:   fmt.Sprintf("Webhook delivery %s (%s): %d changed, %d deleted", delivery.DeliveryID, delivery.EventType, len(upserts), len(deletes)),
+				Details: JSON{
+					"deliveryId": delivery.DeliveryID,
+					"event":      delivery.EventType,
+				},
+			},
+		},
+	}
+}
+
+// optionStrings reads a string list from job sync options.
+// JSONB round-trips turn []string into []interface{}, so both are accepted.
+func optionStrings(options JSON, key string) []string {
+	switch v := options[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
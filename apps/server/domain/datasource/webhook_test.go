@@ -0,0 +1,105 @@
+package datasource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	a, err := GenerateWebhookSecret()
+	require.NoError(t, err)
+	b, err := GenerateWebhookSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(a, WebhookSecretPrefix))
+	assert.Len(t, a, len(WebhookSecretPrefix)+WebhookSecretBytes*2)
+	assert.NotEqual(t, a, b)
+}
+
+func TestBodyDeliveryID(t *testing.T) {
+	a := bodyDeliveryID([]byte(`{"event":"taskUpdated"}`))
+	assert.Equal(t, a, bodyDeliveryID([]byte(`{"event":"taskUpdated"}`)))
+	assert.NotEqual(t, a, bodyDeliveryID([]byte(`{"event":"taskCreated"}`)))
+	assert.True(t, strings.HasPrefix(a, "sha256:"))
+}
+
+func TestSplitWebhookEvents(t *testing.T) {
+	tests := []struct {
+		name            string
+		events          []WebhookEvent
+		expectedUpserts []string
+		expectedDeletes []string
+	}{
+		{
+			name:   "empty",
+			events: nil,
+		},
+		{
+			name: "dedupes preserving order",
+			events: []WebhookEvent{
+				{ItemID: "b", Action: WebhookActionUpsert},
+				{ItemID: "a", Action: WebhookActionUpsert},
+				{ItemID: "b", Action: WebhookActionUpsert},
+			},
+			expectedUpserts: []string{"b", "a"},
+		},
+		{
+			name: "delete wins over upsert in either order",
+			events: []WebhookEvent{
+				{ItemID: "a", Action: WebhookActionUpsert},
+				{ItemID: "a", Action: WebhookActionDelete},
+				{ItemID: "b", Action: WebhookActionDelete},
+				{ItemID: "b", Action: WebhookActionUpsert},
+				{ItemID: "c", Action: WebhookActionUpsert},
+			},
+			expectedUpserts: []string{"c"},
+			expectedDeletes: []string{"a", "b"},
+		},
+		{
+			name: "skips empty item IDs",
+			events: []WebhookEvent{
+				{ItemID: "", Action: WebhookActionUpsert},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upserts, deletes := splitWebhookEvents(tt.events)
+			assert.Equal(t, tt.expectedUpserts, upserts)
+			assert.Equal(t, tt.expectedDeletes, deletes)
+		})
+	}
+}
+
+func TestNewWebhookSyncJob(t *testing.T) {
+	integration := &DataSourceIntegration{ID: "int-1", ProjectID: "proj-1"}
+	delivery := &WebhookDelivery{DeliveryID: "wh:1", EventType: "taskUpdated"}
+
+	job := newWebhookSyncJob(integration, delivery, []string{"t1"}, []string{"t2"})
+
+	assert.Equal(t, "int-1", job.IntegrationID)
+	assert.Equal(t, "proj-1", job.ProjectID)
+	assert.Equal(t, JobStatusPending, job.Status)
+	assert.Equal(t, TriggerTypeWebhook, job.TriggerType)
+	assert.Equal(t, []string{"t1"}, optionStrings(job.SyncOptions, "itemIds"))
+	assert.Equal(t, []string{"t2"}, optionStrings(job.SyncOptions, "deletedItemIds"))
+	assert.Equal(t, "wh:1", job.SyncOptions["webhookDeliveryId"])
+	assert.Len(t, job.Logs, 1)
+}
+
+func TestOptionStrings(t *testing.T) {
+	options := JSON{
+		"typed":   []string{"a", "b"},
+		"decoded": []interface{}{"a", 1, "", "b"},
+		"scalar":  "a",
+	}
+
+	assert.Equal(t, []string{"a", "b"}, optionStrings(options, "typed"))
+	assert.Equal(t, []string{"a", "b"}, optionStrings(options, "decoded"))
+	assert.Nil(t, optionStrings(options, "scalar"))
+	assert.Nil(t, optionStrings(options, "missing"))
+}
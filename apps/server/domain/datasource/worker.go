@@ -16,6 +16,7 @@ type Worker struct {
 	jobs       *JobsService
 	registry   *ProviderRegistry
 	encryption *encryption.Service
+	mapper     *Mapper
 	cfg        *Config
 	log        *slog.Logger
 	stopCh     chan struct{}
@@ -33,11 +34,12 @@ type Worker struct {
 }
 
 // NewWorker creates a new data source sync worker
-func NewWorker(jobs *JobsService, registry *ProviderRegistry, enc *encryption.Service, cfg *Config, log *slog.Logger) *Worker {
+func NewWorker(jobs *JobsService, registry *ProviderRegistry, enc *encryption.Service, mapper *Mapper, cfg *Config, log *slog.Logger) *Worker {
 	return &Worker{
 		jobs:       jobs,
 		registry:   registry,
 		encryption: enc,
+		mapper:     mapper,
 		cfg:        cfg,
 		log:        log.With(logger.Scope("datasource.worker")),
 	}
@@ -234,12 +236,19 @@ func (w *Worker) processJob(ctx context.Context, job *DataSourceSyncJob) error {
 		ProjectID:     integration.ProjectID,
 		Config:        config,
 		Metadata:      integration.Metadata,
+		LastSyncedAt:  integration.LastSyncedAt,
 	}
 
 	// Build sync options
 	syncOptions := SyncOptions{
 		Custom: job.SyncOptions,
 	}
+	if fullSync, ok := job.SyncOptions["fullSync"].(bool); ok {
+		syncOptions.FullSync = fullSync
+	}
+	syncOptions.ItemIDs = optionStrings(job.SyncOptions, "itemIds")
+	syncOptions.DeletedItemIDs = optionStrings(job.SyncOptions, "deletedItemIds")
+	targeted := len(syncOptions.ItemIDs) > 0 || len(syncOptions.DeletedItemIDs) > 0
 	if job.ConfigurationID != nil {
 		syncOptions.ConfigurationID = *job.ConfigurationID
 	}
@@ -268,7 +277,7 @@ func (w *Worker) processJob(ctx context.Context, job *DataSourceSyncJob) error {
 		// Update integration status
 		errMsg := err.Error()
 		if updateErr := w.jobs.UpdateIntegrationSyncStatus(ctx,
-			integration.ID, time.Now(), nil, IntegrationStatusError, &errMsg); updateErr != nil {
+			integration.ID, nil, nil, IntegrationStatusError, &errMsg); updateErr != nil {
 			w.log.Warn("failed to update integration status",
 				slog.String("integration_id", integration.ID),
 				slog.String("error", updateErr.Error()))
@@ -290,6 +299,9 @@ func (w *Worker) processJob(ctx context.Context, job *DataSourceSyncJob) error {
 		return err
 	}
 
+	// Write structured records straight to the graph
+	w.applyMapping(ctx, job, integration, result.Records, targeted)
+
 	// Update final progress
 	if err := w.jobs.UpdateProgress(ctx, job.ID,
 		result.TotalItems,
@@ -313,14 +325,27 @@ func (w *Worker) processJob(ctx context.Context, job *DataSourceSyncJob) error {
 		return err
 	}
 
-	// Update integration status
+	// Update integration status. The sync start time becomes the incremental
+	// cursor so items changed while the sync was running are picked up next time,
+	// unless the provider reports it stopped short of that.
+	// Targeted (webhook) syncs only touch a few items, so they leave both the
+	// cursor and the recurring schedule alone.
+	lastSynced := &startTime
+	if result.Cursor != nil {
+		lastSynced = result.Cursor
+		if lastSynced.IsZero() {
+			lastSynced = nil
+		}
+	}
 	var nextSync *time.Time
-	if integration.SyncMode == SyncModeRecurring && integration.SyncIntervalMinutes != nil {
+	if targeted {
+		lastSynced = nil
+	} else if integration.SyncMode == SyncModeRecurring && integration.SyncIntervalMinutes != nil {
 		next := time.Now().Add(time.Duration(*integration.SyncIntervalMinutes) * time.Minute)
 		nextSync = &next
 	}
 	if err := w.jobs.UpdateIntegrationSyncStatus(ctx,
-		integration.ID, time.Now(), nextSync, IntegrationStatusActive, nil); err != nil {
+		integration.ID, lastSynced, nextSync, IntegrationStatusActive, nil); err != nil {
 		w.log.Warn("failed to update integration status",
 			slog.String("integration_id", integration.ID),
 			slog.String("error", err.Error()))
@@ -339,6 +364,64 @@ func (w *Worker) processJob(ctx context.Context, job *DataSourceSyncJob) error {
 	return nil
 }
 
+// applyMapping saves a record sample for mapping previews and, when the
+// integration has mapping rules, upserts the records into the graph.
+// Targeted (webhook) syncs carry only a few records, so they keep the sample
+// saved by the last regular sync. Mapping failures are logged on the job but don't
+// fail the sync.
+func (w *Worker) applyMapping(ctx context.Context, job *DataSourceSyncJob, integration *DataSourceIntegration, records []Record, targeted bool) {
+	if len(records) == 0 {
+		return
+	}
+
+	if !targeted {
+		if err := w.jobs.SaveMappingSample(ctx, integration.ID, sampleRecords(records)); err != nil {
+			w.log.Warn("failed to save mapping sample",
+				slog.String("integration_id", integration.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	if len(integration.MappingRules) == 0 || w.mapper == nil {
+		return
+	}
+
+	result, err := w.mapper.Apply(ctx, integration, integration.MappingRules, records, false)
+	if err != nil {
+		w.log.Warn("mapping rules aborted",
+			slog.String("job_id", job.ID),
+			slog.String("error", err.Error()))
+	}
+	if result == nil {
+		return
+	}
+
+	entry := SyncJobLogEntry{
+		Timestamp: time.Now(),
+		Level:     "info",
+		Message:   "Applied mapping rules",
+		Details: JSON{
+			"objects":         len(result.Objects),
+			"relationships":   len(result.Relationships),
+			"unmappedRecords": result.UnmappedRecords,
+		},
+	}
+	if len(result.Errors) > 0 {
+		entry.Level = "warn"
+		errs := result.Errors
+		if len(errs) > 10 {
+			errs = errs[:10]
+		}
+		entry.Details["errors"] = errs
+		entry.Details["errorCount"] = len(result.Errors)
+	}
+	if err := w.jobs.AppendLog(ctx, job.ID, entry); err != nil {
+		w.log.Warn("failed to append mapping log",
+			slog.String("job_id", job.ID),
+			slog.String("error", err.Error()))
+	}
+}
+
 // incrementSuccess increments success metrics
 func (w *Worker) incrementSuccess() {
 	w.metricsMu.Lock()
@@ -139,6 +139,8 @@ type UploadParams struct {
 	StorageKey  string
 	StorageURL  string
 	AutoExtract bool
+	SourceType  string // Optional: defaults to "upload"
+	SourceURL   string // Optional: URL the file was fetched from
 }
 
 // BatchUploadResult is the response for batch file upload
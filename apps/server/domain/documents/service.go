@@ -6,26 +6,30 @@ import (
 	"encoding/hex"
 	"log/slog"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/emergent-company/emergent.memory/domain/events"
 	"github.com/emergent-company/emergent.memory/pkg/apperror"
 	"github.com/emergent-company/emergent.memory/pkg/logger"
 )
 
 // Service handles document business logic
 type Service struct {
-	repo *Repository
-	log  *slog.Logger
+	repo   *Repository
+	events *events.Service
+	log    *slog.Logger
 }
 
 // NewService creates a new documents service
-func NewService(repo *Repository, log *slog.Logger) *Service {
+func NewService(repo *Repository, eventsSvc *events.Service, log *slog.Logger) *Service {
 	return &Service{
-		repo: repo,
-		log:  log.With(logger.Scope("documents.svc")),
+		repo:   repo,
+		events: eventsSvc,
+		log:    log.With(logger.Scope("documents.svc")),
 	}
 }
 
@@ -56,6 +60,7 @@ type CreateParams struct {
 	ProjectID     string
 	Filename      *string
 	Content       *string
+	SourceURL     *string
 	StorageKey    *string
 	SourceType    *string
 	MimeType      *string
@@ -106,6 +111,9 @@ func (s *Service) Create(ctx context.Context, params CreateParams) (*Document, b
 	if params.StorageKey != nil {
 		doc.StorageKey = params.StorageKey
 	}
+	if params.SourceURL != nil {
+		doc.SourceURL = params.SourceURL
+	}
 	if params.SourceType != nil {
 		doc.SourceType = params.SourceType
 	}
@@ -153,6 +161,10 @@ func (s *Service) Delete(ctx context.Context, projectID, documentID string) (*De
 		slog.Int("chunks", summary.Chunks),
 		slog.Int("extractionJobs", summary.ExtractionJobs))
 
+	if s.events != nil {
+		s.events.EmitDeleted(events.EntityDocument, documentID, projectID, nil)
+	}
+
 	return &DeleteResponse{
 		Status:  "deleted",
 		Summary: summary,
@@ -185,6 +197,14 @@ func (s *Service) BulkDelete(ctx context.Context, projectID string, documentIDs
 		slog.Int("deleted", deleted),
 		slog.Int("notFound", len(notFound)))
 
+	if s.events != nil {
+		for _, id := range documentIDs {
+			if !slices.Contains(notFound, id) {
+				s.events.EmitDeleted(events.EntityDocument, id, projectID, nil)
+			}
+		}
+	}
+
 	response := &DeleteResponse{
 		Status:  status,
 		Deleted: deleted,
@@ -244,9 +264,15 @@ func (s *Service) CreateFromUpload(ctx context.Context, params UploadParams) (*U
 		UpdatedAt:        now,
 	}
 
-	// Set source type to "upload"
+	// Set source type, defaulting to "upload"
 	sourceType := "upload"
+	if params.SourceType != "" {
+		sourceType = params.SourceType
+	}
 	doc.SourceType = &sourceType
+	if params.SourceURL != "" {
+		doc.SourceURL = &params.SourceURL
+	}
 
 	err = s.repo.Create(ctx, doc)
 	if err != nil {
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OutboxEvent is a durable entity event, written to kb.entity_event_outbox in
+// the same transaction as the change it describes.
+type OutboxEvent struct {
+	bun.BaseModel `bun:"table:kb.entity_event_outbox,alias:eeo"`
+
+	ID         int64           `bun:"id,pk,autoincrement" json:"id"`
+	TxID       int64           `bun:"tx_id,nullzero" json:"txId"`
+	ProjectID  string          `bun:"project_id,type:uuid,notnull" json:"projectId"`
+	EventType  EntityEventType `bun:"event_type,notnull" json:"eventType"`
+	Entity     EntityType      `bun:"entity,notnull" json:"entity"`
+	EntityID   string          `bun:"entity_id,type:uuid,notnull" json:"entityId"`
+	ObjectType string          `bun:"object_type,nullzero" json:"objectType,omitempty"`
+	Version    *int            `bun:"version" json:"version,omitempty"`
+	ActorType  ActorType       `bun:"actor_type,nullzero" json:"actorType,omitempty"`
+	ActorID    string          `bun:"actor_id,nullzero" json:"actorId,omitempty"`
+	Data       map[string]any  `bun:"data,type:jsonb,nullzero" json:"data,omitempty"`
+	CreatedAt  time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+}
+
+// WriteOutbox records an event in the outbox using db, which should be the
+// transaction making the change. The event's actor defaults to the one on ctx.
+func WriteOutbox(ctx context.Context, db bun.IDB, evt EntityEvent) error {
+	if evt.ID == nil {
+		return nil
+	}
+
+	row := &OutboxEvent{
+		ProjectID:  evt.ProjectID,
+		EventType:  evt.Type,
+		Entity:     evt.Entity,
+		EntityID:   *evt.ID,
+		ObjectType: evt.ObjectType,
+		Version:    evt.Version,
+		Data:       evt.Data,
+	}
+	actor := evt.Actor
+	if actor == nil {
+		actor = ActorFromContext(ctx)
+	}
+	if actor != nil {
+		row.ActorType = actor.ActorType
+		row.ActorID = actor.ActorID
+	}
+
+	_, err := db.NewInsert().Model(row).Exec(ctx)
+	return err
+}
+
+// ToEntityEvent converts the outbox row back into an EntityEvent.
+func (e *OutboxEvent) ToEntityEvent() EntityEvent {
+	id := e.EntityID
+	evt := EntityEvent{
+		Type:       e.EventType,
+		Entity:     e.Entity,
+		ID:         &id,
+		ProjectID:  e.ProjectID,
+		Data:       e.Data,
+		Timestamp:  e.CreatedAt.UTC().Format(time.RFC3339),
+		Version:    e.Version,
+		ObjectType: e.ObjectType,
+	}
+	if e.ActorType != "" {
+		evt.Actor = &ActorContext{ActorType: e.ActorType, ActorID: e.ActorID}
+	}
+	return evt
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a context carrying the actor responsible for changes made
+// with it. Agent runs set this so their writes are attributed to the agent.
+func WithActor(ctx context.Context, actor *ActorContext) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or nil.
+func ActorFromContext(ctx context.Context) *ActorContext {
+	actor, _ := ctx.Value(actorContextKey{}).(*ActorContext)
+	return actor
+}
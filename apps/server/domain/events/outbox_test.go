@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActorContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, ActorFromContext(ctx))
+
+	actor := &ActorContext{ActorType: ActorAgent, ActorID: "agent-1"}
+	assert.Equal(t, actor, ActorFromContext(WithActor(ctx, actor)))
+}
+
+func TestOutboxEvent_ToEntityEvent(t *testing.T) {
+	version := 3
+	row := &OutboxEvent{
+		ProjectID:  "p1",
+		EventType:  EventTypeUpdated,
+		Entity:     EntityGraphObject,
+		EntityID:   "obj-1",
+		ObjectType: "Person",
+		Version:    &version,
+		ActorType:  ActorUser,
+		ActorID:    "user-1",
+		CreatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	evt := row.ToEntityEvent()
+	assert.Equal(t, EventTypeUpdated, evt.Type)
+	assert.Equal(t, "obj-1", *evt.ID)
+	assert.Equal(t, "Person", evt.ObjectType)
+	assert.Equal(t, &version, evt.Version)
+	assert.Equal(t, &ActorContext{ActorType: ActorUser, ActorID: "user-1"}, evt.Actor)
+	assert.Equal(t, "2026-01-02T03:04:05Z", evt.Timestamp)
+
+	row.ActorType = ""
+	assert.Nil(t, row.ToEntityEvent().Actor)
+}
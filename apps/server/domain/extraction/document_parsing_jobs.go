@@ -106,6 +106,14 @@ type CreateJobOptions struct {
 	MaxRetries      *int                   // Optional: override default max retries
 }
 
+// Parsing job metadata asking the parsing worker to queue an object
+// extraction job for the document once its content has been parsed.
+const (
+	parsingMetaExtractAfterParse   = "extractAfterParse"
+	parsingMetaExtractEnabledTypes = "extractEnabledTypes"
+	parsingMetaExtractCreatedBy    = "extractCreatedBy"
+)
+
 // CreateJob creates a new document parsing job.
 func (s *DocumentParsingJobsService) CreateJob(ctx context.Context, opts CreateJobOptions) (*DocumentParsingJob, error) {
 	maxRetries := s.cfg.DefaultMaxRetries
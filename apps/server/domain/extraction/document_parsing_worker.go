@@ -34,6 +34,7 @@ type DocumentParsingWorker struct {
 	documentsRepo   *documents.Repository
 	projectsRepo    *projects.Repository
 	chunkingService *chunking.Service
+	extractionJobs  *ObjectExtractionJobsService
 	kreuzbergClient *kreuzberg.Client
 	whisperClient   *whisper.Client
 	storageService  *storage.Service
@@ -63,6 +64,7 @@ func NewDocumentParsingWorker(
 	documentsRepo *documents.Repository,
 	projectsRepo *projects.Repository,
 	chunkingService *chunking.Service,
+	extractionJobs *ObjectExtractionJobsService,
 	kreuzbergClient *kreuzberg.Client,
 	whisperClient *whisper.Client,
 	storageService *storage.Service,
@@ -91,6 +93,7 @@ func NewDocumentParsingWorker(
 		documentsRepo:   documentsRepo,
 		projectsRepo:    projectsRepo,
 		chunkingService: chunkingService,
+		extractionJobs:  extractionJobs,
 		kreuzbergClient: kreuzbergClient,
 		whisperClient:   whisperClient,
 		storageService:  storageService,
@@ -341,6 +344,8 @@ func (w *DocumentParsingWorker) processJob(ctx context.Context, job *DocumentPar
 				slog.Int("chunks", chunkResult.Summary.NewChunks),
 				slog.String("strategy", chunkResult.Summary.Strategy))
 		}
+
+		w.queueExtraction(ctx, job, jobLog)
 	}
 
 	span.SetStatus(codes.Ok, "")
@@ -351,6 +356,30 @@ func (w *DocumentParsingWorker) processJob(ctx context.Context, job *DocumentPar
 	)
 }
 
+// queueExtraction creates the object extraction job requested when the
+// parsing job was created, now that the document has content.
+func (w *DocumentParsingWorker) queueExtraction(ctx context.Context, job *DocumentParsingJob, jobLog *slog.Logger) {
+	if w.extractionJobs == nil {
+		return
+	}
+	if extract, _ := job.Metadata[parsingMetaExtractAfterParse].(bool); !extract {
+		return
+	}
+
+	var createdBy *string
+	if by, _ := job.Metadata[parsingMetaExtractCreatedBy].(string); by != "" {
+		createdBy = &by
+	}
+
+	extractionJob, err := w.extractionJobs.CreateJob(ctx, documentExtractionJobOptions(
+		job.ProjectID, *job.DocumentID, stringSlice(job.Metadata[parsingMetaExtractEnabledTypes]), createdBy))
+	if err != nil {
+		jobLog.Error("failed to queue extraction after parsing", logger.Error(err))
+		return
+	}
+	jobLog.Info("queued extraction after parsing", slog.String("extraction_job_id", extractionJob.ID))
+}
+
 // extractWithKreuzberg downloads a file and sends it to Kreuzberg for extraction
 func (w *DocumentParsingWorker) extractWithKreuzberg(ctx context.Context, storageKey, filename, mimeType string) (string, error) {
 	content, err := w.downloadFile(ctx, storageKey)
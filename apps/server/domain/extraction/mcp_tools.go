@@ -0,0 +1,831 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/emergent-company/emergent.memory/domain/chunking"
+	"github.com/emergent-company/emergent.memory/domain/chunks"
+	"github.com/emergent-company/emergent.memory/domain/documents"
+	"github.com/emergent-company/emergent.memory/domain/mcp"
+	"github.com/emergent-company/emergent.memory/internal/storage"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+	"github.com/emergent-company/emergent.memory/pkg/kreuzberg"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	// maxURLDocumentBytes caps the size of a document fetched from a URL.
+	maxURLDocumentBytes = 50 * 1024 * 1024
+	// urlFetchTimeout bounds fetching a document from a URL.
+	urlFetchTimeout = 30 * time.Second
+
+	defaultContentPageSize = 10000
+	maxContentPageSize     = 50000
+
+	defaultDocumentListLimit = 20
+	maxDocumentListLimit     = 100
+
+	defaultChunkWindow = 1
+	maxChunkWindow     = 10
+)
+
+// DocumentToolHandler implements mcp.DocumentToolHandler, providing document
+// ingestion, chunk retrieval and extraction MCP tools. It bridges the mcp
+// package (which cannot import documents or extraction) to these domains by
+// implementing the interface defined in mcp/entity.go.
+//
+// The handler does not check API token scopes itself: MCP transports
+// authorize each call against the tool's policy in mcp/tool_policy.go
+// (documents:*, chunks:read and extraction:* scopes, granted by data:read and
+// data:write) before it reaches the handler, and agents call it directly.
+type DocumentToolHandler struct {
+	documents      *documents.Service
+	chunks         *chunks.Service
+	chunking       *chunking.Service
+	parsingJobs    *DocumentParsingJobsService
+	extractionJobs *ObjectExtractionJobsService
+	storage        *storage.Service
+	httpClient     *http.Client
+	log            *slog.Logger
+}
+
+// NewDocumentToolHandler creates a new DocumentToolHandler.
+func NewDocumentToolHandler(
+	documentsSvc *documents.Service,
+	chunksSvc *chunks.Service,
+	chunkingSvc *chunking.Service,
+	parsingJobs *DocumentParsingJobsService,
+	extractionJobs *ObjectExtractionJobsService,
+	storageSvc *storage.Service,
+	log *slog.Logger,
+) *DocumentToolHandler {
+	return &DocumentToolHandler{
+		documents:      documentsSvc,
+		chunks:         chunksSvc,
+		chunking:       chunkingSvc,
+		parsingJobs:    parsingJobs,
+		extractionJobs: extractionJobs,
+		storage:        storageSvc,
+		httpClient:     newURLFetchClient(),
+		log:            log.With(logger.Scope("extraction.mcp")),
+	}
+}
+
+// wrapResult marshals data as indented JSON into an MCP ToolResult.
+func wrapResult(data any) (*mcp.ToolResult, error) {
+	jsonBytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal result: %w", err)
+	}
+
+	return &mcp.ToolResult{
+		Content: []mcp.ContentBlock{
+			{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// errResult creates an error ToolResult (non-fatal tool error returned to LLM).
+func errResult(msg string) (*mcp.ToolResult, error) {
+	return &mcp.ToolResult{
+		Content: []mcp.ContentBlock{
+			{Type: "text", Text: fmt.Sprintf(`{"error": %q}`, msg)},
+		},
+		IsError: true,
+	}, nil
+}
+
+// ============================================================================
+// Document Tools
+// ============================================================================
+
+// ExecuteCreateDocument creates a document from text or a URL, chunks it (or
+// queues parsing for binary content) and optionally starts an extraction.
+func (h *DocumentToolHandler) ExecuteCreateDocument(ctx context.Context, projectID string, args map[string]any) (*mcp.ToolResult, error) {
+	content, _ := args["content"].(string)
+	rawURL, _ := args["url"].(string)
+	filename, _ := args["filename"].(string)
+	if (content == "") == (rawURL == "") {
+		return errResult("exactly one of content or url is required")
+	}
+
+	extract := true
+	if e, ok := args["extract"].(bool); ok {
+		extract = e
+	}
+	entityTypes := stringSlice(args["entity_types"])
+
+	if rawURL != "" {
+		return h.createDocumentFromURL(ctx, projectID, rawURL, filename, extract, entityTypes)
+	}
+
+	doc, created, err := h.documents.Create(ctx, documents.CreateParams{
+		ProjectID: projectID,
+		Filename:  &filename,
+		Content:   &content,
+	})
+	if err != nil {
+		return errResult("failed to create document: " + err.Error())
+	}
+	return h.finishTextDocument(ctx, projectID, doc, created, extract, entityTypes)
+}
+
+// finishTextDocument chunks a newly created text document and starts its
+// extraction. Deduplicated documents are returned as they are.
+func (h *DocumentToolHandler) finishTextDocument(ctx context.Context, projectID string, doc *documents.Document, created, extract bool, entityTypes []string) (*mcp.ToolResult, error) {
+	result := map[string]any{
+		"document":     documentSummary(doc),
+		"is_duplicate": !created,
+	}
+	if !created {
+		return wrapResult(result)
+	}
+
+	chunked, err := h.chunking.RecreateChunks(ctx, projectID, doc.ID)
+	if err != nil {
+		return errResult("document created but chunking failed: " + err.Error())
+	}
+	result["chunks"] = chunked.Summary.NewChunks
+
+	if extract {
+		job, err := h.extractionJobs.CreateJob(ctx, documentExtractionJobOptions(projectID, doc.ID, entityTypes, callerID(ctx)))
+		if err != nil {
+			return errResult("document created but extraction could not be started: " + err.Error())
+		}
+		result["extraction_job"] = job.ToDTO()
+	}
+	return wrapResult(result)
+}
+
+// createDocumentFromURL fetches a URL. Plain text is stored as the document's
+// content directly; other formats are stored as a file and parsed by the
+// document parsing worker, which starts the extraction once parsing is done.
+func (h *DocumentToolHandler) createDocumentFromURL(ctx context.Context, projectID, rawURL, filename string, extract bool, entityTypes []string) (*mcp.ToolResult, error) {
+	fetched, err := h.fetchURL(ctx, rawURL)
+	if err != nil {
+		return errResult("failed to fetch url: " + err.Error())
+	}
+	if filename == "" {
+		filename = fetched.filename
+	}
+
+	size := int64(len(fetched.body))
+	sourceType := "url"
+
+	if !kreuzberg.ShouldUseKreuzberg(fetched.mimeType, filename) {
+		if !utf8.Valid(fetched.body) {
+			return errResult("url content is not valid UTF-8 text")
+		}
+		content := string(fetched.body)
+		doc, created, err := h.documents.Create(ctx, documents.CreateParams{
+			ProjectID:     projectID,
+			Filename:      &filename,
+			Content:       &content,
+			SourceURL:     &rawURL,
+			SourceType:    &sourceType,
+			MimeType:      &fetched.mimeType,
+			FileSizeBytes: &size,
+		})
+		if err != nil {
+			return errResult("failed to create document: " + err.Error())
+		}
+		return h.finishTextDocument(ctx, projectID, doc, created, extract, entityTypes)
+	}
+
+	if h.storage == nil || !h.storage.Enabled() {
+		return errResult("storage is not configured: only plain-text URLs can be ingested")
+	}
+
+	var orgID, userID string
+	if user := auth.UserFromContext(ctx); user != nil {
+		orgID, userID = user.OrgID, user.ID
+	}
+
+	uploaded, err := h.storage.UploadDocument(ctx, bytes.NewReader(fetched.body), size, storage.DocumentUploadOptions{
+		ProjectID:     projectID,
+		OrgID:         orgID,
+		Filename:      filename,
+		UploadOptions: storage.UploadOptions{ContentType: fetched.mimeType},
+	})
+	if err != nil {
+		return errResult("failed to store fetched document: " + err.Error())
+	}
+
+	hash := sha256.Sum256(fetched.body)
+	response, err := h.documents.CreateFromUpload(ctx, documents.UploadParams{
+		ProjectID:  projectID,
+		OrgID:      orgID,
+		Filename:   filename,
+		MimeType:   fetched.mimeType,
+		FileSize:   size,
+		FileHash:   hex.EncodeToString(hash[:]),
+		StorageKey: uploaded.Key,
+		StorageURL: uploaded.StorageURL,
+		SourceType: sourceType,
+		SourceURL:  rawURL,
+	})
+	if err != nil {
+		_ = h.storage.Delete(ctx, uploaded.Key)
+		return errResult("failed to create document: " + err.Error())
+	}
+	if response.IsDuplicate {
+		_ = h.storage.Delete(ctx, uploaded.Key)
+		return wrapResult(map[string]any{
+			"document":     response.Document,
+			"is_duplicate": true,
+		})
+	}
+
+	metadata := map[string]any{"sourceUrl": rawURL}
+	if extract {
+		metadata[parsingMetaExtractAfterParse] = true
+		metadata[parsingMetaExtractEnabledTypes] = entityTypes
+		if userID != "" {
+			metadata[parsingMetaExtractCreatedBy] = userID
+		}
+	}
+	var jobOrgID *string
+	if orgID != "" {
+		jobOrgID = &orgID
+	}
+	parsingJob, err := h.parsingJobs.CreateJob(ctx, CreateJobOptions{
+		OrganizationID: jobOrgID,
+		ProjectID:      projectID,
+		DocumentID:     &response.Document.ID,
+		SourceType:     "web_page",
+		SourceFilename: &filename,
+		MimeType:       &fetched.mimeType,
+		FileSizeBytes:  &size,
+		StorageKey:     &uploaded.Key,
+		Metadata:       metadata,
+	})
+	if err != nil {
+		return errResult("document created but parsing could not be queued: " + err.Error())
+	}
+
+	return wrapResult(map[string]any{
+		"document":        response.Document,
+		"is_duplicate":    false,
+		"parsing_job_id":  parsingJob.ID,
+		"extraction_note": extractionNote(extract),
+	})
+}
+
+// extractionNote describes when the extraction of a parsed document starts.
+func extractionNote(extract bool) string {
+	if extract {
+		return "extraction starts automatically once parsing completes; poll get_extraction_status"
+	}
+	return "extraction not requested; call start_extraction once parsing completes"
+}
+
+// ExecuteListDocuments lists the project's documents without their content.
+func (h *DocumentToolHandler) ExecuteListDocuments(ctx context.Context, projectID string, args map[string]any) (*mcp.ToolResult, error) {
+	params := documents.ListParams{
+		ProjectID: projectID,
+		Limit:     clampInt(args["limit"], defaultDocumentListLimit, 1, maxDocumentListLimit),
+	}
+	if cursor, _ := args["cursor"].(string); cursor != "" {
+		parsed, err := documents.ParseCursor(cursor)
+		if err != nil {
+			return errResult("invalid cursor")
+		}
+		params.Cursor = parsed
+	}
+	if sourceType, _ := args["source_type"].(string); sourceType != "" {
+		params.SourceType = &sourceType
+	}
+
+	result, err := h.documents.List(ctx, params)
+	if err != nil {
+		return errResult("failed to list documents: " + err.Error())
+	}
+
+	docs := make([]*documents.Document, len(result.Documents))
+	for i := range result.Documents {
+		docs[i] = documentSummary(&result.Documents[i])
+	}
+	return wrapResult(map[string]any{
+		"documents":   docs,
+		"total":       result.Total,
+		"next_cursor": result.NextCursor,
+	})
+}
+
+// ExecuteGetDocument returns a document with one page of its content.
+func (h *DocumentToolHandler) ExecuteGetDocument(ctx context.Context, projectID string, args map[string]any) (*mcp.ToolResult, error) {
+	documentID, _ := args["document_id"].(string)
+	if _, err := uuid.Parse(documentID); err != nil {
+		return errResult("document_id must be a valid UUID")
+	}
+
+	doc, err := h.documents.GetByID(ctx, projectID, documentID)
+	if err != nil {
+		return errResult("failed to get document: " + err.Error())
+	}
+
+	content := ""
+	if doc.Content != nil {
+		content = *doc.Content
+	}
+	page := pageContent(content,
+		clampInt(args["content_offset"], 0, 0, utf8.RuneCountInString(content)),
+		clampInt(args["content_limit"], defaultContentPageSize, 1, maxContentPageSize))
+
+	return wrapResult(map[string]any{
+		"document": documentSummary(doc),
+		"content":  page,
+	})
+}
+
+// ExecuteGetChunkContext returns a chunk (e.g. a search hit) with its
+// neighbouring chunks.
+func (h *DocumentToolHandler) ExecuteGetChunkContext(ctx context.Context, projectID string, args map[string]any) (*mcp.ToolResult, error) {
+	chunkID, _ := args["chunk_id"].(string)
+	chunkUUID, err := uuid.Parse(chunkID)
+	if err != nil {
+		return errResult("chunk_id must be a valid UUID")
+	}
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return errResult("invalid project ID")
+	}
+
+	result, err := h.chunks.GetContext(ctx, projectUUID, chunkUUID,
+		clampInt(args["before"], defaultChunkWindow, 0, maxChunkWindow),
+		clampInt(args["after"], defaultChunkWindow, 0, maxChunkWindow))
+	if err != nil {
+		return errResult("failed to get chunk context: " + err.Error())
+	}
+	return wrapResult(result)
+}
+
+// ============================================================================
+// Extraction Tools
+// ============================================================================
+
+// ExecuteGetExtractionStatus returns an extraction job, or the parsing and
+// extraction jobs of a document.
+func (h *DocumentToolHandler) ExecuteGetExtractionStatus(ctx context.Context, projectID string, args map[string]any) (*mcp.ToolResult, error) {
+	jobID, _ := args["job_id"].(string)
+	documentID, _ := args["document_id"].(string)
+	if (jobID == "") == (documentID == "") {
+		return errResult("exactly one of job_id or document_id is required")
+	}
+
+	if jobID != "" {
+		if _, err := uuid.Parse(jobID); err != nil {
+			return errResult("job_id must be a valid UUID")
+		}
+		job, err := h.extractionJobs.FindByID(ctx, jobID)
+		if err != nil {
+			return errResult("failed to get extraction job: " + err.Error())
+		}
+		if job == nil || job.ProjectID != projectID {
+			return errResult(fmt.Sprintf("extraction job not found: %s", jobID))
+		}
+		return wrapResult(job.ToDTO())
+	}
+
+	if _, err := uuid.Parse(documentID); err != nil {
+		return errResult("document_id must be a valid UUID")
+	}
+	doc, err := h.documents.GetByID(ctx, projectID, documentID)
+	if err != nil {
+		return errResult("failed to get document: " + err.Error())
+	}
+
+	parsingJobs, err := h.parsingJobs.FindByDocumentID(ctx, doc.ID)
+	if err != nil {
+		return errResult("failed to get parsing jobs: " + err.Error())
+	}
+	extractionJobs, err := h.extractionJobs.FindByDocument(ctx, doc.ID)
+	if err != nil {
+		return errResult("failed to get extraction jobs: " + err.Error())
+	}
+
+	parsing := make([]map[string]any, len(parsingJobs))
+	for i, job := range parsingJobs {
+		parsing[i] = parsingJobSummary(job)
+	}
+	extraction := make([]*ExtractionJobDTO, len(extractionJobs))
+	for i, job := range extractionJobs {
+		extraction[i] = job.ToDTO()
+	}
+
+	return wrapResult(map[string]any{
+		"document_id":       doc.ID,
+		"conversion_status": doc.ConversionStatus,
+		"parsing_jobs":      parsing,
+		"extraction_jobs":   extraction,
+	})
+}
+
+// ExecuteStartExtraction starts an object extraction for a document.
+func (h *DocumentToolHandler) ExecuteStartExtraction(ctx context.Context, projectID string, args map[string]any) (*mcp.ToolResult, error) {
+	documentID, _ := args["document_id"].(string)
+	if _, err := uuid.Parse(documentID); err != nil {
+		return errResult("document_id must be a valid UUID")
+	}
+
+	doc, err := h.documents.GetByID(ctx, projectID, documentID)
+	if err != nil {
+		return errResult("failed to get document: " + err.Error())
+	}
+	if doc.Content == nil || *doc.Content == "" {
+		return errResult("document has no content yet; wait for parsing to complete (see get_extraction_status)")
+	}
+
+	job, err := h.extractionJobs.CreateJob(ctx, documentExtractionJobOptions(projectID, doc.ID, stringSlice(args["entity_types"]), callerID(ctx)))
+	if err != nil {
+		return errResult("failed to start extraction: " + err.Error())
+	}
+	return wrapResult(job.ToDTO())
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// documentExtractionJobOptions builds a full extraction job for a document,
+// limited to entityTypes when any are given.
+func documentExtractionJobOptions(projectID, documentID string, entityTypes []string, createdBy *string) CreateObjectExtractionJobOptions {
+	sourceType := "document"
+	return CreateObjectExtractionJobOptions{
+		ProjectID:    projectID,
+		DocumentID:   &documentID,
+		JobType:      JobTypeFullExtraction,
+		EnabledTypes: entityTypes,
+		SourceType:   &sourceType,
+		SourceID:     &documentID,
+		CreatedBy:    createdBy,
+	}
+}
+
+// callerID returns the ID of the authenticated caller, if any.
+func callerID(ctx context.Context) *string {
+	if user := auth.UserFromContext(ctx); user != nil && user.ID != "" {
+		return &user.ID
+	}
+	return nil
+}
+
+// documentSummary returns a copy of a document without its content, which
+// is read in pages through get_document.
+func documentSummary(doc *documents.Document) *documents.Document {
+	summary := *doc
+	summary.Content = nil
+	return &summary
+}
+
+// contentPage is one page of a document's content, in characters.
+type contentPage struct {
+	Offset      int    `json:"offset"`
+	Length      int    `json:"length"`
+	TotalLength int    `json:"total_length"`
+	HasMore     bool   `json:"has_more"`
+	NextOffset  *int   `json:"next_offset,omitempty"`
+	Text        string `json:"text"`
+}
+
+// pageContent returns up to limit characters of content starting at offset.
+func pageContent(content string, offset, limit int) contentPage {
+	runes := []rune(content)
+	total := len(runes)
+	offset = min(max(offset, 0), total)
+	end := min(offset+limit, total)
+
+	page := contentPage{
+		Offset:      offset,
+		Length:      end - offset,
+		TotalLength: total,
+		HasMore:     end < total,
+		Text:        string(runes[offset:end]),
+	}
+	if page.HasMore {
+		page.NextOffset = &end
+	}
+	return page
+}
+
+// parsingJobSummary reports a parsing job without its parsed content.
+func parsingJobSummary(job *DocumentParsingJob) map[string]any {
+	return map[string]any{
+		"id":            job.ID,
+		"status":        job.Status,
+		"source_type":   job.SourceType,
+		"error_message": job.ErrorMessage,
+		"retry_count":   job.RetryCount,
+		"created_at":    job.CreatedAt,
+		"completed_at":  job.CompletedAt,
+	}
+}
+
+// clampInt reads an integer argument (JSON numbers arrive as float64),
+// falling back to def and clamping to [lo, hi].
+func clampInt(raw any, def, lo, hi int) int {
+	v := def
+	switch n := raw.(type) {
+	case float64:
+		v = int(n)
+	case int:
+		v = n
+	}
+	return min(max(v, lo), hi)
+}
+
+// stringSlice reads a string array argument, skipping non-string entries.
+func stringSlice(raw any) []string {
+	switch v := raw.(type) {
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// fetchedDocument is a document downloaded from a URL.
+type fetchedDocument struct {
+	body     []byte
+	mimeType string
+	filename string
+}
+
+// fetchURL downloads an http(s) URL of at most maxURLDocumentBytes.
+func (h *DocumentToolHandler) fetchURL(ctx context.Context, rawURL string) (*fetchedDocument, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("url must be an absolute http or https URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxURLDocumentBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxURLDocumentBytes {
+		return nil, fmt.Errorf("document exceeds %d MB limit", maxURLDocumentBytes/(1024*1024))
+	}
+	if len(body) == 0 {
+		return nil, errors.New("url returned no content")
+	}
+
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mimeType == "" {
+		mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "" || filename == "." || filename == "/" {
+		filename = u.Host
+	}
+
+	return &fetchedDocument{body: body, mimeType: mimeType, filename: filename}, nil
+}
+
+// newURLFetchClient returns an HTTP client that only connects to public
+// addresses, so document URLs cannot reach internal services.
+func newURLFetchClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // a proxy would connect on our behalf, bypassing the address check
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   urlFetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return nil
+		},
+	}
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598).
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// ============================================================================
+// Tool Definitions
+// ============================================================================
+
+// GetDocumentToolDefinitions returns tool definitions for all document tools.
+func (h *DocumentToolHandler) GetDocumentToolDefinitions() []mcp.ToolDefinition {
+	return []mcp.ToolDefinition{
+		{
+			Name:        "create_document",
+			Description: "Add a document to the project from text or a URL. Text (and plain-text URLs) is chunked immediately; other formats such as HTML or PDF are parsed in the background. By default an entity extraction is started once the document has content. Identical content returns the existing document. Requires data:write for API tokens.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.PropertySchema{
+					"content": {
+						Type:        "string",
+						Description: "Document text (provide either content or url)",
+					},
+					"url": {
+						Type:        "string",
+						Description: "Public http(s) URL to fetch the document from (provide either content or url)",
+					},
+					"filename": {
+						Type:        "string",
+						Description: "Document name (default: derived from the URL, or unnamed.txt)",
+					},
+					"extract": {
+						Type:        "boolean",
+						Description: "Start entity extraction once the document has content (default: true)",
+						Default:     true,
+					},
+					"entity_types": {
+						Type:        "array",
+						Description: "Optional entity types to extract (default: all types of the installed template packs)",
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "list_documents",
+			Description: "List the project's documents, newest first, without their content. Includes chunk counts and the latest extraction status. Use get_document to read content.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.PropertySchema{
+					"limit": {
+						Type:        "number",
+						Description: "Maximum documents to return (default: 20)",
+						Minimum:     intPtr(1),
+						Maximum:     intPtr(maxDocumentListLimit),
+						Default:     defaultDocumentListLimit,
+					},
+					"cursor": {
+						Type:        "string",
+						Description: "next_cursor from a previous call, to fetch the next page",
+					},
+					"source_type": {
+						Type:        "string",
+						Description: "Only list documents of this source type (e.g. upload, url)",
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "get_document",
+			Description: "Get a document's metadata and one page of its content. Page through long documents with content_offset, using next_offset from the previous page.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.PropertySchema{
+					"document_id": {
+						Type:        "string",
+						Description: "The UUID of the document",
+					},
+					"content_offset": {
+						Type:        "number",
+						Description: "Character offset of the content page (default: 0)",
+						Minimum:     intPtr(0),
+						Default:     0,
+					},
+					"content_limit": {
+						Type:        "number",
+						Description: "Maximum characters of content to return (default: 10000)",
+						Minimum:     intPtr(1),
+						Maximum:     intPtr(maxContentPageSize),
+						Default:     defaultContentPageSize,
+					},
+				},
+				Required: []string{"document_id"},
+			},
+		},
+		{
+			Name:        "get_chunk_context",
+			Description: "Get a document chunk, such as a text search hit, together with the chunks before and after it in the same document.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.PropertySchema{
+					"chunk_id": {
+						Type:        "string",
+						Description: "The UUID of the chunk",
+					},
+					"before": {
+						Type:        "number",
+						Description: "Number of preceding chunks to include (default: 1)",
+						Minimum:     intPtr(0),
+						Maximum:     intPtr(maxChunkWindow),
+						Default:     defaultChunkWindow,
+					},
+					"after": {
+						Type:        "number",
+						Description: "Number of following chunks to include (default: 1)",
+						Minimum:     intPtr(0),
+						Maximum:     intPtr(maxChunkWindow),
+						Default:     defaultChunkWindow,
+					},
+				},
+				Required: []string{"chunk_id"},
+			},
+		},
+		{
+			Name:        "get_extraction_status",
+			Description: "Check extraction progress: pass job_id for a single extraction job, or document_id for the document's parsing and extraction jobs.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.PropertySchema{
+					"job_id": {
+						Type:        "string",
+						Description: "The UUID of an extraction job",
+					},
+					"document_id": {
+						Type:        "string",
+						Description: "The UUID of a document",
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "start_extraction",
+			Description: "Start an entity extraction for a document that has content, optionally limited to chosen entity types. Requires data:write for API tokens.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.PropertySchema{
+					"document_id": {
+						Type:        "string",
+						Description: "The UUID of the document",
+					},
+					"entity_types": {
+						Type:        "array",
+						Description: "Optional entity types to extract (default: all types of the installed template packs)",
+					},
+				},
+				Required: []string{"document_id"},
+			},
+		},
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
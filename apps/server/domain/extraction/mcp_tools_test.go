@@ -0,0 +1,139 @@
+package extraction
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDocumentToolHandler() *DocumentToolHandler {
+	return NewDocumentToolHandler(nil, nil, nil, nil, nil, nil, slog.Default())
+}
+
+func TestExecuteCreateDocument_RequiresExactlyOneSource(t *testing.T) {
+	h := newTestDocumentToolHandler()
+
+	for _, args := range []map[string]any{
+		{},
+		{"content": "text", "url": "https://example.com/doc.txt"},
+	} {
+		result, err := h.ExecuteCreateDocument(context.Background(), "project-1", args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "exactly one of content or url")
+	}
+}
+
+func TestExecuteGetDocument_InvalidID(t *testing.T) {
+	h := newTestDocumentToolHandler()
+
+	result, err := h.ExecuteGetDocument(context.Background(), "project-1", map[string]any{"document_id": "nope"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "document_id must be a valid UUID")
+}
+
+func TestPageContent(t *testing.T) {
+	content := "héllo wörld"
+
+	first := pageContent(content, 0, 5)
+	assert.Equal(t, "héllo", first.Text)
+	assert.Equal(t, 11, first.TotalLength)
+	assert.True(t, first.HasMore)
+	require.NotNil(t, first.NextOffset)
+	assert.Equal(t, 5, *first.NextOffset)
+
+	last := pageContent(content, *first.NextOffset, 100)
+	assert.Equal(t, " wörld", last.Text)
+	assert.False(t, last.HasMore)
+	assert.Nil(t, last.NextOffset)
+
+	past := pageContent(content, 50, 10)
+	assert.Equal(t, "", past.Text)
+	assert.Equal(t, 11, past.Offset)
+}
+
+func TestClampInt(t *testing.T) {
+	assert.Equal(t, 20, clampInt(nil, 20, 1, 100))
+	assert.Equal(t, 5, clampInt(float64(5), 20, 1, 100))
+	assert.Equal(t, 100, clampInt(float64(1000), 20, 1, 100))
+	assert.Equal(t, 1, clampInt(float64(-3), 20, 1, 100))
+	assert.Equal(t, 20, clampInt("7", 20, 1, 100))
+}
+
+func TestStringSlice(t *testing.T) {
+	assert.Equal(t, []string{"Person", "Place"}, stringSlice([]any{"Person", 3, "", "Place"}))
+	assert.Equal(t, []string{"Person"}, stringSlice([]string{"Person"}))
+	assert.Nil(t, stringSlice("Person"))
+	assert.Nil(t, stringSlice(nil))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:4700::6810:85e5": true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+	} {
+		assert.Equal(t, public, isPublicIP(net.ParseIP(addr)), addr)
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/notes/readme.md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			_, _ = w.Write([]byte("# Notes"))
+		case "/empty":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	h := newTestDocumentToolHandler()
+	h.httpClient = srv.Client()
+
+	doc, err := h.fetchURL(context.Background(), srv.URL+"/notes/readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "text/markdown", doc.mimeType)
+	assert.Equal(t, "readme.md", doc.filename)
+	assert.Equal(t, "# Notes", string(doc.body))
+
+	_, err = h.fetchURL(context.Background(), srv.URL+"/missing")
+	assert.ErrorContains(t, err, "unexpected status 404")
+
+	_, err = h.fetchURL(context.Background(), srv.URL+"/empty")
+	assert.ErrorContains(t, err, "no content")
+
+	_, err = h.fetchURL(context.Background(), "file:///etc/passwd")
+	assert.ErrorContains(t, err, "http or https")
+}
+
+func TestFetchURL_RefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	h := newTestDocumentToolHandler()
+
+	_, err := h.fetchURL(context.Background(), srv.URL)
+	assert.ErrorContains(t, err, "is not allowed")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/emergent-company/emergent.memory/domain/chunking"
 	"github.com/emergent-company/emergent.memory/domain/documents"
 	"github.com/emergent-company/emergent.memory/domain/graph"
+	"github.com/emergent-company/emergent.memory/domain/mcp"
 	"github.com/emergent-company/emergent.memory/domain/projects"
 	"github.com/emergent-company/emergent.memory/domain/scheduler"
 	"github.com/emergent-company/emergent.memory/internal/config"
@@ -117,6 +118,7 @@ var Module = fx.Module("extraction",
 		provideEmbeddingEnqueuer,
 		provideRelEmbeddingEnqueuer,
 		provideEmbeddingSweepWorker,
+		NewDocumentToolHandler,
 	),
 	fx.Invoke(
 		RegisterSysHealthMonitorLifecycle,
@@ -128,9 +130,16 @@ var Module = fx.Module("extraction",
 		RegisterDocumentParsingWorkerLifecycle,
 		RegisterObjectExtractionWorkerLifecycle,
 		RegisterEmbeddingSweepWorkerLifecycle,
+		registerDocumentToolHandler,
 	),
 )
 
+// registerDocumentToolHandler injects the DocumentToolHandler into the MCP Service
+// after both are constructed (avoids circular dependency).
+func registerDocumentToolHandler(mcpService *mcp.Service, handler *DocumentToolHandler) {
+	mcpService.SetDocumentToolHandler(handler)
+}
+
 // provideAdminHandler creates the extraction jobs admin handler
 func provideAdminHandler(jobsService *ObjectExtractionJobsService) *AdminHandler {
 	return NewAdminHandler(jobsService)
@@ -295,6 +304,7 @@ func provideDocumentParsingWorker(
 	documentsRepo *documents.Repository,
 	projectsRepo *projects.Repository,
 	chunkingService *chunking.Service,
+	extractionJobs *ObjectExtractionJobsService,
 	kreuzbergClient *kreuzberg.Client,
 	whisperClient *whisper.Client,
 	storageService *storage.Service,
@@ -314,7 +324,7 @@ func provideDocumentParsingWorker(
 		cfg.DocumentParsing.MinConcurrency,
 		cfg.DocumentParsing.MaxConcurrency,
 	)
-	return NewDocumentParsingWorker(jobs, documentsRepo, projectsRepo, chunkingService, kreuzbergClient, whisperClient, storageService, workerConfig, log, scaler)
+	return NewDocumentParsingWorker(jobs, documentsRepo, projectsRepo, chunkingService, extractionJobs, kreuzbergClient, whisperClient, storageService, workerConfig, log, scaler)
 }
 
 // RegisterDocumentParsingWorkerLifecycle registers the document parsing worker with fx lifecycle
@@ -370,6 +370,30 @@ func (s *ObjectExtractionJobsService) CancelJob(ctx context.Context, jobID strin
 	return nil
 }
 
+// CancelJobWithReason cancels a processing job that was stopped before
+// finishing, recording why. Unlike MarkFailed it is never retried.
+func (s *ObjectExtractionJobsService) CancelJobWithReason(ctx context.Context, jobID string, reason string) error {
+	now := time.Now().UTC()
+
+	_, err := s.db.NewUpdate().
+		Model((*ObjectExtractionJob)(nil)).
+		Set("status = ?", JobStatusCancelled).
+		Set("error_message = ?", reason).
+		Set("completed_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("id = ?", jobID).
+		Where("status IN (?)", bun.In([]JobStatus{JobStatusPending, JobStatusProcessing})).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("cancel job: %w", err)
+	}
+
+	s.log.Info("object extraction job cancelled",
+		slog.String("id", jobID),
+		slog.String("reason", reason))
+	return nil
+}
+
 // RecoverStaleJobs resets jobs stuck in 'processing' status back to 'pending'
 func (s *ObjectExtractionJobsService) RecoverStaleJobs(ctx context.Context) (int, error) {
 	threshold := time.Now().UTC().Add(-time.Duration(s.config.StaleThresholdMinutes) * time.Minute)
@@ -3,6 +3,7 @@ package extraction
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -197,6 +198,16 @@ func (w *ObjectExtractionWorker) processSingleJob(ctx context.Context, job *Obje
 
 	// Process the job
 	result, err := w.processJob(ctx, job)
+	var budgetErr *adk.BudgetExceededError
+	if errors.As(err, &budgetErr) {
+		// Retrying cannot succeed until the budget period rolls over
+		w.log.Warn("job stopped by LLM budget",
+			slog.String("job_id", job.ID),
+			slog.String("reason", budgetErr.Error()))
+
+		span.SetStatus(codes.Error, budgetErr.Error())
+		return w.jobsService.CancelJobWithReason(ctx, job.ID, budgetErr.Error())
+	}
 	if err != nil {
 		w.log.Error("job failed",
 			slog.String("job_id", job.ID),
@@ -0,0 +1,220 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// predicateOperators lists the operators a Predicate may use.
+var predicateOperators = map[string]bool{
+	"equals": true, "notEquals": true, "contains": true,
+	"greaterThan": true, "lessThan": true, "greaterThanOrEqual": true, "lessThanOrEqual": true,
+	"in": true, "notIn": true, "matches": true, "exists": true, "notExists": true,
+}
+
+// Validate checks that the predicate has a JSON Pointer path, a known
+// operator, and a value the operator can use.
+func (p Predicate) Validate() error {
+	if p.Path == "" || !strings.HasPrefix(p.Path, "/") {
+		return fmt.Errorf("predicate path %q must be a JSON Pointer starting with /", p.Path)
+	}
+	if !predicateOperators[p.Operator] {
+		return fmt.Errorf("unknown predicate operator %q", p.Operator)
+	}
+	switch p.Operator {
+	case "in", "notIn":
+		if _, ok := p.Value.([]any); !ok {
+			return fmt.Errorf("operator %s requires an array value", p.Operator)
+		}
+	case "matches":
+		pattern, ok := p.Value.(string)
+		if !ok {
+			return fmt.Errorf("operator matches requires a string pattern")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern for matches: %w", err)
+		}
+	}
+	return nil
+}
+
+// Evaluate reports whether the predicate holds for doc. The path is resolved
+// as a JSON Pointer into doc; a missing value only satisfies notExists (and
+// notEquals/notIn).
+func (p Predicate) Evaluate(doc map[string]any) (bool, error) {
+	if err := p.Validate(); err != nil {
+		return false, err
+	}
+
+	val, found := resolvePointer(doc, p.Path)
+	switch p.Operator {
+	case "exists":
+		return found && val != nil, nil
+	case "notExists":
+		return !found || val == nil, nil
+	case "equals":
+		return found && valuesEqual(val, p.Value), nil
+	case "notEquals":
+		return !found || !valuesEqual(val, p.Value), nil
+	case "in", "notIn":
+		in := false
+		if found {
+			for _, candidate := range p.Value.([]any) {
+				if valuesEqual(val, candidate) {
+					in = true
+					break
+				}
+			}
+		}
+		return in == (p.Operator == "in"), nil
+	case "contains":
+		if !found {
+			return false, nil
+		}
+		switch v := val.(type) {
+		case string:
+			s, ok := p.Value.(string)
+			return ok && strings.Contains(v, s), nil
+		case []any:
+			for _, item := range v {
+				if valuesEqual(item, p.Value) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case []string:
+			for _, item := range v {
+				if valuesEqual(item, p.Value) {
+					return true, nil
+				}
+			}
+			return false, nil
+		default:
+			return false, nil
+		}
+	case "matches":
+		s, ok := val.(string)
+		if !found || !ok {
+			return false, nil
+		}
+		return regexp.MustCompile(p.Value.(string)).MatchString(s), nil
+	default: // ordering comparisons
+		if !found {
+			return false, nil
+		}
+		cmp, ok := compareValues(val, p.Value)
+		if !ok {
+			return false, nil
+		}
+		switch p.Operator {
+		case "greaterThan":
+			return cmp > 0, nil
+		case "lessThan":
+			return cmp < 0, nil
+		case "greaterThanOrEqual":
+			return cmp >= 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	}
+}
+
+// resolvePointer resolves an RFC 6901 JSON Pointer against nested maps and
+// slices.
+func resolvePointer(doc any, pointer string) (any, bool) {
+	if pointer == "" || pointer == "/" {
+		return doc, true
+	}
+	cur := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		case []string:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// valuesEqual compares two JSON-like values, treating all numeric types alike.
+func valuesEqual(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return jsonEqual(a, b)
+}
+
+// compareValues orders two numbers or two strings. ok is false when the
+// values are not comparable.
+func compareValues(a, b any) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	sa, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+	sb, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
+
+// toFloat converts JSON numbers and Go numeric types to float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredicate_Evaluate(t *testing.T) {
+	doc := map[string]any{
+		"status": "active",
+		"labels": []string{"urgent", "customer"},
+		"properties": map[string]any{
+			"priority": float64(3),
+			"title":    "Fix login bug",
+			"tags":     []any{"auth", "web"},
+			"a/b":      "slash",
+			"nested":   map[string]any{"owner": "ada"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		pred Predicate
+		want bool
+	}{
+		{"equals string", Predicate{Path: "/status", Operator: "equals", Value: "active"}, true},
+		{"equals int vs float", Predicate{Path: "/properties/priority", Operator: "equals", Value: 3}, true},
+		{"notEquals", Predicate{Path: "/status", Operator: "notEquals", Value: "done"}, true},
+		{"notEquals missing", Predicate{Path: "/missing", Operator: "notEquals", Value: "x"}, true},
+		{"greaterThan", Predicate{Path: "/properties/priority", Operator: "greaterThan", Value: 2}, true},
+		{"lessThanOrEqual", Predicate{Path: "/properties/priority", Operator: "lessThanOrEqual", Value: 2}, false},
+		{"compare mixed types", Predicate{Path: "/status", Operator: "greaterThan", Value: 1}, false},
+		{"contains substring", Predicate{Path: "/properties/title", Operator: "contains", Value: "login"}, true},
+		{"contains array item", Predicate{Path: "/properties/tags", Operator: "contains", Value: "auth"}, true},
+		{"contains string slice", Predicate{Path: "/labels", Operator: "contains", Value: "urgent"}, true},
+		{"in", Predicate{Path: "/status", Operator: "in", Value: []any{"active", "open"}}, true},
+		{"notIn", Predicate{Path: "/status", Operator: "notIn", Value: []any{"active"}}, false},
+		{"matches", Predicate{Path: "/properties/title", Operator: "matches", Value: "^Fix"}, true},
+		{"exists", Predicate{Path: "/properties/nested/owner", Operator: "exists"}, true},
+		{"notExists", Predicate{Path: "/properties/nested/reviewer", Operator: "notExists"}, true},
+		{"array index", Predicate{Path: "/properties/tags/1", Operator: "equals", Value: "web"}, true},
+		{"escaped pointer", Predicate{Path: "/properties/a~1b", Operator: "equals", Value: "slash"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pred.Evaluate(doc)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPredicate_Validate(t *testing.T) {
+	assert.NoError(t, Predicate{Path: "/status", Operator: "exists"}.Validate())
+	assert.Error(t, Predicate{Path: "status", Operator: "exists"}.Validate(), "path must be a pointer")
+	assert.Error(t, Predicate{Path: "/status", Operator: "like"}.Validate(), "unknown operator")
+	assert.Error(t, Predicate{Path: "/status", Operator: "in", Value: "active"}.Validate(), "in needs an array")
+	assert.Error(t, Predicate{Path: "/status", Operator: "matches", Value: "("}.Validate(), "bad regex")
+
+	_, err := Predicate{Path: "/status", Operator: "like"}.Evaluate(map[string]any{})
+	assert.Error(t, err)
+}
@@ -542,8 +542,9 @@ func (r *Repository) CreateVersion(ctx context.Context, tx bun.Tx, prevHead *Gra
 	return nil
 }
 
-// SoftDelete marks a graph object as deleted by creating a tombstone version.
-func (r *Repository) SoftDelete(ctx context.Context, tx bun.Tx, obj *GraphObject, actorID *uuid.UUID) error {
+// SoftDelete marks a graph object as deleted by creating a tombstone version,
+// which it returns.
+func (r *Repository) SoftDelete(ctx context.Context, tx bun.Tx, obj *GraphObject, actorID *uuid.UUID) (*GraphObject, error) {
 	now := time.Now()
 	tombstone := &GraphObject{
 		Type:       obj.Type,
@@ -557,11 +558,15 @@ func (r *Repository) SoftDelete(ctx context.Context, tx bun.Tx, obj *GraphObject
 	actorType := "user"
 	tombstone.ActorType = &actorType
 
-	return r.CreateVersion(ctx, tx, obj, tombstone)
+	if err := r.CreateVersion(ctx, tx, obj, tombstone); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
 }
 
-// Restore removes the deleted_at flag by creating a new non-deleted version.
-func (r *Repository) Restore(ctx context.Context, tx bun.Tx, obj *GraphObject, actorID *uuid.UUID) error {
+// Restore removes the deleted_at flag by creating a new non-deleted version,
+// which it returns.
+func (r *Repository) Restore(ctx context.Context, tx bun.Tx, obj *GraphObject, actorID *uuid.UUID) (*GraphObject, error) {
 	restored := &GraphObject{
 		Type:       obj.Type,
 		Key:        obj.Key,
@@ -574,7 +579,10 @@ func (r *Repository) Restore(ctx context.Context, tx bun.Tx, obj *GraphObject, a
 	actorType := "user"
 	restored.ActorType = &actorType
 
-	return r.CreateVersion(ctx, tx, obj, restored)
+	if err := r.CreateVersion(ctx, tx, obj, restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
 }
 
 // GetHistory returns all versions of a graph object by canonical ID.
@@ -1583,29 +1591,59 @@ func (r *Repository) GetDistinctTags(ctx context.Context, projectID uuid.UUID, p
 
 // BulkUpdateStatus updates the status of multiple objects.
 // Accepts either physical ids or canonical_ids.
-func (r *Repository) BulkUpdateStatus(ctx context.Context, projectID uuid.UUID, ids []uuid.UUID, status string, actorID *uuid.UUID) (int, error) {
+// It returns the HEAD versions as they were before and after the update, in
+// matching order, and must run in tx so both reflect the same rows.
+func (r *Repository) BulkUpdateStatus(ctx context.Context, tx bun.Tx, projectID uuid.UUID, ids []uuid.UUID, status string, actorID *uuid.UUID) (prev, updated []*GraphObject, err error) {
 	if len(ids) == 0 {
-		return 0, nil
+		return nil, nil, nil
+	}
+
+	// Lock the HEAD versions so the previous state matches what is updated
+	err = tx.NewSelect().
+		Model(&prev).
+		Where("(id IN (?) OR canonical_id IN (?))", bun.In(ids), bun.In(ids)).
+		Where("project_id = ?", projectID).
+		Where("supersedes_id IS NULL"). // Only update HEAD versions
+		Where("deleted_at IS NULL").
+		OrderExpr("id").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error("failed to bulk update status", logger.Error(err))
+		return nil, nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	if len(prev) == 0 {
+		return nil, nil, nil
+	}
+
+	rowIDs := make([]uuid.UUID, len(prev))
+	for i, obj := range prev {
+		rowIDs[i] = obj.ID
 	}
 
 	now := time.Now()
-	result, err := r.db.NewUpdate().
+	_, err = tx.NewUpdate().
 		Model((*GraphObject)(nil)).
 		Set("status = ?", status).
 		Set("updated_at = ?", now).
 		Set("actor_id = ?", actorID).
-		Where("(id IN (?) OR canonical_id IN (?))", bun.In(ids), bun.In(ids)).
-		Where("project_id = ?", projectID).
-		Where("supersedes_id IS NULL"). // Only update HEAD versions
-		Where("deleted_at IS NULL").
+		Where("id IN (?)", bun.In(rowIDs)).
 		Exec(ctx)
 	if err != nil {
 		r.log.Error("failed to bulk update status", logger.Error(err))
-		return 0, apperror.ErrDatabase.WithInternal(err)
+		return nil, nil, apperror.ErrDatabase.WithInternal(err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	return int(rowsAffected), nil
+	err = tx.NewSelect().
+		Model(&updated).
+		Where("id IN (?)", bun.In(rowIDs)).
+		OrderExpr("id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error("failed to bulk update status", logger.Error(err))
+		return nil, nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return prev, updated, nil
 }
 
 // =============================================================================
@@ -14,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 
+	"github.com/emergent-company/emergent.memory/domain/events"
 	"github.com/emergent-company/emergent.memory/domain/extraction/agents"
 	"github.com/emergent-company/emergent.memory/pkg/apperror"
 	"github.com/emergent-company/emergent.memory/pkg/logger"
@@ -112,6 +113,67 @@ func (s *Service) enqueueRelationshipEmbedding(ctx context.Context, relationship
 	}
 }
 
+// recordEvent writes an entity event for a graph object version to the event
+// outbox in tx. prev is the version it replaces, if any; its status and labels
+// are included so consumers can tell what changed.
+func (s *Service) recordEvent(ctx context.Context, tx bun.IDB, eventType events.EntityEventType, obj, prev *GraphObject) error {
+	actor := events.ActorFromContext(ctx)
+	if actor == nil && obj.ActorID != nil {
+		actor = &events.ActorContext{ActorType: events.ActorUser, ActorID: obj.ActorID.String()}
+	}
+
+	id := obj.ID.String()
+	version := obj.Version
+	err := events.WriteOutbox(ctx, tx, events.EntityEvent{
+		Type:       eventType,
+		Entity:     events.EntityGraphObject,
+		ID:         &id,
+		ProjectID:  obj.ProjectID.String(),
+		Actor:      actor,
+		Version:    &version,
+		ObjectType: obj.Type,
+		Data:       eventData(obj, prev),
+	})
+	if err != nil {
+		return apperror.ErrDatabase.WithInternal(fmt.Errorf("record entity event: %w", err))
+	}
+	return nil
+}
+
+// eventData builds the payload of a graph object event: the new version's
+// state, its change summary, and the previous version's status and labels.
+func eventData(obj, prev *GraphObject) map[string]any {
+	labels := obj.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	data := map[string]any{
+		"canonicalId": obj.CanonicalID.String(),
+		"labels":      labels,
+		"properties":  obj.Properties,
+	}
+	if obj.Key != nil {
+		data["key"] = *obj.Key
+	}
+	if obj.Status != nil {
+		data["status"] = *obj.Status
+	}
+	if obj.ChangeSummary != nil {
+		data["changes"] = obj.ChangeSummary
+	}
+	if prev != nil {
+		previous := map[string]any{"labels": prev.Labels}
+		if prev.Labels == nil {
+			previous["labels"] = []string{}
+		}
+		if prev.Status != nil {
+			previous["status"] = *prev.Status
+		}
+		data["previous"] = previous
+	}
+	return data
+}
+
 // UpdateAccessTimestamps updates last_accessed_at for the given object IDs.
 func (s *Service) UpdateAccessTimestamps(ctx context.Context, objectIDs []uuid.UUID) error {
 	return s.repo.UpdateAccessTimestamps(ctx, objectIDs)
@@ -321,10 +383,23 @@ func (s *Service) Create(ctx context.Context, projectID uuid.UUID, req *CreateGr
 		ActorID:    actorID,
 	}
 
-	if err := s.repo.Create(ctx, obj); err != nil {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	defer tx.Rollback()
+
+	if err := s.repo.CreateInTx(ctx, tx.Tx, obj); err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, tx.Tx, events.EventTypeCreated, obj, nil); err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+
 	s.enqueueEmbedding(ctx, obj.ID.String())
 
 	return obj.ToResponse(), nil
@@ -400,6 +475,9 @@ func (s *Service) CreateOrUpdate(ctx context.Context, projectID uuid.UUID, req *
 		if err := s.repo.CreateInTx(ctx, tx.Tx, obj); err != nil {
 			return nil, false, err
 		}
+		if err := s.recordEvent(ctx, tx.Tx, events.EventTypeCreated, obj, nil); err != nil {
+			return nil, false, err
+		}
 
 		if err := tx.Commit(); err != nil {
 			return nil, false, apperror.ErrDatabase.WithInternal(err)
@@ -428,6 +506,9 @@ func (s *Service) CreateOrUpdate(ctx context.Context, projectID uuid.UUID, req *
 		if err := s.repo.CreateVersion(ctx, tx.Tx, existing, newVersion); err != nil {
 			return nil, false, err
 		}
+		if err := s.recordEvent(ctx, tx.Tx, events.EventTypeCreated, newVersion, existing); err != nil {
+			return nil, false, err
+		}
 
 		if err := tx.Commit(); err != nil {
 			return nil, false, apperror.ErrDatabase.WithInternal(err)
@@ -507,6 +588,9 @@ func (s *Service) CreateOrUpdate(ctx context.Context, projectID uuid.UUID, req *
 	if err := s.repo.CreateVersion(ctx, tx.Tx, existing, newVersion); err != nil {
 		return nil, false, err
 	}
+	if err := s.recordEvent(ctx, tx.Tx, events.EventTypeUpdated, newVersion, existing); err != nil {
+		return nil, false, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, false, apperror.ErrDatabase.WithInternal(err)
@@ -665,6 +749,9 @@ func (s *Service) Patch(ctx context.Context, projectID, id uuid.UUID, req *Patch
 	if err := s.repo.CreateVersion(ctx, tx.Tx, current, newVersion); err != nil {
 		return nil, err
 	}
+	if err := s.recordEvent(ctx, tx.Tx, events.EventTypeUpdated, newVersion, current); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, apperror.ErrDatabase.WithInternal(err)
@@ -702,7 +789,11 @@ func (s *Service) Delete(ctx context.Context, projectID, id uuid.UUID, actorID *
 		return err
 	}
 
-	if err := s.repo.SoftDelete(ctx, tx.Tx, current, actorID); err != nil {
+	tombstone, err := s.repo.SoftDelete(ctx, tx.Tx, current, actorID)
+	if err != nil {
+		return err
+	}
+	if err := s.recordEvent(ctx, tx.Tx, events.EventTypeDeleted, tombstone, current); err != nil {
 		return err
 	}
 
@@ -736,7 +827,11 @@ func (s *Service) Restore(ctx context.Context, projectID, id uuid.UUID, actorID
 		return nil, err
 	}
 
-	if err := s.repo.Restore(ctx, tx.Tx, current, actorID); err != nil {
+	restoredVersion, err := s.repo.Restore(ctx, tx.Tx, current, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, tx.Tx, events.EventTypeCreated, restoredVersion, current); err != nil {
 		return nil, err
 	}
 
@@ -1036,6 +1131,8 @@ func (s *Service) GetRelationship(ctx context.Context, projectID, id uuid.UUID)
 }
 
 // CreateRelationship creates a new relationship or returns existing if properties match.
+// Relationship writes record no entity events, so agent reactions and event
+// subscribers only see changes to objects.
 func (s *Service) CreateRelationship(ctx context.Context, projectID uuid.UUID, req *CreateGraphRelationshipRequest) (*GraphRelationshipResponse, error) {
 	// Validate: no self-loops
 	if req.SrcID == req.DstID {
@@ -1976,13 +2073,33 @@ func (s *Service) BulkUpdateStatus(ctx context.Context, projectID uuid.UUID, req
 		}, nil
 	}
 
-	// Perform bulk update
-	updated, err := s.repo.BulkUpdateStatus(ctx, projectID, validIDs, req.Status, actorID)
+	// Perform bulk update, recording an event for each changed object with
+	// the update so reactions and subscribers see it
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	defer tx.Rollback()
+
+	prev, updatedObjs, err := s.repo.BulkUpdateStatus(ctx, tx.Tx, projectID, validIDs, req.Status, actorID)
 	if err != nil {
 		return nil, err
 	}
+	for i, obj := range updatedObjs {
+		if prev[i].Status != nil && *prev[i].Status == req.Status {
+			continue
+		}
+		if err := s.recordEvent(ctx, tx.Tx, events.EventTypeUpdated, obj, prev[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
 
 	// Calculate success/failed counts
+	updated := len(updatedObjs)
 	successCount := updated
 	failedCount := len(req.IDs) - updated
 
@@ -2057,6 +2174,7 @@ func (s *Service) BulkCreateObjects(ctx context.Context, projectID uuid.UUID, re
 // BulkCreateRelationships creates multiple relationships in a single batch.
 // Each relationship is created independently and concurrently — failures do not roll back other successes.
 // Inverse relationships are auto-created per template pack inverseType declarations.
+// Like CreateRelationship, it records no entity events.
 func (s *Service) BulkCreateRelationships(ctx context.Context, projectID uuid.UUID, req *BulkCreateRelationshipsRequest) (*BulkCreateRelationshipsResponse, error) {
 	results := make([]BulkCreateRelationshipResult, len(req.Items))
 
@@ -3046,6 +3164,9 @@ func (s *Service) CreateSubgraph(ctx context.Context, projectID uuid.UUID, req *
 		if err := s.repo.CreateInTx(ctx, tx.Tx, obj); err != nil {
 			return nil, apperror.ErrDatabase.WithMessage(fmt.Sprintf("objects[%d] (%s): %s", i, objReq.Ref, err.Error()))
 		}
+		if err := s.recordEvent(ctx, tx.Tx, events.EventTypeCreated, obj, nil); err != nil {
+			return nil, err
+		}
 
 		refMap[objReq.Ref] = obj.ID
 		objByRef[objReq.Ref] = obj
@@ -23,29 +23,34 @@ func NewIntegrationRegistry() *IntegrationRegistry {
 			RequiresOAuth:             false,
 			SupportsIncrementalSync:   true,
 		},
-		RequiredSettings: []string{"api_key"},
+		RequiredSettings: []string{"apiToken"},
 		OptionalSettings: map[string]interface{}{
-			"workspace_id":     "Workspace ID to sync from",
-			"include_subtasks": "Include subtasks in sync",
+			"workspaceId":     "Workspace ID to sync from",
+			"includeSubtasks": "Include subtasks in sync",
 		},
 	})
 
 	registry.Register(AvailableIntegrationDTO{
 		Name:        "github",
 		DisplayName: "GitHub",
-		Description: "Import issues, PRs, and discussions from GitHub repositories",
+		Description: "Import issues, PRs, discussions, and markdown files from GitHub repositories",
 		Capabilities: IntegrationCapabilitiesDTO{
 			SupportsImport:            true,
 			SupportsWebhooks:          true,
 			SupportsBidirectionalSync: false,
-			RequiresOAuth:             true,
+			RequiresOAuth:             false,
 			SupportsIncrementalSync:   true,
 		},
-		RequiredSettings: []string{"access_token", "repository"},
+		RequiredSettings: []string{"repository"},
 		OptionalSettings: map[string]interface{}{
-			"include_issues":      "Import issues",
-			"include_prs":         "Import pull requests",
-			"include_discussions": "Import discussions",
+			"importIssues":       "Import issues",
+			"importPullRequests": "Import pull requests",
+			"importDiscussions":  "Import discussions",
+			"importFiles":        "Import markdown files from the branch",
+			"paths":              "Path prefixes to import files from",
+			"branch":             "Branch to import files from",
+			"mapToGraph":         "Map authors, labels and cross-references into the graph",
+			"accessToken":        "Personal access token (defaults to the GitHub App installation)",
 		},
 	})
 
@@ -69,17 +74,22 @@ func NewIntegrationRegistry() *IntegrationRegistry {
 	registry.Register(AvailableIntegrationDTO{
 		Name:        "slack",
 		DisplayName: "Slack",
-		Description: "Import messages and threads from Slack channels",
+		Description: "Import messages and threads from Slack channels, or from Slack and Teams export archives",
 		Capabilities: IntegrationCapabilitiesDTO{
 			SupportsImport:            true,
 			SupportsWebhooks:          true,
 			SupportsBidirectionalSync: false,
-			RequiresOAuth:             true,
+			RequiresOAuth:             false,
 			SupportsIncrementalSync:   true,
 		},
-		RequiredSettings: []string{"bot_token"},
+		RequiredSettings: []string{},
 		OptionalSettings: map[string]interface{}{
-			"channels": "Specific channels to sync",
+			"mode":        "live (Slack Web API) or export (uploaded Slack/Teams export archive)",
+			"botToken":    "Bot token (xoxb-...), required in live mode",
+			"channels":    "Specific channels to sync",
+			"groupBy":     "Document granularity: thread or channel_day",
+			"includeBots": "Include bot messages",
+			"timezone":    "IANA timezone used to split conversations by day",
 		},
 	})
 
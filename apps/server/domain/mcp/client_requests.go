@@ -0,0 +1,275 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// clientRequestTimeout bounds how long a server request waits for the
+// client's response. Elicitations wait for a person, so it is generous.
+const clientRequestTimeout = 10 * time.Minute
+
+// ErrClientCapabilityMissing is returned when the client did not declare the
+// capability a request needs in initialize.
+var ErrClientCapabilityMissing = errors.New("client does not support this request")
+
+// ClientError is a JSON-RPC error the client returned for a server request.
+type ClientError struct {
+	Method string
+	Err    *ErrorObject
+}
+
+func (e *ClientError) Error() string {
+	return fmt.Sprintf("client rejected %s: %s (code %d)", e.Method, e.Err.Message, e.Err.Code)
+}
+
+// ClientSession sends requests to the client of an MCP session: sampling
+// with the client's model and elicitation of user input. It is available to
+// tools through ClientSessionFromContext while the client's tools/call is
+// being served.
+type ClientSession struct {
+	sessionID    string
+	capabilities ClientCapabilities
+	send         func(*Request) error
+	requests     *clientRequests
+
+	// progressToken is the token the client sent with the tool call
+	progressToken any
+}
+
+// withProgressToken returns the session reporting progress for a tool call
+// made with the token.
+func (c *ClientSession) withProgressToken(token any) *ClientSession {
+	call := *c
+	call.progressToken = token
+	return &call
+}
+
+// Progress reports the progress of the tool call being served with
+// notifications/progress. It does nothing unless the client sent a progress
+// token with the call. A total of 0 means the total is unknown.
+func (c *ClientSession) Progress(progress, total float64, message string) {
+	if c == nil || c.progressToken == nil {
+		return
+	}
+	params := map[string]any{"progressToken": c.progressToken, "progress": progress}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	c.notify("notifications/progress", params)
+}
+
+// notify sends the client a notification, if there is a client to send to.
+func (c *ClientSession) notify(method string, params any) {
+	if c == nil {
+		return
+	}
+	_ = c.send(&Request{JSONRPC: "2.0", Method: method, Params: mustMarshal(params)})
+}
+
+// SupportsSampling reports whether the client accepts sampling/createMessage.
+func (c *ClientSession) SupportsSampling() bool {
+	return c != nil && c.capabilities.Sampling != nil
+}
+
+// SupportsSamplingTools reports whether the client accepts tool definitions
+// in sampling requests.
+func (c *ClientSession) SupportsSamplingTools() bool {
+	return c.SupportsSampling() && c.capabilities.Sampling.Tools != nil
+}
+
+// SupportsElicitation reports whether the client accepts form elicitation.
+func (c *ClientSession) SupportsElicitation() bool {
+	if c == nil || c.capabilities.Elicitation == nil {
+		return false
+	}
+	e := c.capabilities.Elicitation
+	return e.Form != nil || e.URL == nil
+}
+
+// CreateMessage asks the client to sample its model.
+func (c *ClientSession) CreateMessage(ctx context.Context, params *CreateMessageParams) (*CreateMessageResult, error) {
+	if !c.SupportsSampling() {
+		return nil, ErrClientCapabilityMissing
+	}
+	var result CreateMessageResult
+	if err := c.request(ctx, "sampling/createMessage", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Elicit asks the client to collect input from its user with a form.
+func (c *ClientSession) Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error) {
+	if !c.SupportsElicitation() {
+		return nil, ErrClientCapabilityMissing
+	}
+	var result ElicitResult
+	if err := c.request(ctx, "elicitation/create", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Confirm asks the client's user to confirm an action. It reports true only
+// when the user accepted and checked the confirmation.
+func (c *ClientSession) Confirm(ctx context.Context, message string) (bool, error) {
+	result, err := c.Elicit(ctx, &ElicitParams{
+		Message: message,
+		RequestedSchema: ElicitSchema{
+			Type: "object",
+			Properties: map[string]ElicitProperty{
+				"confirm": {Type: "boolean", Title: "Confirm", Default: false},
+			},
+			Required: []string{"confirm"},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	confirmed, _ := result.Content["confirm"].(bool)
+	return result.Action == ElicitActionAccept && confirmed, nil
+}
+
+// request sends a request to the client and waits for its response. A
+// request abandoned by timeout or cancellation is cancelled on the client.
+func (c *ClientSession) request(ctx context.Context, method string, params, result any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshal %s params: %w", method, err)
+	}
+
+	id := "srv-" + uuid.NewString()
+	responses := c.requests.await(c.sessionID, id)
+	defer c.requests.forget(id)
+
+	req := &Request{JSONRPC: "2.0", ID: json.RawMessage(strconv.Quote(id)), Method: method, Params: data}
+	if err := c.send(req); err != nil {
+		return fmt.Errorf("send %s: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, clientRequestTimeout)
+	defer cancel()
+
+	select {
+	case resp := <-responses:
+		if resp.Error != nil {
+			return &ClientError{Method: method, Err: resp.Error}
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		_ = c.send(&Request{JSONRPC: "2.0", Method: "notifications/cancelled", Params: mustMarshal(map[string]any{
+			"requestId": id,
+			"reason":    ctx.Err().Error(),
+		})})
+		return fmt.Errorf("no response to %s: %w", method, ctx.Err())
+	}
+}
+
+func mustMarshal(v any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// clientRequests correlates the server requests a transport sent with the
+// responses its clients post back.
+type clientRequests struct {
+	mu      sync.Mutex
+	pending map[string]*pendingClientRequest
+}
+
+type pendingClientRequest struct {
+	sessionID string
+	response  chan *Request
+}
+
+func newClientRequests() *clientRequests {
+	return &clientRequests{pending: make(map[string]*pendingClientRequest)}
+}
+
+// session returns the ClientSession of a session, sending requests with send.
+func (r *clientRequests) session(sessionID string, capabilities ClientCapabilities, send func(*Request) error) *ClientSession {
+	return &ClientSession{sessionID: sessionID, capabilities: capabilities, send: send, requests: r}
+}
+
+// await registers a request and returns the channel receiving its response.
+func (r *clientRequests) await(sessionID, id string) <-chan *Request {
+	p := &pendingClientRequest{sessionID: sessionID, response: make(chan *Request, 1)}
+	r.mu.Lock()
+	r.pending[id] = p
+	r.mu.Unlock()
+	return p.response
+}
+
+// forget drops a request that was answered or abandoned.
+func (r *clientRequests) forget(id string) {
+	r.mu.Lock()
+	delete(r.pending, id)
+	r.mu.Unlock()
+}
+
+// Awaits reports whether a request with this ID sent to the session is
+// waiting for a response.
+func (r *clientRequests) Awaits(sessionID, requestID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.pending[requestID]
+	return ok && p.sessionID == sessionID
+}
+
+// Resolve hands a client's response to the request waiting for it. Responses
+// from another session than the one the request was sent to are ignored.
+func (r *clientRequests) Resolve(sessionID, requestID string, data json.RawMessage) {
+	var resp Request
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	p, ok := r.pending[requestID]
+	if ok && p.sessionID == sessionID {
+		delete(r.pending, requestID)
+	}
+	r.mu.Unlock()
+
+	if ok && p.sessionID == sessionID {
+		p.response <- &resp
+	}
+}
+
+// responseID returns the ID of a client response as a string.
+func responseID(req *Request) string {
+	var id string
+	if err := json.Unmarshal(req.ID, &id); err != nil {
+		return string(req.ID)
+	}
+	return id
+}
+
+type clientSessionKey struct{}
+
+// ContextWithClientSession returns ctx carrying the session's client.
+func ContextWithClientSession(ctx context.Context, c *ClientSession) context.Context {
+	return context.WithValue(ctx, clientSessionKey{}, c)
+}
+
+// ClientSessionFromContext returns the client of the MCP session whose tool
+// call is being served, or nil outside a tool call or on transports that
+// cannot send requests to the client.
+func ClientSessionFromContext(ctx context.Context) *ClientSession {
+	c, _ := ctx.Value(clientSessionKey{}).(*ClientSession)
+	return c
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// respondingClient returns a ClientSession whose client answers every request
+// with respond, delivering the response through a memory session store.
+func respondingClient(t *testing.T, capabilities ClientCapabilities, respond func(req *Request) string) (*ClientSession, *[]*Request) {
+	t.Helper()
+	requests := newClientRequests()
+	store := NewMemorySessionStore(10, 0)
+	store.OnResponse(requests)
+
+	var sent []*Request
+	client := requests.session("s1", capabilities, func(req *Request) error {
+		sent = append(sent, req)
+		if req.IsNotification() {
+			return nil
+		}
+		data := json.RawMessage(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,` + respond(req) + `}`)
+		go func() {
+			var resp Request
+			require.NoError(t, json.Unmarshal(data, &resp))
+			assert.True(t, resp.IsResponse())
+			_ = store.DeliverResponse(context.Background(), "s1", responseID(&resp), data)
+		}()
+		return nil
+	})
+	return client, &sent
+}
+
+func TestClientSession_CreateMessage(t *testing.T) {
+	client, sent := respondingClient(t, ClientCapabilities{Sampling: &SamplingCapability{}}, func(*Request) string {
+		return `"result":{"role":"assistant","content":{"type":"text","text":"hi"},"model":"client-model","stopReason":"endTurn"}`
+	})
+
+	result, err := client.CreateMessage(context.Background(), &CreateMessageParams{
+		Messages:  []SamplingMessage{{Role: "user", Content: SamplingContents{{Type: "text", Text: "hello"}}}},
+		MaxTokens: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "client-model", result.Model)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "hi", result.Content[0].Text)
+
+	require.Len(t, *sent, 1)
+	assert.Equal(t, "sampling/createMessage", (*sent)[0].Method)
+	assert.JSONEq(t, `{"messages":[{"role":"user","content":{"type":"text","text":"hello"}}],"maxTokens":100}`, string((*sent)[0].Params))
+}
+
+func TestClientSession_ClientError(t *testing.T) {
+	client, _ := respondingClient(t, ClientCapabilities{Sampling: &SamplingCapability{}}, func(*Request) string {
+		return `"error":{"code":-1,"message":"User rejected sampling request"}`
+	})
+
+	_, err := client.CreateMessage(context.Background(), &CreateMessageParams{MaxTokens: 10})
+	var clientErr *ClientError
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, "User rejected sampling request", clientErr.Err.Message)
+}
+
+func TestClientSession_Confirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     bool
+	}{
+		{name: "accepted and confirmed", response: `"result":{"action":"accept","content":{"confirm":true}}`, want: true},
+		{name: "accepted unchecked", response: `"result":{"action":"accept","content":{"confirm":false}}`, want: false},
+		{name: "declined", response: `"result":{"action":"decline"}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, sent := respondingClient(t, ClientCapabilities{Elicitation: &ElicitationCapability{}}, func(*Request) string {
+				return tt.response
+			})
+			confirmed, err := client.Confirm(context.Background(), "Install?")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, confirmed)
+			assert.Equal(t, "elicitation/create", (*sent)[0].Method)
+		})
+	}
+}
+
+func TestClientSession_Capabilities(t *testing.T) {
+	var none *ClientSession
+	assert.False(t, none.SupportsSampling(), "no client outside a tool call")
+	assert.False(t, none.SupportsElicitation())
+
+	_, err := (&ClientSession{}).CreateMessage(context.Background(), &CreateMessageParams{})
+	assert.ErrorIs(t, err, ErrClientCapabilityMissing)
+
+	urlOnly := &ClientSession{capabilities: ClientCapabilities{Elicitation: &ElicitationCapability{URL: &struct{}{}}}}
+	assert.False(t, urlOnly.SupportsElicitation(), "url-only clients can't show forms")
+
+	withTools := &ClientSession{capabilities: ClientCapabilities{Sampling: &SamplingCapability{Tools: &struct{}{}}}}
+	assert.True(t, withTools.SupportsSamplingTools())
+}
+
+func TestClientRequests_IgnoresOtherSessions(t *testing.T) {
+	requests := newClientRequests()
+	responses := requests.await("s1", "srv-1")
+
+	requests.Resolve("s2", "srv-1", json.RawMessage(`{"jsonrpc":"2.0","id":"srv-1","result":{}}`))
+	assert.True(t, requests.Awaits("s1", "srv-1"), "a response from another session must not resolve the request")
+
+	requests.Resolve("s1", "srv-1", json.RawMessage(`{"jsonrpc":"2.0","id":"srv-1","result":{}}`))
+	assert.False(t, requests.Awaits("s1", "srv-1"))
+	assert.NotNil(t, <-responses)
+}
+
+func TestSamplingContents_JSON(t *testing.T) {
+	var single SamplingContents
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"text","text":"a"}`), &single))
+	assert.Len(t, single, 1)
+
+	var several SamplingContents
+	require.NoError(t, json.Unmarshal([]byte(`[{"type":"text","text":"a"},{"type":"tool_use","id":"c1","name":"t","input":{}}]`), &several))
+	assert.Len(t, several, 2)
+
+	data, err := json.Marshal(single)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"text","text":"a"}`, string(data))
+}
+
+func TestClientSession_Progress(t *testing.T) {
+	var sent []*Request
+	client := newClientRequests().session("s1", ClientCapabilities{}, func(req *Request) error {
+		sent = append(sent, req)
+		return nil
+	})
+
+	client.Progress(1, 0, "ignored")
+	assert.Empty(t, sent, "no progress without a progress token")
+
+	client.withProgressToken("tok-1").Progress(2, 5, "step 2")
+	require.Len(t, sent, 1)
+	assert.Equal(t, "notifications/progress", sent[0].Method)
+	assert.JSONEq(t, `{"progressToken":"tok-1","progress":2,"total":5,"message":"step 2"}`, string(sent[0].Params))
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxCompletionValues is the most values a completion/complete response may
+// carry, per the MCP spec.
+const maxCompletionValues = 100
+
+// Complete suggests values for a prompt argument or a resource template
+// variable, filtered by the partial value the user typed.
+func (s *Service) Complete(ctx context.Context, projectID string, params CompletionParams) (*CompletionResult, error) {
+	var source string
+	switch params.Ref.Type {
+	case "ref/prompt":
+		prompts, err := s.PromptsForProject(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(prompts, func(p PromptDefinition) bool { return p.Name == params.Ref.Name })
+		if idx < 0 {
+			return nil, fmt.Errorf("unknown prompt: %s", params.Ref.Name)
+		}
+		prompt := prompts[idx]
+		if !slices.ContainsFunc(prompt.Arguments, func(a PromptArgument) bool { return a.Name == params.Argument.Name }) {
+			return nil, fmt.Errorf("prompt %s has no argument %s", prompt.Name, params.Argument.Name)
+		}
+		source = params.Argument.Name
+	case "ref/resource":
+		switch params.Ref.URI {
+		case "memory://entities/{id}":
+			source = "entity_id"
+		case "memory://documents/{id}":
+			source = "document_id"
+		default:
+			return nil, fmt.Errorf("unknown resource template: %s", params.Ref.URI)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ref type: %s", params.Ref.Type)
+	}
+
+	values, err := s.completionValues(ctx, projectID, source, params.Argument.Value)
+	if err != nil {
+		return nil, err
+	}
+	return newCompletionResult(values), nil
+}
+
+// completionValues queries the candidates for an argument. Arguments without
+// a known source complete to nothing.
+func (s *Service) completionValues(ctx context.Context, projectID, source, prefix string) ([]string, error) {
+	var query string
+	switch source {
+	case "entity_type":
+		query = `
+			SELECT type_name FROM kb.project_object_type_registry
+			WHERE project_id = ? AND enabled = true AND type_name ILIKE ?
+			ORDER BY type_name`
+	case "relationship_type":
+		query = `
+			SELECT DISTINCT type FROM kb.graph_relationships
+			WHERE project_id = ? AND deleted_at IS NULL AND type ILIKE ?
+			ORDER BY type`
+	case "entity_name":
+		query = `
+			SELECT DISTINCT properties->>'name' AS name FROM kb.graph_objects
+			WHERE project_id = ? AND deleted_at IS NULL AND properties->>'name' ILIKE ?
+			ORDER BY name`
+	case "entity_id":
+		query = `
+			SELECT id::text FROM kb.graph_objects
+			WHERE project_id = ? AND deleted_at IS NULL AND id::text LIKE ?
+			ORDER BY updated_at DESC NULLS LAST`
+	case "document_id":
+		query = `
+			SELECT id::text FROM kb.documents
+			WHERE project_id = ? AND id::text LIKE ?
+			ORDER BY created_at DESC`
+	case "template_pack":
+		var names []string
+		err := s.db.NewRaw(`
+			SELECT DISTINCT name FROM kb.graph_template_packs
+			WHERE draft = false AND name ILIKE ?
+			ORDER BY name
+			LIMIT ?`, likePrefix(prefix), maxCompletionValues+1).Scan(ctx, &names)
+		if err != nil {
+			return nil, fmt.Errorf("complete template packs: %w", err)
+		}
+		return names, nil
+	default:
+		return nil, nil
+	}
+
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project_id: %w", err)
+	}
+
+	var values []string
+	if err := s.db.NewRaw(query+"\nLIMIT ?", projectUUID, likePrefix(prefix), maxCompletionValues+1).Scan(ctx, &values); err != nil {
+		return nil, fmt.Errorf("complete %s: %w", source, err)
+	}
+	return values, nil
+}
+
+// newCompletionResult caps values at maxCompletionValues. Queries fetch one
+// extra row so HasMore can be reported without counting; the total is only
+// known when everything fits.
+func newCompletionResult(values []string) *CompletionResult {
+	if values == nil {
+		values = []string{}
+	}
+	if len(values) > maxCompletionValues {
+		return &CompletionResult{Completion: Completion{Values: values[:maxCompletionValues], HasMore: true}}
+	}
+	return &CompletionResult{Completion: Completion{Values: values, Total: len(values)}}
+}
+
+// likePrefix builds a LIKE pattern matching values starting with prefix.
+func likePrefix(prefix string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(prefix) + "%"
+}
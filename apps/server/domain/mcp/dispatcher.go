@@ -0,0 +1,405 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// dispatchedMethods are the session methods served by the dispatcher on every
+// transport. initialize is handled by each transport, since it creates the
+// transport's session.
+var dispatchedMethods = []string{
+	"ping",
+	"tools/list", "tools/call",
+	"resources/list", "resources/templates/list", "resources/read",
+	"resources/subscribe", "resources/unsubscribe",
+	"prompts/list", "prompts/get",
+	"completion/complete",
+	"tasks/get", "tasks/result", "tasks/list", "tasks/cancel",
+}
+
+// callContext is the state a transport resolved for a request: the session's
+// project, whether the client completed initialize, the session ID under
+// which the transport registered the session's stream with the notifier, and
+// the session's client for transports that can send it requests.
+type callContext struct {
+	ProjectID   string
+	Initialized bool
+	SessionID   string
+	Client      *ClientSession
+}
+
+// dispatcher routes JSON-RPC methods to the service. The legacy, SSE and
+// Streamable HTTP transports share it, so every method behaves the same
+// regardless of how the client connected.
+type dispatcher struct {
+	svc      *Service
+	notifier *Notifier
+	calls    *toolCalls
+	tasks    *toolTasks
+	log      *slog.Logger
+}
+
+func newDispatcher(svc *Service, notifier *Notifier, log *slog.Logger) *dispatcher {
+	tasks := svc.tasks
+	if tasks == nil {
+		tasks = newToolTasks(svc.db, log)
+	}
+	return &dispatcher{
+		svc:      svc,
+		notifier: notifier,
+		calls:    newToolCalls(),
+		tasks:    tasks,
+		log:      log,
+	}
+}
+
+// dispatch handles every method except initialize.
+func (d *dispatcher) dispatch(ctx context.Context, req *Request, cc callContext) *Response {
+	if !slices.Contains(dispatchedMethods, req.Method) {
+		return NewErrorResponse(
+			req.ID,
+			ErrCodeMethodNotFound,
+			"Method not found: "+req.Method,
+			map[string]any{
+				"method":            req.Method,
+				"supported_methods": append([]string{"initialize"}, dispatchedMethods...),
+			},
+		)
+	}
+
+	if req.Method == "ping" {
+		return NewSuccessResponse(req.ID, map[string]any{})
+	}
+
+	if !cc.Initialized {
+		return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
+			"Client must call initialize before "+req.Method,
+			map[string]string{"hint": "Call initialize method first to establish session"},
+		)
+	}
+
+	switch req.Method {
+	case "tools/list":
+		return d.toolsList(ctx, req, cc)
+	case "tools/call":
+		return d.toolsCall(ctx, req, cc)
+	case "resources/list":
+		return NewSuccessResponse(req.ID, ResourcesListResult{Resources: d.svc.GetResourceDefinitions()})
+	case "resources/templates/list":
+		return NewSuccessResponse(req.ID, ResourceTemplatesListResult{ResourceTemplates: d.svc.GetResourceTemplates()})
+	case "resources/read":
+		return d.resourcesRead(ctx, req, cc)
+	case "resources/subscribe", "resources/unsubscribe":
+		return d.resourcesSubscribe(req, cc)
+	case "prompts/list":
+		return d.promptsList(ctx, req, cc)
+	case "prompts/get":
+		return d.promptsGet(ctx, req, cc)
+	case "tasks/get", "tasks/result", "tasks/list", "tasks/cancel":
+		return d.taskMethod(ctx, req, cc)
+	default: // completion/complete
+		return d.complete(ctx, req, cc)
+	}
+}
+
+// cancel handles a client's notifications/cancelled for a request of the
+// session. Only tool calls running on this replica can be cancelled here;
+// transports sharing sessions between replicas relay the notification.
+func (d *dispatcher) cancel(sessionID string, req *Request) {
+	var params CancelledParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params.RequestID) == 0 {
+		return
+	}
+	if d.calls.Cancel(sessionID, cancelledRequestID(&params), params.Reason) {
+		d.log.Info("tool call cancelled by client",
+			slog.String("session_id", sessionID),
+			slog.String("request_id", cancelledRequestID(&params)),
+			slog.String("reason", params.Reason),
+		)
+	}
+}
+
+// toolsList returns the tools the caller may use: a tool the caller cannot
+// call is not listed.
+func (d *dispatcher) toolsList(ctx context.Context, req *Request, cc callContext) *Response {
+	tools, err := d.svc.ToolsForCaller(ctx, cc.ProjectID)
+	if err != nil {
+		d.log.Error("failed to list tools", logger.Error(err))
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Failed to list tools", nil)
+	}
+	return NewSuccessResponse(req.ID, ToolsListResult{Tools: tools})
+}
+
+func (d *dispatcher) toolsCall(ctx context.Context, req *Request, cc callContext) *Response {
+	var params ToolsCallParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return resp
+	}
+
+	if params.Name == "" {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameter: name",
+			map[string]any{"required": []string{"name"}},
+		)
+	}
+
+	if cc.ProjectID == "" && requiresProject(params.Name) {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Project ID is required. Provide project_id in initialize params or X-Project-Id header.",
+			map[string]string{"hint": "Call initialize with project_id parameter or set X-Project-Id header"},
+		)
+	}
+
+	if err := d.svc.AuthorizeTool(ctx, cc.ProjectID, params.Name); err != nil {
+		if errors.Is(err, errUnknownTool) {
+			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+				"Unknown tool: "+params.Name,
+				map[string]string{"tool": params.Name},
+			)
+		}
+		var accessErr *ToolAccessError
+		if errors.As(err, &accessErr) {
+			return NewErrorResponse(req.ID, ErrCodeForbidden,
+				"Access denied for tool "+params.Name,
+				map[string]string{"tool": params.Name, "reason": accessErr.Reason},
+			)
+		}
+		d.log.Error("tool authorization failed",
+			slog.String("tool", params.Name),
+			logger.Error(err),
+		)
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Tool authorization failed", nil)
+	}
+
+	if cc.Client != nil {
+		client := cc.Client
+		if params.Meta != nil && params.Meta.ProgressToken != nil {
+			client = client.withProgressToken(params.Meta.ProgressToken)
+		}
+		ctx = ContextWithClientSession(ctx, client)
+	}
+
+	if params.Task != nil {
+		return d.startTask(ctx, req, cc, &params)
+	}
+
+	// Calls on a session can be cancelled with notifications/cancelled
+	if cc.SessionID != "" {
+		var done func()
+		ctx, done = d.calls.start(ctx, cc.SessionID, responseID(req))
+		defer done()
+	}
+
+	result, err := d.svc.ExecuteTool(ctx, cc.ProjectID, params.Name, params.Arguments)
+	var cancelled *CancelledError
+	if errors.As(context.Cause(ctx), &cancelled) {
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Tool call "+cancelled.Error(), nil)
+	}
+	if err != nil {
+		d.log.Error("tool execution failed",
+			slog.String("tool", params.Name),
+			logger.Error(err),
+		)
+		return NewErrorResponse(req.ID, ErrCodeInternalError,
+			"Tool execution failed: "+err.Error(),
+			nil,
+		)
+	}
+
+	return NewSuccessResponse(req.ID, result)
+}
+
+func (d *dispatcher) resourcesRead(ctx context.Context, req *Request, cc callContext) *Response {
+	var params ResourceReadParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return resp
+	}
+
+	if params.URI == "" {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameter: uri",
+			map[string]any{"required": []string{"uri"}},
+		)
+	}
+
+	result, err := d.svc.ReadResource(ctx, cc.ProjectID, params.URI)
+	if err != nil {
+		d.log.Error("resource read failed",
+			slog.String("uri", params.URI),
+			logger.Error(err),
+		)
+		return NewErrorResponse(req.ID, ErrCodeInternalError,
+			"Failed to read resource: "+err.Error(),
+			map[string]string{"uri": params.URI},
+		)
+	}
+
+	return NewSuccessResponse(req.ID, result)
+}
+
+// resourcesSubscribe handles resources/subscribe and resources/unsubscribe.
+// Updates are delivered as notifications/resources/updated on the session's
+// event stream, so the legacy transport cannot subscribe.
+func (d *dispatcher) resourcesSubscribe(req *Request, cc callContext) *Response {
+	var params ResourceSubscribeParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return resp
+	}
+
+	if params.URI == "" {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameter: uri",
+			map[string]any{"required": []string{"uri"}},
+		)
+	}
+
+	if !subscribableResource(params.URI) {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Resource does not support subscriptions: "+params.URI,
+			map[string]any{"subscribable": []string{entityResourcePrefix + "{id}", documentResourcePrefix + "{id}"}},
+		)
+	}
+
+	if req.Method == "resources/unsubscribe" {
+		d.notifier.Unsubscribe(cc.SessionID, params.URI)
+		return NewSuccessResponse(req.ID, map[string]any{})
+	}
+
+	if err := d.notifier.Subscribe(cc.SessionID, params.URI); err != nil {
+		return NewErrorResponse(req.ID, ErrCodeInvalidRequest, err.Error(), nil)
+	}
+	return NewSuccessResponse(req.ID, map[string]any{})
+}
+
+// promptsList returns the built-in prompts and the project's published
+// agent prompts.
+func (d *dispatcher) promptsList(ctx context.Context, req *Request, cc callContext) *Response {
+	prompts, err := d.svc.PromptsForProject(ctx, cc.ProjectID)
+	if err != nil {
+		d.log.Error("failed to list prompts", logger.Error(err))
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Failed to list prompts", nil)
+	}
+	return NewSuccessResponse(req.ID, PromptsListResult{Prompts: prompts})
+}
+
+func (d *dispatcher) promptsGet(ctx context.Context, req *Request, cc callContext) *Response {
+	var params PromptGetParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return resp
+	}
+
+	if params.Name == "" {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameter: name",
+			map[string]any{"required": []string{"name"}},
+		)
+	}
+
+	result, err := d.svc.GetPrompt(ctx, cc.ProjectID, params.Name, params.Arguments)
+	if err != nil {
+		d.log.Error("prompt get failed",
+			slog.String("name", params.Name),
+			logger.Error(err),
+		)
+		return NewErrorResponse(req.ID, ErrCodeInternalError,
+			"Failed to get prompt: "+err.Error(),
+			map[string]string{"name": params.Name},
+		)
+	}
+
+	return NewSuccessResponse(req.ID, result)
+}
+
+func (d *dispatcher) complete(ctx context.Context, req *Request, cc callContext) *Response {
+	var params CompletionParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return resp
+	}
+
+	if params.Ref.Type == "" || params.Argument.Name == "" {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameters: ref, argument",
+			map[string]any{"required": []string{"ref", "argument"}},
+		)
+	}
+
+	result, err := d.svc.Complete(ctx, cc.ProjectID, params)
+	if err != nil {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Failed to complete argument: "+err.Error(),
+			map[string]string{"argument": params.Argument.Name},
+		)
+	}
+
+	return NewSuccessResponse(req.ID, result)
+}
+
+// unmarshalParams decodes request params, returning an invalid params error
+// response when they don't parse.
+func unmarshalParams(req *Request, params any) *Response {
+	if len(req.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Invalid "+req.Method+" params", map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// parseInitialize validates initialize params. It returns an error response
+// when they are missing or name an unsupported protocol version.
+func parseInitialize(req *Request) (*InitializeParams, *Response) {
+	var params InitializeParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return nil, resp
+	}
+
+	if params.ProtocolVersion == "" || params.ClientInfo.Name == "" {
+		return nil, NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameters: protocolVersion, clientInfo",
+			map[string]any{
+				"required": []string{"protocolVersion", "clientInfo"},
+			},
+		)
+	}
+
+	if !slices.Contains(SupportedProtocolVersions, params.ProtocolVersion) {
+		return nil, NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Unsupported protocol version: "+params.ProtocolVersion,
+			map[string]any{
+				"requested": params.ProtocolVersion,
+				"supported": SupportedProtocolVersions,
+			},
+		)
+	}
+
+	return &params, nil
+}
+
+// initializeResult builds the initialize response advertised by every
+// transport. Resource subscriptions and tool and prompt list changes are only
+// advertised by transports with a stream to push notifications on.
+func initializeResult(protocolVersion, projectID string, notifications bool) InitializeResult {
+	result := InitializeResult{
+		ProtocolVersion: protocolVersion,
+		Capabilities: ServerCapabilities{
+			Tools:       ToolsCapability{ListChanged: notifications},
+			Resources:   ResourcesCapability{Subscribe: notifications, ListChanged: false},
+			Prompts:     PromptsCapability{ListChanged: notifications},
+			Completions: &CompletionsCapability{},
+			Tasks:       tasksCapability(),
+		},
+		ServerInfo: ServerInfo,
+	}
+	if projectID != "" {
+		result.ProjectContext = map[string]string{"projectId": projectID}
+	}
+	return result
+}
@@ -0,0 +1,279 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+)
+
+func rpcRequest(method, params string) *Request {
+	req := &Request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: method}
+	if params != "" {
+		req.Params = json.RawMessage(params)
+	}
+	return req
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	d := newDispatcher(&Service{}, NewNotifier(nil, nil, slog.Default()), slog.Default())
+	ctx := context.Background()
+	ready := callContext{ProjectID: "proj-1", Initialized: true}
+
+	t.Run("ping works before initialize", func(t *testing.T) {
+		resp := d.dispatch(ctx, rpcRequest("ping", ""), callContext{})
+		assert.Nil(t, resp.Error)
+		assert.Equal(t, map[string]any{}, resp.Result)
+	})
+
+	t.Run("session methods require initialize", func(t *testing.T) {
+		for _, method := range []string{"tools/list", "resources/list", "resources/templates/list", "prompts/list", "completion/complete"} {
+			resp := d.dispatch(ctx, rpcRequest(method, ""), callContext{})
+			require.NotNil(t, resp.Error, method)
+			assert.Equal(t, ErrCodeInvalidRequest, resp.Error.Code, method)
+			assert.Equal(t, "Client must call initialize before "+method, resp.Error.Message)
+		}
+	})
+
+	t.Run("unknown method lists supported methods", func(t *testing.T) {
+		resp := d.dispatch(ctx, rpcRequest("sampling/createMessage", ""), ready)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, ErrCodeMethodNotFound, resp.Error.Code)
+		supported := resp.Error.Data.(map[string]any)["supported_methods"].([]string)
+		assert.Contains(t, supported, "initialize")
+		assert.Contains(t, supported, "resources/templates/list")
+	})
+
+	t.Run("resources and prompts", func(t *testing.T) {
+		resp := d.dispatch(ctx, rpcRequest("resources/list", ""), ready)
+		require.Nil(t, resp.Error)
+		assert.NotEmpty(t, resp.Result.(ResourcesListResult).Resources)
+
+		resp = d.dispatch(ctx, rpcRequest("resources/templates/list", ""), ready)
+		require.Nil(t, resp.Error)
+		var uris []string
+		for _, tmpl := range resp.Result.(ResourceTemplatesListResult).ResourceTemplates {
+			uris = append(uris, tmpl.URITemplate)
+		}
+		assert.Equal(t, []string{"memory://entities/{id}", "memory://documents/{id}"}, uris)
+
+		resp = d.dispatch(ctx, rpcRequest("prompts/list", ""), ready)
+		require.Nil(t, resp.Error)
+		assert.NotEmpty(t, resp.Result.(PromptsListResult).Prompts)
+
+		resp = d.dispatch(ctx, rpcRequest("prompts/get", `{"name":"explore_entity_type","arguments":{"entity_type":"Decision"}}`), ready)
+		require.Nil(t, resp.Error)
+		assert.NotEmpty(t, resp.Result.(*PromptGetResult).Messages)
+	})
+
+	t.Run("resource subscriptions", func(t *testing.T) {
+		resp := d.dispatch(ctx, rpcRequest("resources/subscribe", `{"uri":"memory://entities/e1"}`), ready)
+		require.NotNil(t, resp.Error, "sessions without a stream cannot subscribe")
+		assert.Equal(t, ErrCodeInvalidRequest, resp.Error.Code)
+
+		d.notifier.Register("s1", "proj-1", func(*Notification) error { return nil })
+		defer d.notifier.Unregister("s1")
+		withSession := callContext{ProjectID: "proj-1", Initialized: true, SessionID: "s1"}
+
+		resp = d.dispatch(ctx, rpcRequest("resources/subscribe", `{"uri":"memory://schema/entity-types"}`), withSession)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, "Resource does not support subscriptions: memory://schema/entity-types", resp.Error.Message)
+
+		resp = d.dispatch(ctx, rpcRequest("resources/subscribe", `{"uri":"memory://entities/e1"}`), withSession)
+		require.Nil(t, resp.Error)
+		assert.Equal(t, []string{"proj-1"}, d.notifier.subscribedProjects())
+
+		resp = d.dispatch(ctx, rpcRequest("resources/unsubscribe", `{"uri":"memory://entities/e1"}`), withSession)
+		require.Nil(t, resp.Error)
+		assert.Empty(t, d.notifier.subscribedProjects())
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		resp := d.dispatch(ctx, rpcRequest("resources/read", `{}`), ready)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, "Missing required parameter: uri", resp.Error.Message)
+
+		resp = d.dispatch(ctx, rpcRequest("prompts/get", `[1]`), ready)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, "Invalid prompts/get params", resp.Error.Message)
+
+		resp = d.dispatch(ctx, rpcRequest("completion/complete", `{"ref":{"type":"ref/prompt","name":"explore_entity_type"}}`), ready)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, "Missing required parameters: ref, argument", resp.Error.Message)
+
+		resp = d.dispatch(ctx, rpcRequest("tools/call", `{"name":"query_entities"}`), callContext{Initialized: true})
+		require.NotNil(t, resp.Error)
+		assert.Contains(t, resp.Error.Message, "Project ID is required")
+	})
+
+	t.Run("task-augmented calls", func(t *testing.T) {
+		resp := d.dispatch(ctx, rpcRequest("tools/call", `{"name":"query_entities","arguments":{},"task":{"ttl":60000}}`), ready)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, ErrCodeInvalidParams, resp.Error.Code)
+		assert.Equal(t, "Tool query_entities does not support task execution", resp.Error.Message)
+
+		resp = d.dispatch(ctx, rpcRequest("tasks/get", `{}`), ready)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, "Missing required parameter: taskId", resp.Error.Message)
+	})
+}
+
+func TestService_CompleteRejectsUnknownRefs(t *testing.T) {
+	svc := &Service{}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		params CompletionParams
+		errMsg string
+	}{
+		{"unknown prompt", CompletionParams{Ref: CompletionRef{Type: "ref/prompt", Name: "nope"}, Argument: CompletionArgument{Name: "x"}}, "unknown prompt: nope"},
+		{"unknown argument", CompletionParams{Ref: CompletionRef{Type: "ref/prompt", Name: "explore_entity_type"}, Argument: CompletionArgument{Name: "depth"}}, "has no argument depth"},
+		{"unknown template", CompletionParams{Ref: CompletionRef{Type: "ref/resource", URI: "memory://tasks/{id}"}, Argument: CompletionArgument{Name: "id"}}, "unknown resource template"},
+		{"unknown ref type", CompletionParams{Ref: CompletionRef{Type: "ref/tool"}, Argument: CompletionArgument{Name: "id"}}, "unsupported ref type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Complete(ctx, "proj-1", tt.params)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	// Arguments without a completion source return no values without a query
+	result, err := svc.Complete(ctx, "proj-1", CompletionParams{
+		Ref:      CompletionRef{Type: "ref/prompt", Name: "setup_research_project"},
+		Argument: CompletionArgument{Name: "methodology", Value: "qual"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, result.Completion.Values)
+}
+
+func TestNewCompletionResult(t *testing.T) {
+	result := newCompletionResult([]string{"Decision", "Document"})
+	assert.Equal(t, 2, result.Completion.Total)
+	assert.False(t, result.Completion.HasMore)
+
+	many := make([]string, maxCompletionValues+1)
+	result = newCompletionResult(many)
+	assert.Len(t, result.Completion.Values, maxCompletionValues)
+	assert.True(t, result.Completion.HasMore)
+	assert.Zero(t, result.Completion.Total, "the total is unknown when truncated")
+}
+
+func TestLikePrefix(t *testing.T) {
+	assert.Equal(t, "Dec%", likePrefix("Dec"))
+	assert.Equal(t, `50\%\_off\\%`, likePrefix(`50%_off\`))
+	assert.Equal(t, "%", likePrefix(""))
+}
+
+func TestParseInitialize(t *testing.T) {
+	params, resp := parseInitialize(rpcRequest("initialize", `{"protocolVersion":"2025-11-25","clientInfo":{"name":"test"}}`))
+	require.Nil(t, resp)
+	assert.Equal(t, "test", params.ClientInfo.Name)
+
+	_, resp = parseInitialize(rpcRequest("initialize", `{"protocolVersion":"2025-11-25"}`))
+	require.NotNil(t, resp)
+	assert.Contains(t, resp.Error.Message, "Missing required parameters")
+
+	_, resp = parseInitialize(rpcRequest("initialize", `{"protocolVersion":"2024-01-01","clientInfo":{"name":"test"}}`))
+	require.NotNil(t, resp)
+	assert.Equal(t, "Unsupported protocol version: 2024-01-01", resp.Error.Message)
+
+	result := initializeResult("2025-11-25", "proj-1", true)
+	assert.NotNil(t, result.Capabilities.Completions)
+	assert.True(t, result.Capabilities.Resources.Subscribe)
+	assert.True(t, result.Capabilities.Tools.ListChanged)
+	assert.Equal(t, map[string]string{"projectId": "proj-1"}, result.ProjectContext)
+
+	result = initializeResult("2025-11-25", "", false)
+	assert.False(t, result.Capabilities.Resources.Subscribe, "transports without a stream cannot push updates")
+	assert.False(t, result.Capabilities.Tools.ListChanged)
+	assert.Nil(t, result.ProjectContext)
+}
+
+func TestStreamableHTTPHandler_ResourcesAndPrompts(t *testing.T) {
+	e := echo.New()
+	notifier := NewNotifier(nil, nil, slog.Default())
+	store := NewMemorySessionStore(100, time.Hour)
+	h := NewStreamableHTTPHandler(&Service{}, notifier, store, slog.Default())
+	user := &auth.AuthUser{ID: "user-1", ProjectID: "proj-1"}
+
+	post := func(sessionID, body string) (*httptest.ResponseRecorder, *Response) {
+		req := httptest.NewRequest(http.MethodPost, "/api/mcp", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("MCP-Protocol-Version", "2025-11-25")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(string(auth.UserContextKey), user)
+		require.NoError(t, h.HandleUnifiedEndpoint(c))
+
+		var resp Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec, &resp
+	}
+
+	rec, resp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-11-25","clientInfo":{"name":"test"}}}`)
+	require.Nil(t, resp.Error)
+	assert.Contains(t, rec.Body.String(), `"completions":{}`)
+	assert.Contains(t, rec.Body.String(), `"tasks":{"list":{},"cancel":{},"requests":{"tools":{"call":{}}}}`)
+	sessionID := rec.Header().Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+
+	for _, method := range []string{"resources/list", "resources/templates/list", "prompts/list"} {
+		_, resp = post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"`+method+`"}`)
+		assert.Nil(t, resp.Error, method)
+	}
+
+	_, resp = post(sessionID, `{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"analyze_relationships","arguments":{"entity_name":"Acme"}}}`)
+	require.Nil(t, resp.Error)
+	assert.Contains(t, mustJSON(t, resp.Result), "Acme")
+
+	// Updates to subscribed resources are queued for the session's GET stream
+	_, resp = post(sessionID, `{"jsonrpc":"2.0","id":4,"method":"resources/subscribe","params":{"uri":"memory://entities/e1"}}`)
+	require.Nil(t, resp.Error)
+	notifier.ResourceUpdated("proj-1", "memory://entities/e1")
+	notifier.ToolsChanged("proj-1")
+
+	queued, err := store.EventsSince(context.Background(), sessionID, -1)
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"memory://entities/e1"}}`, string(queued[0].Data))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`, string(queued[1].Data))
+
+	// Tool calls accepting an event stream get their response, and the
+	// messages sent while they run, on the POST's own stream
+	req := httptest.NewRequest(http.MethodPost, "/api/mcp", bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"no_such_tool","arguments":{}}}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2025-11-25")
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(string(auth.UserContextKey), user)
+	require.NoError(t, h.HandleUnifiedEndpoint(c))
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "data: ")
+	assert.Contains(t, rec.Body.String(), "Unknown tool: no_such_tool")
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
@@ -1,7 +1,9 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"time"
 )
 
@@ -28,6 +30,31 @@ type MCPRegistryToolHandler interface {
 	GetMCPRegistryToolDefinitions() []ToolDefinition
 }
 
+// AgentPublisher publishes a project's agent definitions as MCP tools and
+// prompts, so clients can run an agent by name.
+// Implemented by the agents domain to avoid circular imports (agents → mcp).
+type AgentPublisher interface {
+	PublishedAgentTools(ctx context.Context, projectID string) ([]ToolDefinition, error)
+	ExecutePublishedAgent(ctx context.Context, projectID, name string, args map[string]any) (*ToolResult, error)
+	PublishedAgentPrompts(ctx context.Context, projectID string) ([]PromptDefinition, error)
+	GetPublishedAgentPrompt(ctx context.Context, projectID, name string, args map[string]any) (*PromptGetResult, error)
+}
+
+// DocumentToolHandler is the interface for executing document ingestion, chunk
+// retrieval and extraction MCP tools.
+// Implemented by the extraction domain to avoid circular imports (extraction → mcp).
+type DocumentToolHandler interface {
+	ExecuteCreateDocument(ctx context.Context, projectID string, args map[string]any) (*ToolResult, error)
+	ExecuteListDocuments(ctx context.Context, projectID string, args map[string]any) (*ToolResult, error)
+	ExecuteGetDocument(ctx context.Context, projectID string, args map[string]any) (*ToolResult, error)
+	ExecuteGetChunkContext(ctx context.Context, projectID string, args map[string]any) (*ToolResult, error)
+	ExecuteGetExtractionStatus(ctx context.Context, projectID string, args map[string]any) (*ToolResult, error)
+	ExecuteStartExtraction(ctx context.Context, projectID string, args map[string]any) (*ToolResult, error)
+
+	// GetDocumentToolDefinitions returns tool definitions for all document tools
+	GetDocumentToolDefinitions() []ToolDefinition
+}
+
 // AgentToolHandler is the interface for executing agent-related MCP tools.
 // Implemented by the agents domain to avoid circular imports (agents → mcp).
 type AgentToolHandler interface {
@@ -61,10 +88,37 @@ type AgentToolHandler interface {
 
 // InitializeParams represents the params for initialize method
 type InitializeParams struct {
-	ProtocolVersion string     `json:"protocolVersion"`
-	Capabilities    any        `json:"capabilities"`
-	ClientInfo      ClientInfo `json:"clientInfo"`
-	ProjectID       string     `json:"project_id,omitempty"` // Optional project context
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      ClientInfo         `json:"clientInfo"`
+	ProjectID       string             `json:"project_id,omitempty"` // Optional project context
+}
+
+// ClientCapabilities are the features a client declares in initialize. The
+// server only sends sampling and elicitation requests to clients declaring
+// them.
+type ClientCapabilities struct {
+	Roots       *RootsCapability       `json:"roots,omitempty"`
+	Sampling    *SamplingCapability    `json:"sampling,omitempty"`
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
+}
+
+// RootsCapability declares that the client exposes filesystem roots
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability declares support for sampling/createMessage. Tools is
+// set when the client accepts tool definitions in sampling requests.
+type SamplingCapability struct {
+	Tools *struct{} `json:"tools,omitempty"`
+}
+
+// ElicitationCapability declares support for elicitation/create. An empty
+// capability means form mode, for clients predating the form and url modes.
+type ElicitationCapability struct {
+	Form *struct{} `json:"form,omitempty"`
+	URL  *struct{} `json:"url,omitempty"`
 }
 
 // ClientInfo represents client metadata
@@ -83,9 +137,29 @@ type InitializeResult struct {
 
 // ServerCapabilities describes what the server supports
 type ServerCapabilities struct {
-	Tools     ToolsCapability     `json:"tools"`
-	Resources ResourcesCapability `json:"resources"`
-	Prompts   PromptsCapability   `json:"prompts"`
+	Tools       ToolsCapability        `json:"tools"`
+	Resources   ResourcesCapability    `json:"resources"`
+	Prompts     PromptsCapability      `json:"prompts"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
+	Tasks       *TasksCapability       `json:"tasks,omitempty"`
+}
+
+// TasksCapability advertises that tool calls can run as tasks, and that
+// tasks can be listed and cancelled.
+type TasksCapability struct {
+	List     *struct{}               `json:"list,omitempty"`
+	Cancel   *struct{}               `json:"cancel,omitempty"`
+	Requests *TaskRequestsCapability `json:"requests,omitempty"`
+}
+
+// TaskRequestsCapability lists the requests that can run as tasks.
+type TaskRequestsCapability struct {
+	Tools *TaskToolsCapability `json:"tools,omitempty"`
+}
+
+// TaskToolsCapability lists the tool requests that can run as tasks.
+type TaskToolsCapability struct {
+	Call *struct{} `json:"call,omitempty"`
 }
 
 // ToolsCapability describes tool-related capabilities
@@ -104,6 +178,9 @@ type PromptsCapability struct {
 	ListChanged bool `json:"listChanged"`
 }
 
+// CompletionsCapability advertises support for completion/complete
+type CompletionsCapability struct{}
+
 // ToolsListResult represents the result of tools/list method
 type ToolsListResult struct {
 	Tools []ToolDefinition `json:"tools"`
@@ -122,11 +199,34 @@ type ResourceDefinition struct {
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
+// ResourceTemplate describes a parameterized MCP resource (RFC 6570 URI template)
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplatesListResult represents the result of resources/templates/list method
+type ResourceTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
 // ResourceReadParams represents params for resources/read method
 type ResourceReadParams struct {
 	URI string `json:"uri"`
 }
 
+// ResourceSubscribeParams represents params for resources/subscribe and resources/unsubscribe
+type ResourceSubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams represents params of notifications/resources/updated
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
 // ResourceContents represents the contents of a resource
 type ResourceContents struct {
 	URI      string `json:"uri"`
@@ -183,11 +283,67 @@ type PromptGetResult struct {
 	Messages    []PromptMessage `json:"messages"`
 }
 
+// CompletionParams represents params for completion/complete method
+type CompletionParams struct {
+	Ref      CompletionRef      `json:"ref"`
+	Argument CompletionArgument `json:"argument"`
+	Context  *CompletionContext `json:"context,omitempty"`
+}
+
+// CompletionRef identifies what is being completed: a prompt ("ref/prompt",
+// by name) or a resource template ("ref/resource", by URI template)
+type CompletionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and its partial value
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext carries arguments the client has already resolved
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompletionResult represents the result of completion/complete method
+type CompletionResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion lists suggested values for an argument
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
 // ToolDefinition describes an MCP tool
 type ToolDefinition struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema InputSchema `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	InputSchema InputSchema      `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+	Execution   *ToolExecution   `json:"execution,omitempty"`
+}
+
+// ToolExecution describes how a tool may be called. Long-running tools
+// support being called as a task the client polls.
+type ToolExecution struct {
+	TaskSupport string `json:"taskSupport,omitempty"` // forbidden, optional or required
+}
+
+// ToolAnnotations are the MCP behaviour hints of a tool. Clients use them to
+// decide which calls need user confirmation; they are hints, not guarantees.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
 // InputSchema is a JSON schema for tool parameters
@@ -211,6 +367,54 @@ type PropertySchema struct {
 type ToolsCallParams struct {
 	Name      string         `json:"name"`
 	Arguments map[string]any `json:"arguments"`
+	Meta      *RequestMeta   `json:"_meta,omitempty"`
+	// Task asks to run the call as a task: the call returns at once and the
+	// client polls the task for the result
+	Task *TaskMetadata `json:"task,omitempty"`
+}
+
+// TaskMetadata are the client's parameters of a task it asks for.
+type TaskMetadata struct {
+	// TTL is how long the task and its result are kept, in milliseconds
+	TTL *int64 `json:"ttl,omitempty"`
+}
+
+// Task is the state of a tool call running as a task.
+type Task struct {
+	TaskID        string `json:"taskId"`
+	Status        string `json:"status"` // working, input_required, completed, failed or cancelled
+	StatusMessage string `json:"statusMessage,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+	LastUpdatedAt string `json:"lastUpdatedAt"`
+	TTL           int64  `json:"ttl"`
+	PollInterval  int64  `json:"pollInterval,omitempty"`
+}
+
+// CreateTaskResult is the result of a tools/call run as a task.
+type CreateTaskResult struct {
+	Task Task `json:"task"`
+}
+
+// TaskParams are the params of tasks/get, tasks/result and tasks/cancel.
+type TaskParams struct {
+	TaskID string `json:"taskId"`
+}
+
+// TasksListParams are the params of tasks/list.
+type TasksListParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// TasksListResult is the result of tasks/list.
+type TasksListResult struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// RequestMeta is the _meta of a request. A progress token asks the server to
+// report the request's progress with notifications/progress.
+type RequestMeta struct {
+	ProgressToken any `json:"progressToken,omitempty"`
 }
 
 // ToolResult represents the result of a tool call (MCP content format)
@@ -539,3 +743,134 @@ type DeleteEntityResult struct {
 	EntityID string `json:"entity_id"`
 	Message  string `json:"message"`
 }
+
+// CreateMessageParams are the params of a sampling/createMessage request,
+// asking the client to run its model
+type CreateMessageParams struct {
+	Messages         []SamplingMessage `json:"messages"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	Tools            []SamplingTool    `json:"tools,omitempty"`
+}
+
+// ModelPreferences hint which model the client should pick
+type ModelPreferences struct {
+	Hints []ModelHint `json:"hints,omitempty"`
+}
+
+// ModelHint names a model, or a substring of model names, the server prefers
+type ModelHint struct {
+	Name string `json:"name"`
+}
+
+// SamplingTool is a tool the client's model may call during sampling
+type SamplingTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"inputSchema"`
+}
+
+// SamplingMessage is a message of a sampling conversation
+type SamplingMessage struct {
+	Role    string           `json:"role"` // "user" or "assistant"
+	Content SamplingContents `json:"content"`
+}
+
+// SamplingContent is a content block of a sampling message: text, image,
+// audio, a tool_use by the model or the tool_result answering it
+type SamplingContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+
+	// tool_use
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Input any    `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string            `json:"toolUseId,omitempty"`
+	Content   []SamplingContent `json:"content,omitempty"`
+	IsError   bool              `json:"isError,omitempty"`
+}
+
+// SamplingContents is the content of a sampling message. A single block is
+// sent as an object, which clients predating multi-block content expect.
+type SamplingContents []SamplingContent
+
+// MarshalJSON encodes a single block as an object and several as an array.
+func (c SamplingContents) MarshalJSON() ([]byte, error) {
+	if len(c) == 1 {
+		return json.Marshal(c[0])
+	}
+	return json.Marshal([]SamplingContent(c))
+}
+
+// UnmarshalJSON accepts an object or an array of blocks.
+func (c *SamplingContents) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var block SamplingContent
+		if err := json.Unmarshal(trimmed, &block); err != nil {
+			return err
+		}
+		*c = SamplingContents{block}
+		return nil
+	}
+	var blocks []SamplingContent
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*c = blocks
+	return nil
+}
+
+// CreateMessageResult is the client's sampling result
+type CreateMessageResult struct {
+	Role       string           `json:"role"`
+	Content    SamplingContents `json:"content"`
+	Model      string           `json:"model"`
+	StopReason string           `json:"stopReason,omitempty"` // endTurn, stopSequence, maxTokens, toolUse
+}
+
+// ElicitParams are the params of an elicitation/create request, asking the
+// client to collect input from its user with a form
+type ElicitParams struct {
+	Message         string       `json:"message"`
+	RequestedSchema ElicitSchema `json:"requestedSchema"`
+}
+
+// ElicitSchema is the flat object schema of an elicitation form
+type ElicitSchema struct {
+	Type       string                    `json:"type"` // always "object"
+	Properties map[string]ElicitProperty `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// ElicitProperty is a primitive form field: string, number, integer,
+// boolean, or a string enum
+type ElicitProperty struct {
+	Type        string   `json:"type"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	EnumNames   []string `json:"enumNames,omitempty"`
+	Default     any      `json:"default,omitempty"`
+}
+
+// Elicitation actions
+const (
+	ElicitActionAccept  = "accept"
+	ElicitActionDecline = "decline"
+	ElicitActionCancel  = "cancel"
+)
+
+// ElicitResult is the user's answer to an elicitation. Content is only set
+// when the user accepted.
+type ElicitResult struct {
+	Action  string         `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
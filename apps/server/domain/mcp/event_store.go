@@ -32,9 +32,9 @@ func NewEventStore(maxEvents int) *EventStore {
 	}
 }
 
-// AddEvent stores an event for the given session and returns its assigned ID
+// AddEvent stores an event for the given session and returns it with its assigned ID
 // Events are pruned to keep only the last maxEvents per session
-func (es *EventStore) AddEvent(sessionID string, data json.RawMessage) int64 {
+func (es *EventStore) AddEvent(sessionID string, data json.RawMessage) *SSEEvent {
 	es.mu.Lock()
 	defer es.mu.Unlock()
 
@@ -58,7 +58,7 @@ func (es *EventStore) AddEvent(sessionID string, data json.RawMessage) int64 {
 		es.events[sessionID] = es.events[sessionID][1:]
 	}
 
-	return id
+	return event
 }
 
 // GetEventsSince returns all events after the given ID for resumability support
@@ -1,10 +1,8 @@
 package mcp
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
-	"slices"
 	"sync"
 
 	"github.com/labstack/echo/v4"
@@ -16,8 +14,9 @@ import (
 
 // Handler handles MCP HTTP requests
 type Handler struct {
-	svc *Service
-	log *slog.Logger
+	svc        *Service
+	dispatcher *dispatcher
+	log        *slog.Logger
 
 	// Session management (token -> session)
 	sessions   map[string]*Session
@@ -31,22 +30,24 @@ type Session struct {
 }
 
 // NewHandler creates a new MCP handler
-func NewHandler(svc *Service, log *slog.Logger) *Handler {
+func NewHandler(svc *Service, notifier *Notifier, log *slog.Logger) *Handler {
+	log = log.With(logger.Scope("mcp.handler"))
 	return &Handler{
-		svc:      svc,
-		log:      log.With(logger.Scope("mcp.handler")),
-		sessions: make(map[string]*Session),
+		svc:        svc,
+		dispatcher: newDispatcher(svc, notifier, log),
+		log:        log,
+		sessions:   make(map[string]*Session),
 	}
 }
 
 // HandleRPC handles POST /mcp/rpc - JSON-RPC 2.0 endpoint (legacy)
 // @Summary      Execute Model Context Protocol JSON-RPC 2.0 requests
-// @Description  Legacy JSON-RPC endpoint for Model Context Protocol. Supports initialize, tools/*, resources/*, and prompts/* methods. Use unified /api/mcp endpoint for SSE support (Spec 2025-11-25).
+// @Description  Legacy JSON-RPC endpoint for Model Context Protocol. Supports initialize, ping, tools/*, resources/*, prompts/* and completion/complete methods. Use unified /api/mcp endpoint for SSE support (Spec 2025-11-25).
 // @Tags         mcp
 // @Accept       json
 // @Produce      json
 // @Param        X-Project-ID header string true "Project ID (UUID)"
-// @Param        request body Request true "JSON-RPC 2.0 request (method: initialize, tools/list, tools/call, resources/list, resources/read, prompts/list, prompts/get)"
+// @Param        request body Request true "JSON-RPC 2.0 request (method: initialize, ping, tools/list, tools/call, resources/list, resources/templates/list, resources/read, prompts/list, prompts/get, completion/complete)"
 // @Success      200 {object} Response "JSON-RPC 2.0 response (success or error)"
 // @Failure      401 {object} apperror.Error "Unauthorized"
 // @Router       /api/mcp/rpc [post]
@@ -110,64 +111,29 @@ func (h *Handler) handleNotification(c echo.Context, req *Request) {
 
 // routeMethod routes JSON-RPC requests to the appropriate handler
 func (h *Handler) routeMethod(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	switch req.Method {
-	case "initialize":
+	if req.Method == "initialize" {
 		return h.handleInitialize(c, req, user)
-	case "tools/list":
-		return h.handleToolsList(c, req, user)
-	case "tools/call":
-		return h.handleToolsCall(c, req, user)
-	case "resources/list":
-		return h.handleResourcesList(c, req, user)
-	case "resources/read":
-		return h.handleResourcesRead(c, req, user)
-	case "prompts/list":
-		return h.handlePromptsList(c, req, user)
-	case "prompts/get":
-		return h.handlePromptsGet(c, req, user)
-	default:
-		return NewErrorResponse(
-			req.ID,
-			ErrCodeMethodNotFound,
-			"Method not found: "+req.Method,
-			map[string]any{
-				"method":            req.Method,
-				"supported_methods": []string{"initialize", "tools/list", "tools/call", "resources/list", "resources/read", "prompts/list", "prompts/get"},
-			},
-		)
 	}
-}
 
-// handleInitialize handles the initialize method
-func (h *Handler) handleInitialize(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	// Parse params
-	var params InitializeParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Invalid initialize params", map[string]string{"error": err.Error()})
+	cc := callContext{ProjectID: user.ProjectID}
+	if session := h.getSession(extractToken(c)); session != nil {
+		cc.Initialized = session.Initialized
+		if session.ProjectID != "" {
+			cc.ProjectID = session.ProjectID
 		}
 	}
-
-	// Validate required fields
-	if params.ProtocolVersion == "" || params.ClientInfo.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameters: protocolVersion, clientInfo",
-			map[string]any{
-				"required": []string{"protocolVersion", "clientInfo"},
-			},
-		)
+	if cc.ProjectID == "" {
+		cc.ProjectID = c.Request().Header.Get("X-Project-ID")
 	}
 
-	// Validate protocol version
-	if !slices.Contains(SupportedProtocolVersions, params.ProtocolVersion) {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Unsupported protocol version: "+params.ProtocolVersion,
-			map[string]any{
-				"requested": params.ProtocolVersion,
-				"supported": SupportedProtocolVersions,
-			},
-		)
+	return h.dispatcher.dispatch(c.Request().Context(), req, cc)
+}
+
+// handleInitialize handles the initialize method
+func (h *Handler) handleInitialize(c echo.Context, req *Request, user *auth.AuthUser) *Response {
+	params, errResp := parseInitialize(req)
+	if errResp != nil {
+		return errResp
 	}
 
 	// Create/update session
@@ -186,104 +152,13 @@ func (h *Handler) handleInitialize(c echo.Context, req *Request, user *auth.Auth
 		h.sessionsMu.Unlock()
 	}
 
-	// Build response
-	result := InitializeResult{
-		ProtocolVersion: params.ProtocolVersion,
-		Capabilities: ServerCapabilities{
-			Tools:     ToolsCapability{ListChanged: false},
-			Resources: ResourcesCapability{Subscribe: false, ListChanged: false},
-			Prompts:   PromptsCapability{ListChanged: false},
-		},
-		ServerInfo: ServerInfo,
-	}
-
-	if projectID != "" {
-		result.ProjectContext = map[string]string{"projectId": projectID}
-	}
-
 	h.log.Info("MCP session initialized",
 		slog.String("client", params.ClientInfo.Name),
 		slog.String("version", params.ClientInfo.Version),
 		slog.String("project_id", projectID),
 	)
 
-	return NewSuccessResponse(req.ID, result)
-}
-
-// handleToolsList handles the tools/list method
-func (h *Handler) handleToolsList(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	// Check session initialization
-	token := extractToken(c)
-	session := h.getSession(token)
-	if session == nil || !session.Initialized {
-		return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
-			"Client must call initialize before tools/list",
-			map[string]string{"hint": "Call initialize method first to establish session"},
-		)
-	}
-
-	tools := h.svc.GetToolDefinitions()
-	return NewSuccessResponse(req.ID, ToolsListResult{Tools: tools})
-}
-
-// handleToolsCall handles the tools/call method
-func (h *Handler) handleToolsCall(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	// Check session initialization
-	token := extractToken(c)
-	session := h.getSession(token)
-	if session == nil || !session.Initialized {
-		return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
-			"Client must call initialize before tools/call",
-			map[string]string{"hint": "Call initialize method first to establish session"},
-		)
-	}
-
-	// Parse params
-	var params ToolsCallParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Invalid tools/call params", map[string]string{"error": err.Error()})
-		}
-	}
-
-	if params.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameter: name",
-			map[string]any{"required": []string{"name"}},
-		)
-	}
-
-	// Get project ID from session or user context
-	projectID := session.ProjectID
-	if projectID == "" {
-		projectID = user.ProjectID
-	}
-
-	if projectID == "" {
-		// Some tools require project ID
-		if requiresProject(params.Name) {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Project ID is required. Provide project_id in initialize params or X-Project-Id header.",
-				map[string]string{"hint": "Call initialize with project_id parameter or set X-Project-Id header"},
-			)
-		}
-	}
-
-	// Execute tool
-	result, err := h.svc.ExecuteTool(c.Request().Context(), projectID, params.Name, params.Arguments)
-	if err != nil {
-		h.log.Error("tool execution failed",
-			slog.String("tool", params.Name),
-			logger.Error(err),
-		)
-		return NewErrorResponse(req.ID, ErrCodeInternalError,
-			"Tool execution failed: "+err.Error(),
-			nil,
-		)
-	}
-
-	return NewSuccessResponse(req.ID, result)
+	return NewSuccessResponse(req.ID, initializeResult(params.ProtocolVersion, projectID, false))
 }
 
 // getSession returns the session for a token
@@ -315,95 +190,11 @@ func requiresProject(toolName string) bool {
 	switch toolName {
 	case "list_entity_types", "query_entities", "search_entities", "get_entity_edges",
 		"get_available_templates", "get_installed_templates",
-		"assign_template_pack", "update_template_assignment", "uninstall_template_pack":
+		"assign_template_pack", "update_template_assignment", "uninstall_template_pack",
+		"create_document", "list_documents", "get_document", "get_chunk_context",
+		"get_extraction_status", "start_extraction":
 		return true
 	default:
 		return false
 	}
 }
-
-func (h *Handler) handleResourcesList(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	resources := h.svc.GetResourceDefinitions()
-	result := ResourcesListResult{Resources: resources}
-
-	return NewSuccessResponse(req.ID, result)
-}
-
-func (h *Handler) handleResourcesRead(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	var params ResourceReadParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Invalid resources/read params", map[string]string{"error": err.Error()})
-		}
-	}
-
-	if params.URI == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameter: uri",
-			map[string]any{"required": []string{"uri"}},
-		)
-	}
-
-	projectID := user.ProjectID
-	if projectID == "" {
-		projectID = c.Request().Header.Get("X-Project-ID")
-	}
-
-	result, err := h.svc.ReadResource(c.Request().Context(), projectID, params.URI)
-	if err != nil {
-		h.log.Error("resource read failed",
-			slog.String("uri", params.URI),
-			logger.Error(err),
-		)
-		return NewErrorResponse(req.ID, ErrCodeInternalError,
-			"Failed to read resource: "+err.Error(),
-			map[string]string{"uri": params.URI},
-		)
-	}
-
-	return NewSuccessResponse(req.ID, result)
-}
-
-func (h *Handler) handlePromptsList(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	prompts := h.svc.GetPromptDefinitions()
-	result := PromptsListResult{Prompts: prompts}
-
-	return NewSuccessResponse(req.ID, result)
-}
-
-func (h *Handler) handlePromptsGet(c echo.Context, req *Request, user *auth.AuthUser) *Response {
-	var params PromptGetParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Invalid prompts/get params", map[string]string{"error": err.Error()})
-		}
-	}
-
-	if params.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameter: name",
-			map[string]any{"required": []string{"name"}},
-		)
-	}
-
-	projectID := user.ProjectID
-	if projectID == "" {
-		projectID = c.Request().Header.Get("X-Project-ID")
-	}
-
-	result, err := h.svc.GetPrompt(c.Request().Context(), projectID, params.Name, params.Arguments)
-	if err != nil {
-		h.log.Error("prompt get failed",
-			slog.String("name", params.Name),
-			logger.Error(err),
-		)
-		return NewErrorResponse(req.ID, ErrCodeInternalError,
-			"Failed to get prompt: "+err.Error(),
-			map[string]string{"name": params.Name},
-		)
-	}
-
-	return NewSuccessResponse(req.ID, result)
-}
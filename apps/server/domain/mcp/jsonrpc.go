@@ -10,6 +10,11 @@ type Request struct {
 	ID      json.RawMessage `json:"id,omitempty"` // Can be string, number, or null
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
+
+	// Result and Error are set when the message is a client's response to
+	// a request the server sent
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ErrorObject    `json:"error,omitempty"`
 }
 
 // Response represents a JSON-RPC 2.0 response
@@ -20,6 +25,13 @@ type Response struct {
 	Error   *ErrorObject    `json:"error,omitempty"`
 }
 
+// Notification represents a JSON-RPC 2.0 notification sent by the server
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 // ErrorObject represents a JSON-RPC 2.0 error
 type ErrorObject struct {
 	Code    int    `json:"code"`
@@ -73,11 +85,25 @@ func NewSuccessResponse(id json.RawMessage, result any) *Response {
 	}
 }
 
+// NewNotification creates a JSON-RPC notification
+func NewNotification(method string, params any) *Notification {
+	return &Notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+}
+
 // IsNotification checks if request is a notification (no ID)
 func (r *Request) IsNotification() bool {
 	return r.ID == nil || len(r.ID) == 0
 }
 
+// IsResponse checks if the message is a response to a server request
+func (r *Request) IsResponse() bool {
+	return r.Method == "" && len(r.ID) > 0 && (r.Result != nil || r.Error != nil)
+}
+
 // GetIDString returns the ID as a string (for logging)
 func (r *Request) GetIDString() string {
 	if r.ID == nil || len(r.ID) == 0 {
@@ -185,6 +185,22 @@ func TestRequiresProject(t *testing.T) {
 			toolName: "delete_template_pack",
 			expected: false,
 		},
+		// Document tools
+		{
+			name:     "create_document requires project",
+			toolName: "create_document",
+			expected: true,
+		},
+		{
+			name:     "get_chunk_context requires project",
+			toolName: "get_chunk_context",
+			expected: true,
+		},
+		{
+			name:     "start_extraction requires project",
+			toolName: "start_extraction",
+			expected: true,
+		},
 		// Global tool
 		{
 			name:     "schema_version does not require project",
@@ -9,6 +9,10 @@ import (
 // Features:
 // - JSON-RPC 2.0 over HTTP POST (/mcp/rpc)
 // - SSE transport (/mcp/sse/:projectId)
+// - Streamable HTTP transport (/mcp)
+// - Resources, resource templates, prompts and argument completion on every transport
+// - Resource subscriptions and tool list change notifications on the SSE and Streamable HTTP transports
+// - Streamable HTTP sessions in memory or in Postgres, shared across replicas (MCP_SESSION_STORE)
 // - Tools: schema_version, list_entity_types, query_entities, search_entities, get_entity_edges
 // - Tools: create_entity, create_relationship, update_entity, delete_entity
 // - Tools: list_template_packs, get_template_pack, get_available_templates, get_installed_templates
@@ -21,8 +25,41 @@ import (
 //   - Agent Catalog: list_available_agents
 var Module = fx.Module("mcp",
 	fx.Provide(NewService),
+	fx.Provide(NewNotifier),
+	fx.Provide(newSessionStore),
 	fx.Provide(NewHandler),
 	fx.Provide(NewSSEHandler),
 	fx.Provide(NewStreamableHTTPHandler),
 	fx.Invoke(RegisterRoutes),
+	fx.Invoke(registerNotifier),
+	fx.Invoke(registerSessionStore),
+	fx.Invoke(registerToolTasks),
 )
+
+// registerNotifier starts delivering subscription notifications on startup
+// and stops on shutdown. List changes go to every session in the store.
+func registerNotifier(lc fx.Lifecycle, n *Notifier, store SessionStore) {
+	n.SetSessionStore(store)
+	lc.Append(fx.Hook{
+		OnStart: n.Start,
+		OnStop:  n.Stop,
+	})
+}
+
+// registerSessionStore starts session expiry and cross-replica event delivery
+// on startup and stops them on shutdown.
+func registerSessionStore(lc fx.Lifecycle, store SessionStore) {
+	lc.Append(fx.Hook{
+		OnStart: store.Start,
+		OnStop:  store.Stop,
+	})
+}
+
+// registerToolTasks fails tasks abandoned by stopped replicas on startup and
+// stops the tasks running here on shutdown.
+func registerToolTasks(lc fx.Lifecycle, svc *Service) {
+	lc.Append(fx.Hook{
+		OnStart: svc.tasks.Start,
+		OnStop:  svc.tasks.Stop,
+	})
+}
@@ -0,0 +1,394 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/domain/events"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	// notifierPollInterval is how often the notifier reads new entity events
+	// from the outbox while sessions hold subscriptions.
+	notifierPollInterval = 2 * time.Second
+	// notifierBatchSize caps the outbox events read per poll.
+	notifierBatchSize = 500
+
+	entityResourcePrefix   = "memory://entities/"
+	documentResourcePrefix = "memory://documents/"
+)
+
+// errNoNotificationStream is returned when subscribing from a session that
+// has no server-sent event stream to deliver updates on.
+var errNoNotificationStream = errors.New("resources/subscribe requires a Streamable HTTP or SSE session")
+
+// notificationSink delivers a notification to a session's event stream.
+type notificationSink func(n *Notification) error
+
+// notifierSession is a connected session that can receive notifications.
+type notifierSession struct {
+	projectID string
+	sink      notificationSink
+	uris      map[string]bool
+}
+
+// outboxPosition is a read position in kb.entity_event_outbox.
+type outboxPosition struct {
+	TxID int64 `bun:"tx_id"`
+	ID   int64 `bun:"id"`
+}
+
+// outboxChange is the part of an outbox event the notifier needs.
+type outboxChange struct {
+	outboxPosition
+	ProjectID   string `bun:"project_id"`
+	EntityID    string `bun:"entity_id"`
+	CanonicalID string `bun:"canonical_id"`
+}
+
+// Notifier pushes server notifications to connected MCP sessions:
+// notifications/resources/updated when a subscribed entity or document
+// changes, and notifications/tools/list_changed when a project's tool set
+// changes. Entity changes are read from the entity event outbox, so writes
+// made on any replica reach the sessions connected to this one. Document
+// changes arrive on the in-process events bus. List changes are sent to every
+// live session of the project in the session store, wherever it is served.
+type Notifier struct {
+	db     bun.IDB
+	events *events.Service
+	store  SessionStore
+	log    *slog.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*notifierSession // session ID -> session
+
+	// cursor is the outbox position up to which events were handled. It is
+	// reset while nothing is subscribed, so polling resumes from the head.
+	cursor *outboxPosition
+
+	unsubscribe func()
+	stopCh      chan struct{}
+	stopped     chan struct{}
+}
+
+// NewNotifier creates a new Notifier.
+func NewNotifier(db bun.IDB, eventsSvc *events.Service, log *slog.Logger) *Notifier {
+	return &Notifier{
+		db:       db,
+		events:   eventsSvc,
+		log:      log.With(logger.Scope("mcp.notifier")),
+		sessions: make(map[string]*notifierSession),
+	}
+}
+
+// SetSessionStore sets the store whose sessions receive list changes made on
+// this replica, including sessions that never reached it.
+func (n *Notifier) SetSessionStore(store SessionStore) {
+	n.store = store
+}
+
+// Start subscribes to the events bus and begins polling the outbox.
+func (n *Notifier) Start(ctx context.Context) error {
+	if n.events != nil {
+		n.unsubscribe = n.events.Subscribe("*", n.onEntityEvent)
+	}
+	if n.db == nil {
+		return nil
+	}
+	n.stopCh = make(chan struct{})
+	n.stopped = make(chan struct{})
+	go n.run(context.WithoutCancel(ctx))
+	return nil
+}
+
+// Stop ends polling and unsubscribes from the events bus.
+func (n *Notifier) Stop(ctx context.Context) error {
+	if n.unsubscribe != nil {
+		n.unsubscribe()
+	}
+	if n.stopCh == nil {
+		return nil
+	}
+	close(n.stopCh)
+	select {
+	case <-n.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (n *Notifier) run(ctx context.Context) {
+	defer close(n.stopped)
+	ticker := time.NewTicker(notifierPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.stopCh:
+			return
+		case <-ticker.C:
+			if err := n.poll(ctx); err != nil {
+				n.log.Warn("failed to read entity events for MCP subscriptions", logger.Error(err))
+			}
+		}
+	}
+}
+
+// Register makes a session eligible for notifications. Registering a session
+// again replaces its sink and project but keeps its subscriptions.
+func (n *Notifier) Register(sessionID, projectID string, sink notificationSink) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if s, ok := n.sessions[sessionID]; ok {
+		s.projectID = projectID
+		s.sink = sink
+		return
+	}
+	n.sessions[sessionID] = &notifierSession{projectID: projectID, sink: sink, uris: make(map[string]bool)}
+}
+
+// Unregister drops a session and its subscriptions.
+func (n *Notifier) Unregister(sessionID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.sessions, sessionID)
+}
+
+// Subscribe records a session's subscription to a resource URI.
+func (n *Notifier) Subscribe(sessionID, uri string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s, ok := n.sessions[sessionID]
+	if !ok {
+		return errNoNotificationStream
+	}
+	s.uris[uri] = true
+	return nil
+}
+
+// Unsubscribe removes a session's subscription to a resource URI.
+func (n *Notifier) Unsubscribe(sessionID, uri string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if s, ok := n.sessions[sessionID]; ok {
+		delete(s.uris, uri)
+	}
+}
+
+// ResourceUpdated notifies the project's sessions subscribed to any of uris.
+func (n *Notifier) ResourceUpdated(projectID string, uris ...string) {
+	n.notify(func(s *notifierSession) (*Notification, bool) {
+		if s.projectID != projectID {
+			return nil, false
+		}
+		for _, uri := range uris {
+			if s.uris[uri] {
+				return NewNotification("notifications/resources/updated", ResourceUpdatedParams{URI: uri}), true
+			}
+		}
+		return nil, false
+	})
+}
+
+// ToolsChanged tells the project's sessions to refetch tools/list. An empty
+// projectID notifies every session.
+func (n *Notifier) ToolsChanged(projectID string) {
+	n.listChanged(projectID, "notifications/tools/list_changed")
+}
+
+// PromptsChanged tells the project's sessions to refetch prompts/list. An
+// empty projectID notifies every session.
+func (n *Notifier) PromptsChanged(projectID string) {
+	n.listChanged(projectID, "notifications/prompts/list_changed")
+}
+
+// listChanged sends a list change notification to the project's sessions
+// registered here, then appends it to the project's sessions in the session
+// store that are not, so sessions served only by other replicas get it too.
+func (n *Notifier) listChanged(projectID, method string) {
+	n.mu.Lock()
+	registered := make(map[string]bool, len(n.sessions))
+	for id := range n.sessions {
+		registered[id] = true
+	}
+	n.mu.Unlock()
+
+	n.notify(func(s *notifierSession) (*Notification, bool) {
+		if projectID != "" && s.projectID != projectID {
+			return nil, false
+		}
+		return NewNotification(method, nil), true
+	})
+
+	if n.store == nil {
+		return
+	}
+	ctx := context.Background()
+	ids, err := n.store.ListSessions(ctx, projectID)
+	if err != nil {
+		n.log.Warn("failed to list MCP sessions for notification",
+			slog.String("project_id", projectID),
+			slog.String("method", method),
+			logger.Error(err))
+		return
+	}
+	data, err := json.Marshal(NewNotification(method, nil))
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		if registered[id] {
+			continue
+		}
+		_, err := n.store.AppendEvent(ctx, id, data)
+		if err != nil && !errors.Is(err, errSessionNotFound) {
+			n.log.Warn("failed to send MCP notification",
+				slog.String("session_id", id),
+				slog.String("method", method),
+				logger.Error(err))
+		}
+	}
+}
+
+// notify sends each session the notification chosen by pick. Sinks are
+// called outside the lock, since they write to network streams.
+func (n *Notifier) notify(pick func(s *notifierSession) (*Notification, bool)) {
+	type delivery struct {
+		sessionID string
+		sink      notificationSink
+		msg       *Notification
+	}
+
+	n.mu.Lock()
+	var deliveries []delivery
+	for id, s := range n.sessions {
+		if msg, ok := pick(s); ok {
+			deliveries = append(deliveries, delivery{sessionID: id, sink: s.sink, msg: msg})
+		}
+	}
+	n.mu.Unlock()
+
+	for _, d := range deliveries {
+		err := d.sink(d.msg)
+		if errors.Is(err, errSessionNotFound) {
+			// The session was terminated or expired, possibly on another replica
+			n.Unregister(d.sessionID)
+			continue
+		}
+		if err != nil {
+			n.log.Warn("failed to send MCP notification",
+				slog.String("session_id", d.sessionID),
+				slog.String("method", d.msg.Method),
+				logger.Error(err))
+		}
+	}
+}
+
+// subscribedProjects returns the projects with at least one subscription.
+func (n *Notifier) subscribedProjects() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	seen := make(map[string]bool)
+	var projects []string
+	for _, s := range n.sessions {
+		if len(s.uris) > 0 && s.projectID != "" && !seen[s.projectID] {
+			seen[s.projectID] = true
+			projects = append(projects, s.projectID)
+		}
+	}
+	return projects
+}
+
+// poll reads graph object events committed since the last poll and notifies
+// subscribers of the changed entities. Like the reaction agent dispatcher it
+// orders by (tx_id, id) and stops at the oldest running transaction, so
+// events from transactions that commit out of order are not skipped.
+func (n *Notifier) poll(ctx context.Context) error {
+	projects := n.subscribedProjects()
+	if len(projects) == 0 {
+		n.cursor = nil
+		return nil
+	}
+
+	if n.cursor == nil {
+		var head outboxPosition
+		err := n.db.NewRaw(`
+			SELECT COALESCE(last.tx_id, 0) AS tx_id, COALESCE(last.id, 0) AS id
+			FROM (SELECT 1) AS one
+			LEFT JOIN LATERAL (
+				SELECT tx_id, id FROM kb.entity_event_outbox
+				WHERE tx_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+				ORDER BY tx_id DESC, id DESC
+				LIMIT 1
+			) AS last ON true`).Scan(ctx, &head)
+		if err != nil {
+			return err
+		}
+		n.cursor = &head
+		return nil
+	}
+
+	var changes []outboxChange
+	err := n.db.NewRaw(`
+		SELECT tx_id, id, project_id, entity_id, COALESCE(data->>'canonicalId', '') AS canonical_id
+		FROM kb.entity_event_outbox
+		WHERE project_id IN (?) AND entity = ?
+		AND (tx_id, id) > (?, ?)
+		AND tx_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+		ORDER BY tx_id, id
+		LIMIT ?`,
+		bun.In(projects), events.EntityGraphObject, n.cursor.TxID, n.cursor.ID, notifierBatchSize).Scan(ctx, &changes)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		n.ResourceUpdated(c.ProjectID, entityResourceURIs(c.EntityID, c.CanonicalID)...)
+		n.cursor = &outboxPosition{TxID: c.TxID, ID: c.ID}
+	}
+	return nil
+}
+
+// onEntityEvent notifies subscribers of documents changed on the events bus.
+func (n *Notifier) onEntityEvent(evt events.EntityEvent) {
+	if evt.Entity != events.EntityDocument {
+		return
+	}
+	ids := evt.IDs
+	if evt.ID != nil {
+		ids = append(ids, *evt.ID)
+	}
+	for _, id := range ids {
+		n.ResourceUpdated(evt.ProjectID, documentResourcePrefix+id)
+	}
+}
+
+// entityResourceURIs returns the URIs an entity version can be subscribed
+// under: its version ID and, when different, its canonical ID.
+func entityResourceURIs(id, canonicalID string) []string {
+	uris := []string{entityResourcePrefix + id}
+	if canonicalID != "" && canonicalID != id {
+		uris = append(uris, entityResourcePrefix+canonicalID)
+	}
+	return uris
+}
+
+// subscribableResource reports whether uri names a single entity or document,
+// the resources that support resources/subscribe.
+func subscribableResource(uri string) bool {
+	for _, prefix := range []string{entityResourcePrefix, documentResourcePrefix} {
+		if id, ok := strings.CutPrefix(uri, prefix); ok && id != "" && !strings.Contains(id, "/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/emergent-company/emergent.memory/domain/events"
+)
+
+// recordingSink collects the notifications sent to a session.
+type recordingSink struct {
+	mu   sync.Mutex
+	msgs []*Notification
+}
+
+func (r *recordingSink) send(n *Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, n)
+	return nil
+}
+
+func (r *recordingSink) methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var methods []string
+	for _, m := range r.msgs {
+		methods = append(methods, m.Method)
+	}
+	return methods
+}
+
+func TestNotifier_ResourceUpdated(t *testing.T) {
+	n := NewNotifier(nil, nil, slog.Default())
+	var subscriber, sameProject, otherProject recordingSink
+	n.Register("s1", "proj-1", subscriber.send)
+	n.Register("s2", "proj-1", sameProject.send)
+	n.Register("s3", "proj-2", otherProject.send)
+
+	canonical := entityResourcePrefix + "canonical-1"
+	require.NoError(t, n.Subscribe("s1", canonical))
+	require.NoError(t, n.Subscribe("s3", canonical))
+	assert.ErrorIs(t, n.Subscribe("unknown", canonical), errNoNotificationStream)
+
+	// A new version of the entity is reported under its canonical ID too
+	n.ResourceUpdated("proj-1", entityResourceURIs("version-2", "canonical-1")...)
+
+	require.Len(t, subscriber.msgs, 1)
+	assert.Equal(t, "notifications/resources/updated", subscriber.msgs[0].Method)
+	assert.Equal(t, ResourceUpdatedParams{URI: canonical}, subscriber.msgs[0].Params)
+	assert.Empty(t, sameProject.msgs, "not subscribed")
+	assert.Empty(t, otherProject.msgs, "subscribed in another project")
+
+	n.Unsubscribe("s1", canonical)
+	n.ResourceUpdated("proj-1", canonical)
+	assert.Len(t, subscriber.msgs, 1)
+}
+
+func TestNotifier_ToolsChanged(t *testing.T) {
+	n := NewNotifier(nil, nil, slog.Default())
+	var a, b recordingSink
+	n.Register("s1", "proj-1", a.send)
+	n.Register("s2", "proj-2", b.send)
+
+	n.ToolsChanged("proj-1")
+	assert.Equal(t, []string{"notifications/tools/list_changed"}, a.methods())
+	assert.Empty(t, b.methods())
+
+	n.ToolsChanged("")
+	assert.Len(t, a.methods(), 2)
+	assert.Len(t, b.methods(), 1)
+
+	n.Unregister("s1")
+	n.ToolsChanged("")
+	assert.Len(t, a.methods(), 2)
+}
+
+// Sessions served only by other replicas are not registered with this
+// notifier; list changes reach them through the session store.
+func TestNotifier_ListChangedReachesStoreSessions(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySessionStore(10, time.Hour)
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "local", ProjectID: "proj-1", Initialized: true}))
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "remote", ProjectID: "proj-1", Initialized: true}))
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "other", ProjectID: "proj-2", Initialized: true}))
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "pending", ProjectID: "proj-1"}))
+
+	n := NewNotifier(nil, nil, slog.Default())
+	n.SetSessionStore(store)
+	var local recordingSink
+	n.Register("local", "proj-1", local.send)
+
+	n.PromptsChanged("proj-1")
+
+	assert.Equal(t, []string{"notifications/prompts/list_changed"}, local.methods())
+	local.msgs = nil
+	events, err := store.EventsSince(ctx, "local", -1)
+	require.NoError(t, err)
+	assert.Empty(t, events, "registered sessions are notified once, through their sink")
+
+	events, err = store.EventsSince(ctx, "remote", -1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/prompts/list_changed"}`, string(events[0].Data))
+
+	for _, id := range []string{"other", "pending"} {
+		events, err := store.EventsSince(ctx, id, -1)
+		require.NoError(t, err)
+		assert.Empty(t, events, id)
+	}
+
+	n.ToolsChanged("")
+	assert.Len(t, local.methods(), 1)
+	events, err = store.EventsSince(ctx, "other", -1)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestNotifier_DocumentEvents(t *testing.T) {
+	n := NewNotifier(nil, nil, slog.Default())
+	var sink recordingSink
+	n.Register("s1", "proj-1", sink.send)
+	require.NoError(t, n.Subscribe("s1", documentResourcePrefix+"doc-1"))
+
+	id := "doc-1"
+	n.onEntityEvent(events.EntityEvent{Type: events.EventTypeCreated, Entity: events.EntityChunk, ID: &id, ProjectID: "proj-1"})
+	assert.Empty(t, sink.methods(), "only document events map to document resources")
+
+	n.onEntityEvent(events.EntityEvent{Type: events.EventTypeDeleted, Entity: events.EntityDocument, ID: &id, ProjectID: "proj-1"})
+	n.onEntityEvent(events.EntityEvent{Type: events.EventTypeBatch, Entity: events.EntityDocument, IDs: []string{"doc-0", "doc-1"}, ProjectID: "proj-1"})
+	assert.Len(t, sink.methods(), 2)
+}
+
+func TestSubscribableResource(t *testing.T) {
+	assert.True(t, subscribableResource("memory://entities/0b6f"))
+	assert.True(t, subscribableResource("memory://documents/0b6f"))
+	assert.False(t, subscribableResource("memory://entities/"))
+	assert.False(t, subscribableResource("memory://entities/0b6f/edges"))
+	assert.False(t, subscribableResource("memory://schema/entity-types"))
+}
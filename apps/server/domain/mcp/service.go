@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +36,15 @@ type Service struct {
 	// MCP registry tool handler (injected to break import cycle)
 	mcpRegistryToolHandler MCPRegistryToolHandler
 
+	// Document tool handler (injected to break import cycle)
+	documentToolHandler DocumentToolHandler
+
+	// Agent publisher (injected to break import cycle)
+	agentPublisher AgentPublisher
+
+	// Tool calls run as tasks, shared by every transport's dispatcher
+	tasks *toolTasks
+
 	// Brave Search API configuration
 	braveSearchAPIKey  string
 	braveSearchTimeout time.Duration
@@ -57,6 +68,7 @@ func NewService(db bun.IDB, graphService *graph.Service, searchSvc *search.Servi
 		braveSearchAPIKey:  cfg.BraveSearch.APIKey,
 		braveSearchTimeout: timeout,
 		log:                log.With(logger.Scope("mcp.svc")),
+		tasks:              newToolTasks(db, log.With(logger.Scope("mcp.tasks"))),
 	}
 }
 
@@ -70,6 +82,16 @@ func (s *Service) SetMCPRegistryToolHandler(h MCPRegistryToolHandler) {
 	s.mcpRegistryToolHandler = h
 }
 
+// SetDocumentToolHandler sets the document tool handler (called after construction to break circular init)
+func (s *Service) SetDocumentToolHandler(h DocumentToolHandler) {
+	s.documentToolHandler = h
+}
+
+// SetAgentPublisher sets the agent publisher (called after construction to break circular init)
+func (s *Service) SetAgentPublisher(p AgentPublisher) {
+	s.agentPublisher = p
+}
+
 // GetToolDefinitions returns all available MCP tools
 func (s *Service) GetToolDefinitions() []ToolDefinition {
 	tools := []ToolDefinition{
@@ -756,6 +778,18 @@ func (s *Service) GetToolDefinitions() []ToolDefinition {
 		tools = append(tools, s.mcpRegistryToolHandler.GetMCPRegistryToolDefinitions()...)
 	}
 
+	// Append document tool definitions if handler is available
+	if s.documentToolHandler != nil {
+		tools = append(tools, s.documentToolHandler.GetDocumentToolDefinitions()...)
+	}
+
+	for i := range tools {
+		if policy, ok := toolPolicies[tools[i].Name]; ok && tools[i].Annotations == nil {
+			tools[i].Annotations = policy.annotations()
+			tools[i].Execution = policy.execution()
+		}
+	}
+
 	return tools
 }
 
@@ -800,6 +834,25 @@ func (s *Service) GetResourceDefinitions() []ResourceDefinition {
 	}
 }
 
+// GetResourceTemplates returns the parameterized resources clients can read
+// by filling in the URI template.
+func (s *Service) GetResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{
+		{
+			URITemplate: "memory://entities/{id}",
+			Name:        "Entity",
+			Description: "A graph entity by ID or canonical ID, with its properties and labels",
+			MimeType:    "application/json",
+		},
+		{
+			URITemplate: "memory://documents/{id}",
+			Name:        "Document",
+			Description: "A document's metadata and extracted text content",
+			MimeType:    "application/json",
+		},
+	}
+}
+
 func (s *Service) GetPromptDefinitions() []PromptDefinition {
 	return []PromptDefinition{
 		{
@@ -880,6 +933,30 @@ func (s *Service) GetPromptDefinitions() []PromptDefinition {
 	}
 }
 
+// PromptsForProject returns the built-in prompts followed by the prompts the
+// project publishes for its agents. A published prompt never shadows a
+// built-in one.
+func (s *Service) PromptsForProject(ctx context.Context, projectID string) ([]PromptDefinition, error) {
+	prompts := s.GetPromptDefinitions()
+	if s.agentPublisher == nil {
+		return prompts, nil
+	}
+	if _, err := uuid.Parse(projectID); err != nil {
+		return prompts, nil
+	}
+	published, err := s.agentPublisher.PublishedAgentPrompts(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published agent prompts: %w", err)
+	}
+	builtin := len(prompts)
+	for _, p := range published {
+		if !slices.ContainsFunc(prompts[:builtin], func(b PromptDefinition) bool { return b.Name == p.Name }) {
+			prompts = append(prompts, p)
+		}
+	}
+	return prompts, nil
+}
+
 // ExecuteTool executes an MCP tool and returns the result
 func (s *Service) ExecuteTool(ctx context.Context, projectID string, toolName string, args map[string]any) (*ToolResult, error) {
 	switch toolName {
@@ -1018,7 +1095,16 @@ func (s *Service) ExecuteTool(ctx context.Context, projectID string, toolName st
 	case "inspect_mcp_server":
 		return s.delegateRegistryTool(ctx, projectID, toolName, args)
 
+	// Document and extraction tools
+	case "create_document", "list_documents", "get_document", "get_chunk_context",
+		"get_extraction_status", "start_extraction":
+		return s.delegateDocumentTool(ctx, projectID, toolName, args)
+
 	default:
+		// Tools published by the project's agent definitions
+		if s.agentPublisher != nil {
+			return s.agentPublisher.ExecutePublishedAgent(ctx, projectID, toolName, args)
+		}
 		return nil, fmt.Errorf("tool not found: %s", toolName)
 	}
 }
@@ -1875,6 +1961,7 @@ func (s *Service) executeTraverseGraph(ctx context.Context, projectID string, ar
 		QueryContext:      queryContext,
 	}
 
+	ClientSessionFromContext(ctx).Progress(0, 0, fmt.Sprintf("Traversing up to %d hops", maxDepth))
 	results, err := s.graphService.TraverseGraph(ctx, projectUUID, req)
 	if err != nil {
 		return nil, fmt.Errorf("traverse graph: %w", err)
@@ -2047,8 +2134,14 @@ func (s *Service) executeBatchCreateEntities(ctx context.Context, projectID stri
 	results := make([]batchResult, 0, len(entitiesRaw))
 	successCount := 0
 	failedCount := 0
+	client := ClientSessionFromContext(ctx)
 
 	for i, entityRaw := range entitiesRaw {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("batch create stopped after %d of %d entities: %w", i, len(entitiesRaw), context.Cause(ctx))
+		}
+		client.Progress(float64(i), float64(len(entitiesRaw)), "")
+
 		entityMap, ok := entityRaw.(map[string]any)
 		if !ok {
 			results = append(results, batchResult{
@@ -2121,6 +2214,7 @@ func (s *Service) executeBatchCreateEntities(ctx context.Context, projectID stri
 		})
 		successCount++
 	}
+	client.Progress(float64(len(entitiesRaw)), float64(len(entitiesRaw)), "")
 
 	return s.wrapResult(map[string]any{
 		"success": successCount,
@@ -2157,8 +2251,14 @@ func (s *Service) executeBatchCreateRelationships(ctx context.Context, projectID
 	results := make([]batchResult, 0, len(relationshipsRaw))
 	successCount := 0
 	failedCount := 0
+	client := ClientSessionFromContext(ctx)
 
 	for i, relRaw := range relationshipsRaw {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("batch create stopped after %d of %d relationships: %w", i, len(relationshipsRaw), context.Cause(ctx))
+		}
+		client.Progress(float64(i), float64(len(relationshipsRaw)), "")
+
 		relMap, ok := relRaw.(map[string]any)
 		if !ok {
 			results = append(results, batchResult{
@@ -2242,6 +2342,7 @@ func (s *Service) executeBatchCreateRelationships(ctx context.Context, projectID
 		})
 		successCount++
 	}
+	client.Progress(float64(len(relationshipsRaw)), float64(len(relationshipsRaw)), "")
 
 	return s.wrapResult(map[string]any{
 		"success": successCount,
@@ -2849,6 +2950,22 @@ func (s *Service) executeAssignTemplatePack(ctx context.Context, projectID strin
 		typesToInstall = filtered
 	}
 
+	// Clients that can elicit confirm the installation with their user first
+	if client := ClientSessionFromContext(ctx); client.SupportsElicitation() {
+		sort.Strings(typesToInstall)
+		confirmed, err := client.Confirm(ctx, fmt.Sprintf("Install template pack %s@%s into the project? It registers %d types: %s",
+			pack.Name, pack.Version, len(typesToInstall), strings.Join(typesToInstall, ", ")))
+		if err != nil {
+			return nil, fmt.Errorf("confirm template pack installation: %w", err)
+		}
+		if !confirmed {
+			return s.wrapResult(map[string]any{
+				"success": false,
+				"message": "Installation cancelled by the user",
+			})
+		}
+	}
+
 	var assignmentID string
 	var conflicts []TypeConflict
 
@@ -3499,6 +3616,10 @@ func (s *Service) ReadResource(ctx context.Context, projectID, uri string) (*Res
 		return s.readRecentEntitiesResource(ctx, projectID)
 	case strings.HasPrefix(uri, "memory://project/") && strings.Contains(uri, "/templates"):
 		return s.readProjectTemplatesResource(ctx, projectID)
+	case strings.HasPrefix(uri, "memory://entities/"):
+		return s.readEntityResource(ctx, projectID, uri)
+	case strings.HasPrefix(uri, "memory://documents/"):
+		return s.readDocumentResource(ctx, projectID, uri)
 	default:
 		return nil, fmt.Errorf("unknown resource URI: %s", uri)
 	}
@@ -3694,6 +3815,83 @@ func (s *Service) readProjectTemplatesResource(ctx context.Context, projectID st
 	}, nil
 }
 
+func (s *Service) readEntityResource(ctx context.Context, projectID, uri string) (*ResourceReadResult, error) {
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project_id: %w", err)
+	}
+	entityID, err := uuid.Parse(strings.TrimPrefix(uri, "memory://entities/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid entity id in %s", uri)
+	}
+
+	obj, err := s.graphService.GetByID(ctx, projectUUID, entityID, true)
+	if err != nil {
+		return nil, fmt.Errorf("get entity: %w", err)
+	}
+
+	jsonData, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceReadResult{
+		Contents: []ResourceContents{
+			{
+				URI:      uri,
+				MimeType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
+func (s *Service) readDocumentResource(ctx context.Context, projectID, uri string) (*ResourceReadResult, error) {
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project_id: %w", err)
+	}
+	documentID, err := uuid.Parse(strings.TrimPrefix(uri, "memory://documents/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid document id in %s", uri)
+	}
+
+	var doc struct {
+		ID               string    `bun:"id" json:"id"`
+		Filename         *string   `bun:"filename" json:"filename,omitempty"`
+		SourceURL        *string   `bun:"source_url" json:"source_url,omitempty"`
+		MimeType         *string   `bun:"mime_type" json:"mime_type,omitempty"`
+		ConversionStatus *string   `bun:"conversion_status" json:"conversion_status,omitempty"`
+		Content          *string   `bun:"content" json:"content,omitempty"`
+		CreatedAt        time.Time `bun:"created_at" json:"created_at"`
+		UpdatedAt        time.Time `bun:"updated_at" json:"updated_at"`
+	}
+	err = s.db.NewSelect().
+		Table("kb.documents").
+		Column("id", "filename", "source_url", "mime_type", "conversion_status", "content", "created_at", "updated_at").
+		Where("id = ?", documentID).
+		Where("project_id = ?", projectUUID).
+		Scan(ctx, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("get document: %w", err)
+	}
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceReadResult{
+		Contents: []ResourceContents{
+			{
+				URI:      uri,
+				MimeType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
 func (s *Service) GetPrompt(ctx context.Context, projectID, name string, arguments map[string]any) (*PromptGetResult, error) {
 	switch name {
 	case "explore_entity_type":
@@ -3707,6 +3905,12 @@ func (s *Service) GetPrompt(ctx context.Context, projectID, name string, argumen
 	case "find_related_entities":
 		return s.getFindRelatedEntitiesPrompt(arguments)
 	default:
+		// Prompts published by the project's agent definitions
+		if s.agentPublisher != nil {
+			if _, err := uuid.Parse(projectID); err == nil {
+				return s.agentPublisher.GetPublishedAgentPrompt(ctx, projectID, name, arguments)
+			}
+		}
 		return nil, fmt.Errorf("unknown prompt: %s", name)
 	}
 }
@@ -4236,6 +4440,30 @@ func (s *Service) delegateAgentTool(ctx context.Context, projectID, toolName str
 	}
 }
 
+// delegateDocumentTool dispatches document and extraction tool calls to the DocumentToolHandler.
+func (s *Service) delegateDocumentTool(ctx context.Context, projectID, toolName string, args map[string]any) (*ToolResult, error) {
+	if s.documentToolHandler == nil {
+		return nil, fmt.Errorf("document tools not available: handler not configured")
+	}
+
+	switch toolName {
+	case "create_document":
+		return s.documentToolHandler.ExecuteCreateDocument(ctx, projectID, args)
+	case "list_documents":
+		return s.documentToolHandler.ExecuteListDocuments(ctx, projectID, args)
+	case "get_document":
+		return s.documentToolHandler.ExecuteGetDocument(ctx, projectID, args)
+	case "get_chunk_context":
+		return s.documentToolHandler.ExecuteGetChunkContext(ctx, projectID, args)
+	case "get_extraction_status":
+		return s.documentToolHandler.ExecuteGetExtractionStatus(ctx, projectID, args)
+	case "start_extraction":
+		return s.documentToolHandler.ExecuteStartExtraction(ctx, projectID, args)
+	default:
+		return nil, fmt.Errorf("unknown document tool: %s", toolName)
+	}
+}
+
 // delegateRegistryTool dispatches MCP registry tool calls to the MCPRegistryToolHandler.
 func (s *Service) delegateRegistryTool(ctx context.Context, projectID, toolName string, args map[string]any) (*ToolResult, error) {
 	if s.mcpRegistryToolHandler == nil {
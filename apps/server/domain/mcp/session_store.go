@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/internal/config"
+)
+
+// sessionPurgeInterval is how often expired sessions are removed.
+const sessionPurgeInterval = 5 * time.Minute
+
+// errSessionNotFound is returned for sessions that were deleted or expired.
+var errSessionNotFound = errors.New("session_not_found")
+
+// EventHandler receives every event appended to a session, on every replica.
+// It is how a message reaches the replica holding the session's GET stream.
+type EventHandler func(sessionID string, event *SSEEvent)
+
+// ClientResponseSink receives clients' responses to server requests, on
+// every replica. It is how a response reaches the replica whose request is
+// waiting for it.
+type ClientResponseSink interface {
+	// Awaits reports whether the session's request with this ID waits on
+	// this replica.
+	Awaits(sessionID, requestID string) bool
+	// Resolve hands a response to the request waiting for it.
+	Resolve(sessionID, requestID string, data json.RawMessage)
+}
+
+// SessionStore keeps Streamable HTTP sessions and their event logs. Sessions
+// expire after a TTL without access. Events are numbered per session so a
+// client can resume its stream with Last-Event-ID on any replica.
+type SessionStore interface {
+	// SaveSession creates or updates a session and extends its TTL.
+	SaveSession(ctx context.Context, session *MCPSession) error
+	// GetSession returns a live session, or errSessionNotFound.
+	GetSession(ctx context.Context, sessionID string) (*MCPSession, error)
+	// TouchSession records access to a session and extends its TTL.
+	TouchSession(ctx context.Context, sessionID string) error
+	// DeleteSession removes a session and its events.
+	DeleteSession(ctx context.Context, sessionID string) error
+	// ListSessions returns the IDs of the project's live, initialized
+	// sessions. An empty projectID lists every live, initialized session.
+	ListSessions(ctx context.Context, projectID string) ([]string, error)
+
+	// AppendEvent stores a message for a session, assigns its event ID and
+	// hands it to the event handler of every replica.
+	AppendEvent(ctx context.Context, sessionID string, data json.RawMessage) (*SSEEvent, error)
+	// NextEventID allocates an event ID without storing an event, for
+	// priming a new stream.
+	NextEventID(ctx context.Context, sessionID string) (int64, error)
+	// EventsSince returns the stored events after lastEventID.
+	EventsSince(ctx context.Context, sessionID string, lastEventID int64) ([]*SSEEvent, error)
+
+	// OnEvent sets the handler receiving appended events.
+	OnEvent(handler EventHandler)
+
+	// DeliverResponse hands a client's response to a server request to the
+	// replica waiting for it.
+	DeliverResponse(ctx context.Context, sessionID, requestID string, data json.RawMessage) error
+	// OnResponse sets the sink receiving delivered responses.
+	OnResponse(sink ClientResponseSink)
+
+	// Start begins expiring sessions (and, for shared stores, listening for
+	// events appended and responses delivered on other replicas).
+	Start(ctx context.Context) error
+	// Stop ends background work.
+	Stop(ctx context.Context) error
+}
+
+// newSessionStore picks the session store configured by MCP_SESSION_STORE.
+func newSessionStore(cfg *config.Config, db bun.IDB, pool *pgxpool.Pool, log *slog.Logger) SessionStore {
+	backend := cfg.MCP.SessionStore
+	if backend == "" {
+		backend = "postgres"
+		if cfg.Standalone.IsEnabled() {
+			backend = "memory"
+		}
+	}
+	if backend == "memory" {
+		return NewMemorySessionStore(cfg.MCP.MaxEventsPerSession, cfg.MCP.SessionTTL)
+	}
+	return NewPostgresSessionStore(db, pool, cfg.MCP.MaxEventsPerSession, cfg.MCP.SessionTTL, log)
+}
+
+// MemorySessionStore keeps sessions in process. It suits standalone
+// deployments and a single replica; behind a load balancer without sticky
+// sessions use PostgresSessionStore.
+type MemorySessionStore struct {
+	ttl    time.Duration
+	events *EventStore
+
+	mu       sync.RWMutex
+	sessions map[string]*memorySession
+	handler  EventHandler
+	sink     ClientResponseSink
+
+	stopCh chan struct{}
+}
+
+type memorySession struct {
+	session   MCPSession
+	expiresAt time.Time
+}
+
+// NewMemorySessionStore creates an in-process session store keeping up to
+// maxEvents events per session.
+func NewMemorySessionStore(maxEvents int, ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		ttl:      ttl,
+		events:   NewEventStore(maxEvents),
+		sessions: make(map[string]*memorySession),
+	}
+}
+
+// SaveSession creates or updates a session and extends its TTL.
+func (m *MemorySessionStore) SaveSession(_ context.Context, session *MCPSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = &memorySession{session: *session, expiresAt: time.Now().Add(m.ttl)}
+	return nil
+}
+
+// GetSession returns a live session, or errSessionNotFound.
+func (m *MemorySessionStore) GetSession(_ context.Context, sessionID string) (*MCPSession, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[sessionID]
+	if !ok || time.Now().After(s.expiresAt) {
+		return nil, errSessionNotFound
+	}
+	session := s.session
+	return &session, nil
+}
+
+// TouchSession records access to a session and extends its TTL.
+func (m *MemorySessionStore) TouchSession(_ context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return errSessionNotFound
+	}
+	s.session.LastAccessAt = time.Now()
+	s.expiresAt = s.session.LastAccessAt.Add(m.ttl)
+	return nil
+}
+
+// DeleteSession removes a session and its events.
+func (m *MemorySessionStore) DeleteSession(_ context.Context, sessionID string) error {
+	m.mu.Lock()
+	delete(m.sessions, sessionID)
+	m.mu.Unlock()
+	m.events.ClearSession(sessionID)
+	return nil
+}
+
+// ListSessions returns the IDs of the project's live, initialized sessions.
+func (m *MemorySessionStore) ListSessions(_ context.Context, projectID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := time.Now()
+	var ids []string
+	for id, s := range m.sessions {
+		if !s.session.Initialized || now.After(s.expiresAt) {
+			continue
+		}
+		if projectID != "" && s.session.ProjectID != projectID {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// AppendEvent stores a message and hands it to the event handler.
+func (m *MemorySessionStore) AppendEvent(ctx context.Context, sessionID string, data json.RawMessage) (*SSEEvent, error) {
+	if _, err := m.GetSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	event := m.events.AddEvent(sessionID, data)
+
+	m.mu.RLock()
+	handler := m.handler
+	m.mu.RUnlock()
+	if handler != nil {
+		handler(sessionID, event)
+	}
+	return event, nil
+}
+
+// NextEventID allocates an event ID for priming a stream.
+func (m *MemorySessionStore) NextEventID(_ context.Context, sessionID string) (int64, error) {
+	return m.events.GetNextEventID(sessionID), nil
+}
+
+// EventsSince returns the stored events after lastEventID.
+func (m *MemorySessionStore) EventsSince(_ context.Context, sessionID string, lastEventID int64) ([]*SSEEvent, error) {
+	return m.events.GetEventsSince(sessionID, lastEventID), nil
+}
+
+// OnEvent sets the handler receiving appended events.
+func (m *MemorySessionStore) OnEvent(handler EventHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = handler
+}
+
+// DeliverResponse hands a response to the sink if its request waits here.
+func (m *MemorySessionStore) DeliverResponse(_ context.Context, sessionID, requestID string, data json.RawMessage) error {
+	m.mu.RLock()
+	sink := m.sink
+	m.mu.RUnlock()
+	if sink != nil && sink.Awaits(sessionID, requestID) {
+		sink.Resolve(sessionID, requestID, data)
+	}
+	return nil
+}
+
+// OnResponse sets the sink receiving delivered responses.
+func (m *MemorySessionStore) OnResponse(sink ClientResponseSink) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sink = sink
+}
+
+// Start begins expiring idle sessions.
+func (m *MemorySessionStore) Start(context.Context) error {
+	m.stopCh = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(sessionPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				m.purgeExpired(time.Now())
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends session expiry.
+func (m *MemorySessionStore) Stop(context.Context) error {
+	if m.stopCh != nil {
+		close(m.stopCh)
+	}
+	return nil
+}
+
+// purgeExpired removes sessions idle past their TTL.
+func (m *MemorySessionStore) purgeExpired(now time.Time) {
+	m.mu.Lock()
+	var expired []string
+	for id, s := range m.sessions {
+		if now.After(s.expiresAt) {
+			expired = append(expired, id)
+			delete(m.sessions, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range expired {
+		m.events.ClearSession(id)
+	}
+}
@@ -0,0 +1,531 @@
+package mcp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+const (
+	// sessionEventsChannel is the NOTIFY channel announcing appended events.
+	sessionEventsChannel = "mcp_session_events"
+	// sessionResponsesChannel is the NOTIFY channel relaying client responses
+	// to server requests.
+	sessionResponsesChannel = "mcp_session_responses"
+	// maxInlineEventBytes is the largest event or response sent inside a
+	// notification. Postgres caps payloads at 8000 bytes; larger ones are read
+	// back from their table by the receiving replica.
+	maxInlineEventBytes = 7000
+	// listenRetryDelay is the wait before re-establishing a lost LISTEN
+	// connection.
+	listenRetryDelay = 2 * time.Second
+)
+
+// mcpSessionRow is a session in kb.mcp_sessions.
+type mcpSessionRow struct {
+	bun.BaseModel `bun:"table:kb.mcp_sessions,alias:ms"`
+
+	ID              string    `bun:"id,pk"`
+	ProjectID       string    `bun:"project_id,nullzero"`
+	UserID          string    `bun:"user_id,nullzero"`
+	OrgID           string    `bun:"org_id,nullzero"`
+	Initialized     bool      `bun:"initialized,notnull"`
+	ProtocolVersion string    `bun:"protocol_version,notnull"`
+	CreatedAt       time.Time `bun:"created_at,notnull"`
+	LastAccessAt    time.Time `bun:"last_access_at,notnull"`
+	ExpiresAt       time.Time `bun:"expires_at,notnull"`
+
+	ClientCapabilities ClientCapabilities `bun:"client_capabilities,type:jsonb,notnull"`
+}
+
+// mcpSessionEventRow is an event in kb.mcp_session_events.
+type mcpSessionEventRow struct {
+	bun.BaseModel `bun:"table:kb.mcp_session_events,alias:mse"`
+
+	SessionID string          `bun:"session_id,pk"`
+	EventID   int64           `bun:"event_id,pk"`
+	EventType string          `bun:"event_type,notnull"`
+	Data      json.RawMessage `bun:"data,type:jsonb,notnull"`
+	CreatedAt time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// mcpSessionResponseRow is a client response in kb.mcp_session_responses.
+type mcpSessionResponseRow struct {
+	bun.BaseModel `bun:"table:kb.mcp_session_responses,alias:msr"`
+
+	SessionID string          `bun:"session_id,pk"`
+	RequestID string          `bun:"request_id,pk"`
+	Data      json.RawMessage `bun:"data,type:jsonb,notnull"`
+}
+
+// sessionResponseNotification is the payload of a sessionResponsesChannel
+// NOTIFY.
+type sessionResponseNotification struct {
+	SessionID string          `json:"sessionId"`
+	RequestID string          `json:"requestId"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// sessionEventNotification is the payload of a sessionEventsChannel NOTIFY.
+type sessionEventNotification struct {
+	SessionID string          `json:"sessionId"`
+	EventID   int64           `json:"eventId"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// PostgresSessionStore keeps sessions and their event logs in Postgres so any
+// replica can serve a session. Appended events are announced with NOTIFY;
+// every replica LISTENs and hands them to its event handler, which writes
+// them to the session's stream if that replica holds it. Events missed while
+// a LISTEN connection is re-established are recovered by clients resuming
+// with Last-Event-ID. Client responses to server requests are relayed the
+// same way to the replica whose request waits for them.
+type PostgresSessionStore struct {
+	db        bun.IDB
+	pool      *pgxpool.Pool
+	maxEvents int
+	ttl       time.Duration
+	log       *slog.Logger
+
+	mu      sync.RWMutex
+	handler EventHandler
+	sink    ClientResponseSink
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPostgresSessionStore creates a Postgres-backed session store keeping up
+// to maxEvents events per session. pool provides the LISTEN connection.
+func NewPostgresSessionStore(db bun.IDB, pool *pgxpool.Pool, maxEvents int, ttl time.Duration, log *slog.Logger) *PostgresSessionStore {
+	return &PostgresSessionStore{
+		db:        db,
+		pool:      pool,
+		maxEvents: maxEvents,
+		ttl:       ttl,
+		log:       log.With(logger.Scope("mcp.sessions")),
+	}
+}
+
+// SaveSession creates or updates a session and extends its TTL.
+func (p *PostgresSessionStore) SaveSession(ctx context.Context, session *MCPSession) error {
+	row := &mcpSessionRow{
+		ID:              session.ID,
+		ProjectID:       session.ProjectID,
+		UserID:          session.UserID,
+		OrgID:           session.OrgID,
+		Initialized:     session.Initialized,
+		ProtocolVersion: session.ProtocolVersion,
+		CreatedAt:       session.CreatedAt,
+		LastAccessAt:    session.LastAccessAt,
+		ExpiresAt:       time.Now().Add(p.ttl),
+
+		ClientCapabilities: session.ClientCapabilities,
+	}
+	_, err := p.db.NewInsert().Model(row).
+		On("CONFLICT (id) DO UPDATE").
+		Set("project_id = EXCLUDED.project_id").
+		Set("user_id = EXCLUDED.user_id").
+		Set("org_id = EXCLUDED.org_id").
+		Set("initialized = EXCLUDED.initialized").
+		Set("protocol_version = EXCLUDED.protocol_version").
+		Set("client_capabilities = EXCLUDED.client_capabilities").
+		Set("last_access_at = EXCLUDED.last_access_at").
+		Set("expires_at = EXCLUDED.expires_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("save mcp session: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns a live session, or errSessionNotFound.
+func (p *PostgresSessionStore) GetSession(ctx context.Context, sessionID string) (*MCPSession, error) {
+	var row mcpSessionRow
+	err := p.db.NewSelect().Model(&row).
+		Where("id = ?", sessionID).
+		Where("expires_at > now()").
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get mcp session: %w", err)
+	}
+	return &MCPSession{
+		ID:              row.ID,
+		ProjectID:       row.ProjectID,
+		UserID:          row.UserID,
+		OrgID:           row.OrgID,
+		Initialized:     row.Initialized,
+		ProtocolVersion: row.ProtocolVersion,
+		CreatedAt:       row.CreatedAt,
+		LastAccessAt:    row.LastAccessAt,
+
+		ClientCapabilities: row.ClientCapabilities,
+	}, nil
+}
+
+// TouchSession records access to a session and extends its TTL.
+func (p *PostgresSessionStore) TouchSession(ctx context.Context, sessionID string) error {
+	res, err := p.db.NewUpdate().Model((*mcpSessionRow)(nil)).
+		Set("last_access_at = now()").
+		Set("expires_at = now() + make_interval(secs => ?)", p.ttl.Seconds()).
+		Where("id = ?", sessionID).
+		Where("expires_at > now()").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("touch mcp session: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errSessionNotFound
+	}
+	return nil
+}
+
+// DeleteSession removes a session; its events are removed by cascade.
+func (p *PostgresSessionStore) DeleteSession(ctx context.Context, sessionID string) error {
+	_, err := p.db.NewDelete().Model((*mcpSessionRow)(nil)).
+		Where("id = ?", sessionID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete mcp session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns the IDs of the project's live, initialized sessions.
+func (p *PostgresSessionStore) ListSessions(ctx context.Context, projectID string) ([]string, error) {
+	var ids []string
+	q := p.db.NewSelect().Model((*mcpSessionRow)(nil)).
+		Column("id").
+		Where("initialized").
+		Where("expires_at > now()")
+	if projectID != "" {
+		q = q.Where("project_id = ?", projectID)
+	}
+	if err := q.Scan(ctx, &ids); err != nil {
+		return nil, fmt.Errorf("list mcp sessions: %w", err)
+	}
+	return ids, nil
+}
+
+// AppendEvent stores a message, prunes events beyond maxEvents and announces
+// it to every replica. The NOTIFY is sent on commit, so listeners can read the
+// event back when it is too large to inline.
+func (p *PostgresSessionStore) AppendEvent(ctx context.Context, sessionID string, data json.RawMessage) (*SSEEvent, error) {
+	var event *SSEEvent
+	err := p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		id, err := nextSessionEventID(ctx, tx, sessionID)
+		if err != nil {
+			return err
+		}
+
+		row := &mcpSessionEventRow{SessionID: sessionID, EventID: id, EventType: "message", Data: data}
+		if _, err := tx.NewInsert().Model(row).Returning("created_at").Exec(ctx); err != nil {
+			return fmt.Errorf("insert event: %w", err)
+		}
+
+		_, err = tx.NewDelete().Model((*mcpSessionEventRow)(nil)).
+			Where("session_id = ?", sessionID).
+			Where("event_id <= ?", id-int64(p.maxEvents)).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("prune events: %w", err)
+		}
+
+		msg := sessionEventNotification{SessionID: sessionID, EventID: id}
+		if len(data) <= maxInlineEventBytes {
+			msg.Data = data
+		}
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("marshal notification: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "SELECT pg_notify(?, ?)", sessionEventsChannel, string(payload)); err != nil {
+			return fmt.Errorf("notify: %w", err)
+		}
+
+		event = &SSEEvent{ID: id, EventType: row.EventType, Data: data, Timestamp: row.CreatedAt}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errSessionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("append mcp session event: %w", err)
+	}
+	return event, nil
+}
+
+// NextEventID allocates an event ID for priming a stream.
+func (p *PostgresSessionStore) NextEventID(ctx context.Context, sessionID string) (int64, error) {
+	return nextSessionEventID(ctx, p.db, sessionID)
+}
+
+// nextSessionEventID increments a live session's event counter and returns
+// the ID allocated.
+func nextSessionEventID(ctx context.Context, db bun.IDB, sessionID string) (int64, error) {
+	var id int64
+	err := db.NewRaw(`
+		UPDATE kb.mcp_sessions SET next_event_id = next_event_id + 1
+		WHERE id = ? AND expires_at > now()
+		RETURNING next_event_id - 1`, sessionID).Scan(ctx, &id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errSessionNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("allocate event id: %w", err)
+	}
+	return id, nil
+}
+
+// EventsSince returns the stored events after lastEventID.
+func (p *PostgresSessionStore) EventsSince(ctx context.Context, sessionID string, lastEventID int64) ([]*SSEEvent, error) {
+	var rows []mcpSessionEventRow
+	err := p.db.NewSelect().Model(&rows).
+		Where("session_id = ?", sessionID).
+		Where("event_id > ?", lastEventID).
+		Order("event_id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list mcp session events: %w", err)
+	}
+	events := make([]*SSEEvent, 0, len(rows))
+	for _, r := range rows {
+		events = append(events, &SSEEvent{ID: r.EventID, EventType: r.EventType, Data: r.Data, Timestamp: r.CreatedAt})
+	}
+	return events, nil
+}
+
+// OnEvent sets the handler receiving appended events.
+func (p *PostgresSessionStore) OnEvent(handler EventHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handler = handler
+}
+
+// DeliverResponse hands a response to the replica waiting for it: directly
+// when the request waits here, otherwise with a NOTIFY. Responses too large
+// to inline are stored for the waiting replica to read.
+func (p *PostgresSessionStore) DeliverResponse(ctx context.Context, sessionID, requestID string, data json.RawMessage) error {
+	p.mu.RLock()
+	sink := p.sink
+	p.mu.RUnlock()
+	if sink != nil && sink.Awaits(sessionID, requestID) {
+		sink.Resolve(sessionID, requestID, data)
+		return nil
+	}
+
+	msg := sessionResponseNotification{SessionID: sessionID, RequestID: requestID}
+	err := p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(data) <= maxInlineEventBytes {
+			msg.Data = data
+		} else {
+			row := &mcpSessionResponseRow{SessionID: sessionID, RequestID: requestID, Data: data}
+			if _, err := tx.NewInsert().Model(row).On("CONFLICT DO NOTHING").Exec(ctx); err != nil {
+				return fmt.Errorf("insert response: %w", err)
+			}
+		}
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("marshal notification: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "SELECT pg_notify(?, ?)", sessionResponsesChannel, string(payload)); err != nil {
+			return fmt.Errorf("notify: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("deliver mcp client response: %w", err)
+	}
+	return nil
+}
+
+// OnResponse sets the sink receiving delivered responses.
+func (p *PostgresSessionStore) OnResponse(sink ClientResponseSink) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sink = sink
+}
+
+// Start begins listening for appended events and expiring idle sessions.
+func (p *PostgresSessionStore) Start(ctx context.Context) error {
+	ctx, p.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	if p.pool != nil {
+		p.wg.Add(1)
+		go p.listen(ctx)
+	}
+	p.wg.Add(1)
+	go p.purgeLoop(ctx)
+	return nil
+}
+
+// Stop ends listening and session expiry.
+func (p *PostgresSessionStore) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// listen keeps a LISTEN connection open, re-establishing it when it fails.
+func (p *PostgresSessionStore) listen(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		err := p.listenOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		p.log.Warn("mcp session event listener disconnected", logger.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// listenOnce takes a connection out of the pool, LISTENs on it and delivers
+// notifications until the connection fails or ctx is cancelled.
+func (p *PostgresSessionStore) listenOnce(ctx context.Context) error {
+	pooled, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	// The listening connection must not return to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	for _, channel := range []string{sessionEventsChannel, sessionResponsesChannel} {
+		if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return fmt.Errorf("listen %s: %w", channel, err)
+		}
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if n.Channel == sessionResponsesChannel {
+			p.deliverResponse(ctx, n.Payload)
+			continue
+		}
+		p.deliver(ctx, n.Payload)
+	}
+}
+
+// deliver hands a notified event to the event handler, reading it back when
+// it was too large to inline.
+func (p *PostgresSessionStore) deliver(ctx context.Context, payload string) {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	var msg sessionEventNotification
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		p.log.Warn("invalid mcp session event notification", logger.Error(err))
+		return
+	}
+
+	event := &SSEEvent{ID: msg.EventID, EventType: "message", Data: msg.Data, Timestamp: time.Now()}
+	if len(msg.Data) == 0 {
+		events, err := p.EventsSince(ctx, msg.SessionID, msg.EventID-1)
+		if err != nil || len(events) == 0 || events[0].ID != msg.EventID {
+			p.log.Warn("failed to read notified mcp session event",
+				slog.String("session_id", msg.SessionID),
+				slog.Int64("event_id", msg.EventID),
+				logger.Error(err))
+			return
+		}
+		event = events[0]
+	}
+	handler(msg.SessionID, event)
+}
+
+// deliverResponse hands a relayed response to the sink if its request waits
+// on this replica, reading it back when it was too large to inline.
+func (p *PostgresSessionStore) deliverResponse(ctx context.Context, payload string) {
+	p.mu.RLock()
+	sink := p.sink
+	p.mu.RUnlock()
+	if sink == nil {
+		return
+	}
+
+	var msg sessionResponseNotification
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		p.log.Warn("invalid mcp client response notification", logger.Error(err))
+		return
+	}
+	if !sink.Awaits(msg.SessionID, msg.RequestID) {
+		return
+	}
+
+	if len(msg.Data) == 0 {
+		var row mcpSessionResponseRow
+		err := p.db.NewDelete().Model(&row).
+			Where("session_id = ?", msg.SessionID).
+			Where("request_id = ?", msg.RequestID).
+			Returning("data").
+			Scan(ctx)
+		if err != nil {
+			p.log.Warn("failed to read relayed mcp client response",
+				slog.String("session_id", msg.SessionID),
+				slog.String("request_id", msg.RequestID),
+				logger.Error(err))
+			return
+		}
+		msg.Data = row.Data
+	}
+	sink.Resolve(msg.SessionID, msg.RequestID, msg.Data)
+}
+
+// purgeLoop periodically deletes expired sessions with their events.
+func (p *PostgresSessionStore) purgeLoop(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(sessionPurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res, err := p.db.NewDelete().Model((*mcpSessionRow)(nil)).
+				Where("expires_at <= now()").
+				Exec(ctx)
+			if err != nil {
+				p.log.Warn("failed to purge expired mcp sessions", logger.Error(err))
+				continue
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				p.log.Debug("purged expired mcp sessions", slog.Int64("count", n))
+			}
+		}
+	}
+}
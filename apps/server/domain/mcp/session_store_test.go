@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySessionStore(2, time.Hour)
+
+	var delivered []int64
+	store.OnEvent(func(sessionID string, event *SSEEvent) {
+		assert.Equal(t, "s1", sessionID)
+		delivered = append(delivered, event.ID)
+	})
+
+	_, err := store.GetSession(ctx, "s1")
+	assert.ErrorIs(t, err, errSessionNotFound)
+	_, err = store.AppendEvent(ctx, "s1", json.RawMessage(`{}`))
+	assert.ErrorIs(t, err, errSessionNotFound, "events need a live session")
+
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "s1", ProjectID: "proj-1", Initialized: true}))
+	session, err := store.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "proj-1", session.ProjectID)
+
+	// The store hands out copies; callers must save changes
+	session.ProjectID = "changed"
+	session, _ = store.GetSession(ctx, "s1")
+	assert.Equal(t, "proj-1", session.ProjectID)
+
+	priming, err := store.NextEventID(ctx, "s1")
+	require.NoError(t, err)
+	for range 3 {
+		_, err := store.AppendEvent(ctx, "s1", json.RawMessage(`{"jsonrpc":"2.0"}`))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int64{priming + 1, priming + 2, priming + 3}, delivered)
+
+	events, err := store.EventsSince(ctx, "s1", priming)
+	require.NoError(t, err)
+	require.Len(t, events, 2, "only the newest events are kept")
+	assert.Equal(t, priming+2, events[0].ID)
+
+	require.NoError(t, store.DeleteSession(ctx, "s1"))
+	_, err = store.GetSession(ctx, "s1")
+	assert.ErrorIs(t, err, errSessionNotFound)
+	events, _ = store.EventsSince(ctx, "s1", -1)
+	assert.Empty(t, events)
+}
+
+func TestMemorySessionStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySessionStore(10, time.Minute)
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "s1"}))
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "s2"}))
+	store.sessions["s1"].expiresAt = time.Now().Add(-time.Second)
+
+	_, err := store.GetSession(ctx, "s1")
+	assert.ErrorIs(t, err, errSessionNotFound, "expired sessions are gone before they are purged")
+	assert.ErrorIs(t, store.TouchSession(ctx, "missing"), errSessionNotFound)
+	require.NoError(t, store.TouchSession(ctx, "s2"))
+
+	store.purgeExpired(time.Now())
+	assert.NotContains(t, store.sessions, "s1")
+	assert.Contains(t, store.sessions, "s2")
+}
+
+// Two handlers sharing a store behave like replicas behind a load balancer:
+// a session initialized on one is served by the other, and messages sent from
+// either reach the stream held by the other.
+func TestStreamableHTTPHandler_SharedSessionStore(t *testing.T) {
+	store := NewMemorySessionStore(100, time.Hour)
+	notifier := NewNotifier(nil, nil, slog.Default())
+	replicaA := NewStreamableHTTPHandler(&Service{}, notifier, store, slog.Default())
+	replicaB := NewStreamableHTTPHandler(&Service{}, notifier, store, slog.Default())
+
+	ctx := context.Background()
+	require.NoError(t, store.SaveSession(ctx, &MCPSession{ID: "s1", Initialized: true, LastAccessAt: time.Now()}))
+
+	session, err := replicaB.getOrCreateSession(ctx, "s1", nil, LatestProtocolVersion)
+	require.NoError(t, err)
+	assert.True(t, session.Initialized)
+
+	rec := httptest.NewRecorder()
+	stream := &SSEStream{ID: "stream-1", SessionID: "s1", Writer: rec, Flusher: rec, Done: make(chan struct{}), LastEventID: -1}
+	replicaB.streams["s1"] = []*SSEStream{stream}
+
+	require.NoError(t, replicaA.SendServerMessage("s1", NewNotification("notifications/tools/list_changed", nil)))
+	assert.Contains(t, rec.Body.String(), "id: 0\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}")
+	assert.Equal(t, int64(0), stream.LastEventID)
+
+	// Events the stream already sent while resuming are not repeated
+	replicaB.deliverEvent("s1", &SSEEvent{ID: 0, EventType: "message", Data: json.RawMessage(`{}`)})
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), "event: message"))
+
+	require.NoError(t, store.DeleteSession(ctx, "s1"))
+	assert.ErrorIs(t, replicaA.SendServerMessage("s1", NewNotification("ping", nil)), errSessionNotFound)
+}
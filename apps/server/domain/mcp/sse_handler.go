@@ -20,13 +20,17 @@ import (
 
 // SSEHandler handles MCP SSE transport
 type SSEHandler struct {
-	svc     *Service
-	handler *Handler
-	log     *slog.Logger
+	svc        *Service
+	dispatcher *dispatcher
+	notifier   *Notifier
+	log        *slog.Logger
 
 	// SSE sessions
 	sseSessions   map[string]*SSESession
 	sseSessionsMu sync.RWMutex
+
+	// Requests sent to clients, waiting for their responses
+	requests *clientRequests
 }
 
 // SSESession represents an SSE connection session
@@ -35,18 +39,26 @@ type SSESession struct {
 	ProjectID   string
 	UserID      string
 	Initialized bool
-	Done        chan struct{}
-	Writer      http.ResponseWriter
-	Flusher     http.Flusher
+	// ClientCapabilities are the capabilities declared in initialize
+	ClientCapabilities ClientCapabilities
+	Done               chan struct{}
+	Writer             http.ResponseWriter
+	Flusher            http.Flusher
+
+	// writeMu serializes writes from responses, pings and notifications
+	writeMu sync.Mutex
 }
 
 // NewSSEHandler creates a new SSE handler
-func NewSSEHandler(svc *Service, handler *Handler, log *slog.Logger) *SSEHandler {
+func NewSSEHandler(svc *Service, notifier *Notifier, log *slog.Logger) *SSEHandler {
+	log = log.With(logger.Scope("mcp.sse"))
 	return &SSEHandler{
 		svc:         svc,
-		handler:     handler,
-		log:         log.With(logger.Scope("mcp.sse")),
+		dispatcher:  newDispatcher(svc, notifier, log),
+		notifier:    notifier,
+		log:         log,
 		sseSessions: make(map[string]*SSESession),
+		requests:    newClientRequests(),
 	}
 }
 
@@ -92,7 +104,17 @@ func (h *SSEHandler) HandleSSEConnect(c echo.Context) error {
 	h.sseSessions[sessionID] = session
 	h.sseSessionsMu.Unlock()
 
+	h.notifier.Register(sessionID, projectID, func(n *Notification) error {
+		data, err := json.Marshal(n)
+		if err != nil {
+			return fmt.Errorf("marshal notification: %w", err)
+		}
+		h.sendSSEEvent(session, "message", string(data))
+		return nil
+	})
+
 	defer func() {
+		h.notifier.Unregister(sessionID)
 		h.sseSessionsMu.Lock()
 		delete(h.sseSessions, sessionID)
 		h.sseSessionsMu.Unlock()
@@ -158,6 +180,21 @@ func (h *SSEHandler) HandleSSEMessage(c echo.Context) error {
 		return h.sendResponseToSession(c, sessionID, resp)
 	}
 
+	// Client responding to a server request
+	if req.IsResponse() {
+		data, _ := json.Marshal(&req)
+		h.requests.Resolve(sessionID, responseID(&req), data)
+		return c.NoContent(http.StatusAccepted)
+	}
+
+	// Client notifications get no response
+	if req.IsNotification() {
+		if req.Method == "notifications/cancelled" {
+			h.dispatcher.cancel(sessionID, &req)
+		}
+		return c.NoContent(http.StatusAccepted)
+	}
+
 	// Process request
 	response := h.processRequest(c, &req, projectID, user)
 
@@ -191,109 +228,37 @@ func (h *SSEHandler) processRequest(c echo.Context, req *Request, projectID stri
 	session, sessionExists := h.sseSessions[sessionID]
 	h.sseSessionsMu.RUnlock()
 
-	switch req.Method {
-	case "initialize":
+	if req.Method == "initialize" {
+		params, errResp := parseInitialize(req)
+		if errResp != nil {
+			return errResp
+		}
 		if sessionExists {
 			h.sseSessionsMu.Lock()
 			session.Initialized = true
+			session.ClientCapabilities = params.Capabilities
 			h.sseSessionsMu.Unlock()
 		}
-		return h.handleInitialize(req, projectID)
-
-	case "tools/list":
-		if !sessionExists || !session.Initialized {
-			return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
-				"Client must call initialize before tools/list",
-				map[string]string{"hint": "Call initialize method first to establish session"},
-			)
-		}
-		return h.handleToolsList(req)
-
-	case "tools/call":
-		if !sessionExists || !session.Initialized {
-			return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
-				"Client must call initialize before tools/call",
-				map[string]string{"hint": "Call initialize method first to establish session"},
-			)
-		}
-		return h.handleToolsCall(c, req, projectID, user)
-
-	default:
-		return h.handler.routeMethod(c, req, user)
-	}
-}
-
-// handleInitialize handles initialize for SSE transport
-func (h *SSEHandler) handleInitialize(req *Request, projectID string) *Response {
-	var params InitializeParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams, "Invalid params", nil)
-		}
-	}
-
-	if params.ProtocolVersion == "" || params.ClientInfo.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameters: protocolVersion, clientInfo", nil)
-	}
-
-	// Validate protocol version
-	valid := false
-	for _, v := range SupportedProtocolVersions {
-		if v == params.ProtocolVersion {
-			valid = true
-			break
-		}
-	}
-	if !valid {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Unsupported protocol version: "+params.ProtocolVersion,
-			map[string]any{"supported": SupportedProtocolVersions})
-	}
-
-	result := InitializeResult{
-		ProtocolVersion: params.ProtocolVersion,
-		Capabilities: ServerCapabilities{
-			Tools:     ToolsCapability{ListChanged: false},
-			Resources: ResourcesCapability{Subscribe: false, ListChanged: false},
-			Prompts:   PromptsCapability{ListChanged: false},
-		},
-		ServerInfo:     ServerInfo,
-		ProjectContext: map[string]string{"projectId": projectID},
-	}
-
-	return NewSuccessResponse(req.ID, result)
-}
-
-// handleToolsList handles tools/list for SSE transport
-func (h *SSEHandler) handleToolsList(req *Request) *Response {
-	tools := h.svc.GetToolDefinitions()
-	return NewSuccessResponse(req.ID, ToolsListResult{Tools: tools})
-}
-
-// handleToolsCall handles tools/call for SSE transport
-func (h *SSEHandler) handleToolsCall(c echo.Context, req *Request, projectID string, user *auth.AuthUser) *Response {
-	var params ToolsCallParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams, "Invalid params", nil)
-		}
-	}
-
-	if params.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams, "Missing required parameter: name", nil)
+		return NewSuccessResponse(req.ID, initializeResult(params.ProtocolVersion, projectID, true))
 	}
 
-	result, err := h.svc.ExecuteTool(c.Request().Context(), projectID, params.Name, params.Arguments)
-	if err != nil {
-		h.log.Error("tool execution failed",
-			slog.String("tool", params.Name),
-			logger.Error(err),
-		)
-		return NewErrorResponse(req.ID, ErrCodeInternalError, "Tool execution failed: "+err.Error(), nil)
+	cc := callContext{ProjectID: projectID}
+	if sessionExists {
+		h.sseSessionsMu.RLock()
+		cc.Initialized = session.Initialized
+		capabilities := session.ClientCapabilities
+		h.sseSessionsMu.RUnlock()
+		cc.SessionID = sessionID
+		cc.Client = h.requests.session(sessionID, capabilities, func(r *Request) error {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return fmt.Errorf("marshal request: %w", err)
+			}
+			h.sendSSEEvent(session, "message", string(data))
+			return nil
+		})
 	}
-
-	return NewSuccessResponse(req.ID, result)
+	return h.dispatcher.dispatch(c.Request().Context(), req, cc)
 }
 
 // sendSSEEvent sends an SSE event to a session
@@ -304,6 +269,8 @@ func (h *SSEHandler) sendSSEEvent(session *SSESession, event, data string) {
 	default:
 	}
 
+	session.writeMu.Lock()
+	defer session.writeMu.Unlock()
 	fmt.Fprintf(session.Writer, "event: %s\n", event)
 	fmt.Fprintf(session.Writer, "data: %s\n\n", data)
 	session.Flusher.Flush()
@@ -1,10 +1,13 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,22 +21,31 @@ import (
 	"github.com/emergent-company/emergent.memory/pkg/logger"
 )
 
+// sessionTouchInterval is how stale a session's last access may get before
+// a request extends its TTL in the session store.
+const sessionTouchInterval = time.Minute
+
+// errStreamClosed is returned for messages sent to a response stream after
+// the response was written.
+var errStreamClosed = errors.New("response stream closed")
+
 // StreamableHTTPHandler implements MCP Streamable HTTP transport (spec 2025-11-25)
 // Single endpoint that handles both POST and GET requests with SSE support
 type StreamableHTTPHandler struct {
-	svc *Service
-	log *slog.Logger
+	svc        *Service
+	dispatcher *dispatcher
+	notifier   *Notifier
+	log        *slog.Logger
 
-	// Session management (session ID -> session state)
-	sessions   map[string]*MCPSession
-	sessionsMu sync.RWMutex
+	// Sessions and their event logs, possibly shared with other replicas
+	store SessionStore
 
-	// SSE streams (session ID -> list of active streams)
+	// Requests sent to clients, waiting for their responses
+	requests *clientRequests
+
+	// SSE streams held by this replica (session ID -> list of active streams)
 	streams   map[string][]*SSEStream
 	streamsMu sync.RWMutex
-
-	// Event storage for resumability (Last-Event-ID support)
-	eventStore *EventStore
 }
 
 // MCPSession represents an MCP session state
@@ -46,6 +58,9 @@ type MCPSession struct {
 	ProtocolVersion string
 	CreatedAt       time.Time
 	LastAccessAt    time.Time
+
+	// ClientCapabilities are the capabilities declared in initialize
+	ClientCapabilities ClientCapabilities
 }
 
 // SSEStream represents an active SSE connection
@@ -57,17 +72,33 @@ type SSEStream struct {
 	Done        chan struct{}
 	LastEventID int64
 	CreatedAt   time.Time
+
+	// writeMu serializes writes from server messages and keepalives
+	writeMu sync.Mutex
+	// closeOnce guards Done, closed by the stream or by session termination
+	closeOnce sync.Once
+}
+
+// close ends the stream.
+func (s *SSEStream) close() {
+	s.closeOnce.Do(func() { close(s.Done) })
 }
 
 // NewStreamableHTTPHandler creates a new spec-compliant MCP handler
-func NewStreamableHTTPHandler(svc *Service, log *slog.Logger) *StreamableHTTPHandler {
-	return &StreamableHTTPHandler{
+func NewStreamableHTTPHandler(svc *Service, notifier *Notifier, store SessionStore, log *slog.Logger) *StreamableHTTPHandler {
+	log = log.With(logger.Scope("mcp.streamable"))
+	h := &StreamableHTTPHandler{
 		svc:        svc,
-		log:        log.With(logger.Scope("mcp.streamable")),
-		sessions:   make(map[string]*MCPSession),
+		dispatcher: newDispatcher(svc, notifier, log),
+		notifier:   notifier,
+		log:        log,
+		store:      store,
+		requests:   newClientRequests(),
 		streams:    make(map[string][]*SSEStream),
-		eventStore: NewEventStore(100),
 	}
+	store.OnEvent(h.deliverEvent)
+	store.OnResponse(responseSinks{h.requests, h.dispatcher.calls})
+	return h
 }
 
 // HandleUnifiedEndpoint handles both GET and POST requests on the MCP endpoint
@@ -153,9 +184,9 @@ func (h *StreamableHTTPHandler) handlePOST(c echo.Context, protocolVersion strin
 
 	// Get or validate session
 	sessionID := c.Request().Header.Get("Mcp-Session-Id")
-	session, err := h.getOrCreateSession(sessionID, user, protocolVersion)
+	session, err := h.getOrCreateSession(c.Request().Context(), sessionID, user, protocolVersion)
 	if err != nil {
-		if err.Error() == "session_not_found" {
+		if errors.Is(err, errSessionNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]any{
 				"error": map[string]string{
 					"code":    "session_not_found",
@@ -171,20 +202,33 @@ func (h *StreamableHTTPHandler) handlePOST(c echo.Context, protocolVersion strin
 		})
 	}
 
+	// Handle JSON-RPC response (client responding to a server request). The
+	// request may wait on another replica.
+	if req.IsResponse() {
+		data, _ := json.Marshal(&req)
+		if err := h.store.DeliverResponse(c.Request().Context(), session.ID, responseID(&req), data); err != nil {
+			h.log.Warn("failed to deliver client response",
+				slog.String("session_id", session.ID),
+				logger.Error(err),
+			)
+		}
+		return c.NoContent(http.StatusAccepted)
+	}
+
 	// Handle notification (no response expected)
 	if req.IsNotification() {
 		h.handleNotification(c, &req, session)
 		return c.NoContent(http.StatusAccepted)
 	}
 
-	// Handle JSON-RPC response (client responding to server request)
-	if req.ID == nil && req.Method == "" {
-		// This is a response, not a request
-		return c.NoContent(http.StatusAccepted)
+	// Tool calls stream their progress on the POST's own event stream when
+	// the client accepts one. Calls run as tasks return at once.
+	if req.Method == "tools/call" && supportsSSE && !isTaskCall(&req) {
+		return h.streamToolCall(c, &req, session, user)
 	}
 
 	// Handle JSON-RPC request
-	response := h.processRequest(c, &req, session, user)
+	response := h.processRequest(c, &req, session, user, nil)
 
 	// For initialize request, set session ID header
 	if req.Method == "initialize" && response.Error == nil {
@@ -225,8 +269,7 @@ func (h *StreamableHTTPHandler) handleGET(c echo.Context, protocolVersion string
 		})
 	}
 
-	session := h.getSession(sessionID)
-	if session == nil {
+	if _, err := h.store.GetSession(c.Request().Context(), sessionID); err != nil {
 		return c.JSON(http.StatusNotFound, map[string]any{
 			"error": map[string]string{
 				"code":    "session_not_found",
@@ -295,7 +338,7 @@ func (h *StreamableHTTPHandler) handleGET(c echo.Context, protocolVersion string
 			}
 		}
 		h.streamsMu.Unlock()
-		close(stream.Done)
+		stream.close()
 	}()
 
 	h.log.Info("SSE stream opened",
@@ -304,15 +347,29 @@ func (h *StreamableHTTPHandler) handleGET(c echo.Context, protocolVersion string
 		slog.Int64("from_event_id", stream.LastEventID),
 	)
 
-	// Send priming event (MCP spec requirement: establishes event ID sequence)
-	primingEventID := h.eventStore.GetNextEventID(sessionID)
+	// Send priming event (MCP spec requirement: establishes event ID sequence).
+	// Events delivered while priming and replaying wait for writeMu and are
+	// skipped if the replay already sent them.
+	ctx := c.Request().Context()
+	primingEventID, err := h.store.NextEventID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	stream.writeMu.Lock()
 	fmt.Fprintf(stream.Writer, "id: %d\ndata: \n\n", primingEventID)
 	stream.Flusher.Flush()
+	resumeFrom := stream.LastEventID
 	stream.LastEventID = primingEventID
 
 	// If resuming, replay missed events
 	if lastEventIDStr != "" {
-		missedEvents := h.eventStore.GetEventsSince(sessionID, stream.LastEventID-1)
+		missedEvents, err := h.store.EventsSince(ctx, sessionID, resumeFrom)
+		if err != nil {
+			h.log.Warn("failed to load events for stream resumption",
+				slog.String("session_id", sessionID),
+				logger.Error(err),
+			)
+		}
 		for _, event := range missedEvents {
 			fmt.Fprintf(stream.Writer, "event: %s\nid: %d\ndata: %s\n\n",
 				event.EventType, event.ID, event.Data)
@@ -326,12 +383,12 @@ func (h *StreamableHTTPHandler) handleGET(c echo.Context, protocolVersion string
 			)
 		}
 	}
+	stream.writeMu.Unlock()
 
 	// Keep connection alive with spec-compliant keepalive comments
 	ticker := time.NewTicker(4 * time.Hour)
 	defer ticker.Stop()
 
-	ctx := c.Request().Context()
 	for {
 		select {
 		case <-ctx.Done():
@@ -340,9 +397,17 @@ func (h *StreamableHTTPHandler) handleGET(c echo.Context, protocolVersion string
 				slog.String("stream_id", stream.ID),
 			)
 			return nil
+		case <-stream.Done:
+			h.log.Info("SSE stream closed (session terminated)",
+				slog.String("session_id", sessionID),
+				slog.String("stream_id", stream.ID),
+			)
+			return nil
 		case <-ticker.C:
+			stream.writeMu.Lock()
 			fmt.Fprintf(stream.Writer, ": keepalive\n\n")
 			stream.Flusher.Flush()
+			stream.writeMu.Unlock()
 		}
 	}
 }
@@ -359,41 +424,41 @@ func (h *StreamableHTTPHandler) handleDELETE(c echo.Context) error {
 		})
 	}
 
-	h.sessionsMu.Lock()
-	delete(h.sessions, sessionID)
-	h.sessionsMu.Unlock()
+	if err := h.store.DeleteSession(c.Request().Context(), sessionID); err != nil {
+		return err
+	}
+
+	h.notifier.Unregister(sessionID)
 
 	h.streamsMu.Lock()
 	streams := h.streams[sessionID]
 	for _, stream := range streams {
-		close(stream.Done)
+		stream.close()
 	}
 	delete(h.streams, sessionID)
 	h.streamsMu.Unlock()
 
-	h.eventStore.ClearSession(sessionID)
-
 	h.log.Info("session terminated", slog.String("session_id", sessionID))
 
 	return c.NoContent(http.StatusNoContent)
 }
 
 // getOrCreateSession gets existing session or creates new one
-func (h *StreamableHTTPHandler) getOrCreateSession(sessionID string, user *auth.AuthUser, protocolVersion string) (*MCPSession, error) {
+func (h *StreamableHTTPHandler) getOrCreateSession(ctx context.Context, sessionID string, user *auth.AuthUser, protocolVersion string) (*MCPSession, error) {
 	if sessionID != "" {
 		// Validate existing session
-		h.sessionsMu.RLock()
-		session, exists := h.sessions[sessionID]
-		h.sessionsMu.RUnlock()
-
-		if !exists {
-			return nil, fmt.Errorf("session_not_found")
+		session, err := h.store.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, err
 		}
 
-		// Update last access time
-		h.sessionsMu.Lock()
-		session.LastAccessAt = time.Now()
-		h.sessionsMu.Unlock()
+		// Update last access time, at most once per sessionTouchInterval
+		if time.Since(session.LastAccessAt) > sessionTouchInterval {
+			if err := h.store.TouchSession(ctx, sessionID); err != nil {
+				return nil, err
+			}
+			session.LastAccessAt = time.Now()
+		}
 
 		return session, nil
 	}
@@ -413,92 +478,125 @@ func (h *StreamableHTTPHandler) getOrCreateSession(sessionID string, user *auth.
 	return session, nil
 }
 
-// getSession retrieves an existing session
-func (h *StreamableHTTPHandler) getSession(sessionID string) *MCPSession {
-	h.sessionsMu.RLock()
-	defer h.sessionsMu.RUnlock()
-	return h.sessions[sessionID]
+// streamToolCall serves a tools/call with an SSE response. Progress
+// notifications and requests the tool sends the client are written ahead of
+// the call's response, so clients see them without holding a GET stream.
+// The client disconnecting cancels the call.
+func (h *StreamableHTTPHandler) streamToolCall(c echo.Context, req *Request, session *MCPSession, user *auth.AuthUser) error {
+	w := c.Response().Writer
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return c.JSON(http.StatusOK, h.processRequest(c, req, session, user, nil))
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Messages sent after the response would outlive the request
+	var mu sync.Mutex
+	closed := false
+	write := func(message any) error {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("marshal message: %w", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return errStreamClosed
+		}
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		flusher.Flush()
+		return nil
+	}
+
+	response := h.processRequest(c, req, session, user, func(r *Request) error { return write(r) })
+	if err := write(response); err != nil {
+		h.log.Warn("failed to write tool call response", slog.String("session_id", session.ID), logger.Error(err))
+	}
+	mu.Lock()
+	closed = true
+	mu.Unlock()
+	return nil
 }
 
-// processRequest processes JSON-RPC requests
-func (h *StreamableHTTPHandler) processRequest(c echo.Context, req *Request, session *MCPSession, user *auth.AuthUser) *Response {
-	switch req.Method {
-	case "initialize":
-		return h.handleInitialize(c, req, session)
-	case "tools/list":
-		return h.handleToolsList(c, req, session)
-	case "tools/call":
-		return h.handleToolsCall(c, req, session, user)
-	default:
-		return NewErrorResponse(
-			req.ID,
-			ErrCodeMethodNotFound,
-			"Method not found: "+req.Method,
-			map[string]any{
-				"method":            req.Method,
-				"supported_methods": []string{"initialize", "tools/list", "tools/call"},
-			},
-		)
+// isTaskCall reports whether a tools/call asks to run as a task.
+func isTaskCall(req *Request) bool {
+	var params struct {
+		Task *TaskMetadata `json:"task"`
 	}
+	return json.Unmarshal(req.Params, &params) == nil && params.Task != nil
 }
 
-// handleInitialize handles initialize method
-func (h *StreamableHTTPHandler) handleInitialize(c echo.Context, req *Request, session *MCPSession) *Response {
-	var params InitializeParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Invalid initialize params", map[string]string{"error": err.Error()})
-		}
+// processRequest processes JSON-RPC requests. Messages the server sends the
+// client while serving the request go to the session's GET stream, or to
+// send when set.
+func (h *StreamableHTTPHandler) processRequest(c echo.Context, req *Request, session *MCPSession, user *auth.AuthUser, send func(*Request) error) *Response {
+	if req.Method == "initialize" {
+		return h.handleInitialize(c, req, session, user)
 	}
 
-	// Validate required fields
-	if params.ProtocolVersion == "" || params.ClientInfo.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameters: protocolVersion, clientInfo",
-			map[string]any{
-				"required": []string{"protocolVersion", "clientInfo"},
-			},
-		)
+	projectID := session.ProjectID
+	if projectID == "" {
+		projectID = user.ProjectID
 	}
 
-	// Validate protocol version
-	if !h.isValidProtocolVersion(params.ProtocolVersion) {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Unsupported protocol version: "+params.ProtocolVersion,
-			map[string]any{
-				"requested": params.ProtocolVersion,
-				"supported": SupportedProtocolVersions,
-			},
-		)
+	if session.Initialized {
+		h.registerNotifications(session.ID, projectID)
+	}
+
+	if send == nil {
+		send = func(r *Request) error {
+			return h.SendServerMessage(session.ID, r)
+		}
+	}
+	return h.dispatcher.dispatch(c.Request().Context(), req, callContext{
+		ProjectID:   projectID,
+		Initialized: session.Initialized,
+		SessionID:   session.ID,
+		Client:      h.requests.session(session.ID, session.ClientCapabilities, send),
+	})
+}
+
+// registerNotifications lets the notifier push to a session from this
+// replica. Sessions move between replicas, so every replica serving a request
+// registers it; notifications go through the session store and reach the
+// replica holding the GET stream.
+func (h *StreamableHTTPHandler) registerNotifications(sessionID, projectID string) {
+	h.notifier.Register(sessionID, projectID, func(n *Notification) error {
+		return h.SendServerMessage(sessionID, n)
+	})
+}
+
+// handleInitialize handles initialize method
+func (h *StreamableHTTPHandler) handleInitialize(c echo.Context, req *Request, session *MCPSession, user *auth.AuthUser) *Response {
+	params, errResp := parseInitialize(req)
+	if errResp != nil {
+		return errResp
 	}
 
 	// Update session
 	session.Initialized = true
 	session.ProtocolVersion = params.ProtocolVersion
+	session.ClientCapabilities = params.Capabilities
 	if params.ProjectID != "" {
 		session.ProjectID = params.ProjectID
 	}
 
 	// Store session
-	h.sessionsMu.Lock()
-	h.sessions[session.ID] = session
-	h.sessionsMu.Unlock()
-
-	// Build response
-	result := InitializeResult{
-		ProtocolVersion: params.ProtocolVersion,
-		Capabilities: ServerCapabilities{
-			Tools:     ToolsCapability{ListChanged: false},
-			Resources: ResourcesCapability{Subscribe: false, ListChanged: false},
-			Prompts:   PromptsCapability{ListChanged: false},
-		},
-		ServerInfo: ServerInfo,
+	if err := h.store.SaveSession(c.Request().Context(), session); err != nil {
+		h.log.Error("failed to save MCP session", slog.String("session_id", session.ID), logger.Error(err))
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Failed to create session", nil)
 	}
 
-	if session.ProjectID != "" {
-		result.ProjectContext = map[string]string{"projectId": session.ProjectID}
+	projectID := session.ProjectID
+	if projectID == "" {
+		projectID = user.ProjectID
 	}
+	h.registerNotifications(session.ID, projectID)
 
 	h.log.Info("MCP session initialized",
 		slog.String("session_id", session.ID),
@@ -508,80 +606,32 @@ func (h *StreamableHTTPHandler) handleInitialize(c echo.Context, req *Request, s
 		slog.String("project_id", session.ProjectID),
 	)
 
-	return NewSuccessResponse(req.ID, result)
-}
-
-// handleToolsList handles tools/list method
-func (h *StreamableHTTPHandler) handleToolsList(c echo.Context, req *Request, session *MCPSession) *Response {
-	if !session.Initialized {
-		return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
-			"Client must call initialize before tools/list",
-			map[string]string{"hint": "Call initialize method first to establish session"},
-		)
-	}
-
-	tools := h.svc.GetToolDefinitions()
-	return NewSuccessResponse(req.ID, ToolsListResult{Tools: tools})
-}
-
-// handleToolsCall handles tools/call method
-func (h *StreamableHTTPHandler) handleToolsCall(c echo.Context, req *Request, session *MCPSession, user *auth.AuthUser) *Response {
-	if !session.Initialized {
-		return NewErrorResponse(req.ID, ErrCodeInvalidRequest,
-			"Client must call initialize before tools/call",
-			map[string]string{"hint": "Call initialize method first to establish session"},
-		)
-	}
-
-	var params ToolsCallParams
-	if len(req.Params) > 0 {
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-				"Invalid tools/call params", map[string]string{"error": err.Error()})
-		}
-	}
-
-	if params.Name == "" {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Missing required parameter: name",
-			map[string]any{"required": []string{"name"}},
-		)
-	}
-
-	projectID := session.ProjectID
-	if projectID == "" {
-		projectID = user.ProjectID
-	}
-
-	if projectID == "" && requiresProject(params.Name) {
-		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
-			"Project ID is required. Provide project_id in initialize params or X-Project-Id header.",
-			map[string]string{"hint": "Call initialize with project_id parameter or set X-Project-Id header"},
-		)
-	}
-
-	// Execute tool
-	result, err := h.svc.ExecuteTool(c.Request().Context(), projectID, params.Name, params.Arguments)
-	if err != nil {
-		h.log.Error("tool execution failed",
-			slog.String("tool", params.Name),
-			logger.Error(err),
-		)
-		return NewErrorResponse(req.ID, ErrCodeInternalError,
-			"Tool execution failed: "+err.Error(),
-			nil,
-		)
-	}
-
-	return NewSuccessResponse(req.ID, result)
+	return NewSuccessResponse(req.ID, initializeResult(params.ProtocolVersion, session.ProjectID, true))
 }
 
 // handleNotification handles JSON-RPC notifications
 func (h *StreamableHTTPHandler) handleNotification(c echo.Context, req *Request, session *MCPSession) {
 	switch req.Method {
 	case "notifications/initialized":
-		session.Initialized = true
+		if !session.Initialized {
+			session.Initialized = true
+			if err := h.store.SaveSession(c.Request().Context(), session); err != nil {
+				h.log.Warn("failed to save MCP session", slog.String("session_id", session.ID), logger.Error(err))
+			}
+		}
 		h.log.Debug("client sent initialized notification", slog.String("session_id", session.ID))
+	case "notifications/cancelled":
+		// The cancelled call may run on another replica
+		var params CancelledParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.RequestID) == 0 {
+			return
+		}
+		if err := h.store.DeliverResponse(c.Request().Context(), session.ID, cancelPrefix+cancelledRequestID(&params), req.Params); err != nil {
+			h.log.Warn("failed to relay cancellation",
+				slog.String("session_id", session.ID),
+				logger.Error(err),
+			)
+		}
 	default:
 		h.log.Debug("unknown notification", slog.String("method", req.Method))
 	}
@@ -597,30 +647,42 @@ func (h *StreamableHTTPHandler) isValidProtocolVersion(version string) bool {
 	return false
 }
 
-// SendServerMessage sends a JSON-RPC message to all active SSE streams for a session
-func (h *StreamableHTTPHandler) SendServerMessage(sessionID string, message *Response) error {
+// SendServerMessage appends a JSON-RPC message to a session's event log. The
+// session store hands it to deliverEvent on every replica, so it reaches the
+// session's streams wherever they are held.
+func (h *StreamableHTTPHandler) SendServerMessage(sessionID string, message any) error {
 	data, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("marshal message: %w", err)
 	}
 
-	eventID := h.eventStore.AddEvent(sessionID, data)
-
-	h.streamsMu.RLock()
-	streams := h.streams[sessionID]
-	h.streamsMu.RUnlock()
-
-	for _, stream := range streams {
-		fmt.Fprintf(stream.Writer, "event: message\nid: %d\ndata: %s\n\n", eventID, data)
-		stream.Flusher.Flush()
-		stream.LastEventID = eventID
+	event, err := h.store.AppendEvent(context.Background(), sessionID, data)
+	if err != nil {
+		return err
 	}
 
 	h.log.Debug("server message sent",
 		slog.String("session_id", sessionID),
-		slog.Int64("event_id", eventID),
-		slog.Int("stream_count", len(streams)),
+		slog.Int64("event_id", event.ID),
 	)
 
 	return nil
 }
+
+// deliverEvent writes an appended event to this replica's streams of the
+// session. Streams that already sent the event while resuming skip it.
+func (h *StreamableHTTPHandler) deliverEvent(sessionID string, event *SSEEvent) {
+	h.streamsMu.RLock()
+	streams := slices.Clone(h.streams[sessionID])
+	h.streamsMu.RUnlock()
+
+	for _, stream := range streams {
+		stream.writeMu.Lock()
+		if event.ID > stream.LastEventID {
+			fmt.Fprintf(stream.Writer, "event: %s\nid: %d\ndata: %s\n\n", event.EventType, event.ID, event.Data)
+			stream.Flusher.Flush()
+			stream.LastEventID = event.ID
+		}
+		stream.writeMu.Unlock()
+	}
+}
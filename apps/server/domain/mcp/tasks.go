@@ -0,0 +1,549 @@
+package mcp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// Task statuses
+const (
+	TaskStatusWorking   = "working"
+	TaskStatusCompleted = "completed"
+	TaskStatusFailed    = "failed"
+	TaskStatusCancelled = "cancelled"
+)
+
+const (
+	// defaultTaskTTL is how long a task is kept when the client asks for no TTL.
+	defaultTaskTTL = time.Hour
+	// maxTaskTTL caps the TTL a client may ask for.
+	maxTaskTTL = 24 * time.Hour
+	// taskPollInterval is how often clients are asked to poll a task, and how
+	// often the replica running a task checks whether it was cancelled.
+	taskPollInterval = 2 * time.Second
+	// taskResultPollInterval is how often tasks/result checks whether the
+	// task it waits for finished.
+	taskResultPollInterval = time.Second
+	// tasksPageSize is the number of tasks in a tasks/list page.
+	tasksPageSize = 50
+	// taskHeartbeatTimeout is how long a working task may go without a
+	// heartbeat from the replica running it before it is marked failed.
+	taskHeartbeatTimeout = 30 * time.Second
+)
+
+// relatedTaskMeta is the _meta key tying a message to its task.
+const relatedTaskMeta = "io.modelcontextprotocol/related-task"
+
+var (
+	// errTaskNotFound is returned for tasks that do not exist, expired or
+	// belong to another user.
+	errTaskNotFound = errors.New("task not found")
+	// errTaskFinished is returned when cancelling a task that already ended.
+	errTaskFinished = errors.New("task already finished")
+	// errTasksStopped is the cause of running tasks' cancellation when the
+	// server shuts down, and is returned for tasks started afterwards.
+	errTasksStopped = errors.New("server is shutting down")
+)
+
+// mcpTaskRow is a task in kb.mcp_tasks.
+type mcpTaskRow struct {
+	bun.BaseModel `bun:"table:kb.mcp_tasks,alias:mt"`
+
+	ID            string          `bun:"id,pk"`
+	ProjectID     string          `bun:"project_id,nullzero"`
+	UserID        string          `bun:"user_id,nullzero"`
+	SessionID     string          `bun:"session_id,nullzero"`
+	ToolName      string          `bun:"tool_name,notnull"`
+	Status        string          `bun:"status,notnull"`
+	StatusMessage string          `bun:"status_message,nullzero"`
+	Result        json.RawMessage `bun:"result,type:jsonb,nullzero"`
+	Error         *ErrorObject    `bun:"error,type:jsonb"`
+	TTLMillis     int64           `bun:"ttl_ms,notnull"`
+	CreatedAt     time.Time       `bun:"created_at,notnull"`
+	UpdatedAt     time.Time       `bun:"updated_at,notnull"`
+	ExpiresAt     time.Time       `bun:"expires_at,notnull"`
+	HeartbeatAt   time.Time       `bun:"heartbeat_at,notnull"`
+}
+
+// finished reports whether the task reached a terminal status.
+func (r *mcpTaskRow) finished() bool {
+	return r.Status == TaskStatusCompleted || r.Status == TaskStatusFailed || r.Status == TaskStatusCancelled
+}
+
+// task returns the task as sent to clients.
+func (r *mcpTaskRow) task() Task {
+	return Task{
+		TaskID:        r.ID,
+		Status:        r.Status,
+		StatusMessage: r.StatusMessage,
+		CreatedAt:     r.CreatedAt.UTC().Format(time.RFC3339Nano),
+		LastUpdatedAt: r.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		TTL:           r.TTLMillis,
+		PollInterval:  taskPollInterval.Milliseconds(),
+	}
+}
+
+// toolTasks runs tool calls as tasks. Task state lives in Postgres, so a
+// client can poll, read and cancel its tasks on any replica; the replica
+// running a task watches for its cancellation and keeps its heartbeat fresh.
+// Working tasks whose heartbeat stopped, because the replica running them
+// died, are marked failed. Tasks belong to the user who started them.
+type toolTasks struct {
+	db  bun.IDB
+	log *slog.Logger
+
+	mu       sync.Mutex
+	running  map[string]context.CancelCauseFunc // task ID -> cancel
+	stopping bool
+	wg       sync.WaitGroup
+
+	stopSweep context.CancelFunc
+	swept     chan struct{}
+}
+
+func newToolTasks(db bun.IDB, log *slog.Logger) *toolTasks {
+	return &toolTasks{db: db, log: log, running: make(map[string]context.CancelCauseFunc)}
+}
+
+// Start fails the tasks abandoned by replicas that died, then keeps doing so
+// while the server runs.
+func (t *toolTasks) Start(ctx context.Context) error {
+	if t.db == nil {
+		return nil
+	}
+	ctx, t.stopSweep = context.WithCancel(context.WithoutCancel(ctx))
+	t.swept = make(chan struct{})
+	go t.sweep(ctx)
+	return nil
+}
+
+// Stop cancels the tasks running on this replica, which are recorded as
+// failed, and waits for them to return.
+func (t *toolTasks) Stop(ctx context.Context) error {
+	t.mu.Lock()
+	t.stopping = true
+	for _, cancel := range t.running {
+		cancel(errTasksStopped)
+	}
+	t.mu.Unlock()
+
+	if t.stopSweep != nil {
+		t.stopSweep()
+		<-t.swept
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sweep periodically fails abandoned tasks until ctx is cancelled.
+func (t *toolTasks) sweep(ctx context.Context) {
+	defer close(t.swept)
+	ticker := time.NewTicker(taskHeartbeatTimeout)
+	defer ticker.Stop()
+	for {
+		if err := t.failAbandoned(ctx); err != nil && ctx.Err() == nil {
+			t.log.Warn("failed to fail abandoned mcp tasks", logger.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// failAbandoned marks failed the working tasks whose heartbeat is older than
+// taskHeartbeatTimeout.
+func (t *toolTasks) failAbandoned(ctx context.Context) error {
+	const message = "Task was interrupted: the server running it stopped"
+	res, err := t.db.NewUpdate().Model((*mcpTaskRow)(nil)).
+		Set("status = ?", TaskStatusFailed).
+		Set("status_message = ?", message).
+		Set("error = ?::jsonb", string(mustMarshal(&ErrorObject{Code: ErrCodeInternalError, Message: message}))).
+		Set("updated_at = now()").
+		Where("status = ?", TaskStatusWorking).
+		Where("heartbeat_at < now() - make_interval(secs => ?)", taskHeartbeatTimeout.Seconds()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("fail abandoned tasks: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		t.log.Warn("failed mcp tasks abandoned by a stopped server", slog.Int64("count", n))
+	}
+	return nil
+}
+
+// track registers a running task so Stop can cancel it. It fails once Stop
+// was called; done must be called when the task returns.
+func (t *toolTasks) track(id string, cancel context.CancelCauseFunc) (done func(), err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopping {
+		return nil, errTasksStopped
+	}
+	t.running[id] = cancel
+	t.wg.Add(1)
+	return func() {
+		t.mu.Lock()
+		delete(t.running, id)
+		t.mu.Unlock()
+		t.wg.Done()
+	}, nil
+}
+
+// taskOwner returns the ID of the user in ctx, who owns the tasks it starts.
+func taskOwner(ctx context.Context) string {
+	if user := auth.UserFromContext(ctx); user != nil {
+		return user.ID
+	}
+	return ""
+}
+
+// taskTTL returns the TTL of a task the client asked for with meta.
+func taskTTL(meta *TaskMetadata) time.Duration {
+	if meta == nil || meta.TTL == nil || *meta.TTL <= 0 {
+		return defaultTaskTTL
+	}
+	return min(time.Duration(*meta.TTL)*time.Millisecond, maxTaskTTL)
+}
+
+// start records a task for the tool call and runs it in the background.
+// The call keeps ctx's values, such as the caller and the client session,
+// but not its cancellation: the task outlives the request starting it.
+func (t *toolTasks) start(ctx context.Context, projectID, sessionID, toolName string, meta *TaskMetadata, run func(context.Context) (*ToolResult, error)) (Task, error) {
+	now := time.Now()
+	ttl := taskTTL(meta)
+	row := &mcpTaskRow{
+		ID:          uuid.NewString(),
+		ProjectID:   projectID,
+		UserID:      taskOwner(ctx),
+		SessionID:   sessionID,
+		ToolName:    toolName,
+		Status:      TaskStatusWorking,
+		TTLMillis:   ttl.Milliseconds(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+		HeartbeatAt: now,
+	}
+
+	// Expired tasks are purged as new ones start
+	if _, err := t.db.NewDelete().Model((*mcpTaskRow)(nil)).Where("expires_at <= now()").Exec(ctx); err != nil {
+		t.log.Warn("failed to purge expired mcp tasks", logger.Error(err))
+	}
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	done, err := t.track(row.ID, cancel)
+	if err != nil {
+		cancel(nil)
+		return Task{}, err
+	}
+	if _, err := t.db.NewInsert().Model(row).Exec(ctx); err != nil {
+		done()
+		cancel(nil)
+		return Task{}, fmt.Errorf("create task: %w", err)
+	}
+
+	go func() {
+		defer done()
+		t.run(runCtx, cancel, row, run)
+	}()
+	return row.task(), nil
+}
+
+// run executes a task's tool call and records its outcome.
+func (t *toolTasks) run(ctx context.Context, cancel context.CancelCauseFunc, row *mcpTaskRow, run func(context.Context) (*ToolResult, error)) {
+	defer cancel(nil)
+	go t.watch(ctx, cancel, row.ID)
+
+	result, err := run(ctx)
+
+	var cancelled *CancelledError
+	if errors.As(context.Cause(ctx), &cancelled) {
+		return
+	}
+	if errors.Is(context.Cause(ctx), errTasksStopped) {
+		result, err = nil, errTasksStopped
+	}
+
+	q := t.db.NewUpdate().Model((*mcpTaskRow)(nil)).
+		Set("updated_at = now()").
+		Where("id = ?", row.ID).
+		Where("status = ?", TaskStatusWorking)
+	status := TaskStatusCompleted
+	switch {
+	case err != nil:
+		status = TaskStatusFailed
+		q = q.Set("status_message = ?", err.Error()).
+			Set("error = ?::jsonb", string(mustMarshal(&ErrorObject{Code: ErrCodeInternalError, Message: "Tool execution failed: " + err.Error()})))
+	case result != nil && result.IsError:
+		status = TaskStatusFailed
+		q = q.Set("result = ?::jsonb", string(mustMarshal(result)))
+	default:
+		q = q.Set("result = ?::jsonb", string(mustMarshal(result)))
+	}
+	q = q.Set("status = ?", status)
+
+	if _, err := q.Exec(context.WithoutCancel(ctx)); err != nil {
+		t.log.Error("failed to record mcp task outcome",
+			slog.String("task_id", row.ID),
+			slog.String("tool", row.ToolName),
+			logger.Error(err))
+		return
+	}
+
+	row.Status = status
+	row.UpdatedAt = time.Now()
+	ClientSessionFromContext(ctx).notify("notifications/tasks/status", row.task())
+}
+
+// watch refreshes a running task's heartbeat and cancels its call once the
+// task is cancelled, which may happen on another replica.
+func (t *toolTasks) watch(ctx context.Context, cancel context.CancelCauseFunc, id string) {
+	ticker := time.NewTicker(taskPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var status string
+			err := t.db.NewUpdate().Model((*mcpTaskRow)(nil)).
+				Set("heartbeat_at = now()").
+				Where("id = ?", id).
+				Returning("status").
+				Scan(ctx, &status)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				if ctx.Err() == nil {
+					t.log.Warn("failed to check mcp task status", slog.String("task_id", id), logger.Error(err))
+				}
+				continue
+			}
+			// A task marked failed lost its heartbeat while the replica
+			// could not reach the database; its outcome is no longer wanted
+			if status == TaskStatusCancelled || status == TaskStatusFailed || errors.Is(err, sql.ErrNoRows) {
+				cancel(&CancelledError{Reason: "task cancelled"})
+				return
+			}
+		}
+	}
+}
+
+// get returns a live task of the caller in ctx.
+func (t *toolTasks) get(ctx context.Context, id string) (*mcpTaskRow, error) {
+	row := new(mcpTaskRow)
+	err := t.db.NewSelect().Model(row).
+		Where("id = ?", id).
+		Where("coalesce(user_id, '') = ?", taskOwner(ctx)).
+		Where("expires_at > now()").
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+	return row, nil
+}
+
+// wait returns the task once it finished, or ctx's error.
+func (t *toolTasks) wait(ctx context.Context, id string) (*mcpTaskRow, error) {
+	ticker := time.NewTicker(taskResultPollInterval)
+	defer ticker.Stop()
+	for {
+		row, err := t.get(ctx, id)
+		if err != nil || row.finished() {
+			return row, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// list returns a page of the caller's tasks in the project, newest first.
+// The cursor is the offset of the page.
+func (t *toolTasks) list(ctx context.Context, projectID, cursor string) (*TasksListResult, error) {
+	offset, _ := strconv.Atoi(cursor)
+	var rows []*mcpTaskRow
+	err := t.db.NewSelect().Model(&rows).
+		Where("coalesce(user_id, '') = ?", taskOwner(ctx)).
+		Where("coalesce(project_id, '') = ?", projectID).
+		Where("expires_at > now()").
+		OrderExpr("created_at DESC, id").
+		Offset(max(offset, 0)).
+		Limit(tasksPageSize + 1).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	result := &TasksListResult{Tasks: make([]Task, 0, len(rows))}
+	if len(rows) > tasksPageSize {
+		rows = rows[:tasksPageSize]
+		result.NextCursor = strconv.Itoa(offset + tasksPageSize)
+	}
+	for _, row := range rows {
+		result.Tasks = append(result.Tasks, row.task())
+	}
+	return result, nil
+}
+
+// cancel marks a running task of the caller cancelled. The replica running
+// it stops the call within taskPollInterval.
+func (t *toolTasks) cancel(ctx context.Context, id string) (*mcpTaskRow, error) {
+	row := new(mcpTaskRow)
+	err := t.db.NewUpdate().Model(row).
+		Set("status = ?", TaskStatusCancelled).
+		Set("status_message = ?", "Cancelled by the client").
+		Set("updated_at = now()").
+		Where("id = ?", id).
+		Where("coalesce(user_id, '') = ?", taskOwner(ctx)).
+		Where("expires_at > now()").
+		Where("status = ?", TaskStatusWorking).
+		Returning("*").
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := t.get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, errTaskFinished
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cancel task: %w", err)
+	}
+	return row, nil
+}
+
+// taskToolResult is the result of tasks/result for a tools/call task.
+type taskToolResult struct {
+	ToolResult
+	Meta map[string]any `json:"_meta"`
+}
+
+// taskResultResponse returns the tasks/result response of a finished task:
+// the tool call's result, or the error the call failed with.
+func taskResultResponse(id json.RawMessage, row *mcpTaskRow) *Response {
+	if row.Error != nil {
+		return &Response{JSONRPC: "2.0", ID: id, Error: row.Error}
+	}
+	if row.Status == TaskStatusCancelled {
+		return NewErrorResponse(id, ErrCodeInvalidParams, "Task was cancelled", map[string]string{"taskId": row.ID})
+	}
+	result := taskToolResult{Meta: map[string]any{relatedTaskMeta: map[string]string{"taskId": row.ID}}}
+	if err := json.Unmarshal(row.Result, &result.ToolResult); err != nil {
+		return NewErrorResponse(id, ErrCodeInternalError, "Invalid task result", nil)
+	}
+	return NewSuccessResponse(id, result)
+}
+
+// tasksCapability advertises tool calls as tasks, with listing and
+// cancellation.
+func tasksCapability() *TasksCapability {
+	return &TasksCapability{
+		List:     &struct{}{},
+		Cancel:   &struct{}{},
+		Requests: &TaskRequestsCapability{Tools: &TaskToolsCapability{Call: &struct{}{}}},
+	}
+}
+
+// startTask runs a tools/call as a task and returns the task at once. Tools
+// that are quick to run cannot be called as tasks.
+func (d *dispatcher) startTask(ctx context.Context, req *Request, cc callContext, params *ToolsCallParams) *Response {
+	if !supportsTask(params.Name) {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Tool "+params.Name+" does not support task execution",
+			map[string]string{"tool": params.Name},
+		)
+	}
+
+	task, err := d.tasks.start(ctx, cc.ProjectID, cc.SessionID, params.Name, params.Task, func(ctx context.Context) (*ToolResult, error) {
+		return d.svc.ExecuteTool(ctx, cc.ProjectID, params.Name, params.Arguments)
+	})
+	if errors.Is(err, errTasksStopped) {
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Server is shutting down", nil)
+	}
+	if err != nil {
+		d.log.Error("failed to start task", slog.String("tool", params.Name), logger.Error(err))
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Failed to start task", nil)
+	}
+	return NewSuccessResponse(req.ID, CreateTaskResult{Task: task})
+}
+
+// taskMethod handles tasks/get, tasks/result, tasks/list and tasks/cancel.
+func (d *dispatcher) taskMethod(ctx context.Context, req *Request, cc callContext) *Response {
+	if req.Method == "tasks/list" {
+		var params TasksListParams
+		if len(req.Params) > 0 {
+			if resp := unmarshalParams(req, &params); resp != nil {
+				return resp
+			}
+		}
+		result, err := d.tasks.list(ctx, cc.ProjectID, params.Cursor)
+		if err != nil {
+			d.log.Error("failed to list tasks", logger.Error(err))
+			return NewErrorResponse(req.ID, ErrCodeInternalError, "Failed to list tasks", nil)
+		}
+		return NewSuccessResponse(req.ID, result)
+	}
+
+	var params TaskParams
+	if resp := unmarshalParams(req, &params); resp != nil {
+		return resp
+	}
+	if params.TaskID == "" {
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams,
+			"Missing required parameter: taskId",
+			map[string]any{"required": []string{"taskId"}},
+		)
+	}
+
+	var row *mcpTaskRow
+	var err error
+	switch req.Method {
+	case "tasks/get":
+		row, err = d.tasks.get(ctx, params.TaskID)
+	case "tasks/result":
+		row, err = d.tasks.wait(ctx, params.TaskID)
+	default: // tasks/cancel
+		row, err = d.tasks.cancel(ctx, params.TaskID)
+	}
+	switch {
+	case errors.Is(err, errTaskNotFound):
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams, "Task not found: "+params.TaskID, map[string]string{"taskId": params.TaskID})
+	case errors.Is(err, errTaskFinished):
+		return NewErrorResponse(req.ID, ErrCodeInvalidParams, "Task already finished: "+params.TaskID, map[string]string{"taskId": params.TaskID})
+	case err != nil:
+		d.log.Error("task request failed", slog.String("method", req.Method), slog.String("task_id", params.TaskID), logger.Error(err))
+		return NewErrorResponse(req.ID, ErrCodeInternalError, "Task request failed", nil)
+	}
+
+	if req.Method == "tasks/result" {
+		return taskResultResponse(req.ID, row)
+	}
+	return NewSuccessResponse(req.ID, row.task())
+}
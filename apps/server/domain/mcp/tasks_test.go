@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolTasks_StopCancelsRunningTasks(t *testing.T) {
+	tasks := newToolTasks(nil, slog.Default())
+	require.NoError(t, tasks.Start(context.Background()))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done, err := tasks.track("task-1", cancel)
+	require.NoError(t, err)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		<-ctx.Done()
+		done()
+	}()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	require.NoError(t, tasks.Stop(stopCtx))
+
+	<-returned
+	assert.ErrorIs(t, context.Cause(ctx), errTasksStopped)
+	assert.Empty(t, tasks.running)
+
+	_, err = tasks.track("task-2", func(error) {})
+	assert.ErrorIs(t, err, errTasksStopped, "no task starts once the server stops")
+}
+
+func TestToolTasks_StopWaitsForTasks(t *testing.T) {
+	tasks := newToolTasks(nil, slog.Default())
+	_, err := tasks.track("task-1", func(error) {})
+	require.NoError(t, err)
+
+	// The task ignores its cancellation, so Stop gives up when ctx ends
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, tasks.Stop(ctx), context.DeadlineExceeded)
+}
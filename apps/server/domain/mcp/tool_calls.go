@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// cancelPrefix marks the IDs under which a client's notifications/cancelled
+// is relayed through the session store to the replica running the request.
+// Server request IDs never start with it.
+const cancelPrefix = "cancel:"
+
+// CancelledParams are the params of notifications/cancelled.
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// toolCalls tracks the tool calls running on this replica so a client can
+// cancel them with notifications/cancelled. It is a ClientResponseSink for
+// cancellations relayed from other replicas.
+type toolCalls struct {
+	mu      sync.Mutex
+	running map[toolCallKey]context.CancelCauseFunc
+}
+
+// toolCallKey names a running call. Request IDs are chosen by clients, so
+// they are only unique within a session.
+type toolCallKey struct {
+	sessionID string
+	requestID string
+}
+
+func newToolCalls() *toolCalls {
+	return &toolCalls{running: make(map[toolCallKey]context.CancelCauseFunc)}
+}
+
+// start registers the session's call with the request's ID and returns its
+// context. done must be called when the call returns.
+func (t *toolCalls) start(ctx context.Context, sessionID, requestID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	key := toolCallKey{sessionID: sessionID, requestID: requestID}
+	t.mu.Lock()
+	t.running[key] = cancel
+	t.mu.Unlock()
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.running, key)
+		t.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Cancel cancels the session's call with the request's ID. It reports
+// whether such a call runs on this replica.
+func (t *toolCalls) Cancel(sessionID, requestID, reason string) bool {
+	t.mu.Lock()
+	cancel, ok := t.running[toolCallKey{sessionID: sessionID, requestID: requestID}]
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+	cancel(&CancelledError{Reason: reason})
+	return true
+}
+
+// Awaits reports whether a relayed cancellation is for a call of the session
+// running here.
+func (t *toolCalls) Awaits(sessionID, requestID string) bool {
+	id, ok := strings.CutPrefix(requestID, cancelPrefix)
+	if !ok {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok = t.running[toolCallKey{sessionID: sessionID, requestID: id}]
+	return ok
+}
+
+// Resolve cancels the call named by a relayed cancellation.
+func (t *toolCalls) Resolve(sessionID, requestID string, data json.RawMessage) {
+	var params CancelledParams
+	_ = json.Unmarshal(data, &params)
+	t.Cancel(sessionID, strings.TrimPrefix(requestID, cancelPrefix), params.Reason)
+}
+
+// CancelledError is the cause of a tool call's context cancellation when the
+// client cancelled the call.
+type CancelledError struct {
+	Reason string
+}
+
+func (e *CancelledError) Error() string {
+	if e.Reason == "" {
+		return "cancelled by the client"
+	}
+	return "cancelled by the client: " + e.Reason
+}
+
+// cancelledRequestID returns the ID of the request a notifications/cancelled
+// cancels, as a string.
+func cancelledRequestID(params *CancelledParams) string {
+	return responseID(&Request{ID: params.RequestID})
+}
+
+// responseSinks hands each relayed message to the first sink awaiting it, so
+// client responses and cancellations share the session store's relay.
+type responseSinks []ClientResponseSink
+
+// Awaits reports whether any sink awaits the session's ID.
+func (s responseSinks) Awaits(sessionID, requestID string) bool {
+	for _, sink := range s {
+		if sink.Awaits(sessionID, requestID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve hands the message to the first sink awaiting its ID.
+func (s responseSinks) Resolve(sessionID, requestID string, data json.RawMessage) {
+	for _, sink := range s {
+		if sink.Awaits(sessionID, requestID) {
+			sink.Resolve(sessionID, requestID, data)
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCalls_Cancel(t *testing.T) {
+	calls := newToolCalls()
+	ctx, done := calls.start(context.Background(), "s1", "7")
+
+	assert.False(t, calls.Cancel("s2", "7", ""), "other sessions cannot cancel the call")
+	assert.False(t, calls.Cancel("s1", "8", ""))
+	require.NoError(t, ctx.Err())
+
+	assert.True(t, calls.Cancel("s1", "7", "user clicked stop"))
+	require.Error(t, ctx.Err())
+	var cancelled *CancelledError
+	require.True(t, errors.As(context.Cause(ctx), &cancelled))
+	assert.Equal(t, "cancelled by the client: user clicked stop", cancelled.Error())
+
+	done()
+	assert.False(t, calls.Awaits("s1", cancelPrefix+"7"), "finished calls are forgotten")
+}
+
+// Clients choose request IDs, so two sessions can run calls with the same ID.
+func TestToolCalls_SameRequestIDInTwoSessions(t *testing.T) {
+	calls := newToolCalls()
+	ctx1, done1 := calls.start(context.Background(), "s1", "1")
+	ctx2, done2 := calls.start(context.Background(), "s2", "1")
+	defer done2()
+
+	assert.True(t, calls.Awaits("s1", cancelPrefix+"1"))
+	assert.True(t, calls.Awaits("s2", cancelPrefix+"1"))
+	assert.False(t, calls.Awaits("s3", cancelPrefix+"1"))
+
+	calls.Resolve("s2", cancelPrefix+"1", mustJSONRaw(t, &CancelledParams{RequestID: json.RawMessage(`1`)}))
+	require.Error(t, ctx2.Err())
+	require.NoError(t, ctx1.Err(), "cancelling one session's call leaves the other running")
+
+	done1()
+	assert.False(t, calls.Awaits("s1", cancelPrefix+"1"))
+	assert.True(t, calls.Awaits("s2", cancelPrefix+"1"), "finishing one session's call keeps the other registered")
+}
+
+func TestToolCalls_RelayedCancellation(t *testing.T) {
+	requests := newClientRequests()
+	calls := newToolCalls()
+	sinks := responseSinks{requests, calls}
+	store := NewMemorySessionStore(10, 0)
+	store.OnResponse(sinks)
+
+	ctx, done := calls.start(context.Background(), "s1", "req-1")
+	defer done()
+
+	params := &CancelledParams{RequestID: json.RawMessage(`"req-1"`), Reason: "timeout"}
+	id := cancelPrefix + cancelledRequestID(params)
+	require.True(t, sinks.Awaits("s1", id))
+	require.False(t, sinks.Awaits("s2", id))
+	require.NoError(t, store.DeliverResponse(context.Background(), "s1", id, mustJSONRaw(t, params)))
+
+	<-ctx.Done()
+	var cancelled *CancelledError
+	require.True(t, errors.As(context.Cause(ctx), &cancelled))
+	assert.Equal(t, "timeout", cancelled.Reason)
+}
+
+func TestSupportsTask(t *testing.T) {
+	assert.True(t, supportsTask("trigger_agent"))
+	assert.True(t, supportsTask("batch_create_entities"))
+	assert.False(t, supportsTask("query_entities"))
+	assert.True(t, supportsTask("review_contract"), "published agents run as tasks")
+
+	assert.Equal(t, &ToolExecution{TaskSupport: "optional"}, toolPolicies["traverse_graph"].execution())
+	assert.Nil(t, toolPolicies["list_entity_types"].execution())
+}
+
+func mustJSONRaw(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
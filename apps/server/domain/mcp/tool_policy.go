@@ -0,0 +1,331 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+)
+
+// toolPolicy describes who may call a tool and how the tool behaves.
+//
+// scopes are the fine-grained scopes an API token must hold, after umbrella
+// scopes such as data:read are expanded. adminOnly tools change project
+// configuration and need the caller to be a project admin or an admin of the
+// project's organization. longRunning tools may be called as tasks. The
+// remaining fields become the tool's MCP annotations.
+type toolPolicy struct {
+	scopes      []string
+	adminOnly   bool
+	longRunning bool
+	readOnly    bool
+	destructive bool
+	idempotent  bool
+	openWorld   bool
+}
+
+// Policies shared by groups of tools.
+var (
+	graphRead   = toolPolicy{scopes: []string{"graph:read"}, readOnly: true}
+	graphSearch = toolPolicy{scopes: []string{"graph:search:read"}, readOnly: true}
+	schemaRead  = toolPolicy{scopes: []string{"schema:read"}, readOnly: true}
+	agentsRead  = toolPolicy{scopes: []string{"agents:read"}, readOnly: true}
+	configRead  = toolPolicy{scopes: []string{"projects:read"}, readOnly: true}
+)
+
+// toolPolicies covers every built-in and delegated tool. A tool without a
+// policy cannot be listed or called through an MCP transport.
+var toolPolicies = map[string]toolPolicy{
+	// Schema and template packs
+	"schema_version":             schemaRead,
+	"list_entity_types":          schemaRead,
+	"list_template_packs":        schemaRead,
+	"get_template_pack":          schemaRead,
+	"get_available_templates":    schemaRead,
+	"get_installed_templates":    schemaRead,
+	"preview_schema_migration":   schemaRead,
+	"list_migration_archives":    schemaRead,
+	"get_migration_archive":      schemaRead,
+	"assign_template_pack":       {scopes: []string{"projects:write"}, adminOnly: true},
+	"update_template_assignment": {scopes: []string{"projects:write"}, adminOnly: true, idempotent: true},
+	"uninstall_template_pack":    {scopes: []string{"projects:write"}, adminOnly: true, destructive: true, idempotent: true},
+	"create_template_pack":       {scopes: []string{"projects:write"}},
+	"delete_template_pack":       {scopes: []string{"projects:write"}, destructive: true, idempotent: true},
+
+	// Graph reads and search
+	"query_entities":     graphRead,
+	"search_entities":    graphRead,
+	"get_entity_edges":   graphRead,
+	"traverse_graph":     {scopes: []string{"graph:read"}, longRunning: true, readOnly: true},
+	"list_relationships": graphRead,
+	"list_tags":          graphRead,
+	"hybrid_search":      graphSearch,
+	"semantic_search":    graphSearch,
+	"find_similar":       graphSearch,
+	"brave_web_search":   {scopes: []string{"search:read"}, readOnly: true, openWorld: true},
+
+	// Graph writes
+	"create_entity":              {scopes: []string{"graph:write"}},
+	"create_relationship":        {scopes: []string{"graph:write"}},
+	"batch_create_entities":      {scopes: []string{"graph:write"}, longRunning: true},
+	"batch_create_relationships": {scopes: []string{"graph:write"}, longRunning: true},
+	"update_entity":              {scopes: []string{"graph:write"}, idempotent: true},
+	"update_relationship":        {scopes: []string{"graph:write"}, idempotent: true},
+	"restore_entity":             {scopes: []string{"graph:write"}, idempotent: true},
+	"delete_entity":              {scopes: []string{"graph:write"}, destructive: true, idempotent: true},
+	"delete_relationship":        {scopes: []string{"graph:write"}, destructive: true, idempotent: true},
+
+	// Agents
+	"list_agent_definitions":   agentsRead,
+	"get_agent_definition":     agentsRead,
+	"list_agents":              agentsRead,
+	"get_agent":                agentsRead,
+	"list_agent_runs":          agentsRead,
+	"get_agent_run":            agentsRead,
+	"get_agent_run_messages":   agentsRead,
+	"get_agent_run_tool_calls": agentsRead,
+	"list_available_agents":    agentsRead,
+	"create_agent_definition":  {scopes: []string{"agents:write"}, adminOnly: true},
+	"update_agent_definition":  {scopes: []string{"agents:write"}, adminOnly: true, idempotent: true},
+	"delete_agent_definition":  {scopes: []string{"agents:write"}, adminOnly: true, destructive: true, idempotent: true},
+	"create_agent":             {scopes: []string{"agents:write"}, adminOnly: true},
+	"update_agent":             {scopes: []string{"agents:write"}, adminOnly: true, idempotent: true},
+	"delete_agent":             {scopes: []string{"agents:write"}, adminOnly: true, destructive: true, idempotent: true},
+	"trigger_agent":            {scopes: []string{"agents:write"}, longRunning: true, openWorld: true},
+
+	// MCP servers and the official registry. They are project configuration,
+	// so tokens need the projects scopes (projects:write implies projects:read).
+	"list_mcp_servers":          configRead,
+	"get_mcp_server":            configRead,
+	"search_mcp_registry":       {scopes: []string{"projects:read"}, readOnly: true, openWorld: true},
+	"get_mcp_registry_server":   {scopes: []string{"projects:read"}, readOnly: true, openWorld: true},
+	"inspect_mcp_server":        {scopes: []string{"projects:read"}, readOnly: true, openWorld: true},
+	"create_mcp_server":         {scopes: []string{"projects:write"}, adminOnly: true},
+	"update_mcp_server":         {scopes: []string{"projects:write"}, adminOnly: true, idempotent: true},
+	"delete_mcp_server":         {scopes: []string{"projects:write"}, adminOnly: true, destructive: true, idempotent: true},
+	"toggle_mcp_server_tool":    {scopes: []string{"projects:write"}, adminOnly: true, idempotent: true},
+	"sync_mcp_server_tools":     {scopes: []string{"projects:write"}, adminOnly: true, longRunning: true, idempotent: true, openWorld: true},
+	"install_mcp_from_registry": {scopes: []string{"projects:write"}, adminOnly: true, longRunning: true, openWorld: true},
+
+	// Documents and extraction. Creating a document may queue extraction, and
+	// data:write grants both scopes, so create_document asks for both.
+	"create_document":       {scopes: []string{"documents:write", "extraction:write"}, openWorld: true},
+	"list_documents":        {scopes: []string{"documents:read"}, readOnly: true},
+	"get_document":          {scopes: []string{"documents:read"}, readOnly: true},
+	"get_chunk_context":     {scopes: []string{"chunks:read"}, readOnly: true},
+	"get_extraction_status": {scopes: []string{"extraction:read"}, readOnly: true},
+	"start_extraction":      {scopes: []string{"extraction:write"}},
+}
+
+// publishedAgentPolicy covers the tools a project publishes for its agent
+// definitions. Calling one runs an agent, like trigger_agent.
+var publishedAgentPolicy = toolPolicy{scopes: []string{"agents:write"}, longRunning: true, openWorld: true}
+
+// errUnknownTool is returned by AuthorizeTool for a tool that is neither
+// built in nor published by the project.
+var errUnknownTool = errors.New("unknown tool")
+
+// IsKnownTool reports whether name is a built-in or delegated MCP tool.
+func IsKnownTool(name string) bool {
+	_, ok := toolPolicies[name]
+	return ok
+}
+
+// annotations returns the MCP annotations for the policy. Destructive and
+// idempotent hints only carry meaning for tools that are not read-only, so
+// they are omitted for read-only tools.
+func (p toolPolicy) annotations() *ToolAnnotations {
+	a := &ToolAnnotations{
+		ReadOnlyHint:  boolPtr(p.readOnly),
+		OpenWorldHint: boolPtr(p.openWorld),
+	}
+	if !p.readOnly {
+		a.DestructiveHint = boolPtr(p.destructive)
+		a.IdempotentHint = boolPtr(p.idempotent)
+	}
+	return a
+}
+
+// execution returns how the policy's tool may be called: long-running tools
+// may run as tasks, other tools may not.
+func (p toolPolicy) execution() *ToolExecution {
+	if p.longRunning {
+		return &ToolExecution{TaskSupport: "optional"}
+	}
+	return nil
+}
+
+// supportsTask reports whether a built-in or delegated tool may be called as
+// a task. Tools published by agent definitions always may.
+func supportsTask(name string) bool {
+	policy, ok := toolPolicies[name]
+	return !ok || policy.longRunning
+}
+
+// ToolAccessError is returned when the caller may not use a tool.
+type ToolAccessError struct {
+	Tool   string
+	Reason string
+}
+
+func (e *ToolAccessError) Error() string {
+	return fmt.Sprintf("access to tool %s denied: %s", e.Tool, e.Reason)
+}
+
+// toolAccess is what a caller may do with tools in one project.
+type toolAccess struct {
+	user         *auth.AuthUser
+	projectAdmin bool
+}
+
+// check returns a *ToolAccessError if the caller may not use the tool.
+// Callers without an authenticated user are internal and unrestricted.
+func (a toolAccess) check(name string) error {
+	policy, ok := toolPolicies[name]
+	if !ok {
+		return &ToolAccessError{Tool: name, Reason: "unknown tool"}
+	}
+	return a.checkPolicy(name, policy, name)
+}
+
+// checkPublished returns a *ToolAccessError if the caller may not run the
+// agent published as the tool. A token whose allowed tools include
+// trigger_agent may run every published agent.
+func (a toolAccess) checkPublished(name string) error {
+	return a.checkPolicy(name, publishedAgentPolicy, name, "trigger_agent")
+}
+
+// checkPolicy checks the caller against the tool's policy. The tool passes
+// the API token's allow-list when the list contains any of allowedAs.
+func (a toolAccess) checkPolicy(name string, policy toolPolicy, allowedAs ...string) error {
+	if a.user == nil {
+		return nil
+	}
+	if len(a.user.AllowedTools) > 0 && !slices.ContainsFunc(allowedAs, func(n string) bool { return slices.Contains(a.user.AllowedTools, n) }) {
+		return &ToolAccessError{Tool: name, Reason: "tool is not in the API token's allowed tools"}
+	}
+	if missing := auth.MissingAPITokenScopes(a.user, policy.scopes...); len(missing) > 0 {
+		return &ToolAccessError{Tool: name, Reason: "API token is missing scopes: " + strings.Join(missing, ", ")}
+	}
+	if policy.adminOnly && !a.projectAdmin {
+		return &ToolAccessError{Tool: name, Reason: "requires the project admin role"}
+	}
+	return nil
+}
+
+// filter returns the tools the caller may use.
+func (a toolAccess) filter(tools []ToolDefinition) []ToolDefinition {
+	allowed := make([]ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		if a.check(tool.Name) == nil {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// resolveToolAccess returns the access of the caller in ctx. With needRole,
+// the caller's role in the project is looked up; without a valid project ID
+// the caller is not an admin and admin-only tools are unavailable.
+func (s *Service) resolveToolAccess(ctx context.Context, projectID string, needRole bool) (toolAccess, error) {
+	user := auth.UserFromContext(ctx)
+	access := toolAccess{user: user}
+	if user == nil || !needRole {
+		return access, nil
+	}
+
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return access, nil
+	}
+
+	err = s.db.NewRaw(`SELECT EXISTS (
+			SELECT 1 FROM kb.project_memberships
+			WHERE project_id = ? AND user_id = ? AND role = 'project_admin'
+		) OR EXISTS (
+			SELECT 1 FROM kb.organization_memberships om
+			JOIN kb.projects p ON p.organization_id = om.organization_id
+			WHERE p.id = ? AND om.user_id = ? AND om.role = 'org_admin'
+		)`, projectUUID, user.ID, projectUUID, user.ID).
+		Scan(ctx, &access.projectAdmin)
+	if err != nil {
+		return access, fmt.Errorf("failed to resolve project role: %w", err)
+	}
+	return access, nil
+}
+
+// ToolsForCaller returns the tools the caller in ctx may use in the project,
+// filtered by API token scopes, the token's allowed tools and project role.
+// The project's published agents follow the built-in tools.
+func (s *Service) ToolsForCaller(ctx context.Context, projectID string) ([]ToolDefinition, error) {
+	access, err := s.resolveToolAccess(ctx, projectID, true)
+	if err != nil {
+		return nil, err
+	}
+	tools := access.filter(s.GetToolDefinitions())
+
+	published, err := s.publishedAgentTools(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range published {
+		if access.checkPublished(tool.Name) == nil {
+			tool.Annotations = publishedAgentPolicy.annotations()
+			tool.Execution = publishedAgentPolicy.execution()
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+// AuthorizeTool returns a *ToolAccessError if the caller in ctx may not call
+// the tool in the project, or errUnknownTool if there is no such tool.
+// ExecuteTool itself does not authorize, so agents and other internal
+// callers keep the full tool set.
+func (s *Service) AuthorizeTool(ctx context.Context, projectID, toolName string) error {
+	if !IsKnownTool(toolName) {
+		published, err := s.publishedAgentTools(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(published, func(t ToolDefinition) bool { return t.Name == toolName }) {
+			return errUnknownTool
+		}
+		access, err := s.resolveToolAccess(ctx, projectID, false)
+		if err != nil {
+			return err
+		}
+		return access.checkPublished(toolName)
+	}
+
+	access, err := s.resolveToolAccess(ctx, projectID, toolPolicies[toolName].adminOnly)
+	if err != nil {
+		return err
+	}
+	return access.check(toolName)
+}
+
+// publishedAgentTools returns the tools the project publishes for its agents.
+// There are none without a project or an agent publisher.
+func (s *Service) publishedAgentTools(ctx context.Context, projectID string) ([]ToolDefinition, error) {
+	if s.agentPublisher == nil {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(projectID); err != nil {
+		return nil, nil
+	}
+	tools, err := s.agentPublisher.PublishedAgentTools(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published agents: %w", err)
+	}
+	return tools, nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+)
+
+func tokenUser(scopes ...string) *auth.AuthUser {
+	return &auth.AuthUser{ID: "user-1", APITokenID: "token-1", Scopes: scopes}
+}
+
+func TestToolPolicies_CoverBuiltinTools(t *testing.T) {
+	for _, tool := range (&Service{}).GetToolDefinitions() {
+		assert.True(t, IsKnownTool(tool.Name), "tool %s has no policy", tool.Name)
+		assert.NotNil(t, tool.Annotations, tool.Name)
+	}
+}
+
+func TestToolPolicies_ReachableByTokens(t *testing.T) {
+	// Every tool must be callable by some API token, or token clients lose
+	// it. These are the scopes tokens can be created with (apitoken.ValidApiTokenScopes).
+	all := tokenUser("schema:read", "data:read", "data:write", "agents:read", "agents:write", "projects:read", "projects:write")
+	for name, policy := range toolPolicies {
+		assert.Empty(t, auth.MissingAPITokenScopes(all, policy.scopes...), name)
+	}
+}
+
+func TestToolPolicy_Annotations(t *testing.T) {
+	read := toolPolicies["query_entities"].annotations()
+	assert.True(t, *read.ReadOnlyHint)
+	assert.False(t, *read.OpenWorldHint)
+	assert.Nil(t, read.DestructiveHint)
+	assert.Nil(t, read.IdempotentHint)
+
+	del := toolPolicies["delete_entity"].annotations()
+	assert.False(t, *del.ReadOnlyHint)
+	assert.True(t, *del.DestructiveHint)
+	assert.True(t, *del.IdempotentHint)
+
+	create := toolPolicies["create_entity"].annotations()
+	assert.False(t, *create.DestructiveHint)
+	assert.False(t, *create.IdempotentHint)
+
+	assert.True(t, *toolPolicies["brave_web_search"].annotations().OpenWorldHint)
+}
+
+func TestToolAccess_Check(t *testing.T) {
+	research := tokenUser("data:read")
+	research.AllowedTools = []string{"hybrid_search", "semantic_search"}
+
+	tests := []struct {
+		name    string
+		access  toolAccess
+		tool    string
+		allowed bool
+	}{
+		{name: "internal caller", access: toolAccess{}, tool: "delete_agent", allowed: true},
+		{name: "unknown tool", access: toolAccess{}, tool: "drop_database", allowed: false},
+		{name: "read token reads graph", access: toolAccess{user: tokenUser("data:read")}, tool: "query_entities", allowed: true},
+		{name: "read token reads documents", access: toolAccess{user: tokenUser("data:read")}, tool: "get_document", allowed: true},
+		{name: "read token cannot write graph", access: toolAccess{user: tokenUser("data:read")}, tool: "create_entity", allowed: false},
+		{name: "write token writes graph", access: toolAccess{user: tokenUser("data:write")}, tool: "delete_entity", allowed: true},
+		{name: "schema token lists types", access: toolAccess{user: tokenUser("schema:read")}, tool: "list_entity_types", allowed: true},
+		{name: "schema token cannot query", access: toolAccess{user: tokenUser("schema:read")}, tool: "query_entities", allowed: false},
+		{name: "agents token triggers agent", access: toolAccess{user: tokenUser("agents:write")}, tool: "trigger_agent", allowed: true},
+		{name: "non-admin cannot create agent", access: toolAccess{user: tokenUser("agents:write")}, tool: "create_agent", allowed: false},
+		{name: "admin creates agent", access: toolAccess{user: tokenUser("agents:write"), projectAdmin: true}, tool: "create_agent", allowed: true},
+		{name: "project token lists MCP servers", access: toolAccess{user: tokenUser("projects:read")}, tool: "list_mcp_servers", allowed: true},
+		{name: "admin token manages MCP servers", access: toolAccess{user: tokenUser("projects:write"), projectAdmin: true}, tool: "create_mcp_server", allowed: true},
+		{name: "data token cannot manage MCP servers", access: toolAccess{user: tokenUser("data:write"), projectAdmin: true}, tool: "create_mcp_server", allowed: false},
+		{name: "session user is not scope limited", access: toolAccess{user: &auth.AuthUser{ID: "user-1"}}, tool: "create_entity", allowed: true},
+		{name: "session user needs admin role", access: toolAccess{user: &auth.AuthUser{ID: "user-1"}}, tool: "assign_template_pack", allowed: false},
+		{name: "allow-listed tool", access: toolAccess{user: research}, tool: "hybrid_search", allowed: true},
+		{name: "tool outside allow-list", access: toolAccess{user: research}, tool: "query_entities", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.access.check(tt.tool)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			var accessErr *ToolAccessError
+			assert.ErrorAs(t, err, &accessErr)
+		})
+	}
+}
+
+func TestDocumentTools_ScopeMapping(t *testing.T) {
+	read := toolAccess{user: tokenUser("data:read")}
+	for _, tool := range []string{"list_documents", "get_document", "get_chunk_context", "get_extraction_status"} {
+		assert.NoError(t, read.check(tool), tool)
+	}
+	assert.Error(t, read.check("create_document"))
+	assert.Error(t, read.check("start_extraction"))
+
+	write := toolAccess{user: tokenUser("data:write")}
+	assert.NoError(t, write.check("create_document"), "data:write grants documents:write and extraction:write")
+	assert.NoError(t, write.check("start_extraction"))
+	assert.Error(t, write.check("list_documents"), "data:write does not grant reads")
+
+	assert.Error(t, toolAccess{user: tokenUser("schema:read")}.check("get_document"))
+
+	// Sessions not authenticated with an API token are not limited
+	assert.NoError(t, toolAccess{user: &auth.AuthUser{ID: "user-1"}}.check("create_document"))
+}
+
+func TestDocumentTools_RequireTokenScopes(t *testing.T) {
+	d := newDispatcher(&Service{}, NewNotifier(nil, nil, slog.Default()), slog.Default())
+	ready := callContext{ProjectID: "proj-1", Initialized: true}
+	readOnly := auth.ContextWithUser(context.Background(), tokenUser("data:read"))
+
+	tests := []struct {
+		name    string
+		params  string
+		missing string
+	}{
+		{name: "create_document", params: `{"name":"create_document","arguments":{"content":"Some text","extract":false}}`, missing: "documents:write"},
+		{name: "create_document with extraction", params: `{"name":"create_document","arguments":{"content":"Some text"}}`, missing: "extraction:write"},
+		{name: "start_extraction", params: `{"name":"start_extraction","arguments":{"document_id":"00000000-0000-0000-0000-000000000001"}}`, missing: "extraction:write"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := d.dispatch(readOnly, rpcRequest("tools/call", tt.params), ready)
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, ErrCodeForbidden, resp.Error.Code)
+			assert.Contains(t, resp.Error.Data.(map[string]string)["reason"], tt.missing)
+		})
+	}
+}
+
+func TestToolAccess_CheckPublished(t *testing.T) {
+	triggerOnly := tokenUser("agents:write")
+	triggerOnly.AllowedTools = []string{"trigger_agent"}
+	named := tokenUser("agents:write")
+	named.AllowedTools = []string{"summarize"}
+	research := tokenUser("agents:write")
+	research.AllowedTools = []string{"hybrid_search"}
+
+	assert.NoError(t, (toolAccess{}).checkPublished("summarize"))
+	assert.NoError(t, (toolAccess{user: &auth.AuthUser{ID: "user-1"}}).checkPublished("summarize"))
+	assert.NoError(t, (toolAccess{user: triggerOnly}).checkPublished("summarize"), "trigger_agent allows every published agent")
+	assert.NoError(t, (toolAccess{user: named}).checkPublished("summarize"))
+	assert.Error(t, (toolAccess{user: research}).checkPublished("summarize"))
+	assert.Error(t, (toolAccess{user: tokenUser("data:read")}).checkPublished("summarize"))
+}
+
+func TestToolAccess_Filter(t *testing.T) {
+	research := tokenUser("data:read")
+	research.AllowedTools = []string{"hybrid_search", "semantic_search", "create_entity"}
+
+	var names []string
+	for _, tool := range (toolAccess{user: research}).filter((&Service{}).GetToolDefinitions()) {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"hybrid_search", "semantic_search"}, names)
+
+	all := (&Service{}).GetToolDefinitions()
+	assert.Len(t, (toolAccess{}).filter(all), len(all))
+}
+
+func TestDispatcher_ToolAccess(t *testing.T) {
+	d := newDispatcher(&Service{}, NewNotifier(nil, nil, slog.Default()), slog.Default())
+	ready := callContext{ProjectID: "proj-1", Initialized: true}
+	ctx := auth.ContextWithUser(context.Background(), tokenUser("data:read"))
+
+	resp := d.dispatch(ctx, rpcRequest("tools/list", ""), ready)
+	require.Nil(t, resp.Error)
+	tools := resp.Result.(ToolsListResult).Tools
+	require.NotEmpty(t, tools)
+	for _, tool := range tools {
+		assert.True(t, *tool.Annotations.ReadOnlyHint, "read token was offered %s", tool.Name)
+	}
+
+	resp = d.dispatch(ctx, rpcRequest("tools/call", `{"name":"delete_entity","arguments":{"entity_id":"e1"}}`), ready)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, ErrCodeForbidden, resp.Error.Code)
+	assert.Contains(t, resp.Error.Data.(map[string]string)["reason"], "graph:write")
+
+	resp = d.dispatch(ctx, rpcRequest("tools/call", `{"name":"drop_database"}`), ready)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, ErrCodeInvalidParams, resp.Error.Code)
+	assert.Equal(t, "Unknown tool: drop_database", resp.Error.Message)
+}
+
+// stubPublisher publishes a single "summarize" agent.
+type stubPublisher struct {
+	calls []string
+}
+
+func (p *stubPublisher) PublishedAgentTools(context.Context, string) ([]ToolDefinition, error) {
+	return []ToolDefinition{{Name: "summarize", Description: "Summarize", InputSchema: InputSchema{Type: "object"}}}, nil
+}
+
+func (p *stubPublisher) ExecutePublishedAgent(_ context.Context, _, name string, _ map[string]any) (*ToolResult, error) {
+	p.calls = append(p.calls, name)
+	return &ToolResult{Content: []ContentBlock{{Type: "text", Text: "done"}}}, nil
+}
+
+func (p *stubPublisher) PublishedAgentPrompts(context.Context, string) ([]PromptDefinition, error) {
+	return []PromptDefinition{{Name: "summarize"}, {Name: "explore_entity_type"}}, nil
+}
+
+func (p *stubPublisher) GetPublishedAgentPrompt(context.Context, string, string, map[string]any) (*PromptGetResult, error) {
+	return &PromptGetResult{}, nil
+}
+
+func TestDispatcher_PublishedAgents(t *testing.T) {
+	publisher := &stubPublisher{}
+	svc := &Service{}
+	svc.SetAgentPublisher(publisher)
+	d := newDispatcher(svc, NewNotifier(nil, nil, slog.Default()), slog.Default())
+	ready := callContext{ProjectID: "8a1f0c57-6f7e-4d0b-9d55-1c1a3e2f4b6d", Initialized: true}
+
+	triggerOnly := tokenUser("agents:write")
+	triggerOnly.AllowedTools = []string{"trigger_agent"}
+	ctx := auth.ContextWithUser(context.Background(), triggerOnly)
+
+	resp := d.dispatch(ctx, rpcRequest("tools/call", `{"name":"summarize","arguments":{"message":"hi"}}`), ready)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, []string{"summarize"}, publisher.calls)
+
+	readOnly := auth.ContextWithUser(context.Background(), tokenUser("data:read"))
+	resp = d.dispatch(readOnly, rpcRequest("tools/call", `{"name":"summarize"}`), ready)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, ErrCodeForbidden, resp.Error.Code)
+
+	resp = d.dispatch(ctx, rpcRequest("tools/call", `{"name":"summarize"}`), callContext{ProjectID: "proj-1", Initialized: true})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "Unknown tool: summarize", resp.Error.Message, "agents are only published in a project")
+
+	resp = d.dispatch(ctx, rpcRequest("prompts/list", ""), ready)
+	require.Nil(t, resp.Error)
+	prompts := resp.Result.(PromptsListResult).Prompts
+	assert.Len(t, prompts, len(svc.GetPromptDefinitions())+1, "a published prompt does not shadow a built-in one")
+	assert.Equal(t, "summarize", prompts[len(prompts)-1].Name)
+}
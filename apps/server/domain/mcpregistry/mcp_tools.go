@@ -265,6 +265,7 @@ func (h *MCPRegistryToolHandler) ExecuteSyncMCPServerTools(ctx context.Context,
 	}
 
 	var toolCount int
+	client := mcp.ClientSessionFromContext(ctx)
 
 	if len(discoveredTools) > 0 {
 		// Manual sync with provided tools
@@ -274,11 +275,13 @@ func (h *MCPRegistryToolHandler) ExecuteSyncMCPServerTools(ctx context.Context,
 		toolCount = len(discoveredTools)
 	} else {
 		// Auto-discover: connect to server and call tools/list
+		client.Progress(0, 1, "Connecting to server "+serverID)
 		discovered, err := h.service.DiscoverAndSyncTools(ctx, serverID, projectID)
 		if err != nil {
 			return errResult("failed to discover and sync tools: " + err.Error())
 		}
 		toolCount = len(discovered)
+		client.Progress(1, 1, fmt.Sprintf("Synced %d tools", toolCount))
 	}
 
 	return wrapResult(map[string]any{
@@ -345,10 +348,13 @@ func (h *MCPRegistryToolHandler) ExecuteInstallMCPFromRegistry(ctx context.Conte
 		dto.Name = name
 	}
 
+	client := mcp.ClientSessionFromContext(ctx)
+	client.Progress(0, 1, "Installing "+registryName)
 	result, err := h.service.InstallFromRegistry(ctx, projectID, dto)
 	if err != nil {
 		return errResult("failed to install from registry: " + err.Error())
 	}
+	client.Progress(1, 1, "Installed "+registryName)
 
 	return wrapResult(result)
 }
@@ -2,6 +2,9 @@ package provider
 
 import (
 	"context"
+	"log/slog"
+
+	adkmodel "google.golang.org/adk/model"
 
 	"github.com/emergent-company/emergent.memory/pkg/adk"
 )
@@ -43,3 +46,22 @@ func toADKCredential(c *ResolvedCredential) *adk.ResolvedCredential {
 		Source:             string(c.Source),
 	}
 }
+
+// ADKUsageTracker satisfies adk.UsageTracker by wrapping every model the
+// ADK ModelFactory creates in a TrackingModel, which records usage and
+// enforces LLM budgets.
+type ADKUsageTracker struct {
+	usage   *UsageService
+	budgets *BudgetService
+	log     *slog.Logger
+}
+
+// NewADKUsageTracker creates a new ADKUsageTracker.
+func NewADKUsageTracker(usage *UsageService, budgets *BudgetService, log *slog.Logger) *ADKUsageTracker {
+	return &ADKUsageTracker{usage: usage, budgets: budgets, log: log}
+}
+
+// Track satisfies adk.UsageTracker.
+func (t *ADKUsageTracker) Track(llm adkmodel.LLM, provider string) adkmodel.LLM {
+	return NewTrackingModel(llm, t.usage, t.budgets, ProviderType(provider), t.log)
+}
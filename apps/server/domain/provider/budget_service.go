@@ -0,0 +1,371 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/domain/notifications"
+	"github.com/emergent-company/emergent.memory/pkg/adk"
+	"github.com/emergent-company/emergent.memory/pkg/apperror"
+	"github.com/emergent-company/emergent.memory/pkg/auth"
+	"github.com/emergent-company/emergent.memory/pkg/logger"
+)
+
+// BudgetState summarises a budget's usage in its current period.
+type BudgetState string
+
+const (
+	BudgetStateOK        BudgetState = "ok"
+	BudgetStateSoftLimit BudgetState = "soft_limit"
+	BudgetStateExceeded  BudgetState = "exceeded"
+)
+
+// BudgetStatus is a budget together with its usage in the current period.
+type BudgetStatus struct {
+	Budget       LLMBudget   `json:"budget"`
+	PeriodStart  time.Time   `json:"periodStart"`
+	PeriodEnd    time.Time   `json:"periodEnd"`
+	TokensUsed   int64       `json:"tokensUsed"`
+	CostUsedUSD  float64     `json:"costUsedUsd"`
+	TokenPercent *float64    `json:"tokenPercent,omitempty"`
+	CostPercent  *float64    `json:"costPercent,omitempty"`
+	State        BudgetState `json:"state"`
+}
+
+// UsageAttribution identifies the agent run an LLM call is made for.
+type UsageAttribution struct {
+	AgentID           string
+	AgentDefinitionID string
+	RunID             string
+}
+
+type usageAttributionKey struct{}
+
+// WithUsageAttribution returns a context whose LLM usage is attributed to an
+// agent run and counted against the agent definition's budgets.
+func WithUsageAttribution(ctx context.Context, a UsageAttribution) context.Context {
+	return context.WithValue(ctx, usageAttributionKey{}, a)
+}
+
+// UsageAttributionFromContext returns the attribution set by
+// WithUsageAttribution, or the zero value.
+func UsageAttributionFromContext(ctx context.Context) UsageAttribution {
+	a, _ := ctx.Value(usageAttributionKey{}).(UsageAttribution)
+	return a
+}
+
+// BudgetService enforces LLM budgets. Check runs before every tracked model
+// call: it refuses the call once a hard limit applying to the caller's org,
+// project or agent definition is reached, and notifies admins the first time
+// each period a budget crosses its soft limit or is exhausted.
+//
+// Usage is recorded asynchronously, so a limit may be overshot by the calls
+// in flight when it is reached.
+type BudgetService struct {
+	repo *Repository
+	log  *slog.Logger
+
+	projectOrgs sync.Map // project ID → org ID
+}
+
+// NewBudgetService creates a new BudgetService.
+func NewBudgetService(repo *Repository, log *slog.Logger) *BudgetService {
+	return &BudgetService{
+		repo: repo,
+		log:  log.With(logger.Scope("provider.budget")),
+	}
+}
+
+// Check returns an *adk.BudgetExceededError when a hard limit applying to
+// the project (and agent definition) on ctx has been reached. Failures to
+// load budgets are logged and the call is allowed.
+func (s *BudgetService) Check(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	projectID := auth.ProjectIDFromContext(ctx)
+	if projectID == "" {
+		return nil
+	}
+	orgID := s.OrgIDForProject(ctx, projectID)
+	if orgID == "" {
+		return nil
+	}
+
+	attr := UsageAttributionFromContext(ctx)
+	budgets, err := s.repo.ListApplicableBudgets(ctx, orgID, projectID, attr.AgentDefinitionID)
+	if err != nil {
+		s.log.Warn("failed to load budgets, allowing call", logger.Error(err))
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for i := range budgets {
+		status, err := s.evaluate(ctx, &budgets[i], now)
+		if err != nil {
+			s.log.Warn("failed to evaluate budget, allowing call",
+				logger.Error(err), slog.String("budget_id", budgets[i].ID))
+			continue
+		}
+		switch status.State {
+		case BudgetStateExceeded:
+			s.notify(ctx, status, "hard")
+			return exceededError(status)
+		case BudgetStateSoftLimit:
+			s.notify(ctx, status, "soft")
+		}
+	}
+	return nil
+}
+
+// OrgIDForProject returns the project's org, from ctx when set there.
+func (s *BudgetService) OrgIDForProject(ctx context.Context, projectID string) string {
+	if orgID := auth.OrgIDFromContext(ctx); orgID != "" {
+		return orgID
+	}
+	if orgID, ok := s.projectOrgs.Load(projectID); ok {
+		return orgID.(string)
+	}
+	orgID, err := s.repo.GetOrgIDForProject(ctx, projectID)
+	if err != nil {
+		return ""
+	}
+	s.projectOrgs.Store(projectID, orgID)
+	return orgID
+}
+
+// Statuses returns the current usage of the given budgets.
+func (s *BudgetService) Statuses(ctx context.Context, budgets []LLMBudget) ([]BudgetStatus, error) {
+	now := time.Now().UTC()
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for i := range budgets {
+		status, err := s.evaluate(ctx, &budgets[i], now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// evaluate computes a budget's usage in the period containing now.
+func (s *BudgetService) evaluate(ctx context.Context, b *LLMBudget, now time.Time) (*BudgetStatus, error) {
+	start, end := budgetPeriod(b.Period, now)
+	usage, err := s.repo.SumBudgetUsage(ctx, b, start)
+	if err != nil {
+		return nil, err
+	}
+	return budgetStatus(b, start, end, usage.Tokens, usage.CostUSD), nil
+}
+
+// budgetStatus classifies usage against a budget's limits.
+func budgetStatus(b *LLMBudget, start, end time.Time, tokens int64, cost float64) *BudgetStatus {
+	status := &BudgetStatus{
+		Budget:      *b,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		TokensUsed:  tokens,
+		CostUsedUSD: cost,
+		State:       BudgetStateOK,
+	}
+	var highest float64
+	if b.TokenLimit != nil && *b.TokenLimit > 0 {
+		pct := float64(tokens) / float64(*b.TokenLimit) * 100
+		status.TokenPercent = &pct
+		highest = pct
+	}
+	if b.CostLimitUSD != nil && *b.CostLimitUSD > 0 {
+		pct := cost / *b.CostLimitUSD * 100
+		status.CostPercent = &pct
+		if pct > highest {
+			highest = pct
+		}
+	}
+	switch {
+	case highest >= 100:
+		status.State = BudgetStateExceeded
+	case b.SoftLimitPercent > 0 && highest >= float64(b.SoftLimitPercent):
+		status.State = BudgetStateSoftLimit
+	}
+	return status
+}
+
+// budgetPeriod returns the UTC day or month containing now.
+func budgetPeriod(period BudgetPeriod, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	if period == BudgetPeriodDaily {
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// exceededError describes the limit an exceeded budget has reached.
+func exceededError(status *BudgetStatus) *adk.BudgetExceededError {
+	b := status.Budget
+	err := &adk.BudgetExceededError{Scope: string(b.Scope), Period: string(b.Period)}
+	if status.CostPercent != nil && *status.CostPercent >= 100 {
+		err.Metric = "cost"
+		err.Limit = *b.CostLimitUSD
+		err.Used = status.CostUsedUSD
+		return err
+	}
+	err.Metric = "tokens"
+	err.Limit = float64(*b.TokenLimit)
+	err.Used = float64(status.TokensUsed)
+	return err
+}
+
+// notify sends a soft or hard limit notification to the budget's admins,
+// once per period and level.
+func (s *BudgetService) notify(ctx context.Context, status *BudgetStatus, level string) {
+	b := status.Budget
+	first, err := s.repo.MarkBudgetNotified(ctx, b.ID, status.PeriodStart, level)
+	if err != nil || !first {
+		return
+	}
+
+	recipients, err := s.repo.ListBudgetRecipients(ctx, b.OrgID, b.ProjectID)
+	if err != nil {
+		s.log.Warn("failed to list budget notification recipients", logger.Error(err), slog.String("budget_id", b.ID))
+		return
+	}
+
+	title := fmt.Sprintf("LLM budget at %d%%", b.SoftLimitPercent)
+	message := fmt.Sprintf("The %s %s LLM budget has passed %d%% of its limit.", b.Period, b.Scope, b.SoftLimitPercent)
+	severity := "warning"
+	if level == "hard" {
+		title = "LLM budget exceeded"
+		message = exceededError(status).Error() + ". LLM calls are blocked until the period ends."
+		severity = "error"
+	}
+
+	notifType := "llm_budget"
+	sourceType := "llm_budget"
+	groupKey := "llm_budget:" + b.ID
+	for _, userID := range recipients {
+		n := &notifications.Notification{
+			ProjectID:  b.ProjectID,
+			UserID:     userID,
+			Title:      title,
+			Message:    message,
+			Type:       &notifType,
+			Severity:   severity,
+			Importance: "important",
+			SourceType: &sourceType,
+			SourceID:   &b.ID,
+			GroupKey:   &groupKey,
+		}
+		if err := s.repo.CreateNotification(ctx, n); err != nil {
+			s.log.Warn("failed to create budget notification", logger.Error(err), slog.String("user_id", userID))
+		}
+	}
+
+	s.log.Info("budget limit notification sent",
+		slog.String("budget_id", b.ID),
+		slog.String("level", level),
+		slog.Int("recipients", len(recipients)))
+}
+
+// Create validates and stores a new budget for an organization.
+func (s *BudgetService) Create(ctx context.Context, orgID string, req UpsertBudgetRequest) (*LLMBudget, error) {
+	b := &LLMBudget{
+		OrgID:            orgID,
+		Scope:            BudgetScopeOrg,
+		Period:           req.Period,
+		SoftLimitPercent: 80,
+		Enabled:          true,
+	}
+	if req.Period != BudgetPeriodDaily && req.Period != BudgetPeriodMonthly {
+		return nil, apperror.ErrBadRequest.WithMessage("period must be daily or monthly")
+	}
+
+	if req.AgentDefinitionID != nil && *req.AgentDefinitionID != "" {
+		projectID, err := s.repo.AgentDefinitionProjectID(ctx, *req.AgentDefinitionID)
+		if err != nil {
+			return nil, err
+		}
+		if projectID == "" {
+			return nil, apperror.NewNotFound("agent definition", *req.AgentDefinitionID)
+		}
+		if req.ProjectID != nil && *req.ProjectID != "" && *req.ProjectID != projectID {
+			return nil, apperror.ErrBadRequest.WithMessage("agent definition does not belong to projectId")
+		}
+		b.Scope = BudgetScopeAgent
+		b.AgentDefinitionID = req.AgentDefinitionID
+		b.ProjectID = &projectID
+	} else if req.ProjectID != nil && *req.ProjectID != "" {
+		b.Scope = BudgetScopeProject
+		b.ProjectID = req.ProjectID
+	}
+	if b.ProjectID != nil {
+		projectOrgID, err := s.repo.GetOrgIDForProject(ctx, *b.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if projectOrgID != orgID {
+			return nil, apperror.ErrForbidden.WithMessage("project does not belong to this organization")
+		}
+	}
+
+	if err := applyBudgetLimits(b, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBudget(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Update changes a budget's limits, soft limit or enabled flag.
+func (s *BudgetService) Update(ctx context.Context, orgID, id string, req UpsertBudgetRequest) (*LLMBudget, error) {
+	b, err := s.repo.GetBudget(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, apperror.NewNotFound("budget", id)
+	}
+	if req.Period != "" && req.Period != b.Period {
+		return nil, apperror.ErrBadRequest.WithMessage("a budget's period cannot be changed")
+	}
+	if req.TokenLimit == nil && req.CostLimitUSD == nil {
+		req.TokenLimit, req.CostLimitUSD = b.TokenLimit, b.CostLimitUSD
+	}
+	if err := applyBudgetLimits(b, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBudget(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// applyBudgetLimits validates and copies a request's limits onto b.
+func applyBudgetLimits(b *LLMBudget, req UpsertBudgetRequest) error {
+	if req.TokenLimit == nil && req.CostLimitUSD == nil {
+		return apperror.ErrBadRequest.WithMessage("tokenLimit or costLimitUsd is required")
+	}
+	if req.TokenLimit != nil && *req.TokenLimit <= 0 {
+		return apperror.ErrBadRequest.WithMessage("tokenLimit must be positive")
+	}
+	if req.CostLimitUSD != nil && *req.CostLimitUSD <= 0 {
+		return apperror.ErrBadRequest.WithMessage("costLimitUsd must be positive")
+	}
+	b.TokenLimit = req.TokenLimit
+	b.CostLimitUSD = req.CostLimitUSD
+	if req.SoftLimitPercent != nil {
+		if *req.SoftLimitPercent < 0 || *req.SoftLimitPercent > 100 {
+			return apperror.ErrBadRequest.WithMessage("softLimitPercent must be between 0 and 100")
+		}
+		b.SoftLimitPercent = *req.SoftLimitPercent
+	}
+	if req.Enabled != nil {
+		b.Enabled = *req.Enabled
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/emergent-company/emergent.memory/pkg/adk"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	tokens := func(n int64) *int64 { return &n }
+	cost := func(n float64) *float64 { return &n }
+
+	tests := []struct {
+		name       string
+		budget     LLMBudget
+		tokensUsed int64
+		costUsed   float64
+		want       BudgetState
+	}{
+		{
+			name:       "under soft limit",
+			budget:     LLMBudget{TokenLimit: tokens(1000), SoftLimitPercent: 80},
+			tokensUsed: 500,
+			want:       BudgetStateOK,
+		},
+		{
+			name:       "at soft limit",
+			budget:     LLMBudget{TokenLimit: tokens(1000), SoftLimitPercent: 80},
+			tokensUsed: 800,
+			want:       BudgetStateSoftLimit,
+		},
+		{
+			name:       "soft limit disabled",
+			budget:     LLMBudget{TokenLimit: tokens(1000), SoftLimitPercent: 0},
+			tokensUsed: 990,
+			want:       BudgetStateOK,
+		},
+		{
+			name:       "token limit reached",
+			budget:     LLMBudget{TokenLimit: tokens(1000), SoftLimitPercent: 80},
+			tokensUsed: 1000,
+			want:       BudgetStateExceeded,
+		},
+		{
+			name:       "cost limit reached before token limit",
+			budget:     LLMBudget{TokenLimit: tokens(1000), CostLimitUSD: cost(1), SoftLimitPercent: 80},
+			tokensUsed: 100,
+			costUsed:   1.5,
+			want:       BudgetStateExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := budgetStatus(&tt.budget, time.Time{}, time.Time{}, tt.tokensUsed, tt.costUsed)
+			if got.State != tt.want {
+				t.Errorf("state = %s, want %s", got.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetPeriod(t *testing.T) {
+	now := time.Date(2026, 3, 15, 18, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	start, end := budgetPeriod(BudgetPeriodDaily, now)
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("daily start = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("daily end = %v, want %v", end, want)
+	}
+
+	// 18:30 EST is 23:30 UTC, still the 15th; one more hour crosses into the 16th.
+	start, _ = budgetPeriod(BudgetPeriodDaily, now.Add(time.Hour))
+	if want := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("daily start after midnight UTC = %v, want %v", start, want)
+	}
+
+	start, end = budgetPeriod(BudgetPeriodMonthly, now)
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("monthly start = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("monthly end = %v, want %v", end, want)
+	}
+}
+
+func TestExceededError(t *testing.T) {
+	tokenLimit := int64(1000)
+	costLimit := 2.0
+	b := &LLMBudget{Scope: BudgetScopeAgent, Period: BudgetPeriodDaily, TokenLimit: &tokenLimit, CostLimitUSD: &costLimit, SoftLimitPercent: 80}
+
+	err := exceededError(budgetStatus(b, time.Time{}, time.Time{}, 1200, 0.5))
+	if err.Metric != "tokens" || err.Used != 1200 || err.Limit != 1000 {
+		t.Errorf("unexpected token error: %+v", err)
+	}
+	if got, want := err.Error(), "daily agent LLM budget exceeded: 1200 tokens used of 1000 limit"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	err = exceededError(budgetStatus(b, time.Time{}, time.Time{}, 10, 2.5))
+	if got, want := err.Error(), "daily agent LLM budget exceeded: $2.50 used of $2.00 limit"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	var target *adk.BudgetExceededError
+	if !errors.As(error(err), &target) {
+		t.Error("exceededError should match *adk.BudgetExceededError")
+	}
+}
+
+func TestUsageAttribution(t *testing.T) {
+	ctx := context.Background()
+	if got := UsageAttributionFromContext(ctx); got != (UsageAttribution{}) {
+		t.Errorf("expected zero attribution, got %+v", got)
+	}
+
+	want := UsageAttribution{AgentID: "a", AgentDefinitionID: "d", RunID: "r"}
+	if got := UsageAttributionFromContext(WithUsageAttribution(ctx, want)); got != want {
+		t.Errorf("attribution = %+v, want %+v", got, want)
+	}
+}
+
+func TestBudgetService_NilCheckAllowsCall(t *testing.T) {
+	var s *BudgetService
+	if err := s.Check(context.Background()); err != nil {
+		t.Errorf("nil BudgetService should allow calls, got %v", err)
+	}
+}
@@ -30,6 +30,23 @@ const (
 	OperationEmbed    OperationType = "embed"
 )
 
+// BudgetScope identifies what an LLM budget limits.
+type BudgetScope string
+
+const (
+	BudgetScopeOrg     BudgetScope = "org"
+	BudgetScopeProject BudgetScope = "project"
+	BudgetScopeAgent   BudgetScope = "agent" // one agent definition
+)
+
+// BudgetPeriod is the window an LLM budget's usage is counted over.
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   BudgetPeriod = "daily"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+)
+
 // --- Bun entities mapping to migration tables ---
 
 // OrgProviderConfig stores encrypted credentials and model selections for a
@@ -83,6 +100,19 @@ type UpsertProviderConfigRequest struct {
 	EmbeddingModel     string `json:"embeddingModel,omitempty"`
 }
 
+// UpsertBudgetRequest creates or updates an LLM budget. The scope follows
+// from the target: an agent definition, a project, or (neither) the whole org.
+// Scope and period cannot be changed on update.
+type UpsertBudgetRequest struct {
+	ProjectID         *string      `json:"projectId,omitempty"`
+	AgentDefinitionID *string      `json:"agentDefinitionId,omitempty"`
+	Period            BudgetPeriod `json:"period"`
+	TokenLimit        *int64       `json:"tokenLimit,omitempty"`
+	CostLimitUSD      *float64     `json:"costLimitUsd,omitempty"`
+	SoftLimitPercent  *int         `json:"softLimitPercent,omitempty"` // default 80; 0 disables soft notifications
+	Enabled           *bool        `json:"enabled,omitempty"`
+}
+
 // ProviderConfigResponse is the public-safe representation of a stored provider config.
 // Credential fields (APIKey, ServiceAccountJSON) are never returned.
 type ProviderConfigResponse struct {
@@ -126,7 +156,11 @@ type LLMUsageEvent struct {
 	AudioInputTokens int64         `bun:"audio_input_tokens,notnull,default:0" json:"audioInputTokens"`
 	OutputTokens     int64         `bun:"output_tokens,notnull,default:0" json:"outputTokens"`
 	EstimatedCostUSD float64       `bun:"estimated_cost_usd,notnull,default:0" json:"estimatedCostUsd"`
-	CreatedAt        time.Time     `bun:"created_at,notnull,default:now()" json:"createdAt"`
+	// Agent attribution (migration 00048); nil outside agent runs.
+	AgentID           *string   `bun:"agent_id,type:uuid" json:"agentId,omitempty"`
+	AgentDefinitionID *string   `bun:"agent_definition_id,type:uuid" json:"agentDefinitionId,omitempty"`
+	RunID             *string   `bun:"run_id,type:uuid" json:"runId,omitempty"`
+	CreatedAt         time.Time `bun:"created_at,notnull,default:now()" json:"createdAt"`
 }
 
 // ProviderPricing stores global retail pricing per model (synced daily).
@@ -164,3 +198,25 @@ type OrganizationCustomPricing struct {
 	CreatedAt       time.Time    `bun:"created_at,notnull,default:now()" json:"createdAt"`
 	UpdatedAt       time.Time    `bun:"updated_at,notnull,default:now()" json:"updatedAt"`
 }
+
+// LLMBudget limits LLM tokens and/or estimated cost per day or month for an
+// organization, a project or an agent definition.
+// Table: kb.llm_budgets (migration 00048)
+type LLMBudget struct {
+	bun.BaseModel `bun:"table:kb.llm_budgets,alias:lb"`
+
+	ID                string       `bun:"id,pk,type:uuid,default:gen_random_uuid()" json:"id"`
+	OrgID             string       `bun:"org_id,notnull,type:uuid" json:"orgId"`
+	Scope             BudgetScope  `bun:"scope,notnull" json:"scope"`
+	ProjectID         *string      `bun:"project_id,type:uuid" json:"projectId,omitempty"`
+	AgentDefinitionID *string      `bun:"agent_definition_id,type:uuid" json:"agentDefinitionId,omitempty"`
+	Period            BudgetPeriod `bun:"period,notnull" json:"period"`
+	TokenLimit        *int64       `bun:"token_limit" json:"tokenLimit,omitempty"`
+	CostLimitUSD      *float64     `bun:"cost_limit_usd" json:"costLimitUsd,omitempty"`
+	SoftLimitPercent  int          `bun:"soft_limit_percent,notnull,default:80" json:"softLimitPercent"`
+	Enabled           bool         `bun:"enabled,notnull,default:true" json:"enabled"`
+	NotifiedPeriod    *time.Time   `bun:"notified_period" json:"-"`
+	NotifiedLevel     *string      `bun:"notified_level" json:"-"`
+	CreatedAt         time.Time    `bun:"created_at,notnull,default:now()" json:"createdAt"`
+	UpdatedAt         time.Time    `bun:"updated_at,notnull,default:now()" json:"updatedAt"`
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -15,11 +16,12 @@ type Handler struct {
 	creds   *CredentialService
 	catalog *ModelCatalogService
 	repo    *Repository
+	budgets *BudgetService
 }
 
 // NewHandler creates a new provider handler.
-func NewHandler(creds *CredentialService, catalog *ModelCatalogService, repo *Repository) *Handler {
-	return &Handler{creds: creds, catalog: catalog, repo: repo}
+func NewHandler(creds *CredentialService, catalog *ModelCatalogService, repo *Repository, budgets *BudgetService) *Handler {
+	return &Handler{creds: creds, catalog: catalog, repo: repo, budgets: budgets}
 }
 
 // --- Organization Provider Config Endpoints ---
@@ -311,6 +313,74 @@ func (h *Handler) GetOrgUsageSummary(c echo.Context) error {
 	})
 }
 
+// GetProjectUsageByAgent returns token usage and estimated costs per agent for a project.
+// @Summary Get project LLM usage per agent
+// @Param projectId path string true "Project ID"
+// @Param since query string false "Start time (RFC3339)"
+// @Param until query string false "End time (RFC3339)"
+// @Success 200 {object} AgentUsageResponse
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Router /projects/{projectId}/usage/agents [get]
+func (h *Handler) GetProjectUsageByAgent(c echo.Context) error {
+	projectID := c.Param("projectId")
+	ctx := c.Request().Context()
+
+	if err := h.creds.assertCallerOwnsProject(ctx, projectID); err != nil {
+		return err
+	}
+
+	since, until := parseTimeRange(c)
+	rows, err := h.repo.GetUsageByAgent(ctx, "", projectID, since, until)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, AgentUsageResponse{
+		Note: "Costs shown are estimates based on retail pricing and may not reflect your actual provider invoice.",
+		Data: rows,
+	})
+}
+
+// GetOrgUsageByAgent returns token usage and estimated costs per agent for an organization.
+// @Summary Get org LLM usage per agent
+// @Param orgId path string true "Organization ID"
+// @Param since query string false "Start time (RFC3339)"
+// @Param until query string false "End time (RFC3339)"
+// @Success 200 {object} AgentUsageResponse
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Router /organizations/{orgId}/usage/agents [get]
+func (h *Handler) GetOrgUsageByAgent(c echo.Context) error {
+	orgID := c.Param("orgId")
+
+	ctx := c.Request().Context()
+	if auth.OrgIDFromContext(ctx) == "" {
+		ctx = auth.ContextWithOrgID(ctx, orgID)
+	}
+
+	if err := assertCallerOwnsOrg(ctx, orgID); err != nil {
+		return err
+	}
+
+	since, until := parseTimeRange(c)
+	rows, err := h.repo.GetUsageByAgent(ctx, orgID, "", since, until)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, AgentUsageResponse{
+		Note: "Costs shown are estimates based on retail pricing and may not reflect your actual provider invoice.",
+		Data: rows,
+	})
+}
+
+// AgentUsageResponse wraps per-agent usage rows with a note that costs are estimates.
+type AgentUsageResponse struct {
+	Note string          `json:"note"`
+	Data []AgentUsageRow `json:"data"`
+}
+
 // UsageSummaryResponse wraps usage rows with a note that costs are estimates.
 type UsageSummaryResponse struct {
 	Note string            `json:"note"`
@@ -386,3 +456,176 @@ func (h *Handler) TestProvider(c echo.Context) error {
 		LatencyMs: time.Since(start).Milliseconds(),
 	})
 }
+
+// --- LLM Budgets ---
+
+// orgContext injects orgID into the request context when X-Org-ID is absent
+// and checks that the caller belongs to that org.
+func orgContext(c echo.Context, orgID string) (context.Context, error) {
+	ctx := c.Request().Context()
+	if auth.OrgIDFromContext(ctx) == "" {
+		ctx = auth.ContextWithOrgID(ctx, orgID)
+	}
+	if err := assertCallerOwnsOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// ListBudgets returns all LLM budgets of an organization.
+// @Summary List LLM budgets
+// @Param orgId path string true "Organization ID"
+// @Success 200 {array} LLMBudget
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Router /organizations/{orgId}/budgets [get]
+func (h *Handler) ListBudgets(c echo.Context) error {
+	orgID := c.Param("orgId")
+	ctx, err := orgContext(c, orgID)
+	if err != nil {
+		return err
+	}
+
+	budgets, err := h.repo.ListBudgets(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, budgets)
+}
+
+// CreateBudget adds an org, project or agent-definition LLM budget.
+// @Summary Create an LLM budget
+// @Param orgId path string true "Organization ID"
+// @Param body body UpsertBudgetRequest true "Budget"
+// @Success 201 {object} LLMBudget
+// @Failure 400 {object} apperror.Error
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Failure 409 {object} apperror.Error
+// @Router /organizations/{orgId}/budgets [post]
+func (h *Handler) CreateBudget(c echo.Context) error {
+	orgID := c.Param("orgId")
+	ctx, err := orgContext(c, orgID)
+	if err != nil {
+		return err
+	}
+
+	var req UpsertBudgetRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.ErrBadRequest.WithMessage("invalid request body")
+	}
+
+	budget, err := h.budgets.Create(ctx, orgID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, budget)
+}
+
+// UpdateBudget changes an LLM budget's limits, soft limit or enabled flag.
+// @Summary Update an LLM budget
+// @Param orgId path string true "Organization ID"
+// @Param budgetId path string true "Budget ID"
+// @Param body body UpsertBudgetRequest true "Budget"
+// @Success 200 {object} LLMBudget
+// @Failure 400 {object} apperror.Error
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Failure 404 {object} apperror.Error
+// @Router /organizations/{orgId}/budgets/{budgetId} [put]
+func (h *Handler) UpdateBudget(c echo.Context) error {
+	orgID := c.Param("orgId")
+	ctx, err := orgContext(c, orgID)
+	if err != nil {
+		return err
+	}
+
+	var req UpsertBudgetRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.ErrBadRequest.WithMessage("invalid request body")
+	}
+
+	budget, err := h.budgets.Update(ctx, orgID, c.Param("budgetId"), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudget removes an LLM budget.
+// @Summary Delete an LLM budget
+// @Param orgId path string true "Organization ID"
+// @Param budgetId path string true "Budget ID"
+// @Success 204
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Failure 404 {object} apperror.Error
+// @Router /organizations/{orgId}/budgets/{budgetId} [delete]
+func (h *Handler) DeleteBudget(c echo.Context) error {
+	orgID := c.Param("orgId")
+	ctx, err := orgContext(c, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.repo.DeleteBudget(ctx, orgID, c.Param("budgetId")); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetOrgBudgetStatus returns every budget of an organization with its usage in the current period.
+// @Summary Get org LLM budget status
+// @Param orgId path string true "Organization ID"
+// @Success 200 {array} BudgetStatus
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Router /organizations/{orgId}/budgets/status [get]
+func (h *Handler) GetOrgBudgetStatus(c echo.Context) error {
+	orgID := c.Param("orgId")
+	ctx, err := orgContext(c, orgID)
+	if err != nil {
+		return err
+	}
+
+	budgets, err := h.repo.ListBudgets(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	statuses, err := h.budgets.Statuses(ctx, budgets)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, statuses)
+}
+
+// GetProjectBudgetStatus returns the budgets that apply to a project (its
+// org's, its own and its agent definitions') with their current usage.
+// @Summary Get project LLM budget status
+// @Param projectId path string true "Project ID"
+// @Success 200 {array} BudgetStatus
+// @Failure 401 {object} apperror.Error
+// @Failure 403 {object} apperror.Error
+// @Router /projects/{projectId}/budgets/status [get]
+func (h *Handler) GetProjectBudgetStatus(c echo.Context) error {
+	projectID := c.Param("projectId")
+	ctx := c.Request().Context()
+
+	if err := h.creds.assertCallerOwnsProject(ctx, projectID); err != nil {
+		return err
+	}
+
+	orgID, err := h.repo.GetOrgIDForProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	budgets, err := h.repo.ListProjectBudgets(ctx, orgID, projectID)
+	if err != nil {
+		return err
+	}
+	statuses, err := h.budgets.Statuses(ctx, budgets)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, statuses)
+}
@@ -20,8 +20,10 @@ import (
 //   - *CredentialService            — credential resolution hierarchy (Project → Org → Env)
 //   - *ModelCatalogService          — model catalog with API fetch + static fallback
 //   - *UsageService                 — async LLM usage event recording
+//   - *BudgetService                — LLM token/cost budget enforcement
 //   - *PricingSyncService           — daily pricing sync cron job
 //   - adk.CredentialResolver        — adapts CredentialService to pkg/adk interface
+//   - adk.UsageTracker              — wraps ADK models with usage tracking and budgets
 //   - embeddings.EmbeddingResolver  — adapts CredentialService to pkg/embeddings interface
 var Module = fx.Module("provider",
 	fx.Provide(
@@ -30,8 +32,10 @@ var Module = fx.Module("provider",
 		provideCredentialService,
 		provideModelCatalogService,
 		provideUsageService,
+		provideBudgetService,
 		providePricingSyncService,
 		provideADKCredentialAdapter,
+		provideADKUsageTracker,
 		provideEmbeddingCredentialAdapter,
 		NewHandler,
 	),
@@ -61,6 +65,10 @@ func provideUsageService(lc fx.Lifecycle, repo *Repository, log *slog.Logger) *U
 	return NewUsageService(lc, repo, log)
 }
 
+func provideBudgetService(repo *Repository, log *slog.Logger) *BudgetService {
+	return NewBudgetService(repo, log)
+}
+
 func providePricingSyncService(repo *Repository, sched *scheduler.Scheduler, log *slog.Logger) *PricingSyncService {
 	return NewPricingSyncService(repo, sched, log)
 }
@@ -88,6 +96,12 @@ func provideADKCredentialAdapter(svc *CredentialService) adk.CredentialResolver
 	return NewADKCredentialAdapter(svc)
 }
 
+// provideADKUsageTracker exposes usage tracking and budget enforcement as
+// adk.UsageTracker. Consumed by the adk.Module to wrap every model it creates.
+func provideADKUsageTracker(usage *UsageService, budgets *BudgetService, log *slog.Logger) adk.UsageTracker {
+	return NewADKUsageTracker(usage, budgets, log)
+}
+
 // provideEmbeddingCredentialAdapter exposes CredentialService as embeddings.EmbeddingResolver
 // via the EmbeddingCredentialAdapter. Consumed by embeddings.Module.
 func provideEmbeddingCredentialAdapter(svc *CredentialService) embeddings.EmbeddingResolver {
@@ -8,8 +8,10 @@ import (
 
 	"github.com/uptrace/bun"
 
+	"github.com/emergent-company/emergent.memory/domain/notifications"
 	"github.com/emergent-company/emergent.memory/pkg/apperror"
 	"github.com/emergent-company/emergent.memory/pkg/logger"
+	"github.com/emergent-company/emergent.memory/pkg/pgutils"
 )
 
 // Repository handles database operations for the provider domain.
@@ -346,14 +348,14 @@ func (r *Repository) GetOrgCustomPricing(ctx context.Context, orgID string, prov
 
 // UsageSummaryRow is a single row from an aggregated usage query.
 type UsageSummaryRow struct {
-	Provider         ProviderType `bun:"provider"`
-	Model            string       `bun:"model"`
-	TotalText        int64        `bun:"total_text"`
-	TotalImage       int64        `bun:"total_image"`
-	TotalVideo       int64        `bun:"total_video"`
-	TotalAudio       int64        `bun:"total_audio"`
-	TotalOutput      int64        `bun:"total_output"`
-	EstimatedCostUSD float64      `bun:"estimated_cost_usd"`
+	Provider         ProviderType `bun:"provider" json:"provider"`
+	Model            string       `bun:"model" json:"model"`
+	TotalText        int64        `bun:"total_text" json:"total_text"`
+	TotalImage       int64        `bun:"total_image" json:"total_image"`
+	TotalVideo       int64        `bun:"total_video" json:"total_video"`
+	TotalAudio       int64        `bun:"total_audio" json:"total_audio"`
+	TotalOutput      int64        `bun:"total_output" json:"total_output"`
+	EstimatedCostUSD float64      `bun:"estimated_cost_usd" json:"estimated_cost_usd"`
 }
 
 // GetProjectUsageSummary returns aggregated usage for a project grouped by provider + model.
@@ -416,6 +418,53 @@ func (r *Repository) GetOrgUsageSummary(ctx context.Context, orgID string, since
 	return rows, nil
 }
 
+// AgentUsageRow is usage aggregated per agent. Usage outside agent runs is
+// reported in a row with a nil AgentID.
+type AgentUsageRow struct {
+	AgentID           *string `bun:"agent_id" json:"agent_id"`
+	AgentName         *string `bun:"agent_name" json:"agent_name"`
+	AgentDefinitionID *string `bun:"agent_definition_id" json:"agent_definition_id"`
+	Runs              int64   `bun:"runs" json:"runs"`
+	TotalInput        int64   `bun:"total_input" json:"total_input"`
+	TotalOutput       int64   `bun:"total_output" json:"total_output"`
+	EstimatedCostUSD  float64 `bun:"estimated_cost_usd" json:"estimated_cost_usd"`
+}
+
+// GetUsageByAgent returns usage grouped by agent for an org, or for one
+// project when projectID is set, ordered by estimated cost.
+func (r *Repository) GetUsageByAgent(ctx context.Context, orgID, projectID string, since, until *time.Time) ([]AgentUsageRow, error) {
+	var rows []AgentUsageRow
+	q := r.db.NewSelect().
+		TableExpr("kb.llm_usage_events AS lue").
+		Join("LEFT JOIN kb.agents AS a ON a.id = lue.agent_id").
+		ColumnExpr("lue.agent_id, a.name AS agent_name, lue.agent_definition_id").
+		ColumnExpr("COUNT(DISTINCT lue.run_id) AS runs").
+		ColumnExpr("SUM(lue.text_input_tokens + lue.image_input_tokens + lue.video_input_tokens + lue.audio_input_tokens) AS total_input").
+		ColumnExpr("SUM(lue.output_tokens) AS total_output").
+		ColumnExpr("SUM(lue.estimated_cost_usd) AS estimated_cost_usd").
+		GroupExpr("lue.agent_id, a.name, lue.agent_definition_id").
+		OrderExpr("estimated_cost_usd DESC")
+
+	if projectID != "" {
+		q = q.Where("lue.project_id = ?", projectID)
+	} else {
+		q = q.Where("lue.org_id = ?", orgID)
+	}
+	if since != nil {
+		q = q.Where("lue.created_at >= ?", *since)
+	}
+	if until != nil {
+		q = q.Where("lue.created_at <= ?", *until)
+	}
+
+	if err := q.Scan(ctx, &rows); err != nil {
+		r.log.Error("failed to get usage by agent", logger.Error(err),
+			slog.String("orgID", orgID), slog.String("projectID", projectID))
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return rows, nil
+}
+
 // GetOrgIDForProject looks up the organization ID for a given project.
 func (r *Repository) GetOrgIDForProject(ctx context.Context, projectID string) (string, error) {
 	var orgID string
@@ -437,3 +486,221 @@ func (r *Repository) GetOrgIDForProject(ctx context.Context, projectID string) (
 	}
 	return orgID, nil
 }
+
+// --- LLM Budgets ---
+
+// ListBudgets returns all budgets of an organization.
+func (r *Repository) ListBudgets(ctx context.Context, orgID string) ([]LLMBudget, error) {
+	var budgets []LLMBudget
+	err := r.db.NewSelect().
+		Model(&budgets).
+		Where("org_id = ?", orgID).
+		OrderExpr("scope, created_at").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error("failed to list budgets", logger.Error(err), slog.String("orgID", orgID))
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return budgets, nil
+}
+
+// GetBudget returns a budget of an organization, or nil when not found.
+func (r *Repository) GetBudget(ctx context.Context, orgID, id string) (*LLMBudget, error) {
+	var budget LLMBudget
+	err := r.db.NewSelect().
+		Model(&budget).
+		Where("org_id = ?", orgID).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log.Error("failed to get budget", logger.Error(err), slog.String("id", id))
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return &budget, nil
+}
+
+// ListApplicableBudgets returns the enabled budgets that limit usage in a
+// project: the org's budgets, the project's, and, when agentDefinitionID is
+// set, the agent definition's.
+func (r *Repository) ListApplicableBudgets(ctx context.Context, orgID, projectID, agentDefinitionID string) ([]LLMBudget, error) {
+	var budgets []LLMBudget
+	q := r.db.NewSelect().
+		Model(&budgets).
+		Where("org_id = ?", orgID).
+		Where("enabled = true")
+	q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		q = q.Where("scope = ?", BudgetScopeOrg)
+		if projectID != "" {
+			q = q.WhereOr("scope = ? AND project_id = ?", BudgetScopeProject, projectID)
+		}
+		if agentDefinitionID != "" {
+			q = q.WhereOr("scope = ? AND agent_definition_id = ?", BudgetScopeAgent, agentDefinitionID)
+		}
+		return q
+	})
+	if err := q.OrderExpr("scope, created_at").Scan(ctx); err != nil {
+		r.log.Error("failed to list applicable budgets", logger.Error(err), slog.String("orgID", orgID))
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return budgets, nil
+}
+
+// ListProjectBudgets returns the org, project and agent budgets that apply
+// to anything in a project.
+func (r *Repository) ListProjectBudgets(ctx context.Context, orgID, projectID string) ([]LLMBudget, error) {
+	var budgets []LLMBudget
+	err := r.db.NewSelect().
+		Model(&budgets).
+		Where("org_id = ?", orgID).
+		Where("scope = ? OR project_id = ?", BudgetScopeOrg, projectID).
+		OrderExpr("scope, created_at").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error("failed to list project budgets", logger.Error(err), slog.String("projectID", projectID))
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return budgets, nil
+}
+
+// CreateBudget inserts a new budget.
+func (r *Repository) CreateBudget(ctx context.Context, budget *LLMBudget) error {
+	_, err := r.db.NewInsert().Model(budget).Returning("*").Exec(ctx)
+	if err != nil {
+		if pgutils.IsUniqueViolation(err) {
+			return apperror.ErrConflict.WithMessage("a budget for this scope and period already exists")
+		}
+		r.log.Error("failed to create budget", logger.Error(err))
+		return apperror.ErrDatabase.WithInternal(err)
+	}
+	return nil
+}
+
+// UpdateBudget saves a budget's limits and enabled flag.
+func (r *Repository) UpdateBudget(ctx context.Context, budget *LLMBudget) error {
+	budget.UpdatedAt = time.Now()
+	_, err := r.db.NewUpdate().
+		Model(budget).
+		Column("token_limit", "cost_limit_usd", "soft_limit_percent", "enabled", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		r.log.Error("failed to update budget", logger.Error(err), slog.String("id", budget.ID))
+		return apperror.ErrDatabase.WithInternal(err)
+	}
+	return nil
+}
+
+// DeleteBudget removes a budget of an organization.
+func (r *Repository) DeleteBudget(ctx context.Context, orgID, id string) error {
+	res, err := r.db.NewDelete().
+		Model((*LLMBudget)(nil)).
+		Where("org_id = ?", orgID).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		r.log.Error("failed to delete budget", logger.Error(err), slog.String("id", id))
+		return apperror.ErrDatabase.WithInternal(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return apperror.NewNotFound("budget", id)
+	}
+	return nil
+}
+
+// budgetUsage is the usage counted against a budget.
+type budgetUsage struct {
+	Tokens  int64   `bun:"tokens"`
+	CostUSD float64 `bun:"cost_usd"`
+}
+
+// SumBudgetUsage returns the tokens and estimated cost counted against a
+// budget since the start of its current period.
+func (r *Repository) SumBudgetUsage(ctx context.Context, budget *LLMBudget, since time.Time) (*budgetUsage, error) {
+	usage := new(budgetUsage)
+	q := r.db.NewSelect().
+		TableExpr("kb.llm_usage_events").
+		ColumnExpr("COALESCE(SUM(text_input_tokens + image_input_tokens + video_input_tokens + audio_input_tokens + output_tokens), 0) AS tokens").
+		ColumnExpr("COALESCE(SUM(estimated_cost_usd), 0) AS cost_usd").
+		Where("org_id = ?", budget.OrgID).
+		Where("created_at >= ?", since)
+	switch budget.Scope {
+	case BudgetScopeProject:
+		q = q.Where("project_id = ?", *budget.ProjectID)
+	case BudgetScopeAgent:
+		q = q.Where("agent_definition_id = ?", *budget.AgentDefinitionID)
+	}
+	if err := q.Scan(ctx, usage); err != nil {
+		r.log.Error("failed to sum budget usage", logger.Error(err), slog.String("budgetID", budget.ID))
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return usage, nil
+}
+
+// MarkBudgetNotified records that a limit notification was sent for the
+// period starting at periodStart. It returns false when one at the same or a
+// higher level was already recorded, so each notification is sent once even
+// with several replicas checking.
+func (r *Repository) MarkBudgetNotified(ctx context.Context, id string, periodStart time.Time, level string) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model((*LLMBudget)(nil)).
+		Set("notified_period = ?", periodStart).
+		Set("notified_level = ?", level).
+		Where("id = ?", id).
+		Where("notified_period IS DISTINCT FROM ? OR (notified_level = 'soft' AND ? = 'hard')", periodStart, level).
+		Exec(ctx)
+	if err != nil {
+		return false, apperror.ErrDatabase.WithInternal(err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListBudgetRecipients returns the users notified about a budget: the org
+// admins, plus the project admins for project and agent budgets.
+func (r *Repository) ListBudgetRecipients(ctx context.Context, orgID string, projectID *string) ([]string, error) {
+	var userIDs []string
+	q := r.db.NewSelect().
+		TableExpr("kb.organization_memberships").
+		ColumnExpr("user_id").
+		Where("organization_id = ?", orgID).
+		Where("role = 'org_admin'")
+	if projectID != nil {
+		q = q.UnionAll(r.db.NewSelect().
+			TableExpr("kb.project_memberships").
+			ColumnExpr("user_id").
+			Where("project_id = ?", *projectID).
+			Where("role = 'project_admin'"))
+	}
+	if err := q.Scan(ctx, &userIDs); err != nil {
+		return nil, apperror.ErrDatabase.WithInternal(err)
+	}
+	return userIDs, nil
+}
+
+// CreateNotification inserts a notification directly into kb.notifications.
+// This is a cross-domain insert used for budget limit notifications.
+func (r *Repository) CreateNotification(ctx context.Context, n *notifications.Notification) error {
+	_, err := r.db.NewInsert().Model(n).Exec(ctx)
+	return err
+}
+
+// AgentDefinitionProjectID returns the project of an agent definition, or ""
+// when it does not exist.
+func (r *Repository) AgentDefinitionProjectID(ctx context.Context, id string) (string, error) {
+	var projectID string
+	err := r.db.NewSelect().
+		TableExpr("kb.agent_definitions").
+		Column("project_id").
+		Where("id = ?", id).
+		Scan(ctx, &projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", apperror.ErrDatabase.WithInternal(err)
+	}
+	return projectID, nil
+}
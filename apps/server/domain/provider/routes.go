@@ -14,9 +14,17 @@ import (
 //	GET    /api/v1/organizations/:orgId/providers/:provider   — get org config metadata
 //	DELETE /api/v1/organizations/:orgId/providers/:provider   — delete org config
 //	GET    /api/v1/organizations/:orgId/providers             — list org configs
+//	GET    /api/v1/organizations/:orgId/usage/agents          — org usage per agent
+//	GET    /api/v1/organizations/:orgId/budgets               — list LLM budgets
+//	POST   /api/v1/organizations/:orgId/budgets               — create LLM budget
+//	PUT    /api/v1/organizations/:orgId/budgets/:budgetId     — update LLM budget
+//	DELETE /api/v1/organizations/:orgId/budgets/:budgetId     — delete LLM budget
+//	GET    /api/v1/organizations/:orgId/budgets/status        — org budget status
 //	PUT    /api/v1/projects/:projectId/providers/:provider    — upsert project config
 //	GET    /api/v1/projects/:projectId/providers/:provider    — get project config metadata
 //	DELETE /api/v1/projects/:projectId/providers/:provider    — delete project config
+//	GET    /api/v1/projects/:projectId/usage/agents           — project usage per agent
+//	GET    /api/v1/projects/:projectId/budgets/status         — project budget status
 //	GET    /api/v1/providers/:provider/models                 — read-only model catalog
 //	POST   /api/v1/providers/:provider/test                   — live credential test
 func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
@@ -32,6 +40,15 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
 
 	// Org-level usage summary
 	api.GET("/organizations/:orgId/usage", h.GetOrgUsageSummary)
+	api.GET("/organizations/:orgId/usage/agents", h.GetOrgUsageByAgent)
+
+	// Org-level LLM budgets
+	budgets := api.Group("/organizations/:orgId/budgets")
+	budgets.GET("", h.ListBudgets)
+	budgets.POST("", h.CreateBudget)
+	budgets.GET("/status", h.GetOrgBudgetStatus)
+	budgets.PUT("/:budgetId", h.UpdateBudget)
+	budgets.DELETE("/:budgetId", h.DeleteBudget)
 
 	// Project-level provider configs
 	projects := api.Group("/projects/:projectId/providers")
@@ -41,6 +58,8 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware) {
 
 	// Project-level usage summary
 	api.GET("/projects/:projectId/usage", h.GetProjectUsageSummary)
+	api.GET("/projects/:projectId/usage/agents", h.GetProjectUsageByAgent)
+	api.GET("/projects/:projectId/budgets/status", h.GetProjectBudgetStatus)
 
 	// Read-only model catalog
 	api.GET("/providers/:provider/models", h.ListModels)
@@ -30,18 +30,22 @@ type usageRecorder interface {
 //     so they never block the agent execution path.
 //   - Multimodal token counts are extracted from PromptTokensDetails when present,
 //     falling back to PromptTokenCount (text-only) for older response shapes.
+//   - Before each call, LLM budgets are checked; a call over a hard limit fails
+//     with *adk.BudgetExceededError without reaching the provider.
 type TrackingModel struct {
 	inner    adkmodel.LLM
 	usage    usageRecorder
+	budgets  *BudgetService // nil disables budget enforcement
 	provider ProviderType
 	log      *slog.Logger
 }
 
-// NewTrackingModel wraps an existing LLM with usage tracking.
-func NewTrackingModel(inner adkmodel.LLM, usage usageRecorder, provider ProviderType, log *slog.Logger) *TrackingModel {
+// NewTrackingModel wraps an existing LLM with usage tracking and budget checks.
+func NewTrackingModel(inner adkmodel.LLM, usage usageRecorder, budgets *BudgetService, provider ProviderType, log *slog.Logger) *TrackingModel {
 	return &TrackingModel{
 		inner:    inner,
 		usage:    usage,
+		budgets:  budgets,
 		provider: provider,
 		log:      log.With(logger.Scope("provider.tracking")),
 	}
@@ -61,6 +65,10 @@ func (m *TrackingModel) GenerateContent(
 	stream bool,
 ) iter.Seq2[*adkmodel.LLMResponse, error] {
 	return func(yield func(*adkmodel.LLMResponse, error) bool) {
+		if err := m.budgets.Check(ctx); err != nil {
+			yield(nil, err)
+			return
+		}
 		for resp, err := range m.inner.GenerateContent(ctx, req, stream) {
 			if !yield(resp, err) {
 				return
@@ -77,10 +85,14 @@ func (m *TrackingModel) GenerateContent(
 }
 
 // recordUsage asynchronously dispatches an LLMUsageEvent built from the
-// response's UsageMetadata. Project / org IDs are read from the context.
+// response's UsageMetadata. Project / org IDs and the agent run are read from
+// the context.
 func (m *TrackingModel) recordUsage(ctx context.Context, req *adkmodel.LLMRequest, resp *adkmodel.LLMResponse) {
 	projectID := auth.ProjectIDFromContext(ctx)
 	orgID := auth.OrgIDFromContext(ctx)
+	if orgID == "" && projectID != "" && m.budgets != nil {
+		orgID = m.budgets.OrgIDForProject(ctx, projectID)
+	}
 
 	if projectID == "" || orgID == "" {
 		// No tenant context — skip tracking (e.g. background jobs, tests)
@@ -102,6 +114,16 @@ func (m *TrackingModel) recordUsage(ctx context.Context, req *adkmodel.LLMReques
 		OutputTokens: int64(meta.CandidatesTokenCount),
 		CreatedAt:    time.Now().UTC(),
 	}
+	attr := UsageAttributionFromContext(ctx)
+	if attr.AgentID != "" {
+		event.AgentID = &attr.AgentID
+	}
+	if attr.AgentDefinitionID != "" {
+		event.AgentDefinitionID = &attr.AgentDefinitionID
+	}
+	if attr.RunID != "" {
+		event.RunID = &attr.RunID
+	}
 
 	// Extract per-modality prompt tokens when the breakdown is available.
 	// This is present when the model response includes PromptTokensDetails.
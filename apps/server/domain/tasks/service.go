@@ -8,20 +8,37 @@ import (
 	"github.com/emergent-company/emergent.memory/pkg/logger"
 )
 
+// ResolutionHandler applies the side effects of resolving tasks of a given
+// type. Domains that create tasks register a handler so that resolving the
+// task from the inbox acts on the underlying item. The resolution is
+// "accepted", "rejected" or "cancelled". Returning an error leaves the task
+// pending.
+type ResolutionHandler interface {
+	HandleTaskResolution(ctx context.Context, task *Task, resolution, userID string, notes *string) error
+}
+
 // Service handles business logic for tasks
 type Service struct {
-	repo *Repository
-	log  *slog.Logger
+	repo     *Repository
+	log      *slog.Logger
+	handlers map[string]ResolutionHandler
 }
 
 // NewService creates a new tasks service
 func NewService(repo *Repository, log *slog.Logger) *Service {
 	return &Service{
-		repo: repo,
-		log:  log.With(logger.Scope("tasks.svc")),
+		repo:     repo,
+		log:      log.With(logger.Scope("tasks.svc")),
+		handlers: make(map[string]ResolutionHandler),
 	}
 }
 
+// SetResolutionHandler registers the handler for tasks of the given type
+// (called after construction to avoid import cycles with task producers).
+func (s *Service) SetResolutionHandler(taskType string, h ResolutionHandler) {
+	s.handlers[taskType] = h
+}
+
 // GetCountsByProject returns task counts by status for a specific project
 func (s *Service) GetCountsByProject(ctx context.Context, projectID string) (*TaskCounts, error) {
 	return s.repo.GetCountsByProject(ctx, projectID)
@@ -70,10 +87,36 @@ func (s *Service) Resolve(ctx context.Context, projectID, taskID, userID string,
 		return apperror.ErrBadRequest.WithMessage("resolution must be 'accepted' or 'rejected'")
 	}
 
+	if err := s.runResolutionHandler(ctx, projectID, taskID, userID, req.Resolution, req.ResolutionNotes); err != nil {
+		return err
+	}
+
 	return s.repo.Resolve(ctx, projectID, taskID, userID, req.Resolution, req.ResolutionNotes)
 }
 
 // Cancel cancels a pending task
 func (s *Service) Cancel(ctx context.Context, projectID, taskID, userID string) error {
+	if err := s.runResolutionHandler(ctx, projectID, taskID, userID, "cancelled", nil); err != nil {
+		return err
+	}
+
 	return s.repo.Cancel(ctx, projectID, taskID, userID)
 }
+
+// runResolutionHandler invokes the registered handler for a pending task's type, if any.
+func (s *Service) runResolutionHandler(ctx context.Context, projectID, taskID, userID, resolution string, notes *string) error {
+	if len(s.handlers) == 0 {
+		return nil
+	}
+
+	task, err := s.repo.GetByID(ctx, projectID, taskID)
+	if err != nil {
+		return err
+	}
+	h, ok := s.handlers[task.Type]
+	if !ok || task.Status != "pending" {
+		return nil
+	}
+
+	return h.HandleTaskResolution(ctx, task, resolution, userID, notes)
+}
@@ -9,10 +9,14 @@ import (
 	"github.com/emergent-company/emergent.memory/pkg/auth"
 )
 
+// auditProviderIDKey is the Echo context key tool handlers store the workspace's provider ID under.
+const auditProviderIDKey = "workspace_provider_id"
+
 // ToolAuditMiddleware creates Echo middleware that logs tool operations for security and debugging.
-// Logs: operation type, workspace ID, user ID, timestamp, duration, request summary.
+// Logs: operation type, workspace ID, user ID, timestamp, duration, request summary, and the
+// network attempts the workspace's egress proxy blocked during the operation.
 // Does NOT log file contents or command output.
-func ToolAuditMiddleware(log *slog.Logger) echo.MiddlewareFunc {
+func ToolAuditMiddleware(log *slog.Logger, egress *EgressManager) echo.MiddlewareFunc {
 	auditLog := log.With("component", "workspace-tool-audit")
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -34,15 +38,36 @@ func ToolAuditMiddleware(log *slog.Logger) echo.MiddlewareFunc {
 			duration := time.Since(start)
 			status := c.Response().Status
 
+			// Log blocked egress attempts, attributed to this operation
+			tool := extractToolName(toolPath)
+			var blocked []EgressEvent
+			if providerID, ok := c.Get(auditProviderIDKey).(string); ok && egress != nil {
+				blocked = egress.Blocked(providerID, start)
+			}
+			for _, ev := range blocked {
+				auditLog.Warn("workspace egress blocked",
+					"workspace_id", workspaceID,
+					"tool", tool,
+					"user_id", userID,
+					"protocol", ev.Protocol,
+					"host", ev.Host,
+					"reason", ev.Reason,
+					"blocked_at", ev.Time,
+				)
+			}
+
 			// Build audit log entry
 			attrs := []any{
 				"workspace_id", workspaceID,
-				"tool", extractToolName(toolPath),
+				"tool", tool,
 				"method", method,
 				"user_id", userID,
 				"status", status,
 				"duration_ms", duration.Milliseconds(),
 			}
+			if len(blocked) > 0 {
+				attrs = append(attrs, "egress_blocked", len(blocked))
+			}
 
 			if err != nil {
 				attrs = append(attrs, "error", err.Error())
@@ -36,7 +36,8 @@ type AutoProvisioner struct {
 	checkoutSvc   *CheckoutService
 	setupExec     *SetupExecutor
 	warmPool      *WarmPool
-	imageResolver ImageResolver // optional — if nil, falls back to ResolveProviderType()
+	imageResolver ImageResolver  // optional — if nil, falls back to ResolveProviderType()
+	egress        *EgressManager // optional — if nil, network policies use the built-in presets
 	log           *slog.Logger
 }
 
@@ -65,6 +66,11 @@ func (ap *AutoProvisioner) SetImageResolver(resolver ImageResolver) {
 	ap.imageResolver = resolver
 }
 
+// SetEgressManager injects the egress manager whose presets resolve network policies.
+func (ap *AutoProvisioner) SetEgressManager(egress *EgressManager) {
+	ap.egress = egress
+}
+
 // ProvisionForSession provisions a workspace based on an agent definition's workspace config.
 // This is called when an agent session starts.
 //
@@ -250,16 +256,28 @@ func (ap *AutoProvisioner) attemptProvision(
 		}
 	}
 
+	// Resolve the network policy; the repository being checked out is always reachable
+	presets := builtinEgressPresets
+	if ap.egress != nil {
+		presets = ap.egress.presets
+	}
+	egress, err := ResolveEgressPolicy(cfg.Network, repoURL, presets)
+	if err != nil {
+		return nil, err
+	}
+
 	// Select provider — use smart routing based on workspace config
 	requestedProvider := ResolveProviderType(cfg)
 	ap.log.Info("selecting provider with fallback",
 		"requested_provider", requestedProvider,
 		"base_image", cfg.BaseImage,
+		"network_mode", egress.NetworkMode(),
 	)
-	provider, providerType, err := ap.orchestrator.SelectProviderWithFallback(
+	provider, providerType, err := ap.orchestrator.SelectProviderForNetwork(
 		ContainerTypeAgentWorkspace,
 		DeploymentSelfHosted,
 		requestedProvider,
+		egress.NetworkMode(),
 	)
 	if err != nil {
 		ap.log.Error("no provider available", "error", err)
@@ -273,13 +291,15 @@ func (ap *AutoProvisioner) attemptProvision(
 		ContainerType:  ContainerTypeAgentWorkspace,
 		ResourceLimits: cfg.ResourceLimits,
 		BaseImage:      cfg.BaseImage,
+		Egress:         egress,
 	}
 
 	// Try warm pool first, fall back to cold creation
-	// Note: warm pool only contains default (base) images, so skip it if a
-	// specific BaseImage is requested (e.g., "coder", "researcher", "reviewer")
+	// Note: warm pool only contains default (base) images with open networking,
+	// so skip it if a specific BaseImage (e.g., "coder", "researcher", "reviewer")
+	// or a network policy is requested
 	var containerProviderID string
-	if cfg.BaseImage == "" {
+	if cfg.BaseImage == "" && egress == nil {
 		// Only use warm pool for default/base image
 		if wc := ap.warmPool.Acquire(providerType); wc != nil {
 			containerProviderID = wc.ProviderID()
@@ -289,8 +309,9 @@ func (ap *AutoProvisioner) attemptProvision(
 			)
 		}
 	} else {
-		ap.log.Info("skipping warm pool due to specific BaseImage",
+		ap.log.Info("skipping warm pool due to specific BaseImage or network policy",
 			"base_image", cfg.BaseImage,
+			"network_mode", egress.NetworkMode(),
 			"provider_type", providerType,
 		)
 	}
@@ -10,7 +10,7 @@ import (
 // CreateWorkspaceRequest is the request DTO for creating a workspace.
 type CreateWorkspaceRequest struct {
 	ContainerType  ContainerType   `json:"container_type" validate:"required"`
-	Provider       string          `json:"provider,omitempty"` // "firecracker", "e2b", "gvisor", or "auto"
+	Provider       string          `json:"provider,omitempty"` // "firecracker", "e2b", "gvisor", "namespace", or "auto"
 	RepositoryURL  string          `json:"repository_url,omitempty"`
 	Branch         string          `json:"branch,omitempty"`
 	DeploymentMode string          `json:"deployment_mode,omitempty"` // "managed" or "self-hosted"
@@ -0,0 +1,262 @@
+package workspace
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEgressProxyPort = 3128
+	// egressDNSPort is where proxies answer DNS: Docker can only point
+	// containers at name servers on the standard port
+	egressDNSPort = 53
+)
+
+// builtinEgressPresets are the domain groups allow-lists can name. The "git"
+// preset covers the common forges; the others cover package registries.
+// Server configuration can add presets or replace these.
+var builtinEgressPresets = map[string][]string{
+	"git":      {"github.com", "*.github.com", "*.githubusercontent.com", "gitlab.com", "*.gitlab.com", "bitbucket.org", "*.bitbucket.org"},
+	"npm":      {"registry.npmjs.org", "registry.yarnpkg.com"},
+	"pypi":     {"pypi.org", "files.pythonhosted.org"},
+	"go":       {"proxy.golang.org", "sum.golang.org", "golang.org"},
+	"cargo":    {"crates.io", "index.crates.io", "static.crates.io"},
+	"rubygems": {"rubygems.org", "*.rubygems.org"},
+	"maven":    {"repo.maven.apache.org", "repo1.maven.org"},
+}
+
+// EgressPolicy is a workspace's resolved network policy: its mode and, in
+// allowlist mode, the domains its egress proxy lets through.
+type EgressPolicy struct {
+	Mode    NetworkMode `json:"mode"`
+	Domains []string    `json:"domains,omitempty"`
+}
+
+// NetworkMode returns the policy's mode; a nil policy is open.
+func (p *EgressPolicy) NetworkMode() NetworkMode {
+	if p == nil || p.Mode == "" {
+		return NetworkModeOpen
+	}
+	return p.Mode
+}
+
+// Allows reports whether the policy lets the workspace reach host. In
+// allowlist mode host must equal an allowed domain or be a subdomain of a
+// "*." entry; IP addresses only match when listed literally.
+func (p *EgressPolicy) Allows(host string) bool {
+	switch p.NetworkMode() {
+	case NetworkModeOpen:
+		return true
+	case NetworkModeNone:
+		return false
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, d := range p.Domains {
+		if suffix, ok := strings.CutPrefix(d, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == d {
+			return true
+		}
+	}
+	return false
+}
+
+// listsAddress reports whether the policy allows ip by listing it literally.
+func (p *EgressPolicy) listsAddress(ip net.IP) bool {
+	if p == nil {
+		return false
+	}
+	for _, d := range p.Domains {
+		if listed := net.ParseIP(d); listed != nil && listed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveEgressPolicy turns a definition's network policy into the policy a
+// provider enforces, expanding presets. In allowlist mode the host of the
+// repository the workspace checks out is always allowed. A nil policy
+// resolves to nil (open).
+func ResolveEgressPolicy(np *NetworkPolicy, repoURL string, presets map[string][]string) (*EgressPolicy, error) {
+	if np == nil || np.Mode == NetworkModeOpen {
+		return nil, nil
+	}
+	if errs := np.validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid network policy: %s", strings.Join(errs, "; "))
+	}
+	if np.Mode == NetworkModeNone {
+		return &EgressPolicy{Mode: NetworkModeNone}, nil
+	}
+
+	var domains []string
+	for _, d := range np.AllowedDomains {
+		domains = append(domains, strings.ToLower(strings.TrimSpace(d)))
+	}
+	for _, name := range np.Presets {
+		preset, ok := presets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown network preset %q", name)
+		}
+		domains = append(domains, preset...)
+	}
+	if host := repoHost(repoURL); host != "" {
+		domains = append(domains, host)
+	}
+
+	slices.Sort(domains)
+	return &EgressPolicy{Mode: NetworkModeAllowList, Domains: slices.Compact(domains)}, nil
+}
+
+// repoHost returns the host of an HTTPS, SSH or scp-style git URL.
+func repoHost(repoURL string) string {
+	if repoURL == "" {
+		return ""
+	}
+	if u, err := url.Parse(repoURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	// scp-style: git@github.com:org/repo.git
+	if _, rest, ok := strings.Cut(repoURL, "@"); ok {
+		if host, _, ok := strings.Cut(rest, ":"); ok {
+			return strings.ToLower(host)
+		}
+	}
+	return ""
+}
+
+// ParseEgressPresets parses presets in the WORKSPACE_EGRESS_PRESETS format:
+// "name=domain,domain;name=domain".
+func ParseEgressPresets(s string) (map[string][]string, error) {
+	presets := make(map[string][]string)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !presetNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid egress preset %q: want name=domain,domain", entry)
+		}
+		var domains []string
+		for _, d := range strings.Split(list, ",") {
+			d = strings.ToLower(strings.TrimSpace(d))
+			if !domainPattern.MatchString(d) {
+				return nil, fmt.Errorf("invalid domain %q in egress preset %q", d, name)
+			}
+			domains = append(domains, d)
+		}
+		presets[name] = domains
+	}
+	return presets, nil
+}
+
+// EgressManagerConfig holds configuration for workspace egress proxies.
+type EgressManagerConfig struct {
+	// Presets adds domain groups to the built-in ones, replacing any with
+	// the same name.
+	Presets map[string][]string
+	// ProxyPort is the TCP port proxies accept HTTP(S) traffic on (default 3128).
+	ProxyPort int
+}
+
+// EgressManager runs the egress proxies of allowlist-mode workspaces, one
+// per workspace, and keeps the attempts they blocked for the audit trail.
+type EgressManager struct {
+	log       *slog.Logger
+	presets   map[string][]string
+	proxyPort int
+
+	mu      sync.Mutex
+	proxies map[string]*EgressProxy // provider ID -> proxy
+}
+
+// NewEgressManager creates an egress manager.
+func NewEgressManager(log *slog.Logger, cfg *EgressManagerConfig) *EgressManager {
+	m := &EgressManager{
+		log:       log.With("component", "workspace-egress"),
+		presets:   make(map[string][]string, len(builtinEgressPresets)),
+		proxyPort: defaultEgressProxyPort,
+		proxies:   make(map[string]*EgressProxy),
+	}
+	for name, domains := range builtinEgressPresets {
+		m.presets[name] = domains
+	}
+	if cfg != nil {
+		for name, domains := range cfg.Presets {
+			m.presets[name] = domains
+		}
+		if cfg.ProxyPort > 0 {
+			m.proxyPort = cfg.ProxyPort
+		}
+	}
+	return m
+}
+
+// ProxyURL returns the URL workspaces reach a proxy listening on host at.
+func (m *EgressManager) ProxyURL(host string) string {
+	return "http://" + net.JoinHostPort(host, fmt.Sprint(m.proxyPort))
+}
+
+// Start starts the egress proxy of a workspace on host, replacing any
+// proxy it already has. It fails when port 53 cannot be bound for DNS (the
+// server lacks CAP_NET_BIND_SERVICE), since a workspace that cannot resolve
+// names would only half work.
+func (m *EgressManager) Start(providerID string, policy *EgressPolicy, host string) error {
+	m.Stop(providerID)
+	proxy, err := startEgressProxy(m.log.With("provider_id", providerID), policy,
+		net.JoinHostPort(host, fmt.Sprint(m.proxyPort)),
+		net.JoinHostPort(host, fmt.Sprint(egressDNSPort)),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.proxies[providerID] = proxy
+	m.mu.Unlock()
+
+	m.log.Info("egress proxy started",
+		"provider_id", providerID,
+		"address", proxy.Addr(),
+		"dns", proxy.DNSAddr() != "",
+		"domains", len(policy.Domains),
+	)
+	return nil
+}
+
+// Stop stops a workspace's egress proxy, if it has one.
+func (m *EgressManager) Stop(providerID string) {
+	m.mu.Lock()
+	proxy := m.proxies[providerID]
+	delete(m.proxies, providerID)
+	m.mu.Unlock()
+	if proxy != nil {
+		proxy.Close()
+		m.log.Info("egress proxy stopped", "provider_id", providerID)
+	}
+}
+
+// Blocked returns the attempts a workspace's proxy blocked since a time.
+func (m *EgressManager) Blocked(providerID string, since time.Time) []EgressEvent {
+	m.mu.Lock()
+	proxy := m.proxies[providerID]
+	m.mu.Unlock()
+	if proxy == nil {
+		return nil
+	}
+	return proxy.Blocked(since)
+}
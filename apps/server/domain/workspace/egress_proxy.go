@@ -0,0 +1,373 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	maxEgressEvents   = 100 // Blocked attempts kept per proxy
+	egressDialTimeout = 10 * time.Second
+	egressDNSTTL      = 60
+)
+
+// errEgressAddress is returned when an allowed domain resolves to an address
+// workspaces may not reach.
+var errEgressAddress = errors.New("address not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), internal to
+// the provider's network like the private ranges.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// EgressEvent is a connection or DNS lookup an egress proxy blocked.
+type EgressEvent struct {
+	Time     time.Time `json:"time"`
+	Protocol string    `json:"protocol"` // "http", "https" or "dns"
+	Host     string    `json:"host"`
+	Reason   string    `json:"reason"`
+}
+
+// EgressProxy enforces an allowlist-mode workspace's network policy: an
+// HTTP proxy (plain requests and CONNECT tunnels) and a DNS server that
+// only answer for allowed domains.
+type EgressProxy struct {
+	log       *slog.Logger
+	policy    *EgressPolicy
+	listener  net.Listener
+	server    *http.Server
+	dns       net.PacketConn
+	resolver  *net.Resolver
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	transport *http.Transport
+
+	mu     sync.Mutex
+	events []EgressEvent
+}
+
+// newEgressProxy creates a proxy that is not listening yet.
+func newEgressProxy(log *slog.Logger, policy *EgressPolicy) *EgressProxy {
+	dialer := &net.Dialer{Timeout: egressDialTimeout, Control: egressDialGuard(policy)}
+	p := &EgressProxy{
+		log:      log,
+		policy:   policy,
+		resolver: net.DefaultResolver,
+		dial:     dialer.DialContext,
+	}
+	p.transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return p.dial(ctx, network, addr)
+		},
+		ResponseHeaderTimeout: 5 * time.Minute,
+		IdleConnTimeout:       time.Minute,
+	}
+	return p
+}
+
+// startEgressProxy starts a proxy serving HTTP on httpAddr and DNS on dnsAddr.
+func startEgressProxy(log *slog.Logger, policy *EgressPolicy, httpAddr, dnsAddr string) (*EgressProxy, error) {
+	p := newEgressProxy(log, policy)
+
+	ln, err := net.Listen("tcp", httpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start egress proxy on %s: %w", httpAddr, err)
+	}
+	p.serveHTTP(ln)
+
+	conn, err := net.ListenPacket("udp", dnsAddr)
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to start egress DNS on %s: %w", dnsAddr, err)
+	}
+	p.serveDNS(conn)
+	return p, nil
+}
+
+// egressDialGuard returns a dialer control function keeping allowed domains
+// from leading to the host itself, to link-local services such as cloud
+// metadata endpoints, or to internal networks. Private (including IPv6
+// unique local) and carrier-grade NAT addresses are only reached when the
+// policy lists the address itself, an explicit opt-in to that host.
+func egressDialGuard(policy *EgressPolicy) func(network, address string, c syscall.RawConn) error {
+	return func(_, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+			ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+			return errEgressAddress
+		}
+		if (ip.IsPrivate() || sharedAddressSpace.Contains(ip)) && !policy.listsAddress(ip) {
+			return errEgressAddress
+		}
+		return nil
+	}
+}
+
+// Addr returns the address the HTTP proxy listens on.
+func (p *EgressProxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// DNSAddr returns the address the DNS server listens on, or "" without one.
+func (p *EgressProxy) DNSAddr() string {
+	if p.dns == nil {
+		return ""
+	}
+	return p.dns.LocalAddr().String()
+}
+
+// Close stops the proxy. Open tunnels end when their connections do.
+func (p *EgressProxy) Close() {
+	if p.server != nil {
+		_ = p.server.Close()
+	}
+	if p.dns != nil {
+		_ = p.dns.Close()
+	}
+	p.transport.CloseIdleConnections()
+}
+
+// Blocked returns the attempts the proxy blocked since a time.
+func (p *EgressProxy) Blocked(since time.Time) []EgressEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var events []EgressEvent
+	for _, ev := range p.events {
+		if !ev.Time.Before(since) {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// block records and logs a blocked attempt.
+func (p *EgressProxy) block(protocol, host, reason string) {
+	ev := EgressEvent{Time: time.Now(), Protocol: protocol, Host: host, Reason: reason}
+
+	p.mu.Lock()
+	if len(p.events) == maxEgressEvents {
+		p.events = p.events[1:]
+	}
+	p.events = append(p.events, ev)
+	p.mu.Unlock()
+
+	p.log.Warn("egress blocked", "protocol", protocol, "host", host, "reason", reason)
+}
+
+// serveHTTP serves proxy requests from ln in the background.
+func (p *EgressProxy) serveHTTP(ln net.Listener) {
+	p.listener = ln
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		if err := p.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.log.Error("egress proxy stopped", "error", err)
+		}
+	}()
+}
+
+// ServeHTTP forwards allowed proxy requests and CONNECT tunnels.
+func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a workspace egress proxy", http.StatusBadRequest)
+		return
+	}
+
+	host := r.URL.Hostname()
+	if !p.policy.Allows(host) {
+		p.block("http", host, "domain not allowed")
+		http.Error(w, fmt.Sprintf("egress to %s is blocked by the workspace network policy", host), http.StatusForbidden)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	for _, h := range []string{"Proxy-Connection", "Proxy-Authorization", "Connection", "Keep-Alive", "Te", "Trailer", "Upgrade"} {
+		out.Header.Del(h)
+	}
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		if errors.Is(err, errEgressAddress) {
+			p.block("http", host, "address not allowed")
+			http.Error(w, fmt.Sprintf("egress to %s is blocked by the workspace network policy", host), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// tunnel connects a CONNECT request to an allowed host and relays bytes
+// both ways until either side closes.
+func (p *EgressProxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+	if !p.policy.Allows(host) {
+		p.block("https", host, "domain not allowed")
+		http.Error(w, fmt.Sprintf("egress to %s is blocked by the workspace network policy", host), http.StatusForbidden)
+		return
+	}
+
+	upstream, err := p.dial(r.Context(), "tcp", r.URL.Host)
+	if err != nil {
+		if errors.Is(err, errEgressAddress) {
+			p.block("https", host, "address not allowed")
+			http.Error(w, fmt.Sprintf("egress to %s is blocked by the workspace network policy", host), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunnelling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		client.Close()
+		upstream.Close()
+		return
+	}
+
+	go func() {
+		defer upstream.Close()
+		defer client.Close()
+		// Bytes the client sent after the CONNECT request are already buffered
+		_, _ = io.Copy(upstream, buffered)
+	}()
+	go func() {
+		defer upstream.Close()
+		defer client.Close()
+		_, _ = io.Copy(client, upstream)
+	}()
+}
+
+// serveDNS answers DNS queries from conn in the background.
+func (p *EgressProxy) serveDNS(conn net.PacketConn) {
+	p.dns = conn
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				continue
+			}
+			query := append([]byte(nil), buf[:n]...)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), egressDialTimeout)
+				defer cancel()
+				if resp := p.answerDNS(ctx, query); resp != nil {
+					_, _ = conn.WriteTo(resp, addr)
+				}
+			}()
+		}
+	}()
+}
+
+// answerDNS answers a DNS query: A and AAAA records for allowed domains,
+// REFUSED for others. It returns nil for messages that are not queries.
+func (p *EgressProxy) answerDNS(ctx context.Context, query []byte) []byte {
+	var parser dnsmessage.Parser
+	h, err := parser.Start(query)
+	if err != nil || h.Response {
+		return nil
+	}
+	q, err := parser.Question()
+	if err != nil {
+		return nil
+	}
+
+	hdr := dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		OpCode:             h.OpCode,
+		RecursionDesired:   h.RecursionDesired,
+		RecursionAvailable: true,
+	}
+	name := strings.TrimSuffix(q.Name.String(), ".")
+
+	var ips []net.IP
+	switch {
+	case !p.policy.Allows(name):
+		p.block("dns", name, "domain not allowed")
+		hdr.RCode = dnsmessage.RCodeRefused
+	case q.Type == dnsmessage.TypeA || q.Type == dnsmessage.TypeAAAA:
+		network := "ip4"
+		if q.Type == dnsmessage.TypeAAAA {
+			network = "ip6"
+		}
+		ips, err = p.resolver.LookupIP(ctx, network, name)
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			hdr.RCode = dnsmessage.RCodeNameError
+		} else if err != nil {
+			hdr.RCode = dnsmessage.RCodeServerFailure
+		}
+	}
+
+	b := dnsmessage.NewBuilder(nil, hdr)
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil
+	}
+	if err := b.Question(q); err != nil {
+		return nil
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil
+	}
+	rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: egressDNSTTL}
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil && q.Type == dnsmessage.TypeA {
+			err = b.AResource(rh, dnsmessage.AResource{A: [4]byte(ip4)})
+		} else if ip4 == nil && q.Type == dnsmessage.TypeAAAA {
+			err = b.AAAAResource(rh, dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())})
+		}
+		if err != nil {
+			return nil
+		}
+	}
+	resp, err := b.Finish()
+	if err != nil {
+		return nil
+	}
+	return resp
+}
@@ -0,0 +1,251 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestEgressPolicyAllows(t *testing.T) {
+	policy := &EgressPolicy{Mode: NetworkModeAllowList, Domains: []string{"github.com", "*.githubusercontent.com", "10.0.0.5"}}
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"github.com", true},
+		{"GitHub.com.", true},
+		{"github.com:443", true},
+		{"api.github.com", false},
+		{"raw.githubusercontent.com", true},
+		{"githubusercontent.com", false},
+		{"evilgithubusercontent.com", false},
+		{"10.0.0.5", true},
+		{"10.0.0.6", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, policy.Allows(tt.host))
+		})
+	}
+
+	var open *EgressPolicy
+	assert.True(t, open.Allows("example.com"))
+	assert.Equal(t, NetworkModeOpen, open.NetworkMode())
+	assert.False(t, (&EgressPolicy{Mode: NetworkModeNone}).Allows("github.com"))
+}
+
+func TestResolveEgressPolicy(t *testing.T) {
+	presets := map[string][]string{"npm": {"registry.npmjs.org"}}
+
+	policy, err := ResolveEgressPolicy(nil, "https://github.com/org/repo", presets)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = ResolveEgressPolicy(&NetworkPolicy{Mode: NetworkModeNone}, "https://github.com/org/repo", presets)
+	require.NoError(t, err)
+	assert.Equal(t, &EgressPolicy{Mode: NetworkModeNone}, policy)
+
+	policy, err = ResolveEgressPolicy(&NetworkPolicy{
+		Mode:           NetworkModeAllowList,
+		AllowedDomains: []string{" API.acme.dev ", "registry.npmjs.org"},
+		Presets:        []string{"npm"},
+	}, "git@git.acme.dev:org/repo.git", presets)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api.acme.dev", "git.acme.dev", "registry.npmjs.org"}, policy.Domains)
+
+	_, err = ResolveEgressPolicy(&NetworkPolicy{Mode: NetworkModeAllowList, Presets: []string{"cargo"}}, "", presets)
+	assert.EqualError(t, err, `unknown network preset "cargo"`)
+
+	_, err = ResolveEgressPolicy(&NetworkPolicy{Mode: "firewalled"}, "", presets)
+	assert.ErrorContains(t, err, "invalid network policy")
+}
+
+func TestParseEgressPresets(t *testing.T) {
+	presets, err := ParseEgressPresets(" internal = git.acme.dev, *.pkg.acme.dev ;docs=docs.acme.dev;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"internal": {"git.acme.dev", "*.pkg.acme.dev"},
+		"docs":     {"docs.acme.dev"},
+	}, presets)
+
+	_, err = ParseEgressPresets("internal")
+	assert.EqualError(t, err, `invalid egress preset "internal": want name=domain,domain`)
+	_, err = ParseEgressPresets("internal=https://git.acme.dev")
+	assert.EqualError(t, err, `invalid domain "https://git.acme.dev" in egress preset "internal"`)
+
+	m := NewEgressManager(testLogger(), &EgressManagerConfig{Presets: presets})
+	assert.Contains(t, m.presets, "git", "built-in presets are kept")
+	assert.Contains(t, m.presets, "internal")
+	assert.Equal(t, "http://172.30.0.1:3128", m.ProxyURL("172.30.0.1"))
+}
+
+func TestEgressDialGuard(t *testing.T) {
+	guard := egressDialGuard(&EgressPolicy{Mode: NetworkModeAllowList, Domains: []string{"github.com", "10.0.0.5", "127.0.0.1"}})
+	blocked := []string{
+		"127.0.0.1:80", "[::1]:443", "169.254.169.254:80", "0.0.0.0:22", "[fe80::1]:80",
+		"10.0.0.6:443", "172.16.0.1:443", "192.168.1.1:80", "100.64.0.1:443", "[fd00::1]:443", "[::ffff:192.168.1.1]:80",
+	}
+	for _, addr := range blocked {
+		assert.ErrorIs(t, guard("tcp", addr, nil), errEgressAddress, addr)
+	}
+	for _, addr := range []string{"140.82.112.3:443", "100.128.0.1:443", "10.0.0.5:443"} {
+		assert.NoError(t, guard("tcp", addr, nil), addr)
+	}
+
+	// Without a listed address, internal ranges stay out of reach
+	assert.ErrorIs(t, egressDialGuard(nil)("tcp", "10.0.0.5:443", nil), errEgressAddress)
+}
+
+func TestStartEgressProxy_DNSUnavailable(t *testing.T) {
+	taken, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+
+	_, err = startEgressProxy(testLogger(), &EgressPolicy{Mode: NetworkModeAllowList}, "127.0.0.1:0", taken.LocalAddr().String())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start egress DNS")
+}
+
+// startTestEgressProxy starts a proxy on loopback that may dial loopback
+// targets, which the production dialer refuses.
+func startTestEgressProxy(t *testing.T, policy *EgressPolicy) (*EgressProxy, *url.URL) {
+	t.Helper()
+	p := newEgressProxy(testLogger(), policy)
+	p.dial = (&net.Dialer{Timeout: time.Second}).DialContext
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p.serveHTTP(ln)
+	t.Cleanup(p.Close)
+
+	proxyURL, err := url.Parse("http://" + p.Addr())
+	require.NoError(t, err)
+	return p, proxyURL
+}
+
+func TestEgressProxyHTTP(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer target.Close()
+	tlsTarget := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "secure hello")
+	}))
+	defer tlsTarget.Close()
+
+	p, proxyURL := startTestEgressProxy(t, &EgressPolicy{Mode: NetworkModeAllowList, Domains: []string{"127.0.0.1"}})
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	start := time.Now()
+
+	// Plain HTTP is forwarded
+	resp, err := client.Get(target.URL)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", string(body))
+
+	// HTTPS is tunnelled with CONNECT
+	resp, err = client.Get(tlsTarget.URL)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "secure hello", string(body))
+
+	// Other hosts are refused and recorded
+	resp, err = client.Get("http://example.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	_, err = client.Get("https://example.com/")
+	assert.Error(t, err, "CONNECT to a blocked host fails")
+
+	blocked := p.Blocked(start)
+	require.Len(t, blocked, 2)
+	assert.Equal(t, "http", blocked[0].Protocol)
+	assert.Equal(t, "example.com", blocked[0].Host)
+	assert.Equal(t, "domain not allowed", blocked[0].Reason)
+	assert.Equal(t, "https", blocked[1].Protocol)
+	assert.Empty(t, p.Blocked(time.Now().Add(time.Second)))
+}
+
+func TestEgressProxyDNS(t *testing.T) {
+	p := newEgressProxy(testLogger(), &EgressPolicy{Mode: NetworkModeAllowList, Domains: []string{"localhost"}})
+
+	query := func(name string, qtype dnsmessage.Type) dnsmessage.Message {
+		t.Helper()
+		q := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: 42, RecursionDesired: true},
+			Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}},
+		}
+		packed, err := q.Pack()
+		require.NoError(t, err)
+
+		var resp dnsmessage.Message
+		require.NoError(t, resp.Unpack(p.answerDNS(context.Background(), packed)))
+		return resp
+	}
+
+	resp := query("localhost.", dnsmessage.TypeA)
+	assert.Equal(t, uint16(42), resp.Header.ID)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.Header.RCode)
+	require.NotEmpty(t, resp.Answers)
+	assert.Equal(t, [4]byte{127, 0, 0, 1}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	resp = query("exfil.attacker.example.", dnsmessage.TypeTXT)
+	assert.Equal(t, dnsmessage.RCodeRefused, resp.Header.RCode)
+	assert.Empty(t, resp.Answers)
+
+	blocked := p.Blocked(time.Time{})
+	require.Len(t, blocked, 1)
+	assert.Equal(t, EgressEvent{Time: blocked[0].Time, Protocol: "dns", Host: "exfil.attacker.example", Reason: "domain not allowed"}, blocked[0])
+
+	assert.Nil(t, p.answerDNS(context.Background(), []byte{1, 2, 3}), "malformed queries get no answer")
+}
+
+func TestToolAuditMiddlewareLogsBlockedEgress(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	egress := NewEgressManager(testLogger(), nil)
+	proxy := newEgressProxy(testLogger(), &EgressPolicy{Mode: NetworkModeAllowList})
+	egress.proxies["container-1"] = proxy
+	proxy.block("https", "before.example", "domain not allowed")
+
+	e := echo.New()
+	handler := ToolAuditMiddleware(log, egress)(func(c echo.Context) error {
+		c.Set(auditProviderIDKey, "container-1")
+		proxy.block("https", "pastebin.com", "domain not allowed")
+		return c.NoContent(http.StatusOK)
+	})
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/v1/agent/workspaces/ws-1/bash", nil), httptest.NewRecorder())
+	c.SetPath("/api/v1/agent/workspaces/:id/bash")
+	c.SetParamNames("id")
+	c.SetParamValues("ws-1")
+	require.NoError(t, handler(c))
+
+	out := logs.String()
+	assert.Contains(t, out, `"msg":"workspace egress blocked","component":"workspace-tool-audit","workspace_id":"ws-1","tool":"bash"`)
+	assert.Contains(t, out, `"host":"pastebin.com"`)
+	assert.NotContains(t, out, "before.example", "attempts before the operation are not attributed to it")
+	assert.Contains(t, out, `"egress_blocked":1`)
+}
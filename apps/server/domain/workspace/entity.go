@@ -21,6 +21,7 @@ const (
 	ProviderFirecracker ProviderType = "firecracker"
 	ProviderE2B         ProviderType = "e2b"
 	ProviderGVisor      ProviderType = "gvisor"
+	ProviderNamespace   ProviderType = "namespace"
 )
 
 // DeploymentMode indicates managed vs self-hosted.
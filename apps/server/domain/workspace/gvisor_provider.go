@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"strings"
 	"time"
 
@@ -26,6 +28,9 @@ const (
 	defaultRuntimeLabel   = "memory.workspace"
 	workspaceDir          = "/workspace"
 	maxOutputBytes        = 50 * 1024 // 50KB output limit
+
+	egressNetworkLabel = "workspace.egress_network"
+	egressPolicyLabel  = "workspace.egress_policy"
 )
 
 // GVisorProvider implements the Provider interface using Docker with gVisor (runsc) runtime.
@@ -37,6 +42,7 @@ type GVisorProvider struct {
 	runtimeName  string // "runsc" or "" (default)
 	networkName  string // Docker network for container isolation
 	defaultImage string // Override for default workspace image
+	egress       *EgressManager
 }
 
 // GVisorProviderConfig holds configuration for the gVisor provider.
@@ -50,6 +56,9 @@ type GVisorProviderConfig struct {
 	// DefaultImage overrides the default workspace base image.
 	// When empty, falls back to the package constant (memory-workspace:latest).
 	DefaultImage string
+	// Egress runs the egress proxies of allowlist-mode workspaces.
+	// Without it the provider cannot enforce network allow-lists.
+	Egress *EgressManager
 }
 
 // NewGVisorProvider creates a new gVisor-based workspace provider.
@@ -67,6 +76,7 @@ func NewGVisorProvider(log *slog.Logger, cfg *GVisorProviderConfig) (*GVisorProv
 	if cfg != nil {
 		p.networkName = cfg.NetworkName
 		p.defaultImage = cfg.DefaultImage
+		p.egress = cfg.Egress
 	}
 
 	if cfg != nil && cfg.ForceStandardRuntime {
@@ -78,6 +88,10 @@ func NewGVisorProvider(log *slog.Logger, cfg *GVisorProviderConfig) (*GVisorProv
 		p.detectRuntime(context.Background())
 	}
 
+	if p.egress != nil {
+		p.restoreEgressProxies(context.Background())
+	}
+
 	return p, nil
 }
 
@@ -107,6 +121,10 @@ func (p *GVisorProvider) detectRuntime(ctx context.Context) {
 
 // Capabilities returns what this provider supports.
 func (p *GVisorProvider) Capabilities() *ProviderCapabilities {
+	networkModes := []NetworkMode{NetworkModeNone}
+	if p.egress != nil {
+		networkModes = append(networkModes, NetworkModeAllowList)
+	}
 	return &ProviderCapabilities{
 		Name:                "gVisor (Docker)",
 		SupportsPersistence: true,
@@ -115,6 +133,7 @@ func (p *GVisorProvider) Capabilities() *ProviderCapabilities {
 		RequiresKVM:         false,
 		EstimatedStartupMs:  50,
 		ProviderType:        ProviderGVisor,
+		NetworkModes:        networkModes,
 	}
 }
 
@@ -253,9 +272,13 @@ func (p *GVisorProvider) Create(ctx context.Context, req *CreateContainerRequest
 		p.applyResourceLimits(hostConfig, req.ResourceLimits)
 	}
 
-	// Create container
-	var networkConfig *network.NetworkingConfig
-	if p.networkName != "" {
+	// Apply the network policy, then fall back to the configured network
+	networkConfig, egressNetwork, egressGateway, err := p.applyEgress(ctx, req.Egress, containerConfig, hostConfig)
+	if err != nil {
+		_ = p.client.VolumeRemove(ctx, volumeName, true)
+		return nil, err
+	}
+	if req.Egress.NetworkMode() == NetworkModeOpen && p.networkName != "" {
 		p.log.Info("attaching container to network", "network", p.networkName)
 		networkConfig = &network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
@@ -276,17 +299,29 @@ func (p *GVisorProvider) Create(ctx context.Context, req *CreateContainerRequest
 	if err != nil {
 		p.log.Error("failed to create Docker container", "image", image, "error", err)
 		// Clean up volume on failure
+		p.removeEgressNetwork(ctx, egressNetwork)
 		_ = p.client.VolumeRemove(ctx, volumeName, true)
 		return nil, fmt.Errorf("failed to create container: %w", err)
 	}
 	p.log.Info("Docker container created successfully", "container_id", resp.ID[:12], "name", containerName)
 
+	// The egress proxy must be listening before the workspace can use the network
+	if egressNetwork != "" {
+		if err := p.egress.Start(resp.ID, req.Egress, egressGateway); err != nil {
+			_ = p.client.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+			p.removeEgressNetwork(ctx, egressNetwork)
+			_ = p.client.VolumeRemove(ctx, volumeName, true)
+			return nil, err
+		}
+	}
+
 	// Start container
 	p.log.Info("starting Docker container", "container_id", resp.ID[:12])
 	if err := p.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
 		p.log.Error("failed to start Docker container", "container_id", resp.ID[:12], "error", err)
 		// Clean up on failure
 		_ = p.client.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+		p.stopEgress(ctx, resp.ID, egressNetwork)
 		_ = p.client.VolumeRemove(ctx, volumeName, true)
 		return nil, fmt.Errorf("failed to start container: %w", err)
 	}
@@ -305,11 +340,13 @@ func (p *GVisorProvider) Create(ctx context.Context, req *CreateContainerRequest
 
 // Destroy permanently removes a workspace container and its volume.
 func (p *GVisorProvider) Destroy(ctx context.Context, providerID string) error {
-	// Get volume name from container labels before removing
+	// Get volume and egress network names from container labels before removing
 	volumeName := ""
+	egressNetwork := ""
 	info, err := p.client.ContainerInspect(ctx, providerID)
 	if err == nil {
 		volumeName = info.Config.Labels["workspace.volume"]
+		egressNetwork = info.Config.Labels[egressNetworkLabel]
 	}
 
 	// Remove container (force kill if running)
@@ -322,6 +359,8 @@ func (p *GVisorProvider) Destroy(ctx context.Context, providerID string) error {
 		}
 	}
 
+	p.stopEgress(ctx, providerID, egressNetwork)
+
 	// Remove associated volume
 	if volumeName != "" {
 		if err := p.client.VolumeRemove(ctx, volumeName, true); err != nil {
@@ -345,6 +384,16 @@ func (p *GVisorProvider) Stop(ctx context.Context, providerID string) error {
 
 // Resume restarts a stopped workspace container.
 func (p *GVisorProvider) Resume(ctx context.Context, providerID string) error {
+	if p.egress != nil {
+		// The proxy may be gone if the server restarted while the workspace was stopped
+		info, err := p.client.ContainerInspect(ctx, providerID)
+		if err != nil {
+			return fmt.Errorf("failed to inspect container: %w", err)
+		}
+		if err := p.startEgressFromLabels(ctx, providerID, info.Config.Labels); err != nil {
+			return err
+		}
+	}
 	if err := p.client.ContainerStart(ctx, providerID, container.StartOptions{}); err != nil {
 		return fmt.Errorf("failed to resume container: %w", err)
 	}
@@ -557,15 +606,32 @@ func (p *GVisorProvider) CreateFromSnapshot(ctx context.Context, snapshotID stri
 		p.applyResourceLimits(hostConfig, req.ResourceLimits)
 	}
 
+	networkConfig, egressNetwork, egressGateway, err := p.applyEgress(ctx, req.Egress, containerConfig, hostConfig)
+	if err != nil {
+		_ = p.client.VolumeRemove(ctx, volumeName, true)
+		return nil, err
+	}
+
 	containerName := fmt.Sprintf("memory-ws-%d", time.Now().UnixNano())
-	resp, err := p.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, containerName)
+	resp, err := p.client.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, nil, containerName)
 	if err != nil {
+		p.removeEgressNetwork(ctx, egressNetwork)
 		_ = p.client.VolumeRemove(ctx, volumeName, true)
 		return nil, fmt.Errorf("failed to create container from snapshot: %w", err)
 	}
 
+	if egressNetwork != "" {
+		if err := p.egress.Start(resp.ID, req.Egress, egressGateway); err != nil {
+			_ = p.client.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+			p.removeEgressNetwork(ctx, egressNetwork)
+			_ = p.client.VolumeRemove(ctx, volumeName, true)
+			return nil, err
+		}
+	}
+
 	if err := p.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
 		_ = p.client.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+		p.stopEgress(ctx, resp.ID, egressNetwork)
 		_ = p.client.VolumeRemove(ctx, volumeName, true)
 		return nil, fmt.Errorf("failed to start container from snapshot: %w", err)
 	}
@@ -579,6 +645,128 @@ func (p *GVisorProvider) CreateFromSnapshot(ctx context.Context, snapshotID stri
 	return &CreateContainerResult{ProviderID: resp.ID}, nil
 }
 
+// applyEgress configures a workspace container for its network policy.
+// In none mode the container gets no network. In allowlist mode it gets its
+// own internal Docker network, which has no route out; the network's
+// gateway on the host runs the workspace's egress proxy, which the
+// container uses for HTTP(S) and DNS. It returns the networking config to
+// create the container with and, in allowlist mode, the egress network's
+// name and gateway address.
+func (p *GVisorProvider) applyEgress(ctx context.Context, policy *EgressPolicy, containerConfig *container.Config, hostConfig *container.HostConfig) (*network.NetworkingConfig, string, string, error) {
+	switch policy.NetworkMode() {
+	case NetworkModeNone:
+		hostConfig.NetworkMode = network.NetworkNone
+		hostConfig.ExtraHosts = nil
+		return nil, "", "", nil
+
+	case NetworkModeAllowList:
+		if p.egress == nil {
+			return nil, "", "", fmt.Errorf("gVisor provider has no egress proxy to enforce a network allow-list")
+		}
+		policyJSON, err := json.Marshal(policy)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode egress policy: %w", err)
+		}
+
+		name := fmt.Sprintf("memory-egress-%d", time.Now().UnixNano())
+		if _, err := p.client.NetworkCreate(ctx, name, network.CreateOptions{
+			Driver:   "bridge",
+			Internal: true,
+			Labels:   map[string]string{defaultRuntimeLabel: "true"},
+		}); err != nil {
+			return nil, "", "", fmt.Errorf("failed to create egress network: %w", err)
+		}
+		gateway, err := p.egressGateway(ctx, name)
+		if err != nil {
+			p.removeEgressNetwork(ctx, name)
+			return nil, "", "", err
+		}
+
+		containerConfig.Labels[egressNetworkLabel] = name
+		containerConfig.Labels[egressPolicyLabel] = string(policyJSON)
+		proxyURL := p.egress.ProxyURL(gateway)
+		for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+			containerConfig.Env = append(containerConfig.Env, k+"="+proxyURL)
+		}
+		containerConfig.Env = append(containerConfig.Env, "NO_PROXY=localhost,127.0.0.1", "no_proxy=localhost,127.0.0.1")
+		hostConfig.DNS = []string{gateway}
+		hostConfig.ExtraHosts = nil
+
+		p.log.Info("attaching container to egress network", "network", name, "proxy", proxyURL)
+		return &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{name: {}},
+		}, name, gateway, nil
+	}
+	return nil, "", "", nil
+}
+
+// egressGateway returns the IPv4 gateway address of an egress network.
+func (p *GVisorProvider) egressGateway(ctx context.Context, name string) (string, error) {
+	info, err := p.client.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect egress network %s: %w", name, err)
+	}
+	for _, cfg := range info.IPAM.Config {
+		if ip := net.ParseIP(cfg.Gateway); ip != nil && ip.To4() != nil {
+			return cfg.Gateway, nil
+		}
+	}
+	return "", fmt.Errorf("egress network %s has no IPv4 gateway", name)
+}
+
+// startEgressFromLabels (re)starts the egress proxy of a container created
+// in allowlist mode, using the policy recorded in its labels.
+func (p *GVisorProvider) startEgressFromLabels(ctx context.Context, providerID string, labels map[string]string) error {
+	name := labels[egressNetworkLabel]
+	if name == "" {
+		return nil
+	}
+	var policy EgressPolicy
+	if err := json.Unmarshal([]byte(labels[egressPolicyLabel]), &policy); err != nil {
+		return fmt.Errorf("invalid egress policy label on container %s: %w", providerID, err)
+	}
+	gateway, err := p.egressGateway(ctx, name)
+	if err != nil {
+		return err
+	}
+	return p.egress.Start(providerID, &policy, gateway)
+}
+
+// restoreEgressProxies restarts the egress proxies of running allowlist-mode
+// containers after a server restart. Until then they have no way out.
+func (p *GVisorProvider) restoreEgressProxies(ctx context.Context) {
+	containers, err := p.client.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", egressNetworkLabel)),
+	})
+	if err != nil {
+		p.log.Warn("failed to list workspace containers for egress proxies", "error", err)
+		return
+	}
+	for _, c := range containers {
+		if err := p.startEgressFromLabels(ctx, c.ID, c.Labels); err != nil {
+			p.log.Warn("failed to restore egress proxy", "container_id", c.ID[:min(12, len(c.ID))], "error", err)
+		}
+	}
+}
+
+// stopEgress stops a container's egress proxy and removes its egress network.
+func (p *GVisorProvider) stopEgress(ctx context.Context, providerID, egressNetwork string) {
+	if p.egress != nil {
+		p.egress.Stop(providerID)
+	}
+	p.removeEgressNetwork(ctx, egressNetwork)
+}
+
+// removeEgressNetwork removes an egress network, if there is one.
+func (p *GVisorProvider) removeEgressNetwork(ctx context.Context, name string) {
+	if name == "" {
+		return
+	}
+	if err := p.client.NetworkRemove(ctx, name); err != nil && !client.IsErrNotFound(err) {
+		p.log.Warn("failed to remove egress network", "network", name, "error", err)
+	}
+}
+
 // Exec executes a command inside a workspace container.
 func (p *GVisorProvider) Exec(ctx context.Context, providerID string, req *ExecRequest) (*ExecResult, error) {
 	start := time.Now()
@@ -19,6 +19,7 @@ var Module = fx.Options(
 	fx.Provide(newStoreFromDB),
 	fx.Provide(newServiceFromConfig),
 	fx.Provide(newOrchestrator),
+	fx.Provide(newEgressManager),
 	fx.Provide(newCleanupJob),
 	fx.Provide(newSetupExecutor),
 	fx.Provide(newCheckoutService),
@@ -35,12 +36,12 @@ var Module = fx.Options(
 )
 
 // registerWorkspaceRoutes registers workspace routes only if the feature is enabled.
-func registerWorkspaceRoutes(cfg *config.Config, e *echo.Echo, h *Handler, authMiddleware *auth.Middleware, log *slog.Logger) {
+func registerWorkspaceRoutes(cfg *config.Config, e *echo.Echo, h *Handler, authMiddleware *auth.Middleware, egress *EgressManager, log *slog.Logger) {
 	if !cfg.Workspace.IsEnabled() {
 		log.Info("agent workspaces disabled (ENABLE_AGENT_WORKSPACES=false), skipping route registration")
 		return
 	}
-	RegisterRoutes(e, h, authMiddleware, log)
+	RegisterRoutes(e, h, authMiddleware, egress, log)
 }
 
 // newStoreFromDB creates a workspace store with the bun DB.
@@ -77,6 +78,20 @@ func newOrchestrator(log *slog.Logger) *Orchestrator {
 	return NewOrchestrator(log)
 }
 
+// newEgressManager creates the egress manager for workspace network policies.
+// Invalid WORKSPACE_EGRESS_PRESETS are logged and ignored, leaving the built-in presets.
+func newEgressManager(log *slog.Logger, cfg *config.Config) *EgressManager {
+	presets, err := ParseEgressPresets(cfg.Workspace.EgressPresets)
+	if err != nil {
+		log.Warn("ignoring WORKSPACE_EGRESS_PRESETS", "error", err)
+		presets = nil
+	}
+	return NewEgressManager(log, &EgressManagerConfig{
+		Presets:   presets,
+		ProxyPort: cfg.Workspace.EgressProxyPort,
+	})
+}
+
 // newSetupExecutor creates a workspace setup command executor.
 func newSetupExecutor(orchestrator *Orchestrator, log *slog.Logger) *SetupExecutor {
 	return NewSetupExecutor(orchestrator, log)
@@ -92,8 +107,10 @@ func newCheckoutService(log *slog.Logger) *CheckoutService {
 }
 
 // newAutoProvisioner creates the auto-provisioning service for agent workspaces.
-func newAutoProvisioner(service *Service, orchestrator *Orchestrator, checkoutSvc *CheckoutService, setupExec *SetupExecutor, warmPool *WarmPool, log *slog.Logger) *AutoProvisioner {
-	return NewAutoProvisioner(service, orchestrator, checkoutSvc, setupExec, warmPool, log)
+func newAutoProvisioner(service *Service, orchestrator *Orchestrator, checkoutSvc *CheckoutService, setupExec *SetupExecutor, warmPool *WarmPool, egress *EgressManager, log *slog.Logger) *AutoProvisioner {
+	ap := NewAutoProvisioner(service, orchestrator, checkoutSvc, setupExec, warmPool, log)
+	ap.SetEgressManager(egress)
+	return ap
 }
 
 // newCleanupJob creates a cleanup job with configuration from env vars.
@@ -112,7 +129,7 @@ func newCleanupJob(store *Store, orchestrator *Orchestrator, log *slog.Logger, c
 }
 
 // registerProviders registers all available workspace providers with the orchestrator.
-func registerProviders(lc fx.Lifecycle, orchestrator *Orchestrator, cfg *config.Config, log *slog.Logger) {
+func registerProviders(lc fx.Lifecycle, orchestrator *Orchestrator, egress *EgressManager, cfg *config.Config, log *slog.Logger) {
 	if !cfg.Workspace.IsEnabled() {
 		return
 	}
@@ -123,6 +140,7 @@ func registerProviders(lc fx.Lifecycle, orchestrator *Orchestrator, cfg *config.
 			gvisorCfg := &GVisorProviderConfig{
 				NetworkName:  cfg.Workspace.NetworkName,
 				DefaultImage: cfg.Workspace.DefaultImage,
+				Egress:       egress,
 			}
 			gvisorProvider, err := NewGVisorProvider(log, gvisorCfg)
 			if err != nil {
@@ -141,6 +159,20 @@ func registerProviders(lc fx.Lifecycle, orchestrator *Orchestrator, cfg *config.
 				log.Info("Firecracker provider not registered — KVM not available")
 			}
 
+			// Register namespace provider (requires bubblewrap and unprivileged user namespaces)
+			nsProvider, err := NewNamespaceProvider(log, &NamespaceProviderConfig{
+				DataDir:      cfg.Workspace.NamespaceDataDir,
+				RootfsDir:    cfg.Workspace.NamespaceRootfsDir,
+				CgroupParent: cfg.Workspace.NamespaceCgroupParent,
+			})
+			if err != nil {
+				log.Warn("failed to create namespace provider", "error", err)
+			} else if nsProvider.IsAvailable() {
+				orchestrator.RegisterProvider(ProviderNamespace, nsProvider)
+			} else {
+				log.Info("namespace provider not registered", "reason", nsProvider.UnavailableReason())
+			}
+
 			// Register E2B provider (requires API key)
 			if cfg.Workspace.E2BAPIKey != "" {
 				e2bProvider, err := NewE2BProvider(log, &E2BProviderConfig{
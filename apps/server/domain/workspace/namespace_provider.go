@@ -0,0 +1,1019 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// nsDataDir is the base directory for namespace sandbox data (sandboxes, snapshots, root filesystems).
+	nsDataDir = "/var/lib/emergent/namespace"
+
+	// nsDefaultRootfs is the root filesystem used when a workspace requests no base image.
+	nsDefaultRootfs = "default"
+
+	// nsDefaultPath is the PATH inside a sandbox.
+	nsDefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+	// nsDefaultExecTimeout is the timeout for commands that do not set one.
+	nsDefaultExecTimeout = 120 * time.Second
+
+	// nsProbeTimeout bounds the bubblewrap probe run at startup.
+	nsProbeTimeout = 5 * time.Second
+
+	// nsPidsMax caps the number of processes in a sandbox's cgroup.
+	nsPidsMax = 1024
+
+	// nsMaxListedFiles caps the number of files returned by ListFiles.
+	nsMaxListedFiles = 1000
+
+	// nsSeccompFD is the descriptor bubblewrap reads the seccomp filter from.
+	nsSeccompFD = 3
+)
+
+var (
+	// nsRootfsName matches root filesystem names selectable as a base image.
+	nsRootfsName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+	// nsSnapshotID matches the IDs of namespace sandbox snapshots.
+	nsSnapshotID = regexp.MustCompile(`^ns-snap-[0-9]+$`)
+)
+
+// NamespaceProvider implements the Provider interface with unprivileged Linux
+// namespaces, for hosts without Docker or KVM. Each workspace is a directory
+// mounted at /workspace over a read-only root filesystem directory. Commands
+// run under bubblewrap in fresh user, mount, PID, IPC and UTS namespaces with
+// a seccomp filter, inside a per-workspace cgroup v2 group that enforces
+// resource limits and freezes the workspace on Stop. Sandboxes share the
+// host's kernel and, unless their network mode is none, its network, so
+// isolation is weaker than gVisor or Firecracker.
+type NamespaceProvider struct {
+	log    *slog.Logger
+	config *NamespaceProviderConfig
+
+	bwrapPath         string
+	available         bool
+	unavailableReason string
+	cgroups           bool   // Whether per-workspace cgroups are enabled
+	seccomp           []byte // Compiled seccomp filter, nil when unsupported on this host
+
+	mu sync.RWMutex
+	// sandboxes tracks workspaces by provider ID. They are persisted in
+	// DataDir and restored on startup.
+	sandboxes map[string]*namespaceSandbox
+}
+
+// namespaceSandbox is the persisted state of a namespace workspace.
+type namespaceSandbox struct {
+	ID      string            `json:"id"`
+	Rootfs  string            `json:"rootfs"` // Host path of the root filesystem
+	Env     map[string]string `json:"env,omitempty"`
+	Limits  *ResourceLimits   `json:"resource_limits,omitempty"`
+	Network NetworkMode       `json:"network,omitempty"` // "none" gives the sandbox its own empty network namespace
+	Stopped bool              `json:"stopped"`
+
+	dir string // Host directory holding the sandbox's state and workspace
+}
+
+// workspacePath returns the host directory mounted at /workspace.
+func (sb *namespaceSandbox) workspacePath() string {
+	return filepath.Join(sb.dir, "workspace")
+}
+
+// resolvePath maps a path inside the sandbox to the host directory backing
+// it and the path relative to that directory. /workspace (and relative
+// paths) map to the sandbox's own directory; anything else maps to the
+// read-only root filesystem.
+func (sb *namespaceSandbox) resolvePath(p string) (dir, rel string, writable bool) {
+	if !path.IsAbs(p) {
+		p = path.Join(workspaceDir, p)
+	}
+	p = path.Clean(p)
+	if p == workspaceDir || strings.HasPrefix(p, workspaceDir+"/") {
+		dir, rel, writable = sb.workspacePath(), strings.TrimPrefix(p, workspaceDir), true
+	} else {
+		dir, rel = sb.Rootfs, p
+	}
+	rel = strings.TrimPrefix(rel, "/")
+	if rel == "" {
+		rel = "."
+	}
+	return dir, rel, writable
+}
+
+// NamespaceProviderConfig holds configuration for the namespace provider.
+type NamespaceProviderConfig struct {
+	// DataDir is the base directory for sandbox data. Defaults to /var/lib/emergent/namespace.
+	DataDir string
+
+	// RootfsDir holds the root filesystems, one directory each, selected by a
+	// workspace's base image name. Defaults to DataDir/rootfs; "default" is
+	// used when no base image is requested.
+	RootfsDir string
+
+	// BwrapPath is the bubblewrap binary. Defaults to "bwrap" on the PATH.
+	BwrapPath string
+
+	// CgroupParent is a cgroup v2 directory delegated to the server, under
+	// which each workspace gets its own cgroup. When empty, resource limits
+	// are not enforced and Stop does not freeze running commands.
+	CgroupParent string
+}
+
+// NewNamespaceProvider creates a new Linux namespace workspace provider.
+// Use IsAvailable to check that the host can run its sandboxes.
+func NewNamespaceProvider(log *slog.Logger, cfg *NamespaceProviderConfig) (*NamespaceProvider, error) {
+	if cfg == nil {
+		cfg = &NamespaceProviderConfig{}
+	}
+
+	// Apply defaults
+	if cfg.DataDir == "" {
+		cfg.DataDir = nsDataDir
+	}
+	if cfg.RootfsDir == "" {
+		cfg.RootfsDir = filepath.Join(cfg.DataDir, "rootfs")
+	}
+	if cfg.BwrapPath == "" {
+		cfg.BwrapPath = "bwrap"
+	}
+
+	p := &NamespaceProvider{
+		log:       log.With("component", "namespace-provider"),
+		config:    cfg,
+		sandboxes: make(map[string]*namespaceSandbox),
+	}
+
+	for _, sub := range []string{"sandboxes", "snapshots"} {
+		if err := os.MkdirAll(filepath.Join(cfg.DataDir, sub), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create %s directory: %w", sub, err)
+		}
+	}
+
+	p.detectSandbox()
+	if p.available {
+		p.cgroups = p.setupCgroups()
+		if err := p.restore(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// detectSandbox checks that bubblewrap can create an unprivileged user
+// namespace on this host, and compiles the seccomp filter.
+func (p *NamespaceProvider) detectSandbox() {
+	bwrapPath, err := exec.LookPath(p.config.BwrapPath)
+	if err != nil {
+		p.unavailableReason = fmt.Sprintf("bubblewrap not found: %v", err)
+		p.log.Warn("namespace sandboxes not available", "reason", p.unavailableReason)
+		return
+	}
+	p.bwrapPath = bwrapPath
+
+	ctx, cancel := context.WithTimeout(context.Background(), nsProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, bwrapPath,
+		"--unshare-user", "--unshare-pid", "--die-with-parent",
+		"--ro-bind", "/", "/", "true",
+	).CombinedOutput()
+	if err != nil {
+		p.unavailableReason = fmt.Sprintf("unprivileged user namespaces not usable: %v: %s", err, strings.TrimSpace(string(out)))
+		p.log.Warn("namespace sandboxes not available", "reason", p.unavailableReason)
+		return
+	}
+	p.available = true
+
+	filter, err := seccompFilter()
+	if err != nil {
+		p.log.Warn("seccomp filter not available, sandboxes run without it", "error", err)
+	} else {
+		p.seccomp = filter
+	}
+	p.log.Info("namespace sandboxes available", "bwrap", bwrapPath)
+}
+
+// setupCgroups checks that CgroupParent is a cgroup v2 directory and enables
+// the controllers workspace cgroups need.
+func (p *NamespaceProvider) setupCgroups() bool {
+	parent := p.config.CgroupParent
+	if parent == "" {
+		p.log.Info("no cgroup parent configured — workspace resource limits and freezing disabled")
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		p.log.Warn("cgroup parent is not a cgroup v2 directory — workspace resource limits and freezing disabled",
+			"cgroup_parent", parent, "error", err)
+		return false
+	}
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+cpu +memory +pids"), 0); err != nil {
+		// Controllers may already be enabled by whoever delegated the cgroup
+		p.log.Warn("failed to enable cgroup controllers", "cgroup_parent", parent, "error", err)
+	}
+	return true
+}
+
+// restore loads the sandboxes persisted in DataDir.
+func (p *NamespaceProvider) restore() error {
+	dirs, err := os.ReadDir(filepath.Join(p.config.DataDir, "sandboxes"))
+	if err != nil {
+		return fmt.Errorf("failed to read sandboxes directory: %w", err)
+	}
+	for _, d := range dirs {
+		dir := filepath.Join(p.config.DataDir, "sandboxes", d.Name())
+		sb, err := loadNamespaceSandbox(filepath.Join(dir, "sandbox.json"))
+		if err != nil {
+			p.log.Warn("skipping unreadable sandbox", "dir", dir, "error", err)
+			continue
+		}
+		sb.dir = dir
+		p.sandboxes[sb.ID] = sb
+	}
+	if len(p.sandboxes) > 0 {
+		p.log.Info("namespace sandboxes restored", "count", len(p.sandboxes))
+	}
+	return nil
+}
+
+// Capabilities returns what this provider supports.
+func (p *NamespaceProvider) Capabilities() *ProviderCapabilities {
+	return &ProviderCapabilities{
+		Name:                "Linux namespaces (bubblewrap, shared kernel)",
+		SupportsPersistence: true,
+		SupportsSnapshots:   true,
+		SupportsWarmPool:    true,
+		RequiresKVM:         false,
+		EstimatedStartupMs:  10,
+		ProviderType:        ProviderNamespace,
+		ContainerTypes:      []ContainerType{ContainerTypeAgentWorkspace},
+		NetworkModes:        []NetworkMode{NetworkModeNone},
+	}
+}
+
+// Create provisions a new sandbox: an empty workspace directory over the
+// requested root filesystem.
+func (p *NamespaceProvider) Create(_ context.Context, req *CreateContainerRequest) (*CreateContainerResult, error) {
+	return p.create(req, "", "")
+}
+
+// create provisions a sandbox over rootfs (or the one req asks for), copying
+// the workspace from fromDir when set.
+func (p *NamespaceProvider) create(req *CreateContainerRequest, rootfs, fromDir string) (*CreateContainerResult, error) {
+	if !p.available {
+		return nil, fmt.Errorf("namespace sandboxes are not available: %s", p.unavailableReason)
+	}
+	if req.ContainerType == ContainerTypeMCPServer {
+		return nil, fmt.Errorf("namespace provider does not host MCP server containers")
+	}
+	// Sandboxes share the host network or have none; there is nowhere to
+	// put an egress proxy in between
+	network := req.Egress.NetworkMode()
+	if network == NetworkModeAllowList {
+		return nil, fmt.Errorf("namespace provider cannot enforce a network allow-list")
+	}
+
+	if rootfs == "" || req.BaseImage != "" {
+		var err error
+		if rootfs, err = p.resolveRootfs(req.BaseImage); err != nil {
+			return nil, err
+		}
+	}
+
+	sb := &namespaceSandbox{
+		ID:     fmt.Sprintf("ns-%d", time.Now().UnixNano()),
+		Rootfs: rootfs,
+		Env:    req.Env,
+		Limits: req.ResourceLimits,
+	}
+	if network == NetworkModeNone {
+		sb.Network = NetworkModeNone
+	}
+	sb.dir = filepath.Join(p.config.DataDir, "sandboxes", sb.ID)
+
+	if err := os.MkdirAll(sb.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	if fromDir != "" {
+		if err := copyDir(fromDir, sb.workspacePath()); err != nil {
+			_ = os.RemoveAll(sb.dir)
+			return nil, fmt.Errorf("failed to restore workspace: %w", err)
+		}
+	} else if err := os.Mkdir(sb.workspacePath(), 0755); err != nil {
+		_ = os.RemoveAll(sb.dir)
+		return nil, fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+	if err := p.createCgroup(sb); err != nil {
+		_ = os.RemoveAll(sb.dir)
+		return nil, err
+	}
+	if err := p.save(sb); err != nil {
+		p.removeCgroup(sb.ID)
+		_ = os.RemoveAll(sb.dir)
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.sandboxes[sb.ID] = sb
+	p.mu.Unlock()
+
+	p.log.Info("namespace sandbox created", "sandbox_id", sb.ID, "rootfs", rootfs)
+	return &CreateContainerResult{ProviderID: sb.ID}, nil
+}
+
+// resolveRootfs returns the host path of the root filesystem named by a
+// base image, making sure it has the mount points sandboxes need.
+func (p *NamespaceProvider) resolveRootfs(baseImage string) (string, error) {
+	name := baseImage
+	if name == "" {
+		name = nsDefaultRootfs
+	}
+	if !nsRootfsName.MatchString(name) {
+		return "", fmt.Errorf("namespace provider needs a root filesystem name, not %q", baseImage)
+	}
+
+	rootfs := filepath.Join(p.config.RootfsDir, name)
+	if _, err := os.Stat(filepath.Join(rootfs, "bin", "sh")); err != nil {
+		return "", fmt.Errorf("root filesystem %q not found in %s: %w", name, p.config.RootfsDir, err)
+	}
+	for _, dir := range []string{"proc", "dev", "tmp", strings.TrimPrefix(workspaceDir, "/")} {
+		if err := os.MkdirAll(filepath.Join(rootfs, dir), 0755); err != nil {
+			return "", fmt.Errorf("root filesystem %q lacks /%s: %w", name, dir, err)
+		}
+	}
+	return rootfs, nil
+}
+
+// Destroy kills a sandbox's processes and removes its directory and cgroup.
+func (p *NamespaceProvider) Destroy(_ context.Context, providerID string) error {
+	p.mu.Lock()
+	sb, ok := p.sandboxes[providerID]
+	if ok {
+		delete(p.sandboxes, providerID)
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("sandbox not found: %s", providerID)
+	}
+
+	p.removeCgroup(sb.ID)
+	if err := os.RemoveAll(sb.dir); err != nil {
+		return fmt.Errorf("failed to remove sandbox directory: %w", err)
+	}
+
+	p.log.Info("namespace sandbox destroyed", "sandbox_id", providerID)
+	return nil
+}
+
+// Stop freezes the sandbox's running commands and refuses new ones until
+// Resume.
+func (p *NamespaceProvider) Stop(_ context.Context, providerID string) error {
+	return p.setStopped(providerID, true)
+}
+
+// Resume thaws a stopped sandbox.
+func (p *NamespaceProvider) Resume(_ context.Context, providerID string) error {
+	return p.setStopped(providerID, false)
+}
+
+// setStopped freezes or thaws a sandbox and persists its state.
+func (p *NamespaceProvider) setStopped(providerID string, stopped bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sb, ok := p.sandboxes[providerID]
+	if !ok {
+		return fmt.Errorf("sandbox not found: %s", providerID)
+	}
+	if sb.Stopped == stopped {
+		if stopped {
+			return fmt.Errorf("sandbox is already stopped: %s", providerID)
+		}
+		return fmt.Errorf("sandbox is not stopped: %s", providerID)
+	}
+
+	if err := p.freeze(sb.ID, stopped); err != nil {
+		return err
+	}
+	sb.Stopped = stopped
+	if err := p.save(sb); err != nil {
+		return err
+	}
+
+	if stopped {
+		p.log.Info("namespace sandbox stopped", "sandbox_id", providerID)
+	} else {
+		p.log.Info("namespace sandbox resumed", "sandbox_id", providerID)
+	}
+	return nil
+}
+
+// Exec runs a command in the sandbox with /bin/sh -c.
+func (p *NamespaceProvider) Exec(ctx context.Context, providerID string, req *ExecRequest) (*ExecResult, error) {
+	sb, err := p.getSandbox(providerID)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	timeout := nsDefaultExecTimeout
+	if req.TimeoutMs > 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	workdir := req.Workdir
+	if workdir == "" {
+		workdir = workspaceDir
+	}
+
+	cmd := exec.CommandContext(execCtx, p.bwrapPath, p.bwrapArgs(sb, workdir, req.Command)...)
+	cmd.Env = sandboxEnv(sb)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+
+	if p.seccomp != nil {
+		filter, err := seccompPipe(p.seccomp)
+		if err != nil {
+			return nil, err
+		}
+		defer filter.Close()
+		cmd.ExtraFiles = []*os.File{filter} // nsSeccompFD
+	}
+
+	cgroupFD := -1
+	if p.cgroups {
+		cg, err := os.Open(p.cgroupPath(sb.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sandbox cgroup: %w", err)
+		}
+		defer cg.Close()
+		cgroupFD = int(cg.Fd())
+	}
+	cmd.SysProcAttr = sandboxProcAttr(cgroupFD)
+
+	err = cmd.Run()
+	exitCode := 0
+	if err != nil {
+		if execCtx.Err() != nil {
+			return &ExecResult{
+				Stdout:     stdoutBuf.String(),
+				Stderr:     stderrBuf.String(),
+				ExitCode:   -1,
+				DurationMs: time.Since(start).Milliseconds(),
+			}, fmt.Errorf("command timed out after %dms", timeout.Milliseconds())
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run sandbox: %w", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	stdout := stdoutBuf.String()
+	truncated := false
+	if len(stdout) > maxOutputBytes {
+		stdout = stdout[:maxOutputBytes]
+		truncated = true
+	}
+
+	return &ExecResult{
+		Stdout:     stdout,
+		Stderr:     stderrBuf.String(),
+		ExitCode:   exitCode,
+		DurationMs: time.Since(start).Milliseconds(),
+		Truncated:  truncated,
+	}, nil
+}
+
+// bwrapArgs returns the bubblewrap arguments running command in the sandbox.
+// The command inherits bubblewrap's environment, set by sandboxEnv, so
+// environment values stay out of the command line other users can read.
+func (p *NamespaceProvider) bwrapArgs(sb *namespaceSandbox, workdir, command string) []string {
+	args := []string{
+		"--unshare-user", "--unshare-pid", "--unshare-ipc", "--unshare-uts", "--unshare-cgroup-try",
+		"--die-with-parent", "--new-session",
+		"--hostname", sb.ID,
+		"--ro-bind", sb.Rootfs, "/",
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+		"--bind", sb.workspacePath(), workspaceDir,
+	}
+	if sb.Network == NetworkModeNone {
+		args = append(args, "--unshare-net")
+	}
+	if p.seccomp != nil {
+		args = append(args, "--seccomp", strconv.Itoa(nsSeccompFD))
+	}
+	return append(args, "--chdir", workdir, "--", "/bin/sh", "-c", command)
+}
+
+// sandboxEnv returns the environment of commands run in the sandbox: PATH and
+// HOME, overridden by the sandbox's own variables.
+func sandboxEnv(sb *namespaceSandbox) []string {
+	env := map[string]string{"PATH": nsDefaultPath, "HOME": workspaceDir}
+	for k, v := range sb.Env {
+		env[k] = v
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	vars := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vars = append(vars, k+"="+env[k])
+	}
+	return vars
+}
+
+// seccompPipe returns the read end of a pipe holding the seccomp filter.
+func seccompPipe(filter []byte) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seccomp pipe: %w", err)
+	}
+	defer w.Close()
+	// The filter is a few hundred bytes, well within the pipe buffer
+	if _, err := w.Write(filter); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to write seccomp filter: %w", err)
+	}
+	return r, nil
+}
+
+// ReadFile reads a file or lists a directory in the sandbox.
+func (p *NamespaceProvider) ReadFile(_ context.Context, providerID string, req *FileReadRequest) (*FileReadResult, error) {
+	sb, err := p.getSandbox(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, rel, _ := sb.resolvePath(req.FilePath)
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sandbox filesystem: %w", err)
+	}
+	defer root.Close()
+
+	info, err := root.Stat(rel)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("file not found: %s", req.FilePath)
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	if info.IsDir() {
+		entries, err := f.ReadDir(-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list directory: %w", err)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+		var listing strings.Builder
+		for _, e := range entries {
+			listing.WriteString(e.Name())
+			if e.IsDir() {
+				listing.WriteString("/")
+			}
+			listing.WriteString("\n")
+		}
+		return &FileReadResult{Content: listing.String(), IsDir: true}, nil
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	content := string(data)
+
+	if isBinaryContent(content) {
+		return &FileReadResult{
+			IsBinary: true,
+			Content:  "Binary file",
+			FileSize: int64(len(data)),
+		}, nil
+	}
+
+	content, totalLines := sliceLines(content, req.Offset, req.Limit)
+	return &FileReadResult{
+		Content:    content,
+		TotalLines: totalLines,
+	}, nil
+}
+
+// WriteFile writes a file under /workspace, creating parent directories as
+// needed. The rest of the sandbox's filesystem is read-only.
+func (p *NamespaceProvider) WriteFile(_ context.Context, providerID string, req *FileWriteRequest) error {
+	sb, err := p.getSandbox(providerID)
+	if err != nil {
+		return err
+	}
+
+	dir, rel, writable := sb.resolvePath(req.FilePath)
+	if !writable || rel == "." {
+		return fmt.Errorf("cannot write %s: only files under %s are writable", req.FilePath, workspaceDir)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open sandbox filesystem: %w", err)
+	}
+	defer root.Close()
+
+	// Create parent directories
+	parent := ""
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if part == "." {
+			continue
+		}
+		parent = path.Join(parent, part)
+		if err := root.Mkdir(parent, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to create parent directories: %w", err)
+		}
+	}
+
+	f, err := root.OpenFile(rel, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if _, err := f.WriteString(req.Content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// sliceLines applies a 1-indexed line offset and a line limit to file
+// content, numbering the returned lines. Content is returned unchanged when
+// neither is set. It also returns the file's total line count.
+func sliceLines(content string, offset, limit int) (string, int) {
+	lines := strings.Split(content, "\n")
+	totalLines := len(lines)
+	if offset <= 0 && limit <= 0 {
+		return content, totalLines
+	}
+
+	start := 0
+	if offset > 0 {
+		start = offset - 1 // 1-indexed
+		if start >= len(lines) {
+			start = len(lines)
+		}
+	}
+	end := len(lines)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	// Format with line numbers
+	var sb strings.Builder
+	for i, line := range lines[start:end] {
+		fmt.Fprintf(&sb, "%d: %s\n", start+i+1, line)
+	}
+	return sb.String(), totalLines
+}
+
+// ListFiles returns the files under a directory whose name matches a glob
+// pattern, most recently modified first.
+func (p *NamespaceProvider) ListFiles(_ context.Context, providerID string, req *FileListRequest) (*FileListResult, error) {
+	sb, err := p.getSandbox(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	searchPath := req.Path
+	if searchPath == "" {
+		searchPath = workspaceDir
+	}
+	pattern := req.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	dir, rel, writable := sb.resolvePath(searchPath)
+	base := "/"
+	if writable {
+		base = workspaceDir
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sandbox filesystem: %w", err)
+	}
+	defer root.Close()
+
+	files := []FileInfo{}
+	_ = fs.WalkDir(root.FS(), rel, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable entries, like find 2>/dev/null
+			return nil
+		}
+		if ok, _ := path.Match(pattern, d.Name()); !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, FileInfo{
+			Path:       path.Join(base, p),
+			IsDir:      d.IsDir(),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+		return nil
+	})
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].ModifiedAt.After(files[j].ModifiedAt) })
+	if len(files) > nsMaxListedFiles {
+		files = files[:nsMaxListedFiles]
+	}
+	return &FileListResult{Files: files}, nil
+}
+
+// Snapshot copies a sandbox's workspace directory. Running commands are
+// frozen during the copy when cgroups are enabled.
+func (p *NamespaceProvider) Snapshot(_ context.Context, providerID string) (string, error) {
+	p.mu.RLock()
+	sb, ok := p.sandboxes[providerID]
+	p.mu.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("sandbox not found: %s", providerID)
+	}
+
+	snapshotID := fmt.Sprintf("ns-snap-%d", time.Now().UnixNano())
+	snapshotDir := filepath.Join(p.config.DataDir, "snapshots", snapshotID)
+	if err := os.MkdirAll(snapshotDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	if !sb.Stopped {
+		if err := p.freeze(sb.ID, true); err != nil {
+			_ = os.RemoveAll(snapshotDir)
+			return "", fmt.Errorf("failed to freeze sandbox for snapshot: %w", err)
+		}
+		defer func() {
+			if err := p.freeze(sb.ID, false); err != nil {
+				p.log.Warn("failed to thaw sandbox after snapshot", "sandbox_id", providerID, "error", err)
+			}
+		}()
+	}
+
+	if err := copyDir(sb.workspacePath(), filepath.Join(snapshotDir, "workspace")); err != nil {
+		_ = os.RemoveAll(snapshotDir)
+		return "", fmt.Errorf("failed to copy workspace: %w", err)
+	}
+	snap := *sb
+	snap.Stopped = false
+	if err := writeNamespaceSandbox(filepath.Join(snapshotDir, "sandbox.json"), &snap); err != nil {
+		_ = os.RemoveAll(snapshotDir)
+		return "", err
+	}
+
+	p.log.Info("namespace sandbox snapshot created",
+		"sandbox_id", providerID,
+		"snapshot_id", snapshotID,
+	)
+	return snapshotID, nil
+}
+
+// CreateFromSnapshot creates a sandbox with a copy of a snapshot's workspace,
+// over the snapshot's root filesystem unless req names another.
+func (p *NamespaceProvider) CreateFromSnapshot(_ context.Context, snapshotID string, req *CreateContainerRequest) (*CreateContainerResult, error) {
+	if !nsSnapshotID.MatchString(snapshotID) {
+		return nil, fmt.Errorf("invalid snapshot ID: %s", snapshotID)
+	}
+	snapshotDir := filepath.Join(p.config.DataDir, "snapshots", snapshotID)
+	snap, err := loadNamespaceSandbox(filepath.Join(snapshotDir, "sandbox.json"))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot not found: %s: %w", snapshotID, err)
+	}
+
+	restored := *req
+	if len(snap.Env) > 0 {
+		env := make(map[string]string, len(snap.Env)+len(req.Env))
+		for k, v := range snap.Env {
+			env[k] = v
+		}
+		for k, v := range req.Env {
+			env[k] = v
+		}
+		restored.Env = env
+	}
+	if restored.ResourceLimits == nil {
+		restored.ResourceLimits = snap.Limits
+	}
+	if restored.Egress == nil && snap.Network == NetworkModeNone {
+		restored.Egress = &EgressPolicy{Mode: NetworkModeNone}
+	}
+
+	result, err := p.create(&restored, snap.Rootfs, filepath.Join(snapshotDir, "workspace"))
+	if err != nil {
+		return nil, err
+	}
+	p.log.Info("namespace sandbox restored from snapshot",
+		"sandbox_id", result.ProviderID,
+		"snapshot_id", snapshotID,
+	)
+	return result, nil
+}
+
+// Health reports whether sandboxes can run and how many exist.
+func (p *NamespaceProvider) Health(_ context.Context) (*HealthStatus, error) {
+	p.mu.RLock()
+	activeCount := len(p.sandboxes)
+	p.mu.RUnlock()
+
+	if !p.available {
+		return &HealthStatus{
+			Healthy: false,
+			Message: "namespace sandboxes not available — " + p.unavailableReason,
+		}, nil
+	}
+
+	msg := fmt.Sprintf("bubblewrap available, %d sandboxes", activeCount)
+	if p.cgroups {
+		msg += ", cgroup v2 limits and freezer active"
+	} else {
+		msg += ", no cgroup delegation (limits and freezing disabled)"
+	}
+	if p.seccomp == nil {
+		msg += ", no seccomp filter"
+	}
+
+	return &HealthStatus{
+		Healthy:     true,
+		Message:     msg,
+		ActiveCount: activeCount,
+	}, nil
+}
+
+// IsAvailable returns whether this host can run namespace sandboxes.
+func (p *NamespaceProvider) IsAvailable() bool {
+	return p.available
+}
+
+// UnavailableReason explains why namespace sandboxes cannot run, if they cannot.
+func (p *NamespaceProvider) UnavailableReason() string {
+	return p.unavailableReason
+}
+
+// --- Internal Helpers ---
+
+// getSandbox retrieves a sandbox by provider ID, returning an error if not found or stopped.
+func (p *NamespaceProvider) getSandbox(providerID string) (*namespaceSandbox, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	sb, ok := p.sandboxes[providerID]
+	if !ok {
+		return nil, fmt.Errorf("sandbox not found: %s", providerID)
+	}
+	if sb.Stopped {
+		return nil, fmt.Errorf("sandbox is stopped: %s", providerID)
+	}
+	return sb, nil
+}
+
+// save persists a sandbox's state.
+func (p *NamespaceProvider) save(sb *namespaceSandbox) error {
+	return writeNamespaceSandbox(filepath.Join(sb.dir, "sandbox.json"), sb)
+}
+
+// writeNamespaceSandbox writes a sandbox's state to a file.
+func writeNamespaceSandbox(file string, sb *namespaceSandbox) error {
+	data, err := json.Marshal(sb)
+	if err != nil {
+		return fmt.Errorf("failed to encode sandbox state: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("failed to save sandbox state: %w", err)
+	}
+	return nil
+}
+
+// loadNamespaceSandbox reads a sandbox's state from a file.
+func loadNamespaceSandbox(file string) (*namespaceSandbox, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var sb namespaceSandbox
+	if err := json.Unmarshal(data, &sb); err != nil {
+		return nil, fmt.Errorf("invalid sandbox state: %w", err)
+	}
+	return &sb, nil
+}
+
+// cgroupPath returns the cgroup directory of a sandbox.
+func (p *NamespaceProvider) cgroupPath(id string) string {
+	return filepath.Join(p.config.CgroupParent, id)
+}
+
+// createCgroup creates a sandbox's cgroup and applies its resource limits.
+// Limits the host cannot enforce are logged and skipped.
+func (p *NamespaceProvider) createCgroup(sb *namespaceSandbox) error {
+	if !p.cgroups {
+		return nil
+	}
+	cg := p.cgroupPath(sb.ID)
+	if err := os.Mkdir(cg, 0755); err != nil {
+		return fmt.Errorf("failed to create sandbox cgroup: %w", err)
+	}
+
+	limits := map[string]string{"pids.max": strconv.Itoa(nsPidsMax)}
+	if sb.Limits != nil {
+		if cpus := parseFloat(sb.Limits.CPU); cpus > 0 {
+			limits["cpu.max"] = fmt.Sprintf("%d 100000", int64(cpus*100000))
+		}
+		if mem := parseMemoryBytes(sb.Limits.Memory); mem > 0 {
+			limits["memory.max"] = strconv.FormatInt(mem, 10)
+		}
+		if sb.Limits.Disk != "" {
+			p.log.Debug("disk limit not enforced for namespace sandboxes", "sandbox_id", sb.ID, "disk", sb.Limits.Disk)
+		}
+	}
+	for file, value := range limits {
+		if err := os.WriteFile(filepath.Join(cg, file), []byte(value), 0); err != nil {
+			p.log.Warn("failed to apply cgroup limit", "sandbox_id", sb.ID, "file", file, "error", err)
+		}
+	}
+	return nil
+}
+
+// freeze freezes or thaws a sandbox's cgroup. It does nothing without cgroups.
+func (p *NamespaceProvider) freeze(id string, frozen bool) error {
+	if !p.cgroups {
+		return nil
+	}
+	value := "0"
+	if frozen {
+		value = "1"
+	}
+	if err := os.WriteFile(filepath.Join(p.cgroupPath(id), "cgroup.freeze"), []byte(value), 0); err != nil {
+		return fmt.Errorf("failed to update cgroup freezer: %w", err)
+	}
+	return nil
+}
+
+// removeCgroup kills a sandbox's processes and removes its cgroup.
+func (p *NamespaceProvider) removeCgroup(id string) {
+	if !p.cgroups {
+		return
+	}
+	cg := p.cgroupPath(id)
+	// cgroup.kill needs Linux 5.14; older kernels leave running commands to their timeout
+	_ = os.WriteFile(filepath.Join(cg, "cgroup.kill"), []byte("1"), 0)
+	_ = os.WriteFile(filepath.Join(cg, "cgroup.freeze"), []byte("0"), 0)
+
+	var err error
+	for range 20 {
+		if err = os.Remove(cg); err == nil || errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	p.log.Warn("failed to remove sandbox cgroup", "sandbox_id", id, "error", err)
+}
+
+// copyDir copies a directory tree, preserving modes and using copy-on-write
+// clones where the filesystem supports them.
+func copyDir(src, dst string) error {
+	out, err := exec.Command("cp", "-a", "--reflink=auto", src, dst).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
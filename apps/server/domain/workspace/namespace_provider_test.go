@@ -0,0 +1,198 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNamespaceProvider returns a provider over a temporary data
+// directory with a minimal "default" root filesystem. It never runs
+// bubblewrap, so only the filesystem operations are usable.
+func newTestNamespaceProvider(t *testing.T) *NamespaceProvider {
+	t.Helper()
+	dataDir := t.TempDir()
+	rootfs := filepath.Join(dataDir, "rootfs", nsDefaultRootfs)
+	require.NoError(t, os.MkdirAll(filepath.Join(rootfs, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootfs, "bin", "sh"), []byte("#!"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(rootfs, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootfs, "etc", "os-release"), []byte("ID=test\n"), 0644))
+	for _, sub := range []string{"sandboxes", "snapshots"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, sub), 0700))
+	}
+
+	return &NamespaceProvider{
+		log:       testLogger(),
+		config:    &NamespaceProviderConfig{DataDir: dataDir, RootfsDir: filepath.Join(dataDir, "rootfs")},
+		available: true,
+		sandboxes: make(map[string]*namespaceSandbox),
+	}
+}
+
+func TestNamespaceSandboxResolvePath(t *testing.T) {
+	sb := &namespaceSandbox{Rootfs: "/srv/rootfs", dir: "/data/ns-1"}
+
+	tests := []struct {
+		path     string
+		dir      string
+		rel      string
+		writable bool
+	}{
+		{"/workspace", "/data/ns-1/workspace", ".", true},
+		{"/workspace/src/main.go", "/data/ns-1/workspace", "src/main.go", true},
+		{"src/main.go", "/data/ns-1/workspace", "src/main.go", true},
+		{"/workspace/../etc/passwd", "/srv/rootfs", "etc/passwd", false},
+		{"/workspacefoo", "/srv/rootfs", "workspacefoo", false},
+		{"/", "/srv/rootfs", ".", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			dir, rel, writable := sb.resolvePath(tt.path)
+			assert.Equal(t, tt.dir, dir)
+			assert.Equal(t, tt.rel, rel)
+			assert.Equal(t, tt.writable, writable)
+		})
+	}
+}
+
+func TestNamespaceProviderFiles(t *testing.T) {
+	p := newTestNamespaceProvider(t)
+	ctx := context.Background()
+
+	result, err := p.Create(ctx, &CreateContainerRequest{ContainerType: ContainerTypeAgentWorkspace})
+	require.NoError(t, err)
+	id := result.ProviderID
+
+	require.NoError(t, p.WriteFile(ctx, id, &FileWriteRequest{FilePath: "/workspace/src/main.go", Content: "package main\n\nfunc main() {}\n"}))
+
+	read, err := p.ReadFile(ctx, id, &FileReadRequest{FilePath: "src/main.go", Offset: 3, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "3: func main() {}\n", read.Content)
+	assert.Equal(t, 4, read.TotalLines)
+
+	dir, err := p.ReadFile(ctx, id, &FileReadRequest{FilePath: "/workspace"})
+	require.NoError(t, err)
+	assert.True(t, dir.IsDir)
+	assert.Equal(t, "src/\n", dir.Content)
+
+	osRelease, err := p.ReadFile(ctx, id, &FileReadRequest{FilePath: "/etc/os-release"})
+	require.NoError(t, err)
+	assert.Equal(t, "ID=test\n", osRelease.Content)
+
+	_, err = p.ReadFile(ctx, id, &FileReadRequest{FilePath: "/workspace/missing.txt"})
+	assert.EqualError(t, err, "file not found: /workspace/missing.txt")
+
+	err = p.WriteFile(ctx, id, &FileWriteRequest{FilePath: "/etc/passwd", Content: "root::0:0::/:/bin/sh\n"})
+	assert.ErrorContains(t, err, "only files under /workspace are writable")
+
+	// Symlinks cannot lead file operations out of the workspace
+	require.NoError(t, os.Symlink("/etc", filepath.Join(p.sandboxes[id].workspacePath(), "host-etc")))
+	_, err = p.ReadFile(ctx, id, &FileReadRequest{FilePath: "/workspace/host-etc/hostname"})
+	assert.Error(t, err)
+	err = p.WriteFile(ctx, id, &FileWriteRequest{FilePath: "/workspace/host-etc/evil", Content: "x"})
+	assert.Error(t, err)
+
+	list, err := p.ListFiles(ctx, id, &FileListRequest{Pattern: "*.go"})
+	require.NoError(t, err)
+	require.Len(t, list.Files, 1)
+	assert.Equal(t, "/workspace/src/main.go", list.Files[0].Path)
+}
+
+func TestNamespaceProviderLifecycle(t *testing.T) {
+	p := newTestNamespaceProvider(t)
+	ctx := context.Background()
+
+	_, err := p.Create(ctx, &CreateContainerRequest{ContainerType: ContainerTypeMCPServer})
+	assert.ErrorContains(t, err, "does not host MCP server containers")
+	_, err = p.Create(ctx, &CreateContainerRequest{ContainerType: ContainerTypeAgentWorkspace, BaseImage: "ghcr.io/acme/image:latest"})
+	assert.ErrorContains(t, err, "needs a root filesystem name")
+	_, err = p.Create(ctx, &CreateContainerRequest{ContainerType: ContainerTypeAgentWorkspace, Egress: &EgressPolicy{Mode: NetworkModeAllowList}})
+	assert.EqualError(t, err, "namespace provider cannot enforce a network allow-list")
+
+	result, err := p.Create(ctx, &CreateContainerRequest{
+		ContainerType: ContainerTypeAgentWorkspace,
+		Env:           map[string]string{"GOFLAGS": "-mod=mod"},
+	})
+	require.NoError(t, err)
+	id := result.ProviderID
+	require.NoError(t, p.WriteFile(ctx, id, &FileWriteRequest{FilePath: "notes.txt", Content: "v1"}))
+
+	require.NoError(t, p.Stop(ctx, id))
+	assert.EqualError(t, p.Stop(ctx, id), "sandbox is already stopped: "+id)
+	_, err = p.Exec(ctx, id, &ExecRequest{Command: "true"})
+	assert.EqualError(t, err, "sandbox is stopped: "+id)
+
+	// Snapshots work on stopped sandboxes
+	snapshotID, err := p.Snapshot(ctx, id)
+	require.NoError(t, err)
+	require.NoError(t, p.Resume(ctx, id))
+	require.NoError(t, p.WriteFile(ctx, id, &FileWriteRequest{FilePath: "notes.txt", Content: "v2"}))
+
+	restored, err := p.CreateFromSnapshot(ctx, snapshotID, &CreateContainerRequest{ContainerType: ContainerTypeAgentWorkspace})
+	require.NoError(t, err)
+	read, err := p.ReadFile(ctx, restored.ProviderID, &FileReadRequest{FilePath: "notes.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", read.Content)
+	assert.Equal(t, map[string]string{"GOFLAGS": "-mod=mod"}, p.sandboxes[restored.ProviderID].Env)
+
+	_, err = p.CreateFromSnapshot(ctx, "../sandboxes", &CreateContainerRequest{})
+	assert.EqualError(t, err, "invalid snapshot ID: ../sandboxes")
+
+	// Sandboxes survive a restart
+	reloaded := &NamespaceProvider{log: testLogger(), config: p.config, available: true, sandboxes: make(map[string]*namespaceSandbox)}
+	require.NoError(t, reloaded.restore())
+	assert.Len(t, reloaded.sandboxes, 2)
+
+	require.NoError(t, p.Destroy(ctx, id))
+	assert.NoDirExists(t, filepath.Join(p.config.DataDir, "sandboxes", id))
+	assert.EqualError(t, p.Destroy(ctx, id), "sandbox not found: "+id)
+}
+
+func TestNamespaceProviderBwrapArgs(t *testing.T) {
+	p := &NamespaceProvider{seccomp: []byte{0}}
+	sb := &namespaceSandbox{ID: "ns-1", Rootfs: "/srv/rootfs", Env: map[string]string{"B": "2", "A": "1"}, dir: "/data/ns-1"}
+
+	args := p.bwrapArgs(sb, "/workspace/src", "go test ./...")
+	assert.Contains(t, args, "--unshare-user")
+	assert.Subset(t, args, []string{"--ro-bind", "/srv/rootfs", "/", "--bind", "/data/ns-1/workspace", "/workspace"})
+	assert.Equal(t, []string{"--seccomp", "3"}, args[len(args)-8:len(args)-6])
+	assert.Equal(t, []string{"--chdir", "/workspace/src", "--", "/bin/sh", "-c", "go test ./..."}, args[len(args)-6:])
+	assert.NotContains(t, args, "--unshare-net")
+	assert.NotContains(t, args, "--setenv", "environment values must not be visible in the command line")
+	assert.NotContains(t, args, "1")
+
+	assert.Equal(t, []string{"A=1", "B=2", "HOME=/workspace", "PATH=" + nsDefaultPath}, sandboxEnv(sb))
+	sb.Env["PATH"] = "/opt/bin"
+	assert.Contains(t, sandboxEnv(sb), "PATH=/opt/bin")
+
+	sb.Network = NetworkModeNone
+	assert.Contains(t, p.bwrapArgs(sb, "/workspace", "true"), "--unshare-net")
+}
+
+func TestSeccompFilter(t *testing.T) {
+	filter, err := seccompFilter()
+	if err != nil {
+		t.Skipf("no seccomp filter on this host: %v", err)
+	}
+	// Instructions are 8 bytes: the arch check, the syscall checks, allow and deny
+	assert.Zero(t, len(filter)%8)
+	assert.Greater(t, len(filter)/8, len(seccompDeniedSyscalls)+5)
+}
+
+func TestOrchestratorFallbackSkipsUnsupportedContainerTypes(t *testing.T) {
+	o := NewOrchestrator(testLogger())
+	ns := &NamespaceProvider{available: true, sandboxes: make(map[string]*namespaceSandbox)}
+	o.RegisterProvider(ProviderNamespace, ns)
+
+	p, pt, err := o.SelectProviderWithFallback(ContainerTypeAgentWorkspace, DeploymentSelfHosted, "auto")
+	require.NoError(t, err)
+	assert.Equal(t, ProviderNamespace, pt)
+	assert.Same(t, ns, p)
+
+	_, _, err = o.SelectProviderWithFallback(ContainerTypeMCPServer, DeploymentSelfHosted, "auto")
+	assert.Error(t, err, "namespace sandboxes cannot host MCP servers")
+}
@@ -0,0 +1,115 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// seccompDeniedSyscalls are the system calls namespace sandboxes may not
+// make: kernel and module management, mounts and namespace changes, tracing
+// other processes, keyrings, BPF and perf, and clock changes. They fail with
+// EPERM.
+var seccompDeniedSyscalls = []uint32{
+	unix.SYS_ACCT,
+	unix.SYS_ADD_KEY,
+	unix.SYS_BPF,
+	unix.SYS_CLOCK_ADJTIME,
+	unix.SYS_CLOCK_SETTIME,
+	unix.SYS_DELETE_MODULE,
+	unix.SYS_FINIT_MODULE,
+	unix.SYS_FSCONFIG,
+	unix.SYS_FSMOUNT,
+	unix.SYS_FSOPEN,
+	unix.SYS_FSPICK,
+	unix.SYS_INIT_MODULE,
+	unix.SYS_KEXEC_FILE_LOAD,
+	unix.SYS_KEXEC_LOAD,
+	unix.SYS_KEYCTL,
+	unix.SYS_MOUNT,
+	unix.SYS_MOUNT_SETATTR,
+	unix.SYS_MOVE_MOUNT,
+	unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_OPEN_TREE,
+	unix.SYS_PERF_EVENT_OPEN,
+	unix.SYS_PIVOT_ROOT,
+	unix.SYS_PROCESS_VM_READV,
+	unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_PTRACE,
+	unix.SYS_QUOTACTL,
+	unix.SYS_REBOOT,
+	unix.SYS_REQUEST_KEY,
+	unix.SYS_SETNS,
+	unix.SYS_SETTIMEOFDAY,
+	unix.SYS_SWAPOFF,
+	unix.SYS_SWAPON,
+	unix.SYS_SYSLOG,
+	unix.SYS_UMOUNT2,
+	unix.SYS_UNSHARE,
+	unix.SYS_USERFAULTFD,
+}
+
+// seccompAuditArch maps GOARCH to the audit architecture seccomp reports.
+var seccompAuditArch = map[string]uint32{
+	"amd64": unix.AUDIT_ARCH_X86_64,
+	"arm64": unix.AUDIT_ARCH_AARCH64,
+}
+
+// seccompX32Bit marks x32 ABI system calls on amd64, which the filter denies
+// so they cannot bypass it.
+const seccompX32Bit = 0x40000000
+
+// seccompFilter compiles the sandbox's seccomp filter into the classic BPF
+// program bubblewrap's --seccomp option expects. Calls from other
+// architectures kill the process; denied calls fail with EPERM.
+func seccompFilter() ([]byte, error) {
+	arch, ok := seccompAuditArch[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("no seccomp filter for architecture %s", runtime.GOARCH)
+	}
+
+	const (
+		archOffset = 4 // offsetof(struct seccomp_data, arch)
+		nrOffset   = 0 // offsetof(struct seccomp_data, nr)
+	)
+	prog := []unix.SockFilter{
+		{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: archOffset},
+		{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: 1, K: arch},
+		{Code: unix.BPF_RET | unix.BPF_K, K: unix.SECCOMP_RET_KILL_PROCESS},
+		{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: nrOffset},
+	}
+	// Each check jumps to the deny instruction at the end
+	checks := len(seccompDeniedSyscalls)
+	if runtime.GOARCH == "amd64" {
+		checks++
+		prog = append(prog, unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K, Jt: uint8(checks), K: seccompX32Bit})
+	}
+	for i, nr := range seccompDeniedSyscalls {
+		prog = append(prog, unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: uint8(len(seccompDeniedSyscalls) - i), K: nr})
+	}
+	prog = append(prog,
+		unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: unix.SECCOMP_RET_ALLOW},
+		unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)},
+	)
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.NativeEndian, prog); err != nil {
+		return nil, fmt.Errorf("failed to encode seccomp filter: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sandboxProcAttr returns the attributes of a bubblewrap process: killed
+// with the server, and started in the sandbox's cgroup when cgroupFD is set.
+func sandboxProcAttr(cgroupFD int) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+	if cgroupFD >= 0 {
+		attr.UseCgroupFD = true
+		attr.CgroupFD = cgroupFD
+	}
+	return attr
+}
@@ -69,6 +69,11 @@ func (o *Orchestrator) ListProviders() []ProviderStatusResponse {
 
 // SelectProvider chooses the best provider based on container type, deployment mode, and availability.
 func (o *Orchestrator) SelectProvider(containerType ContainerType, deploymentMode DeploymentMode, requested ProviderType) (Provider, ProviderType, error) {
+	return o.selectProvider(containerType, deploymentMode, requested, NetworkModeOpen)
+}
+
+// selectProvider is SelectProvider restricted to providers that can enforce the network mode.
+func (o *Orchestrator) selectProvider(containerType ContainerType, deploymentMode DeploymentMode, requested ProviderType, networkMode NetworkMode) (Provider, ProviderType, error) {
 	o.mu.RLock()
 	defer o.mu.RUnlock()
 
@@ -81,6 +86,9 @@ func (o *Orchestrator) SelectProvider(containerType ContainerType, deploymentMod
 		if h, ok := o.health[requested]; ok && !h.Healthy {
 			return nil, "", fmt.Errorf("requested provider %q is unhealthy: %s", requested, h.Message)
 		}
+		if !p.Capabilities().SupportsNetworkMode(networkMode) {
+			return nil, "", fmt.Errorf("requested provider %q cannot enforce network mode %q", requested, networkMode)
+		}
 		return p, requested, nil
 	}
 
@@ -89,7 +97,7 @@ func (o *Orchestrator) SelectProvider(containerType ContainerType, deploymentMod
 
 	for _, pt := range chain {
 		p, ok := o.providers[pt]
-		if !ok {
+		if !ok || !p.Capabilities().SupportsNetworkMode(networkMode) {
 			continue
 		}
 		if h, ok := o.health[pt]; ok && !h.Healthy {
@@ -105,8 +113,14 @@ func (o *Orchestrator) SelectProvider(containerType ContainerType, deploymentMod
 
 // SelectProviderWithFallback tries the primary provider and falls back on failure.
 func (o *Orchestrator) SelectProviderWithFallback(containerType ContainerType, deploymentMode DeploymentMode, requested ProviderType) (Provider, ProviderType, error) {
+	return o.SelectProviderForNetwork(containerType, deploymentMode, requested, NetworkModeOpen)
+}
+
+// SelectProviderForNetwork is SelectProviderWithFallback for workspaces with
+// a network policy: only providers that can enforce its mode are chosen.
+func (o *Orchestrator) SelectProviderForNetwork(containerType ContainerType, deploymentMode DeploymentMode, requested ProviderType, networkMode NetworkMode) (Provider, ProviderType, error) {
 	// Try explicit selection first
-	p, pt, err := o.SelectProvider(containerType, deploymentMode, requested)
+	p, pt, err := o.selectProvider(containerType, deploymentMode, requested, networkMode)
 	if err == nil {
 		return p, pt, nil
 	}
@@ -121,6 +135,10 @@ func (o *Orchestrator) SelectProviderWithFallback(containerType ContainerType, d
 	defer o.mu.RUnlock()
 
 	for pt, p := range o.providers {
+		caps := p.Capabilities()
+		if !caps.SupportsContainerType(containerType) || !caps.SupportsNetworkMode(networkMode) {
+			continue
+		}
 		if h, ok := o.health[pt]; ok && h.Healthy {
 			o.log.Warn("falling back to alternative provider", "type", pt, "original_error", err)
 			return p, pt, nil
@@ -229,7 +247,7 @@ func (o *Orchestrator) buildSelectionChain(containerType ContainerType, deployme
 		if containerType == ContainerTypeMCPServer {
 			return []ProviderType{ProviderGVisor, ProviderE2B, ProviderFirecracker}
 		}
-		return []ProviderType{ProviderE2B, ProviderFirecracker, ProviderGVisor}
+		return []ProviderType{ProviderE2B, ProviderFirecracker, ProviderGVisor, ProviderNamespace}
 	}
 
 	// Self-hosted mode
@@ -238,6 +256,7 @@ func (o *Orchestrator) buildSelectionChain(containerType ContainerType, deployme
 		return []ProviderType{ProviderGVisor, ProviderFirecracker, ProviderE2B}
 	}
 
-	// Agent workspaces prefer Firecracker (better isolation)
-	return []ProviderType{ProviderFirecracker, ProviderGVisor, ProviderE2B}
+	// Agent workspaces prefer Firecracker (better isolation); namespace
+	// sandboxes share the host kernel, so they come after gVisor
+	return []ProviderType{ProviderFirecracker, ProviderGVisor, ProviderNamespace, ProviderE2B}
 }
@@ -23,6 +23,7 @@ type mockProvider struct {
 	fromSnapshotErr   error
 	snapshotID        string // returned by Snapshot()
 	supportsSnapshots bool
+	networkModes      []NetworkMode
 	createCount       atomic.Int64
 	destroyCount      atomic.Int64
 }
@@ -82,6 +83,7 @@ func (m *mockProvider) Capabilities() *ProviderCapabilities {
 		Name:              m.name,
 		ProviderType:      m.providerType,
 		SupportsSnapshots: m.supportsSnapshots,
+		NetworkModes:      m.networkModes,
 	}
 }
 
@@ -229,6 +231,31 @@ func TestOrchestratorFallbackAuto(t *testing.T) {
 	assert.NotNil(t, p)
 }
 
+func TestOrchestratorSelectProviderForNetwork(t *testing.T) {
+	o := NewOrchestrator(testLogger())
+
+	fc := &mockProvider{name: "fc", providerType: ProviderFirecracker, healthy: true}
+	gv := &mockProvider{name: "gv", providerType: ProviderGVisor, healthy: true, networkModes: []NetworkMode{NetworkModeNone, NetworkModeAllowList}}
+	o.RegisterProvider(ProviderFirecracker, fc)
+	o.RegisterProvider(ProviderGVisor, gv)
+
+	// Firecracker comes first but cannot enforce an allow-list
+	_, pt, err := o.SelectProviderForNetwork(ContainerTypeAgentWorkspace, DeploymentSelfHosted, "", NetworkModeAllowList)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderGVisor, pt)
+
+	_, pt, err = o.SelectProviderForNetwork(ContainerTypeAgentWorkspace, DeploymentSelfHosted, "", NetworkModeOpen)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderFirecracker, pt)
+
+	_, _, err = o.SelectProviderForNetwork(ContainerTypeAgentWorkspace, DeploymentSelfHosted, ProviderFirecracker, NetworkModeNone)
+	assert.EqualError(t, err, `requested provider "firecracker" cannot enforce network mode "none"`)
+
+	o.UpdateHealth(ProviderGVisor, false, "down")
+	_, _, err = o.SelectProviderForNetwork(ContainerTypeAgentWorkspace, DeploymentSelfHosted, "auto", NetworkModeAllowList)
+	assert.Error(t, err, "fallback must not pick a provider that cannot enforce the mode")
+}
+
 func TestOrchestratorNoProviders(t *testing.T) {
 	o := NewOrchestrator(testLogger())
 
@@ -250,7 +277,7 @@ func TestBuildSelectionChain(t *testing.T) {
 			"self-hosted workspace",
 			ContainerTypeAgentWorkspace,
 			DeploymentSelfHosted,
-			[]ProviderType{ProviderFirecracker, ProviderGVisor, ProviderE2B},
+			[]ProviderType{ProviderFirecracker, ProviderGVisor, ProviderNamespace, ProviderE2B},
 		},
 		{
 			"self-hosted MCP",
@@ -262,7 +289,7 @@ func TestBuildSelectionChain(t *testing.T) {
 			"managed workspace",
 			ContainerTypeAgentWorkspace,
 			DeploymentManaged,
-			[]ProviderType{ProviderE2B, ProviderFirecracker, ProviderGVisor},
+			[]ProviderType{ProviderE2B, ProviderFirecracker, ProviderGVisor, ProviderNamespace},
 		},
 		{
 			"managed MCP",
@@ -3,10 +3,11 @@ package workspace
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 )
 
-// Provider defines the interface that all workspace providers (Firecracker, E2B, gVisor) must implement.
+// Provider defines the interface that all workspace providers (Firecracker, E2B, gVisor, namespace) must implement.
 type Provider interface {
 	// Create provisions a new workspace container/VM and returns its provider-specific ID.
 	Create(ctx context.Context, req *CreateContainerRequest) (*CreateContainerResult, error)
@@ -61,6 +62,22 @@ type ProviderCapabilities struct {
 	RequiresKVM         bool         `json:"requires_kvm"`
 	EstimatedStartupMs  int          `json:"estimated_startup_ms"`
 	ProviderType        ProviderType `json:"provider_type"`
+	// ContainerTypes lists the container types the provider can create.
+	// Empty means all of them.
+	ContainerTypes []ContainerType `json:"container_types,omitempty"`
+	// NetworkModes lists the restricted network modes (none, allowlist)
+	// the provider can enforce. Every provider supports open.
+	NetworkModes []NetworkMode `json:"network_modes,omitempty"`
+}
+
+// SupportsContainerType reports whether the provider can create containers of the type.
+func (c *ProviderCapabilities) SupportsContainerType(containerType ContainerType) bool {
+	return len(c.ContainerTypes) == 0 || slices.Contains(c.ContainerTypes, containerType)
+}
+
+// SupportsNetworkMode reports whether the provider can enforce the network mode.
+func (c *ProviderCapabilities) SupportsNetworkMode(mode NetworkMode) bool {
+	return mode == "" || mode == NetworkModeOpen || slices.Contains(c.NetworkModes, mode)
 }
 
 // CreateContainerRequest holds parameters for creating a new workspace container.
@@ -74,6 +91,8 @@ type CreateContainerRequest struct {
 	Env          map[string]string `json:"env,omitempty"`           // Environment variables
 	ExtraVolumes []string          `json:"extra_volumes,omitempty"` // Additional volume mount paths (e.g. "/data")
 	AttachStdin  bool              `json:"attach_stdin,omitempty"`  // Keep stdin open for stdio bridge
+	// Egress is the network policy the provider must enforce (nil = open).
+	Egress *EgressPolicy `json:"egress,omitempty"`
 }
 
 // CreateContainerResult holds the result of a container creation.
@@ -9,7 +9,7 @@ import (
 )
 
 // RegisterRoutes registers workspace HTTP routes.
-func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware, log *slog.Logger) {
+func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware, egress *EgressManager, log *slog.Logger) {
 	// Agent workspace routes
 	g := e.Group("/api/v1/agent/workspaces")
 	g.Use(authMiddleware.RequireAuth())
@@ -36,7 +36,7 @@ func RegisterRoutes(e *echo.Echo, h *Handler, authMiddleware *auth.Middleware, l
 	// Tool operations (require write scope + audit logging)
 	toolGroup := g.Group("/:id")
 	toolGroup.Use(authMiddleware.RequireAPITokenScopes("admin:write"))
-	toolGroup.Use(ToolAuditMiddleware(log))
+	toolGroup.Use(ToolAuditMiddleware(log, egress))
 	toolGroup.POST("/bash", h.BashTool)
 	toolGroup.POST("/read", h.ReadTool)
 	toolGroup.POST("/write", h.WriteTool)
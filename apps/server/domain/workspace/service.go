@@ -247,6 +247,8 @@ func (s *Service) resolveProvider(requested string) ProviderType {
 		return ProviderE2B
 	case string(ProviderGVisor):
 		return ProviderGVisor
+	case string(ProviderNamespace):
+		return ProviderNamespace
 	case "", "auto":
 		return s.config.DefaultProvider
 	default:
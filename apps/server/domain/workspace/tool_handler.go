@@ -56,6 +56,9 @@ func (h *Handler) getWorkspaceAndProvider(c echo.Context) (*WorkspaceResponse, P
 		_ = h.svc.TouchLastUsed(c.Request().Context(), id)
 	}()
 
+	// Lets the audit middleware find the workspace's egress proxy
+	c.Set(auditProviderIDKey, ws.ProviderWorkspaceID)
+
 	return ws, provider, nil
 }
 
@@ -3,6 +3,7 @@ package workspace
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -18,6 +19,25 @@ const (
 // ValidRepoSourceTypes lists all valid repo source types.
 var ValidRepoSourceTypes = []RepoSourceType{RepoSourceTaskContext, RepoSourceFixed, RepoSourceNone}
 
+// NetworkMode defines what a workspace may reach over the network.
+type NetworkMode string
+
+const (
+	NetworkModeNone      NetworkMode = "none"      // No network access at all
+	NetworkModeAllowList NetworkMode = "allowlist" // Only allowed domains, through the workspace's egress proxy
+	NetworkModeOpen      NetworkMode = "open"      // Unrestricted access (the default)
+)
+
+// ValidNetworkModes lists all valid network modes.
+var ValidNetworkModes = []NetworkMode{NetworkModeNone, NetworkModeAllowList, NetworkModeOpen}
+
+// domainPattern matches allow-list entries: a host name, optionally prefixed
+// with "*." to allow its subdomains.
+var domainPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// presetNamePattern matches network preset names.
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 // ValidToolNames lists all workspace tools that can be allowed.
 var ValidToolNames = []string{"bash", "read", "write", "edit", "glob", "grep", "git"}
 
@@ -25,13 +45,24 @@ var ValidToolNames = []string{"bash", "read", "write", "edit", "glob", "grep", "
 // Stored as JSONB in kb.agent_definitions.workspace_config.
 type AgentWorkspaceConfig struct {
 	Enabled         bool              `json:"enabled"`
-	Provider        string            `json:"provider,omitempty"` // Explicit provider: "firecracker", "gvisor", "e2b", or "" (auto)
+	Provider        string            `json:"provider,omitempty"` // Explicit provider: "firecracker", "gvisor", "e2b", "namespace", or "" (auto)
 	RepoSource      *RepoSourceConfig `json:"repo_source,omitempty"`
 	Tools           []string          `json:"tools,omitempty"`
 	ResourceLimits  *ResourceLimits   `json:"resource_limits,omitempty"`
 	CheckoutOnStart bool              `json:"checkout_on_start,omitempty"`
 	BaseImage       string            `json:"base_image,omitempty"`
 	SetupCommands   []string          `json:"setup_commands,omitempty"`
+	Network         *NetworkPolicy    `json:"network,omitempty"` // nil = open
+}
+
+// NetworkPolicy defines a workspace's egress network policy.
+type NetworkPolicy struct {
+	Mode NetworkMode `json:"mode"`
+	// AllowedDomains lists host names ("example.com") and subdomain
+	// wildcards ("*.example.com") reachable in allowlist mode.
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	// Presets names domain groups to allow, such as "git" or "npm".
+	Presets []string `json:"presets,omitempty"`
 }
 
 // RepoSourceConfig defines the repository source for a workspace.
@@ -128,6 +159,42 @@ func (c *AgentWorkspaceConfig) Validate() []string {
 		}
 	}
 
+	// Validate network policy
+	if c.Network != nil {
+		errs = append(errs, c.Network.validate()...)
+	}
+
+	return errs
+}
+
+// validate returns the network policy's validation errors.
+func (n *NetworkPolicy) validate() []string {
+	var errs []string
+
+	validMode := false
+	for _, m := range ValidNetworkModes {
+		if n.Mode == m {
+			validMode = true
+			break
+		}
+	}
+	if !validMode {
+		errs = append(errs, fmt.Sprintf("invalid network.mode: %q (valid: none, allowlist, open)", n.Mode))
+	}
+
+	if n.Mode != NetworkModeAllowList && (len(n.AllowedDomains) > 0 || len(n.Presets) > 0) {
+		errs = append(errs, "network.allowed_domains and network.presets require mode 'allowlist'")
+	}
+	for _, d := range n.AllowedDomains {
+		if !domainPattern.MatchString(strings.ToLower(strings.TrimSpace(d))) {
+			errs = append(errs, fmt.Sprintf("invalid network.allowed_domains entry: %q (use host names like example.com or *.example.com)", d))
+		}
+	}
+	for _, name := range n.Presets {
+		if !presetNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Sprintf("invalid network.presets entry: %q", name))
+		}
+	}
 	return errs
 }
 
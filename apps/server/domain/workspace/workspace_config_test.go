@@ -119,6 +119,32 @@ func TestValidate_MultipleErrors(t *testing.T) {
 	assert.GreaterOrEqual(t, len(errs), 3) // dup tool, invalid tool, missing URL
 }
 
+func TestValidate_NetworkPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		network *NetworkPolicy
+		errs    []string
+	}{
+		{"open", &NetworkPolicy{Mode: NetworkModeOpen}, nil},
+		{"none", &NetworkPolicy{Mode: NetworkModeNone}, nil},
+		{"allowlist", &NetworkPolicy{Mode: NetworkModeAllowList, AllowedDomains: []string{"api.acme.dev", "*.acme.dev"}, Presets: []string{"git", "npm"}}, nil},
+		{"invalid mode", &NetworkPolicy{Mode: "firewalled"}, []string{`invalid network.mode: "firewalled" (valid: none, allowlist, open)`}},
+		{"domains without allowlist", &NetworkPolicy{Mode: NetworkModeNone, Presets: []string{"git"}}, []string{"network.allowed_domains and network.presets require mode 'allowlist'"}},
+		{"invalid domains", &NetworkPolicy{Mode: NetworkModeAllowList, AllowedDomains: []string{"https://acme.dev", "acme.dev:443", "api.*.dev"}}, []string{
+			`invalid network.allowed_domains entry: "https://acme.dev" (use host names like example.com or *.example.com)`,
+			`invalid network.allowed_domains entry: "acme.dev:443" (use host names like example.com or *.example.com)`,
+			`invalid network.allowed_domains entry: "api.*.dev" (use host names like example.com or *.example.com)`,
+		}},
+		{"invalid preset", &NetworkPolicy{Mode: NetworkModeAllowList, Presets: []string{"Git Hub"}}, []string{`invalid network.presets entry: "Git Hub"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AgentWorkspaceConfig{Enabled: true, Network: tt.network}
+			assert.Equal(t, tt.errs, cfg.Validate())
+		})
+	}
+}
+
 func TestValidate_EmptyResourceLimits(t *testing.T) {
 	cfg := &AgentWorkspaceConfig{
 		Enabled: true,
@@ -58,6 +58,9 @@ type Config struct {
 	// Brave Search API configuration
 	BraveSearch BraveSearchConfig
 
+	// MCP server session configuration
+	MCP MCPConfig
+
 	// OpenTelemetry tracing configuration
 	Otel OtelConfig
 
@@ -325,7 +328,7 @@ type WorkspaceConfig struct {
 	AlertThresholdPct int `env:"WORKSPACE_ALERT_THRESHOLD_PCT" envDefault:"80"`
 	// WarmPoolSize is the number of pre-booted containers to keep ready (0 = disabled)
 	WarmPoolSize int `env:"WORKSPACE_WARM_POOL_SIZE" envDefault:"0"`
-	// DefaultProvider is the default sandbox provider (gvisor, firecracker, e2b)
+	// DefaultProvider is the default sandbox provider (gvisor, firecracker, e2b, namespace)
 	DefaultProvider string `env:"WORKSPACE_DEFAULT_PROVIDER" envDefault:"gvisor"`
 	// DefaultCPU is the default CPU limit for workspaces (e.g. "2")
 	DefaultCPU string `env:"WORKSPACE_DEFAULT_CPU" envDefault:"2"`
@@ -343,6 +346,16 @@ type WorkspaceConfig struct {
 	DefaultImage string `env:"WORKSPACE_DEFAULT_IMAGE" envDefault:""`
 	// FirecrackerDataDir is the directory containing Firecracker rootfs and kernel files
 	FirecrackerDataDir string `env:"WORKSPACE_FIRECRACKER_DATA_DIR" envDefault:"/var/lib/firecracker"`
+	// NamespaceDataDir is the directory holding namespace sandboxes, snapshots and root filesystems
+	NamespaceDataDir string `env:"WORKSPACE_NAMESPACE_DATA_DIR" envDefault:"/var/lib/emergent/namespace"`
+	// NamespaceRootfsDir holds the root filesystem directories namespace sandboxes run on (default: <data dir>/rootfs)
+	NamespaceRootfsDir string `env:"WORKSPACE_NAMESPACE_ROOTFS_DIR" envDefault:""`
+	// NamespaceCgroupParent is a cgroup v2 directory delegated to the server for namespace sandbox limits and freezing
+	NamespaceCgroupParent string `env:"WORKSPACE_NAMESPACE_CGROUP_PARENT" envDefault:""`
+	// EgressPresets adds or replaces network allow-list presets (e.g. "internal=git.acme.dev,*.pkg.acme.dev;docs=docs.acme.dev")
+	EgressPresets string `env:"WORKSPACE_EGRESS_PRESETS" envDefault:""`
+	// EgressProxyPort is the TCP port allowlist-mode workspace egress proxies listen on for HTTP(S)
+	EgressProxyPort int `env:"WORKSPACE_EGRESS_PROXY_PORT" envDefault:"3128"`
 }
 
 // IsEnabled returns true if agent workspaces are enabled
@@ -350,6 +363,18 @@ func (w *WorkspaceConfig) IsEnabled() bool {
 	return w.Enabled
 }
 
+// MCPConfig holds MCP Streamable HTTP session configuration
+type MCPConfig struct {
+	// SessionStore selects where sessions and their event logs live: "memory"
+	// (single replica) or "postgres" (shared across replicas). Empty picks
+	// memory in standalone mode and postgres otherwise.
+	SessionStore string `env:"MCP_SESSION_STORE" envDefault:""`
+	// SessionTTL is how long an idle session and its events are kept
+	SessionTTL time.Duration `env:"MCP_SESSION_TTL" envDefault:"24h"`
+	// MaxEventsPerSession is how many events are kept per session for Last-Event-ID resumption
+	MaxEventsPerSession int `env:"MCP_MAX_EVENTS_PER_SESSION" envDefault:"100"`
+}
+
 // BraveSearchConfig holds Brave Search API configuration
 type BraveSearchConfig struct {
 	// APIKey is the Brave Search API subscription token
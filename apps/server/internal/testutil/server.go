@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/uptrace/bun"
@@ -135,9 +136,11 @@ func newTestServerWithDB(testDB *TestDB, db bun.IDB) *TestServer {
 		})
 	})
 
+	eventsSvc := events.NewService(log)
+
 	// Register documents routes
 	docsRepo := documents.NewRepository(db, log)
-	docsSvc := documents.NewService(docsRepo, log)
+	docsSvc := documents.NewService(docsRepo, eventsSvc, log)
 	storageCfg := storage.NewConfig()
 	storageSvc, _ := storage.NewService(storageCfg, log)
 	docsHandler := documents.NewHandler(docsSvc, storageSvc, log)
@@ -218,9 +221,12 @@ func newTestServerWithDB(testDB *TestDB, db bun.IDB) *TestServer {
 
 	// Register MCP routes
 	mcpSvc := mcp.NewService(db, graphSvc, searchSvc, testDB.Config, log)
-	mcpHandler := mcp.NewHandler(mcpSvc, log)
-	mcpSSEHandler := mcp.NewSSEHandler(mcpSvc, mcpHandler, log)
-	mcpStreamableHandler := mcp.NewStreamableHTTPHandler(mcpSvc, log)
+	mcpNotifier := mcp.NewNotifier(db, eventsSvc, log)
+	mcpSessionStore := mcp.NewMemorySessionStore(100, time.Hour)
+	mcpNotifier.SetSessionStore(mcpSessionStore)
+	mcpHandler := mcp.NewHandler(mcpSvc, mcpNotifier, log)
+	mcpSSEHandler := mcp.NewSSEHandler(mcpSvc, mcpNotifier, log)
+	mcpStreamableHandler := mcp.NewStreamableHTTPHandler(mcpSvc, mcpNotifier, mcpSessionStore, log)
 	mcp.RegisterRoutes(e, mcpHandler, mcpSSEHandler, mcpStreamableHandler, authMiddleware)
 
 	// Register MCP registry routes
@@ -241,7 +247,6 @@ func newTestServerWithDB(testDB *TestDB, db bun.IDB) *TestServer {
 	invites.RegisterRoutes(e, invitesHandler, authMiddleware)
 
 	// Register events routes
-	eventsSvc := events.NewService(log)
 	eventsHandler := events.NewHandler(eventsSvc, log)
 	events.RegisterRoutesManual(e, eventsHandler, authMiddleware)
 
@@ -281,7 +286,7 @@ func newTestServerWithDB(testDB *TestDB, db bun.IDB) *TestServer {
 
 	// Register agents routes
 	agentsRepo := agents.NewRepository(db)
-	agentsHandler := agents.NewHandler(agentsRepo, nil, nil)
+	agentsHandler := agents.NewHandler(agentsRepo, nil, nil, nil)
 	agents.RegisterRoutes(e, agentsHandler, authMiddleware)
 
 	// Register extraction admin routes
@@ -309,7 +314,7 @@ func newTestServerWithDB(testDB *TestDB, db bun.IDB) *TestServer {
 	datasourceRegistry.Register(datasource.NewNoOpProvider("imap"))
 	datasourceRegistry.Register(datasource.NewNoOpProvider("gmail_oauth"))
 	datasourceRegistry.Register(datasource.NewNoOpProvider("google_drive"))
-	datasourceHandler := datasource.NewHandler(datasourceRepo, datasourceJobsSvc, datasourceRegistry, encryptionSvc, log)
+	datasourceHandler := datasource.NewHandler(datasourceRepo, datasourceJobsSvc, datasourceRegistry, encryptionSvc, storageSvc, datasource.NewMapper(graphSvc, log), log)
 	datasource.RegisterRoutes(e, datasourceHandler, authMiddleware)
 
 	// Register provider routes (LLM credential management, model catalog, usage)
@@ -317,7 +322,7 @@ func newTestServerWithDB(testDB *TestDB, db bun.IDB) *TestServer {
 	providerRegistry := provider.NewRegistry()
 	providerCatalogSvc := provider.NewModelCatalogService(providerRepo, log)
 	providerCredSvc := provider.NewCredentialService(providerRepo, providerRegistry, providerCatalogSvc, testDB.Config, log)
-	providerHandler := provider.NewHandler(providerCredSvc, providerCatalogSvc, providerRepo)
+	providerHandler := provider.NewHandler(providerCredSvc, providerCatalogSvc, providerRepo, provider.NewBudgetService(providerRepo, log))
 	provider.RegisterRoutes(e, providerHandler, authMiddleware)
 
 	return &TestServer{
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-integration signing secret for inbound webhooks (encrypted like config_encrypted).
+-- NULL means webhooks are disabled for the integration.
+ALTER TABLE kb.data_source_integrations ADD COLUMN IF NOT EXISTS webhook_secret_encrypted TEXT;
+
+-- Log of verified webhook deliveries. The unique (integration_id, delivery_id)
+-- constraint is what rejects replayed deliveries.
+CREATE TABLE IF NOT EXISTS kb.data_source_webhook_deliveries (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration_id  UUID NOT NULL REFERENCES kb.data_source_integrations(id) ON DELETE CASCADE,
+    project_id      UUID NOT NULL,
+    delivery_id     TEXT NOT NULL,
+    event_type      TEXT,
+    item_ids        JSONB NOT NULL DEFAULT '[]',
+    status          TEXT NOT NULL DEFAULT 'accepted', -- 'accepted', 'ignored'
+    status_message  TEXT,
+    sync_job_id     UUID REFERENCES kb.data_source_sync_jobs(id) ON DELETE SET NULL,
+    received_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_data_source_webhook_deliveries_delivery UNIQUE (integration_id, delivery_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_source_webhook_deliveries_integration
+    ON kb.data_source_webhook_deliveries (integration_id, received_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS kb.data_source_webhook_deliveries;
+ALTER TABLE kb.data_source_integrations DROP COLUMN IF EXISTS webhook_secret_encrypted;
+
+-- +goose StatementEnd
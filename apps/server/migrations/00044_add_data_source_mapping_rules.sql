@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Declarative field-to-graph mapping rules for structured records emitted by
+-- providers (source item type -> object type, field paths -> properties,
+-- references -> relationships). Empty means records are not mapped.
+ALTER TABLE kb.data_source_integrations ADD COLUMN IF NOT EXISTS mapping_rules JSONB NOT NULL DEFAULT '[]';
+
+-- Sample of structured records from the most recent sync, used to author and
+-- dry-run mapping rules against real data.
+ALTER TABLE kb.data_source_integrations ADD COLUMN IF NOT EXISTS mapping_sample JSONB NOT NULL DEFAULT '[]';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE kb.data_source_integrations DROP COLUMN IF EXISTS mapping_sample;
+ALTER TABLE kb.data_source_integrations DROP COLUMN IF EXISTS mapping_rules;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Step configuration for multi-step agent flows. Sequential flows run the steps
+-- in order; loop flows repeat them until a step calls exit_loop or the
+-- iteration cap is reached. NULL for single-agent definitions.
+ALTER TABLE kb.agent_definitions ADD COLUMN IF NOT EXISTS flow_config JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE kb.agent_definitions DROP COLUMN IF EXISTS flow_config;
+
+-- +goose StatementEnd
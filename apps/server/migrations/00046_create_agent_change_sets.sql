@@ -0,0 +1,38 @@
+-- +goose Up
+
+-- Create kb.agent_change_sets table for suggest/hybrid execution modes.
+-- Agents in suggest mode (and hybrid mode, for changes not covered by their
+-- auto-apply rules) stage mutating graph tool calls here instead of applying
+-- them. A reviewer approves or rejects the change set, which replays or drops
+-- the staged calls.
+CREATE TABLE IF NOT EXISTS kb.agent_change_sets (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id   UUID NOT NULL,
+    agent_id     UUID REFERENCES kb.agents(id) ON DELETE SET NULL,
+    run_id       UUID NOT NULL REFERENCES kb.agent_runs(id) ON DELETE CASCADE,
+    status       TEXT NOT NULL DEFAULT 'pending',
+    changes      JSONB NOT NULL DEFAULT '[]',
+    task_id      UUID,
+    reviewed_by  UUID,
+    reviewed_at  TIMESTAMPTZ,
+    review_notes TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE kb.agent_change_sets IS 'Graph writes staged by suggest/hybrid mode agents, pending review';
+COMMENT ON COLUMN kb.agent_change_sets.changes IS 'Ordered JSON array of staged tool calls {seq, toolName, args, objectType, confidence, status, result, error}';
+COMMENT ON COLUMN kb.agent_change_sets.status IS 'Change set lifecycle: pending, applying, applied, partially_applied, failed, rejected';
+COMMENT ON COLUMN kb.agent_change_sets.task_id IS 'Link to the kb.tasks inbox entry created for review';
+
+CREATE INDEX IF NOT EXISTS idx_agent_change_sets_run_id ON kb.agent_change_sets(run_id);
+CREATE INDEX IF NOT EXISTS idx_agent_change_sets_project_status ON kb.agent_change_sets(project_id, status);
+
+-- Per-agent rules for hybrid mode: staged changes matching these rules are
+-- applied immediately instead of waiting for review.
+ALTER TABLE kb.agents ADD COLUMN IF NOT EXISTS auto_apply_rules JSONB;
+
+-- +goose Down
+
+ALTER TABLE kb.agents DROP COLUMN IF EXISTS auto_apply_rules;
+DROP TABLE IF EXISTS kb.agent_change_sets;
@@ -0,0 +1,63 @@
+-- +goose Up
+
+-- Durable outbox for entity events. Graph mutations insert a row here in the
+-- same transaction as the change, so an event exists if and only if the
+-- change committed. The reaction agent dispatcher reads the outbox ordered by
+-- (tx_id, id) and only past the oldest in-progress transaction, so events
+-- from transactions that commit out of order are never skipped.
+CREATE TABLE IF NOT EXISTS kb.entity_event_outbox (
+    id          BIGSERIAL PRIMARY KEY,
+    tx_id       BIGINT NOT NULL DEFAULT (pg_current_xact_id()::text::bigint),
+    project_id  UUID NOT NULL,
+    event_type  TEXT NOT NULL,
+    entity      TEXT NOT NULL,
+    entity_id   UUID NOT NULL,
+    object_type TEXT,
+    version     INTEGER,
+    actor_type  TEXT,
+    actor_id    TEXT,
+    data        JSONB,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE kb.entity_event_outbox IS 'Entity events written transactionally with graph mutations, consumed by reaction agents';
+COMMENT ON COLUMN kb.entity_event_outbox.tx_id IS 'Writing transaction ID; readers order by (tx_id, id) and stop at the oldest running transaction';
+
+CREATE INDEX IF NOT EXISTS idx_entity_event_outbox_project_position ON kb.entity_event_outbox(project_id, tx_id, id);
+CREATE INDEX IF NOT EXISTS idx_entity_event_outbox_created_at ON kb.entity_event_outbox(created_at);
+
+-- Per-agent read position in the outbox. Replicas lock a cursor row with
+-- SKIP LOCKED so each agent's events are fanned out by one replica at a time.
+CREATE TABLE IF NOT EXISTS kb.agent_event_cursors (
+    agent_id      UUID PRIMARY KEY REFERENCES kb.agents(id) ON DELETE CASCADE,
+    last_tx_id    BIGINT NOT NULL DEFAULT 0,
+    last_event_id BIGINT NOT NULL DEFAULT 0,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE kb.agent_event_cursors IS 'Outbox position (tx_id, id) up to which events have been queued for each reaction agent';
+
+-- Delivery state for queued events. Each outbox event is queued at most once
+-- per agent; failed deliveries are retried with backoff until max attempts.
+ALTER TABLE kb.agent_processing_log
+    ADD COLUMN IF NOT EXISTS event_id BIGINT,
+    ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS run_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agent_processing_log_agent_event
+    ON kb.agent_processing_log(agent_id, event_id) WHERE event_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_agent_processing_log_due
+    ON kb.agent_processing_log(next_attempt_at, created_at) WHERE status = 'pending';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS kb.idx_agent_processing_log_due;
+DROP INDEX IF EXISTS kb.idx_agent_processing_log_agent_event;
+ALTER TABLE kb.agent_processing_log
+    DROP COLUMN IF EXISTS run_id,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempt_count,
+    DROP COLUMN IF EXISTS event_id;
+DROP TABLE IF EXISTS kb.agent_event_cursors;
+DROP TABLE IF EXISTS kb.entity_event_outbox;
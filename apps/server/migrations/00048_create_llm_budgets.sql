@@ -0,0 +1,60 @@
+-- +goose Up
+
+-- Attribute LLM usage to the agent run that caused it, so budgets can be
+-- enforced and usage broken down per agent.
+ALTER TABLE kb.llm_usage_events
+    ADD COLUMN IF NOT EXISTS agent_id UUID,
+    ADD COLUMN IF NOT EXISTS agent_definition_id UUID,
+    ADD COLUMN IF NOT EXISTS run_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_events_agent ON kb.llm_usage_events(agent_id, created_at) WHERE agent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_llm_usage_events_agent_definition ON kb.llm_usage_events(agent_definition_id, created_at) WHERE agent_definition_id IS NOT NULL;
+
+-- Daily or monthly token/cost limits on LLM usage. A budget applies to a whole
+-- organization, one project, or one agent definition. Model calls are refused
+-- once a hard limit is reached; admins are notified when usage crosses the
+-- soft limit percentage.
+CREATE TABLE IF NOT EXISTS kb.llm_budgets (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id              UUID NOT NULL REFERENCES kb.orgs(id) ON DELETE CASCADE,
+    scope               TEXT NOT NULL,
+    project_id          UUID REFERENCES kb.projects(id) ON DELETE CASCADE,
+    agent_definition_id UUID REFERENCES kb.agent_definitions(id) ON DELETE CASCADE,
+    period              TEXT NOT NULL,
+    token_limit         BIGINT,
+    cost_limit_usd      NUMERIC(12, 4),
+    soft_limit_percent  INTEGER NOT NULL DEFAULT 80,
+    enabled             BOOLEAN NOT NULL DEFAULT true,
+    notified_period     TIMESTAMPTZ,
+    notified_level      TEXT,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT llm_budgets_scope_check CHECK (
+        (scope = 'org' AND project_id IS NULL AND agent_definition_id IS NULL) OR
+        (scope = 'project' AND project_id IS NOT NULL AND agent_definition_id IS NULL) OR
+        (scope = 'agent' AND project_id IS NOT NULL AND agent_definition_id IS NOT NULL)
+    ),
+    CONSTRAINT llm_budgets_period_check CHECK (period IN ('daily', 'monthly')),
+    CONSTRAINT llm_budgets_limit_check CHECK (token_limit IS NOT NULL OR cost_limit_usd IS NOT NULL)
+);
+
+COMMENT ON TABLE kb.llm_budgets IS 'Token and cost limits on LLM usage per org, project or agent definition';
+COMMENT ON COLUMN kb.llm_budgets.notified_period IS 'Start of the period for which the last limit notification was sent';
+COMMENT ON COLUMN kb.llm_budgets.notified_level IS 'Last notification sent in notified_period: soft or hard';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_llm_budgets_target ON kb.llm_budgets(
+    org_id,
+    COALESCE(project_id, '00000000-0000-0000-0000-000000000000'::uuid),
+    COALESCE(agent_definition_id, '00000000-0000-0000-0000-000000000000'::uuid),
+    period
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS kb.llm_budgets;
+DROP INDEX IF EXISTS kb.idx_llm_usage_events_agent_definition;
+DROP INDEX IF EXISTS kb.idx_llm_usage_events_agent;
+ALTER TABLE kb.llm_usage_events
+    DROP COLUMN IF EXISTS run_id,
+    DROP COLUMN IF EXISTS agent_definition_id,
+    DROP COLUMN IF EXISTS agent_id;
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- Long-term memories of an agent definition: facts, preferences and summaries
+-- of past runs. A memory with a user_id belongs to that user's runs only; one
+-- without is shared by every run of the definition. Relevant memories are
+-- recalled by embedding similarity at run start.
+CREATE TABLE IF NOT EXISTS kb.agent_memories (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id          UUID NOT NULL REFERENCES kb.projects(id) ON DELETE CASCADE,
+    agent_definition_id UUID NOT NULL REFERENCES kb.agent_definitions(id) ON DELETE CASCADE,
+    user_id             UUID,
+    kind                TEXT NOT NULL DEFAULT 'fact',
+    content             TEXT NOT NULL,
+    embedding           vector(768),
+    source_run_id       UUID REFERENCES kb.agent_runs(id) ON DELETE SET NULL,
+    recall_count        INTEGER NOT NULL DEFAULT 0,
+    last_recalled_at    TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT agent_memories_kind_check CHECK (kind IN ('fact', 'preference', 'summary'))
+);
+
+COMMENT ON TABLE kb.agent_memories IS 'Long-term memories of agent definitions, optionally scoped to a user';
+COMMENT ON COLUMN kb.agent_memories.user_id IS 'User the memory belongs to; NULL for memories shared by all runs of the definition';
+
+CREATE INDEX IF NOT EXISTS idx_agent_memories_definition ON kb.agent_memories(agent_definition_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_agent_memories_project ON kb.agent_memories(project_id, created_at DESC);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS kb.agent_memories;
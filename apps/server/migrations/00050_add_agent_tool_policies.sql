@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- Per-tool policies of an agent definition: an ordered list of
+-- {tool, args, action} rules deciding whether a tool call runs, waits for a
+-- user's approval, or is refused. The first matching rule wins.
+ALTER TABLE kb.agent_definitions
+    ADD COLUMN IF NOT EXISTS tool_policies JSONB;
+
+-- Approval questions carry the exact tool call awaiting approval.
+ALTER TABLE kb.agent_questions
+    ADD COLUMN IF NOT EXISTS tool_name TEXT,
+    ADD COLUMN IF NOT EXISTS tool_args JSONB;
+
+-- +goose Down
+
+ALTER TABLE kb.agent_questions
+    DROP COLUMN IF EXISTS tool_args,
+    DROP COLUMN IF EXISTS tool_name;
+
+ALTER TABLE kb.agent_definitions
+    DROP COLUMN IF EXISTS tool_policies;
@@ -0,0 +1,52 @@
+-- +goose Up
+
+-- Evaluation suites of an agent definition: scenarios that seed graph state,
+-- send an input message and assert on the tool calls, graph end state and
+-- final answer of the run.
+CREATE TABLE IF NOT EXISTS kb.agent_eval_suites (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id          UUID NOT NULL REFERENCES kb.projects(id) ON DELETE CASCADE,
+    agent_definition_id UUID NOT NULL REFERENCES kb.agent_definitions(id) ON DELETE CASCADE,
+    name                TEXT NOT NULL,
+    description         TEXT,
+    scenarios           JSONB NOT NULL DEFAULT '[]',
+    judge_model         TEXT,
+    pass_threshold      DOUBLE PRECISION,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT agent_eval_suites_name_unique UNIQUE (agent_definition_id, name)
+);
+
+COMMENT ON TABLE kb.agent_eval_suites IS 'Evaluation scenario suites of agent definitions';
+
+CREATE INDEX IF NOT EXISTS idx_agent_eval_suites_project ON kb.agent_eval_suites(project_id, created_at DESC);
+
+-- One execution of a suite. The definition the suite ran against is stored as
+-- a snapshot and a hash, so results can be compared across prompt, model and
+-- tool changes.
+CREATE TABLE IF NOT EXISTS kb.agent_eval_runs (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    suite_id            UUID NOT NULL REFERENCES kb.agent_eval_suites(id) ON DELETE CASCADE,
+    project_id          UUID NOT NULL REFERENCES kb.projects(id) ON DELETE CASCADE,
+    agent_definition_id UUID NOT NULL REFERENCES kb.agent_definitions(id) ON DELETE CASCADE,
+    definition_hash     TEXT NOT NULL,
+    definition_snapshot JSONB NOT NULL DEFAULT '{}',
+    status              TEXT NOT NULL DEFAULT 'running',
+    score               DOUBLE PRECISION,
+    passed              INTEGER NOT NULL DEFAULT 0,
+    total               INTEGER NOT NULL DEFAULT 0,
+    results             JSONB NOT NULL DEFAULT '[]',
+    error_message       TEXT,
+    started_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at        TIMESTAMPTZ,
+    CONSTRAINT agent_eval_runs_status_check CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+COMMENT ON COLUMN kb.agent_eval_runs.definition_hash IS 'SHA-256 of the definition fields that affect behavior; equal hashes ran the same definition version';
+
+CREATE INDEX IF NOT EXISTS idx_agent_eval_runs_suite ON kb.agent_eval_runs(suite_id, started_at DESC);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS kb.agent_eval_runs;
+DROP TABLE IF EXISTS kb.agent_eval_suites;
@@ -0,0 +1,40 @@
+-- +goose Up
+
+-- MCP Streamable HTTP sessions, shared by all replicas so a client's requests
+-- and stream resumption can land on any of them. Project, user and org are
+-- kept as text since the project comes from unvalidated initialize params.
+CREATE TABLE IF NOT EXISTS kb.mcp_sessions (
+    id               TEXT PRIMARY KEY,
+    project_id       TEXT,
+    user_id          TEXT,
+    org_id           TEXT,
+    initialized      BOOLEAN NOT NULL DEFAULT false,
+    protocol_version TEXT NOT NULL,
+    next_event_id    BIGINT NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_access_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at       TIMESTAMPTZ NOT NULL
+);
+
+COMMENT ON TABLE kb.mcp_sessions IS 'MCP Streamable HTTP sessions, expired after a TTL without client access';
+COMMENT ON COLUMN kb.mcp_sessions.next_event_id IS 'Next SSE event ID of the session, for Last-Event-ID resumption';
+
+CREATE INDEX IF NOT EXISTS idx_mcp_sessions_expires_at ON kb.mcp_sessions(expires_at);
+
+-- Recent server messages of each session, replayed to a client that resumes
+-- its stream with Last-Event-ID. Pruned to the newest events per session.
+CREATE TABLE IF NOT EXISTS kb.mcp_session_events (
+    session_id TEXT NOT NULL REFERENCES kb.mcp_sessions(id) ON DELETE CASCADE,
+    event_id   BIGINT NOT NULL,
+    event_type TEXT NOT NULL DEFAULT 'message',
+    data       JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id, event_id)
+);
+
+COMMENT ON TABLE kb.mcp_session_events IS 'Server-sent events of MCP sessions kept for stream resumption';
+
+-- +goose Down
+
+DROP TABLE IF EXISTS kb.mcp_session_events;
+DROP TABLE IF EXISTS kb.mcp_sessions;
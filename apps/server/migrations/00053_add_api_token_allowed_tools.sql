@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE core.api_tokens ADD COLUMN IF NOT EXISTS allowed_tools text[];
+COMMENT ON COLUMN core.api_tokens.allowed_tools IS 'MCP tools the token may list and call. NULL allows every tool its scopes permit.';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE core.api_tokens DROP COLUMN IF EXISTS allowed_tools;
+-- +goose StatementEnd
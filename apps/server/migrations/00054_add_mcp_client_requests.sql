@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- Capabilities the client declared in initialize, deciding whether the server
+-- may send it sampling and elicitation requests.
+ALTER TABLE kb.mcp_sessions ADD COLUMN IF NOT EXISTS client_capabilities JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN kb.mcp_sessions.client_capabilities IS 'Client capabilities declared in initialize (sampling, elicitation, roots)';
+
+-- Client responses to server requests too large for a NOTIFY payload, held
+-- until the replica waiting for them reads them.
+CREATE TABLE IF NOT EXISTS kb.mcp_session_responses (
+    session_id TEXT NOT NULL REFERENCES kb.mcp_sessions(id) ON DELETE CASCADE,
+    request_id TEXT NOT NULL,
+    data       JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id, request_id)
+);
+
+COMMENT ON TABLE kb.mcp_session_responses IS 'Large MCP client responses to server requests, relayed between replicas';
+
+-- +goose Down
+
+DROP TABLE IF EXISTS kb.mcp_session_responses;
+ALTER TABLE kb.mcp_sessions DROP COLUMN IF EXISTS client_capabilities;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE kb.agent_definitions ADD COLUMN IF NOT EXISTS input_schema jsonb;
+COMMENT ON COLUMN kb.agent_definitions.input_schema IS 'JSON Schema of the input the agent takes when called as an MCP tool. NULL takes a free-text message.';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE kb.agent_definitions DROP COLUMN IF EXISTS input_schema;
+-- +goose StatementEnd
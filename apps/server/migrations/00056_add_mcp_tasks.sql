@@ -0,0 +1,32 @@
+-- +goose Up
+
+-- MCP tool calls run as tasks. The client polls a task with tasks/get and
+-- reads its result with tasks/result, on any replica; tasks/cancel marks the
+-- task cancelled and the replica running it stops the call. Tasks are kept
+-- for their TTL, then purged.
+CREATE TABLE IF NOT EXISTS kb.mcp_tasks (
+    id             TEXT PRIMARY KEY,
+    project_id     TEXT,
+    user_id        TEXT,
+    session_id     TEXT,
+    tool_name      TEXT NOT NULL,
+    status         TEXT NOT NULL DEFAULT 'working',
+    status_message TEXT,
+    result         JSONB,
+    error          JSONB,
+    ttl_ms         BIGINT NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ NOT NULL
+);
+
+COMMENT ON TABLE kb.mcp_tasks IS 'MCP tool calls run as tasks the client polls for their result';
+COMMENT ON COLUMN kb.mcp_tasks.result IS 'Tool result of a completed or failed task';
+COMMENT ON COLUMN kb.mcp_tasks.error IS 'JSON-RPC error of a task whose tool call could not run';
+
+CREATE INDEX IF NOT EXISTS idx_mcp_tasks_user ON kb.mcp_tasks(user_id, project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_mcp_tasks_expires_at ON kb.mcp_tasks(expires_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS kb.mcp_tasks;
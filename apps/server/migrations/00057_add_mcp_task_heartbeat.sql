@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- The replica running a task refreshes its heartbeat while it watches the
+-- task. Working tasks whose heartbeat stopped belong to a replica that died
+-- and are marked failed.
+ALTER TABLE kb.mcp_tasks ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+COMMENT ON COLUMN kb.mcp_tasks.heartbeat_at IS 'Last time the replica running the task reported it alive';
+
+CREATE INDEX IF NOT EXISTS idx_mcp_tasks_working_heartbeat ON kb.mcp_tasks(heartbeat_at) WHERE status = 'working';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS kb.idx_mcp_tasks_working_heartbeat;
+ALTER TABLE kb.mcp_tasks DROP COLUMN IF EXISTS heartbeat_at;
@@ -27,12 +27,17 @@ type modelFactoryParams struct {
 	Cfg      *config.Config
 	Log      *slog.Logger
 	Resolver CredentialResolver `optional:"true"`
+	Tracker  UsageTracker       `optional:"true"`
 }
 
 // provideModelFactory creates a ModelFactory from the main config, with an
-// optional CredentialResolver injected by domain/provider.Module.
+// optional CredentialResolver and UsageTracker injected by domain/provider.Module.
 func provideModelFactory(p modelFactoryParams) *ModelFactory {
-	return NewModelFactory(&p.Cfg.LLM, p.Log, p.Resolver)
+	f := NewModelFactory(&p.Cfg.LLM, p.Log, p.Resolver)
+	if p.Tracker != nil {
+		f.SetUsageTracker(p.Tracker)
+	}
+	return f
 }
 
 // ModelFactory creates ADK-compatible LLM models from configuration.
@@ -40,6 +45,7 @@ type ModelFactory struct {
 	cfg      *config.LLMConfig
 	log      *slog.Logger
 	resolver CredentialResolver // optional; nil → env-var-only mode
+	tracker  UsageTracker       // optional; nil → models are not tracked
 }
 
 // NewModelFactory creates a new ModelFactory with the given configuration.
@@ -52,6 +58,12 @@ func NewModelFactory(cfg *config.LLMConfig, log *slog.Logger, resolver Credentia
 	}
 }
 
+// SetUsageTracker wraps every model created from now on with t, which
+// records token usage and enforces LLM budgets.
+func (f *ModelFactory) SetUsageTracker(t UsageTracker) {
+	f.tracker = t
+}
+
 // CreateModel creates an ADK-compatible Gemini model for Vertex AI.
 //
 // The model uses Vertex AI backend with the configured GCP project and location.
@@ -72,8 +84,20 @@ func (f *ModelFactory) CreateModel(ctx context.Context) (model.LLM, error) {
 //  2. Fall back to static env-var config (GCP_PROJECT_ID+VERTEX_AI_LOCATION or
 //     GOOGLE_API_KEY). Used in tests and env-var-only setups.
 func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string) (model.LLM, error) {
+	llm, provider, err := f.createModel(ctx, modelName)
+	if err != nil {
+		return nil, err
+	}
+	if f.tracker != nil {
+		llm = f.tracker.Track(llm, provider)
+	}
+	return llm, nil
+}
+
+// createModel creates the model and reports which provider serves it.
+func (f *ModelFactory) createModel(ctx context.Context, modelName string) (model.LLM, string, error) {
 	if modelName == "" {
-		return nil, fmt.Errorf("model name is required")
+		return nil, "", fmt.Errorf("model name is required")
 	}
 
 	// --- 1. DB credential resolution (project/org hierarchy) ---
@@ -109,7 +133,7 @@ func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string
 						},
 					)
 					if err != nil {
-						return nil, fmt.Errorf("failed to parse service account credentials: %w", err)
+						return nil, "", fmt.Errorf("failed to parse service account credentials: %w", err)
 					}
 					clientCfg.Credentials = creds
 				}
@@ -121,9 +145,9 @@ func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string
 				)
 				llm, err := gemini.NewModel(ctx, resolvedModel, clientCfg)
 				if err != nil {
-					return nil, fmt.Errorf("failed to create Gemini model via Vertex AI (DB cred): %w", err)
+					return nil, "", fmt.Errorf("failed to create Gemini model via Vertex AI (DB cred): %w", err)
 				}
-				return llm, nil
+				return llm, "vertex-ai", nil
 			}
 
 			if cred.IsGoogleAI && cred.APIKey != "" {
@@ -137,9 +161,9 @@ func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string
 				)
 				llm, err := gemini.NewModel(ctx, resolvedModel, clientCfg)
 				if err != nil {
-					return nil, fmt.Errorf("failed to create Gemini model via Google AI (DB cred): %w", err)
+					return nil, "", fmt.Errorf("failed to create Gemini model via Google AI (DB cred): %w", err)
 				}
-				return llm, nil
+				return llm, "google-ai", nil
 			}
 		}
 		// cred == nil means no DB credential found — fall through to env vars
@@ -161,7 +185,7 @@ func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string
 
 		llm, err := gemini.NewModel(ctx, modelName, clientCfg)
 		if err == nil {
-			return llm, nil
+			return llm, "vertex-ai", nil
 		}
 
 		// If Vertex AI fails and we have an API key, fall back
@@ -170,7 +194,7 @@ func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string
 				slog.String("error", err.Error()),
 			)
 		} else {
-			return nil, fmt.Errorf("failed to create Gemini model: %w", err)
+			return nil, "", fmt.Errorf("failed to create Gemini model: %w", err)
 		}
 	}
 
@@ -185,12 +209,12 @@ func (f *ModelFactory) CreateModelWithName(ctx context.Context, modelName string
 
 		llm, err := gemini.NewModel(ctx, modelName, clientCfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create Gemini model via Google AI: %w", err)
+			return nil, "", fmt.Errorf("failed to create Gemini model via Google AI: %w", err)
 		}
-		return llm, nil
+		return llm, "google-ai", nil
 	}
 
-	return nil, fmt.Errorf("no LLM credentials configured: set GCP_PROJECT_ID+VERTEX_AI_LOCATION for Vertex AI, or GOOGLE_API_KEY for Google AI")
+	return nil, "", fmt.Errorf("no LLM credentials configured: set GCP_PROJECT_ID+VERTEX_AI_LOCATION for Vertex AI, or GOOGLE_API_KEY for Google AI")
 }
 
 // DefaultGenerateConfig returns a default GenerateContentConfig for extraction tasks.
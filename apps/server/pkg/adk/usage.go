@@ -0,0 +1,34 @@
+package adk
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/model"
+)
+
+// UsageTracker wraps models created by ModelFactory to record token usage and
+// enforce LLM budgets. Implemented by domain/provider.ADKUsageTracker and
+// injected via fx, for the same import-cycle reason as CredentialResolver.
+type UsageTracker interface {
+	// Track wraps llm, created for the given provider ("google-ai" or
+	// "vertex-ai").
+	Track(llm model.LLM, provider string) model.LLM
+}
+
+// BudgetExceededError is returned by a tracked model call when a hard LLM
+// budget limit has been reached. Callers stop the work in progress and record
+// Error() as the reason.
+type BudgetExceededError struct {
+	Scope  string  // "org", "project" or "agent"
+	Period string  // "daily" or "monthly"
+	Metric string  // "tokens" or "cost"
+	Limit  float64 // configured hard limit
+	Used   float64 // usage in the current period
+}
+
+func (e *BudgetExceededError) Error() string {
+	if e.Metric == "cost" {
+		return fmt.Sprintf("%s %s LLM budget exceeded: $%.2f used of $%.2f limit", e.Period, e.Scope, e.Used, e.Limit)
+	}
+	return fmt.Sprintf("%s %s LLM budget exceeded: %.0f tokens used of %.0f limit", e.Period, e.Scope, e.Used, e.Limit)
+}
@@ -43,6 +43,10 @@ type AuthUser struct {
 
 	// API token ID (if authenticated via API token)
 	APITokenID string `json:"apiTokenId,omitempty"`
+
+	// MCP tools the API token is limited to; empty means all tools its
+	// scopes allow
+	AllowedTools []string `json:"allowedTools,omitempty"`
 }
 
 // ContextKey for storing auth user in context
@@ -274,6 +278,26 @@ func expandScopes(scopes []string) map[string]bool {
 	return result
 }
 
+// MissingAPITokenScopes returns the scopes an emt_* API token user lacks,
+// expanding umbrella scopes. It returns nil for other users, whose access is
+// not limited by token scopes. Used where scopes are enforced per operation
+// rather than per route, such as individual MCP tools.
+func MissingAPITokenScopes(user *AuthUser, scopes ...string) []string {
+	if user == nil || user.APITokenID == "" {
+		return nil
+	}
+
+	userScopes := expandScopes(user.Scopes)
+
+	var missing []string
+	for _, required := range scopes {
+		if !userScopes[required] {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
 // RequireAPITokenScopes returns middleware that requires specific scopes ONLY when the
 // request is authenticated via an emt_* API token. For Zitadel/OAuth sessions the check
 // is skipped, preserving backward compatibility.
@@ -287,22 +311,7 @@ func (m *Middleware) RequireAPITokenScopes(scopes ...string) echo.MiddlewareFunc
 				return apperror.ErrUnauthorized
 			}
 
-			// Only enforce for API token auth (emt_* tokens identified by APITokenID)
-			if user.APITokenID == "" {
-				return next(c)
-			}
-
-			// Build the effective scope set, expanding umbrella scopes.
-			userScopes := expandScopes(user.Scopes)
-
-			missing := []string{}
-			for _, required := range scopes {
-				if !userScopes[required] {
-					missing = append(missing, required)
-				}
-			}
-
-			if len(missing) > 0 {
+			if missing := MissingAPITokenScopes(user, scopes...); len(missing) > 0 {
 				return echo.NewHTTPError(http.StatusForbidden, map[string]any{
 					"error": map[string]any{
 						"code":    "forbidden",
@@ -462,15 +471,16 @@ func (m *Middleware) validateAPIToken(ctx context.Context, token string) (*AuthU
 
 	// Query the api_tokens table
 	var result struct {
-		ID        string   `bun:"id"`
-		UserID    string   `bun:"user_id"`
-		ProjectID string   `bun:"project_id"`
-		Scopes    []string `bun:"scopes,array"`
+		ID           string   `bun:"id"`
+		UserID       string   `bun:"user_id"`
+		ProjectID    string   `bun:"project_id"`
+		Scopes       []string `bun:"scopes,array"`
+		AllowedTools []string `bun:"allowed_tools,array"`
 	}
 
 	err := m.db.NewSelect().
 		TableExpr("core.api_tokens").
-		Column("id", "user_id", "project_id", "scopes").
+		Column("id", "user_id", "project_id", "scopes", "allowed_tools").
 		Where("token_hash = ?", tokenHash).
 		Where("revoked_at IS NULL").
 		Scan(ctx, &result)
@@ -492,6 +502,7 @@ func (m *Middleware) validateAPIToken(ctx context.Context, token string) (*AuthU
 		Scopes:            result.Scopes,
 		APITokenProjectID: result.ProjectID,
 		APITokenID:        result.ID,
+		AllowedTools:      result.AllowedTools,
 	}, nil
 }
 
@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 	"net/url"
+	"reflect"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -376,3 +377,46 @@ func TestRequireAPITokenScopes_OAuthSession_BypassesCheck(t *testing.T) {
 		t.Error("RequireAPITokenScopes() did not call next for OAuth session")
 	}
 }
+
+func TestMissingAPITokenScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *AuthUser
+		scopes []string
+		want   []string
+	}{
+		{
+			name:   "umbrella scope implies fine-grained scopes",
+			user:   &AuthUser{ID: "user-1", APITokenID: "token-1", Scopes: []string{"data:write"}},
+			scopes: []string{"documents:write", "extraction:write"},
+			want:   nil,
+		},
+		{
+			name:   "read-only token lacks write scope",
+			user:   &AuthUser{ID: "user-1", APITokenID: "token-1", Scopes: []string{"data:read"}},
+			scopes: []string{"documents:read", "documents:write"},
+			want:   []string{"documents:write"},
+		},
+		{
+			name:   "OAuth session is not limited",
+			user:   &AuthUser{ID: "user-1"},
+			scopes: []string{"documents:write"},
+			want:   nil,
+		},
+		{
+			name:   "no user is not limited",
+			user:   nil,
+			scopes: []string{"documents:write"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MissingAPITokenScopes(tt.user, tt.scopes...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingAPITokenScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,19 @@ import (
 	"testing"
 )
 
+// TestMain points log files at a temporary directory so test runs do not
+// write into the working tree.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "logger-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("WORKSPACE_ROOT", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestScope(t *testing.T) {
 	tests := []struct {
 		name  string
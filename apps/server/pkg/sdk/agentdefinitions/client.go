@@ -66,6 +66,7 @@ type AgentDefinition struct {
 	Visibility     string         `json:"visibility"`
 	ACPConfig      *ACPConfig     `json:"acpConfig,omitempty"`
 	Config         map[string]any `json:"config,omitempty"`
+	ToolPolicies   []ToolPolicy   `json:"toolPolicies,omitempty"`
 	CreatedAt      time.Time      `json:"createdAt"`
 	UpdatedAt      time.Time      `json:"updatedAt"`
 }
@@ -100,6 +101,16 @@ type ACPConfig struct {
 	OutputModes  []string `json:"outputModes,omitempty"`
 }
 
+// ToolPolicy decides whether matching tool calls are allowed, held for a
+// user's approval, or denied. Tool is a tool name or glob; Args maps argument
+// names to regular expressions. Action is "allow", "require_approval" or
+// "deny". Policies are checked in order and the first match wins.
+type ToolPolicy struct {
+	Tool   string            `json:"tool"`
+	Args   map[string]string `json:"args,omitempty"`
+	Action string            `json:"action"`
+}
+
 // APIResponse wraps API responses with success flag.
 type APIResponse[T any] struct {
 	Success bool    `json:"success"`
@@ -122,6 +133,7 @@ type CreateAgentDefinitionRequest struct {
 	Visibility     string         `json:"visibility,omitempty"`
 	ACPConfig      *ACPConfig     `json:"acpConfig,omitempty"`
 	Config         map[string]any `json:"config,omitempty"`
+	ToolPolicies   []ToolPolicy   `json:"toolPolicies,omitempty"`
 }
 
 // UpdateAgentDefinitionRequest is the request body for updating an agent definition.
@@ -138,6 +150,7 @@ type UpdateAgentDefinitionRequest struct {
 	Visibility     *string        `json:"visibility,omitempty"`
 	ACPConfig      *ACPConfig     `json:"acpConfig,omitempty"`
 	Config         map[string]any `json:"config,omitempty"`
+	ToolPolicies   []ToolPolicy   `json:"toolPolicies,omitempty"`
 }
 
 // --- Internal helpers ---
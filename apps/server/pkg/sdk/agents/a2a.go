@@ -0,0 +1,299 @@
+package agents
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sdkerrors "github.com/emergent-company/emergent.memory/apps/server/pkg/sdk/errors"
+)
+
+// --- A2A Types ---
+
+// AgentCard describes an externally visible agent to other agent frameworks.
+type AgentCard struct {
+	Name               string                `json:"name"`
+	DisplayName        string                `json:"displayName"`
+	Description        string                `json:"description,omitempty"`
+	URL                string                `json:"url"`
+	Capabilities       AgentCardCapabilities `json:"capabilities"`
+	Skills             []string              `json:"skills,omitempty"`
+	DefaultInputModes  []string              `json:"defaultInputModes"`
+	DefaultOutputModes []string              `json:"defaultOutputModes"`
+	Authentication     AgentCardAuth         `json:"authentication"`
+}
+
+// AgentCardCapabilities lists the protocol features an agent supports.
+type AgentCardCapabilities struct {
+	Streaming     bool `json:"streaming"`
+	InputRequired bool `json:"inputRequired"`
+}
+
+// AgentCardAuth describes how to authenticate task requests.
+type AgentCardAuth struct {
+	Schemes []string `json:"schemes"`
+	Scopes  []string `json:"scopes"`
+}
+
+// AgentCardDirectory is the well-known card listing of a project.
+type AgentCardDirectory struct {
+	ProjectID string      `json:"projectId"`
+	Agents    []AgentCard `json:"agents"`
+}
+
+// A2ATask is the state of a task created through the A2A endpoint. State is
+// one of working, input-required, paused, completed, failed, canceled or
+// rejected.
+type A2ATask struct {
+	ID           string          `json:"id"`
+	Agent        string          `json:"agent"`
+	State        string          `json:"state"`
+	Message      string          `json:"message,omitempty"`
+	Result       string          `json:"result,omitempty"`
+	Questions    []AgentQuestion `json:"questions,omitempty"`
+	Steps        int             `json:"steps"`
+	CurrentRunID string          `json:"currentRunId"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	CompletedAt  *time.Time      `json:"completedAt,omitempty"`
+}
+
+// Done reports whether the task has reached a terminal state.
+func (t *A2ATask) Done() bool {
+	switch t.State {
+	case "completed", "failed", "canceled", "rejected":
+		return true
+	}
+	return false
+}
+
+// CreateA2ATaskRequest starts a task for an external agent.
+type CreateA2ATaskRequest struct {
+	Message  string         `json:"message"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// A2ATaskInputRequest answers a question asked by a task's agent. QuestionID
+// may be omitted when exactly one question is pending.
+type A2ATaskInputRequest struct {
+	QuestionID string `json:"questionId,omitempty"`
+	Response   string `json:"response"`
+}
+
+// A2ATaskEvent is one event of a task stream. Type is "task", "message" or
+// "tool_call", and the matching field is set.
+type A2ATaskEvent struct {
+	Type     string
+	Task     *A2ATask
+	Message  *AgentRunMessage
+	ToolCall *AgentRunToolCall
+}
+
+// A2ATaskStream is an open task event stream. It closes after the task
+// reaches a terminal state.
+type A2ATaskStream struct {
+	resp      *http.Response
+	events    chan *A2ATaskEvent
+	err       error
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// --- A2A Methods ---
+
+func (c *Client) a2aURL(projectID, path string) string {
+	return c.base + "/api/projects/" + url.PathEscape(projectID) + "/a2a" + path
+}
+
+func (c *Client) doA2A(ctx context.Context, method, u string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := c.setHeaders(req); err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return sdkerrors.ParseErrorResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// GetAgentCards returns the cards of a project's externally visible agents.
+// GET /api/projects/:projectId/a2a/.well-known/agent.json
+func (c *Client) GetAgentCards(ctx context.Context, projectID string) (*AgentCardDirectory, error) {
+	var result AgentCardDirectory
+	if err := c.doA2A(ctx, "GET", c.a2aURL(projectID, "/.well-known/agent.json"), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAgentCard returns the card of one external agent.
+// GET /api/projects/:projectId/a2a/agents/:name
+func (c *Client) GetAgentCard(ctx context.Context, projectID, name string) (*AgentCard, error) {
+	var result AgentCard
+	if err := c.doA2A(ctx, "GET", c.a2aURL(projectID, "/agents/"+url.PathEscape(name)), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateA2ATask starts an external agent on a message. The task runs in the
+// background; follow it with StreamA2ATask or GetA2ATask.
+// POST /api/projects/:projectId/a2a/agents/:name/tasks
+func (c *Client) CreateA2ATask(ctx context.Context, projectID, name string, createReq *CreateA2ATaskRequest) (*A2ATask, error) {
+	var result A2ATask
+	if err := c.doA2A(ctx, "POST", c.a2aURL(projectID, "/agents/"+url.PathEscape(name)+"/tasks"), createReq, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetA2ATask returns a task's state, pending questions and result.
+// GET /api/projects/:projectId/a2a/tasks/:taskId
+func (c *Client) GetA2ATask(ctx context.Context, projectID, taskID string) (*A2ATask, error) {
+	var result A2ATask
+	if err := c.doA2A(ctx, "GET", c.a2aURL(projectID, "/tasks/"+url.PathEscape(taskID)), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SendA2ATaskInput answers a task's pending question and resumes it.
+// POST /api/projects/:projectId/a2a/tasks/:taskId/input
+func (c *Client) SendA2ATaskInput(ctx context.Context, projectID, taskID string, inputReq *A2ATaskInputRequest) (*A2ATask, error) {
+	var result A2ATask
+	if err := c.doA2A(ctx, "POST", c.a2aURL(projectID, "/tasks/"+url.PathEscape(taskID)+"/input"), inputReq, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StreamA2ATask opens a task's event stream. Earlier progress is replayed
+// first.
+// GET /api/projects/:projectId/a2a/tasks/:taskId/events
+func (c *Client) StreamA2ATask(ctx context.Context, projectID, taskID string) (*A2ATaskStream, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.a2aURL(projectID, "/tasks/"+url.PathEscape(taskID)+"/events"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if err := c.setHeaders(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, sdkerrors.ParseErrorResponse(resp)
+	}
+
+	stream := &A2ATaskStream{resp: resp, events: make(chan *A2ATaskEvent)}
+	go stream.readEvents()
+	return stream, nil
+}
+
+// Events returns the channel of stream events. It is closed when the stream
+// ends.
+func (s *A2ATaskStream) Events() <-chan *A2ATaskEvent {
+	return s.events
+}
+
+// Err returns the error that ended the stream, if any.
+func (s *A2ATaskStream) Err() error {
+	return s.err
+}
+
+// Close closes the stream. It is safe to call multiple times.
+func (s *A2ATaskStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.resp.Body.Close()
+	})
+	return s.closeErr
+}
+
+func (s *A2ATaskStream) readEvents() {
+	defer close(s.events)
+
+	reader := bufio.NewReader(s.resp.Body)
+	eventType := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				s.err = err
+			}
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			evt, err := decodeA2AEvent(eventType, []byte(strings.TrimPrefix(line, "data: ")))
+			if err != nil {
+				s.err = err
+				return
+			}
+			s.events <- evt
+		case line == "":
+			eventType = ""
+		}
+	}
+}
+
+func decodeA2AEvent(eventType string, data []byte) (*A2ATaskEvent, error) {
+	evt := &A2ATaskEvent{Type: eventType}
+	var target any
+	switch eventType {
+	case "task":
+		evt.Task = &A2ATask{}
+		target = evt.Task
+	case "message":
+		evt.Message = &AgentRunMessage{}
+		target = evt.Message
+	case "tool_call":
+		evt.ToolCall = &AgentRunToolCall{}
+		target = evt.ToolCall
+	default:
+		return evt, nil
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", eventType, err)
+	}
+	return evt, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/emergent-company/emergent.memory/apps/server/pkg/sdk/auth"
 	sdkerrors "github.com/emergent-company/emergent.memory/apps/server/pkg/sdk/errors"
+	"github.com/emergent-company/emergent.memory/apps/server/pkg/sdk/graph"
 )
 
 // Client provides access to the Agents API.
@@ -74,6 +75,22 @@ type ReactionConfig struct {
 	ConcurrencyStrategy  string   `json:"concurrencyStrategy"`
 	IgnoreAgentTriggered bool     `json:"ignoreAgentTriggered"`
 	IgnoreSelfTriggered  bool     `json:"ignoreSelfTriggered"`
+	// Condition narrows the matching events further; nil matches all.
+	Condition *ReactionCondition `json:"condition,omitempty"`
+	// DebounceSeconds coalesces events for the same object into one run per window.
+	DebounceSeconds int `json:"debounceSeconds,omitempty"`
+	// MaxConcurrentRuns caps the agent's reaction runs in flight; 0 means unlimited.
+	MaxConcurrentRuns int `json:"maxConcurrentRuns,omitempty"`
+}
+
+// ReactionCondition restricts a reaction to events whose change matches.
+// Every clause that is set must hold.
+type ReactionCondition struct {
+	PropertiesChanged []string          `json:"propertiesChanged,omitempty"`
+	LabelsAdded       []string          `json:"labelsAdded,omitempty"`
+	LabelsRemoved     []string          `json:"labelsRemoved,omitempty"`
+	StatusChanged     bool              `json:"statusChanged,omitempty"`
+	Match             []graph.Predicate `json:"match,omitempty"`
 }
 
 // AgentCapabilities defines capability restrictions for agents.
@@ -134,6 +151,305 @@ type AgentRunToolCall struct {
 	CreatedAt  time.Time      `json:"createdAt"`
 }
 
+// ReplayRunRequest configures a replay of a recorded run.
+type ReplayRunRequest struct {
+	// Prompt replaces the recorded user message.
+	Prompt string `json:"prompt,omitempty"`
+	// SystemPrompt replaces the agent definition's system prompt.
+	SystemPrompt *string `json:"systemPrompt,omitempty"`
+	// ForkFromStep keeps the recorded decisions before this step.
+	ForkFromStep int `json:"forkFromStep,omitempty"`
+	// LiveTools run for real instead of being served from the recording.
+	LiveTools []string `json:"liveTools,omitempty"`
+}
+
+// ReplayDecision is a tool call made by the model at a step.
+type ReplayDecision struct {
+	Step     int            `json:"step"`
+	ToolName string         `json:"toolName"`
+	Input    map[string]any `json:"input"`
+}
+
+// ReplayDivergence pairs the recorded and replayed decisions at a position in
+// the tool call sequence. Either side is nil when one run made fewer calls.
+type ReplayDivergence struct {
+	Index    int             `json:"index"`
+	Original *ReplayDecision `json:"original,omitempty"`
+	Replayed *ReplayDecision `json:"replayed,omitempty"`
+}
+
+// ReplayDiff compares the decisions of a replay against the recorded run.
+type ReplayDiff struct {
+	Identical        bool               `json:"identical"`
+	OriginalCalls    int                `json:"originalCalls"`
+	ReplayedCalls    int                `json:"replayedCalls"`
+	Divergences      []ReplayDivergence `json:"divergences"`
+	OriginalResponse string             `json:"originalResponse,omitempty"`
+	ReplayedResponse string             `json:"replayedResponse,omitempty"`
+	ResponseChanged  bool               `json:"responseChanged"`
+}
+
+// ReplayResult is the outcome of a replay.
+type ReplayResult struct {
+	RunID       string         `json:"runId"`
+	SourceRunID string         `json:"sourceRunId"`
+	Status      string         `json:"status"`
+	Steps       int            `json:"steps"`
+	Summary     map[string]any `json:"summary"`
+	Diff        *ReplayDiff    `json:"diff"`
+}
+
+// AgentMemory is a long-term memory stored by an agent definition's runs.
+type AgentMemory struct {
+	ID                string     `json:"id"`
+	ProjectID         string     `json:"projectId"`
+	AgentDefinitionID string     `json:"agentDefinitionId"`
+	UserID            *string    `json:"userId,omitempty"`
+	Kind              string     `json:"kind"`
+	Content           string     `json:"content"`
+	SourceRunID       *string    `json:"sourceRunId,omitempty"`
+	RecallCount       int        `json:"recallCount"`
+	LastRecalledAt    *time.Time `json:"lastRecalledAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// ListMemoriesOptions contains options for listing agent memories.
+type ListMemoriesOptions struct {
+	Limit             int
+	Offset            int
+	AgentDefinitionID string
+	UserID            string
+	Kind              string
+}
+
+// PurgeMemoriesRequest selects the memories to purge. Empty fields match
+// everything; an empty request purges the whole project.
+type PurgeMemoriesRequest struct {
+	AgentDefinitionID *string `json:"agentDefinitionId,omitempty"`
+	UserID            *string `json:"userId,omitempty"`
+	Kind              *string `json:"kind,omitempty"`
+}
+
+// PurgeMemoriesResponse reports how many memories a purge removed.
+type PurgeMemoriesResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// EvalScenario is one evaluation case of a suite: graph seed, input message
+// and the expected tool calls, graph end state and answer.
+type EvalScenario struct {
+	Name              string                    `json:"name"`
+	Input             string                    `json:"input"`
+	Seed              *EvalSeed                 `json:"seed,omitempty"`
+	ExpectedToolCalls []EvalToolCallExpectation `json:"expectedToolCalls,omitempty"`
+	ExpectedGraph     *EvalGraphExpectation     `json:"expectedGraph,omitempty"`
+	ResponseContains  []string                  `json:"responseContains,omitempty"`
+	Rubric            string                    `json:"rubric,omitempty"`
+	// ToolResults stubs tools by name.
+	ToolResults map[string]map[string]any `json:"toolResults,omitempty"`
+	// ModelScript replaces the model with scripted turns.
+	ModelScript []EvalModelTurn `json:"modelScript,omitempty"`
+}
+
+// EvalSeed is the graph state a scenario starts from.
+type EvalSeed struct {
+	Objects       []EvalSeedObject       `json:"objects,omitempty"`
+	Relationships []EvalSeedRelationship `json:"relationships,omitempty"`
+}
+
+// EvalSeedObject is a graph object created for a scenario.
+type EvalSeedObject struct {
+	Ref        string         `json:"ref"`
+	Type       string         `json:"type"`
+	Key        string         `json:"key,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// EvalSeedRelationship is a relationship between two seeded objects.
+type EvalSeedRelationship struct {
+	Type       string         `json:"type"`
+	Source     string         `json:"source"`
+	Target     string         `json:"target"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// EvalToolCallExpectation matches tool calls by name (or glob) and a subset
+// of their arguments.
+type EvalToolCallExpectation struct {
+	Tool      string         `json:"tool"`
+	Args      map[string]any `json:"args,omitempty"`
+	Forbidden bool           `json:"forbidden,omitempty"`
+}
+
+// EvalGraphExpectation describes the expected graph end state.
+type EvalGraphExpectation struct {
+	Objects       []EvalObjectExpectation       `json:"objects,omitempty"`
+	Relationships []EvalRelationshipExpectation `json:"relationships,omitempty"`
+}
+
+// EvalObjectExpectation matches a seeded object by Ref, or any object by
+// Type and Key.
+type EvalObjectExpectation struct {
+	Ref        string         `json:"ref,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Key        string         `json:"key,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+	Deleted    bool           `json:"deleted,omitempty"`
+	Absent     bool           `json:"absent,omitempty"`
+}
+
+// EvalRelationshipExpectation expects a relationship between two objects.
+type EvalRelationshipExpectation struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Absent bool   `json:"absent,omitempty"`
+}
+
+// EvalModelTurn is one scripted model response.
+type EvalModelTurn struct {
+	Text      string              `json:"text,omitempty"`
+	ToolCalls []EvalModelToolCall `json:"toolCalls,omitempty"`
+}
+
+// EvalModelToolCall is a tool call made by a scripted model turn.
+type EvalModelToolCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// AgentEvalSuite is a set of evaluation scenarios for an agent definition.
+type AgentEvalSuite struct {
+	ID                string         `json:"id"`
+	ProjectID         string         `json:"projectId"`
+	AgentDefinitionID string         `json:"agentDefinitionId"`
+	Name              string         `json:"name"`
+	Description       *string        `json:"description,omitempty"`
+	Scenarios         []EvalScenario `json:"scenarios"`
+	JudgeModel        *string        `json:"judgeModel,omitempty"`
+	PassThreshold     *float64       `json:"passThreshold,omitempty"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+}
+
+// CreateEvalSuiteRequest is the request to create an evaluation suite.
+type CreateEvalSuiteRequest struct {
+	AgentDefinitionID string         `json:"agentDefinitionId"`
+	Name              string         `json:"name"`
+	Description       *string        `json:"description,omitempty"`
+	Scenarios         []EvalScenario `json:"scenarios"`
+	JudgeModel        *string        `json:"judgeModel,omitempty"`
+	PassThreshold     *float64       `json:"passThreshold,omitempty"`
+}
+
+// UpdateEvalSuiteRequest is the request to update an evaluation suite.
+// Omitted fields are left unchanged.
+type UpdateEvalSuiteRequest struct {
+	Name          *string        `json:"name,omitempty"`
+	Description   *string        `json:"description,omitempty"`
+	Scenarios     []EvalScenario `json:"scenarios,omitempty"`
+	JudgeModel    *string        `json:"judgeModel,omitempty"`
+	PassThreshold *float64       `json:"passThreshold,omitempty"`
+}
+
+// ListEvalSuitesOptions contains options for listing evaluation suites.
+type ListEvalSuitesOptions struct {
+	Limit             int
+	Offset            int
+	AgentDefinitionID string
+}
+
+// StartEvalRunRequest configures an evaluation run.
+type StartEvalRunRequest struct {
+	// Scenarios limits the run to these scenario names.
+	Scenarios []string `json:"scenarios,omitempty"`
+	// SystemPrompt evaluates an unsaved system prompt instead of the
+	// definition's.
+	SystemPrompt *string `json:"systemPrompt,omitempty"`
+}
+
+// EvalCheck is the outcome of one assertion of a scenario.
+type EvalCheck struct {
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// EvalScenarioResult is the outcome of one scenario in an evaluation run.
+type EvalScenarioResult struct {
+	Scenario  string           `json:"scenario"`
+	RunID     string           `json:"runId,omitempty"`
+	Passed    bool             `json:"passed"`
+	Score     float64          `json:"score"`
+	Checks    []EvalCheck      `json:"checks"`
+	Response  string           `json:"response,omitempty"`
+	ToolCalls []ReplayDecision `json:"toolCalls,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// AgentEvalRun is one execution of an evaluation suite.
+type AgentEvalRun struct {
+	ID                 string               `json:"id"`
+	SuiteID            string               `json:"suiteId"`
+	ProjectID          string               `json:"projectId"`
+	AgentDefinitionID  string               `json:"agentDefinitionId"`
+	DefinitionHash     string               `json:"definitionHash"`
+	DefinitionSnapshot map[string]any       `json:"definitionSnapshot"`
+	Status             string               `json:"status"`
+	Score              *float64             `json:"score,omitempty"`
+	Passed             int                  `json:"passed"`
+	Total              int                  `json:"total"`
+	Results            []EvalScenarioResult `json:"results"`
+	ErrorMessage       *string              `json:"errorMessage,omitempty"`
+	StartedAt          time.Time            `json:"startedAt"`
+	CompletedAt        *time.Time           `json:"completedAt,omitempty"`
+}
+
+// ListEvalRunsOptions contains options for listing evaluation runs.
+type ListEvalRunsOptions struct {
+	Limit  int
+	Offset int
+	Status string
+}
+
+// CompareEvalRunsOptions selects the runs to compare. Empty fields use the
+// latest completed run and the latest earlier run of a different definition
+// version.
+type CompareEvalRunsOptions struct {
+	RunID     string
+	BaseRunID string
+}
+
+// EvalScenarioComparison compares one scenario across two runs.
+type EvalScenarioComparison struct {
+	Scenario   string   `json:"scenario"`
+	Status     string   `json:"status"`
+	BaseScore  *float64 `json:"baseScore,omitempty"`
+	Score      *float64 `json:"score,omitempty"`
+	Delta      float64  `json:"delta"`
+	BasePassed bool     `json:"basePassed"`
+	Passed     bool     `json:"passed"`
+}
+
+// EvalComparison compares an evaluation run against a base run.
+type EvalComparison struct {
+	BaseRunID          string                   `json:"baseRunId"`
+	RunID              string                   `json:"runId"`
+	BaseDefinitionHash string                   `json:"baseDefinitionHash"`
+	DefinitionHash     string                   `json:"definitionHash"`
+	ChangedFields      []string                 `json:"changedFields"`
+	BaseScore          float64                  `json:"baseScore"`
+	Score              float64                  `json:"score"`
+	ScoreDelta         float64                  `json:"scoreDelta"`
+	BasePassed         int                      `json:"basePassed"`
+	Passed             int                      `json:"passed"`
+	Regressions        []string                 `json:"regressions"`
+	Improvements       []string                 `json:"improvements"`
+	Scenarios          []EvalScenarioComparison `json:"scenarios"`
+}
+
 // AgentQuestion represents a question posed by an agent to a user during execution.
 type AgentQuestion struct {
 	ID             string                `json:"id"`
@@ -149,6 +465,11 @@ type AgentQuestion struct {
 	NotificationID *string               `json:"notificationId,omitempty"`
 	CreatedAt      time.Time             `json:"createdAt"`
 	UpdatedAt      time.Time             `json:"updatedAt"`
+
+	// ToolName and ToolArgs are set on approval questions: the exact tool
+	// call waiting for the user to answer "approve" or "deny".
+	ToolName *string        `json:"toolName,omitempty"`
+	ToolArgs map[string]any `json:"toolArgs,omitempty"`
 }
 
 // AgentQuestionOption represents a structured choice for an agent question.
@@ -240,7 +561,7 @@ type TriggerResponse struct {
 	Error   *string `json:"error,omitempty"`
 }
 
-// PendingEventObject represents a graph object pending processing.
+// PendingEventObject represents a graph object event pending processing.
 type PendingEventObject struct {
 	ID        string    `json:"id"`
 	Type      string    `json:"type"`
@@ -248,6 +569,24 @@ type PendingEventObject struct {
 	Version   int       `json:"version"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	EventType     string     `json:"eventType"`
+	Status        string     `json:"status"` // new (not yet queued), pending or processing
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	LastError     *string    `json:"lastError,omitempty"`
+	EventID       *int64     `json:"eventId,omitempty"`
+	QueuedAt      time.Time  `json:"queuedAt"`
+}
+
+// ReplayEventsRequest is the request for replaying a reaction agent's events.
+type ReplayEventsRequest struct {
+	Since time.Time `json:"since"`
+}
+
+// ReplayEventsResponse is the response for replaying events.
+type ReplayEventsResponse struct {
+	Since time.Time `json:"since"`
 }
 
 // PendingEventsResponse is the response for pending events query.
@@ -747,6 +1086,44 @@ func (c *Client) BatchTrigger(ctx context.Context, agentID string, batchReq *Bat
 	return &result, nil
 }
 
+// ReplayEvents re-delivers a reaction agent's entity events since a point in
+// time. Events the agent already processed successfully are not re-run.
+// POST /api/projects/:projectId/agents/:id/replay-events
+// Requires project:write scope.
+func (c *Client) ReplayEvents(ctx context.Context, agentID string, replayReq *ReplayEventsRequest) (*APIResponse[ReplayEventsResponse], error) {
+	body, err := json.Marshal(replayReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.base+"/api/projects/"+url.PathEscape(c.projectID)+"/agents/"+url.PathEscape(agentID)+"/replay-events", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.setHeaders(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, sdkerrors.ParseErrorResponse(resp)
+	}
+
+	var result APIResponse[ReplayEventsResponse]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
 // CancelRun cancels a running agent run.
 // POST /api/projects/:projectId/agents/:id/runs/:runId/cancel
 // Requires project:write scope.
@@ -940,6 +1317,391 @@ func (c *Client) GetRunToolCalls(ctx context.Context, projectID, runID string) (
 	return &result, nil
 }
 
+// ReplayRun re-runs the agent against a recorded run, serving tool results
+// from the recording, and returns the new run with a diff of its decisions.
+// POST /api/projects/:projectId/agent-runs/:runId/replay
+// Requires agents:write scope.
+func (c *Client) ReplayRun(ctx context.Context, projectID, runID string, replayReq *ReplayRunRequest) (*APIResponse[ReplayResult], error) {
+	if replayReq == nil {
+		replayReq = &ReplayRunRequest{}
+	}
+	body, err := json.Marshal(replayReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		c.base+"/api/projects/"+url.PathEscape(projectID)+"/agent-runs/"+url.PathEscape(runID)+"/replay",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.setHeaders(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, sdkerrors.ParseErrorResponse(resp)
+	}
+
+	var result APIResponse[ReplayResult]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// --- Agent Memory Methods ---
+
+// ListMemories lists the long-term memories agents stored in a project.
+// GET /api/projects/:projectId/agent-memories
+// Requires agents:read scope.
+func (c *Client) ListMemories(ctx context.Context, projectID string, opts *ListMemoriesOptions) (*APIResponse[PaginatedResponse[AgentMemory]], error) {
+	u, err := url.Parse(c.base + "/api/projects/" + url.PathEscape(projectID) + "/agent-memories")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	if opts != nil {
+		q := u.Query()
+		if opts.Limit > 0 {
+			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		}
+		if opts.Offset > 0 {
+			q.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.AgentDefinitionID != "" {
+			q.Set("agentDefinitionId", opts.AgentDefinitionID)
+		}
+		if opts.UserID != "" {
+			q.Set("userId", opts.UserID)
+		}
+		if opts.Kind != "" {
+			q.Set("kind", opts.Kind)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := c.setHeaders(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, sdkerrors.ParseErrorResponse(resp)
+	}
+
+	var result APIResponse[PaginatedResponse[AgentMemory]]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteMemory deletes one agent memory.
+// DELETE /api/projects/:projectId/agent-memories/:memoryId
+// Requires agents:write scope.
+func (c *Client) DeleteMemory(ctx context.Context, projectID, memoryID string) error {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"DELETE",
+		c.base+"/api/projects/"+url.PathEscape(projectID)+"/agent-memories/"+url.PathEscape(memoryID),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := c.setHeaders(req); err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return sdkerrors.ParseErrorResponse(resp)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// PurgeMemories deletes every agent memory in a project matching the request.
+// POST /api/projects/:projectId/agent-memories/purge
+// Requires agents:write scope.
+func (c *Client) PurgeMemories(ctx context.Context, projectID string, purgeReq *PurgeMemoriesRequest) (*APIResponse[PurgeMemoriesResponse], error) {
+	if purgeReq == nil {
+		purgeReq = &PurgeMemoriesRequest{}
+	}
+	body, err := json.Marshal(purgeReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		c.base+"/api/projects/"+url.PathEscape(projectID)+"/agent-memories/purge",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.setHeaders(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, sdkerrors.ParseErrorResponse(resp)
+	}
+
+	var result APIResponse[PurgeMemoriesResponse]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// --- Agent Eval Methods ---
+
+// ListEvalSuites lists the evaluation suites of a project.
+// GET /api/projects/:projectId/agent-eval-suites
+// Requires agents:read scope.
+func (c *Client) ListEvalSuites(ctx context.Context, projectID string, opts *ListEvalSuitesOptions) (*APIResponse[PaginatedResponse[AgentEvalSuite]], error) {
+	u, err := url.Parse(c.base + "/api/projects/" + url.PathEscape(projectID) + "/agent-eval-suites")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	if opts != nil {
+		q := u.Query()
+		if opts.Limit > 0 {
+			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		}
+		if opts.Offset > 0 {
+			q.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.AgentDefinitionID != "" {
+			q.Set("agentDefinitionId", opts.AgentDefinitionID)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var result APIResponse[PaginatedResponse[AgentEvalSuite]]
+	if err := c.doEvalRequest(ctx, "GET", u.String(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateEvalSuite creates an evaluation suite for an agent definition.
+// POST /api/projects/:projectId/agent-eval-suites
+// Requires agents:write scope.
+func (c *Client) CreateEvalSuite(ctx context.Context, projectID string, createReq *CreateEvalSuiteRequest) (*APIResponse[AgentEvalSuite], error) {
+	var result APIResponse[AgentEvalSuite]
+	if err := c.doEvalRequest(ctx, "POST", c.evalSuitesURL(projectID), createReq, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetEvalSuite gets an evaluation suite by ID.
+// GET /api/projects/:projectId/agent-eval-suites/:suiteId
+// Requires agents:read scope.
+func (c *Client) GetEvalSuite(ctx context.Context, projectID, suiteID string) (*APIResponse[AgentEvalSuite], error) {
+	var result APIResponse[AgentEvalSuite]
+	if err := c.doEvalRequest(ctx, "GET", c.evalSuitesURL(projectID)+"/"+url.PathEscape(suiteID), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateEvalSuite updates an evaluation suite.
+// PATCH /api/projects/:projectId/agent-eval-suites/:suiteId
+// Requires agents:write scope.
+func (c *Client) UpdateEvalSuite(ctx context.Context, projectID, suiteID string, updateReq *UpdateEvalSuiteRequest) (*APIResponse[AgentEvalSuite], error) {
+	var result APIResponse[AgentEvalSuite]
+	if err := c.doEvalRequest(ctx, "PATCH", c.evalSuitesURL(projectID)+"/"+url.PathEscape(suiteID), updateReq, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteEvalSuite deletes an evaluation suite and its runs.
+// DELETE /api/projects/:projectId/agent-eval-suites/:suiteId
+// Requires agents:write scope.
+func (c *Client) DeleteEvalSuite(ctx context.Context, projectID, suiteID string) error {
+	return c.doEvalRequest(ctx, "DELETE", c.evalSuitesURL(projectID)+"/"+url.PathEscape(suiteID), nil, nil)
+}
+
+// StartEvalRun starts running an evaluation suite. The run executes in the
+// background; poll GetEvalRun until its status is no longer "running".
+// POST /api/projects/:projectId/agent-eval-suites/:suiteId/runs
+// Requires agents:write scope.
+func (c *Client) StartEvalRun(ctx context.Context, projectID, suiteID string, startReq *StartEvalRunRequest) (*APIResponse[AgentEvalRun], error) {
+	if startReq == nil {
+		startReq = &StartEvalRunRequest{}
+	}
+	var result APIResponse[AgentEvalRun]
+	if err := c.doEvalRequest(ctx, "POST", c.evalSuitesURL(projectID)+"/"+url.PathEscape(suiteID)+"/runs", startReq, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListEvalRuns lists the runs of an evaluation suite, newest first.
+// GET /api/projects/:projectId/agent-eval-suites/:suiteId/runs
+// Requires agents:read scope.
+func (c *Client) ListEvalRuns(ctx context.Context, projectID, suiteID string, opts *ListEvalRunsOptions) (*APIResponse[PaginatedResponse[AgentEvalRun]], error) {
+	u, err := url.Parse(c.evalSuitesURL(projectID) + "/" + url.PathEscape(suiteID) + "/runs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	if opts != nil {
+		q := u.Query()
+		if opts.Limit > 0 {
+			q.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		}
+		if opts.Offset > 0 {
+			q.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.Status != "" {
+			q.Set("status", opts.Status)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var result APIResponse[PaginatedResponse[AgentEvalRun]]
+	if err := c.doEvalRequest(ctx, "GET", u.String(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetEvalRun gets an evaluation run with its scenario results.
+// GET /api/projects/:projectId/agent-eval-suites/:suiteId/runs/:runId
+// Requires agents:read scope.
+func (c *Client) GetEvalRun(ctx context.Context, projectID, suiteID, runID string) (*APIResponse[AgentEvalRun], error) {
+	var result APIResponse[AgentEvalRun]
+	if err := c.doEvalRequest(ctx, "GET", c.evalSuitesURL(projectID)+"/"+url.PathEscape(suiteID)+"/runs/"+url.PathEscape(runID), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CompareEvalRuns compares two evaluation runs of a suite scenario by
+// scenario.
+// GET /api/projects/:projectId/agent-eval-suites/:suiteId/compare
+// Requires agents:read scope.
+func (c *Client) CompareEvalRuns(ctx context.Context, projectID, suiteID string, opts *CompareEvalRunsOptions) (*APIResponse[EvalComparison], error) {
+	u, err := url.Parse(c.evalSuitesURL(projectID) + "/" + url.PathEscape(suiteID) + "/compare")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	if opts != nil {
+		q := u.Query()
+		if opts.RunID != "" {
+			q.Set("runId", opts.RunID)
+		}
+		if opts.BaseRunID != "" {
+			q.Set("baseRunId", opts.BaseRunID)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var result APIResponse[EvalComparison]
+	if err := c.doEvalRequest(ctx, "GET", u.String(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) evalSuitesURL(projectID string) string {
+	return c.base + "/api/projects/" + url.PathEscape(projectID) + "/agent-eval-suites"
+}
+
+// doEvalRequest sends an evaluation API request with an optional JSON body
+// and decodes the response into out, when given.
+func (c *Client) doEvalRequest(ctx context.Context, method, target string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := c.setHeaders(req); err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return sdkerrors.ParseErrorResponse(resp)
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
 // --- Webhook Hook Methods ---
 
 // CreateWebhookHook creates a new webhook hook for an agent.
@@ -401,3 +401,311 @@ func TestAgentsRespondToQuestionError(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestAgentsCreateA2ATask(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("POST", "/api/projects/proj_test123/a2a/agents/summariser/tasks", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertHeader(t, r, "X-API-Key", "test_key")
+
+		var reqBody agents.CreateA2ATaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if reqBody.Message != "Summarise the roadmap" {
+			t.Errorf("expected message 'Summarise the roadmap', got %s", reqBody.Message)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		testutil.JSONResponse(t, w, map[string]interface{}{
+			"id":           "run_test123",
+			"agent":        "summariser",
+			"state":        "working",
+			"currentRunId": "run_test123",
+		})
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	task, err := client.Agents.CreateA2ATask(context.Background(), "proj_test123", "summariser", &agents.CreateA2ATaskRequest{
+		Message: "Summarise the roadmap",
+	})
+	if err != nil {
+		t.Fatalf("CreateA2ATask() error = %v", err)
+	}
+	if task.ID != "run_test123" || task.State != "working" {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	if task.Done() {
+		t.Error("working task should not be done")
+	}
+}
+
+func TestAgentsStreamA2ATask(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("GET", "/api/projects/proj_test123/a2a/tasks/run_test123/events", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertHeader(t, r, "Accept", "text/event-stream")
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("event: message\ndata: {\"id\":\"msg_1\",\"role\":\"assistant\"}\n\n" +
+			"event: task\ndata: {\"id\":\"run_test123\",\"state\":\"completed\",\"result\":\"Done\"}\n\n"))
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	stream, err := client.Agents.StreamA2ATask(context.Background(), "proj_test123", "run_test123")
+	if err != nil {
+		t.Fatalf("StreamA2ATask() error = %v", err)
+	}
+	defer stream.Close()
+
+	var events []*agents.A2ATaskEvent
+	for evt := range stream.Events() {
+		events = append(events, evt)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != "message" || events[0].Message == nil || events[0].Message.ID != "msg_1" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Task == nil || !events[1].Task.Done() || events[1].Task.Result != "Done" {
+		t.Errorf("unexpected task event: %+v", events[1])
+	}
+}
+
+func TestAgentsReplayRun(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("POST", "/api/projects/proj_test123/agent-runs/run_test123/replay", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertHeader(t, r, "X-API-Key", "test_key")
+
+		var reqBody agents.ReplayRunRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if reqBody.ForkFromStep != 3 || len(reqBody.LiveTools) != 1 {
+			t.Errorf("unexpected request: %+v", reqBody)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		testutil.JSONResponse(t, w, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"runId":       "run_replay1",
+				"sourceRunId": "run_test123",
+				"status":      "success",
+				"diff": map[string]interface{}{
+					"identical":       false,
+					"originalCalls":   2,
+					"replayedCalls":   1,
+					"responseChanged": true,
+					"divergences": []map[string]interface{}{
+						{"index": 1, "original": map[string]interface{}{"step": 2, "toolName": "get_object"}},
+					},
+				},
+			},
+		})
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	result, err := client.Agents.ReplayRun(context.Background(), "proj_test123", "run_test123", &agents.ReplayRunRequest{
+		ForkFromStep: 3,
+		LiveTools:    []string{"search_*"},
+	})
+	if err != nil {
+		t.Fatalf("ReplayRun() error = %v", err)
+	}
+
+	diff := result.Data.Diff
+	if diff == nil || diff.Identical || len(diff.Divergences) != 1 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	if diff.Divergences[0].Replayed != nil || diff.Divergences[0].Original.ToolName != "get_object" {
+		t.Errorf("unexpected divergence: %+v", diff.Divergences[0])
+	}
+}
+
+func TestAgentsListMemories(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("GET", "/api/projects/proj_test123/agent-memories", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertHeader(t, r, "X-API-Key", "test_key")
+		if got := r.URL.Query().Get("agentDefinitionId"); got != "def_1" {
+			t.Errorf("agentDefinitionId = %q, want def_1", got)
+		}
+		if got := r.URL.Query().Get("kind"); got != "preference" {
+			t.Errorf("kind = %q, want preference", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		testutil.JSONResponse(t, w, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"items": []map[string]interface{}{
+					{"id": "mem_1", "agentDefinitionId": "def_1", "kind": "preference", "content": "Prefers short answers", "recallCount": 2},
+				},
+				"totalCount": 1,
+				"limit":      20,
+				"offset":     0,
+			},
+		})
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	result, err := client.Agents.ListMemories(context.Background(), "proj_test123", &agents.ListMemoriesOptions{
+		AgentDefinitionID: "def_1",
+		Kind:              "preference",
+	})
+	if err != nil {
+		t.Fatalf("ListMemories() error = %v", err)
+	}
+	if len(result.Data.Items) != 1 || result.Data.Items[0].Content != "Prefers short answers" || result.Data.Items[0].RecallCount != 2 {
+		t.Errorf("unexpected memories: %+v", result.Data.Items)
+	}
+}
+
+func TestAgentsPurgeMemories(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("POST", "/api/projects/proj_test123/agent-memories/purge", func(w http.ResponseWriter, r *http.Request) {
+		var reqBody agents.PurgeMemoriesRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if reqBody.UserID == nil || *reqBody.UserID != "user_1" || reqBody.AgentDefinitionID != nil {
+			t.Errorf("unexpected request: %+v", reqBody)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		testutil.JSONResponse(t, w, map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"deleted": 4},
+		})
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	userID := "user_1"
+	result, err := client.Agents.PurgeMemories(context.Background(), "proj_test123", &agents.PurgeMemoriesRequest{UserID: &userID})
+	if err != nil {
+		t.Fatalf("PurgeMemories() error = %v", err)
+	}
+	if result.Data.Deleted != 4 {
+		t.Errorf("Deleted = %d, want 4", result.Data.Deleted)
+	}
+}
+
+func TestAgentsStartEvalRun(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("POST", "/api/projects/proj_test123/agent-eval-suites/suite_1/runs", func(w http.ResponseWriter, r *http.Request) {
+		var reqBody agents.StartEvalRunRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if len(reqBody.Scenarios) != 1 || reqBody.Scenarios[0] != "links owner" {
+			t.Errorf("unexpected request: %+v", reqBody)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		testutil.JSONResponse(t, w, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"id": "evalrun_1", "suiteId": "suite_1", "status": "running", "definitionHash": "abc", "total": 1,
+			},
+		})
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	result, err := client.Agents.StartEvalRun(context.Background(), "proj_test123", "suite_1", &agents.StartEvalRunRequest{
+		Scenarios: []string{"links owner"},
+	})
+	if err != nil {
+		t.Fatalf("StartEvalRun() error = %v", err)
+	}
+	if result.Data.ID != "evalrun_1" || result.Data.Status != "running" || result.Data.Total != 1 {
+		t.Errorf("unexpected run: %+v", result.Data)
+	}
+}
+
+func TestAgentsCompareEvalRuns(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("GET", "/api/projects/proj_test123/agent-eval-suites/suite_1/compare", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("baseRunId"); got != "evalrun_1" {
+			t.Errorf("baseRunId = %q, want evalrun_1", got)
+		}
+		if r.URL.Query().Has("runId") {
+			t.Error("runId should be omitted")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		testutil.JSONResponse(t, w, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"baseRunId":     "evalrun_1",
+				"runId":         "evalrun_2",
+				"changedFields": []string{"systemPrompt"},
+				"scoreDelta":    -0.25,
+				"regressions":   []string{"links owner"},
+				"improvements":  []string{},
+				"scenarios": []map[string]interface{}{
+					{"scenario": "links owner", "status": "regressed", "baseScore": 1, "score": 0.75, "delta": -0.25, "basePassed": true},
+				},
+			},
+		})
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+	})
+
+	result, err := client.Agents.CompareEvalRuns(context.Background(), "proj_test123", "suite_1", &agents.CompareEvalRunsOptions{BaseRunID: "evalrun_1"})
+	if err != nil {
+		t.Fatalf("CompareEvalRuns() error = %v", err)
+	}
+	cmp := result.Data
+	if cmp.RunID != "evalrun_2" || len(cmp.Regressions) != 1 || cmp.ChangedFields[0] != "systemPrompt" {
+		t.Errorf("unexpected comparison: %+v", cmp)
+	}
+	if len(cmp.Scenarios) != 1 || cmp.Scenarios[0].Status != "regressed" || *cmp.Scenarios[0].Score != 0.75 {
+		t.Errorf("unexpected scenarios: %+v", cmp.Scenarios)
+	}
+}
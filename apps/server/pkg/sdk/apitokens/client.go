@@ -32,29 +32,34 @@ func NewClient(httpClient *http.Client, baseURL string, authProvider auth.Provid
 
 // APIToken represents an API token (includes full token value if retrieved by ID)
 type APIToken struct {
-	ID        string   `json:"id"`
-	Name      string   `json:"name"`
-	Prefix    string   `json:"prefix"`
-	Token     string   `json:"token,omitempty"` // Full token value - available when retrieved by ID
-	Scopes    []string `json:"scopes"`
-	CreatedAt string   `json:"createdAt"`
-	RevokedAt *string  `json:"revokedAt,omitempty"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Prefix       string   `json:"prefix"`
+	Token        string   `json:"token,omitempty"` // Full token value - available when retrieved by ID
+	Scopes       []string `json:"scopes"`
+	AllowedTools []string `json:"allowedTools,omitempty"` // MCP tools the token is limited to
+	CreatedAt    string   `json:"createdAt"`
+	RevokedAt    *string  `json:"revokedAt,omitempty"`
 }
 
 // CreateTokenResponse represents the response when creating a token (includes full token value)
 type CreateTokenResponse struct {
-	ID        string   `json:"id"`
-	Name      string   `json:"name"`
-	Token     string   `json:"token"` // Full token value - only returned at creation
-	Prefix    string   `json:"prefix"`
-	Scopes    []string `json:"scopes"`
-	CreatedAt string   `json:"createdAt"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Token        string   `json:"token"` // Full token value - only returned at creation
+	Prefix       string   `json:"prefix"`
+	Scopes       []string `json:"scopes"`
+	AllowedTools []string `json:"allowedTools,omitempty"` // MCP tools the token is limited to
+	CreatedAt    string   `json:"createdAt"`
 }
 
 // CreateTokenRequest represents an API token creation request
 type CreateTokenRequest struct {
 	Name   string   `json:"name"`
 	Scopes []string `json:"scopes"`
+	// AllowedTools limits the token to these MCP tools; omit for all tools
+	// its scopes allow
+	AllowedTools []string `json:"allowedTools,omitempty"`
 }
 
 // ListResponse represents the response from listing tokens
@@ -177,3 +177,25 @@ func (c *Client) GetPrompt(ctx context.Context, name string, arguments interface
 	}
 	return c.CallMethod(ctx, "prompts/get", params)
 }
+
+// ListResourceTemplates lists the URI templates of parameterized MCP resources.
+func (c *Client) ListResourceTemplates(ctx context.Context) (json.RawMessage, error) {
+	return c.CallMethod(ctx, "resources/templates/list", nil)
+}
+
+// Complete requests suggested values for a prompt argument or resource
+// template variable. refType is "ref/prompt" (ref is the prompt name) or
+// "ref/resource" (ref is the URI template).
+func (c *Client) Complete(ctx context.Context, refType, ref, argument, value string) (json.RawMessage, error) {
+	refParams := map[string]interface{}{"type": refType}
+	if refType == "ref/resource" {
+		refParams["uri"] = ref
+	} else {
+		refParams["name"] = ref
+	}
+	params := map[string]interface{}{
+		"ref":      refParams,
+		"argument": map[string]string{"name": argument, "value": value},
+	}
+	return c.CallMethod(ctx, "completion/complete", params)
+}
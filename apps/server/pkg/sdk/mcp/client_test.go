@@ -185,3 +185,65 @@ func TestMCPListResources(t *testing.T) {
 		t.Error("expected resources result, got empty")
 	}
 }
+
+func TestMCPComplete(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("POST", "/api/mcp/rpc", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+			Params struct {
+				Ref      map[string]string `json:"ref"`
+				Argument map[string]string `json:"argument"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Method != "completion/complete" {
+			t.Errorf("method = %q, want completion/complete", req.Method)
+		}
+		if req.Params.Ref["type"] != "ref/prompt" || req.Params.Ref["name"] != "explore_entity_type" {
+			t.Errorf("unexpected ref: %v", req.Params.Ref)
+		}
+		if req.Params.Argument["name"] != "entity_type" || req.Params.Argument["value"] != "Dec" {
+			t.Errorf("unexpected argument: %v", req.Params.Argument)
+		}
+
+		response := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": map[string]interface{}{
+				"completion": map[string]interface{}{"values": []string{"Decision"}, "total": 1},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	})
+
+	client, _ := sdk.New(sdk.Config{
+		ServerURL: mock.URL,
+		Auth:      sdk.AuthConfig{Mode: "apikey", APIKey: "test_key"},
+		ProjectID: "proj_test123",
+	})
+
+	result, err := client.MCP.Complete(context.Background(), "ref/prompt", "explore_entity_type", "entity_type", "Dec")
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	var out struct {
+		Completion struct {
+			Values []string `json:"values"`
+		} `json:"completion"`
+	}
+	if err := json.Unmarshal(result, &out); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(out.Completion.Values) != 1 || out.Completion.Values[0] != "Decision" {
+		t.Errorf("unexpected values: %v", out.Completion.Values)
+	}
+}
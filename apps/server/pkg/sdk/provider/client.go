@@ -91,6 +91,54 @@ type UsageSummary struct {
 	Data []UsageSummaryRow `json:"data"`
 }
 
+// AgentUsageRow is the aggregated usage of one agent. AgentID is nil for
+// usage not made by an agent run (e.g. extraction).
+type AgentUsageRow struct {
+	AgentID           *string `json:"agent_id"`
+	AgentName         *string `json:"agent_name"`
+	AgentDefinitionID *string `json:"agent_definition_id"`
+	Runs              int64   `json:"runs"`
+	TotalInput        int64   `json:"total_input"`
+	TotalOutput       int64   `json:"total_output"`
+	EstimatedCostUSD  float64 `json:"estimated_cost_usd"`
+}
+
+// AgentUsageSummary is the API response for a per-agent usage query.
+type AgentUsageSummary struct {
+	Note string          `json:"note"`
+	Data []AgentUsageRow `json:"data"`
+}
+
+// Budget is a daily or monthly token/cost limit on LLM usage for an org,
+// project ("project" scope) or agent definition ("agent" scope).
+type Budget struct {
+	ID                string    `json:"id"`
+	OrgID             string    `json:"orgId"`
+	Scope             string    `json:"scope"`
+	ProjectID         *string   `json:"projectId,omitempty"`
+	AgentDefinitionID *string   `json:"agentDefinitionId,omitempty"`
+	Period            string    `json:"period"`
+	TokenLimit        *int64    `json:"tokenLimit,omitempty"`
+	CostLimitUSD      *float64  `json:"costLimitUsd,omitempty"`
+	SoftLimitPercent  int       `json:"softLimitPercent"`
+	Enabled           bool      `json:"enabled"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// BudgetStatus is a budget with its usage in the current period. State is
+// "ok", "soft_limit" or "exceeded".
+type BudgetStatus struct {
+	Budget       Budget    `json:"budget"`
+	PeriodStart  time.Time `json:"periodStart"`
+	PeriodEnd    time.Time `json:"periodEnd"`
+	TokensUsed   int64     `json:"tokensUsed"`
+	CostUsedUSD  float64   `json:"costUsedUsd"`
+	TokenPercent *float64  `json:"tokenPercent,omitempty"`
+	CostPercent  *float64  `json:"costPercent,omitempty"`
+	State        string    `json:"state"`
+}
+
 // TestProviderResponse is returned by the provider test endpoint.
 type TestProviderResponse struct {
 	Provider  string `json:"provider"`
@@ -115,6 +163,19 @@ type UpsertProviderConfigRequest struct {
 	EmbeddingModel     string `json:"embeddingModel,omitempty"`
 }
 
+// UpsertBudgetRequest is the request body for creating or updating a budget.
+// Set AgentDefinitionID for an agent budget, ProjectID alone for a project
+// budget, neither for an org budget. Scope and period cannot be updated.
+type UpsertBudgetRequest struct {
+	ProjectID         *string  `json:"projectId,omitempty"`
+	AgentDefinitionID *string  `json:"agentDefinitionId,omitempty"`
+	Period            string   `json:"period,omitempty"` // "daily" or "monthly"
+	TokenLimit        *int64   `json:"tokenLimit,omitempty"`
+	CostLimitUSD      *float64 `json:"costLimitUsd,omitempty"`
+	SoftLimitPercent  *int     `json:"softLimitPercent,omitempty"`
+	Enabled           *bool    `json:"enabled,omitempty"`
+}
+
 // --- Organization Provider Config Methods ---
 
 // UpsertOrgConfig stores credentials and model selections for an organization's provider.
@@ -261,6 +322,89 @@ func (c *Client) GetOrgUsage(ctx context.Context, orgID string, since, until tim
 	return &result, nil
 }
 
+// GetProjectUsageByAgent returns usage and estimated cost per agent for a project.
+func (c *Client) GetProjectUsageByAgent(ctx context.Context, projectID string, since, until time.Time) (*AgentUsageSummary, error) {
+	path := fmt.Sprintf("/api/v1/projects/%s/usage/agents", url.PathEscape(projectID))
+	path = appendTimeRange(path, since, until)
+
+	var result AgentUsageSummary
+	if err := c.doJSON(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetOrgUsageByAgent returns usage and estimated cost per agent for an org.
+func (c *Client) GetOrgUsageByAgent(ctx context.Context, orgID string, since, until time.Time) (*AgentUsageSummary, error) {
+	path := fmt.Sprintf("/api/v1/organizations/%s/usage/agents", url.PathEscape(orgID))
+	path = appendTimeRange(path, since, until)
+
+	var result AgentUsageSummary
+	if err := c.doJSON(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// --- Budget Methods ---
+
+// ListBudgets returns all LLM budgets of an organization.
+func (c *Client) ListBudgets(ctx context.Context, orgID string) ([]Budget, error) {
+	path := fmt.Sprintf("/api/v1/organizations/%s/budgets", url.PathEscape(orgID))
+	var result []Budget
+	if err := c.doJSON(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateBudget adds an org, project or agent-definition LLM budget.
+func (c *Client) CreateBudget(ctx context.Context, orgID string, req *UpsertBudgetRequest) (*Budget, error) {
+	path := fmt.Sprintf("/api/v1/organizations/%s/budgets", url.PathEscape(orgID))
+	var result Budget
+	if err := c.doJSON(ctx, "POST", path, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateBudget changes a budget's limits, soft limit or enabled flag.
+func (c *Client) UpdateBudget(ctx context.Context, orgID, budgetID string, req *UpsertBudgetRequest) (*Budget, error) {
+	path := fmt.Sprintf("/api/v1/organizations/%s/budgets/%s", url.PathEscape(orgID), url.PathEscape(budgetID))
+	var result Budget
+	if err := c.doJSON(ctx, "PUT", path, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteBudget removes a budget.
+func (c *Client) DeleteBudget(ctx context.Context, orgID, budgetID string) error {
+	path := fmt.Sprintf("/api/v1/organizations/%s/budgets/%s", url.PathEscape(orgID), url.PathEscape(budgetID))
+	return c.doJSON(ctx, "DELETE", path, nil, nil)
+}
+
+// GetOrgBudgetStatus returns every budget of an org with its current usage.
+func (c *Client) GetOrgBudgetStatus(ctx context.Context, orgID string) ([]BudgetStatus, error) {
+	path := fmt.Sprintf("/api/v1/organizations/%s/budgets/status", url.PathEscape(orgID))
+	var result []BudgetStatus
+	if err := c.doJSON(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProjectBudgetStatus returns the budgets that apply to a project with
+// their current usage.
+func (c *Client) GetProjectBudgetStatus(ctx context.Context, projectID string) ([]BudgetStatus, error) {
+	path := fmt.Sprintf("/api/v1/projects/%s/budgets/status", url.PathEscape(projectID))
+	var result []BudgetStatus
+	if err := c.doJSON(ctx, "GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // --- Internal helpers ---
 
 func (c *Client) doJSON(ctx context.Context, method, path string, bodyIn, bodyOut any) error {
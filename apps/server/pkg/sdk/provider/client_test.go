@@ -348,6 +348,119 @@ func TestGetOrgUsage(t *testing.T) {
 	}
 }
 
+func TestGetProjectUsageByAgent(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	agentID := "agent_test123"
+	mock.OnJSON("GET", "/api/v1/projects/proj_test123/usage/agents",
+		http.StatusOK, provider.AgentUsageSummary{
+			Data: []provider.AgentUsageRow{
+				{AgentID: &agentID, Runs: 3, TotalInput: 1200, TotalOutput: 300, EstimatedCostUSD: 0.01},
+				{Runs: 0, TotalInput: 500},
+			},
+		})
+
+	c := newClient(t, mock)
+	result, err := c.Provider.GetProjectUsageByAgent(context.Background(), "proj_test123", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetProjectUsageByAgent() error = %v", err)
+	}
+	if len(result.Data) != 2 {
+		t.Fatalf("expected 2 usage rows, got %d", len(result.Data))
+	}
+	if result.Data[0].AgentID == nil || *result.Data[0].AgentID != agentID || result.Data[0].Runs != 3 {
+		t.Errorf("unexpected first row: %+v", result.Data[0])
+	}
+	if result.Data[1].AgentID != nil {
+		t.Errorf("expected nil agent for non-agent usage, got %v", *result.Data[1].AgentID)
+	}
+}
+
+// --- Budget Tests ---
+
+func TestCreateBudget(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("POST", "/api/v1/organizations/org_test456/budgets",
+		func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body["period"] != "daily" || body["costLimitUsd"] != 5.0 || body["projectId"] != "proj_test123" {
+				t.Errorf("unexpected request body: %v", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			if err := encodeJSON(w, map[string]any{
+				"id": "budget_1", "orgId": "org_test456", "scope": "project",
+				"projectId": "proj_test123", "period": "daily", "costLimitUsd": 5.0,
+				"softLimitPercent": 80, "enabled": true,
+			}); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		})
+
+	c := newClient(t, mock)
+	projectID, limit := "proj_test123", 5.0
+	result, err := c.Provider.CreateBudget(context.Background(), "org_test456", &provider.UpsertBudgetRequest{
+		ProjectID:    &projectID,
+		Period:       "daily",
+		CostLimitUSD: &limit,
+	})
+	if err != nil {
+		t.Fatalf("CreateBudget() error = %v", err)
+	}
+	if result.ID != "budget_1" || result.Scope != "project" || result.SoftLimitPercent != 80 {
+		t.Errorf("unexpected budget: %+v", result)
+	}
+}
+
+func TestGetOrgBudgetStatus(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.OnJSON("GET", "/api/v1/organizations/org_test456/budgets/status",
+		http.StatusOK, []map[string]any{{
+			"budget":       map[string]any{"id": "budget_1", "scope": "org", "period": "monthly", "tokenLimit": 1000},
+			"tokensUsed":   900,
+			"costUsedUsd":  0.5,
+			"tokenPercent": 90.0,
+			"state":        "soft_limit",
+		}})
+
+	c := newClient(t, mock)
+	result, err := c.Provider.GetOrgBudgetStatus(context.Background(), "org_test456")
+	if err != nil {
+		t.Fatalf("GetOrgBudgetStatus() error = %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 status, got %d", len(result))
+	}
+	if result[0].State != "soft_limit" || result[0].TokenPercent == nil || *result[0].TokenPercent != 90 {
+		t.Errorf("unexpected status: %+v", result[0])
+	}
+	if result[0].Budget.TokenLimit == nil || *result[0].Budget.TokenLimit != 1000 {
+		t.Errorf("unexpected budget: %+v", result[0].Budget)
+	}
+}
+
+func TestDeleteBudget(t *testing.T) {
+	mock := testutil.NewMockServer(t)
+	defer mock.Close()
+
+	mock.On("DELETE", "/api/v1/organizations/org_test456/budgets/budget_1",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+	c := newClient(t, mock)
+	if err := c.Provider.DeleteBudget(context.Background(), "org_test456", "budget_1"); err != nil {
+		t.Fatalf("DeleteBudget() error = %v", err)
+	}
+}
+
 // --- Error handling ---
 
 func TestUpsertOrgConfig_4xxError(t *testing.T) {
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/emergent-company/emergent.memory/domain/datasource"
+	"github.com/emergent-company/emergent.memory/internal/config"
+	"github.com/emergent-company/emergent.memory/internal/testutil"
+)
+
+// DataSourceWebhookTestSuite tests how webhook deliveries and their sync jobs are recorded
+type DataSourceWebhookTestSuite struct {
+	suite.Suite
+	testDB      *testutil.TestDB
+	ctx         context.Context
+	repo        *datasource.Repository
+	jobsService *datasource.JobsService
+
+	// Test fixtures
+	projectID     string
+	integrationID string
+}
+
+func TestDataSourceWebhookSuite(t *testing.T) {
+	suite.Run(t, new(DataSourceWebhookTestSuite))
+}
+
+func (s *DataSourceWebhookTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	testDB, err := testutil.SetupTestDB(s.ctx, "ds_webhook")
+	s.Require().NoError(err, "Failed to setup test database")
+	s.testDB = testDB
+
+	s.repo = datasource.NewRepository(testDB.DB, log)
+	s.jobsService = datasource.NewJobsService(testDB.DB, log, &config.Config{})
+
+	orgID := uuid.NewString()
+	s.projectID = uuid.NewString()
+	s.integrationID = uuid.NewString()
+
+	s.Require().NoError(testutil.CreateTestOrganization(s.ctx, testDB.DB, orgID, "Test Org"))
+	s.Require().NoError(testutil.CreateTestProject(s.ctx, testDB.DB, testutil.TestProject{
+		ID:    s.projectID,
+		OrgID: orgID,
+		Name:  "Test Project",
+	}, testutil.AdminUser.ID))
+
+	_, err = testDB.DB.NewRaw(`
+		INSERT INTO kb.data_source_integrations (id, project_id, name, provider_type, source_type)
+		VALUES (?, ?, 'Test Integration', 'github', 'github-issue')
+	`, s.integrationID, s.projectID).Exec(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *DataSourceWebhookTestSuite) TearDownSuite() {
+	if s.testDB != nil {
+		s.testDB.Close()
+	}
+}
+
+func (s *DataSourceWebhookTestSuite) SetupTest() {
+	_, err := s.testDB.DB.NewRaw("TRUNCATE TABLE kb.data_source_webhook_deliveries, kb.data_source_sync_jobs CASCADE").Exec(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *DataSourceWebhookTestSuite) newDelivery(deliveryID string) *datasource.DataSourceWebhookDelivery {
+	return &datasource.DataSourceWebhookDelivery{
+		ID:            uuid.NewString(),
+		IntegrationID: s.integrationID,
+		ProjectID:     s.projectID,
+		DeliveryID:    deliveryID,
+		ItemIDs:       datasource.StringArray{"issue:1"},
+		Status:        datasource.WebhookDeliveryAccepted,
+		ReceivedAt:    time.Now(),
+	}
+}
+
+func (s *DataSourceWebhookTestSuite) newJob(id string) *datasource.DataSourceSyncJob {
+	return &datasource.DataSourceSyncJob{
+		ID:            id,
+		IntegrationID: s.integrationID,
+		ProjectID:     s.projectID,
+		Status:        datasource.JobStatusPending,
+		TriggerType:   datasource.TriggerTypeWebhook,
+	}
+}
+
+func (s *DataSourceWebhookTestSuite) countDeliveries(deliveryID string) int {
+	count, err := s.testDB.DB.NewSelect().
+		Model((*datasource.DataSourceWebhookDelivery)(nil)).
+		Where("integration_id = ?", s.integrationID).
+		Where("delivery_id = ?", deliveryID).
+		Count(s.ctx)
+	s.Require().NoError(err)
+	return count
+}
+
+func (s *DataSourceWebhookTestSuite) TestCreateWebhookDeliveryJob_RetryAfterJobFailure() {
+	// A job ID that is already taken makes the job insert fail
+	existing := s.newJob(uuid.NewString())
+	s.Require().NoError(s.jobsService.Create(s.ctx, existing))
+
+	inserted, err := s.repo.CreateWebhookDeliveryJob(s.ctx, s.newDelivery("delivery-1"), s.newJob(existing.ID))
+	s.Require().Error(err)
+	s.False(inserted)
+	s.Equal(0, s.countDeliveries("delivery-1"), "failed delivery must not be recorded")
+
+	// The provider's retry is processed instead of being treated as a replay
+	job := s.newJob(uuid.NewString())
+	delivery := s.newDelivery("delivery-1")
+	inserted, err = s.repo.CreateWebhookDeliveryJob(s.ctx, delivery, job)
+	s.Require().NoError(err)
+	s.True(inserted)
+	s.Require().NotNil(delivery.SyncJobID)
+	s.Equal(job.ID, *delivery.SyncJobID)
+
+	deliveries, err := s.repo.ListWebhookDeliveries(s.ctx, s.integrationID, 10)
+	s.Require().NoError(err)
+	s.Require().Len(deliveries, 1)
+	s.Require().NotNil(deliveries[0].SyncJobID)
+	s.Equal(job.ID, *deliveries[0].SyncJobID)
+
+	_, err = s.jobsService.GetByID(s.ctx, job.ID)
+	s.NoError(err)
+}
+
+func (s *DataSourceWebhookTestSuite) TestCreateWebhookDeliveryJob_ReplayCreatesNoJob() {
+	inserted, err := s.repo.CreateWebhookDeliveryJob(s.ctx, s.newDelivery("delivery-2"), s.newJob(uuid.NewString()))
+	s.Require().NoError(err)
+	s.True(inserted)
+
+	replayJob := s.newJob(uuid.NewString())
+	inserted, err = s.repo.CreateWebhookDeliveryJob(s.ctx, s.newDelivery("delivery-2"), replayJob)
+	s.Require().NoError(err)
+	s.False(inserted)
+	s.Equal(1, s.countDeliveries("delivery-2"))
+
+	_, err = s.jobsService.GetByID(s.ctx, replayJob.ID)
+	s.ErrorIs(err, datasource.ErrJobNotFound)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/emergent-company/emergent.memory/apps/server/pkg/sdk/agents"
 	"github.com/spf13/cobra"
@@ -76,6 +78,21 @@ var runsAgentCmd = &cobra.Command{
 	RunE:  runGetAgentRuns,
 }
 
+var replayRunCmd = &cobra.Command{
+	Use:   "replay [run-id]",
+	Short: "Replay an agent run",
+	Long: `Re-run an agent against a recorded run. Tool results are served from the
+recording unless listed in --live-tools, and the new decisions are diffed
+against the original.
+
+Examples:
+  emergent-cli agents replay <run-id>
+  emergent-cli agents replay <run-id> --fork-from-step 4 --prompt "Only use verified sources"
+  emergent-cli agents replay <run-id> --live-tools search_hybrid,get_object`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReplayRun,
+}
+
 // Flags for agents
 var (
 	agentProjectID        string
@@ -90,6 +107,10 @@ var (
 	agentReactionEvents   string
 	agentReactionObjTypes string
 	agentRunsLimit        int
+	replayPrompt          string
+	replaySystemPrompt    string
+	replayForkFromStep    int
+	replayLiveTools       string
 )
 
 func runListAgents(cmd *cobra.Command, args []string) error {
@@ -432,6 +453,120 @@ var (
 	questionStatus string
 )
 
+// --- Memories Commands ---
+
+var memoriesCmd = &cobra.Command{
+	Use:   "memories",
+	Short: "Manage agent memories",
+	Long:  "Commands for inspecting and purging the long-term memories agents store between runs",
+}
+
+var listMemoriesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List agent memories",
+	Long:  "List a project's agent memories, newest first, with optional filters",
+	RunE:  runListMemories,
+}
+
+var deleteMemoryCmd = &cobra.Command{
+	Use:   "delete [memory-id]",
+	Short: "Delete an agent memory",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteMemory,
+}
+
+var purgeMemoriesCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Purge agent memories",
+	Long: `Delete every agent memory in the project matching the filters.
+Without filters, --all is required to purge the whole project.`,
+	RunE: runPurgeMemories,
+}
+
+// Flags for memories
+var (
+	memoryDefinitionID string
+	memoryUserID       string
+	memoryKind         string
+	memoryLimit        int
+	memoryPurgeAll     bool
+)
+
+// --- Evals Commands ---
+
+var evalsCmd = &cobra.Command{
+	Use:   "evals",
+	Short: "Manage agent evaluation suites",
+	Long:  "Commands for defining evaluation suites of agent definitions, running them and comparing results across definition versions",
+}
+
+var listEvalSuitesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List evaluation suites",
+	RunE:  runListEvalSuites,
+}
+
+var createEvalSuiteCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an evaluation suite from a JSON file",
+	Long: `Create an evaluation suite from a JSON file with agentDefinitionId, name
+and scenarios.
+
+Examples:
+  emergent-cli agents evals create --file researcher-evals.json`,
+	RunE: runCreateEvalSuite,
+}
+
+var deleteEvalSuiteCmd = &cobra.Command{
+	Use:   "delete [suite-id]",
+	Short: "Delete an evaluation suite and its runs",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteEvalSuite,
+}
+
+var runEvalSuiteCmd = &cobra.Command{
+	Use:   "run [suite-id]",
+	Short: "Run an evaluation suite",
+	Long: `Run an evaluation suite against the current agent definition. With --wait,
+poll until the run finishes and print its results.
+
+Examples:
+  emergent-cli agents evals run <suite-id> --wait
+  emergent-cli agents evals run <suite-id> --scenario "links owner" --system-prompt "Always cite sources"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRunEvalSuite,
+}
+
+var evalRunsCmd = &cobra.Command{
+	Use:   "runs [suite-id] [run-id]",
+	Short: "List the runs of an evaluation suite, or show one run",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runEvalRuns,
+}
+
+var compareEvalRunsCmd = &cobra.Command{
+	Use:   "compare [suite-id]",
+	Short: "Compare evaluation runs across definition versions",
+	Long: `Compare two runs of an evaluation suite scenario by scenario. By default the
+latest completed run is compared against the latest earlier run of a
+different definition version.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCompareEvalRuns,
+}
+
+// Flags for evals
+var (
+	evalDefinitionID string
+	evalFile         string
+	evalScenarios    []string
+	evalSystemPrompt string
+	evalWait         bool
+	evalWaitTimeout  time.Duration
+	evalRunStatus    string
+	evalCompareRun   string
+	evalCompareBase  string
+)
+
 // --- Webhook Hooks Commands ---
 
 var hooksCmd = &cobra.Command{
@@ -647,6 +782,306 @@ func runRespondToQuestion(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runReplayRun(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	runID := args[0]
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	req := &agents.ReplayRunRequest{
+		Prompt:       replayPrompt,
+		ForkFromStep: replayForkFromStep,
+	}
+	if cmd.Flags().Changed("system-prompt") {
+		req.SystemPrompt = &replaySystemPrompt
+	}
+	if replayLiveTools != "" {
+		req.LiveTools = strings.Split(replayLiveTools, ",")
+	}
+
+	result, err := c.SDK.Agents.ReplayRun(context.Background(), projectID, runID, req)
+	if err != nil {
+		return fmt.Errorf("failed to replay run: %w", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func runListMemories(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	result, err := c.SDK.Agents.ListMemories(context.Background(), projectID, &agents.ListMemoriesOptions{
+		Limit:             memoryLimit,
+		AgentDefinitionID: memoryDefinitionID,
+		UserID:            memoryUserID,
+		Kind:              memoryKind,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list memories: %w", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func runDeleteMemory(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	if err := c.SDK.Agents.DeleteMemory(context.Background(), projectID, args[0]); err != nil {
+		return fmt.Errorf("failed to delete memory: %w", err)
+	}
+
+	fmt.Printf("Memory %s deleted successfully.\n", args[0])
+	return nil
+}
+
+func runPurgeMemories(cmd *cobra.Command, args []string) error {
+	req := &agents.PurgeMemoriesRequest{}
+	if memoryDefinitionID != "" {
+		req.AgentDefinitionID = &memoryDefinitionID
+	}
+	if memoryUserID != "" {
+		req.UserID = &memoryUserID
+	}
+	if memoryKind != "" {
+		req.Kind = &memoryKind
+	}
+	if req.AgentDefinitionID == nil && req.UserID == nil && req.Kind == nil && !memoryPurgeAll {
+		return fmt.Errorf("no filters given; pass --all to purge every memory in the project")
+	}
+
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	result, err := c.SDK.Agents.PurgeMemories(context.Background(), projectID, req)
+	if err != nil {
+		return fmt.Errorf("failed to purge memories: %w", err)
+	}
+
+	fmt.Printf("Purged %d memories.\n", result.Data.Deleted)
+	return nil
+}
+
+func runListEvalSuites(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	result, err := c.SDK.Agents.ListEvalSuites(context.Background(), projectID, &agents.ListEvalSuitesOptions{
+		AgentDefinitionID: evalDefinitionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list evaluation suites: %w", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func runCreateEvalSuite(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(evalFile)
+	if err != nil {
+		return fmt.Errorf("failed to read suite file: %w", err)
+	}
+	var req agents.CreateEvalSuiteRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return fmt.Errorf("failed to parse suite file: %w", err)
+	}
+	if evalDefinitionID != "" {
+		req.AgentDefinitionID = evalDefinitionID
+	}
+
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	result, err := c.SDK.Agents.CreateEvalSuite(context.Background(), projectID, &req)
+	if err != nil {
+		return fmt.Errorf("failed to create evaluation suite: %w", err)
+	}
+
+	fmt.Printf("Evaluation suite %s created with %d scenarios.\n", result.Data.ID, len(result.Data.Scenarios))
+	return nil
+}
+
+func runDeleteEvalSuite(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	if err := c.SDK.Agents.DeleteEvalSuite(context.Background(), projectID, args[0]); err != nil {
+		return fmt.Errorf("failed to delete evaluation suite: %w", err)
+	}
+
+	fmt.Printf("Evaluation suite %s deleted successfully.\n", args[0])
+	return nil
+}
+
+func runRunEvalSuite(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	suiteID := args[0]
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	req := &agents.StartEvalRunRequest{Scenarios: evalScenarios}
+	if cmd.Flags().Changed("system-prompt") {
+		req.SystemPrompt = &evalSystemPrompt
+	}
+
+	ctx := context.Background()
+	started, err := c.SDK.Agents.StartEvalRun(ctx, projectID, suiteID, req)
+	if err != nil {
+		return fmt.Errorf("failed to start evaluation run: %w", err)
+	}
+	run := started.Data
+
+	if evalWait {
+		deadline := time.Now().Add(evalWaitTimeout)
+		for run.Status == "running" {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("evaluation run %s still running after %s", run.ID, evalWaitTimeout)
+			}
+			time.Sleep(2 * time.Second)
+			result, err := c.SDK.Agents.GetEvalRun(ctx, projectID, suiteID, run.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get evaluation run: %w", err)
+			}
+			run = result.Data
+		}
+	}
+
+	out, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func runEvalRuns(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	var result any
+	if len(args) == 2 {
+		result, err = c.SDK.Agents.GetEvalRun(context.Background(), projectID, args[0], args[1])
+	} else {
+		result, err = c.SDK.Agents.ListEvalRuns(context.Background(), projectID, args[0], &agents.ListEvalRunsOptions{
+			Status: evalRunStatus,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get evaluation runs: %w", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func runCompareEvalRuns(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectContext(cmd, agentProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project ID: %w", err)
+	}
+
+	result, err := c.SDK.Agents.CompareEvalRuns(context.Background(), projectID, args[0], &agents.CompareEvalRunsOptions{
+		RunID:     evalCompareRun,
+		BaseRunID: evalCompareBase,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to compare evaluation runs: %w", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
 func init() {
 	// Persistent flags for all agent subcommands
 	agentsCmd.PersistentFlags().StringVar(&agentProjectID, "project", "", "Project name or ID (auto-detected from config/env if not specified)")
@@ -676,6 +1111,12 @@ func init() {
 	// Runs limit flag
 	runsAgentCmd.Flags().IntVar(&agentRunsLimit, "limit", 10, "Maximum number of runs to return")
 
+	// Replay flags
+	replayRunCmd.Flags().StringVar(&replayPrompt, "prompt", "", "Replace the recorded user message")
+	replayRunCmd.Flags().StringVar(&replaySystemPrompt, "system-prompt", "", "Replace the agent definition's system prompt")
+	replayRunCmd.Flags().IntVar(&replayForkFromStep, "fork-from-step", 0, "Keep the recorded decisions before this step")
+	replayRunCmd.Flags().StringVar(&replayLiveTools, "live-tools", "", "Comma-separated tool names or globs to run live instead of from the recording")
+
 	// Questions flags
 	listProjectQuestionsCmd.Flags().StringVar(&questionStatus, "status", "", "Filter by status (pending, answered, cancelled, expired)")
 
@@ -684,6 +1125,34 @@ func init() {
 	questionsCmd.AddCommand(listProjectQuestionsCmd)
 	questionsCmd.AddCommand(respondToQuestionCmd)
 
+	// Memories flags
+	for _, cmd := range []*cobra.Command{listMemoriesCmd, purgeMemoriesCmd} {
+		cmd.Flags().StringVar(&memoryDefinitionID, "definition", "", "Filter by agent definition ID")
+		cmd.Flags().StringVar(&memoryUserID, "user", "", "Filter by user ID")
+		cmd.Flags().StringVar(&memoryKind, "kind", "", "Filter by kind (fact, preference, summary)")
+	}
+	listMemoriesCmd.Flags().IntVar(&memoryLimit, "limit", 20, "Maximum number of memories to return")
+	purgeMemoriesCmd.Flags().BoolVar(&memoryPurgeAll, "all", false, "Purge every memory in the project when no filters are given")
+
+	// Register memories subcommands
+	memoriesCmd.AddCommand(listMemoriesCmd, deleteMemoryCmd, purgeMemoriesCmd)
+
+	// Evals flags
+	listEvalSuitesCmd.Flags().StringVar(&evalDefinitionID, "definition", "", "Filter by agent definition ID")
+	createEvalSuiteCmd.Flags().StringVar(&evalFile, "file", "", "Path to the suite JSON file (required)")
+	createEvalSuiteCmd.Flags().StringVar(&evalDefinitionID, "definition", "", "Agent definition ID (overrides the file)")
+	_ = createEvalSuiteCmd.MarkFlagRequired("file")
+	runEvalSuiteCmd.Flags().StringSliceVar(&evalScenarios, "scenario", nil, "Run only these scenarios (repeatable)")
+	runEvalSuiteCmd.Flags().StringVar(&evalSystemPrompt, "system-prompt", "", "Evaluate this system prompt instead of the definition's")
+	runEvalSuiteCmd.Flags().BoolVar(&evalWait, "wait", false, "Wait for the run to finish and print its results")
+	runEvalSuiteCmd.Flags().DurationVar(&evalWaitTimeout, "timeout", 30*time.Minute, "Maximum time to wait with --wait")
+	evalRunsCmd.Flags().StringVar(&evalRunStatus, "status", "", "Filter by status (running, completed, failed)")
+	compareEvalRunsCmd.Flags().StringVar(&evalCompareRun, "run", "", "Run to compare (default: latest completed)")
+	compareEvalRunsCmd.Flags().StringVar(&evalCompareBase, "base", "", "Run to compare against (default: latest earlier version)")
+
+	// Register evals subcommands
+	evalsCmd.AddCommand(listEvalSuitesCmd, createEvalSuiteCmd, deleteEvalSuiteCmd, runEvalSuiteCmd, evalRunsCmd, compareEvalRunsCmd)
+
 	// Webhook hooks flags
 	createHookCmd.Flags().StringVar(&hookLabel, "label", "", "Hook label (required)")
 	createHookCmd.Flags().IntVar(&hookRateLimit, "rate-limit", 0, "Rate limit in requests per minute (0 = server default)")
@@ -701,7 +1170,10 @@ func init() {
 	agentsCmd.AddCommand(deleteAgentCmd)
 	agentsCmd.AddCommand(triggerAgentCmd)
 	agentsCmd.AddCommand(runsAgentCmd)
+	agentsCmd.AddCommand(replayRunCmd)
 	agentsCmd.AddCommand(questionsCmd)
+	agentsCmd.AddCommand(memoriesCmd)
+	agentsCmd.AddCommand(evalsCmd)
 	agentsCmd.AddCommand(hooksCmd)
 	rootCmd.AddCommand(agentsCmd)
 }
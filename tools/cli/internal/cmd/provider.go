@@ -342,10 +342,12 @@ var providerUsageCmd = &cobra.Command{
 
 Without --project, reports org-wide usage across all projects.
 With --project, reports usage for that specific project.
+With --by-agent, breaks usage down per agent instead of per model.
 
 Examples:
   emergent provider usage
   emergent provider usage --project <id>
+  emergent provider usage --project <id> --by-agent
   emergent provider usage --since 2024-01-01`,
 	RunE: runProviderUsage,
 }
@@ -356,6 +358,7 @@ var (
 	usageUntil     string
 	usageOrgID     string
 	usageJSONFlag  bool
+	usageByAgent   bool
 )
 
 func runProviderUsage(cmd *cobra.Command, args []string) error {
@@ -380,6 +383,10 @@ func runProviderUsage(cmd *cobra.Command, args []string) error {
 		until = t
 	}
 
+	if usageByAgent {
+		return runProviderUsageByAgent(c, since, until)
+	}
+
 	var summary *provider.UsageSummary
 
 	if usageProjectID != "" {
@@ -435,6 +442,155 @@ func runProviderUsage(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runProviderUsageByAgent(c *client.Client, since, until time.Time) error {
+	var summary *provider.AgentUsageSummary
+	var err error
+
+	if usageProjectID != "" {
+		summary, err = c.SDK.Provider.GetProjectUsageByAgent(context.Background(), usageProjectID, since, until)
+		if err != nil {
+			return fmt.Errorf("failed to get project usage: %w", err)
+		}
+	} else {
+		orgID, err := resolveProviderOrgID(c, usageOrgID)
+		if err != nil {
+			return err
+		}
+		summary, err = c.SDK.Provider.GetOrgUsageByAgent(context.Background(), orgID, since, until)
+		if err != nil {
+			return fmt.Errorf("failed to get org usage: %w", err)
+		}
+	}
+
+	if usageJSONFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+
+	if summary.Note != "" {
+		fmt.Println("Note:", summary.Note)
+		fmt.Println()
+	}
+
+	if len(summary.Data) == 0 {
+		fmt.Println("No usage data found for the specified period.")
+		return nil
+	}
+
+	var totalCost float64
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tAGENT ID\tRUNS\tINPUT\tOUTPUT\tEST. COST (USD)")
+	for _, row := range summary.Data {
+		name, id := "(no agent)", "-"
+		if row.AgentID != nil {
+			name, id = "(deleted)", *row.AgentID
+		}
+		if row.AgentName != nil {
+			name = *row.AgentName
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t$%.4f\n",
+			name,
+			id,
+			row.Runs,
+			row.TotalInput,
+			row.TotalOutput,
+			row.EstimatedCostUSD,
+		)
+		totalCost += row.EstimatedCostUSD
+	}
+	_ = w.Flush()
+	fmt.Printf("\nTotal estimated cost: $%.4f\n", totalCost)
+	return nil
+}
+
+// ── budgets ───────────────────────────────────────────────────────────────────
+
+var providerBudgetsCmd = &cobra.Command{
+	Use:   "budgets",
+	Short: "Show LLM budgets and their usage in the current period",
+	Long: `Show daily and monthly LLM token/cost budgets with their usage so far.
+
+Without --project, lists every budget in the organization.
+With --project, lists the budgets that apply to that project: the org's,
+the project's own and those of its agent definitions.
+
+Model calls are refused once a budget is exceeded; agent runs stop with
+status "cancelled" until the period rolls over.
+
+Examples:
+  emergent provider budgets
+  emergent provider budgets --project <id>`,
+	RunE: runProviderBudgets,
+}
+
+var (
+	budgetsProjectID string
+	budgetsOrgID     string
+	budgetsJSONFlag  bool
+)
+
+func runProviderBudgets(cmd *cobra.Command, args []string) error {
+	c, err := getClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	var statuses []provider.BudgetStatus
+	if budgetsProjectID != "" {
+		statuses, err = c.SDK.Provider.GetProjectBudgetStatus(context.Background(), budgetsProjectID)
+		if err != nil {
+			return fmt.Errorf("failed to get project budgets: %w", err)
+		}
+	} else {
+		orgID, err := resolveProviderOrgID(c, budgetsOrgID)
+		if err != nil {
+			return err
+		}
+		statuses, err = c.SDK.Provider.GetOrgBudgetStatus(context.Background(), orgID)
+		if err != nil {
+			return fmt.Errorf("failed to get org budgets: %w", err)
+		}
+	}
+
+	if budgetsJSONFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No budgets configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCOPE\tTARGET\tPERIOD\tTOKENS\tCOST (USD)\tSTATE")
+	for _, st := range statuses {
+		b := st.Budget
+		target := b.OrgID
+		if b.AgentDefinitionID != nil {
+			target = *b.AgentDefinitionID
+		} else if b.ProjectID != nil {
+			target = *b.ProjectID
+		}
+		tokens := fmt.Sprintf("%d", st.TokensUsed)
+		if b.TokenLimit != nil {
+			tokens = fmt.Sprintf("%d / %d", st.TokensUsed, *b.TokenLimit)
+		}
+		cost := fmt.Sprintf("$%.4f", st.CostUsedUSD)
+		if b.CostLimitUSD != nil {
+			cost = fmt.Sprintf("$%.4f / $%.2f", st.CostUsedUSD, *b.CostLimitUSD)
+		}
+		state := st.State
+		if !b.Enabled {
+			state = "disabled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", b.Scope, target, b.Period, tokens, cost, state)
+	}
+	return w.Flush()
+}
+
 // ── test ──────────────────────────────────────────────────────────────────────
 
 var providerTestCmd = &cobra.Command{
@@ -575,6 +731,12 @@ func init() {
 	providerUsageCmd.Flags().StringVar(&usageUntil, "until", "", "End date for usage window (YYYY-MM-DD)")
 	providerUsageCmd.Flags().StringVar(&usageOrgID, "org-id", "", "Organization ID (auto-detected from config)")
 	providerUsageCmd.Flags().BoolVar(&usageJSONFlag, "json", false, "Output raw JSON")
+	providerUsageCmd.Flags().BoolVar(&usageByAgent, "by-agent", false, "Break usage down per agent")
+
+	// budgets flags
+	providerBudgetsCmd.Flags().StringVar(&budgetsProjectID, "project", "", "Show budgets applying to a specific project ID")
+	providerBudgetsCmd.Flags().StringVar(&budgetsOrgID, "org-id", "", "Organization ID (auto-detected from config)")
+	providerBudgetsCmd.Flags().BoolVar(&budgetsJSONFlag, "json", false, "Output raw JSON")
 
 	// test flags
 	providerTestCmd.Flags().StringVar(&testProviderOrgID, "org-id", "", "Organization ID (auto-detected from config)")
@@ -585,6 +747,7 @@ func init() {
 	providerCmd.AddCommand(configureProjectCmd)
 	providerCmd.AddCommand(providerModelsCmd)
 	providerCmd.AddCommand(providerUsageCmd)
+	providerCmd.AddCommand(providerBudgetsCmd)
 	providerCmd.AddCommand(providerTestCmd)
 
 	rootCmd.AddCommand(providerCmd)
@@ -31,7 +31,10 @@ var createTokenCmd = &cobra.Command{
 Without --project, creates an account-level token usable across all projects.
 With --project, creates a project-scoped token.
 
-Valid scopes: schema:read, data:read, data:write, agents:read, agents:write, projects:read, projects:write`,
+Valid scopes: schema:read, data:read, data:write, agents:read, agents:write, projects:read, projects:write
+
+Use --tools to limit the token to specific MCP tools, e.g. a read-only
+research token: --scopes data:read --tools hybrid_search,semantic_search`,
 	RunE: runCreateToken,
 }
 
@@ -55,6 +58,7 @@ var (
 	tokenProjectID string
 	tokenName      string
 	tokenScopes    string
+	tokenTools     string
 )
 
 func runListTokens(cmd *cobra.Command, args []string) error {
@@ -150,9 +154,19 @@ func runCreateToken(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var allowedTools []string
+	if tokenTools != "" {
+		for _, tool := range strings.Split(tokenTools, ",") {
+			if tool = strings.TrimSpace(tool); tool != "" {
+				allowedTools = append(allowedTools, tool)
+			}
+		}
+	}
+
 	req := &apitokens.CreateTokenRequest{
-		Name:   tokenName,
-		Scopes: scopes,
+		Name:         tokenName,
+		Scopes:       scopes,
+		AllowedTools: allowedTools,
 	}
 
 	// If --project not provided, create an account-level token
@@ -172,6 +186,9 @@ func runCreateToken(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Type:    account\n")
 		fmt.Printf("  Prefix:  %s\n", result.Prefix)
 		fmt.Printf("  Scopes:  %s\n", strings.Join(result.Scopes, ", "))
+		if len(result.AllowedTools) > 0 {
+			fmt.Printf("  Tools:   %s\n", strings.Join(result.AllowedTools, ", "))
+		}
 		fmt.Printf("  Created: %s\n", result.CreatedAt)
 		fmt.Println()
 
@@ -199,6 +216,9 @@ func runCreateToken(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Type:    project\n")
 	fmt.Printf("  Prefix:  %s\n", result.Prefix)
 	fmt.Printf("  Scopes:  %s\n", strings.Join(result.Scopes, ", "))
+	if len(result.AllowedTools) > 0 {
+		fmt.Printf("  Tools:   %s\n", strings.Join(result.AllowedTools, ", "))
+	}
 	fmt.Printf("  Created: %s\n", result.CreatedAt)
 	fmt.Println()
 	fmt.Println("  Retrieve this token later: emergent-cli tokens get " + result.ID)
@@ -285,6 +305,7 @@ func init() {
 	// Create token flags
 	createTokenCmd.Flags().StringVar(&tokenName, "name", "", "Token name (required)")
 	createTokenCmd.Flags().StringVar(&tokenScopes, "scopes", "", "Comma-separated scopes (default: data:read). Valid: schema:read, data:read, data:write, agents:read, agents:write, projects:read, projects:write")
+	createTokenCmd.Flags().StringVar(&tokenTools, "tools", "", "Comma-separated MCP tools the token is limited to (default: all tools its scopes allow)")
 	_ = createTokenCmd.MarkFlagRequired("name")
 
 	// Register subcommands